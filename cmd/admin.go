@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"text/tabwriter"
 
 	"code.gitea.io/gitea/models"
@@ -19,6 +20,7 @@ import (
 	pwd "code.gitea.io/gitea/modules/password"
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/archiver"
 
 	"github.com/urfave/cli"
 )
@@ -32,6 +34,7 @@ var (
 			subcmdCreateUser,
 			subcmdChangePassword,
 			subcmdRepoSyncReleases,
+			subcmdRepoBundle,
 			subcmdRegenerate,
 			subcmdAuth,
 		},
@@ -106,6 +109,30 @@ var (
 		Action: runRepoSyncReleases,
 	}
 
+	subcmdRepoBundle = cli.Command{
+		Name:   "repo-bundle",
+		Usage:  "Write a git bundle of branches and tags of a repository",
+		Action: runRepoBundle,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "owner",
+				Usage: "Owner of the repository",
+			},
+			cli.StringFlag{
+				Name:  "repo",
+				Usage: "Name of the repository",
+			},
+			cli.StringSliceFlag{
+				Name:  "ref",
+				Usage: "Branch or tag to include, can be repeated. All of them are included by default",
+			},
+			cli.StringFlag{
+				Name:  "output, o",
+				Usage: "Path of the bundle file, defaults to the name of the repository with the .bundle extension",
+			},
+		},
+	}
+
 	subcmdRegenerate = cli.Command{
 		Name:  "regenerate",
 		Usage: "Regenerate specific files",
@@ -435,6 +462,41 @@ func getReleaseCount(id int64) (int64, error) {
 	)
 }
 
+func runRepoBundle(c *cli.Context) error {
+	if err := argsSet(c, "owner", "repo"); err != nil {
+		return err
+	}
+	if err := initDB(); err != nil {
+		return err
+	}
+
+	repo, err := models.GetRepositoryByOwnerAndName(c.String("owner"), c.String("repo"))
+	if err != nil {
+		return fmt.Errorf("GetRepositoryByOwnerAndName: %v", err)
+	}
+	heads, err := archiver.ResolveBundleHeads(repo.RepoPath(), c.StringSlice("ref"))
+	if err != nil {
+		return err
+	}
+	if len(heads) == 0 {
+		return fmt.Errorf("repository %s has no branches or tags", repo.FullName())
+	}
+
+	output := c.String("output")
+	if output == "" {
+		output = repo.Name + ".bundle"
+	}
+	// The bundle is written by git in the directory of the repository
+	if output, err = filepath.Abs(output); err != nil {
+		return err
+	}
+	if err := archiver.WriteBundle(repo.RepoPath(), heads, output); err != nil {
+		return err
+	}
+	fmt.Printf("Bundle of %d refs of %s written to %s\n", len(heads), repo.FullName(), output)
+	return nil
+}
+
 func runRegenerateHooks(c *cli.Context) error {
 	if err := initDB(); err != nil {
 		return err
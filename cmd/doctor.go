@@ -114,6 +114,12 @@ var checklist = []check{
 		isDefault: false,
 		f:         runDoctorHooks,
 	},
+	{
+		title:     "Check if the git configs of repositories are applied and allowed",
+		name:      "check-repo-git-configs",
+		isDefault: false,
+		f:         runDoctorRepoGitConfigs,
+	},
 	{
 		title:     "Recalculate merge bases",
 		name:      "recalculate_merge_bases",
@@ -419,6 +425,22 @@ func runDoctorHooks(ctx *cli.Context) ([]string, error) {
 	})
 }
 
+func runDoctorRepoGitConfigs(ctx *cli.Context) ([]string, error) {
+	return iterateRepositories(func(repo *models.Repository) ([]string, error) {
+		changed, err := models.SyncRepoGitConfigs(repo, ctx.Bool("fix"))
+		if err != nil {
+			return nil, err
+		}
+		if len(changed) == 0 {
+			return nil, nil
+		}
+		if ctx.Bool("fix") {
+			return []string{fmt.Sprintf("synchronized git configs %s of %s", strings.Join(changed, ", "), repo.FullName())}, nil
+		}
+		return []string{fmt.Sprintf("git configs %s of %s are not applied or not allowed anymore", strings.Join(changed, ", "), repo.FullName())}, nil
+	})
+}
+
 func runDoctorPRMergeBase(ctx *cli.Context) ([]string, error) {
 	numRepos := 0
 	numPRs := 0
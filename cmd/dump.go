@@ -226,7 +226,8 @@ func runDump(ctx *cli.Context) error {
 			fatal("Failed to include repositories: %v", err)
 		}
 
-		if _, err := os.Stat(setting.LFS.ContentPath); !os.IsNotExist(err) {
+		// The objects of remote lfs storages are not dumped
+		if _, err := os.Stat(setting.LFS.ContentPath); setting.LFS.StoreType == "local" && !os.IsNotExist(err) {
 			log.Info("Dumping lfs... %s", setting.LFS.ContentPath)
 			if err := addRecursive(w, "lfs", setting.LFS.ContentPath, verbose); err != nil {
 				fatal("Failed to include lfs: %v", err)
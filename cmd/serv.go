@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/lfs"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/pprof"
@@ -245,20 +246,16 @@ func runServ(c *cli.Context) error {
 		return nil
 	}
 
-	// Special handle for Windows.
-	if setting.IsWindows {
-		verb = strings.Replace(verb, "-", " ", 1)
-	}
-
-	var gitcmd *exec.Cmd
-	verbs := strings.Split(verb, " ")
-	if len(verbs) == 2 {
-		gitcmd = exec.Command(verbs[0], verbs[1], repoPath)
-	} else {
-		gitcmd = exec.Command(verb, repoPath)
-	}
+	// Run the command as a git sub command to apply the global git configuration, like the partial clone support
+	gitArgs := append(append([]string{}, git.GlobalCommandArgs...), strings.TrimPrefix(verb, "git-"), repoPath)
+	gitcmd := exec.Command(git.GitExecutable, gitArgs...)
 
 	gitcmd.Dir = setting.RepoRootPath
+	// The client requests the wire protocol version through GIT_PROTOCOL, if the SSH server accepts it
+	if protocol := os.Getenv("GIT_PROTOCOL"); protocol != "" &&
+		(!setting.Git.EnableAutoGitWireProtocol || !git.IsValidProtocolParameters(protocol)) {
+		gitcmd.Env = append(os.Environ(), "GIT_PROTOCOL=")
+	}
 	gitcmd.Stdout = os.Stdout
 	gitcmd.Stdin = os.Stdin
 	gitcmd.Stderr = os.Stderr
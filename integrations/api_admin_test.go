@@ -144,3 +144,151 @@ func TestAPIListUsersNonAdmin(t *testing.T) {
 	req := NewRequestf(t, "GET", "/api/v1/admin/users?token=%s", token)
 	session.MakeRequest(t, req, http.StatusForbidden)
 }
+
+func TestAPIAdminPruneHookDeliveries(t *testing.T) {
+	defer prepareTestEnv(t)()
+	// user1 is an admin user
+	session := loginUser(t, "user1")
+	token := getTokenForLoggedInUser(t, session)
+	urlStr := "/api/v1/admin/hooks/deliveries/prune?token=" + token
+
+	req := NewRequestWithJSON(t, "POST", urlStr, &api.PruneHookDeliveriesOption{})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.PruneHookDeliveriesOption{OlderThan: "a week"})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.PruneHookDeliveriesOption{OlderThan: "168h"})
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var result api.PruneHookDeliveriesResult
+	DecodeJSON(t, resp, &result)
+	assert.EqualValues(t, 2, result.Deleted)
+	models.AssertNotExistsBean(t, &models.HookTask{ID: 1})
+
+	// only site admins can prune deliveries
+	session = loginUser(t, "user2")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/admin/hooks/deliveries/prune?token="+token, &api.PruneHookDeliveriesOption{NumberToKeep: 1})
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
+
+func TestAPIAdminSystemHooks(t *testing.T) {
+	defer prepareTestEnv(t)()
+	// user1 is an admin user
+	session := loginUser(t, "user1")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/admin/hooks?token="+token, &api.CreateHookOption{
+		Type: "gitea",
+		Config: api.CreateHookOptionConfig{
+			"url":          "http://example.com/",
+			"content_type": "json",
+		},
+		Events: []string{"user", "organization"},
+		Active: true,
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var hook api.Hook
+	DecodeJSON(t, resp, &hook)
+	assert.EqualValues(t, []string{"user", "organization"}, hook.Events)
+	models.AssertExistsAndLoadBean(t, &models.Webhook{ID: hook.ID, RepoID: 0, OrgID: 0, IsSystemWebhook: true})
+
+	req = NewRequest(t, "GET", "/api/v1/admin/hooks?token="+token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var hooks []*api.Hook
+	DecodeJSON(t, resp, &hooks)
+	if assert.Len(t, hooks, 1) {
+		assert.EqualValues(t, hook.ID, hooks[0].ID)
+	}
+
+	// creating a user and an organization is sent to the hook
+	req = NewRequestWithJSON(t, "POST", "/api/v1/admin/users?token="+token, &api.CreateUserOption{
+		Username: "hookuser",
+		Email:    "hookuser@example.com",
+		Password: "Passw0rd!",
+	})
+	session.MakeRequest(t, req, http.StatusCreated)
+	models.AssertExistsAndLoadBean(t, &models.HookTask{HookID: hook.ID, RepoID: 0, EventType: models.HookEventUser})
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/orgs?token="+token, &api.CreateOrgOption{
+		UserName: "hookorg",
+	})
+	session.MakeRequest(t, req, http.StatusCreated)
+	models.AssertExistsAndLoadBean(t, &models.HookTask{HookID: hook.ID, RepoID: 0, EventType: models.HookEventOrganization})
+
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/admin/hooks/%d?token=%s", hook.ID, token), &api.EditHookOption{
+		Events: []string{"push"},
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &hook)
+	assert.EqualValues(t, []string{"push"}, hook.Events)
+
+	// repository hooks aren't system hooks
+	req = NewRequestf(t, "GET", "/api/v1/admin/hooks/1?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	// only site admins can manage system hooks
+	normalSession := loginUser(t, "user2")
+	normalToken := getTokenForLoggedInUser(t, normalSession)
+	req = NewRequest(t, "GET", "/api/v1/admin/hooks?token="+normalToken)
+	normalSession.MakeRequest(t, req, http.StatusForbidden)
+
+	req = NewRequestf(t, "DELETE", "/api/v1/admin/hooks/%d?token=%s", hook.ID, token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.Webhook{ID: hook.ID})
+}
+
+func TestAPIAdminUserEmails(t *testing.T) {
+	defer prepareTestEnv(t)()
+	// user1 is an admin user
+	session := loginUser(t, "user1")
+	token := getTokenForLoggedInUser(t, session)
+	urlStr := "/api/v1/admin/users/user2/emails?token=" + token
+
+	var emails []*api.Email
+	req := NewRequest(t, "GET", urlStr)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &emails)
+	assert.Equal(t, []*api.Email{
+		{Email: "user2@example.com", Verified: true, Primary: true},
+		{Email: "user21@example.com", Verified: false, Primary: false},
+	}, emails)
+
+	// only verified addresses can be made primary
+	req = NewRequestWithJSON(t, "POST", "/api/v1/admin/users/user2/emails/primary?token="+token, &api.UserEmailOption{Email: "user21@example.com"})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// user21 uses the address already
+	req = NewRequestWithJSON(t, "POST", "/api/v1/admin/users/user2/emails/verify?token="+token, &api.UserEmailOption{Email: "user21@example.com"})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/admin/users/user2/emails/verify?token="+token, &api.UserEmailOption{Email: "unknown@example.com"})
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.CreateEmailOption{Emails: []string{"user2-new@example.com", "user2-old@example.com"}})
+	session.MakeRequest(t, req, http.StatusCreated)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/admin/users/user2/emails/verify?token="+token, &api.UserEmailOption{Email: "user2-new@example.com"})
+	session.MakeRequest(t, req, http.StatusOK)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/admin/users/user2/emails/primary?token="+token, &api.UserEmailOption{Email: "user2-new@example.com"})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &emails)
+	assert.Len(t, emails, 4)
+	models.AssertExistsAndLoadBean(t, &models.User{ID: 2, Email: "user2-new@example.com"})
+
+	req = NewRequestWithJSON(t, "DELETE", urlStr, &api.DeleteEmailOption{Emails: []string{"user2-old@example.com"}})
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.EmailAddress{UID: 2, Email: "user2-old@example.com"})
+
+	// user9 is not activated
+	req = NewRequest(t, "POST", "/api/v1/admin/users/user9/activate?token="+token)
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertExistsAndLoadBean(t, &models.User{ID: 9, IsActive: true})
+
+	// only site admins can manage the emails of the other users
+	session = loginUser(t, "user2")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequest(t, "GET", "/api/v1/admin/users/user4/emails?token="+token)
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
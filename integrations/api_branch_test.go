@@ -101,6 +101,34 @@ func TestAPIGetBranch(t *testing.T) {
 	}
 }
 
+func TestAPIListBranches(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	for _, test := range []struct {
+		Query         string
+		ExpectedNames []string
+		ExpectedTotal string
+	}{
+		{"limit=2", []string{"DefaultBranch", "branch2"}, "6"},
+		{"limit=2&page=3", []string{"master", "pr-to-update"}, "6"},
+		{"prefix=feature", []string{"feature/1"}, "1"},
+		{"prefix=de&sort=newest", []string{"develop"}, "1"},
+	} {
+		req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/branches?%s&token=%s", test.Query, token)
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		var branches []*api.Branch
+		DecodeJSON(t, resp, &branches)
+		names := make([]string, len(branches))
+		for i, branch := range branches {
+			names[i] = branch.Name
+		}
+		assert.Equal(t, test.ExpectedNames, names, test.Query)
+		assert.Equal(t, test.ExpectedTotal, resp.Header().Get("X-Total-Count"), test.Query)
+	}
+}
+
 func TestAPICreateBranch(t *testing.T) {
 	onGiteaRun(t, testAPICreateBranches)
 }
@@ -193,3 +221,77 @@ func TestAPIBranchProtection(t *testing.T) {
 	testAPIDeleteBranch(t, "master", http.StatusForbidden)
 	testAPIDeleteBranch(t, "branch2", http.StatusNoContent)
 }
+
+func TestAPIEditBranchProtectionKeepsOmittedFields(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/branch_protections?token="+token, &api.CreateBranchProtectionOption{
+		BranchName:          "master",
+		EnableStatusCheck:   true,
+		StatusCheckContexts: []string{"ci/build"},
+	})
+	session.MakeRequest(t, req, http.StatusCreated)
+
+	requiredApprovals := int64(2)
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1/branch_protections/master?token="+token, &api.EditBranchProtectionOption{
+		RequiredApprovals: &requiredApprovals,
+	})
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var branchProtection api.BranchProtection
+	DecodeJSON(t, resp, &branchProtection)
+	assert.EqualValues(t, 2, branchProtection.RequiredApprovals)
+	assert.True(t, branchProtection.EnableStatusCheck)
+	assert.Equal(t, []string{"ci/build"}, branchProtection.StatusCheckContexts)
+
+	enableStatusCheck := false
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1/branch_protections/master?token="+token, &api.EditBranchProtectionOption{
+		EnableStatusCheck: &enableStatusCheck,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &branchProtection)
+	assert.False(t, branchProtection.EnableStatusCheck)
+	assert.Empty(t, branchProtection.StatusCheckContexts)
+}
+
+func TestAPIRestoreDeletedBranch(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session)
+
+		testAPIDeleteBranch(t, "feature/1", http.StatusNoContent)
+		testAPIGetBranch(t, "feature/1", false)
+
+		req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/deleted_branches?token=%s", token)
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		var deletedBranches []*api.DeletedBranch
+		DecodeJSON(t, resp, &deletedBranches)
+		var deletedBranch *api.DeletedBranch
+		for _, branch := range deletedBranches {
+			if branch.Name == "feature/1" {
+				deletedBranch = branch
+			}
+		}
+		if !assert.NotNil(t, deletedBranch) {
+			return
+		}
+		assert.EqualValues(t, "user2", deletedBranch.DeletedBy.UserName)
+
+		req = NewRequestf(t, "POST", "/api/v1/repos/user2/repo1/deleted_branches/%d/restore?token=%s", deletedBranch.ID, token)
+		resp = session.MakeRequest(t, req, http.StatusCreated)
+		var branch api.Branch
+		DecodeJSON(t, resp, &branch)
+		assert.EqualValues(t, "feature/1", branch.Name)
+		assert.EqualValues(t, deletedBranch.CommitID, branch.Commit.ID)
+		testAPIGetBranch(t, "feature/1", true)
+
+		// The restored branch is no longer a deleted branch
+		req = NewRequestf(t, "POST", "/api/v1/repos/user2/repo1/deleted_branches/%d/restore?token=%s", deletedBranch.ID, token)
+		session.MakeRequest(t, req, http.StatusNotFound)
+
+		// The commit of the deleted branch of the fixtures does not exist
+		req = NewRequestf(t, "POST", "/api/v1/repos/user2/repo1/deleted_branches/1/restore?token=%s", token)
+		session.MakeRequest(t, req, http.StatusNotFound)
+	})
+}
@@ -0,0 +1,66 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIETag(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	for _, url := range []string{
+		"/api/v1/repos/user2/repo1",
+		"/api/v1/repos/user2/repo1/issues?state=all",
+		"/api/v1/repos/user2/repo1/releases",
+	} {
+		resp := MakeRequest(t, NewRequest(t, "GET", url), http.StatusOK)
+		etag := resp.Header().Get("ETag")
+		assert.NotEmpty(t, etag, url)
+
+		req := NewRequest(t, "GET", url)
+		req.Header.Set("If-None-Match", etag)
+		resp = MakeRequest(t, req, http.StatusNotModified)
+		assert.Empty(t, resp.Body.String(), url)
+
+		req = NewRequest(t, "GET", url)
+		req.Header.Set("If-None-Match", `"outdated"`)
+		MakeRequest(t, req, http.StatusOK)
+	}
+}
+
+func TestAPILastModified(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	resp := MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/user2/repo1/issues/1"), http.StatusOK)
+	lastModified := resp.Header().Get("Last-Modified")
+	etag := resp.Header().Get("ETag")
+	assert.NotEmpty(t, lastModified)
+
+	req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1/issues/1")
+	req.Header.Set("If-Modified-Since", lastModified)
+	MakeRequest(t, req, http.StatusNotModified)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	title := "new title"
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1/issues/1?token="+token, &api.EditIssueOption{
+		Title: title,
+	})
+	session.MakeRequest(t, req, http.StatusCreated)
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/issues/1")
+	req.Header.Set("If-None-Match", etag)
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.NotEqual(t, etag, resp.Header().Get("ETag"))
+	var apiIssue api.Issue
+	DecodeJSON(t, resp, &apiIssue)
+	assert.Equal(t, title, apiIssue.Title)
+}
@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 
 	"github.com/stretchr/testify/assert"
@@ -79,6 +80,64 @@ func TestAPICreateIssue(t *testing.T) {
 	assert.Equal(t, repoBefore.NumClosedIssues, repoAfter.NumClosedIssues)
 }
 
+func TestAPICreateIssues(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	repoBefore := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	urlStr := "/api/v1/repos/user2/repo1/issues/batch?token=" + token
+
+	// none of the issues is created if one is invalid
+	req := NewRequestWithJSON(t, "POST", urlStr, &api.CreateIssuesOption{Issues: []*api.CreateIssueOption{
+		{Title: "valid issue"},
+		{Title: "unknown label", Labels: []int64{1, 999}},
+		{Title: "unknown assignee", Assignees: []string{"not-a-user"}},
+		{Title: "unknown milestone", Milestone: 999},
+	}})
+	resp := session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	var results []*api.CreateIssueResult
+	DecodeJSON(t, resp, &results)
+	if assert.Len(t, results, 4) {
+		assert.Empty(t, results[0].Error)
+		assert.Nil(t, results[0].Issue)
+		assert.Equal(t, "Label does not exist: [id: 999]", results[1].Error)
+		assert.Contains(t, results[2].Error, "Assignee does not exist")
+		assert.Equal(t, "Milestone does not exist: [id: 999]", results[3].Error)
+	}
+	models.AssertNotExistsBean(t, &models.Issue{RepoID: 1, Title: "valid issue"})
+
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.CreateIssuesOption{Issues: []*api.CreateIssueOption{
+		{Title: "first issue", Labels: []int64{1}, Milestone: 1},
+		{Title: "second issue", Assignees: []string{"user2"}, Closed: true},
+	}})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	DecodeJSON(t, resp, &results)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "first issue", results[0].Issue.Title)
+		assert.EqualValues(t, 6, results[0].Issue.Index)
+		if assert.Len(t, results[0].Issue.Labels, 1) {
+			assert.EqualValues(t, 1, results[0].Issue.Labels[0].ID)
+		}
+		assert.EqualValues(t, 1, results[0].Issue.Milestone.ID)
+		assert.Equal(t, "second issue", results[1].Issue.Title)
+		assert.Equal(t, api.StateClosed, results[1].Issue.State)
+		if assert.Len(t, results[1].Issue.Assignees, 1) {
+			assert.Equal(t, "user2", results[1].Issue.Assignees[0].UserName)
+		}
+	}
+
+	repoAfter := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	assert.Equal(t, repoBefore.NumIssues+2, repoAfter.NumIssues)
+	assert.Equal(t, repoBefore.NumClosedIssues+1, repoAfter.NumClosedIssues)
+
+	defer func(max int) {
+		setting.API.MaxBatchIssues = max
+	}(setting.API.MaxBatchIssues)
+	setting.API.MaxBatchIssues = 1
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+}
+
 func TestAPIEditIssue(t *testing.T) {
 	defer prepareTestEnv(t)()
 
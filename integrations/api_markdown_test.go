@@ -0,0 +1,48 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRenderMarkdownWithContext(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	render := func(t *testing.T, token, mode, context string) string {
+		urlStr := "/api/v1/markdown"
+		if token != "" {
+			urlStr += "?token=" + token
+		}
+		req := NewRequestWithJSON(t, "POST", urlStr, &api.MarkdownOption{
+			Text:    "Fixes #1",
+			Mode:    mode,
+			Context: context,
+		})
+		resp := MakeRequest(t, req, http.StatusOK)
+		return resp.Body.String()
+	}
+	issueLink := `href="` + setting.AppURL + `user2/repo1/issues/1"`
+
+	assert.Contains(t, render(t, "", "gfm", "user2/repo1"), issueLink)
+	assert.Contains(t, render(t, "", "comment", setting.AppURL+"user2/repo1/src/branch/master"), issueLink)
+	assert.NotContains(t, render(t, "", "markdown", "user2/repo1"), issueLink)
+
+	// The metas of private repositories are only used for users who can read them
+	privateLink := `href="` + setting.AppURL + `user2/repo2/issues/1"`
+	assert.NotContains(t, render(t, "", "gfm", "user2/repo2"), privateLink)
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	assert.Contains(t, render(t, token, "gfm", "user2/repo2"), privateLink)
+
+	// Unknown repositories are ignored
+	assert.NotContains(t, render(t, "", "gfm", "user2/repo-not-exist"), "user2/repo-not-exist/issues/1")
+}
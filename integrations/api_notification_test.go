@@ -114,6 +114,26 @@ func TestAPINotification(t *testing.T) {
 	thread5 = models.AssertExistsAndLoadBean(t, &models.Notification{ID: 5}).(*models.Notification)
 	assert.Equal(t, models.NotificationStatusRead, thread5.Status)
 
+	// -- /notifications/threads/{id}/subscription --
+	req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/notifications/threads/%d/subscription?token=%s", 1, token))
+	session.MakeRequest(t, req, http.StatusForbidden)
+
+	req = NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/notifications/threads/%d/subscription?token=%s", thread5.ID, token))
+	session.MakeRequest(t, req, http.StatusNoContent)
+	var watchInfo api.WatchInfo
+	req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/notifications/threads/%d/subscription?token=%s", thread5.ID, token))
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &watchInfo)
+	assert.False(t, watchInfo.Subscribed)
+	assert.True(t, watchInfo.Ignored)
+
+	req = NewRequest(t, "PUT", fmt.Sprintf("/api/v1/notifications/threads/%d/subscription?token=%s", thread5.ID, token))
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &watchInfo)
+	assert.True(t, watchInfo.Subscribed)
+	assert.EqualValues(t, thread5.APIURL()+"/subscription", watchInfo.URL)
+	models.AssertExistsAndLoadBean(t, &models.IssueWatch{UserID: user2.ID, IssueID: thread5.IssueID, IsWatching: true})
+
 	// -- check notifications --
 	req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/notifications/new?token=%s", token))
 	resp = session.MakeRequest(t, req, http.StatusOK)
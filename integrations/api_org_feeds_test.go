@@ -0,0 +1,75 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIOrgActivityFeeds(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	var activities []*api.Activity
+	req := NewRequestf(t, "GET", "/api/v1/orgs/user3/activities/feeds?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &activities)
+	if assert.Len(t, activities, 1) {
+		assert.EqualValues(t, 2, activities[0].ID)
+		assert.Equal(t, "rename_repo", activities[0].OpType)
+		assert.Equal(t, "user2", activities[0].ActUser.UserName)
+		assert.Equal(t, "repo3", activities[0].Repo.Name)
+	}
+
+	// the actions of the private repositories are hidden from the other users
+	req = NewRequest(t, "GET", "/api/v1/orgs/user3/activities/feeds")
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &activities)
+	assert.Len(t, activities, 0)
+
+	req = NewRequestf(t, "GET", "/api/v1/orgs/user3/activities/feeds?actor=user4&token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &activities)
+	assert.Len(t, activities, 0)
+
+	req = NewRequestf(t, "GET", "/api/v1/orgs/user3/activities/feeds?actor=user2&type=rename_repo&type=create_issue&token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &activities)
+	assert.Len(t, activities, 1)
+
+	req = NewRequestf(t, "GET", "/api/v1/orgs/user3/activities/feeds?since=2000-01-01T00:00:00Z&before=2001-01-01T00:00:00Z&token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &activities)
+	assert.Len(t, activities, 0)
+
+	req = NewRequestf(t, "GET", "/api/v1/orgs/user3/activities/feeds?actor=not-a-user&token=%s", token)
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	req = NewRequestf(t, "GET", "/api/v1/orgs/user3/activities/feeds?type=unknown&token=%s", token)
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	req = NewRequestf(t, "GET", "/api/v1/orgs/user3/activities/feeds?since=yesterday&token=%s", token)
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// publishing a release is shown in the feeds
+	org := models.AssertExistsAndLoadBean(t, &models.User{ID: 3}).(*models.User)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 3}).(*models.Repository)
+	createNewReleaseUsingAPI(t, session, token, org, repo, "v1.0", "master", "First release", "")
+
+	req = NewRequestf(t, "GET", "/api/v1/orgs/user3/activities/feeds?type=publish_release&token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &activities)
+	if assert.Len(t, activities, 1) {
+		assert.Equal(t, "publish_release", activities[0].OpType)
+		assert.Equal(t, "v1.0", activities[0].RefName)
+		assert.Equal(t, "v1.0|First release", activities[0].Content)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIListPagination(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	t.Run("Branches", func(t *testing.T) {
+		req := AddBasicAuthHeader(NewRequest(t, "GET", "/api/v1/repos/user2/repo1/branches?limit=2&page=2"), "user2")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var branches []*api.Branch
+		DecodeJSON(t, resp, &branches)
+		assert.Len(t, branches, 2)
+		assert.Equal(t, "6", resp.Header().Get("X-Total-Count"))
+		link := resp.Header().Get("Link")
+		assert.Contains(t, link, `page=3>; rel="next"`)
+		assert.Contains(t, link, `page=1>; rel="prev"`)
+
+		req = AddBasicAuthHeader(NewRequest(t, "GET", "/api/v1/repos/user2/repo1/branches?limit=4&page=2"), "user2")
+		resp = MakeRequest(t, req, http.StatusOK)
+		DecodeJSON(t, resp, &branches)
+		assert.Len(t, branches, 2)
+		assert.NotContains(t, resp.Header().Get("Link"), `rel="next"`)
+	})
+
+	t.Run("Hooks", func(t *testing.T) {
+		req := AddBasicAuthHeader(NewRequest(t, "GET", "/api/v1/repos/user2/repo1/hooks?limit=1"), "user2")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var hooks []*api.Hook
+		DecodeJSON(t, resp, &hooks)
+		assert.Len(t, hooks, 1)
+		assert.Equal(t, "2", resp.Header().Get("X-Total-Count"))
+		assert.Contains(t, resp.Header().Get("Link"), `page=2>; rel="next"`)
+	})
+
+	t.Run("Tags", func(t *testing.T) {
+		req := AddBasicAuthHeader(NewRequest(t, "GET", "/api/v1/repos/user2/repo1/tags"), "user2")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var tags []*api.Tag
+		DecodeJSON(t, resp, &tags)
+		assert.Len(t, tags, 1)
+		assert.Equal(t, "1", resp.Header().Get("X-Total-Count"))
+		assert.Empty(t, resp.Header().Get("Link"))
+	})
+
+	t.Run("Releases", func(t *testing.T) {
+		req := AddBasicAuthHeader(NewRequest(t, "GET", "/api/v1/repos/user2/repo1/releases?per_page=1"), "user2")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var releases []*api.Release
+		DecodeJSON(t, resp, &releases)
+		assert.Len(t, releases, 1)
+		assert.Equal(t, "1", resp.Header().Get("X-Total-Count"))
+		assert.Empty(t, resp.Header().Get("Link"))
+	})
+
+	t.Run("Collaborators", func(t *testing.T) {
+		req := AddBasicAuthHeader(NewRequest(t, "GET", "/api/v1/repos/user5/repo4/collaborators?limit=1"), "user5")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var users []*api.User
+		DecodeJSON(t, resp, &users)
+		assert.Len(t, users, 1)
+		assert.Equal(t, "2", resp.Header().Get("X-Total-Count"))
+		assert.Contains(t, resp.Header().Get("Link"), `page=2>; rel="last"`)
+	})
+}
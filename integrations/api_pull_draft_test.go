@@ -0,0 +1,84 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIPullDraft(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session)
+		testEditFileToNewBranch(t, session, "user2", "repo1", "master", "draft", "README.md", "draft")
+
+		req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/pulls?token="+token, &api.CreatePullRequestOption{
+			Head:  "draft",
+			Base:  "master",
+			Title: "draft pull request",
+			Draft: true,
+		})
+		resp := session.MakeRequest(t, req, http.StatusCreated)
+		var pull api.PullRequest
+		DecodeJSON(t, resp, &pull)
+		assert.True(t, pull.Draft)
+		assert.False(t, pull.Mergeable)
+		issue := models.AssertExistsAndLoadBean(t, &models.Issue{RepoID: 1, Index: pull.Index}).(*models.Issue)
+
+		// The reviewers aren't notified while the pull request is a draft
+		link := path.Join("user2", "repo1", "issues", "request_review")
+		req = NewRequestWithValues(t, "POST", link, map[string]string{
+			"_csrf":     GetCSRF(t, session, path.Join("user2", "repo1", "pulls", fmt.Sprint(pull.Index))),
+			"issue_ids": fmt.Sprint(issue.ID),
+			"id":        "5",
+			"is_add":    "add",
+		})
+		session.MakeRequest(t, req, http.StatusOK)
+		request := models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: issue.ID, Type: models.CommentTypeReviewRequest, AssigneeID: 5}).(*models.Comment)
+		time.Sleep(500 * time.Millisecond)
+		models.AssertNotExistsBean(t, &models.Notification{UserID: 5, IssueID: issue.ID})
+
+		// Drafts can't be merged
+		mergeURL := fmt.Sprintf("/api/v1/repos/user2/repo1/pulls/%d/merge?token=%s", pull.Index, token)
+		req = NewRequestWithJSON(t, "POST", mergeURL, &auth.MergePullRequestForm{Do: string(models.MergeStyleMerge)})
+		session.MakeRequest(t, req, http.StatusMethodNotAllowed)
+
+		// Only the poster and the users who can write pull requests can mark it as ready for review
+		readyURL := fmt.Sprintf("/api/v1/repos/user2/repo1/pulls/%d/ready_for_review", pull.Index)
+		session4 := loginUser(t, "user4")
+		req = NewRequest(t, "POST", readyURL+"?token="+getTokenForLoggedInUser(t, session4))
+		session4.MakeRequest(t, req, http.StatusForbidden)
+
+		req = NewRequest(t, "POST", readyURL+"?token="+token)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		DecodeJSON(t, resp, &pull)
+		assert.False(t, pull.Draft)
+		models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: issue.ID, Type: models.CommentTypePullReadyForReview, PosterID: 2})
+		session.MakeRequest(t, NewRequest(t, "POST", readyURL+"?token="+token), http.StatusConflict)
+
+		// The pending review requests are notified once it is ready
+		for i := 0; i < 50; i++ {
+			if models.BeanExists(t, &models.Notification{UserID: 5, IssueID: issue.ID, CommentID: request.ID}) {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		models.AssertExistsAndLoadBean(t, &models.Notification{UserID: 5, IssueID: issue.ID, CommentID: request.ID})
+
+		req = NewRequestWithJSON(t, "POST", mergeURL, &auth.MergePullRequestForm{Do: string(models.MergeStyleMerge)})
+		session.MakeRequest(t, req, http.StatusOK)
+	})
+}
@@ -118,3 +118,59 @@ func TestAPIPullReview(t *testing.T) {
 	req = NewRequestf(t, http.MethodDelete, "/api/v1/repos/%s/%s/pulls/%d/reviews/%d?token=%s", repo.OwnerName, repo.Name, pullIssue.Index, review.ID, token)
 	resp = session.MakeRequest(t, req, http.StatusNoContent)
 }
+
+func TestAPIPullReviewThreads(t *testing.T) {
+	defer prepareTestEnv(t)()
+	pullIssue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 2}).(*models.Issue)
+	assert.NoError(t, pullIssue.LoadAttributes())
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: pullIssue.RepoID}).(*models.Repository)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	threadsURL := fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d/threads", repo.OwnerName, repo.Name, pullIssue.Index)
+
+	// the thread of the pending review of user1 isn't listed
+	req := NewRequestf(t, "GET", "%s?token=%s", threadsURL, token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var threads []*api.PullReviewThread
+	DecodeJSON(t, resp, &threads)
+	if assert.Len(t, threads, 1) {
+		assert.EqualValues(t, 5, threads[0].ID)
+		assert.EqualValues(t, "README.md", threads[0].Path)
+		assert.EqualValues(t, 4, threads[0].OldLineNum)
+		assert.False(t, threads[0].Resolved)
+		assert.Nil(t, threads[0].ResolvedBy)
+		assert.Len(t, threads[0].Comments, 2)
+	}
+
+	req = NewRequestf(t, "GET", "%s/4?token=%s", threadsURL, token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequestf(t, "POST", "%s/5/resolve?token=%s", threadsURL, token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var thread api.PullReviewThread
+	DecodeJSON(t, resp, &thread)
+	assert.True(t, thread.Resolved)
+	if assert.NotNil(t, thread.ResolvedBy) {
+		assert.Equal(t, "user2", thread.ResolvedBy.UserName)
+	}
+
+	req = NewRequestf(t, "GET", "%s/5?token=%s", threadsURL, token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	thread = api.PullReviewThread{}
+	DecodeJSON(t, resp, &thread)
+	assert.True(t, thread.Resolved)
+
+	// user4 can neither write to the repository nor review officially
+	session4 := loginUser(t, "user4")
+	token4 := getTokenForLoggedInUser(t, session4)
+	req = NewRequestf(t, "POST", "%s/5/unresolve?token=%s", threadsURL, token4)
+	session4.MakeRequest(t, req, http.StatusForbidden)
+
+	req = NewRequestf(t, "POST", "%s/5/unresolve?token=%s", threadsURL, token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	thread = api.PullReviewThread{}
+	DecodeJSON(t, resp, &thread)
+	assert.False(t, thread.Resolved)
+	assert.Nil(t, thread.ResolvedBy)
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/routes"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRateLimit(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	oldAPI := setting.API
+	defer func() {
+		setting.API = oldAPI
+	}()
+	setting.API.EnableRateLimit = true
+	setting.API.RateLimitPeriod = time.Hour
+	setting.API.RateLimitPerIP = 2
+	setting.API.RateLimitPerUser = 3
+
+	request := func(ip, token string, status int) *http.Response {
+		req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1?token="+token)
+		req.Header.Set("X-Real-IP", ip)
+		return MakeRequest(t, req, status).Result()
+	}
+
+	resp := request("10.0.0.1", "", http.StatusOK)
+	assert.Equal(t, "2", resp.Header.Get("X-RateLimit-Limit"))
+	assert.Equal(t, "1", resp.Header.Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, resp.Header.Get("X-RateLimit-Reset"))
+	resp = request("10.0.0.1", "", http.StatusOK)
+	assert.Equal(t, "0", resp.Header.Get("X-RateLimit-Remaining"))
+	resp = request("10.0.0.1", "", http.StatusTooManyRequests)
+	assert.Equal(t, "0", resp.Header.Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+
+	// another IP address has its own limit
+	request("10.0.0.2", "", http.StatusOK)
+
+	// signed in users are limited whatever their IP address
+	token := getTokenForLoggedInUser(t, loginUser(t, "user2"))
+	resp = request("10.0.0.1", token, http.StatusOK)
+	assert.Equal(t, "3", resp.Header.Get("X-RateLimit-Limit"))
+	assert.Equal(t, "2", resp.Header.Get("X-RateLimit-Remaining"))
+	request("10.0.0.3", token, http.StatusOK)
+	request("10.0.0.4", token, http.StatusOK)
+	request("10.0.0.5", token, http.StatusTooManyRequests)
+}
+
+func TestAPIGraphQLRateLimit(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	oldAPI := setting.API
+	defer func() {
+		setting.API = oldAPI
+		mac = routes.NewMacaron()
+		routes.RegisterRoutes(mac)
+	}()
+	setting.API.EnableGraphQL = true
+	setting.API.EnableRateLimit = true
+	setting.API.RateLimitPeriod = time.Hour
+	setting.API.RateLimitPerIP = 2
+	mac = routes.NewMacaron()
+	routes.RegisterRoutes(mac)
+
+	// the GraphQL queries share the limit of the other API requests
+	request := func(status int) *http.Response {
+		req := NewRequestWithJSON(t, "POST", "/api/graphql", map[string]string{
+			"query": `{repository(owner: "user2", name: "repo1") {name}}`,
+		})
+		req.Header.Set("X-Real-IP", "10.1.0.1")
+		return MakeRequest(t, req, status).Result()
+	}
+	req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1")
+	req.Header.Set("X-Real-IP", "10.1.0.1")
+	MakeRequest(t, req, http.StatusOK)
+	resp := request(http.StatusOK)
+	assert.Equal(t, "0", resp.Header.Get("X-RateLimit-Remaining"))
+	request(http.StatusTooManyRequests)
+}
@@ -5,12 +5,21 @@
 package integrations
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 	api "code.gitea.io/gitea/modules/structs"
 
 	"github.com/stretchr/testify/assert"
@@ -120,3 +129,485 @@ func TestAPICreateReleaseToDefaultBranchOnExistingTag(t *testing.T) {
 
 	createNewReleaseUsingAPI(t, session, token, owner, repo, "v0.0.1", "", "v0.0.1", "test")
 }
+
+func TestAPIGetLatestRelease(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	owner := models.AssertExistsAndLoadBean(t, &models.User{ID: repo.OwnerID}).(*models.User)
+	session := loginUser(t, owner.LowerName)
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/%s/%s/releases/latest", owner.Name, repo.Name)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+
+	var release api.Release
+	DecodeJSON(t, resp, &release)
+	assert.Equal(t, "v1.1", release.TagName)
+	assert.False(t, release.IsDraft)
+	assert.False(t, release.IsPrerelease)
+
+	// repository without releases
+	req = NewRequestf(t, "GET", "/api/v1/repos/%s/%s/releases/latest", owner.Name, "repo15")
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestAPIReleaseAttachmentUpload(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	owner := models.AssertExistsAndLoadBean(t, &models.User{ID: repo.OwnerID}).(*models.User)
+	session := loginUser(t, owner.LowerName)
+	token := getTokenForLoggedInUser(t, session)
+
+	content := []byte("PK\x03\x04 resumable upload content")
+	urlStr := fmt.Sprintf("/api/v1/repos/%s/%s/releases/1/assets/uploads?token=%s", owner.Name, repo.Name, token)
+	req := NewRequestWithJSON(t, "POST", urlStr, &api.CreateAttachmentUploadOptions{
+		Name: "archive.zip",
+		Size: int64(len(content)),
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+
+	var upload api.AttachmentUpload
+	DecodeJSON(t, resp, &upload)
+	assert.EqualValues(t, 0, upload.Offset)
+	assert.False(t, upload.Complete)
+	assert.True(t, upload.Expires.After(time.Now()))
+
+	// the announced size is capped
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.CreateAttachmentUploadOptions{
+		Name: "huge.zip",
+		Size: (setting.Repository.Release.MaxUploadSize + 1) * 1024 * 1024,
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	uploadURL := fmt.Sprintf("/api/v1/repos/%s/%s/releases/1/assets/uploads/%s?token=%s", owner.Name, repo.Name, upload.UUID, token)
+	req = NewRequestWithBody(t, "PATCH", uploadURL, bytes.NewReader(content[:10]))
+	req.Header.Set("Upload-Offset", "0")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &upload)
+	assert.EqualValues(t, 10, upload.Offset)
+
+	// the upload can't be continued through another repository
+	req = NewRequestWithBody(t, "PATCH", fmt.Sprintf("/api/v1/repos/%s/repo16/releases/1/assets/uploads/%s?token=%s", owner.Name, upload.UUID, token), bytes.NewReader(content[10:]))
+	req.Header.Set("Upload-Offset", "10")
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	// resuming at a wrong offset is rejected
+	req = NewRequestWithBody(t, "PATCH", uploadURL, bytes.NewReader(content[5:]))
+	req.Header.Set("Upload-Offset", "5")
+	resp = session.MakeRequest(t, req, http.StatusConflict)
+	assert.Equal(t, "10", resp.Header().Get("Upload-Offset"))
+
+	req = NewRequest(t, "GET", uploadURL)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, "10", resp.Header().Get("Upload-Offset"))
+
+	req = NewRequestWithBody(t, "PATCH", uploadURL, bytes.NewReader(content[10:]))
+	req.Header.Set("Upload-Offset", "10")
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+
+	var attachment api.Attachment
+	DecodeJSON(t, resp, &attachment)
+	assert.Equal(t, "archive.zip", attachment.Name)
+	assert.EqualValues(t, len(content), attachment.Size)
+
+	// the upload is gone once completed
+	req = NewRequest(t, "GET", uploadURL)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestAPIReleaseWebhook(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	owner := models.AssertExistsAndLoadBean(t, &models.User{ID: repo.OwnerID}).(*models.User)
+	session := loginUser(t, owner.LowerName)
+	token := getTokenForLoggedInUser(t, session)
+
+	hook := &models.Webhook{
+		RepoID:      repo.ID,
+		URL:         "http://localhost:1/release-hook",
+		ContentType: models.ContentTypeJSON,
+		HookEvent: &models.HookEvent{
+			ChooseEvents: true,
+			HookEvents:   models.HookEvents{Release: true},
+		},
+		IsActive:     true,
+		HookTaskType: models.GITEA,
+	}
+	assert.NoError(t, hook.UpdateEvent())
+	assert.NoError(t, models.CreateWebhook(hook))
+
+	releaseActions := func() []api.HookReleaseAction {
+		tasks, err := models.HookTasks(hook.ID, 1)
+		assert.NoError(t, err)
+		actions := make([]api.HookReleaseAction, 0, len(tasks))
+		for i := len(tasks) - 1; i >= 0; i-- {
+			assert.EqualValues(t, models.HookEventRelease, tasks[i].EventType)
+			var payload api.ReleasePayload
+			assert.NoError(t, json.Unmarshal([]byte(tasks[i].PayloadContent), &payload))
+			assert.EqualValues(t, owner.ID, payload.Sender.ID)
+			if payload.Action == api.HookReleaseAssetUploaded || payload.Action == api.HookReleaseAssetDeleted {
+				if assert.NotNil(t, payload.Asset) {
+					assert.Equal(t, "archive.zip", payload.Asset.Name)
+					assert.NotEmpty(t, payload.Asset.Size)
+					assert.Len(t, payload.Asset.SHA256, 64)
+				}
+			} else {
+				assert.Nil(t, payload.Asset)
+			}
+			actions = append(actions, payload.Action)
+		}
+		return actions
+	}
+
+	urlStr := fmt.Sprintf("/api/v1/repos/%s/%s/releases?token=%s", owner.Name, repo.Name, token)
+	req := NewRequestWithJSON(t, "POST", urlStr, &api.CreateReleaseOption{
+		TagName: "v0.0.2",
+		Title:   "v0.0.2",
+		IsDraft: true,
+		Target:  "master",
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var release api.Release
+	DecodeJSON(t, resp, &release)
+	assert.Empty(t, releaseActions(), "drafts must not be announced")
+
+	urlStr = fmt.Sprintf("/api/v1/repos/%s/%s/releases/%d?token=%s", owner.Name, repo.Name, release.ID, token)
+	isDraft := false
+	req = NewRequestWithJSON(t, "PATCH", urlStr, &api.EditReleaseOption{IsDraft: &isDraft})
+	session.MakeRequest(t, req, http.StatusOK)
+	req = NewRequestWithJSON(t, "PATCH", urlStr, &api.EditReleaseOption{Note: "updated"})
+	session.MakeRequest(t, req, http.StatusOK)
+
+	content := []byte("PK\x03\x04 release asset content")
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/%s/%s/releases/%d/assets/uploads?token=%s", owner.Name, repo.Name, release.ID, token),
+		&api.CreateAttachmentUploadOptions{Name: "archive.zip", Size: int64(len(content))})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var upload api.AttachmentUpload
+	DecodeJSON(t, resp, &upload)
+	req = NewRequestWithBody(t, "PATCH", fmt.Sprintf("/api/v1/repos/%s/%s/releases/%d/assets/uploads/%s?token=%s", owner.Name, repo.Name, release.ID, upload.UUID, token),
+		bytes.NewReader(content))
+	req.Header.Set("Upload-Offset", "0")
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var attachment api.Attachment
+	DecodeJSON(t, resp, &attachment)
+	req = NewRequestf(t, "DELETE", "/api/v1/repos/%s/%s/releases/%d/assets/%d?token=%s", owner.Name, repo.Name, release.ID, attachment.ID, token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+
+	req = NewRequest(t, "DELETE", urlStr)
+	session.MakeRequest(t, req, http.StatusNoContent)
+
+	assert.Equal(t, []api.HookReleaseAction{
+		api.HookReleasePublished,
+		api.HookReleaseUpdated,
+		api.HookReleaseAssetUploaded,
+		api.HookReleaseAssetDeleted,
+		api.HookReleaseDeleted,
+	}, releaseActions())
+}
+
+func TestAPIListReleasesFilters(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	owner := models.AssertExistsAndLoadBean(t, &models.User{ID: repo.OwnerID}).(*models.User)
+	session := loginUser(t, owner.LowerName)
+	token := getTokenForLoggedInUser(t, session)
+
+	for _, opts := range []api.CreateReleaseOption{
+		{TagName: "v1.2-beta", Title: "v1.2 beta", IsPrerelease: true, Target: "master"},
+		{TagName: "v2.0", Title: "v2.0", IsDraft: true, Target: "master"},
+	} {
+		req := NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/%s/%s/releases?token=%s", owner.Name, repo.Name, token), &opts)
+		session.MakeRequest(t, req, http.StatusCreated)
+	}
+
+	listTags := func(query string) []string {
+		req := NewRequestf(t, "GET", "/api/v1/repos/%s/%s/releases?token=%s&%s", owner.Name, repo.Name, token, query)
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		var releases []*api.Release
+		DecodeJSON(t, resp, &releases)
+		tags := make([]string, 0, len(releases))
+		for _, rel := range releases {
+			tags = append(tags, rel.TagName)
+		}
+		return tags
+	}
+
+	assert.ElementsMatch(t, []string{"v1.1", "v1.2-beta", "v2.0"}, listTags(""))
+	assert.ElementsMatch(t, []string{"v2.0"}, listTags("draft=true"))
+	assert.ElementsMatch(t, []string{"v1.1", "v1.2-beta"}, listTags("draft=false"))
+	assert.ElementsMatch(t, []string{"v1.2-beta"}, listTags("pre-release=true"))
+	assert.ElementsMatch(t, []string{"v1.1", "v2.0"}, listTags("q=v?.?"))
+	assert.ElementsMatch(t, []string{"v1.2-beta"}, listTags("q=beta&draft=false"))
+}
+
+func TestAPICompareReleases(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	owner := models.AssertExistsAndLoadBean(t, &models.User{ID: repo.OwnerID}).(*models.User)
+	session := loginUser(t, owner.LowerName)
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/%s/%s/releases?token=%s", owner.Name, repo.Name, token), &api.CreateReleaseOption{
+		TagName: "v1.2",
+		Title:   "v1.2",
+		Target:  "branch2",
+	})
+	session.MakeRequest(t, req, http.StatusCreated)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/%s/%s/releases/compare?head=v1.2&token=%s", owner.Name, repo.Name, token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var changelog api.ReleaseChangelog
+	DecodeJSON(t, resp, &changelog)
+	assert.Equal(t, "v1.1", changelog.Base.TagName)
+	assert.Equal(t, "v1.2", changelog.Head.TagName)
+	assert.Len(t, changelog.Commits, 2)
+	if assert.Len(t, changelog.Contributors, 1) {
+		assert.Equal(t, 2, changelog.Contributors[0].Commits)
+	}
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/%s/%s/releases/compare?token=%s", owner.Name, repo.Name, token)
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	req = NewRequestf(t, "GET", "/api/v1/repos/%s/%s/releases/compare?base=v0.9&head=v1.2&token=%s", owner.Name, repo.Name, token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestAPIYankRelease(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	owner := models.AssertExistsAndLoadBean(t, &models.User{ID: repo.OwnerID}).(*models.User)
+	session := loginUser(t, owner.LowerName)
+	token := getTokenForLoggedInUser(t, session)
+
+	urlStr := fmt.Sprintf("/api/v1/repos/%s/%s/releases/1/yank?token=%s", owner.Name, repo.Name, token)
+	req := NewRequestWithJSON(t, "POST", urlStr, &api.YankReleaseOption{})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.YankReleaseOption{Reason: "broken build"})
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var release api.Release
+	DecodeJSON(t, resp, &release)
+	assert.True(t, release.IsYanked)
+	assert.Equal(t, "broken build", release.YankedReason)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/%s/%s/releases/latest", owner.Name, repo.Name)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	// Downloads of a yanked release are kept but carry a warning
+	req = NewRequestf(t, "GET", "/%s/%s/archive/v1.1.zip", owner.Name, repo.Name)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, `299 - "release v1.1 has been yanked: broken build"`, resp.Header().Get("Warning"))
+	_, err := storage.Attachments.Save(models.AttachmentRelativePath("a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a19"), strings.NewReader("hello world"))
+	assert.NoError(t, err)
+	req = NewRequest(t, "GET", "/attachments/a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a19")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Header().Get("Warning"), "broken build")
+
+	req = NewRequest(t, "DELETE", urlStr)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &release)
+	assert.False(t, release.IsYanked)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/%s/%s/releases/latest", owner.Name, repo.Name)
+	session.MakeRequest(t, req, http.StatusOK)
+	req = NewRequestf(t, "GET", "/%s/%s/archive/v1.1.zip", owner.Name, repo.Name)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.Empty(t, resp.Header().Get("Warning"))
+}
+
+func TestAPIDeleteReleases(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	owner := models.AssertExistsAndLoadBean(t, &models.User{ID: repo.OwnerID}).(*models.User)
+	session := loginUser(t, owner.LowerName)
+	token := getTokenForLoggedInUser(t, session)
+
+	nightly1 := createNewReleaseUsingAPI(t, session, token, owner, repo, "nightly-1", "master", "nightly-1", "")
+	nightly2 := createNewReleaseUsingAPI(t, session, token, owner, repo, "nightly-2", "master", "nightly-2", "")
+
+	urlStr := fmt.Sprintf("/api/v1/repos/%s/%s/releases?token=%s", owner.Name, repo.Name, token)
+	req := NewRequest(t, "DELETE", urlStr)
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequest(t, "DELETE", urlStr+"&tag=nightly-*")
+	resp := session.MakeRequest(t, req, http.StatusAccepted)
+	var job api.ReleasesJob
+	DecodeJSON(t, resp, &job)
+
+	for i := 0; i < 50 && job.Status != "finished" && job.Status != "failed"; i++ {
+		time.Sleep(100 * time.Millisecond)
+		req = NewRequestf(t, "GET", "/api/v1/repos/%s/%s/releases/jobs/%d?token=%s", owner.Name, repo.Name, job.ID, token)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		DecodeJSON(t, resp, &job)
+	}
+	assert.Equal(t, "finished", job.Status)
+	assert.Empty(t, job.Errors)
+
+	// The tags of the deleted releases are kept
+	models.AssertExistsAndLoadBean(t, &models.Release{ID: nightly1.ID}, models.Cond("is_tag = ?", true))
+	models.AssertExistsAndLoadBean(t, &models.Release{ID: nightly2.ID}, models.Cond("is_tag = ?", true))
+	models.AssertExistsAndLoadBean(t, &models.Release{ID: 1}, models.Cond("is_tag = ?", false))
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/%s/%s/releases/jobs/%d?token=%s", owner.Name, repo.Name, job.ID+1, token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestAPIReleaseComments(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user4")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1/releases/1/comments")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var comments []*api.ReleaseComment
+	DecodeJSON(t, resp, &comments)
+	if assert.Len(t, comments, 1) {
+		assert.Equal(t, "first comment", comments[0].Body)
+		assert.Equal(t, "user2", comments[0].Poster.UserName)
+	}
+
+	urlStr := fmt.Sprintf("/api/v1/repos/user2/repo1/releases/1/comments?token=%s", token)
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.CreateReleaseCommentOption{Body: "thanks"})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var comment api.ReleaseComment
+	DecodeJSON(t, resp, &comment)
+	assert.Equal(t, "thanks", comment.Body)
+	assert.EqualValues(t, 1, comment.ReleaseID)
+	assert.Contains(t, comment.HTMLURL, "/user2/repo1/releases/tag/v1.1#releasecomment-")
+
+	urlStr = fmt.Sprintf("/api/v1/repos/user2/repo1/releases/1/comments/%d?token=%s", comment.ID, token)
+	req = NewRequestWithJSON(t, "PATCH", urlStr, &api.EditReleaseCommentOption{Body: "thanks!"})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &comment)
+	assert.Equal(t, "thanks!", comment.Body)
+
+	// Other users can't change the comment
+	session5 := loginUser(t, "user5")
+	token5 := getTokenForLoggedInUser(t, session5)
+	req = NewRequestf(t, "DELETE", "/api/v1/repos/user2/repo1/releases/1/comments/%d?token=%s", comment.ID, token5)
+	session5.MakeRequest(t, req, http.StatusForbidden)
+
+	req = NewRequest(t, "DELETE", urlStr)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.ReleaseComment{ID: comment.ID})
+}
+
+func TestAPIReleaseAttachmentRole(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	owner := models.AssertExistsAndLoadBean(t, &models.User{ID: repo.OwnerID}).(*models.User)
+	session := loginUser(t, owner.LowerName)
+	token := getTokenForLoggedInUser(t, session)
+
+	urlStr := fmt.Sprintf("/api/v1/repos/%s/%s/releases/1/assets/9?token=%s", owner.Name, repo.Name, token)
+	role := "timestamp"
+	req := NewRequestWithJSON(t, "PATCH", urlStr, &api.EditAttachmentOptions{Role: &role})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	role = "signature"
+	req = NewRequestWithJSON(t, "PATCH", urlStr, &api.EditAttachmentOptions{Role: &role})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var attachment api.Attachment
+	DecodeJSON(t, resp, &attachment)
+	assert.Equal(t, "signature", attachment.Role)
+	assert.Equal(t, "attach1", attachment.Name)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/%s/%s/releases/1", owner.Name, repo.Name)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var release api.Release
+	DecodeJSON(t, resp, &release)
+	if assert.NotNil(t, release.Verification) {
+		if assert.Len(t, release.Verification.Signatures, 1) {
+			assert.EqualValues(t, 9, release.Verification.Signatures[0].ID)
+		}
+		assert.Empty(t, release.Verification.Certificates)
+		assert.Empty(t, release.Verification.Provenance)
+	}
+	// attachments with a role are still listed as assets
+	assert.Len(t, release.Attachments, 1)
+
+	role = ""
+	req = NewRequestWithJSON(t, "PATCH", urlStr, &api.EditAttachmentOptions{Role: &role})
+	session.MakeRequest(t, req, http.StatusCreated)
+	models.AssertExistsAndLoadBean(t, &models.Attachment{ID: 9}, models.Cond("role = ?", ""))
+}
+
+func TestAPIReleaseTagVerification(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	owner := models.AssertExistsAndLoadBean(t, &models.User{ID: repo.OwnerID}).(*models.User)
+	session := loginUser(t, owner.LowerName)
+	token := getTokenForLoggedInUser(t, session)
+
+	// Generate a signing key without passphrase in a new GPG home
+	tmpDir, err := ioutil.TempDir("", "temp-gpg")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, os.Chmod(tmpDir, 0700))
+
+	oldGNUPGHome := os.Getenv("GNUPGHOME")
+	assert.NoError(t, os.Setenv("GNUPGHOME", tmpDir))
+	defer os.Setenv("GNUPGHOME", oldGNUPGHome)
+
+	_, _, err = process.GetManager().Exec("gpg --quick-gen-key", "gpg", "--batch", "--passphrase", "", "--quick-gen-key", "gitea <gitea@fake.local>", "rsa2048", "sign", "never")
+	assert.NoError(t, err)
+
+	oldSigning := setting.Repository.Signing
+	defer func() {
+		setting.Repository.Signing = oldSigning
+	}()
+	setting.Repository.Signing.SigningKey = "gitea@fake.local"
+	setting.Repository.Signing.SigningName = "gitea"
+	setting.Repository.Signing.SigningEmail = "gitea@fake.local"
+	setting.Repository.Signing.Releases = []string{"always"}
+
+	signed := createNewReleaseUsingAPI(t, session, token, owner, repo, "v-signed", "master", "v-signed", "signed release")
+	if assert.NotNil(t, signed.Verification) && assert.NotNil(t, signed.Verification.Tag) {
+		assert.True(t, signed.Verification.Tag.Verified)
+	}
+
+	setting.Repository.Signing.Releases = []string{"never"}
+	unsigned := createNewReleaseUsingAPI(t, session, token, owner, repo, "v-unsigned", "master", "v-unsigned", "unsigned release")
+
+	getRelease := func(id int64) *api.Release {
+		req := NewRequestf(t, "GET", "/api/v1/repos/%s/%s/releases/%d?token=%s", owner.Name, repo.Name, id, token)
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		var release api.Release
+		DecodeJSON(t, resp, &release)
+		return &release
+	}
+
+	release := getRelease(signed.ID)
+	if assert.NotNil(t, release.Verification) && assert.NotNil(t, release.Verification.Tag) {
+		assert.True(t, release.Verification.Tag.Verified)
+		if assert.NotNil(t, release.Verification.Tag.Signer) {
+			assert.Equal(t, "gitea@fake.local", release.Verification.Tag.Signer.Email)
+		}
+	}
+
+	release = getRelease(unsigned.ID)
+	if assert.NotNil(t, release.Verification) && assert.NotNil(t, release.Verification.Tag) {
+		assert.False(t, release.Verification.Tag.Verified)
+		assert.Equal(t, "gpg.error.not_signed_commit", release.Verification.Tag.Reason)
+	}
+
+	// The tags aren't verified when listing releases
+	req := NewRequestf(t, "GET", "/api/v1/repos/%s/%s/releases?token=%s", owner.Name, repo.Name, token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var releases []*api.Release
+	DecodeJSON(t, resp, &releases)
+	assert.Len(t, releases, 3)
+	for _, rel := range releases {
+		if assert.NotNil(t, rel.Verification) {
+			assert.Nil(t, rel.Verification.Tag)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoRequestArchive(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequest(t, "POST", "/api/v1/repos/user2/repo1/archive/not-a-branch.zip?token="+token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	var archive api.RepoArchive
+	for start := time.Now(); time.Since(start) < 10*time.Second; time.Sleep(100 * time.Millisecond) {
+		req = NewRequest(t, "POST", "/api/v1/repos/user2/repo1/archive/master.zip?token="+token)
+		resp := session.MakeRequest(t, req, NoExpectedStatus)
+		DecodeJSON(t, resp, &archive)
+		if archive.Status == "complete" {
+			assert.Equal(t, http.StatusOK, resp.Code)
+			break
+		}
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		assert.Equal(t, "pending", archive.Status)
+	}
+	assert.Equal(t, "complete", archive.Status)
+	assert.Equal(t, setting.AppURL+"api/v1/repos/user2/repo1/archive/master.zip", archive.DownloadURL)
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/archive/master.zip?token="+token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	assert.NotEmpty(t, resp.Body.Bytes())
+
+	// The web UI asks whether the archive is complete before downloading it
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/archive/master.zip", map[string]string{
+		"_csrf": GetCSRF(t, session, "/user2/repo1"),
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var result map[string]interface{}
+	DecodeJSON(t, resp, &result)
+	assert.Equal(t, true, result["complete"])
+}
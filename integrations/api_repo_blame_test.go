@@ -0,0 +1,49 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoBlame(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	for _, ref := range []string{
+		"master", // Branch
+		"v1.1",   // Tag
+		"65f1bf27bc3bf70f64657658635e66094edbcb4d", // Commit
+	} {
+		req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/blame/%s/README.md", ref)
+		resp := MakeRequest(t, req, http.StatusOK)
+		var ranges []*api.BlameRange
+		DecodeJSON(t, resp, &ranges)
+		if assert.Len(t, ranges, 1) {
+			assert.Equal(t, 1, ranges[0].StartLine)
+			assert.Equal(t, 3, ranges[0].EndLine)
+			assert.Equal(t, []string{"# repo1", "", "Description for repo1"}, ranges[0].Lines)
+			assert.Equal(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", ranges[0].Commit.SHA)
+			assert.Equal(t, setting.AppURL+"api/v1/repos/user2/repo1/git/commits/65f1bf27bc3bf70f64657658635e66094edbcb4d", ranges[0].Commit.URL)
+			assert.Equal(t, "user1", ranges[0].Commit.Author.Name)
+			assert.Equal(t, "Initial commit", ranges[0].Commit.Summary)
+			assert.True(t, ranges[0].Commit.Age > 0)
+		}
+	}
+
+	req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1/blame/master/not-exist.md")
+	MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/blame/not-exist/README.md")
+	MakeRequest(t, req, http.StatusNotFound)
+
+	// private repository
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo2/blame/master/README.md")
+	MakeRequest(t, req, http.StatusNotFound)
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoBundle(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1/bundle?refs=master&refs=not-a-branch&token="+token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	// The bundle of a small repository is downloaded right away
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/bundle?refs=master&refs=v1.1&token="+token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	assert.True(t, strings.HasPrefix(resp.Body.String(), "# v2 git bundle\n"))
+	assert.Contains(t, resp.Body.String(), "65f1bf27bc3bf70f64657658635e66094edbcb4d refs/heads/master\n")
+	assert.Contains(t, resp.Body.String(), "65f1bf27bc3bf70f64657658635e66094edbcb4d refs/tags/v1.1\n")
+
+	// The bundle of a large repository is generated in the background
+	defer func(minSize int64) { setting.Repository.BundleQueueMinSize = minSize }(setting.Repository.BundleQueueMinSize)
+	setting.Repository.BundleQueueMinSize = 0
+
+	var bundle api.RepoArchive
+	for start := time.Now(); time.Since(start) < 10*time.Second; time.Sleep(100 * time.Millisecond) {
+		req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/bundle?refs=branch2&token="+token)
+		resp = session.MakeRequest(t, req, NoExpectedStatus)
+		if resp.Code == http.StatusOK {
+			break
+		}
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		DecodeJSON(t, resp, &bundle)
+		assert.Equal(t, "pending", bundle.Status)
+		assert.Equal(t, setting.AppURL+"api/v1/repos/user2/repo1/bundle?refs=branch2", bundle.DownloadURL)
+	}
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), "985f0301dba5e7b34be866819cd15ad3d8f508ee refs/heads/branch2\n")
+	assert.NotContains(t, resp.Body.String(), "refs/heads/master")
+}
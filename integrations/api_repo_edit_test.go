@@ -327,3 +327,43 @@ func TestAPIRepoEdit(t *testing.T) {
 		session.MakeRequest(t, req, http.StatusForbidden)
 	})
 }
+
+func TestAPIRepoEditTrustModel(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	unknown := "unknown"
+	req := NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1?token="+token, &api.EditRepoOption{TrustModel: &unknown})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	trustModel, trustedKeys := "trustedkeys", "0123456789ABCDEF"
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1?token="+token, &api.EditRepoOption{
+		TrustModel:  &trustModel,
+		TrustedKeys: &trustedKeys,
+	})
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var repo api.Repository
+	DecodeJSON(t, resp, &repo)
+	assert.Equal(t, "trustedkeys", repo.TrustModel)
+	models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1, TrustModel: models.TrustedKeysTrustModel, TrustedKeys: trustedKeys})
+
+	// The commits report the trust model their signatures are verified with
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/commits?token="+token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var commits []api.Commit
+	DecodeJSON(t, resp, &commits)
+	assert.NotEmpty(t, commits)
+	for _, commit := range commits {
+		assert.Equal(t, "trustedkeys", commit.RepoCommit.Verification.TrustModel)
+		assert.Equal(t, "gpg.error.not_signed_commit", commit.RepoCommit.Verification.Reason)
+	}
+
+	// The default trust model is the one of the instance
+	trustModel = "default"
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1?token="+token, &api.EditRepoOption{TrustModel: &trustModel})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &repo)
+	assert.Equal(t, "collaborator", repo.TrustModel)
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/eventsource"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoStreamEvents(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session)
+
+		// Anonymous users can't listen
+		req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1/events/stream")
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		streamReq, err := http.NewRequest("GET", fmt.Sprintf("%sapi/v1/repos/user2/repo1/events/stream?token=%s", u.String(), token), nil)
+		assert.NoError(t, err)
+		// The gzip middleware holds back short responses
+		streamReq.Header.Set("Accept-Encoding", "identity")
+		resp, err := http.DefaultClient.Do(streamReq)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		lines := make(chan string, 100)
+		go func() {
+			scanner := bufio.NewScanner(resp.Body)
+			scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+			for scanner.Scan() {
+				lines <- scanner.Text()
+			}
+			close(lines)
+		}()
+		assert.Eventually(t, func() bool {
+			return eventsource.GetManager().HasRepoListeners(repo.ID)
+		}, 5*time.Second, 10*time.Millisecond)
+
+		req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/issues?token="+token, &api.CreateIssueOption{
+			Title: "streamed issue",
+		})
+		session.MakeRequest(t, req, http.StatusCreated)
+
+		var name, data string
+		timeout := time.After(10 * time.Second)
+	loop:
+		for {
+			select {
+			case line, ok := <-lines:
+				if !assert.True(t, ok, "stream closed") {
+					return
+				}
+				switch {
+				case strings.HasPrefix(line, "event: "):
+					name = strings.TrimPrefix(line, "event: ")
+				case strings.HasPrefix(line, "data: "):
+					data = strings.TrimPrefix(line, "data: ")
+				case line == "" && name != "":
+					break loop
+				}
+			case <-timeout:
+				assert.Fail(t, "no event received")
+				return
+			}
+		}
+		assert.Equal(t, "issues", name)
+		assert.Contains(t, data, `"action":"opened"`)
+		assert.Contains(t, data, `"title":"streamed issue"`)
+	})
+}
@@ -97,10 +97,11 @@ func getExpectedFileResponseForCreate(commitID, treePath string) *api.FileRespon
 			Message: "Updates README.md\n",
 		},
 		Verification: &api.PayloadCommitVerification{
-			Verified:  false,
-			Reason:    "gpg.error.not_signed_commit",
-			Signature: "",
-			Payload:   "",
+			Verified:   false,
+			Reason:     "gpg.error.not_signed_commit",
+			Signature:  "",
+			Payload:    "",
+			TrustModel: "collaborator",
 		},
 	}
 }
@@ -93,10 +93,11 @@ func getExpectedFileResponseForUpdate(commitID, treePath string) *api.FileRespon
 			Message: "My update of README.md\n",
 		},
 		Verification: &api.PayloadCommitVerification{
-			Verified:  false,
-			Reason:    "gpg.error.not_signed_commit",
-			Signature: "",
-			Payload:   "",
+			Verified:   false,
+			Reason:     "gpg.error.not_signed_commit",
+			Signature:  "",
+			Payload:    "",
+			TrustModel: "collaborator",
 		},
 	}
 }
@@ -0,0 +1,108 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getCommitMessage(t *testing.T, sha string) string {
+	gitRepo, err := git.OpenRepository(models.RepoPath("user2", "repo1"))
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+	commit, err := gitRepo.GetCommit(sha)
+	assert.NoError(t, err)
+	return commit.Message()
+}
+
+func TestAPIRepoGitCherryPick(t *testing.T) {
+	onGiteaRun(t, testAPIRepoGitCherryPick)
+}
+
+func testAPIRepoGitCherryPick(t *testing.T, u *url.URL) {
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	urlStr := "/api/v1/repos/user2/repo1/git/cherry-pick?token=" + token
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/git/cherry-pick", &api.CherryPickCommitOption{SHA: "62fb502a7172d4453f0322a2cc85bddffa57f07a"})
+	session.MakeRequest(t, req, http.StatusUnauthorized)
+
+	// check invalid requests
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.CherryPickCommitOption{SHA: "master"})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.CherryPickCommitOption{SHA: "1234567890123456789012345678901234567890"})
+	session.MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.CherryPickCommitOption{SHA: "62fb502a7172d4453f0322a2cc85bddffa57f07a", Branch: "unknown"})
+	session.MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.CherryPickCommitOption{SHA: "62fb502a7172d4453f0322a2cc85bddffa57f07a", NewBranch: "develop"})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// "add WoW File" applies cleanly onto master
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.CherryPickCommitOption{SHA: "62fb502a7172d4453f0322a2cc85bddffa57f07a"})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var result api.CherryPickCommitResponse
+	DecodeJSON(t, resp, &result)
+	assert.Equal(t, "master", result.Branch)
+	assert.Empty(t, result.Conflicts)
+	assert.Nil(t, result.PullRequest)
+	assert.Equal(t, "add WoW File\n\n(cherry picked from commit 62fb502a7172d4453f0322a2cc85bddffa57f07a)\n", getCommitMessage(t, result.Commit.SHA))
+	assert.Equal(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", result.Commit.Parents[0].SHA)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/contents/File-WoW?ref=master&token=%s", token)
+	session.MakeRequest(t, req, http.StatusOK)
+
+	// "make pull5 outdated" conflicts with master
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.CherryPickCommitOption{SHA: "985f0301dba5e7b34be866819cd15ad3d8f508ee"})
+	session.MakeRequest(t, req, http.StatusConflict)
+
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.CherryPickCommitOption{SHA: "985f0301dba5e7b34be866819cd15ad3d8f508ee", PullOnConflict: true})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	result = api.CherryPickCommitResponse{}
+	DecodeJSON(t, resp, &result)
+	assert.Equal(t, "cherry-pick-985f0301db", result.Branch)
+	assert.Equal(t, []string{"README.md"}, result.Conflicts)
+	if assert.NotNil(t, result.PullRequest) {
+		assert.Equal(t, "cherry-pick-985f0301db", result.PullRequest.Head.Ref)
+		assert.Equal(t, "master", result.PullRequest.Base.Ref)
+		assert.Equal(t, "make pull5 outdated", result.PullRequest.Title)
+	}
+}
+
+func TestAPIRepoGitRevert(t *testing.T) {
+	onGiteaRun(t, testAPIRepoGitRevert)
+}
+
+func testAPIRepoGitRevert(t *testing.T, u *url.URL) {
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/git/revert?token="+token, &api.CherryPickCommitOption{
+		SHA:       "62fb502a7172d4453f0322a2cc85bddffa57f07a",
+		Branch:    "pr-to-update",
+		NewBranch: "revert-wow",
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var result api.CherryPickCommitResponse
+	DecodeJSON(t, resp, &result)
+	assert.Equal(t, "revert-wow", result.Branch)
+	assert.Empty(t, result.Conflicts)
+	assert.Equal(t, "Revert \"add WoW File\"\n\nThis reverts commit 62fb502a7172d4453f0322a2cc85bddffa57f07a.\n", getCommitMessage(t, result.Commit.SHA))
+	// reverts are authored by the doer
+	user2 := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	assert.Equal(t, user2.GetEmail(), result.Commit.RepoCommit.Author.Email)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/contents/File-WoW?ref=revert-wow&token=%s", token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/contents/File-WoW?ref=pr-to-update&token=%s", token)
+	session.MakeRequest(t, req, http.StatusOK)
+}
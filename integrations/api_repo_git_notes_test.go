@@ -0,0 +1,71 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIReposGitNotes(t *testing.T) {
+	defer prepareTestEnv(t)()
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	// Login as User2.
+	session := loginUser(t, user.Name)
+	token := getTokenForLoggedInUser(t, session)
+
+	// check invalid requests
+	req := NewRequestf(t, "GET", "/api/v1/repos/%s/repo1/git/notes/12345?token=%s", user.Name, token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/%s/repo1/git/notes/..?token=%s", user.Name, token)
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// the commit has no note yet
+	req = NewRequestf(t, "GET", "/api/v1/repos/%s/repo1/git/notes/65f1bf27bc3bf70f64657658635e66094edbcb4d?token=%s", user.Name, token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/git/notes/master", &api.CreateNoteOption{Message: "build 42"})
+	session.MakeRequest(t, req, http.StatusUnauthorized)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/git/notes/master?token="+token, &api.CreateNoteOption{})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/git/notes/master?token="+token, &api.CreateNoteOption{Message: "build 42"})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var apiNote api.Note
+	DecodeJSON(t, resp, &apiNote)
+	assert.Equal(t, "build 42\n", apiNote.Message)
+	assert.Equal(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", apiNote.Commit.SHA)
+
+	// the note replaces the existing one
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/git/notes/master?token="+token, &api.CreateNoteOption{Message: "build 43"})
+	session.MakeRequest(t, req, http.StatusCreated)
+
+	for _, ref := range [...]string{
+		"master", // Branch
+		"65f1bf27bc3bf70f64657658635e66094edbcb4d", // full sha
+	} {
+		req = NewRequestf(t, "GET", "/api/v1/repos/%s/repo1/git/notes/%s?token=%s", user.Name, ref, token)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		apiNote = api.Note{}
+		DecodeJSON(t, resp, &apiNote)
+		assert.Equal(t, "build 43\n", apiNote.Message)
+		assert.Equal(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", apiNote.Commit.SHA)
+	}
+
+	// user4 can read the public repository but can't write to it
+	session = loginUser(t, "user4")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/git/notes/master?token=%s", token)
+	session.MakeRequest(t, req, http.StatusOK)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/git/notes/master?token="+token, &api.CreateNoteOption{Message: "build 44"})
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
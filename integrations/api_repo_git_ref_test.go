@@ -6,9 +6,13 @@ package integrations
 
 import (
 	"net/http"
+	"net/url"
 	"testing"
 
 	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestAPIReposGitRefs(t *testing.T) {
@@ -32,3 +36,52 @@ func TestAPIReposGitRefs(t *testing.T) {
 	req = NewRequestf(t, "GET", "/api/v1/repos/%s/repo1/git/refs/heads/unknown?token="+token, user.Name)
 	session.MakeRequest(t, req, http.StatusNotFound)
 }
+
+func TestAPIReposGitRefsCreateDelete(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session)
+		refURL := func(ref string) string {
+			return "/api/v1/repos/user2/repo1/git/" + ref + "?token=" + token
+		}
+		sha := "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+
+		// check invalid requests
+		req := NewRequestWithJSON(t, "POST", refURL("refs/notes/test"), &api.CreateGitRefOption{SHA: sha})
+		session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+		req = NewRequestWithJSON(t, "POST", refURL("refs/heads/.."), &api.CreateGitRefOption{SHA: sha})
+		session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+		req = NewRequestWithJSON(t, "POST", refURL("refs/heads/new-branch"), &api.CreateGitRefOption{SHA: "1234567890123456789012345678901234567890"})
+		session.MakeRequest(t, req, http.StatusNotFound)
+		req = NewRequestWithJSON(t, "POST", refURL("refs/heads/develop"), &api.CreateGitRefOption{SHA: sha})
+		session.MakeRequest(t, req, http.StatusConflict)
+
+		for _, ref := range []string{"refs/heads/new-branch", "refs/tags/new-tag"} {
+			req = NewRequestWithJSON(t, "POST", refURL(ref), &api.CreateGitRefOption{SHA: sha})
+			resp := session.MakeRequest(t, req, http.StatusCreated)
+			var apiRef api.Reference
+			DecodeJSON(t, resp, &apiRef)
+			assert.Equal(t, ref, apiRef.Ref)
+			assert.Equal(t, sha, apiRef.Object.SHA)
+			assert.Equal(t, "commit", apiRef.Object.Type)
+
+			req = NewRequest(t, "GET", refURL(ref))
+			session.MakeRequest(t, req, http.StatusOK)
+
+			req = NewRequest(t, "DELETE", refURL(ref))
+			session.MakeRequest(t, req, http.StatusNoContent)
+			req = NewRequest(t, "GET", refURL(ref))
+			session.MakeRequest(t, req, http.StatusNotFound)
+		}
+		req = NewRequest(t, "DELETE", refURL("refs/heads/new-branch"))
+		session.MakeRequest(t, req, http.StatusNotFound)
+
+		// the default branch and protected branches can't be deleted
+		req = NewRequest(t, "DELETE", refURL("refs/heads/master"))
+		session.MakeRequest(t, req, http.StatusForbidden)
+		req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/branch_protections?token="+token, &api.CreateBranchProtectionOption{BranchName: "develop"})
+		session.MakeRequest(t, req, http.StatusCreated)
+		req = NewRequest(t, "DELETE", refURL("refs/heads/develop"))
+		session.MakeRequest(t, req, http.StatusForbidden)
+	})
+}
@@ -9,6 +9,8 @@ import (
 	"net/url"
 	"testing"
 
+	api "code.gitea.io/gitea/modules/structs"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -42,5 +44,13 @@ func TestRepoLanguages(t *testing.T) {
 		DecodeJSON(t, resp, &languages)
 
 		assert.InDeltaMapValues(t, map[string]int64{"Go": 12}, languages, 0)
+
+		// the language bar shares
+		req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/languages/bar")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+
+		var shares []*api.LanguageShare
+		DecodeJSON(t, resp, &shares)
+		assert.Equal(t, []*api.LanguageShare{{Language: "Go", Percentage: 100, Color: "#00ADD8"}}, shares)
 	})
 }
@@ -0,0 +1,76 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIReposLFSFile(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		defer func(startServer bool) { setting.LFS.StartServer = startServer }(setting.LFS.StartServer)
+		setting.LFS.StartServer = true
+
+		user2 := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+		repo1 := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+		session := loginUser(t, user2.Name)
+		token := getTokenForLoggedInUser(t, session)
+
+		// Commit the pointer to an image stored in LFS
+		content := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+		oid := storeObjectInRepo(t, repo1.ID, &content)
+		defer repo1.RemoveLFSMetaObjectByOid(oid)
+		pointer := (&models.LFSMetaObject{Oid: oid, Size: int64(len(content))}).Pointer()
+		createFileOptions := getCreateFileOptions()
+		createFileOptions.Content = base64.StdEncoding.EncodeToString([]byte(pointer))
+		req := NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/%s/%s/contents/image.png?token=%s", user2.Name, repo1.Name, token), &createFileOptions)
+		session.MakeRequest(t, req, http.StatusCreated)
+
+		// The contents flag the file and link to the content of the object
+		req = NewRequestf(t, "GET", "/api/v1/repos/%s/%s/contents/image.png?token=%s", user2.Name, repo1.Name, token)
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		var contentsResponse api.ContentsResponse
+		DecodeJSON(t, resp, &contentsResponse)
+		assert.True(t, contentsResponse.LFS)
+		assert.Contains(t, *contentsResponse.DownloadURL, "/media/branch/master/image.png")
+
+		req = NewRequestf(t, "GET", "/api/v1/repos/%s/%s/contents/README.md?token=%s", user2.Name, repo1.Name, token)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		DecodeJSON(t, resp, &contentsResponse)
+		assert.False(t, contentsResponse.LFS)
+
+		// The raw file is the pointer, the media is the content of the object
+		req = NewRequestf(t, "GET", "/api/v1/repos/%s/%s/raw/image.png?token=%s", user2.Name, repo1.Name, token)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, pointer, resp.Body.String())
+
+		req = NewRequestf(t, "GET", "/api/v1/repos/%s/%s/media/image.png?token=%s", user2.Name, repo1.Name, token)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, content, resp.Body.Bytes())
+
+		req = NewRequestf(t, "GET", "/api/v1/repos/%s/%s/media/README.md?token=%s", user2.Name, repo1.Name, token)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		assert.True(t, strings.HasPrefix(resp.Body.String(), "# repo1"))
+
+		// The image is previewed from the media link
+		req = NewRequestf(t, "GET", "/%s/%s/src/branch/master/image.png", user2.Name, repo1.Name)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		src, exists := htmlDoc.doc.Find(".file-view img").Attr("src")
+		assert.True(t, exists)
+		assert.Equal(t, fmt.Sprintf("/%s/%s/media/branch/master/image.png", user2.Name, repo1.Name), src)
+	})
+}
@@ -0,0 +1,99 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoGarbageCollect(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/maintenance?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequestf(t, "POST", "/api/v1/repos/user2/repo1/maintenance/gc?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusAccepted)
+	var gc api.RepoGarbageCollection
+	DecodeJSON(t, resp, &gc)
+
+	for i := 0; i < 50 && gc.Status != "finished" && gc.Status != "failed"; i++ {
+		time.Sleep(100 * time.Millisecond)
+		req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/maintenance?token=%s", token)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		DecodeJSON(t, resp, &gc)
+	}
+	assert.Equal(t, "finished", gc.Status)
+	assert.Empty(t, gc.Errors)
+	assert.NotZero(t, gc.SizeBefore)
+	assert.NotZero(t, gc.SizeAfter)
+	assert.NotNil(t, gc.Started)
+	assert.NotNil(t, gc.Finished)
+
+	// only the owner can run the garbage collection
+	session = loginUser(t, "user4")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestf(t, "POST", "/api/v1/repos/user2/repo1/maintenance/gc?token=%s", token)
+	session.MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/maintenance?token=%s", token)
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
+
+func TestAPIAdminRepoMaintenance(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	// only site admins can inspect and run the maintenance
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	req := NewRequestf(t, "GET", "/api/v1/admin/repos/maintenance?token=%s", token)
+	session.MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequestf(t, "POST", "/api/v1/admin/repos/maintenance?token=%s", token)
+	session.MakeRequest(t, req, http.StatusForbidden)
+
+	session = loginUser(t, "user1")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestf(t, "GET", "/api/v1/admin/repos/maintenance?status=unknown&token=%s", token)
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestf(t, "POST", "/api/v1/admin/repos/maintenance?force=true&token=%s", token)
+	session.MakeRequest(t, req, http.StatusAccepted)
+
+	countGarbageCollections := func(status string) int {
+		req := NewRequestf(t, "GET", "/api/v1/admin/repos/maintenance?status=%s&limit=50&token=%s", status, token)
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		var gcs []*api.RepoGarbageCollection
+		DecodeJSON(t, resp, &gcs)
+		return len(gcs)
+	}
+	// wait for all the garbage collections to be done
+	for i := 0; i < 300 && countGarbageCollections("queued")+countGarbageCollections("running") > 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	assert.Zero(t, countGarbageCollections("queued")+countGarbageCollections("running"))
+
+	req = NewRequestf(t, "GET", "/api/v1/admin/repos/maintenance?status=finished&limit=50&token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var gcs []*api.RepoGarbageCollection
+	DecodeJSON(t, resp, &gcs)
+	var repo1 *api.RepoGarbageCollection
+	for _, gc := range gcs {
+		if gc.Repository == "user2/repo1" {
+			repo1 = gc
+		}
+	}
+	if assert.NotNil(t, repo1) {
+		assert.NotZero(t, repo1.SizeAfter)
+		assert.NotNil(t, repo1.Finished)
+	}
+}
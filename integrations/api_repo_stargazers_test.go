@@ -0,0 +1,55 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoStargazers(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user1")
+	token := getTokenForLoggedInUser(t, session)
+	req := NewRequest(t, "PUT", "/api/v1/user/starred/user2/repo1?token="+token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+
+	var stargazers []*api.Stargazer
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/stargazers?sort=newest")
+	resp := MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &stargazers)
+	if assert.Len(t, stargazers, 1) {
+		assert.Equal(t, "user1", stargazers[0].UserName)
+		assert.NotNil(t, stargazers[0].StarredAt)
+	}
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/stargazers?sort=popular")
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+}
+
+func TestAPIRepoSubscribers(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	var watchers []*api.Watcher
+	req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1/subscribers?sort=newest&page=1&limit=2")
+	resp := MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &watchers)
+	if assert.Len(t, watchers, 2) {
+		assert.Equal(t, "user11", watchers[0].UserName)
+		assert.EqualValues(t, 946684840, watchers[0].WatchedAt.Unix())
+		assert.Equal(t, "user4", watchers[1].UserName)
+	}
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/subscribers")
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &watchers)
+	assert.Len(t, watchers, 4)
+	assert.Equal(t, "user1", watchers[0].UserName)
+}
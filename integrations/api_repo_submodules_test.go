@@ -0,0 +1,73 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoListSubmodules(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	// pin the private repo16 of user2 as submodule of repo1
+	repoPath := models.RepoPath("user2", "repo1")
+	run := func(stdin string, args ...string) string {
+		stdout := new(strings.Builder)
+		stderr := new(strings.Builder)
+		err := git.NewCommand(args...).RunInDirTimeoutEnvFullPipeline([]string{
+			"GIT_AUTHOR_NAME=user2", "GIT_AUTHOR_EMAIL=user2@example.com",
+			"GIT_COMMITTER_NAME=user2", "GIT_COMMITTER_EMAIL=user2@example.com",
+		}, -1, repoPath, stdout, stderr, strings.NewReader(stdin))
+		assert.NoError(t, err, stderr.String())
+		return strings.TrimSpace(stdout.String())
+	}
+	blobID := run("[submodule \"repo16\"]\n\tpath = sub\n\turl = "+setting.AppURL+"user2/repo16.git\n", "hash-object", "-w", "--stdin")
+	treeID := run("100644 blob "+blobID+"\t.gitmodules\n160000 commit 69554a64c1e6030f051e5c3f94bfbd773cd6a324\tsub\n", "mktree")
+	commitID := run("", "commit-tree", treeID, "-m", "add submodule")
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1/submodules?token="+token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var submodules []*api.Submodule
+	DecodeJSON(t, resp, &submodules)
+	assert.Empty(t, submodules)
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/submodules?ref=not-a-ref&token="+token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/submodules?ref="+commitID+"&token="+token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &submodules)
+	if assert.Len(t, submodules, 1) {
+		assert.Equal(t, "repo16", submodules[0].Name)
+		assert.Equal(t, "sub", submodules[0].Path)
+		assert.Equal(t, "69554a64c1e6030f051e5c3f94bfbd773cd6a324", submodules[0].SHA)
+		assert.Equal(t, setting.AppURL+"user2/repo16", submodules[0].HTMLURL)
+		assert.Equal(t, setting.AppURL+"user2/repo16/commit/69554a64c1e6030f051e5c3f94bfbd773cd6a324", submodules[0].CommitURL)
+		if assert.NotNil(t, submodules[0].Repository) {
+			assert.Equal(t, "user2/repo16", submodules[0].Repository.FullName)
+		}
+	}
+
+	// anonymous users can't see the private repository of the submodule
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/submodules?ref="+commitID)
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &submodules)
+	if assert.Len(t, submodules, 1) {
+		assert.Nil(t, submodules[0].Repository)
+		assert.Equal(t, setting.AppURL+"user2/repo16", submodules[0].HTMLURL)
+	}
+}
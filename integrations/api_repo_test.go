@@ -13,6 +13,7 @@ import (
 	"testing"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
 	api "code.gitea.io/gitea/modules/structs"
 
 	"github.com/stretchr/testify/assert"
@@ -381,6 +382,91 @@ func TestAPIOrgRepoCreate(t *testing.T) {
 	}
 }
 
+func TestAPIRepoCreateObjectFormat(t *testing.T) {
+	defer prepareTestEnv(t)()
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	session := loginUser(t, user.Name)
+	token := getTokenForLoggedInUser(t, session)
+
+	for _, objectFormat := range git.SupportedObjectFormats() {
+		req := NewRequestWithJSON(t, "POST", "/api/v1/user/repos?token="+token, &api.CreateRepoOption{
+			Name:             "repo-" + string(objectFormat),
+			ObjectFormatName: string(objectFormat),
+		})
+		resp := session.MakeRequest(t, req, http.StatusCreated)
+		var apiRepo api.Repository
+		DecodeJSON(t, resp, &apiRepo)
+		assert.EqualValues(t, objectFormat, apiRepo.ObjectFormatName)
+
+		repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: apiRepo.ID}).(*models.Repository)
+		assert.Equal(t, objectFormat, repo.ObjectFormat())
+		repoObjectFormat, err := git.GetObjectFormatOfRepo(repo.RepoPath())
+		assert.NoError(t, err)
+		assert.Equal(t, objectFormat, repoObjectFormat)
+	}
+
+	// sha256 repositories can't be read yet
+	req := NewRequestWithJSON(t, "POST", "/api/v1/user/repos?token="+token, &api.CreateRepoOption{
+		Name:             "repo-sha256",
+		ObjectFormatName: "sha256",
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	models.AssertNotExistsBean(t, &models.Repository{OwnerID: user.ID, LowerName: "repo-sha256"})
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/user/repos?token="+token, &api.CreateRepoOption{
+		Name:             "repo-md5",
+		ObjectFormatName: "md5",
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+}
+
+func TestAPIRepoObjectFormatPushView(t *testing.T) {
+	onGiteaRun(t, testAPIRepoObjectFormatPushView)
+}
+
+func testAPIRepoObjectFormatPushView(t *testing.T, u *url.URL) {
+	username := "user2"
+	ctx := NewAPITestContext(t, username, "repo-object-format")
+
+	for _, objectFormat := range git.SupportedObjectFormats() {
+		ctx.Reponame = "repo-" + string(objectFormat)
+		req := NewRequestWithJSON(t, "POST", "/api/v1/user/repos?token="+ctx.Token, &api.CreateRepoOption{
+			Name:             ctx.Reponame,
+			AutoInit:         true,
+			Readme:           "Default",
+			ObjectFormatName: string(objectFormat),
+		})
+		ctx.Session.MakeRequest(t, req, http.StatusCreated)
+
+		dstPath, err := ioutil.TempDir("", ctx.Reponame)
+		assert.NoError(t, err)
+		defer os.RemoveAll(dstPath)
+
+		u.Path = ctx.GitPath()
+		u.User = url.UserPassword(username, userPassword)
+		t.Run("Clone", doGitClone(dstPath, u))
+		filename := doCommitAndPush(t, 1024, dstPath, "data-file-")
+
+		gitRepo, err := git.OpenRepository(dstPath)
+		assert.NoError(t, err)
+		commitID, err := gitRepo.GetBranchCommitID("master")
+		gitRepo.Close()
+		assert.NoError(t, err)
+		assert.Len(t, commitID, objectFormat.HexLen())
+
+		// The pushed commit and file can be viewed
+		req = NewRequestf(t, "GET", "/%s/%s/commit/%s", username, ctx.Reponame, commitID)
+		ctx.Session.MakeRequest(t, req, http.StatusOK)
+		req = NewRequestf(t, "GET", "/%s/%s/src/branch/master/%s", username, ctx.Reponame, filename)
+		ctx.Session.MakeRequest(t, req, http.StatusOK)
+		req = NewRequestf(t, "GET", "/api/v1/repos/%s/%s/git/commits/%s?token=%s", username, ctx.Reponame, commitID, ctx.Token)
+		resp := ctx.Session.MakeRequest(t, req, http.StatusOK)
+		var apiCommit api.Commit
+		DecodeJSON(t, resp, &apiCommit)
+		assert.Equal(t, commitID, apiCommit.SHA)
+	}
+}
+
 func TestAPIRepoCreateConflict(t *testing.T) {
 	onGiteaRun(t, testAPIRepoCreateConflict)
 }
@@ -419,8 +505,8 @@ func TestAPIRepoTransfer(t *testing.T) {
 		expectedStatus int
 	}{
 		{ctxUserID: 1, newOwner: "user2", teams: nil, expectedStatus: http.StatusAccepted},
-		{ctxUserID: 2, newOwner: "user1", teams: nil, expectedStatus: http.StatusAccepted},
-		{ctxUserID: 2, newOwner: "user6", teams: nil, expectedStatus: http.StatusForbidden},
+		{ctxUserID: 2, newOwner: "user1", teams: nil, expectedStatus: http.StatusCreated},
+		{ctxUserID: 2, newOwner: "user6", teams: nil, expectedStatus: http.StatusUnprocessableEntity},
 		{ctxUserID: 1, newOwner: "user2", teams: &[]int64{2}, expectedStatus: http.StatusUnprocessableEntity},
 		{ctxUserID: 1, newOwner: "user3", teams: &[]int64{5}, expectedStatus: http.StatusForbidden},
 		{ctxUserID: 1, newOwner: "user3", teams: &[]int64{2}, expectedStatus: http.StatusAccepted},
@@ -461,3 +547,72 @@ func TestAPIRepoTransfer(t *testing.T) {
 	repo = models.AssertExistsAndLoadBean(t, &models.Repository{ID: repo.ID}).(*models.Repository)
 	_ = models.DeleteRepository(user, repo.OwnerID, repo.ID)
 }
+
+func TestAPIRepoTransferRequest(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	user2 := loginUser(t, "user2")
+	token2 := getTokenForLoggedInUser(t, user2)
+	user4 := loginUser(t, "user4")
+	token4 := getTokenForLoggedInUser(t, user4)
+	user5 := loginUser(t, "user5")
+	token5 := getTokenForLoggedInUser(t, user5)
+
+	requestTransfer := func() {
+		req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo16/transfer?token="+token2, &api.TransferRepoOption{
+			NewOwner: "user4",
+		})
+		resp := user2.MakeRequest(t, req, http.StatusCreated)
+
+		var transfer api.RepoTransfer
+		DecodeJSON(t, resp, &transfer)
+		assert.Equal(t, "user2", transfer.Doer.UserName)
+		assert.Equal(t, "user4", transfer.Recipient.UserName)
+	}
+
+	// user2 can't create repositories for user4, the transfer is pending until user4 accepts it
+	requestTransfer()
+	models.AssertExistsAndLoadBean(t, &models.RepoTransfer{RepoID: 16, DoerID: 2, RecipientID: 4})
+	models.AssertExistsAndLoadBean(t, &models.Repository{ID: 16, OwnerID: 2})
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo16/transfer?token="+token2, &api.TransferRepoOption{
+		NewOwner: "user5",
+	})
+	user2.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// the private repository is visible to the new owner through the transfer only
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo16/transfer?token="+token4)
+	user4.MakeRequest(t, req, http.StatusOK)
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo16?token="+token4)
+	user4.MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo16/transfer?token="+token5)
+	user5.MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo16/transfer?token="+token2)
+	user2.MakeRequest(t, req, http.StatusOK)
+
+	req = NewRequest(t, "POST", "/api/v1/repos/user2/repo16/transfer/accept?token="+token2)
+	user2.MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequest(t, "DELETE", "/api/v1/repos/user2/repo16/transfer?token="+token4)
+	user4.MakeRequest(t, req, http.StatusForbidden)
+
+	req = NewRequest(t, "POST", "/api/v1/repos/user2/repo16/transfer/reject?token="+token4)
+	user4.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.RepoTransfer{RepoID: 16})
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo16/transfer?token="+token2)
+	user2.MakeRequest(t, req, http.StatusNotFound)
+
+	requestTransfer()
+	req = NewRequest(t, "DELETE", "/api/v1/repos/user2/repo16/transfer?token="+token2)
+	user2.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.RepoTransfer{RepoID: 16})
+
+	requestTransfer()
+	req = NewRequest(t, "POST", "/api/v1/repos/user2/repo16/transfer/accept?token="+token4)
+	resp := user4.MakeRequest(t, req, http.StatusAccepted)
+
+	var repo api.Repository
+	DecodeJSON(t, resp, &repo)
+	assert.Equal(t, "user4/repo16", repo.FullName)
+	models.AssertExistsAndLoadBean(t, &models.Repository{ID: 16, OwnerID: 4})
+	models.AssertNotExistsBean(t, &models.RepoTransfer{RepoID: 16})
+}
@@ -57,6 +57,20 @@ func TestAPIRepoTopic(t *testing.T) {
 	DecodeJSON(t, res, &topics)
 	assert.ElementsMatch(t, []string{"topicname2", "golang", "topicname3"}, topics.TopicNames)
 
+	// Test the topics of the repository and the search by topic
+	req = NewRequestf(t, "GET", "/api/v1/repos/%s/%s?token=%s", user2.Name, repo2.Name, token2)
+	res = session.MakeRequest(t, req, http.StatusOK)
+	var apiRepo api.Repository
+	DecodeJSON(t, res, &apiRepo)
+	assert.ElementsMatch(t, []string{"topicname2", "golang", "topicname3"}, apiRepo.Topics)
+	req = NewRequestf(t, "GET", "/api/v1/repos/search?q=topicname3&topic=true&token=%s", token2)
+	res = session.MakeRequest(t, req, http.StatusOK)
+	var searchResults api.SearchResults
+	DecodeJSON(t, res, &searchResults)
+	if assert.Len(t, searchResults.Data, 1) {
+		assert.EqualValues(t, repo2.ID, searchResults.Data[0].ID)
+	}
+
 	// Test replace topics
 	newTopics := []string{"   windows ", "   ", "MAC  "}
 	req = NewRequestWithJSON(t, "PUT", url, &api.RepoTopicOptions{
@@ -5,11 +5,18 @@
 package integrations
 
 import (
+	"bytes"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/routes"
+
+	"github.com/stretchr/testify/assert"
 )
 
 // TestAPICreateAndDeleteToken tests that token that was just created can be deleted
@@ -48,3 +55,189 @@ func TestAPIDeleteMissingToken(t *testing.T) {
 	req = AddBasicAuthHeader(req, user.Name)
 	MakeRequest(t, req, http.StatusNotFound)
 }
+
+func createScopedToken(t *testing.T, scopes ...string) string {
+	req := NewRequestWithJSON(t, "POST", "/api/v1/users/user2/tokens", &api.CreateAccessTokenOption{
+		Name:   "scoped-" + strings.Join(scopes, "-"),
+		Scopes: scopes,
+	})
+	req = AddBasicAuthHeader(req, "user2")
+	resp := MakeRequest(t, req, http.StatusCreated)
+
+	var token api.AccessToken
+	DecodeJSON(t, resp, &token)
+	assert.Equal(t, scopes, token.Scopes)
+	return token.Token
+}
+
+func TestAPITokenScopes(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	readToken := createScopedToken(t, "repo:read")
+	issueToken := createScopedToken(t, "issue:write")
+
+	req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1/issues/1?token="+readToken)
+	MakeRequest(t, req, http.StatusOK)
+	req = NewRequest(t, "GET", "/api/v1/user?token="+readToken)
+	MakeRequest(t, req, http.StatusOK)
+
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1/issues/1?token="+readToken, &api.EditIssueOption{
+		Title: "edited",
+	})
+	MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1/issues/1?token="+issueToken, &api.EditIssueOption{
+		Title: "edited",
+	})
+	MakeRequest(t, req, http.StatusCreated)
+
+	description := "edited"
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1?token="+issueToken, &api.EditRepoOption{
+		Description: &description,
+	})
+	MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/user/repos?token="+issueToken, &api.CreateRepoOption{
+		Name: "scoped-repo",
+	})
+	MakeRequest(t, req, http.StatusForbidden)
+
+	// the scopes are listed with the tokens
+	req = AddBasicAuthHeader(NewRequest(t, "GET", "/api/v1/users/user2/tokens"), "user2")
+	resp := MakeRequest(t, req, http.StatusOK)
+	var tokens []*api.AccessToken
+	DecodeJSON(t, resp, &tokens)
+	scopes := map[string][]string{}
+	for _, token := range tokens {
+		scopes[token.Name] = token.Scopes
+	}
+	assert.Equal(t, []string{"all"}, scopes["Token A"])
+	assert.Equal(t, []string{"repo:read"}, scopes["scoped-repo:read"])
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/users/user2/tokens", &api.CreateAccessTokenOption{
+		Name:   "invalid",
+		Scopes: []string{"repo:delete"},
+	})
+	req = AddBasicAuthHeader(req, "user2")
+	MakeRequest(t, req, http.StatusBadRequest)
+}
+
+func TestAPITokenScopeAdmin(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/users/user1/tokens", &api.CreateAccessTokenOption{
+		Name:   "not-admin",
+		Scopes: []string{"repo:write", "user"},
+	})
+	req = AddBasicAuthHeader(req, "user1")
+	resp := MakeRequest(t, req, http.StatusCreated)
+	var token api.AccessToken
+	DecodeJSON(t, resp, &token)
+
+	req = NewRequest(t, "GET", "/api/v1/admin/orgs?token="+token.Token)
+	MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1?token="+token.Token)
+	MakeRequest(t, req, http.StatusOK)
+
+	// sudo requires the admin scope too
+	req = NewRequest(t, "GET", "/api/v1/user?sudo=user2&token="+token.Token)
+	MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequest(t, "GET", "/api/v1/user?sudo=user2")
+	req = AddBasicAuthHeader(req, "user1")
+	resp = MakeRequest(t, req, http.StatusOK)
+	var user api.User
+	DecodeJSON(t, resp, &user)
+	assert.Equal(t, "user2", user.UserName)
+}
+
+func TestAPIGraphQLTokenScope(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	defer func(enabled bool) {
+		setting.API.EnableGraphQL = enabled
+		mac = routes.NewMacaron()
+		routes.RegisterRoutes(mac)
+	}(setting.API.EnableGraphQL)
+	setting.API.EnableGraphQL = true
+	mac = routes.NewMacaron()
+	routes.RegisterRoutes(mac)
+
+	query := func(token string, status int) *httptest.ResponseRecorder {
+		req := NewRequestWithJSON(t, "POST", "/api/graphql?token="+token, map[string]string{
+			"query": `{repository(owner: "user2", name: "repo2") {name}}`,
+		})
+		return MakeRequest(t, req, status)
+	}
+
+	// the private repositories can't be read without the repo:read scope
+	query(createScopedToken(t, "user"), http.StatusForbidden)
+
+	resp := query(createScopedToken(t, "repo:read"), http.StatusOK)
+	var result struct {
+		Data struct {
+			Repository struct {
+				Name string
+			}
+		}
+	}
+	DecodeJSON(t, resp, &result)
+	assert.Equal(t, "repo2", result.Data.Repository.Name)
+}
+
+func TestWebTokenScope(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	getSettings := func(token string, status int) {
+		req := NewRequest(t, "GET", "/user/settings")
+		req.SetBasicAuth("user2", token)
+		MakeRequest(t, req, status)
+	}
+
+	// the scoped tokens don't sign in on the web routes
+	getSettings(createScopedToken(t, "user"), http.StatusFound)
+	getSettings(createScopedToken(t, "repo:write", "user", "admin"), http.StatusFound)
+	getSettings(createScopedToken(t, "all"), http.StatusOK)
+}
+
+func TestLFSTokenScope(t *testing.T) {
+	defer prepareTestEnv(t)()
+	setting.CheckLFSVersion()
+	if !setting.LFS.StartServer {
+		t.Skip()
+		return
+	}
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{OwnerName: "user2", Name: "repo2"}).(*models.Repository)
+	assert.True(t, repo.IsPrivate)
+
+	content := []byte("A file of a private repository\n")
+	oid := storeObjectInRepo(t, repo.ID, &content)
+	defer repo.RemoveLFSMetaObjectByOid(oid)
+
+	download := func(token string, status int) {
+		req := NewRequest(t, "GET", "/user2/repo2.git/info/lfs/objects/"+oid+"/test")
+		req.SetBasicAuth("user2", token)
+		resp := MakeRequest(t, req, status)
+		if status == http.StatusOK {
+			assert.Equal(t, content, resp.Body.Bytes())
+		}
+	}
+	readToken := createScopedToken(t, "repo:read")
+	download(createScopedToken(t, "user"), http.StatusUnauthorized)
+	download(readToken, http.StatusOK)
+
+	newContent := []byte("A new file of a private repository\n")
+	newOid, err := GenerateLFSOid(bytes.NewReader(newContent))
+	assert.NoError(t, err)
+	upload := func(token string, status int) {
+		req := NewRequestWithJSON(t, "POST", "/user2/repo2.git/info/lfs/objects/batch", map[string]interface{}{
+			"operation": "upload",
+			"objects":   []map[string]interface{}{{"oid": newOid, "size": len(newContent)}},
+		})
+		req.Header.Set("Accept", "application/vnd.git-lfs+json")
+		req.Header.Set("Content-Type", "application/vnd.git-lfs+json")
+		req.SetBasicAuth("user2", token)
+		MakeRequest(t, req, status)
+	}
+	upload(readToken, http.StatusUnauthorized)
+	upload(createScopedToken(t, "repo:write"), http.StatusOK)
+	defer repo.RemoveLFSMetaObjectByOid(newOid)
+}
@@ -25,6 +25,20 @@ func TestViewBranches(t *testing.T) {
 	assert.False(t, exists, "The template has changed")
 }
 
+func TestSearchBranches(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	req := NewRequest(t, "GET", "/user2/repo1/branches?q=feature")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	names := htmlDoc.doc.Find(".delete-branch-button").Map(func(i int, s *goquery.Selection) string {
+		return s.AttrOr("data-name", "")
+	})
+	assert.Equal(t, []string{"feature/1"}, names)
+}
+
 func TestDeleteBranch(t *testing.T) {
 	defer prepareTestEnv(t)()
 
@@ -0,0 +1,93 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/metrics"
+	"code.gitea.io/gitea/modules/setting"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitSmartHTTPWireProtocol(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	getInfoRefs := func(t *testing.T, protocol string) string {
+		req := NewRequest(t, "GET", "/user2/repo1.git/info/refs?service=git-upload-pack")
+		if protocol != "" {
+			req.Header.Set("Git-Protocol", protocol)
+		}
+		resp := MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, "application/x-git-upload-pack-advertisement", resp.Header().Get("Content-Type"))
+		return resp.Body.String()
+	}
+
+	refs := getInfoRefs(t, "")
+	assert.Contains(t, refs, "# service=git-upload-pack\n")
+	assert.Contains(t, refs, "refs/heads/master")
+
+	if !setting.Git.EnableAutoGitWireProtocol {
+		t.Skip("git is too old for wire protocol version 2")
+	}
+
+	// Version 2 only advertises the capabilities
+	refs = getInfoRefs(t, "version=2")
+	assert.NotContains(t, refs, "# service=git-upload-pack")
+	assert.Contains(t, refs, "version 2\n")
+	assert.NotContains(t, refs, "refs/heads/master")
+
+	// Invalid parameters are ignored
+	refs = getInfoRefs(t, "version=2\nfoo")
+	assert.Contains(t, refs, "refs/heads/master")
+}
+
+func TestGitSmartHTTPPartialClone(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		if setting.Git.DisablePartialClone {
+			t.Skip("git is too old for partial clones")
+		}
+		defer func(enabled bool) {
+			setting.Metrics.Enabled = enabled
+		}(setting.Metrics.Enabled)
+		setting.Metrics.Enabled = true
+
+		filteredFetches := func() float64 {
+			m := &dto.Metric{}
+			assert.NoError(t, metrics.GitFilteredFetches.WithLabelValues("blob:none").Write(m))
+			return m.GetCounter().GetValue()
+		}
+		before := filteredFetches()
+
+		dstPath, err := ioutil.TempDir("", "partial-clone")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dstPath)
+
+		u.Path = "user2/repo1.git"
+		_, err = git.NewCommand("clone", "--no-checkout", "--filter=blob:none", u.String(), dstPath).Run()
+		assert.NoError(t, err)
+
+		// The blobs are left out
+		objects, err := git.NewCommand("rev-list", "--objects", "--missing=print", "HEAD").RunInDir(dstPath)
+		assert.NoError(t, err)
+		assert.Contains(t, objects, "\n?")
+		assert.Equal(t, before+1, filteredFetches())
+
+		// and fetched when they are needed
+		_, err = git.NewCommand("checkout", "master").RunInDir(dstPath)
+		assert.NoError(t, err)
+		content, err := ioutil.ReadFile(dstPath + "/README.md")
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(content), "# repo1"))
+	})
+}
@@ -0,0 +1,66 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLFSLockedFileChanges(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		setting.LFS.StartServer = true
+		user2 := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+		user4 := models.AssertExistsAndLoadBean(t, &models.User{ID: 4}).(*models.User)
+		repo3 := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 3}).(*models.Repository) // both users can write to it
+
+		lock, err := models.CreateLFSLock(&models.LFSLock{Repo: repo3, Owner: user4, Path: "README.md"})
+		assert.NoError(t, err)
+
+		// Changes of the file by user2 through the api are forbidden
+		session := loginUser(t, user2.Name)
+		token := getTokenForLoggedInUser(t, session)
+		urlStr := fmt.Sprintf("/api/v1/repos/%s/%s/contents/README.md?token=%s", repo3.OwnerName, repo3.Name, token)
+		req := NewRequestWithJSON(t, "PUT", urlStr, getUpdateFileOptions())
+		session.MakeRequest(t, req, http.StatusForbidden)
+
+		// Pushes of changes of the file by user2 are rejected
+		dstPath, err := ioutil.TempDir("", "lfs-locked-file")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dstPath)
+		u.Path = fmt.Sprintf("%s/%s.git", repo3.OwnerName, repo3.Name)
+		u.User = url.UserPassword(user2.Name, userPassword)
+		t.Run("Clone", doGitClone(dstPath, u))
+		t.Run("ChangeLockedFile", func(t *testing.T) {
+			assert.NoError(t, ioutil.WriteFile(filepath.Join(dstPath, "README.md"), []byte("Changed while locked"), 0644))
+			assert.NoError(t, git.AddChanges(dstPath, false, "README.md"))
+			signature := &git.Signature{Email: user2.Email, Name: user2.Name, When: time.Now()}
+			assert.NoError(t, git.CommitChanges(dstPath, git.CommitChangesOptions{
+				Committer: signature,
+				Author:    signature,
+				Message:   "Change the locked file",
+			}))
+		})
+		t.Run("FailToPushLockedFile", doGitPushTestRepositoryFail(dstPath, "origin", "master"))
+		t.Run("FailToPushLockedFileToNewBranch", doGitPushTestRepositoryFail(dstPath, "origin", "master:locked-file"))
+
+		// Once the file is unlocked the changes can be pushed
+		_, err = models.DeleteLFSLockByID(lock.ID, user4, false)
+		assert.NoError(t, err)
+		t.Run("PushUnlockedFile", doGitPushTestRepository(dstPath, "origin", "master"))
+	})
+}
@@ -0,0 +1,176 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullAutoMerge(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session)
+		testEditFileToNewBranch(t, session, "user2", "repo1", "master", "automerge", "README.md", "automerge")
+
+		// The status check testci is required to merge into master
+		req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/branch_protections?token="+token, &api.CreateBranchProtectionOption{
+			BranchName:          "master",
+			EnableStatusCheck:   true,
+			StatusCheckContexts: []string{"testci"},
+		})
+		session.MakeRequest(t, req, http.StatusCreated)
+
+		link := path.Join("user2", "repo1", "compare", "master...automerge")
+		req = NewRequestWithValues(t, "POST", link, map[string]string{
+			"_csrf": GetCSRF(t, session, link),
+			"title": "pull request from automerge",
+		})
+		session.MakeRequest(t, req, http.StatusFound)
+
+		repo := models.AssertExistsAndLoadBean(t, &models.Repository{OwnerName: "user2", Name: "repo1"}).(*models.Repository)
+		issue := models.AssertExistsAndLoadBean(t, &models.Issue{RepoID: repo.ID, Title: "pull request from automerge"}).(*models.Issue)
+		pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{IssueID: issue.ID}).(*models.PullRequest)
+		mergeURL := fmt.Sprintf("/api/v1/repos/user2/repo1/pulls/%d/merge?token=%s", issue.Index, token)
+
+		// The pull request can't be merged as long as the check hasn't succeeded
+		req = NewRequestWithJSON(t, "POST", mergeURL, &auth.MergePullRequestForm{Do: string(models.MergeStyleMerge)})
+		session.MakeRequest(t, req, http.StatusMethodNotAllowed)
+
+		// Schedule and cancel the merge through the API
+		scheduleOption := &auth.MergePullRequestForm{
+			Do:                     string(models.MergeStyleMerge),
+			MergeWhenChecksSucceed: true,
+		}
+		req = NewRequestWithJSON(t, "POST", mergeURL, scheduleOption)
+		session.MakeRequest(t, req, http.StatusAccepted)
+		models.AssertExistsAndLoadBean(t, &models.PullAutoMerge{PullID: pr.ID, MergeStyle: models.MergeStyleMerge})
+		req = NewRequestWithJSON(t, "POST", mergeURL, scheduleOption)
+		session.MakeRequest(t, req, http.StatusConflict)
+
+		req = NewRequest(t, "DELETE", mergeURL)
+		session.MakeRequest(t, req, http.StatusNoContent)
+		models.AssertNotExistsBean(t, &models.PullAutoMerge{PullID: pr.ID})
+		session.MakeRequest(t, req, http.StatusNotFound)
+
+		// Schedule the merge from the pull request page
+		pullLink := path.Join("user2", "repo1", "pulls", fmt.Sprint(issue.Index))
+		resp := session.MakeRequest(t, NewRequest(t, "GET", pullLink), http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		assert.EqualValues(t, 1, htmlDoc.doc.Find(".merge-fields input[name=merge_when_checks_succeed]").Length())
+		link, exists := htmlDoc.doc.Find(".ui.form.merge-fields > form").Attr("action")
+		assert.True(t, exists, "The template has changed")
+		req = NewRequestWithValues(t, "POST", link, map[string]string{
+			"_csrf":                     htmlDoc.GetCSRF(),
+			"do":                        string(models.MergeStyleMerge),
+			"merge_when_checks_succeed": "true",
+		})
+		session.MakeRequest(t, req, http.StatusFound)
+		models.AssertExistsAndLoadBean(t, &models.PullAutoMerge{PullID: pr.ID})
+		models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: issue.ID, Type: models.CommentTypePullScheduledMerge})
+
+		resp = session.MakeRequest(t, NewRequest(t, "GET", pullLink), http.StatusOK)
+		htmlDoc = NewHTMLParser(t, resp.Body)
+		assert.EqualValues(t, 1, htmlDoc.doc.Find(".auto-merge").Length())
+		_, exists = htmlDoc.doc.Find("form[action$='/cancel_auto_merge']").Attr("action")
+		assert.True(t, exists)
+
+		// The pull request is merged once the check succeeds
+		req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/branches/automerge?token=%s", token)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		var branch api.Branch
+		DecodeJSON(t, resp, &branch)
+		req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/statuses/%s?token=%s", branch.Commit.ID, token),
+			api.CreateStatusOption{
+				State:   api.StatusSuccess,
+				Context: "testci",
+			},
+		)
+		session.MakeRequest(t, req, http.StatusCreated)
+
+		for i := 0; i < 50; i++ {
+			pr = models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: pr.ID}).(*models.PullRequest)
+			if pr.HasMerged {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		assert.True(t, pr.HasMerged)
+		assert.EqualValues(t, 2, pr.MergerID)
+		models.AssertNotExistsBean(t, &models.PullAutoMerge{PullID: pr.ID})
+	})
+}
+
+func TestPullAutoMergeHeadChanged(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session)
+		testEditFileToNewBranch(t, session, "user2", "repo1", "master", "automerge", "README.md", "automerge")
+
+		req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/branch_protections?token="+token, &api.CreateBranchProtectionOption{
+			BranchName:          "master",
+			EnableStatusCheck:   true,
+			StatusCheckContexts: []string{"testci"},
+		})
+		session.MakeRequest(t, req, http.StatusCreated)
+
+		req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/pulls?token="+token, &api.CreatePullRequestOption{
+			Head:  "automerge",
+			Base:  "master",
+			Title: "pull request from automerge",
+		})
+		resp := session.MakeRequest(t, req, http.StatusCreated)
+		var apiPull api.PullRequest
+		DecodeJSON(t, resp, &apiPull)
+
+		req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/pulls/%d/merge?token=%s", apiPull.Index, token), &auth.MergePullRequestForm{
+			Do:                     string(models.MergeStyleMerge),
+			MergeWhenChecksSucceed: true,
+		})
+		session.MakeRequest(t, req, http.StatusAccepted)
+		models.AssertExistsAndLoadBean(t, &models.PullAutoMerge{PullID: apiPull.ID, HeadCommitID: apiPull.Head.Sha})
+
+		// The commits pushed after the merge has been scheduled aren't merged on behalf of the doer
+		testEditFile(t, session, "user2", "repo1", "automerge", "README.md", "changed after the merge has been scheduled")
+		for i := 0; i < 50; i++ {
+			if !models.BeanExists(t, &models.PullAutoMerge{PullID: apiPull.ID}) {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		models.AssertNotExistsBean(t, &models.PullAutoMerge{PullID: apiPull.ID})
+		pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: apiPull.ID}).(*models.PullRequest)
+		models.AssertExistsAndLoadBean(t, &models.Comment{
+			IssueID: pr.IssueID,
+			Type:    models.CommentTypePullCancelledScheduledMerge,
+			Content: models.AutoMergeCancelledHeadChanged,
+		})
+
+		req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/branches/automerge?token=%s", token)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		var branch api.Branch
+		DecodeJSON(t, resp, &branch)
+		req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/statuses/%s?token=%s", branch.Commit.ID, token),
+			api.CreateStatusOption{
+				State:   api.StatusSuccess,
+				Context: "testci",
+			},
+		)
+		session.MakeRequest(t, req, http.StatusCreated)
+		time.Sleep(500 * time.Millisecond)
+		pr = models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: apiPull.ID}).(*models.PullRequest)
+		assert.False(t, pr.HasMerged)
+	})
+}
@@ -0,0 +1,138 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/unknwon/i18n"
+)
+
+func TestPullCodeOwners(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session)
+
+		// Unknown owners are ignored
+		req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/contents/CODEOWNERS?token="+token, &api.CreateFileOptions{
+			FileOptions: api.FileOptions{
+				BranchName: "master",
+				Message:    "Add CODEOWNERS",
+			},
+			Content: base64.StdEncoding.EncodeToString([]byte("README.md @user4 @nonexistent\ndocs/ @user5\n")),
+		})
+		session.MakeRequest(t, req, http.StatusCreated)
+
+		req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/branch_protections?token="+token, &api.CreateBranchProtectionOption{
+			BranchName:              "master",
+			RequireCodeOwnerReviews: true,
+		})
+		resp := session.MakeRequest(t, req, http.StatusCreated)
+		var protection api.BranchProtection
+		DecodeJSON(t, resp, &protection)
+		assert.True(t, protection.RequireCodeOwnerReviews)
+
+		testEditFileToNewBranch(t, session, "user2", "repo1", "master", "codeowners", "README.md", "codeowners")
+		link := path.Join("user2", "repo1", "compare", "master...codeowners")
+		req = NewRequestWithValues(t, "POST", link, map[string]string{
+			"_csrf": GetCSRF(t, session, link),
+			"title": "pull request from codeowners",
+		})
+		session.MakeRequest(t, req, http.StatusFound)
+		repo := models.AssertExistsAndLoadBean(t, &models.Repository{OwnerName: "user2", Name: "repo1"}).(*models.Repository)
+		issue := models.AssertExistsAndLoadBean(t, &models.Issue{RepoID: repo.ID, Title: "pull request from codeowners"}).(*models.Issue)
+
+		codeOwnersURL := fmt.Sprintf("/api/v1/repos/user2/repo1/pulls/%d/code_owners?token=%s", issue.Index, token)
+		resp = session.MakeRequest(t, NewRequest(t, "GET", codeOwnersURL), http.StatusOK)
+		var codeOwners api.PullRequestCodeOwners
+		DecodeJSON(t, resp, &codeOwners)
+		assert.True(t, codeOwners.Required)
+		assert.Equal(t, []string{"@user4"}, codeOwners.Unapproved)
+
+		// The pull request can't be merged until the owner of README.md approves it
+		mergeURL := fmt.Sprintf("/api/v1/repos/user2/repo1/pulls/%d/merge?token=%s", issue.Index, token)
+		req = NewRequestWithJSON(t, "POST", mergeURL, &auth.MergePullRequestForm{Do: string(models.MergeStyleMerge)})
+		session.MakeRequest(t, req, http.StatusMethodNotAllowed)
+
+		resp = session.MakeRequest(t, NewRequest(t, "GET", path.Join("user2", "repo1", "pulls", fmt.Sprint(issue.Index))), http.StatusOK)
+		assert.Contains(t, resp.Body.String(), i18n.Tr("en", "repo.pulls.blocked_by_code_owners", "@user4"))
+
+		session4 := loginUser(t, "user4")
+		token4 := getTokenForLoggedInUser(t, session4)
+		req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/pulls/%d/reviews?token=%s", issue.Index, token4), &api.CreatePullReviewOptions{
+			Event: api.ReviewStateApproved,
+		})
+		session4.MakeRequest(t, req, http.StatusOK)
+
+		resp = session.MakeRequest(t, NewRequest(t, "GET", codeOwnersURL), http.StatusOK)
+		DecodeJSON(t, resp, &codeOwners)
+		assert.Empty(t, codeOwners.Unapproved)
+
+		req = NewRequestWithJSON(t, "POST", mergeURL, &auth.MergePullRequestForm{Do: string(models.MergeStyleMerge)})
+		session.MakeRequest(t, req, http.StatusOK)
+	})
+}
+
+func TestPullCodeOwnersReviewRequests(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session)
+
+		// The members of the team1 of the organization user3 are user2 and user4
+		req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/contents/.gitea/CODEOWNERS?token="+token, &api.CreateFileOptions{
+			FileOptions: api.FileOptions{
+				BranchName: "master",
+				Message:    "Add CODEOWNERS",
+			},
+			Content: base64.StdEncoding.EncodeToString([]byte("README.md @user3/team1\nnew.txt @user5\n")),
+		})
+		session.MakeRequest(t, req, http.StatusCreated)
+
+		testEditFileToNewBranch(t, session, "user2", "repo1", "master", "reviewrequests", "README.md", "reviewrequests")
+		link := path.Join("user2", "repo1", "compare", "master...reviewrequests")
+		req = NewRequestWithValues(t, "POST", link, map[string]string{
+			"_csrf": GetCSRF(t, session, link),
+			"title": "pull request from reviewrequests",
+		})
+		session.MakeRequest(t, req, http.StatusFound)
+		repo := models.AssertExistsAndLoadBean(t, &models.Repository{OwnerName: "user2", Name: "repo1"}).(*models.Repository)
+		issue := models.AssertExistsAndLoadBean(t, &models.Issue{RepoID: repo.ID, Title: "pull request from reviewrequests"}).(*models.Issue)
+
+		// The poster isn't requested
+		models.AssertExistsAndLoadBean(t, &models.Review{IssueID: issue.ID, ReviewerID: 4, Type: models.ReviewTypeRequest})
+		models.AssertNotExistsBean(t, &models.Review{IssueID: issue.ID, ReviewerID: 2})
+		models.AssertNotExistsBean(t, &models.Review{IssueID: issue.ID, ReviewerID: 5})
+
+		// The owners of the files changed by new commits are requested too
+		req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/contents/new.txt?token="+token, &api.CreateFileOptions{
+			FileOptions: api.FileOptions{
+				BranchName: "reviewrequests",
+				Message:    "Add new.txt",
+			},
+			Content: base64.StdEncoding.EncodeToString([]byte("new")),
+		})
+		session.MakeRequest(t, req, http.StatusCreated)
+
+		for i := 0; i < 50; i++ {
+			if models.BeanExists(t, &models.Review{IssueID: issue.ID, ReviewerID: 5}) {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		models.AssertExistsAndLoadBean(t, &models.Review{IssueID: issue.ID, ReviewerID: 5, Type: models.ReviewTypeRequest})
+		models.AssertCount(t, &models.Review{IssueID: issue.ID, ReviewerID: 4}, 1)
+	})
+}
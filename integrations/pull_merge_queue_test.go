@@ -0,0 +1,298 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullMergeQueue(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session)
+		testEditFileToNewBranch(t, session, "user2", "repo1", "master", "queued", "README.md", "queued")
+		testEditFileToNewBranch(t, session, "user2", "repo1", "master", "removed", "README.md", "removed")
+
+		// The pull requests into master go through its merge queue, which requires the status check testci
+		req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/branch_protections?token="+token, &api.CreateBranchProtectionOption{
+			BranchName:          "master",
+			EnableStatusCheck:   true,
+			StatusCheckContexts: []string{"testci"},
+			EnableMergeQueue:    true,
+		})
+		session.MakeRequest(t, req, http.StatusCreated)
+
+		repo := models.AssertExistsAndLoadBean(t, &models.Repository{OwnerName: "user2", Name: "repo1"}).(*models.Repository)
+		createPull := func(branch string) *models.PullRequest {
+			link := path.Join("user2", "repo1", "compare", "master..."+branch)
+			req := NewRequestWithValues(t, "POST", link, map[string]string{
+				"_csrf": GetCSRF(t, session, link),
+				"title": "pull request from " + branch,
+			})
+			session.MakeRequest(t, req, http.StatusFound)
+			issue := models.AssertExistsAndLoadBean(t, &models.Issue{RepoID: repo.ID, Title: "pull request from " + branch}).(*models.Issue)
+			return models.AssertExistsAndLoadBean(t, &models.PullRequest{IssueID: issue.ID}).(*models.PullRequest)
+		}
+		pr := createPull("queued")
+		mergeURL := fmt.Sprintf("/api/v1/repos/user2/repo1/pulls/%d/merge?token=%s", pr.Index, token)
+		queueURL := fmt.Sprintf("/api/v1/repos/user2/repo1/pulls/%d/merge_queue?token=%s", pr.Index, token)
+
+		// Merging the pull request adds it to the merge queue
+		session.MakeRequest(t, NewRequest(t, "GET", queueURL), http.StatusNotFound)
+		req = NewRequestWithJSON(t, "POST", mergeURL, &auth.MergePullRequestForm{Do: string(models.MergeStyleMerge)})
+		session.MakeRequest(t, req, http.StatusAccepted)
+		req = NewRequestWithJSON(t, "POST", mergeURL, &auth.MergePullRequestForm{Do: string(models.MergeStyleMerge)})
+		session.MakeRequest(t, req, http.StatusConflict)
+		models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: pr.IssueID, Type: models.CommentTypePullAddedToMergeQueue})
+
+		// Its merge is tested on a branch of the repository
+		var entry *models.MergeQueueEntry
+		for i := 0; i < 50; i++ {
+			entry = models.AssertExistsAndLoadBean(t, &models.MergeQueueEntry{PullID: pr.ID}).(*models.MergeQueueEntry)
+			if entry.IsTesting() {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		assert.True(t, entry.IsTesting())
+		assert.Equal(t, fmt.Sprintf("merge-queue/pr-%d", pr.Index), entry.TestBranch)
+
+		resp := session.MakeRequest(t, NewRequest(t, "GET", queueURL), http.StatusOK)
+		var apiEntry api.PullRequestMergeQueueEntry
+		DecodeJSON(t, resp, &apiEntry)
+		assert.EqualValues(t, 1, apiEntry.Position)
+		assert.Equal(t, "master", apiEntry.BaseBranch)
+		assert.Equal(t, "user2", apiEntry.Merger.UserName)
+		assert.True(t, apiEntry.Testing)
+		assert.Equal(t, entry.TestCommitID, apiEntry.TestCommitSHA)
+
+		req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/branches/%s?token=%s", entry.TestBranch, token)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		var branch api.Branch
+		DecodeJSON(t, resp, &branch)
+		assert.Equal(t, entry.TestCommitID, branch.Commit.ID)
+
+		pullLink := path.Join("user2", "repo1", "pulls", fmt.Sprint(pr.Index))
+		resp = session.MakeRequest(t, NewRequest(t, "GET", pullLink), http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		assert.EqualValues(t, 1, htmlDoc.doc.Find(".merge-queue").Length())
+		_, exists := htmlDoc.doc.Find("form[action$='/remove_from_merge_queue']").Attr("action")
+		assert.True(t, exists)
+
+		// A second pull request waits behind the first one until it is removed from the queue
+		removed := createPull("removed")
+		req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/pulls/%d/merge?token=%s", removed.Index, token),
+			&auth.MergePullRequestForm{Do: string(models.MergeStyleMerge)})
+		session.MakeRequest(t, req, http.StatusAccepted)
+		removedQueueURL := fmt.Sprintf("/api/v1/repos/user2/repo1/pulls/%d/merge_queue?token=%s", removed.Index, token)
+		resp = session.MakeRequest(t, NewRequest(t, "GET", removedQueueURL), http.StatusOK)
+		DecodeJSON(t, resp, &apiEntry)
+		assert.EqualValues(t, 2, apiEntry.Position)
+		assert.False(t, apiEntry.Testing)
+
+		session.MakeRequest(t, NewRequest(t, "DELETE", removedQueueURL), http.StatusNoContent)
+		models.AssertNotExistsBean(t, &models.MergeQueueEntry{PullID: removed.ID})
+		models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: removed.IssueID, Type: models.CommentTypePullRemovedFromMergeQueue})
+		session.MakeRequest(t, NewRequest(t, "DELETE", removedQueueURL), http.StatusNotFound)
+
+		// The tested merge is merged once its check succeeds
+		req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/statuses/%s?token=%s", entry.TestCommitID, token),
+			api.CreateStatusOption{
+				State:   api.StatusSuccess,
+				Context: "testci",
+			},
+		)
+		session.MakeRequest(t, req, http.StatusCreated)
+
+		for i := 0; i < 50; i++ {
+			pr = models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: pr.ID}).(*models.PullRequest)
+			if pr.HasMerged {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		assert.True(t, pr.HasMerged)
+		assert.EqualValues(t, 2, pr.MergerID)
+		assert.Equal(t, entry.TestCommitID, pr.MergedCommitID)
+
+		req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/branches/master?token=%s", token)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		DecodeJSON(t, resp, &branch)
+		assert.Equal(t, entry.TestCommitID, branch.Commit.ID)
+
+		// The pull request leaves the queue and its test branch is deleted
+		for i := 0; i < 50; i++ {
+			if entry, err := models.GetMergeQueueEntryByPullID(pr.ID); assert.NoError(t, err) && entry == nil {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		models.AssertNotExistsBean(t, &models.MergeQueueEntry{PullID: pr.ID})
+		req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/branches/%s?token=%s", entry.TestBranch, token)
+		session.MakeRequest(t, req, http.StatusNotFound)
+	})
+}
+
+func TestPullMergeQueueHeadChanged(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session)
+		testEditFileToNewBranch(t, session, "user2", "repo1", "master", "changed", "README.md", "changed")
+
+		req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/branch_protections?token="+token, &api.CreateBranchProtectionOption{
+			BranchName:          "master",
+			EnableStatusCheck:   true,
+			StatusCheckContexts: []string{"testci"},
+			EnableMergeQueue:    true,
+		})
+		session.MakeRequest(t, req, http.StatusCreated)
+
+		req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/pulls?token="+token, &api.CreatePullRequestOption{
+			Head:  "changed",
+			Base:  "master",
+			Title: "pull request from changed",
+		})
+		resp := session.MakeRequest(t, req, http.StatusCreated)
+		var apiPull api.PullRequest
+		DecodeJSON(t, resp, &apiPull)
+		pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: apiPull.ID}).(*models.PullRequest)
+
+		req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/pulls/%d/merge?token=%s", pr.Index, token),
+			&auth.MergePullRequestForm{Do: string(models.MergeStyleMerge)})
+		session.MakeRequest(t, req, http.StatusAccepted)
+
+		var entry *models.MergeQueueEntry
+		for i := 0; i < 50; i++ {
+			entry = models.AssertExistsAndLoadBean(t, &models.MergeQueueEntry{PullID: pr.ID}).(*models.MergeQueueEntry)
+			if entry.IsTesting() {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		assert.True(t, entry.IsTesting())
+		assert.Equal(t, apiPull.Head.Sha, entry.HeadCommitID)
+
+		// The commits pushed after the pull request has been added aren't merged by the queue
+		testEditFile(t, session, "user2", "repo1", "changed", "README.md", "changed again")
+		for i := 0; i < 50; i++ {
+			if entry, err := models.GetMergeQueueEntryByPullID(pr.ID); assert.NoError(t, err) && entry == nil {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		models.AssertNotExistsBean(t, &models.MergeQueueEntry{PullID: pr.ID})
+		models.AssertExistsAndLoadBean(t, &models.Comment{
+			IssueID: pr.IssueID,
+			Type:    models.CommentTypePullRemovedFromMergeQueue,
+			Content: models.MergeQueueRemovalHeadChanged,
+		})
+
+		req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/statuses/%s?token=%s", entry.TestCommitID, token),
+			api.CreateStatusOption{
+				State:   api.StatusSuccess,
+				Context: "testci",
+			},
+		)
+		session.MakeRequest(t, req, http.StatusCreated)
+		time.Sleep(500 * time.Millisecond)
+		pr = models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: pr.ID}).(*models.PullRequest)
+		assert.False(t, pr.HasMerged)
+	})
+}
+
+func TestPullAutoMergeIntoMergeQueue(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session)
+		testEditFileToNewBranch(t, session, "user2", "repo1", "master", "scheduled", "README.md", "scheduled")
+
+		req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/branch_protections?token="+token, &api.CreateBranchProtectionOption{
+			BranchName:          "master",
+			EnableStatusCheck:   true,
+			StatusCheckContexts: []string{"testci"},
+		})
+		session.MakeRequest(t, req, http.StatusCreated)
+
+		req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/pulls?token="+token, &api.CreatePullRequestOption{
+			Head:  "scheduled",
+			Base:  "master",
+			Title: "pull request from scheduled",
+		})
+		resp := session.MakeRequest(t, req, http.StatusCreated)
+		var apiPull api.PullRequest
+		DecodeJSON(t, resp, &apiPull)
+
+		req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/pulls/%d/merge?token=%s", apiPull.Index, token), &auth.MergePullRequestForm{
+			Do:                     string(models.MergeStyleMerge),
+			MergeWhenChecksSucceed: true,
+		})
+		session.MakeRequest(t, req, http.StatusAccepted)
+		models.AssertExistsAndLoadBean(t, &models.PullAutoMerge{PullID: apiPull.ID})
+
+		// The merge queue is enabled once the merge has been scheduled
+		enable := true
+		req = NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1/branch_protections/master?token="+token, &api.EditBranchProtectionOption{
+			EnableMergeQueue: &enable,
+		})
+		session.MakeRequest(t, req, http.StatusOK)
+
+		// The ready pull request enters the queue instead of being merged
+		req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/statuses/%s?token=%s", apiPull.Head.Sha, token),
+			api.CreateStatusOption{
+				State:   api.StatusSuccess,
+				Context: "testci",
+			},
+		)
+		session.MakeRequest(t, req, http.StatusCreated)
+
+		var entry *models.MergeQueueEntry
+		for i := 0; i < 50; i++ {
+			var err error
+			entry, err = models.GetMergeQueueEntryByPullID(apiPull.ID)
+			assert.NoError(t, err)
+			if entry != nil && entry.IsTesting() {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		if !assert.NotNil(t, entry) {
+			return
+		}
+		assert.True(t, entry.IsTesting())
+		assert.EqualValues(t, 2, entry.DoerID)
+		models.AssertNotExistsBean(t, &models.PullAutoMerge{PullID: apiPull.ID})
+		pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: apiPull.ID}).(*models.PullRequest)
+		assert.False(t, pr.HasMerged)
+
+		// It is merged by the queue once the check of its tested merge succeeds
+		req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/statuses/%s?token=%s", entry.TestCommitID, token),
+			api.CreateStatusOption{
+				State:   api.StatusSuccess,
+				Context: "testci",
+			},
+		)
+		session.MakeRequest(t, req, http.StatusCreated)
+		for i := 0; i < 50; i++ {
+			pr = models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: apiPull.ID}).(*models.PullRequest)
+			if pr.HasMerged {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		assert.True(t, pr.HasMerged)
+		assert.Equal(t, entry.TestCommitID, pr.MergedCommitID)
+	})
+}
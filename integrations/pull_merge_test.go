@@ -12,11 +12,13 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
 	"code.gitea.io/gitea/modules/git"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/test"
@@ -148,6 +150,106 @@ func TestPullSquash(t *testing.T) {
 	})
 }
 
+func testPullEnableFastForward(t *testing.T, session *TestSession, user, repo string) {
+	hasPullRequests := true
+	allowFastForward := true
+	token := getTokenForLoggedInUser(t, session)
+	req := NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/repos/%s/%s?token=%s", user, repo, token), &api.EditRepoOption{
+		HasPullRequests:        &hasPullRequests,
+		AllowRebaseFastForward: &allowFastForward,
+		AllowFastForwardOnly:   &allowFastForward,
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+}
+
+func testPullFastForward(t *testing.T, mergeStyle models.MergeStyle) {
+	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
+		hookTasks, err := models.HookTasks(1, 1) //Retrieve previous hook number
+		assert.NoError(t, err)
+		hookTasksLenBefore := len(hookTasks)
+
+		session := loginUser(t, "user1")
+		testPullEnableFastForward(t, session, "user2", "repo1")
+		testRepoFork(t, session, "user2", "repo1", "user1", "repo1")
+		testEditFile(t, session, "user1", "repo1", "master", "README.md", "Hello, World (Edited)\n")
+
+		resp := testPullCreate(t, session, "user1", "repo1", "master", "This is a pull title")
+
+		elem := strings.Split(test.RedirectURL(resp), "/")
+		assert.EqualValues(t, "pulls", elem[3])
+		testPullMerge(t, session, elem[1], elem[2], elem[4], mergeStyle)
+
+		// The base branch is fast-forwarded to the head commit and the merge is notified once
+		headCommitID, err := git.GetFullCommitID(models.RepoPath("user1", "repo1"), git.BranchPrefix+"master")
+		assert.NoError(t, err)
+		baseCommitID, err := git.GetFullCommitID(models.RepoPath("user2", "repo1"), git.BranchPrefix+"master")
+		assert.NoError(t, err)
+		assert.Equal(t, headCommitID, baseCommitID)
+
+		index, err := strconv.ParseInt(elem[4], 10, 64)
+		assert.NoError(t, err)
+		pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{BaseRepoID: 1, Index: index}).(*models.PullRequest)
+		assert.True(t, pr.HasMerged)
+		assert.Equal(t, headCommitID, pr.MergedCommitID)
+		assert.Equal(t, models.PullRequestStatusMergeable, pr.Status)
+
+		hookTasks, err = models.HookTasks(1, 1)
+		assert.NoError(t, err)
+		assert.Len(t, hookTasks, hookTasksLenBefore+1)
+	})
+}
+
+func TestPullRebaseFastForward(t *testing.T) {
+	testPullFastForward(t, models.MergeStyleRebaseFastForward)
+}
+
+func TestPullFastForwardOnly(t *testing.T) {
+	testPullFastForward(t, models.MergeStyleFastForwardOnly)
+}
+
+func TestPullSquashMessageTemplate(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
+		session := loginUser(t, "user1")
+		token := getTokenForLoggedInUser(t, session)
+		hasPullRequests := true
+		template := "${PullRequestTitle} (${PullRequestReference})\r\n\r\nSquashed from ${HeadBranch}\r\n"
+		req := NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1?token="+token, &api.EditRepoOption{
+			HasPullRequests:       &hasPullRequests,
+			SquashMessageTemplate: &template,
+		})
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		var repo api.Repository
+		DecodeJSON(t, resp, &repo)
+		assert.Equal(t, template, repo.SquashMessageTemplate)
+
+		testRepoFork(t, session, "user2", "repo1", "user1", "repo1")
+		testEditFile(t, session, "user1", "repo1", "master", "README.md", "Hello, World (Edited)\n")
+		resp = testPullCreate(t, session, "user1", "repo1", "master", "This is a pull title")
+		elem := strings.Split(test.RedirectURL(resp), "/")
+		assert.EqualValues(t, "pulls", elem[3])
+
+		// The squash message is prefilled from the template
+		resp = session.MakeRequest(t, NewRequest(t, "GET", path.Join(elem[1], elem[2], "pulls", elem[4])), http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		title, _ := htmlDoc.doc.Find(".squash-fields input[name=merge_title_field]").Attr("value")
+		assert.Equal(t, "This is a pull title (#"+elem[4]+")", title)
+		assert.Equal(t, "Squashed from master", htmlDoc.doc.Find(".squash-fields textarea[name=merge_message_field]").Text())
+
+		// And used by default by the API
+		req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/pulls/%s/merge?token=%s", elem[4], token), &auth.MergePullRequestForm{
+			Do: string(models.MergeStyleSquash),
+		})
+		session.MakeRequest(t, req, http.StatusOK)
+
+		gitRepo, err := git.OpenRepository(models.RepoPath("user2", "repo1"))
+		assert.NoError(t, err)
+		defer gitRepo.Close()
+		commit, err := gitRepo.GetBranchCommit("master")
+		assert.NoError(t, err)
+		assert.Equal(t, "This is a pull title (#"+elem[4]+")\n\nSquashed from master\n", commit.CommitMessage)
+	})
+}
+
 func TestPullCleanUpAfterMerge(t *testing.T) {
 	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
 		session := loginUser(t, "user1")
@@ -249,6 +351,47 @@ func TestCantMergeConflict(t *testing.T) {
 	})
 }
 
+func TestCantFastForwardDiverging(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
+		session := loginUser(t, "user1")
+		testRepoFork(t, session, "user2", "repo1", "user1", "repo1")
+		testPullEnableFastForward(t, session, "user1", "repo1")
+		testEditFileToNewBranch(t, session, "user1", "repo1", "master", "diverging", "README.md", "Hello, World (Edited Once)\n")
+		testEditFileToNewBranch(t, session, "user1", "repo1", "master", "base", "README.md", "Hello, World (Edited Twice)\n")
+
+		token := getTokenForLoggedInUser(t, session)
+		req := NewRequestWithJSON(t, http.MethodPost, fmt.Sprintf("/api/v1/repos/%s/%s/pulls?token=%s", "user1", "repo1", token), &api.CreatePullRequestOption{
+			Head:  "diverging",
+			Base:  "base",
+			Title: "create a diverging pr",
+		})
+		session.MakeRequest(t, req, 201)
+
+		user1 := models.AssertExistsAndLoadBean(t, &models.User{
+			Name: "user1",
+		}).(*models.User)
+		repo1 := models.AssertExistsAndLoadBean(t, &models.Repository{
+			OwnerID: user1.ID,
+			Name:    "repo1",
+		}).(*models.Repository)
+
+		pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{
+			HeadRepoID: repo1.ID,
+			BaseRepoID: repo1.ID,
+			HeadBranch: "diverging",
+			BaseBranch: "base",
+		}).(*models.PullRequest)
+
+		gitRepo, err := git.OpenRepository(models.RepoPath(user1.Name, repo1.Name))
+		assert.NoError(t, err)
+		defer gitRepo.Close()
+
+		err = pull.Merge(pr, user1, gitRepo, models.MergeStyleFastForwardOnly, "")
+		assert.Error(t, err, "Merge should return an error due to the diverging branches")
+		assert.True(t, models.IsErrMergeDivergingFastForwardOnly(err), "Merge error is not a diverging error")
+	})
+}
+
 func TestCantMergeUnrelated(t *testing.T) {
 	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
 		session := loginUser(t, "user1")
@@ -6,10 +6,15 @@ package integrations
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"net/url"
+	"os"
 	"testing"
 	"time"
 
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/test"
 
 	"github.com/stretchr/testify/assert"
@@ -76,6 +81,21 @@ func TestViewReleasesNoLogin(t *testing.T) {
 	MakeRequest(t, req, http.StatusOK)
 }
 
+func TestViewReleasesFeed(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	req := NewRequest(t, "GET", "/user2/repo1/releases.rss")
+	resp := MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, "application/rss+xml;charset=utf-8", resp.Header().Get("Content-Type"))
+	assert.Contains(t, resp.Body.String(), "<title>testing-release</title>")
+
+	req = NewRequest(t, "GET", "/user2/repo1/releases.atom")
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, "application/atom+xml;charset=utf-8", resp.Header().Get("Content-Type"))
+	assert.Contains(t, resp.Body.String(), "<title>testing-release</title>")
+	assert.Contains(t, resp.Body.String(), "/user2/repo1/releases/tag/v1.1")
+}
+
 func TestCreateRelease(t *testing.T) {
 	defer prepareTestEnv(t)()
 
@@ -120,3 +140,91 @@ func TestCreateReleasePaging(t *testing.T) {
 	session2 := loginUser(t, "user4")
 	checkLatestReleaseAndCount(t, session2, "/user2/repo1", "v0.0.11", i18n.Tr("en", "repo.release.stable"), 10)
 }
+
+func TestDownloadReleaseArchive(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	for _, ext := range []string{".zip", ".tar.gz"} {
+		req := NewRequest(t, "GET", "/user2/repo1/archive/v1.1"+ext)
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		assert.NotEmpty(t, resp.Body.Bytes())
+
+		// The second download is served from the cached archive
+		req = NewRequest(t, "GET", "/user2/repo1/archive/v1.1"+ext)
+		resp2 := session.MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, resp.Body.Bytes(), resp2.Body.Bytes())
+	}
+}
+
+func TestAutoReleaseOnTagPush(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+		assert.NoError(t, models.UpdateRepositoryUnits(repo, []models.RepoUnit{{
+			RepoID: repo.ID,
+			Type:   models.UnitTypeReleases,
+			Config: &models.ReleasesConfig{AutoReleaseTagPatterns: "v*"},
+		}}, nil))
+
+		dstPath, err := ioutil.TempDir("", "repo1")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dstPath)
+
+		u.Path = "user2/repo1.git"
+		u.User = url.UserPassword("user2", userPassword)
+		t.Run("Clone", doGitClone(dstPath, u))
+
+		for _, tagName := range []string{"v2.0", "nightly"} {
+			_, err = git.NewCommand("tag", tagName).RunInDir(dstPath)
+			assert.NoError(t, err)
+			t.Run("PushTag", doGitPushTestRepository(dstPath, "origin", tagName))
+		}
+
+		rel := models.AssertExistsAndLoadBean(t, &models.Release{RepoID: repo.ID, TagName: "v2.0"}).(*models.Release)
+		assert.False(t, rel.IsTag)
+		assert.EqualValues(t, 2, rel.PublisherID)
+		models.AssertExistsAndLoadBean(t, &models.Release{RepoID: repo.ID, TagName: "nightly", IsTag: true})
+
+		session := loginUser(t, "user2")
+		checkLatestReleaseAndCount(t, session, "/user2/repo1", "v2.0", i18n.Tr("en", "repo.release.stable"), 3)
+	})
+}
+
+func TestReleaseComments(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user4")
+	req := NewRequest(t, "GET", "/user2/repo1/releases/tag/v1.1")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 1, htmlDoc.doc.Find("#release-comments .comment").Length())
+	link, exists := htmlDoc.doc.Find("#release-comments form").Attr("action")
+	assert.True(t, exists)
+
+	req = NewRequestWithValues(t, "POST", link, map[string]string{
+		"_csrf":   htmlDoc.GetCSRF(),
+		"content": "works **great**",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	comment := models.AssertExistsAndLoadBean(t, &models.ReleaseComment{ReleaseID: 1, PosterID: 4}).(*models.ReleaseComment)
+
+	req = NewRequest(t, "GET", "/user2/repo1/releases/tag/v1.1")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, "great", htmlDoc.doc.Find("#"+comment.HashTag()+" .render-content strong").Text())
+
+	// Only the poster and the repository administrators can delete a comment
+	session5 := loginUser(t, "user5")
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/releases/comments/delete", map[string]string{
+		"_csrf": GetCSRF(t, session5, "/user2/repo1/releases/tag/v1.1"),
+		"id":    fmt.Sprint(comment.ID),
+	})
+	session5.MakeRequest(t, req, http.StatusForbidden)
+
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/releases/comments/delete", map[string]string{
+		"_csrf": htmlDoc.GetCSRF(),
+		"id":    fmt.Sprint(comment.ID),
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertNotExistsBean(t, &models.ReleaseComment{ID: comment.ID})
+}
@@ -0,0 +1,60 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoBlame(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	req := NewRequest(t, "GET", "/user2/repo1/blame/branch/master/README.md")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	doc := NewHTMLParser(t, resp.Body)
+
+	// The lines are rendered right away, the blame is loaded from the incremental link
+	assert.Equal(t, 3, doc.doc.Find(".lines-code li").Length())
+	assert.Equal(t, 3, doc.doc.Find(".lines-commit .blame-info").Length())
+	assert.Equal(t, "Description for repo1", doc.doc.Find(".lines-code li.L3").Text())
+	blameURL, exists := doc.doc.Find(".lines-commit").Attr("data-blame-url")
+	assert.True(t, exists)
+	assert.Equal(t, "/user2/repo1/blame/incremental/commit/65f1bf27bc3bf70f64657658635e66094edbcb4d/README.md", blameURL)
+
+	req = NewRequest(t, "GET", blameURL)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Header().Get("Content-Type"), "application/x-ndjson")
+
+	type blameEntry struct {
+		Sha       string `json:"sha"`
+		StartLine int    `json:"start_line"`
+		NumLines  int    `json:"num_lines"`
+		HTML      string `json:"html"`
+	}
+	var entries []*blameEntry
+	scanner := bufio.NewScanner(strings.NewReader(resp.Body.String()))
+	for scanner.Scan() {
+		var entry blameEntry
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, &entry)
+	}
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", entries[0].Sha)
+		assert.Equal(t, 1, entries[0].StartLine)
+		assert.Equal(t, 3, entries[0].NumLines)
+		assert.Contains(t, entries[0].HTML, `href="/user2/repo1/commit/65f1bf27bc3bf70f64657658635e66094edbcb4d"`)
+		assert.Contains(t, entries[0].HTML, "Initial commit")
+	}
+
+	req = NewRequest(t, "GET", "/user2/repo1/blame/incremental/commit/65f1bf27bc3bf70f64657658635e66094edbcb4d/not-exist.md")
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
@@ -11,6 +11,8 @@ import (
 	"path"
 	"testing"
 
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 
@@ -79,6 +81,63 @@ func doTestRepoCommitWithStatus(t *testing.T, state string, classes ...string) {
 	testRepoCommitsWithStatus(t, session.MakeRequest(t, req, http.StatusOK), state)
 	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/commits/v1.1/statuses")
 	testRepoCommitsWithStatus(t, session.MakeRequest(t, req, http.StatusOK), state)
+
+	// Combined status
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/commits/master/status")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var combined api.CombinedStatus
+	DecodeJSON(t, resp, &combined)
+	assert.Equal(t, api.StatusState(state), combined.State)
+	assert.Equal(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", combined.SHA)
+	assert.Equal(t, 1, combined.TotalCount)
+	assert.Len(t, combined.Statuses, 1)
+	assert.Equal(t, "user2/repo1", combined.Repository.FullName)
+	assert.Equal(t, setting.AppURL+"api/v1/repos/user2/repo1/git/commits/65f1bf27bc3bf70f64657658635e66094edbcb4d", combined.CommitURL)
+}
+
+func TestRepoCommitsCombinedStatus(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	sha := "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+	for _, status := range []api.CreateStatusOption{
+		{State: api.StatusFailure, Context: "ci"},
+		{State: api.StatusSuccess, Context: "coverage"},
+		{State: api.StatusWarning, Context: "lint"},
+		// only the latest status of a context counts
+		{State: api.StatusSuccess, Context: "ci"},
+	} {
+		req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/statuses/"+sha+"?token="+token, status)
+		session.MakeRequest(t, req, http.StatusCreated)
+	}
+
+	req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1/commits/"+sha+"/status")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var combined api.CombinedStatus
+	DecodeJSON(t, resp, &combined)
+	assert.Equal(t, api.StatusWarning, combined.State)
+	assert.Equal(t, 3, combined.TotalCount)
+	assert.Len(t, combined.Statuses, 3)
+
+	// the state isn't affected by the pagination of the statuses
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/commits/master/status?page=2&limit=2")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	combined = api.CombinedStatus{}
+	DecodeJSON(t, resp, &combined)
+	assert.Equal(t, api.StatusWarning, combined.State)
+	assert.Equal(t, 3, combined.TotalCount)
+	assert.Len(t, combined.Statuses, 1)
+
+	// no status
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/commits/"+sha+"5/status")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	combined = api.CombinedStatus{}
+	DecodeJSON(t, resp, &combined)
+	assert.Equal(t, api.StatusState(""), combined.State)
+	assert.Equal(t, 0, combined.TotalCount)
+	assert.Len(t, combined.Statuses, 0)
 }
 
 func testRepoCommitsWithStatus(t *testing.T, resp *httptest.ResponseRecorder, state string) {
@@ -114,3 +173,48 @@ func TestRepoCommitsWithStatusFailure(t *testing.T) {
 func TestRepoCommitsWithStatusWarning(t *testing.T) {
 	doTestRepoCommitWithStatus(t, "warning", "warning", "sign", "yellow")
 }
+
+func TestRepoCommitsReplaced(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	// "a change" is replaced by "For PR3" in the history of branch2
+	replacedID := "5c050d3b6d2db231ab1f64e324f1b6b9a0b181c2"
+	replacementID := "4a357436d925b5c974181ff12a994538ddc5a269"
+	_, err := git.NewCommand("replace", replacedID, replacementID).RunInDir(models.RepoPath("user2", "repo1"))
+	assert.NoError(t, err)
+
+	session := loginUser(t, "user2")
+
+	req := NewRequest(t, "GET", "/user2/repo1/commits/branch/branch2")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	doc := NewHTMLParser(t, resp.Body)
+	rows := doc.doc.Find("#commits-table tbody tr")
+	assert.Equal(t, 3, rows.Length())
+	replacedRow := rows.Eq(1)
+	assert.Contains(t, replacedRow.Find("td.message").Text(), "For PR3")
+	assert.Equal(t, 1, replacedRow.Find("td.message .label .octicon-sync").Length())
+	assert.Equal(t, 0, rows.Eq(0).Find("td.message .label .octicon-sync").Length())
+
+	req = NewRequest(t, "GET", "/user2/repo1/commit/"+replacedID)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	doc = NewHTMLParser(t, resp.Body)
+	assert.Contains(t, doc.doc.Find(".commit-summary").Text(), "For PR3")
+	link, exists := doc.doc.Find(".ui.info.message a").Last().Attr("href")
+	assert.True(t, exists)
+	assert.Equal(t, "/user2/repo1/commit/"+replacementID, link)
+
+	req = NewRequest(t, "GET", "/user2/repo1/src/commit/"+replacedID)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	doc = NewHTMLParser(t, resp.Body)
+	assert.Equal(t, 1, doc.doc.Find(".ui.info.message .octicon-sync").Length())
+
+	req = NewRequest(t, "GET", "/user2/repo1/blame/commit/"+replacedID+"/README.md")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	doc = NewHTMLParser(t, resp.Body)
+	assert.Equal(t, 1, doc.doc.Find(".ui.info.message .octicon-sync").Length())
+
+	req = NewRequest(t, "GET", "/user2/repo1/src/branch/branch2")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	doc = NewHTMLParser(t, resp.Body)
+	assert.Equal(t, 0, doc.doc.Find(".ui.info.message .octicon-sync").Length())
+}
@@ -0,0 +1,71 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoGitConfigSettings(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user2")
+
+	req := NewRequest(t, "GET", "/user2/repo1/settings")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.Equal(t, 0, htmlDoc.doc.Find("input[name=action][value=git_config]").Length())
+
+	defer func(allowlist []string) {
+		setting.Repository.GitConfigAllowlist = allowlist
+	}(setting.Repository.GitConfigAllowlist)
+	setting.Repository.GitConfigAllowlist = []string{"core.bigFileThreshold", "pack.window"}
+
+	req = NewRequest(t, "GET", "/user2/repo1/settings")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	assert.Equal(t, 1, htmlDoc.doc.Find("input[name=action][value=git_config]").Length())
+	assert.Equal(t, 1, htmlDoc.doc.Find(`input[name="git_config.pack.window"]`).Length())
+
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/settings", map[string]string{
+		"_csrf":                            htmlDoc.GetCSRF(),
+		"action":                           "git_config",
+		"git_config.core.bigFileThreshold": " 2m ",
+		"git_config.pack.window":           "",
+		"git_config.core.hooksPath":        "/tmp",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertExistsAndLoadBean(t, &models.RepoGitConfig{RepoID: 1, Name: "core.bigFileThreshold", Value: "2m"})
+	models.AssertNotExistsBean(t, &models.RepoGitConfig{RepoID: 1, Name: "pack.window"})
+	models.AssertNotExistsBean(t, &models.RepoGitConfig{RepoID: 1, Name: "core.hooksPath"})
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	value, err := git.NewCommand("config", "--get", "core.bigFileThreshold").RunInDir(repo.RepoPath())
+	assert.NoError(t, err)
+	assert.Equal(t, "2m", strings.TrimSpace(value))
+
+	req = NewRequest(t, "GET", "/user2/repo1/settings")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	value, _ = htmlDoc.doc.Find(`input[name="git_config.core.bigFileThreshold"]`).Attr("value")
+	assert.Equal(t, "2m", value)
+
+	// Only the admins of the repository can change its git config
+	session = loginUser(t, "user4")
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/settings", map[string]string{
+		"_csrf":                  GetCSRF(t, session, "/user/settings"),
+		"action":                 "git_config",
+		"git_config.pack.window": "10",
+	})
+	session.MakeRequest(t, req, http.StatusNotFound)
+	models.AssertNotExistsBean(t, &models.RepoGitConfig{RepoID: 1, Name: "pack.window"})
+}
@@ -0,0 +1,44 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoSigningSettings(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user2")
+
+	req := NewRequest(t, "GET", "/user2/repo1/settings")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	value, exists := htmlDoc.doc.Find("input[name=trust_model]").Attr("value")
+	assert.True(t, exists)
+	assert.Equal(t, "default", value)
+	assert.Equal(t, 5, htmlDoc.doc.Find("input[name=trust_model]").Parent().Find(".menu .item").Length())
+
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/settings", map[string]string{
+		"_csrf":        htmlDoc.GetCSRF(),
+		"action":       "signing",
+		"trust_model":  "committer",
+		"trusted_keys": " 0123456789ABCDEF ",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1, TrustModel: models.CommitterTrustModel, TrustedKeys: "0123456789ABCDEF"})
+
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/settings", map[string]string{
+		"_csrf":       htmlDoc.GetCSRF(),
+		"action":      "signing",
+		"trust_model": "unknown",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1, TrustModel: models.CommitterTrustModel})
+}
@@ -0,0 +1,48 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoSettingsTransfer(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	transfer := func(newOwner string, status int) *httptest.ResponseRecorder {
+		req := NewRequestWithValues(t, "POST", "/user2/repo16/settings", map[string]string{
+			"_csrf":          GetCSRF(t, session, "/user2/repo16/settings"),
+			"action":         "transfer",
+			"repo_name":      "repo16",
+			"new_owner_name": newOwner,
+		})
+		return session.MakeRequest(t, req, status)
+	}
+
+	// user2 can't create repositories for user4, the transfer waits for user4 to accept it
+	resp := transfer("user4", http.StatusFound)
+	assert.Equal(t, "/user2/repo16/settings", resp.Header().Get("Location"))
+	models.AssertExistsAndLoadBean(t, &models.RepoTransfer{RepoID: 16, DoerID: 2, RecipientID: 4})
+	models.AssertExistsAndLoadBean(t, &models.Repository{ID: 16, OwnerID: 2})
+
+	// only one transfer can be pending
+	transfer("user5", http.StatusOK)
+	models.AssertNotExistsBean(t, &models.RepoTransfer{RepoID: 16, RecipientID: 5})
+
+	// user2 owns the organization user3, the repository is transferred right away
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 16}).(*models.Repository)
+	assert.NoError(t, models.CancelRepositoryTransfer(repo))
+	resp = transfer("user3", http.StatusFound)
+	assert.Equal(t, "/user3/repo16", resp.Header().Get("Location"))
+	models.AssertExistsAndLoadBean(t, &models.Repository{ID: 16, OwnerID: 3})
+	models.AssertNotExistsBean(t, &models.RepoTransfer{RepoID: 16})
+}
@@ -0,0 +1,45 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+)
+
+func TestRepoSettingsOrgWebhookOptOut(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	req := NewRequest(t, "GET", "/user3/repo3/settings/hooks")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	htmlDoc.AssertElement(t, `a[data-url="/user3/repo3/settings/hooks/org/3/disable"]`, true)
+
+	req = NewRequestWithValues(t, "POST", "/user3/repo3/settings/hooks/org/3/disable", map[string]string{
+		"_csrf": htmlDoc.GetCSRF(),
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertExistsAndLoadBean(t, &models.WebhookOptOut{RepoID: 3, HookID: 3})
+
+	req = NewRequest(t, "GET", "/user3/repo3/settings/hooks")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	htmlDoc.AssertElement(t, `a[data-url="/user3/repo3/settings/hooks/org/3/enable"]`, true)
+
+	req = NewRequestWithValues(t, "POST", "/user3/repo3/settings/hooks/org/3/enable", map[string]string{
+		"_csrf": htmlDoc.GetCSRF(),
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertNotExistsBean(t, &models.WebhookOptOut{RepoID: 3, HookID: 3})
+
+	// repository webhooks can't be disabled
+	req = NewRequestWithValues(t, "POST", "/user3/repo3/settings/hooks/org/1/disable", map[string]string{
+		"_csrf": htmlDoc.GetCSRF(),
+	})
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
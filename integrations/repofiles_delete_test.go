@@ -52,10 +52,11 @@ func getExpectedDeleteFileResponse(u *url.URL) *api.FileResponse {
 			Message: "Deletes README.md\n",
 		},
 		Verification: &api.PayloadCommitVerification{
-			Verified:  false,
-			Reason:    "gpg.error.not_signed_commit",
-			Signature: "",
-			Payload:   "",
+			Verified:   false,
+			Reason:     "gpg.error.not_signed_commit",
+			Signature:  "",
+			Payload:    "",
+			TrustModel: "collaborator",
 		},
 	}
 }
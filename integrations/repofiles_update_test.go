@@ -107,10 +107,11 @@ func getExpectedFileResponseForRepofilesCreate(commitID string) *api.FileRespons
 			},
 		},
 		Verification: &api.PayloadCommitVerification{
-			Verified:  false,
-			Reason:    "gpg.error.not_signed_commit",
-			Signature: "",
-			Payload:   "",
+			Verified:   false,
+			Reason:     "gpg.error.not_signed_commit",
+			Signature:  "",
+			Payload:    "",
+			TrustModel: "collaborator",
 		},
 	}
 }
@@ -174,10 +175,11 @@ func getExpectedFileResponseForRepofilesUpdate(commitID, filename string) *api.F
 			},
 		},
 		Verification: &api.PayloadCommitVerification{
-			Verified:  false,
-			Reason:    "gpg.error.not_signed_commit",
-			Signature: "",
-			Payload:   "",
+			Verified:   false,
+			Reason:     "gpg.error.not_signed_commit",
+			Signature:  "",
+			Payload:    "",
+			TrustModel: "collaborator",
 		},
 	}
 }
@@ -49,8 +49,56 @@ const (
 	ActionApprovePullRequest                       // 21
 	ActionRejectPullRequest                        // 22
 	ActionCommentPull                              // 23
+	ActionCommentRelease                           // 24
+	ActionPublishRelease                           // 25
 )
 
+var actionTypeNames = map[ActionType]string{
+	ActionCreateRepo:         "create_repo",
+	ActionRenameRepo:         "rename_repo",
+	ActionStarRepo:           "star_repo",
+	ActionWatchRepo:          "watch_repo",
+	ActionCommitRepo:         "commit_repo",
+	ActionCreateIssue:        "create_issue",
+	ActionCreatePullRequest:  "create_pull_request",
+	ActionTransferRepo:       "transfer_repo",
+	ActionPushTag:            "push_tag",
+	ActionCommentIssue:       "comment_issue",
+	ActionMergePullRequest:   "merge_pull_request",
+	ActionCloseIssue:         "close_issue",
+	ActionReopenIssue:        "reopen_issue",
+	ActionClosePullRequest:   "close_pull_request",
+	ActionReopenPullRequest:  "reopen_pull_request",
+	ActionDeleteTag:          "delete_tag",
+	ActionDeleteBranch:       "delete_branch",
+	ActionMirrorSyncPush:     "mirror_sync_push",
+	ActionMirrorSyncCreate:   "mirror_sync_create",
+	ActionMirrorSyncDelete:   "mirror_sync_delete",
+	ActionApprovePullRequest: "approve_pull_request",
+	ActionRejectPullRequest:  "reject_pull_request",
+	ActionCommentPull:        "comment_pull",
+	ActionCommentRelease:     "comment_release",
+	ActionPublishRelease:     "publish_release",
+}
+
+// String returns the name of the action type
+func (t ActionType) String() string {
+	if name, ok := actionTypeNames[t]; ok {
+		return name
+	}
+	return strconv.Itoa(int(t))
+}
+
+// ActionTypeFromName returns the action type of the given name, false if there is none
+func ActionTypeFromName(name string) (ActionType, bool) {
+	for t, n := range actionTypeNames {
+		if n == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
 // Action represents user operation type and other information to
 // repository. It implemented interface base.Actioner so that can be
 // used in template render.
@@ -287,14 +335,20 @@ func (a *Action) GetIssueContent() string {
 
 // GetFeedsOptions options for retrieving feeds
 type GetFeedsOptions struct {
-	RequestedUser   *User // the user we want activity for
-	Actor           *User // the user viewing the activity
-	IncludePrivate  bool  // include private actions
-	OnlyPerformedBy bool  // only actions performed by requested user
-	IncludeDeleted  bool  // include deleted actions
+	ListOptions
+	RequestedUser   *User        // the user we want activity for
+	Actor           *User        // the user viewing the activity
+	IncludePrivate  bool         // include private actions
+	OnlyPerformedBy bool         // only actions performed by requested user
+	IncludeDeleted  bool         // include deleted actions
+	ActUserID       int64        // only actions performed by this user
+	OpTypes         []ActionType // only actions of these types
+	Since           int64        // only actions created at or after this time
+	Before          int64        // only actions created at or before this time
 }
 
-// GetFeeds returns actions according to the provided options
+// GetFeeds returns actions according to the provided options, the 20 latest ones
+// if no page size is given
 func GetFeeds(opts GetFeedsOptions) ([]*Action, error) {
 	cond := builder.NewCond()
 
@@ -338,9 +392,28 @@ func GetFeeds(opts GetFeedsOptions) ([]*Action, error) {
 		cond = cond.And(builder.Eq{"is_deleted": false})
 	}
 
-	actions := make([]*Action, 0, 20)
+	if opts.ActUserID > 0 {
+		cond = cond.And(builder.Eq{"act_user_id": opts.ActUserID})
+	}
+	if len(opts.OpTypes) > 0 {
+		cond = cond.And(builder.In("op_type", opts.OpTypes))
+	}
+	if opts.Since > 0 {
+		cond = cond.And(builder.Gte{"created_unix": opts.Since})
+	}
+	if opts.Before > 0 {
+		cond = cond.And(builder.Lte{"created_unix": opts.Before})
+	}
 
-	if err := x.Limit(20).Desc("id").Where(cond).Find(&actions); err != nil {
+	sess := x.Desc("id").Where(cond)
+	if opts.PageSize > 0 {
+		sess = opts.setSessionPagination(sess)
+	} else {
+		sess = sess.Limit(20)
+	}
+
+	actions := make([]*Action, 0, 20)
+	if err := sess.Find(&actions); err != nil {
 		return nil, fmt.Errorf("Find: %v", err)
 	}
 
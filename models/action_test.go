@@ -89,3 +89,53 @@ func TestGetFeeds2(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, actions, 0)
 }
+
+func TestGetFeedsFilters(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	org := AssertExistsAndLoadBean(t, &User{ID: 3}).(*User)
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	opts := GetFeedsOptions{
+		RequestedUser:  org,
+		Actor:          user,
+		IncludePrivate: true,
+	}
+
+	opts.ActUserID = user.ID
+	opts.OpTypes = []ActionType{ActionRenameRepo, ActionCreateIssue}
+	actions, err := GetFeeds(opts)
+	assert.NoError(t, err)
+	assert.Len(t, actions, 1)
+
+	opts.ActUserID = 4
+	actions, err = GetFeeds(opts)
+	assert.NoError(t, err)
+	assert.Len(t, actions, 0)
+
+	opts.ActUserID = 0
+	opts.OpTypes = []ActionType{ActionCreateIssue}
+	actions, err = GetFeeds(opts)
+	assert.NoError(t, err)
+	assert.Len(t, actions, 0)
+
+	opts.OpTypes = nil
+	opts.Before = 1
+	actions, err = GetFeeds(opts)
+	assert.NoError(t, err)
+	assert.Len(t, actions, 0)
+
+	opts.Before = 0
+	opts.ListOptions = ListOptions{Page: 2, PageSize: 1}
+	actions, err = GetFeeds(opts)
+	assert.NoError(t, err)
+	assert.Len(t, actions, 0)
+}
+
+func TestActionTypeName(t *testing.T) {
+	assert.Equal(t, "publish_release", ActionPublishRelease.String())
+	opType, ok := ActionTypeFromName("commit_repo")
+	assert.True(t, ok)
+	assert.Equal(t, ActionCommitRepo, opType)
+	_, ok = ActionTypeFromName("unknown")
+	assert.False(t, ok)
+}
@@ -5,12 +5,18 @@
 package models
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
 	"fmt"
 	"io"
-	"os"
+	"net/url"
 	"path"
 
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
 
@@ -29,7 +35,34 @@ type Attachment struct {
 	Name          string
 	DownloadCount int64              `xorm:"DEFAULT 0"`
 	Size          int64              `xorm:"DEFAULT 0"`
+	Sha256        string             `xorm:"VARCHAR(64)"`
+	Sha512        string             `xorm:"VARCHAR(128)"`
 	CreatedUnix   timeutil.TimeStamp `xorm:"created"`
+
+	// MirrorPath is the path of the copy in the release CDN, empty if the attachment isn't mirrored
+	MirrorPath string
+	// Role tells what a release attachment is used for, empty for a regular asset
+	Role AttachmentRole `xorm:"VARCHAR(20) NOT NULL DEFAULT ''"`
+}
+
+// AttachmentRole represents what a release attachment is used for
+type AttachmentRole string
+
+// enumerates the roles of release attachments
+const (
+	AttachmentRoleAsset       AttachmentRole = ""
+	AttachmentRoleSignature   AttachmentRole = "signature"
+	AttachmentRoleCertificate AttachmentRole = "certificate"
+	AttachmentRoleProvenance  AttachmentRole = "provenance"
+)
+
+// IsValid returns true if the role is a known one
+func (r AttachmentRole) IsValid() bool {
+	switch r {
+	case AttachmentRoleAsset, AttachmentRoleSignature, AttachmentRoleCertificate, AttachmentRoleProvenance:
+		return true
+	}
+	return false
 }
 
 // IncreaseDownloadCount is update download count + 1
@@ -42,6 +75,23 @@ func (a *Attachment) IncreaseDownloadCount() error {
 	return nil
 }
 
+// IncreaseAttachmentDownloadCounts adds the given amounts of downloads to the attachments
+func IncreaseAttachmentDownloadCounts(counts map[int64]int64) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	for id, count := range counts {
+		if _, err := sess.Exec("UPDATE `attachment` SET download_count=download_count+? WHERE id=?", count, id); err != nil {
+			return fmt.Errorf("increase attachment count: %v", err)
+		}
+	}
+
+	return sess.Commit()
+}
+
 // APIFormat converts models.Attachment to api.Attachment
 func (a *Attachment) APIFormat() *api.Attachment {
 	return &api.Attachment{
@@ -52,25 +102,57 @@ func (a *Attachment) APIFormat() *api.Attachment {
 		Size:          a.Size,
 		UUID:          a.UUID,
 		DownloadURL:   a.DownloadURL(),
+		SHA256:        a.Sha256,
+		SHA512:        a.Sha512,
+		Role:          string(a.Role),
 	}
 }
 
-// AttachmentLocalPath returns where attachment is stored in local file
-// system based on given UUID.
-func AttachmentLocalPath(uuid string) string {
-	return path.Join(setting.AttachmentPath, uuid[0:1], uuid[1:2], uuid)
+// AttachmentRelativePath returns the relative path of the attachment
+// in the attachments storage based on given UUID.
+func AttachmentRelativePath(uuid string) string {
+	return path.Join(uuid[0:1], uuid[1:2], uuid)
 }
 
-// LocalPath returns where attachment is stored in local file system.
-func (a *Attachment) LocalPath() string {
-	return AttachmentLocalPath(a.UUID)
+// RelativePath returns the relative path of the attachment in the attachments storage.
+func (a *Attachment) RelativePath() string {
+	return AttachmentRelativePath(a.UUID)
 }
 
-// DownloadURL returns the download url of the attached file
+// DownloadURL returns the download url of the attached file, the url of the release CDN if it is mirrored there
 func (a *Attachment) DownloadURL() string {
+	if mirrorURL := a.MirrorURL(); mirrorURL != "" {
+		return mirrorURL
+	}
 	return fmt.Sprintf("%sattachments/%s", setting.AppURL, a.UUID)
 }
 
+// MirrorURL returns the url of the copy of the attachment in the release CDN,
+// or an empty string if it isn't mirrored or the CDN is disabled.
+func (a *Attachment) MirrorURL() string {
+	if !setting.AttachmentCDNEnabled || a.MirrorPath == "" {
+		return ""
+	}
+	return setting.AttachmentCDNBaseURL + (&url.URL{Path: a.MirrorPath}).EscapedPath()
+}
+
+// CalculateChecksums calculates the SHA256 and SHA512 digests of the stored file
+func (a *Attachment) CalculateChecksums() error {
+	fr, err := storage.Attachments.Open(a.RelativePath())
+	if err != nil {
+		return fmt.Errorf("Open: %v", err)
+	}
+	defer fr.Close()
+
+	h256, h512 := sha256.New(), sha512.New()
+	if _, err = io.Copy(io.MultiWriter(h256, h512), fr); err != nil {
+		return fmt.Errorf("Copy: %v", err)
+	}
+	a.Sha256 = hex.EncodeToString(h256.Sum(nil))
+	a.Sha512 = hex.EncodeToString(h512.Sum(nil))
+	return nil
+}
+
 // LinkedRepository returns the linked repo if any
 func (a *Attachment) LinkedRepository() (*Repository, UnitType, error) {
 	if a.IssueID != 0 {
@@ -99,29 +181,13 @@ func (a *Attachment) LinkedRepository() (*Repository, UnitType, error) {
 func NewAttachment(attach *Attachment, buf []byte, file io.Reader) (_ *Attachment, err error) {
 	attach.UUID = gouuid.NewV4().String()
 
-	localPath := attach.LocalPath()
-	if err = os.MkdirAll(path.Dir(localPath), os.ModePerm); err != nil {
-		return nil, fmt.Errorf("MkdirAll: %v", err)
-	}
-
-	fw, err := os.Create(localPath)
-	if err != nil {
-		return nil, fmt.Errorf("Create: %v", err)
-	}
-	defer fw.Close()
-
-	if _, err = fw.Write(buf); err != nil {
-		return nil, fmt.Errorf("Write: %v", err)
-	} else if _, err = io.Copy(fw, file); err != nil {
-		return nil, fmt.Errorf("Copy: %v", err)
-	}
-
-	// Update file size
-	var fi os.FileInfo
-	if fi, err = fw.Stat(); err != nil {
-		return nil, fmt.Errorf("file size: %v", err)
+	h256, h512 := sha256.New(), sha512.New()
+	r := io.TeeReader(io.MultiReader(bytes.NewReader(buf), file), io.MultiWriter(h256, h512))
+	if attach.Size, err = storage.Attachments.Save(attach.RelativePath(), r); err != nil {
+		return nil, fmt.Errorf("Save: %v", err)
 	}
-	attach.Size = fi.Size()
+	attach.Sha256 = hex.EncodeToString(h256.Sum(nil))
+	attach.Sha512 = hex.EncodeToString(h512.Sum(nil))
 
 	if _, err := x.Insert(attach); err != nil {
 		return nil, err
@@ -238,9 +304,14 @@ func DeleteAttachments(attachments []*Attachment, remove bool) (int, error) {
 
 	if remove {
 		for i, a := range attachments {
-			if err := os.Remove(a.LocalPath()); err != nil {
+			if err := storage.Attachments.Delete(a.RelativePath()); err != nil {
 				return i, err
 			}
+			if a.MirrorPath != "" && storage.ReleaseCDN != nil {
+				if err := storage.ReleaseCDN.Delete(a.MirrorPath); err != nil {
+					log.Error("Delete mirror %s of attachment %s failed: %v", a.MirrorPath, a.UUID, err)
+				}
+			}
 		}
 	}
 	return int(cnt), nil
@@ -281,7 +352,13 @@ func updateAttachment(e Engine, atta *Attachment) error {
 		// Use uuid only if id is not set and uuid is set
 		sess = e.Where("uuid = ?", atta.UUID)
 	}
-	_, err := sess.Cols("name", "issue_id", "release_id", "comment_id", "download_count").Update(atta)
+	_, err := sess.Cols("name", "issue_id", "release_id", "comment_id", "download_count", "role").Update(atta)
+	return err
+}
+
+// UpdateAttachmentMirrorPath updates the path of the copy of the attachment in the release CDN
+func UpdateAttachmentMirrorPath(atta *Attachment) error {
+	_, err := x.ID(atta.ID).Cols("mirror_path").Update(atta)
 	return err
 }
 
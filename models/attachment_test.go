@@ -37,6 +37,14 @@ func TestUploadAttachment(t *testing.T) {
 	assert.NoError(t, err)
 	assert.EqualValues(t, user.ID, attachment.UploaderID)
 	assert.Equal(t, int64(0), attachment.DownloadCount)
+	assert.Len(t, attachment.Sha256, 64)
+	assert.Len(t, attachment.Sha512, 128)
+
+	// checksums calculated from the stored file match the ones computed on upload
+	sha256, sha512 := attachment.Sha256, attachment.Sha512
+	assert.NoError(t, attachment.CalculateChecksums())
+	assert.Equal(t, sha256, attachment.Sha256)
+	assert.Equal(t, sha512, attachment.Sha512)
 }
 
 func TestIncreaseDownloadCount(t *testing.T) {
@@ -55,6 +63,17 @@ func TestIncreaseDownloadCount(t *testing.T) {
 	assert.Equal(t, int64(1), attachment.DownloadCount)
 }
 
+func TestIncreaseAttachmentDownloadCounts(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, IncreaseAttachmentDownloadCounts(map[int64]int64{1: 3, 2: 1}))
+
+	attachment := AssertExistsAndLoadBean(t, &Attachment{ID: 1}).(*Attachment)
+	assert.EqualValues(t, 3, attachment.DownloadCount)
+	attachment = AssertExistsAndLoadBean(t, &Attachment{ID: 2}).(*Attachment)
+	assert.EqualValues(t, 2, attachment.DownloadCount)
+}
+
 func TestGetByCommentOrIssueID(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 
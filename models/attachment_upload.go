@@ -0,0 +1,195 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"code.gitea.io/gitea/modules/log"
+
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	gouuid "github.com/satori/go.uuid"
+)
+
+// AttachmentUpload represents a resumable upload of a release attachment
+// whose content is received in chunks. It expires when it isn't continued in time.
+type AttachmentUpload struct {
+	ID          int64  `xorm:"pk autoincr"`
+	UUID        string `xorm:"uuid UNIQUE"`
+	ReleaseID   int64  `xorm:"INDEX"`
+	UploaderID  int64  `xorm:"INDEX"`
+	Name        string
+	Size        int64              `xorm:"NOT NULL DEFAULT 0"`
+	Received    int64              `xorm:"NOT NULL DEFAULT 0"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	ExpiresUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+}
+
+// AttachmentUploadLocalPath returns where the received content of an upload is stored
+// in local file system based on given UUID.
+func AttachmentUploadLocalPath(uuid string) string {
+	return path.Join(setting.AttachmentPath, "uploads", uuid[0:1], uuid[1:2], uuid)
+}
+
+// LocalPath returns where the received content of the upload is stored in local file system.
+func (u *AttachmentUpload) LocalPath() string {
+	return AttachmentUploadLocalPath(u.UUID)
+}
+
+// IsComplete returns true if the whole content of the upload has been received
+func (u *AttachmentUpload) IsComplete() bool {
+	return u.Received >= u.Size
+}
+
+// IsExpired returns true if the upload hasn't been continued in time
+func (u *AttachmentUpload) IsExpired() bool {
+	return u.ExpiresUnix <= timeutil.TimeStampNow()
+}
+
+func (u *AttachmentUpload) refreshExpiry() {
+	u.ExpiresUnix = timeutil.TimeStampNow().AddDuration(setting.Repository.Release.UploadExpiry)
+}
+
+// APIFormat converts a AttachmentUpload to api.AttachmentUpload
+func (u *AttachmentUpload) APIFormat() *api.AttachmentUpload {
+	return &api.AttachmentUpload{
+		UUID:     u.UUID,
+		Name:     u.Name,
+		Size:     u.Size,
+		Offset:   u.Received,
+		Created:  u.CreatedUnix.AsTime(),
+		Updated:  u.UpdatedUnix.AsTime(),
+		Expires:  u.ExpiresUnix.AsTime(),
+		Complete: u.IsComplete(),
+	}
+}
+
+// NewAttachmentUpload creates a new upload with an empty file to append the chunks to
+func NewAttachmentUpload(upload *AttachmentUpload) (*AttachmentUpload, error) {
+	upload.UUID = gouuid.NewV4().String()
+	upload.Received = 0
+	upload.refreshExpiry()
+
+	localPath := upload.LocalPath()
+	if err := os.MkdirAll(path.Dir(localPath), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("MkdirAll: %v", err)
+	}
+	fw, err := os.Create(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("Create: %v", err)
+	}
+	if err = fw.Close(); err != nil {
+		return nil, fmt.Errorf("Close: %v", err)
+	}
+
+	if _, err := x.Insert(upload); err != nil {
+		if err := os.Remove(localPath); err != nil {
+			log.Error("Unable to remove the content of the upload %s: %v", upload.UUID, err)
+		}
+		return nil, err
+	}
+	return upload, nil
+}
+
+// GetAttachmentUploadByUUID returns the upload by given UUID, expired uploads don't exist anymore
+func GetAttachmentUploadByUUID(uuid string) (*AttachmentUpload, error) {
+	upload := &AttachmentUpload{UUID: uuid}
+	has, err := x.Get(upload)
+	if err != nil {
+		return nil, err
+	} else if !has || upload.IsExpired() {
+		return nil, ErrAttachmentUploadNotExist{UUID: uuid}
+	}
+	return upload, nil
+}
+
+// AppendChunk writes a chunk of content at the given offset of the upload,
+// the offset has to match the amount of bytes already received.
+func (u *AttachmentUpload) AppendChunk(offset int64, chunk io.Reader) error {
+	if offset != u.Received {
+		return ErrAttachmentUploadOffsetMismatch{UUID: u.UUID, Expected: u.Received, Given: offset}
+	}
+
+	fw, err := os.OpenFile(u.LocalPath(), os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("OpenFile: %v", err)
+	}
+	defer fw.Close()
+
+	// A previously interrupted chunk may have left more bytes in the file than were acknowledged.
+	if err = fw.Truncate(u.Received); err != nil {
+		return fmt.Errorf("Truncate: %v", err)
+	}
+	if _, err = fw.Seek(u.Received, io.SeekStart); err != nil {
+		return fmt.Errorf("Seek: %v", err)
+	}
+
+	// Never accept more bytes than announced on creation.
+	n, err := io.Copy(fw, io.LimitReader(chunk, u.Size-u.Received))
+	if err != nil {
+		return fmt.Errorf("Copy: %v", err)
+	}
+	u.Received += n
+	u.refreshExpiry()
+
+	_, err = x.ID(u.ID).Cols("received", "expires_unix").Update(u)
+	return err
+}
+
+// DeleteAttachmentUpload deletes the upload and its received content
+func DeleteAttachmentUpload(u *AttachmentUpload) error {
+	if _, err := x.ID(u.ID).Delete(new(AttachmentUpload)); err != nil {
+		return err
+	}
+	if err := os.Remove(u.LocalPath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("Remove: %v", err)
+	}
+	return nil
+}
+
+// DeleteAttachmentUploadsByRelease deletes all unfinished uploads of the given release
+func DeleteAttachmentUploadsByRelease(releaseID int64) error {
+	uploads := make([]*AttachmentUpload, 0, 5)
+	if err := x.Where("release_id = ?", releaseID).Find(&uploads); err != nil {
+		return err
+	}
+	for _, u := range uploads {
+		if err := DeleteAttachmentUpload(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteExpiredAttachmentUploads deletes the uploads which haven't been continued in time and their received content
+func DeleteExpiredAttachmentUploads(ctx context.Context) error {
+	log.Trace("Doing: DeleteExpiredAttachmentUploads")
+
+	uploads := make([]*AttachmentUpload, 0, 10)
+	if err := x.Where("expires_unix <= ?", timeutil.TimeStampNow()).Find(&uploads); err != nil {
+		return err
+	}
+	for _, u := range uploads {
+		select {
+		case <-ctx.Done():
+			return ErrCancelledf("before deleting the expired upload %s", u.UUID)
+		default:
+		}
+		if err := DeleteAttachmentUpload(u); err != nil {
+			return err
+		}
+	}
+
+	log.Trace("Finished: DeleteExpiredAttachmentUploads")
+	return nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttachmentUpload(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	upload, err := NewAttachmentUpload(&AttachmentUpload{
+		ReleaseID:  1,
+		UploaderID: 2,
+		Name:       "chunks.txt",
+		Size:       10,
+	})
+	assert.NoError(t, err)
+	assert.False(t, upload.IsComplete())
+
+	assert.NoError(t, upload.AppendChunk(0, strings.NewReader("hello")))
+	assert.EqualValues(t, 5, upload.Received)
+
+	err = upload.AppendChunk(3, strings.NewReader("lo world"))
+	assert.True(t, IsErrAttachmentUploadOffsetMismatch(err))
+
+	upload, err = GetAttachmentUploadByUUID(upload.UUID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, upload.Received)
+
+	// Content beyond the announced size is discarded
+	assert.NoError(t, upload.AppendChunk(5, strings.NewReader("world!!!")))
+	assert.True(t, upload.IsComplete())
+
+	content, err := ioutil.ReadFile(upload.LocalPath())
+	assert.NoError(t, err)
+	assert.Equal(t, "helloworld", string(content))
+
+	assert.NoError(t, DeleteAttachmentUpload(upload))
+	_, err = GetAttachmentUploadByUUID(upload.UUID)
+	assert.True(t, IsErrAttachmentUploadNotExist(err))
+}
+
+func TestDeleteExpiredAttachmentUploads(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	newUpload := func(name string) *AttachmentUpload {
+		upload, err := NewAttachmentUpload(&AttachmentUpload{
+			ReleaseID:  1,
+			UploaderID: 2,
+			Name:       name,
+			Size:       10,
+		})
+		assert.NoError(t, err)
+		assert.False(t, upload.IsExpired())
+		return upload
+	}
+	active := newUpload("active.txt")
+	expired := newUpload("expired.txt")
+
+	// Expired uploads can't be resumed anymore
+	expired.ExpiresUnix = timeutil.TimeStampNow() - 1
+	_, err := x.ID(expired.ID).Cols("expires_unix").Update(expired)
+	assert.NoError(t, err)
+	_, err = GetAttachmentUploadByUUID(expired.UUID)
+	assert.True(t, IsErrAttachmentUploadNotExist(err))
+
+	// Appending a chunk postpones the expiry
+	active.ExpiresUnix = timeutil.TimeStampNow() - 1
+	_, err = x.ID(active.ID).Cols("expires_unix").Update(active)
+	assert.NoError(t, err)
+	assert.NoError(t, active.AppendChunk(0, strings.NewReader("hello")))
+	assert.False(t, active.IsExpired())
+
+	assert.NoError(t, DeleteExpiredAttachmentUploads(context.Background()))
+
+	_, err = GetAttachmentUploadByUUID(active.UUID)
+	assert.NoError(t, err)
+	_, err = os.Stat(active.LocalPath())
+	assert.NoError(t, err)
+
+	AssertNotExistsBean(t, &AttachmentUpload{ID: expired.ID})
+	_, err = os.Stat(expired.LocalPath())
+	assert.True(t, os.IsNotExist(err))
+}
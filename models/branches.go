@@ -50,6 +50,8 @@ type ProtectedBranch struct {
 	DismissStaleApprovals     bool     `xorm:"NOT NULL DEFAULT false"`
 	RequireSignedCommits      bool     `xorm:"NOT NULL DEFAULT false"`
 	ProtectedFilePatterns     string   `xorm:"TEXT"`
+	EnableMergeQueue          bool     `xorm:"NOT NULL DEFAULT false"`
+	RequireCodeOwnerReviews   bool     `xorm:"NOT NULL DEFAULT false"`
 
 	CreatedUnix timeutil.TimeStamp `xorm:"created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
@@ -508,9 +510,9 @@ func (repo *Repository) GetDeletedBranches() ([]*DeletedBranch, error) {
 	return deletedBranches, x.Where("repo_id = ?", repo.ID).Desc("deleted_unix").Find(&deletedBranches)
 }
 
-// GetDeletedBranchByID get a deleted branch by its ID
+// GetDeletedBranchByID get a deleted branch of the repository by its ID
 func (repo *Repository) GetDeletedBranchByID(ID int64) (*DeletedBranch, error) {
-	deletedBranch := &DeletedBranch{ID: ID}
+	deletedBranch := &DeletedBranch{ID: ID, RepoID: repo.ID}
 	has, err := x.Get(deletedBranch)
 	if err != nil {
 		return nil, err
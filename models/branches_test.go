@@ -35,6 +35,15 @@ func TestGetDeletedBranch(t *testing.T) {
 	assert.NotNil(t, getDeletedBranch(t, firstBranch))
 }
 
+func TestGetDeletedBranchOfOtherRepository(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 2}).(*Repository)
+
+	deletedBranch, err := repo.GetDeletedBranchByID(1)
+	assert.NoError(t, err)
+	assert.Nil(t, deletedBranch)
+}
+
 func TestDeletedBranchLoadUser(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 
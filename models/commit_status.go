@@ -159,15 +159,18 @@ func sortCommitStatusesSession(sess *xorm.Session, sortType string) {
 	}
 }
 
-// GetLatestCommitStatus returns all statuses with a unique context for a given commit.
-func GetLatestCommitStatus(repo *Repository, sha string, page int) ([]*CommitStatus, error) {
+// GetLatestCommitStatus returns the latest status of each context for a given commit,
+// all of them if listOptions.Page is not set.
+func GetLatestCommitStatus(repo *Repository, sha string, listOptions ListOptions) ([]*CommitStatus, error) {
 	ids := make([]int64, 0, 10)
-	err := x.Limit(10, page*10).
-		Table(&CommitStatus{}).
+	sess := x.Table(&CommitStatus{}).
 		Where("repo_id = ?", repo.ID).And("sha = ?", sha).
 		Select("max( id ) as id").
-		GroupBy("context_hash").OrderBy("max( id ) desc").Find(&ids)
-	if err != nil {
+		GroupBy("context_hash").OrderBy("max( id ) desc")
+	if listOptions.Page > 0 {
+		sess = listOptions.setSessionPagination(sess)
+	}
+	if err := sess.Find(&ids); err != nil {
 		return nil, err
 	}
 	statuses := make([]*CommitStatus, 0, len(ids))
@@ -282,7 +285,7 @@ func ParseCommitsWithStatus(oldCommits *list.List, repo *Repository) *list.List
 		commit := SignCommitWithStatuses{
 			SignCommit: &c,
 		}
-		statuses, err := GetLatestCommitStatus(repo, commit.ID.String(), 0)
+		statuses, err := GetLatestCommitStatus(repo, commit.ID.String(), ListOptions{})
 		if err != nil {
 			log.Error("GetLatestCommitStatus: %v", err)
 		} else {
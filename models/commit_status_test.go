@@ -43,3 +43,31 @@ func TestGetCommitStatuses(t *testing.T) {
 	assert.Equal(t, structs.CommitStatusError, statuses[4].State)
 	assert.Equal(t, "https://try.gitea.io/api/v1/repos/user2/repo1/statuses/1234123412341234123412341234123412341234", statuses[4].APIURL())
 }
+
+func TestGetLatestCommitStatus(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo1 := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	sha1 := "1234123412341234123412341234123412341234"
+
+	statuses, err := GetLatestCommitStatus(repo1, sha1, ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 3)
+
+	states := make(map[string]structs.CommitStatusState, len(statuses))
+	for _, status := range statuses {
+		states[status.Context] = status.State
+	}
+	assert.Equal(t, map[string]structs.CommitStatusState{
+		"ci/awesomeness":     structs.CommitStatusFailure,
+		"cov/awesomeness":    structs.CommitStatusSuccess,
+		"deploy/awesomeness": structs.CommitStatusError,
+	}, states)
+	assert.Equal(t, structs.CommitStatusError, CalcCommitStatus(statuses).State)
+
+	statuses, err = GetLatestCommitStatus(repo1, sha1, ListOptions{Page: 2, PageSize: 2})
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 1)
+	assert.EqualValues(t, 3, statuses[0].ID)
+}
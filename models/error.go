@@ -7,6 +7,7 @@ package models
 
 import (
 	"fmt"
+	"strings"
 
 	"code.gitea.io/gitea/modules/git"
 )
@@ -586,6 +587,21 @@ func (err ErrAccessTokenEmpty) Error() string {
 	return "access token is empty"
 }
 
+// ErrInvalidAccessTokenScope represents a "InvalidAccessTokenScope" kind of error.
+type ErrInvalidAccessTokenScope struct {
+	Scope string
+}
+
+// IsErrInvalidAccessTokenScope checks if an error is a ErrInvalidAccessTokenScope.
+func IsErrInvalidAccessTokenScope(err error) bool {
+	_, ok := err.(ErrInvalidAccessTokenScope)
+	return ok
+}
+
+func (err ErrInvalidAccessTokenScope) Error() string {
+	return fmt.Sprintf("invalid access token scope: %s", err.Scope)
+}
+
 // ________                            .__                __  .__
 // \_____  \_______  _________    ____ |__|____________ _/  |_|__| ____   ____
 //  /   |   \_  __ \/ ___\__  \  /    \|  \___   /\__  \\   __\  |/  _ \ /    \
@@ -743,6 +759,39 @@ func (err ErrRepoAlreadyExist) Error() string {
 	return fmt.Sprintf("repository already exists [uname: %s, name: %s]", err.Uname, err.Name)
 }
 
+// ErrNoPendingRepoTransfer is an error type for repositories without a pending
+// transfer request
+type ErrNoPendingRepoTransfer struct {
+	RepoID int64
+}
+
+// IsErrNoPendingRepoTransfer checks if an error is an ErrNoPendingRepoTransfer.
+func IsErrNoPendingRepoTransfer(err error) bool {
+	_, ok := err.(ErrNoPendingRepoTransfer)
+	return ok
+}
+
+func (err ErrNoPendingRepoTransfer) Error() string {
+	return fmt.Sprintf("repository doesn't have a pending transfer [repo_id: %d]", err.RepoID)
+}
+
+// ErrRepoTransferInProgress represents the state of a repository that has an
+// ongoing transfer
+type ErrRepoTransferInProgress struct {
+	Uname string
+	Name  string
+}
+
+// IsErrRepoTransferInProgress checks if an error is a ErrRepoTransferInProgress.
+func IsErrRepoTransferInProgress(err error) bool {
+	_, ok := err.(ErrRepoTransferInProgress)
+	return ok
+}
+
+func (err ErrRepoTransferInProgress) Error() string {
+	return fmt.Sprintf("repository is already being transferred [uname: %s, name: %s]", err.Uname, err.Name)
+}
+
 // ErrForkAlreadyExist represents a "ForkAlreadyExist" kind of error.
 type ErrForkAlreadyExist struct {
 	Uname    string
@@ -760,6 +809,22 @@ func (err ErrForkAlreadyExist) Error() string {
 	return fmt.Sprintf("repository is already forked by user [uname: %s, repo path: %s, fork path: %s]", err.Uname, err.RepoName, err.ForkName)
 }
 
+// ErrInvalidGitConfig represents a "InvalidGitConfig" kind of error.
+type ErrInvalidGitConfig struct {
+	Name  string
+	Value string
+}
+
+// IsErrInvalidGitConfig checks if an error is an ErrInvalidGitConfig.
+func IsErrInvalidGitConfig(err error) bool {
+	_, ok := err.(ErrInvalidGitConfig)
+	return ok
+}
+
+func (err ErrInvalidGitConfig) Error() string {
+	return fmt.Sprintf("git config is not allowed or invalid [name: %s, value: %q]", err.Name, err.Value)
+}
+
 // ErrRepoRedirectNotExist represents a "RepoRedirectNotExist" kind of error.
 type ErrRepoRedirectNotExist struct {
 	OwnerID  int64
@@ -840,6 +905,82 @@ func (err ErrReleaseNotExist) Error() string {
 	return fmt.Sprintf("release tag does not exist [id: %d, tag_name: %s]", err.ID, err.TagName)
 }
 
+// ErrReleaseProtected represents a "ReleaseProtected" kind of error.
+type ErrReleaseProtected struct {
+	TagName string
+}
+
+// IsErrReleaseProtected checks if an error is a ErrReleaseProtected.
+func IsErrReleaseProtected(err error) bool {
+	_, ok := err.(ErrReleaseProtected)
+	return ok
+}
+
+func (err ErrReleaseProtected) Error() string {
+	return fmt.Sprintf("release is protected [tag_name: %s]", err.TagName)
+}
+
+// ErrReleaseYankReasonRequired represents a "ReleaseYankReasonRequired" kind of error.
+type ErrReleaseYankReasonRequired struct {
+	TagName string
+}
+
+// IsErrReleaseYankReasonRequired checks if an error is a ErrReleaseYankReasonRequired.
+func IsErrReleaseYankReasonRequired(err error) bool {
+	_, ok := err.(ErrReleaseYankReasonRequired)
+	return ok
+}
+
+func (err ErrReleaseYankReasonRequired) Error() string {
+	return fmt.Sprintf("a reason is required to yank a release [tag_name: %s]", err.TagName)
+}
+
+// ErrReleaseNotPublished represents a "ReleaseNotPublished" kind of error.
+type ErrReleaseNotPublished struct {
+	TagName string
+}
+
+// IsErrReleaseNotPublished checks if an error is a ErrReleaseNotPublished.
+func IsErrReleaseNotPublished(err error) bool {
+	_, ok := err.(ErrReleaseNotPublished)
+	return ok
+}
+
+func (err ErrReleaseNotPublished) Error() string {
+	return fmt.Sprintf("release is not published [tag_name: %s]", err.TagName)
+}
+
+// ErrReleaseAssetQuotaExceeded represents a "ReleaseAssetQuotaExceeded" kind of error.
+type ErrReleaseAssetQuotaExceeded struct {
+	Quota string
+	Limit int64
+}
+
+// IsErrReleaseAssetQuotaExceeded checks if an error is a ErrReleaseAssetQuotaExceeded.
+func IsErrReleaseAssetQuotaExceeded(err error) bool {
+	_, ok := err.(ErrReleaseAssetQuotaExceeded)
+	return ok
+}
+
+func (err ErrReleaseAssetQuotaExceeded) Error() string {
+	return fmt.Sprintf("release asset quota exceeded [quota: %s, limit: %d]", err.Quota, err.Limit)
+}
+
+// ErrReleaseCommentNotExist represents a "ReleaseCommentNotExist" kind of error.
+type ErrReleaseCommentNotExist struct {
+	ID int64
+}
+
+// IsErrReleaseCommentNotExist checks if an error is a ErrReleaseCommentNotExist.
+func IsErrReleaseCommentNotExist(err error) bool {
+	_, ok := err.(ErrReleaseCommentNotExist)
+	return ok
+}
+
+func (err ErrReleaseCommentNotExist) Error() string {
+	return fmt.Sprintf("release comment does not exist [id: %d]", err.ID)
+}
+
 // ErrInvalidTagName represents a "InvalidTagName" kind of error.
 type ErrInvalidTagName struct {
 	TagName string
@@ -1071,6 +1212,98 @@ func (err ErrNotAllowedToMerge) Error() string {
 	return fmt.Sprintf("not allowed to merge [reason: %s]", err.Reason)
 }
 
+// ErrPullAutoMergeAlreadyScheduled represents an error that a pull request is already scheduled to be merged once it is ready.
+type ErrPullAutoMergeAlreadyScheduled struct {
+	PullID int64
+}
+
+// IsErrPullAutoMergeAlreadyScheduled checks if an error is an ErrPullAutoMergeAlreadyScheduled.
+func IsErrPullAutoMergeAlreadyScheduled(err error) bool {
+	_, ok := err.(ErrPullAutoMergeAlreadyScheduled)
+	return ok
+}
+
+func (err ErrPullAutoMergeAlreadyScheduled) Error() string {
+	return fmt.Sprintf("pull request is already scheduled to be merged [pull_id: %d]", err.PullID)
+}
+
+// ErrPullAutoMergeNotExist represents an error that a pull request isn't scheduled to be merged.
+type ErrPullAutoMergeNotExist struct {
+	PullID int64
+}
+
+// IsErrPullAutoMergeNotExist checks if an error is an ErrPullAutoMergeNotExist.
+func IsErrPullAutoMergeNotExist(err error) bool {
+	_, ok := err.(ErrPullAutoMergeNotExist)
+	return ok
+}
+
+func (err ErrPullAutoMergeNotExist) Error() string {
+	return fmt.Sprintf("pull request isn't scheduled to be merged [pull_id: %d]", err.PullID)
+}
+
+// ErrPullAlreadyInMergeQueue represents an error that a pull request is already in a merge queue.
+type ErrPullAlreadyInMergeQueue struct {
+	PullID int64
+}
+
+// IsErrPullAlreadyInMergeQueue checks if an error is an ErrPullAlreadyInMergeQueue.
+func IsErrPullAlreadyInMergeQueue(err error) bool {
+	_, ok := err.(ErrPullAlreadyInMergeQueue)
+	return ok
+}
+
+func (err ErrPullAlreadyInMergeQueue) Error() string {
+	return fmt.Sprintf("pull request is already in the merge queue [pull_id: %d]", err.PullID)
+}
+
+// ErrPullNotInMergeQueue represents an error that a pull request isn't in a merge queue.
+type ErrPullNotInMergeQueue struct {
+	PullID int64
+}
+
+// IsErrPullNotInMergeQueue checks if an error is an ErrPullNotInMergeQueue.
+func IsErrPullNotInMergeQueue(err error) bool {
+	_, ok := err.(ErrPullNotInMergeQueue)
+	return ok
+}
+
+func (err ErrPullNotInMergeQueue) Error() string {
+	return fmt.Sprintf("pull request isn't in the merge queue [pull_id: %d]", err.PullID)
+}
+
+// ErrMergeQueueHeadChanged represents an error that the head branch of a pull request has changed
+// since it has been added to a merge queue.
+type ErrMergeQueueHeadChanged struct {
+	PullID       int64
+	HeadCommitID string
+}
+
+// IsErrMergeQueueHeadChanged checks if an error is an ErrMergeQueueHeadChanged.
+func IsErrMergeQueueHeadChanged(err error) bool {
+	_, ok := err.(ErrMergeQueueHeadChanged)
+	return ok
+}
+
+func (err ErrMergeQueueHeadChanged) Error() string {
+	return fmt.Sprintf("head branch of the pull request has changed since it has been added to the merge queue [pull_id: %d, head_commit_id: %s]", err.PullID, err.HeadCommitID)
+}
+
+// ErrPullRequestNotDraft represents an error that a pull request isn't a draft.
+type ErrPullRequestNotDraft struct {
+	ID int64
+}
+
+// IsErrPullRequestNotDraft checks if an error is an ErrPullRequestNotDraft.
+func IsErrPullRequestNotDraft(err error) bool {
+	_, ok := err.(ErrPullRequestNotDraft)
+	return ok
+}
+
+func (err ErrPullRequestNotDraft) Error() string {
+	return fmt.Sprintf("pull request isn't a draft [id: %d]", err.ID)
+}
+
 // ErrTagAlreadyExists represents an error that tag with such name already exists.
 type ErrTagAlreadyExists struct {
 	TagName string
@@ -1169,6 +1402,37 @@ func (err ErrWebhookNotExist) Error() string {
 	return fmt.Sprintf("webhook does not exist [id: %d]", err.ID)
 }
 
+// ErrHookTaskNotExist represents a "HookTaskNotExist" kind of error.
+type ErrHookTaskNotExist struct {
+	HookID int64
+	ID     int64
+}
+
+// IsErrHookTaskNotExist checks if an error is a ErrHookTaskNotExist.
+func IsErrHookTaskNotExist(err error) bool {
+	_, ok := err.(ErrHookTaskNotExist)
+	return ok
+}
+
+func (err ErrHookTaskNotExist) Error() string {
+	return fmt.Sprintf("hook task does not exist [hook_id: %d, id: %d]", err.HookID, err.ID)
+}
+
+// ErrInvalidClientCertificate represents a "InvalidClientCertificate" kind of error.
+type ErrInvalidClientCertificate struct {
+	Err error
+}
+
+// IsErrInvalidClientCertificate checks if an error is a ErrInvalidClientCertificate.
+func IsErrInvalidClientCertificate(err error) bool {
+	_, ok := err.(ErrInvalidClientCertificate)
+	return ok
+}
+
+func (err ErrInvalidClientCertificate) Error() string {
+	return fmt.Sprintf("invalid client certificate: %v", err.Err)
+}
+
 // .___
 // |   | ______ ________ __   ____
 // |   |/  ___//  ___/  |  \_/ __ \
@@ -1424,6 +1688,23 @@ func (err ErrMergeUnrelatedHistories) Error() string {
 	return fmt.Sprintf("Merge UnrelatedHistories Error: %v: %s\n%s", err.Err, err.StdErr, err.StdOut)
 }
 
+// ErrMergeDivergingFastForwardOnly represents an error if a fast-forward-only merge fails because the branches diverge
+type ErrMergeDivergingFastForwardOnly struct {
+	StdOut string
+	StdErr string
+	Err    error
+}
+
+// IsErrMergeDivergingFastForwardOnly checks if an error is a ErrMergeDivergingFastForwardOnly.
+func IsErrMergeDivergingFastForwardOnly(err error) bool {
+	_, ok := err.(ErrMergeDivergingFastForwardOnly)
+	return ok
+}
+
+func (err ErrMergeDivergingFastForwardOnly) Error() string {
+	return fmt.Sprintf("Merge DivergingFastForwardOnly Error: %v: %s\n%s", err.Err, err.StdErr, err.StdOut)
+}
+
 // ErrRebaseConflicts represents an error if rebase fails with a conflict
 type ErrRebaseConflicts struct {
 	Style     MergeStyle
@@ -1443,6 +1724,27 @@ func (err ErrRebaseConflicts) Error() string {
 	return fmt.Sprintf("Rebase Error: %v: Whilst Rebasing: %s\n%s\n%s", err.Err, err.CommitSHA, err.StdErr, err.StdOut)
 }
 
+// ErrCherryPickConflicts represents an error if cherry-picking or reverting a commit fails with a conflict
+type ErrCherryPickConflicts struct {
+	CommitSHA string
+	Revert    bool
+	Files     []string
+}
+
+// IsErrCherryPickConflicts checks if an error is a ErrCherryPickConflicts.
+func IsErrCherryPickConflicts(err error) bool {
+	_, ok := err.(ErrCherryPickConflicts)
+	return ok
+}
+
+func (err ErrCherryPickConflicts) Error() string {
+	action := "cherry-picking"
+	if err.Revert {
+		action = "reverting"
+	}
+	return fmt.Sprintf("conflicts %s commit %s [files: %s]", action, err.CommitSHA, strings.Join(err.Files, ", "))
+}
+
 // ErrPullRequestHasMerged represents a "PullRequestHasMerged"-error
 type ErrPullRequestHasMerged struct {
 	ID         int64
@@ -1636,6 +1938,38 @@ func (err ErrAttachmentNotExist) Error() string {
 	return fmt.Sprintf("attachment does not exist [id: %d, uuid: %s]", err.ID, err.UUID)
 }
 
+// ErrAttachmentUploadNotExist represents a "AttachmentUploadNotExist" kind of error.
+type ErrAttachmentUploadNotExist struct {
+	UUID string
+}
+
+// IsErrAttachmentUploadNotExist checks if an error is a ErrAttachmentUploadNotExist.
+func IsErrAttachmentUploadNotExist(err error) bool {
+	_, ok := err.(ErrAttachmentUploadNotExist)
+	return ok
+}
+
+func (err ErrAttachmentUploadNotExist) Error() string {
+	return fmt.Sprintf("attachment upload does not exist [uuid: %s]", err.UUID)
+}
+
+// ErrAttachmentUploadOffsetMismatch represents a "AttachmentUploadOffsetMismatch" kind of error.
+type ErrAttachmentUploadOffsetMismatch struct {
+	UUID     string
+	Expected int64
+	Given    int64
+}
+
+// IsErrAttachmentUploadOffsetMismatch checks if an error is a ErrAttachmentUploadOffsetMismatch.
+func IsErrAttachmentUploadOffsetMismatch(err error) bool {
+	_, ok := err.(ErrAttachmentUploadOffsetMismatch)
+	return ok
+}
+
+func (err ErrAttachmentUploadOffsetMismatch) Error() string {
+	return fmt.Sprintf("attachment upload offset mismatch [uuid: %s, expected: %d, given: %d]", err.UUID, err.Expected, err.Given)
+}
+
 // .____                 .__           _________
 // |    |    ____   ____ |__| ____    /   _____/ ____  __ _________   ____  ____
 // |    |   /  _ \ / ___\|  |/    \   \_____  \ /  _ \|  |  \_  __ \_/ ___\/ __ \
@@ -375,6 +375,7 @@ type CommitVerification struct {
 	SigningEmail   string
 	SigningKey     *GPGKey
 	TrustStatus    string
+	TrustModel     string
 }
 
 // SignCommit represents a commit with validation of signature.
@@ -797,10 +798,29 @@ func ParseCommitsWithSignature(oldCommits *list.List, repository *Repository) *l
 	return newCommits
 }
 
-// CalculateTrustStatus will calculate the TrustStatus for a commit verification within a repository
+// CalculateTrustStatus will calculate the TrustStatus for a commit verification within a repository,
+// according to the trust model of the repository
 func CalculateTrustStatus(verification *CommitVerification, repository *Repository, memberMap *map[int64]bool) (err error) {
-	if verification.Verified {
-		verification.TrustStatus = "trusted"
+	trustModel := repository.GetTrustModel()
+	verification.TrustModel = trustModel.String()
+	if !verification.Verified {
+		return
+	}
+
+	verification.TrustStatus = "trusted"
+	switch trustModel {
+	case AnyKeyTrustModel:
+		// Any key known to the instance is trusted
+	case TrustedKeysTrustModel:
+		if !repository.IsTrustedKey(verification.SigningKey) {
+			verification.TrustStatus = "untrusted"
+		}
+	case CommitterTrustModel:
+		// The keys of the instance are trusted, the keys of users only for their own commits
+		if verification.SigningUser.ID != 0 && verification.CommittingUser.ID != verification.SigningUser.ID {
+			verification.TrustStatus = "unmatched"
+		}
+	default:
 		if verification.SigningUser.ID != 0 {
 			var isMember bool
 			if memberMap != nil {
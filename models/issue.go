@@ -626,6 +626,14 @@ func (issue *Issue) doChangeStatus(e *xorm.Session, doer *User, isMergePull bool
 		return nil, err
 	}
 
+	// A closed pull request isn't merged once it is ready anymore, even if it is reopened
+	if issue.IsClosed && issue.IsPull {
+		if _, err := e.In("pull_id", builder.Select("id").From("pull_request").Where(builder.Eq{"issue_id": issue.ID})).
+			Delete(&PullAutoMerge{}); err != nil {
+			return nil, err
+		}
+	}
+
 	// New action comment
 	cmtType := CommentTypeClose
 	if !issue.IsClosed {
@@ -829,6 +837,7 @@ type NewIssueOptions struct {
 	Repo        *Repository
 	Issue       *Issue
 	LabelIDs    []int64
+	AssigneeIDs []int64
 	Attachments []string // In UUID format.
 	IsPull      bool
 }
@@ -920,6 +929,12 @@ func newIssue(e *xorm.Session, doer *User, opts NewIssueOptions) (err error) {
 		return err
 	}
 
+	for _, assigneeID := range opts.AssigneeIDs {
+		if _, _, err = opts.Issue.toggleAssignee(e, doer, assigneeID, true); err != nil {
+			return err
+		}
+	}
+
 	if len(opts.Attachments) > 0 {
 		attachments, err := getAttachmentsByUUIDs(e, opts.Attachments)
 		if err != nil {
@@ -984,6 +999,54 @@ func newIssueAttempt(repo *Repository, issue *Issue, labelIDs []int64, uuids []s
 	return nil
 }
 
+// NewIssues creates the issues with their labels and assignees in a single transaction,
+// none of them is created if one fails.
+func NewIssues(repo *Repository, opts []NewIssueOptions) (err error) {
+	i := 0
+	for {
+		if err = newIssuesAttempt(repo, opts); err == nil {
+			return nil
+		}
+		if !IsErrNewIssueInsert(err) {
+			return err
+		}
+		if i++; i == issueMaxDupIndexAttempts {
+			break
+		}
+		log.Error("NewIssues: error attempting to insert the new issues; will retry. Original error: %v", err)
+
+		// The issues inserted before the error were rolled back
+		for _, opt := range opts {
+			opt.Issue.ID = 0
+		}
+	}
+	return fmt.Errorf("NewIssues: too many errors attempting to insert the new issues. Last error was: %v", err)
+}
+
+func newIssuesAttempt(repo *Repository, opts []NewIssueOptions) (err error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	for _, opt := range opts {
+		opt.Repo = repo
+		if err = newIssue(sess, opt.Issue.Poster, opt); err != nil {
+			if IsErrUserDoesNotHaveAccessToRepo(err) || IsErrNewIssueInsert(err) {
+				return err
+			}
+			return fmt.Errorf("newIssue: %v", err)
+		}
+	}
+
+	if err = sess.Commit(); err != nil {
+		return fmt.Errorf("Commit: %v", err)
+	}
+
+	return nil
+}
+
 // GetIssueByIndex returns raw issue without loading attributes by index in a repository.
 func GetIssueByIndex(repoID, index int64) (*Issue, error) {
 	issue := &Issue{
@@ -1959,7 +2022,7 @@ func deleteIssuesByRepoID(sess Engine, repoID int64) (attachmentPaths []string,
 		return
 	}
 	for j := range attachments {
-		attachmentPaths = append(attachmentPaths, attachments[j].LocalPath())
+		attachmentPaths = append(attachmentPaths, attachments[j].RelativePath())
 	}
 
 	if _, err = sess.In("issue_id", deleteCond).
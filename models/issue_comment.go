@@ -94,6 +94,16 @@ const (
 	CommentTypeMergePull
 	// push to PR head branch
 	CommentTypePullPush
+	// schedule the merge of a pull request once it is ready
+	CommentTypePullScheduledMerge
+	// cancel the scheduled merge of a pull request
+	CommentTypePullCancelledScheduledMerge
+	// add a pull request to the merge queue of its base branch
+	CommentTypePullAddedToMergeQueue
+	// remove a pull request from the merge queue of its base branch
+	CommentTypePullRemovedFromMergeQueue
+	// mark a draft pull request as ready for review
+	CommentTypePullReadyForReview
 )
 
 // CommentTag defines comment tag type
@@ -148,7 +158,7 @@ type Comment struct {
 	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
 
 	// Reference issue in commit message
-	CommitSHA string `xorm:"VARCHAR(40)"`
+	CommitSHA string `xorm:"VARCHAR(64)"`
 
 	Attachments []*Attachment `xorm:"-"`
 	Reactions   ReactionList  `xorm:"-"`
@@ -1056,6 +1066,87 @@ func FetchCodeComments(issue *Issue, currentUser *User) (CodeComments, error) {
 	return fetchCodeComments(x, issue, currentUser)
 }
 
+// CodeConversation is a thread of code comments on the same line of a file, ordered by creation.
+// It is resolved when its first comment is.
+type CodeConversation []*Comment
+
+// IsResolved returns whether the conversation is resolved
+func (conv CodeConversation) IsResolved() bool {
+	return len(conv) > 0 && conv[0].IsResolved()
+}
+
+// FetchCodeConversations returns the code conversations of a pull request ordered by their first
+// comment, including the outdated ones. The comments of pending reviews are only returned to their
+// reviewer.
+func FetchCodeConversations(issue *Issue, currentUser *User) ([]CodeConversation, error) {
+	opts := FindCommentsOptions{
+		Type:    CommentTypeCode,
+		IssueID: issue.ID,
+	}
+	var comments []*Comment
+	if err := x.Where(opts.toConds()).
+		Asc("comment.created_unix").
+		Asc("comment.id").
+		Find(&comments); err != nil {
+		return nil, err
+	}
+
+	if err := CommentList(comments).loadPosters(x); err != nil {
+		return nil, err
+	}
+
+	reviews := make(map[int64]*Review)
+	var ids = make([]int64, 0, len(comments))
+	for _, comment := range comments {
+		if comment.ReviewID != 0 {
+			ids = append(ids, comment.ReviewID)
+		}
+	}
+	if err := x.In("id", ids).Find(&reviews); err != nil {
+		return nil, err
+	}
+
+	conversations := make([]CodeConversation, 0, 10)
+	indexes := make(map[string]map[int64]int)
+	for _, comment := range comments {
+		if re, ok := reviews[comment.ReviewID]; ok && re != nil {
+			if re.Type == ReviewTypePending && (currentUser == nil || currentUser.ID != re.ReviewerID) {
+				continue
+			}
+			comment.Review = re
+		}
+		if err := comment.LoadResolveDoer(); err != nil {
+			return nil, err
+		}
+		comment.Issue = issue
+
+		if indexes[comment.TreePath] == nil {
+			indexes[comment.TreePath] = make(map[int64]int)
+		}
+		if i, ok := indexes[comment.TreePath][comment.Line]; ok {
+			conversations[i] = append(conversations[i], comment)
+		} else {
+			indexes[comment.TreePath][comment.Line] = len(conversations)
+			conversations = append(conversations, CodeConversation{comment})
+		}
+	}
+	return conversations, nil
+}
+
+// GetCodeConversation returns the code conversation of a pull request starting with the given comment
+func GetCodeConversation(issue *Issue, currentUser *User, commentID int64) (CodeConversation, error) {
+	conversations, err := FetchCodeConversations(issue, currentUser)
+	if err != nil {
+		return nil, err
+	}
+	for _, conv := range conversations {
+		if conv[0].ID == commentID {
+			return conv, nil
+		}
+	}
+	return nil, ErrCommentNotExist{commentID, issue.ID}
+}
+
 // UpdateCommentsMigrationsByType updates comments' migrations information via given git service type and original id and poster id
 func UpdateCommentsMigrationsByType(tp structs.GitServiceType, originalAuthorID string, posterID int64) error {
 	_, err := x.Table("comment").
@@ -57,3 +57,38 @@ func TestFetchCodeComments(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, res, 1)
 }
+
+func TestFetchCodeConversations(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 2}).(*Issue)
+	user := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	convs, err := FetchCodeConversations(issue, user)
+	assert.NoError(t, err)
+	if assert.Len(t, convs, 2) {
+		assert.Len(t, convs[0], 1)
+		assert.EqualValues(t, 4, convs[0][0].ID)
+		// the outdated comments are included
+		assert.Len(t, convs[1], 2)
+		assert.EqualValues(t, 5, convs[1][0].ID)
+		assert.EqualValues(t, 6, convs[1][1].ID)
+	}
+
+	// the comments of the pending review are only returned to its reviewer
+	user2 := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	convs, err = FetchCodeConversations(issue, user2)
+	assert.NoError(t, err)
+	if assert.Len(t, convs, 1) {
+		assert.EqualValues(t, 5, convs[0][0].ID)
+		assert.False(t, convs[0].IsResolved())
+	}
+
+	assert.NoError(t, MarkConversation(convs[0][0], user2, true))
+	conv, err := GetCodeConversation(issue, user2, 5)
+	assert.NoError(t, err)
+	assert.True(t, conv.IsResolved())
+	assert.EqualValues(t, 2, conv[0].ResolveDoer.ID)
+
+	_, err = GetCodeConversation(issue, user2, 6)
+	assert.True(t, IsErrCommentNotExist(err))
+}
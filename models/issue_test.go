@@ -371,6 +371,29 @@ func TestIssue_InsertIssue(t *testing.T) {
 	testInsertIssue(t, `my issue2, this is my son's love \n \r \ `, "special issue's '' comments?")
 }
 
+func TestNewIssues(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	newIssue := func(title string) *Issue {
+		return &Issue{RepoID: repo.ID, Repo: repo, PosterID: user.ID, Poster: user, Title: title}
+	}
+	opts := []NewIssueOptions{
+		{Issue: newIssue("first issue"), LabelIDs: []int64{1}},
+		{Issue: newIssue("second issue"), AssigneeIDs: []int64{2}},
+	}
+	assert.NoError(t, NewIssues(repo, opts))
+
+	issue := AssertExistsAndLoadBean(t, &Issue{RepoID: repo.ID, Title: "first issue", Index: 6}).(*Issue)
+	AssertExistsAndLoadBean(t, &IssueLabel{IssueID: issue.ID, LabelID: 1})
+	issue = AssertExistsAndLoadBean(t, &Issue{RepoID: repo.ID, Title: "second issue", Index: 7}).(*Issue)
+	AssertExistsAndLoadBean(t, &IssueAssignees{IssueID: issue.ID, AssigneeID: 2})
+	AssertExistsAndLoadBean(t, &Comment{IssueID: issue.ID, Type: CommentTypeAssignees, AssigneeID: 2})
+	CheckConsistencyFor(t, &Repository{ID: repo.ID})
+}
+
 func TestIssue_ResolveMentions(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 
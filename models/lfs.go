@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"path"
 
 	"code.gitea.io/gitea/modules/timeutil"
 
@@ -31,6 +32,15 @@ func (m *LFSMetaObject) Pointer() string {
 	return fmt.Sprintf("%s\n%s%s\nsize %d\n", LFSMetaFileIdentifier, LFSMetaFileOidPrefix, m.Oid, m.Size)
 }
 
+// RelativePath returns the relative path of the lfs object in the lfs storage
+func (m *LFSMetaObject) RelativePath() string {
+	if len(m.Oid) < 5 {
+		return m.Oid
+	}
+
+	return path.Join(m.Oid[0:2], m.Oid[2:4], m.Oid[4:])
+}
+
 // LFSTokenResponse defines the JSON structure in which the JWT token is stored.
 // This structure is fetched via SSH and passed by the Git LFS client to the server
 // endpoint for authorization.
@@ -152,6 +162,12 @@ func (repo *Repository) GetLFSMetaObjects(page, pageSize int) ([]*LFSMetaObject,
 	return lfsObjects, sess.Find(&lfsObjects, &LFSMetaObject{RepositoryID: repo.ID})
 }
 
+// GetLFSMetaObjectsCreatedBefore returns the LFSMetaObjects associated with a repository created at or before the time
+func (repo *Repository) GetLFSMetaObjectsCreatedBefore(before timeutil.TimeStamp) ([]*LFSMetaObject, error) {
+	lfsObjects := make([]*LFSMetaObject, 0, 10)
+	return lfsObjects, x.Where("repository_id = ? AND created_unix <= ?", repo.ID, before).Find(&lfsObjects)
+}
+
 // CountLFSMetaObjects returns a count of all LFSMetaObjects associated with a repository
 func (repo *Repository) CountLFSMetaObjects() (int64, error) {
 	return x.Count(&LFSMetaObject{RepositoryID: repo.ID})
@@ -45,3 +45,21 @@ func (opts ListOptions) setDefaultValues() {
 		opts.Page = 1
 	}
 }
+
+// GetStartEnd returns the bounds of the page in a list of total items, it is used to
+// paginate the lists which aren't read from the database. All the items are in the page
+// if no page is given.
+func (opts ListOptions) GetStartEnd(total int) (start, end int) {
+	if opts.Page <= 0 || opts.PageSize <= 0 {
+		return 0, total
+	}
+	start = (opts.Page - 1) * opts.PageSize
+	if start > total {
+		start = total
+	}
+	end = start + opts.PageSize
+	if end > total {
+		end = total
+	}
+	return start, end
+}
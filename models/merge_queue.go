@@ -0,0 +1,172 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// The reasons pull requests are removed from merge queues by the queues themselves,
+// which are the contents of the comments of their removals
+const (
+	MergeQueueRemovalChecksFailed = "checks_failed"
+	MergeQueueRemovalConflicts    = "conflicts"
+	MergeQueueRemovalNotAllowed   = "not_allowed"
+	MergeQueueRemovalDisabled     = "disabled"
+	MergeQueueRemovalRetargeted   = "retargeted"
+	MergeQueueRemovalHeadChanged  = "head_changed"
+)
+
+// MergeQueueEntry is a pull request waiting in the merge queue of its base branch to be merged on behalf of the doer.
+// The pull requests of a queue are merged one after the other in the order they have been added to it: the merge
+// of the first one is pushed to a test branch of the base repository and merged into the base branch once its
+// required status checks succeed.
+type MergeQueueEntry struct {
+	ID         int64        `xorm:"pk autoincr"`
+	PullID     int64        `xorm:"UNIQUE NOT NULL"`
+	Pull       *PullRequest `xorm:"-"`
+	BaseRepoID int64        `xorm:"INDEX(s) NOT NULL"`
+	BaseBranch string       `xorm:"INDEX(s) NOT NULL"`
+	DoerID     int64        `xorm:"NOT NULL"`
+	Doer       *User        `xorm:"-"`
+	MergeStyle MergeStyle   `xorm:"varchar(30)"`
+	Message    string       `xorm:"TEXT"`
+	// HeadCommitID is the commit of the head branch when the pull request has been added to the queue,
+	// it leaves the queue if its head branch changes so only the commits the doer has seen are merged
+	HeadCommitID string `xorm:"VARCHAR(64)"`
+	// TestBranch is the branch of the base repository the merge is tested on, empty until it is tested
+	TestBranch string
+	// TestBaseCommitID is the commit of the base branch the tested merge has been made on
+	TestBaseCommitID string `xorm:"VARCHAR(64)"`
+	// TestCommitID is the tested merge, which is pushed into the base branch once its checks succeed
+	TestCommitID string `xorm:"VARCHAR(64) INDEX"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// LoadDoer loads the user who added the pull request to the merge queue
+func (e *MergeQueueEntry) LoadDoer() (err error) {
+	if e.Doer == nil {
+		e.Doer, err = GetUserByID(e.DoerID)
+	}
+	return err
+}
+
+// LoadPullRequest loads the pull request of the entry
+func (e *MergeQueueEntry) LoadPullRequest() (err error) {
+	if e.Pull == nil {
+		e.Pull, err = GetPullRequestByID(e.PullID)
+	}
+	return err
+}
+
+// IsTesting returns whether the merge of the pull request is being tested
+func (e *MergeQueueEntry) IsTesting() bool {
+	return len(e.TestCommitID) > 0
+}
+
+// Position returns the position of the pull request in the merge queue, starting at 1
+func (e *MergeQueueEntry) Position() (int64, error) {
+	return x.Where("base_repo_id = ? AND base_branch = ? AND id <= ?", e.BaseRepoID, e.BaseBranch, e.ID).
+		Count(new(MergeQueueEntry))
+}
+
+// AddToMergeQueue adds the pull request with the commit of its head branch to the end of the merge queue
+// of its base branch to be merged with the style and the message and adds the corresponding comment to its timeline
+func AddToMergeQueue(doer *User, pr *PullRequest, headCommitID string, style MergeStyle, message string) (*MergeQueueEntry, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	if exist, err := sess.Exist(&MergeQueueEntry{PullID: pr.ID}); err != nil {
+		return nil, err
+	} else if exist {
+		return nil, ErrPullAlreadyInMergeQueue{PullID: pr.ID}
+	}
+
+	entry := &MergeQueueEntry{
+		PullID:       pr.ID,
+		Pull:         pr,
+		BaseRepoID:   pr.BaseRepoID,
+		BaseBranch:   pr.BaseBranch,
+		DoerID:       doer.ID,
+		Doer:         doer,
+		MergeStyle:   style,
+		Message:      message,
+		HeadCommitID: headCommitID,
+	}
+	if _, err := sess.Insert(entry); err != nil {
+		return nil, err
+	}
+
+	if err := addPullMergeComment(sess, doer, pr, CommentTypePullAddedToMergeQueue, ""); err != nil {
+		return nil, err
+	}
+	return entry, sess.Commit()
+}
+
+// GetMergeQueueEntryByPullID returns the entry of the pull request in the merge queue of its base branch,
+// nil if it isn't in it
+func GetMergeQueueEntryByPullID(pullID int64) (*MergeQueueEntry, error) {
+	entry := new(MergeQueueEntry)
+	if has, err := x.Where("pull_id = ?", pullID).Get(entry); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return entry, nil
+}
+
+// GetMergeQueue returns the entries of the merge queue of the branch of the repository in order
+func GetMergeQueue(baseRepoID int64, baseBranch string) ([]*MergeQueueEntry, error) {
+	entries := make([]*MergeQueueEntry, 0, 5)
+	return entries, x.Where("base_repo_id = ? AND base_branch = ?", baseRepoID, baseBranch).
+		Asc("id").
+		Find(&entries)
+}
+
+// GetMergeQueueEntriesByTestCommitID returns the entries of the merge queues of the repository
+// whose merge is tested with the commit
+func GetMergeQueueEntriesByTestCommitID(baseRepoID int64, commitID string) ([]*MergeQueueEntry, error) {
+	entries := make([]*MergeQueueEntry, 0, 1)
+	return entries, x.Where("base_repo_id = ? AND test_commit_id = ?", baseRepoID, commitID).
+		Find(&entries)
+}
+
+// UpdateMergeQueueEntryTest updates the test of the merge of the pull request of the entry
+func UpdateMergeQueueEntryTest(e *MergeQueueEntry) error {
+	_, err := x.ID(e.ID).Cols("test_branch", "test_base_commit_id", "test_commit_id").Update(e)
+	return err
+}
+
+// DeleteMergeQueueEntry deletes the entry of a pull request which has been merged or closed from its merge queue
+func DeleteMergeQueueEntry(e *MergeQueueEntry) error {
+	_, err := x.ID(e.ID).Delete(new(MergeQueueEntry))
+	return err
+}
+
+// RemoveFromMergeQueue removes the pull request from the merge queue of its base branch and adds
+// the corresponding comment with the reason of the removal, empty if the doer removed it, to its timeline
+func RemoveFromMergeQueue(doer *User, pr *PullRequest, reason string) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if cnt, err := sess.Delete(&MergeQueueEntry{PullID: pr.ID}); err != nil {
+		return err
+	} else if cnt == 0 {
+		return ErrPullNotInMergeQueue{PullID: pr.ID}
+	}
+
+	if err := addPullMergeComment(sess, doer, pr, CommentTypePullRemovedFromMergeQueue, reason); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
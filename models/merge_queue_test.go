@@ -0,0 +1,106 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddToMergeQueue(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	pr1 := AssertExistsAndLoadBean(t, &PullRequest{ID: 1}).(*PullRequest)
+	pr2 := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+	pr5 := AssertExistsAndLoadBean(t, &PullRequest{ID: 5}).(*PullRequest)
+
+	entry, err := GetMergeQueueEntryByPullID(pr2.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, entry)
+
+	_, err = AddToMergeQueue(doer, pr2, "985f0301dba5e7b34be866819cd15ad3d8f508ee", MergeStyleSquash, "squashed")
+	assert.NoError(t, err)
+	AssertExistsAndLoadBean(t, &Comment{IssueID: pr2.IssueID, PosterID: doer.ID, Type: CommentTypePullAddedToMergeQueue})
+	_, err = AddToMergeQueue(doer, pr1, "985f0301dba5e7b34be866819cd15ad3d8f508ee", MergeStyleMerge, "")
+	assert.NoError(t, err)
+	// The queues of the branches are separate
+	_, err = AddToMergeQueue(doer, pr5, "985f0301dba5e7b34be866819cd15ad3d8f508ee", MergeStyleMerge, "")
+	assert.NoError(t, err)
+
+	_, err = AddToMergeQueue(doer, pr2, "985f0301dba5e7b34be866819cd15ad3d8f508ee", MergeStyleMerge, "")
+	assert.True(t, IsErrPullAlreadyInMergeQueue(err))
+
+	entries, err := GetMergeQueue(1, "master")
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, pr2.ID, entries[0].PullID)
+		assert.Equal(t, MergeStyleSquash, entries[0].MergeStyle)
+		assert.Equal(t, "squashed", entries[0].Message)
+		assert.Equal(t, "985f0301dba5e7b34be866819cd15ad3d8f508ee", entries[0].HeadCommitID)
+		assert.False(t, entries[0].IsTesting())
+		assert.Equal(t, pr1.ID, entries[1].PullID)
+	}
+
+	entry, err = GetMergeQueueEntryByPullID(pr1.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, entry) {
+		position, err := entry.Position()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, position)
+		assert.NoError(t, entry.LoadDoer())
+		assert.Equal(t, doer.Name, entry.Doer.Name)
+	}
+	entry, err = GetMergeQueueEntryByPullID(pr5.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, entry) {
+		position, err := entry.Position()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, position)
+	}
+}
+
+func TestUpdateMergeQueueEntryTest(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+
+	entry, err := AddToMergeQueue(doer, pr, "985f0301dba5e7b34be866819cd15ad3d8f508ee", MergeStyleMerge, "")
+	assert.NoError(t, err)
+	entry.TestBranch = "merge-queue/pr-2"
+	entry.TestBaseCommitID = "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+	entry.TestCommitID = "985f0301dba5e7b34be866819cd15ad3d8f508ee"
+	assert.NoError(t, UpdateMergeQueueEntryTest(entry))
+
+	entries, err := GetMergeQueueEntriesByTestCommitID(pr.BaseRepoID, entry.TestCommitID)
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, pr.ID, entries[0].PullID)
+		assert.Equal(t, "merge-queue/pr-2", entries[0].TestBranch)
+		assert.True(t, entries[0].IsTesting())
+	}
+
+	assert.NoError(t, DeleteMergeQueueEntry(entry))
+	AssertNotExistsBean(t, &MergeQueueEntry{PullID: pr.ID})
+}
+
+func TestRemoveFromMergeQueue(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+
+	err := RemoveFromMergeQueue(doer, pr, "")
+	assert.True(t, IsErrPullNotInMergeQueue(err))
+
+	_, err = AddToMergeQueue(doer, pr, "985f0301dba5e7b34be866819cd15ad3d8f508ee", MergeStyleMerge, "")
+	assert.NoError(t, err)
+	assert.NoError(t, RemoveFromMergeQueue(doer, pr, MergeQueueRemovalChecksFailed))
+	AssertNotExistsBean(t, &MergeQueueEntry{PullID: pr.ID})
+	AssertExistsAndLoadBean(t, &Comment{
+		IssueID: pr.IssueID,
+		Type:    CommentTypePullRemovedFromMergeQueue,
+		Content: MergeQueueRemovalChecksFailed,
+	})
+}
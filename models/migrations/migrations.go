@@ -216,6 +216,64 @@ var migrations = []Migration{
 	NewMigration("Save detected language file size to database instead of percent", fixLanguageStatsToSaveSize),
 	// v141 -> 142
 	NewMigration("Add KeepActivityPrivate to User table", addKeepActivityPrivateUserColumn),
+	// v142 -> v143
+	NewMigration("Add SHA256 and SHA512 checksums to Attachment table", addChecksumsToAttachment),
+	// v143 -> v144
+	NewMigration("Add ReleaseExternalAsset table", addReleaseExternalAssetTable),
+	// v144 -> v145
+	NewMigration("Add AttachmentUpload table", addAttachmentUploadTable),
+	// v145 -> v146
+	NewMigration("Add yanked state to Release table", addYankedToRelease),
+	// v146 -> v147
+	NewMigration("Add release asset quotas to Repository table", addReleaseQuotasToRepository),
+	// v147 -> v148
+	NewMigration("add release comment table", addReleaseCommentTable),
+	// v148 -> v149
+	NewMigration("add mirror path to attachment", addMirrorPathToAttachment),
+	// v149 -> v150
+	NewMigration("add role to attachment", addRoleToAttachment),
+	// v150 -> v151
+	NewMigration("add retry info to hook task", addRetryInfoToHookTask),
+	// v151 -> v152
+	NewMigration("add dead letter flag to hook task", addIsDeadLetterToHookTask),
+	// v152 -> v153
+	NewMigration("add signature algorithm to webhook and hook_task", addSignatureAlgorithmToWebhook),
+	// v153 -> v154
+	NewMigration("add proxy url to webhook and hook_task", addProxyURLToWebhook),
+	// v154 -> v155
+	NewMigration("add is_payload_compressed to hook_task", addIsPayloadCompressedToHookTask),
+	// v155 -> v156
+	NewMigration("add client certificate to webhook", addClientCertificateToWebhook),
+	// v156 -> v157
+	NewMigration("add webhook_opt_out table", addWebhookOptOutTable),
+	// v157 -> v158
+	NewMigration("add updated_unix column to release table", addUpdatedUnixToRelease),
+	// v158 -> v159
+	NewMigration("add scope column to access_token table", addScopeToAccessToken),
+	// v159 -> v160
+	NewMigration("add repo_transfer table", addRepoTransfer),
+	// v160 -> v161
+	NewMigration("add created_unix to star and watch", addCreatedUnixToStarAndWatch),
+	// v161 -> v162
+	NewMigration("add object_format_name to repository and widen commit id columns", addObjectFormatNameToRepository),
+	// v162 -> v163
+	NewMigration("add trust_model and trusted_keys to repository", addTrustModelToRepository),
+	// v163 -> v164
+	NewMigration("add repo_git_config table", addRepoGitConfig),
+	// v164 -> v165
+	NewMigration("add pull_auto_merge table", addPullAutoMerge),
+	// v165 -> v166
+	NewMigration("add merge queue", addMergeQueue),
+	// v166 -> v167
+	NewMigration("add require_code_owner_reviews to protected_branch", addRequireCodeOwnerReviews),
+	// v167 -> v168
+	NewMigration("add is_draft to pull_request", addIsDraftToPullRequest),
+	// v168 -> v169
+	NewMigration("add head_commit_id to merge_queue_entry", addHeadCommitIDToMergeQueueEntry),
+	// v169 -> v170
+	NewMigration("add head_commit_id to pull_auto_merge", addHeadCommitIDToPullAutoMerge),
+	// v170 -> v171
+	NewMigration("add expires_unix to attachment_upload", addExpiresUnixToAttachmentUpload),
 }
 
 // GetCurrentDBVersion returns the current db version
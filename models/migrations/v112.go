@@ -6,8 +6,10 @@ package migrations
 
 import (
 	"os"
+	"path"
+
+	"code.gitea.io/gitea/modules/setting"
 
-	"code.gitea.io/gitea/models"
 	"xorm.io/builder"
 	"xorm.io/xorm"
 )
@@ -27,7 +29,8 @@ func removeAttachmentMissedRepo(x *xorm.Engine) error {
 		}
 
 		for i := 0; i < len(attachments); i++ {
-			os.RemoveAll(models.AttachmentLocalPath(attachments[i].UUID))
+			uuid := attachments[i].UUID
+			os.RemoveAll(path.Join(setting.AttachmentPath, uuid[0:1], uuid[1:2], uuid))
 		}
 
 		if len(attachments) < 50 {
@@ -0,0 +1,23 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+func addChecksumsToAttachment(x *xorm.Engine) error {
+	type Attachment struct {
+		Sha256 string `xorm:"VARCHAR(64)"`
+		Sha512 string `xorm:"VARCHAR(128)"`
+	}
+
+	if err := x.Sync2(new(Attachment)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
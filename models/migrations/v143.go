@@ -0,0 +1,29 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addReleaseExternalAssetTable(x *xorm.Engine) error {
+	type ReleaseExternalAsset struct {
+		ID          int64              `xorm:"pk autoincr"`
+		ReleaseID   int64              `xorm:"INDEX"`
+		Name        string             `xorm:"NOT NULL"`
+		URL         string             `xorm:"TEXT NOT NULL"`
+		Size        int64              `xorm:"DEFAULT 0"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(ReleaseExternalAsset)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addYankedToRelease(x *xorm.Engine) error {
+	type Release struct {
+		IsYanked     bool   `xorm:"NOT NULL DEFAULT false"`
+		YankedReason string `xorm:"TEXT"`
+		YankedUnix   timeutil.TimeStamp
+	}
+
+	if err := x.Sync2(new(Release)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+func addReleaseQuotasToRepository(x *xorm.Engine) error {
+	type Repository struct {
+		ReleaseMaxAssetSize int64 `xorm:"NOT NULL DEFAULT -1"`
+		ReleaseMaxAssets    int   `xorm:"NOT NULL DEFAULT -1"`
+		ReleaseMaxTotalSize int64 `xorm:"NOT NULL DEFAULT -1"`
+	}
+
+	if err := x.Sync2(new(Repository)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addReleaseCommentTable(x *xorm.Engine) error {
+	type ReleaseComment struct {
+		ID          int64              `xorm:"pk autoincr"`
+		RepoID      int64              `xorm:"INDEX"`
+		ReleaseID   int64              `xorm:"INDEX"`
+		PosterID    int64              `xorm:"INDEX"`
+		Content     string             `xorm:"TEXT"`
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	if err := x.Sync2(new(ReleaseComment)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+func addRetryInfoToHookTask(x *xorm.Engine) error {
+	type HookTask struct {
+		Attempts       int
+		NextAttempt    int64  `xorm:"INDEX NOT NULL DEFAULT 0"`
+		AttemptContent string `xorm:"TEXT"`
+	}
+
+	if err := x.Sync2(new(HookTask)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+func addProxyURLToWebhook(x *xorm.Engine) error {
+	type Webhook struct {
+		ProxyURL string `xorm:"proxy_url TEXT"`
+	}
+
+	type HookTask struct {
+		ProxyURL string `xorm:"proxy_url TEXT"`
+	}
+
+	if err := x.Sync2(new(Webhook), new(HookTask)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
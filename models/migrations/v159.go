@@ -0,0 +1,30 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addRepoTransfer(x *xorm.Engine) error {
+	type RepoTransfer struct {
+		ID          int64 `xorm:"pk autoincr"`
+		DoerID      int64
+		RecipientID int64
+		RepoID      int64 `xorm:"UNIQUE"`
+		TeamIDs     []int64
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL updated"`
+	}
+
+	if err := x.Sync2(new(RepoTransfer)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
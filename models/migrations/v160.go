@@ -0,0 +1,28 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addCreatedUnixToStarAndWatch(x *xorm.Engine) error {
+	type Star struct {
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	}
+
+	type Watch struct {
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	}
+
+	if err := x.Sync2(new(Star), new(Watch)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
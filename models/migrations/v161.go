@@ -0,0 +1,47 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+	"xorm.io/xorm/schemas"
+)
+
+func addObjectFormatNameToRepository(x *xorm.Engine) error {
+	type Repository struct {
+		ObjectFormatName string `xorm:"VARCHAR(6) NOT NULL DEFAULT 'sha1'"`
+	}
+
+	if err := x.Sync2(new(Repository)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+
+	// The columns holding commit IDs must fit the IDs of sha256 repositories
+	for _, col := range []struct{ table, column string }{
+		{"comment", "commit_sha"},
+		{"pull_request", "merge_base"},
+		{"pull_request", "merged_commit_id"},
+		{"release", "sha1"},
+		{"repo_indexer_status", "commit_sha"},
+		{"review", "commit_id"},
+	} {
+		var err error
+		switch x.Dialect().URI().DBType {
+		case schemas.MYSQL:
+			_, err = x.Exec(fmt.Sprintf("ALTER TABLE `%s` MODIFY `%s` VARCHAR(64)", col.table, col.column))
+		case schemas.POSTGRES:
+			_, err = x.Exec(fmt.Sprintf("ALTER TABLE `%s` ALTER COLUMN `%s` TYPE VARCHAR(64)", col.table, col.column))
+		case schemas.MSSQL:
+			_, err = x.Exec(fmt.Sprintf("ALTER TABLE `%s` ALTER COLUMN `%s` VARCHAR(64)", col.table, col.column))
+		}
+		// SQLite doesn't enforce the length of VARCHAR columns
+		if err != nil {
+			return fmt.Errorf("Error changing %s %s column type: %v", col.table, col.column, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addMergeQueue(x *xorm.Engine) error {
+	type ProtectedBranch struct {
+		EnableMergeQueue bool `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	type MergeQueueEntry struct {
+		ID               int64  `xorm:"pk autoincr"`
+		PullID           int64  `xorm:"UNIQUE NOT NULL"`
+		BaseRepoID       int64  `xorm:"INDEX(s) NOT NULL"`
+		BaseBranch       string `xorm:"INDEX(s) NOT NULL"`
+		DoerID           int64  `xorm:"NOT NULL"`
+		MergeStyle       string `xorm:"varchar(30)"`
+		Message          string `xorm:"TEXT"`
+		TestBranch       string
+		TestBaseCommitID string             `xorm:"VARCHAR(64)"`
+		TestCommitID     string             `xorm:"VARCHAR(64) INDEX"`
+		CreatedUnix      timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix      timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	if err := x.Sync2(new(ProtectedBranch)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	if err := x.Sync2(new(MergeQueueEntry)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addExpiresUnixToAttachmentUpload(x *xorm.Engine) error {
+	type AttachmentUpload struct {
+		ExpiresUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+	if err := x.Sync2(new(AttachmentUpload)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+
+	// The existing uploads expire as if they had been continued last when they were updated
+	_, err := x.Exec("UPDATE attachment_upload SET expires_unix = updated_unix + ?", int64(setting.Repository.Release.UploadExpiry.Seconds()))
+	return err
+}
@@ -6,8 +6,8 @@ package migrations
 
 import (
 	"os"
+	"path"
 
-	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/setting"
 
 	"xorm.io/xorm"
@@ -52,7 +52,7 @@ func deleteOrphanedAttachments(x *xorm.Engine) error {
 		}
 
 		for _, attachment := range attachements {
-			if err := os.RemoveAll(models.AttachmentLocalPath(attachment.UUID)); err != nil {
+			if err := os.RemoveAll(path.Join(setting.AttachmentPath, attachment.UUID[0:1], attachment.UUID[1:2], attachment.UUID)); err != nil {
 				return err
 			}
 		}
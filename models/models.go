@@ -89,6 +89,7 @@ func init() {
 		new(LoginSource),
 		new(Webhook),
 		new(HookTask),
+		new(WebhookOptOut),
 		new(Team),
 		new(OrgUser),
 		new(TeamUser),
@@ -125,6 +126,13 @@ func init() {
 		new(Task),
 		new(LanguageStat),
 		new(EmailHash),
+		new(ReleaseExternalAsset),
+		new(AttachmentUpload),
+		new(ReleaseComment),
+		new(RepoTransfer),
+		new(RepoGitConfig),
+		new(PullAutoMerge),
+		new(MergeQueueEntry),
 	)
 
 	gonicNames := []string{"SSL", "UID"}
@@ -44,6 +44,8 @@ type PullRequest struct {
 	ConflictedFiles []string `xorm:"TEXT JSON"`
 	CommitsAhead    int
 	CommitsBehind   int
+	// IsDraft is whether the pull request is a draft, which can't be merged until it is marked as ready for review
+	IsDraft bool `xorm:"NOT NULL DEFAULT false"`
 
 	IssueID int64  `xorm:"INDEX"`
 	Issue   *Issue `xorm:"-"`
@@ -56,10 +58,10 @@ type PullRequest struct {
 	HeadBranch      string
 	BaseBranch      string
 	ProtectedBranch *ProtectedBranch `xorm:"-"`
-	MergeBase       string           `xorm:"VARCHAR(40)"`
+	MergeBase       string           `xorm:"VARCHAR(64)"`
 
 	HasMerged      bool               `xorm:"INDEX"`
-	MergedCommitID string             `xorm:"VARCHAR(40)"`
+	MergedCommitID string             `xorm:"VARCHAR(64)"`
 	MergerID       int64              `xorm:"INDEX"`
 	Merger         *User              `xorm:"-"`
 	MergedUnix     timeutil.TimeStamp `xorm:"updated INDEX"`
@@ -341,6 +343,10 @@ const (
 	MergeStyleRebaseMerge MergeStyle = "rebase-merge"
 	// MergeStyleSquash squash commits into single commit before merging
 	MergeStyleSquash MergeStyle = "squash"
+	// MergeStyleRebaseFastForward fast-forward if possible, rebase before fast-forwarding otherwise
+	MergeStyleRebaseFastForward MergeStyle = "rebase-fast-forward"
+	// MergeStyleFastForwardOnly fast-forward without rebasing or merging
+	MergeStyleFastForwardOnly MergeStyle = "fast-forward-only"
 )
 
 // SetMerged sets a pull request to merged and closes the corresponding issue
@@ -400,6 +406,11 @@ func (pr *PullRequest) SetMerged() (bool, error) {
 		return false, fmt.Errorf("Failed to update pr[%d]: %v", pr.ID, err)
 	}
 
+	// The pull request doesn't need to be merged once it is ready anymore
+	if _, err := sess.Delete(&PullAutoMerge{PullID: pr.ID}); err != nil {
+		return false, fmt.Errorf("Failed to delete the scheduled merge of pr[%d]: %v", pr.ID, err)
+	}
+
 	if err := sess.Commit(); err != nil {
 		return false, fmt.Errorf("Commit: %v", err)
 	}
@@ -581,8 +592,11 @@ func (pr *PullRequest) UpdateColsIfNotMerged(cols ...string) error {
 	return err
 }
 
-// IsWorkInProgress determine if the Pull Request is a Work In Progress by its title
+// IsWorkInProgress determine if the Pull Request is a Work In Progress, as a draft or by its title
 func (pr *PullRequest) IsWorkInProgress() bool {
+	if pr.IsDraft {
+		return true
+	}
 	if err := pr.LoadIssue(); err != nil {
 		log.Error("LoadIssue: %v", err)
 		return false
@@ -596,6 +610,27 @@ func (pr *PullRequest) IsWorkInProgress() bool {
 	return false
 }
 
+// MarkReadyForReview marks the draft pull request as ready for review and adds the corresponding comment to its timeline
+func (pr *PullRequest) MarkReadyForReview(doer *User) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	pr.IsDraft = false
+	if cnt, err := sess.ID(pr.ID).And("is_draft = ?", true).Cols("is_draft").Update(pr); err != nil {
+		return err
+	} else if cnt == 0 {
+		return ErrPullRequestNotDraft{ID: pr.ID}
+	}
+
+	if err := addPullMergeComment(sess, doer, pr, CommentTypePullReadyForReview, ""); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
 // IsFilesConflicted determines if the  Pull Request has changes conflicting with the target branch.
 func (pr *PullRequest) IsFilesConflicted() bool {
 	return len(pr.ConflictedFiles) > 0
@@ -0,0 +1,136 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// AutoMergeCancelledHeadChanged is the content of the comment of a scheduled merge cancelled
+// because the head branch of the pull request has changed
+const AutoMergeCancelledHeadChanged = "head_changed"
+
+// PullAutoMerge is a pull request scheduled to be merged on behalf of the doer once it is ready,
+// when its branch protection is satisfied
+type PullAutoMerge struct {
+	ID         int64      `xorm:"pk autoincr"`
+	PullID     int64      `xorm:"UNIQUE NOT NULL"`
+	DoerID     int64      `xorm:"NOT NULL"`
+	Doer       *User      `xorm:"-"`
+	MergeStyle MergeStyle `xorm:"varchar(30)"`
+	Message    string     `xorm:"TEXT"`
+	// HeadCommitID is the commit of the head branch when the merge has been scheduled,
+	// the merge is cancelled if the head branch changes so only the commits the doer has seen are merged
+	HeadCommitID string             `xorm:"VARCHAR(64)"`
+	CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+}
+
+// LoadDoer loads the user who scheduled the merge
+func (m *PullAutoMerge) LoadDoer() (err error) {
+	if m.Doer == nil {
+		m.Doer, err = GetUserByID(m.DoerID)
+	}
+	return err
+}
+
+// ScheduleAutoMerge schedules the merge of the pull request with the commit of its head branch with the style
+// and the message once it is ready and adds the corresponding comment to its timeline
+func ScheduleAutoMerge(doer *User, pr *PullRequest, headCommitID string, style MergeStyle, message string) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if exist, err := sess.Exist(&PullAutoMerge{PullID: pr.ID}); err != nil {
+		return err
+	} else if exist {
+		return ErrPullAutoMergeAlreadyScheduled{PullID: pr.ID}
+	}
+
+	if _, err := sess.Insert(&PullAutoMerge{
+		PullID:       pr.ID,
+		DoerID:       doer.ID,
+		MergeStyle:   style,
+		Message:      message,
+		HeadCommitID: headCommitID,
+	}); err != nil {
+		return err
+	}
+
+	if err := addPullMergeComment(sess, doer, pr, CommentTypePullScheduledMerge, ""); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// GetScheduledAutoMergeByPullID returns the scheduled merge of the pull request, nil if there isn't any
+func GetScheduledAutoMergeByPullID(pullID int64) (*PullAutoMerge, error) {
+	autoMerge := new(PullAutoMerge)
+	if has, err := x.Where("pull_id = ?", pullID).Get(autoMerge); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return autoMerge, nil
+}
+
+// GetScheduledAutoMergePullIDs returns the IDs of the open pull requests of the base repository
+// which are scheduled to be merged
+func GetScheduledAutoMergePullIDs(baseRepoID int64) ([]int64, error) {
+	pullIDs := make([]int64, 0, 5)
+	return pullIDs, x.Table("pull_auto_merge").
+		Join("INNER", "pull_request", "pull_request.id = pull_auto_merge.pull_id").
+		Join("INNER", "issue", "issue.id = pull_request.issue_id").
+		Where("pull_request.base_repo_id = ? AND issue.is_closed = ?", baseRepoID, false).
+		Cols("pull_auto_merge.pull_id").
+		Find(&pullIDs)
+}
+
+// CancelScheduledAutoMerge cancels the scheduled merge of the pull request and adds the corresponding comment
+// with the reason of the cancellation, empty if the doer cancelled it, to its timeline
+func CancelScheduledAutoMerge(doer *User, pr *PullRequest, reason string) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if cnt, err := sess.Delete(&PullAutoMerge{PullID: pr.ID}); err != nil {
+		return err
+	} else if cnt == 0 {
+		return ErrPullAutoMergeNotExist{PullID: pr.ID}
+	}
+
+	if err := addPullMergeComment(sess, doer, pr, CommentTypePullCancelledScheduledMerge, reason); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// DeleteScheduledAutoMerge deletes the scheduled merge of a pull request which has been merged or closed
+func DeleteScheduledAutoMerge(pullID int64) error {
+	_, err := x.Delete(&PullAutoMerge{PullID: pullID})
+	return err
+}
+
+func addPullMergeComment(e *xorm.Session, doer *User, pr *PullRequest, commentType CommentType, content string) error {
+	if err := pr.loadIssue(e); err != nil {
+		return err
+	}
+	if err := pr.Issue.loadRepo(e); err != nil {
+		return err
+	}
+	_, err := createComment(e, &CreateCommentOptions{
+		Type:    commentType,
+		Doer:    doer,
+		Repo:    pr.Issue.Repo,
+		Issue:   pr.Issue,
+		Content: content,
+	})
+	return err
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleAutoMerge(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+
+	autoMerge, err := GetScheduledAutoMergeByPullID(pr.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, autoMerge)
+
+	assert.NoError(t, ScheduleAutoMerge(doer, pr, "985f0301dba5e7b34be866819cd15ad3d8f508ee", MergeStyleSquash, "squashed"))
+	AssertExistsAndLoadBean(t, &Comment{IssueID: pr.IssueID, PosterID: doer.ID, Type: CommentTypePullScheduledMerge})
+
+	autoMerge, err = GetScheduledAutoMergeByPullID(pr.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, autoMerge) {
+		assert.Equal(t, doer.ID, autoMerge.DoerID)
+		assert.Equal(t, MergeStyleSquash, autoMerge.MergeStyle)
+		assert.Equal(t, "squashed", autoMerge.Message)
+		assert.Equal(t, "985f0301dba5e7b34be866819cd15ad3d8f508ee", autoMerge.HeadCommitID)
+		assert.NoError(t, autoMerge.LoadDoer())
+		assert.Equal(t, doer.Name, autoMerge.Doer.Name)
+	}
+
+	pullIDs, err := GetScheduledAutoMergePullIDs(pr.BaseRepoID)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{pr.ID}, pullIDs)
+
+	err = ScheduleAutoMerge(doer, pr, "985f0301dba5e7b34be866819cd15ad3d8f508ee", MergeStyleMerge, "")
+	assert.True(t, IsErrPullAutoMergeAlreadyScheduled(err))
+}
+
+func TestCancelScheduledAutoMerge(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+
+	err := CancelScheduledAutoMerge(doer, pr, "")
+	assert.True(t, IsErrPullAutoMergeNotExist(err))
+
+	assert.NoError(t, ScheduleAutoMerge(doer, pr, "985f0301dba5e7b34be866819cd15ad3d8f508ee", MergeStyleMerge, ""))
+	assert.NoError(t, CancelScheduledAutoMerge(doer, pr, ""))
+	AssertNotExistsBean(t, &PullAutoMerge{PullID: pr.ID})
+	AssertExistsAndLoadBean(t, &Comment{IssueID: pr.IssueID, PosterID: doer.ID, Type: CommentTypePullCancelledScheduledMerge})
+}
+
+func TestCloseScheduledAutoMerge(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+	assert.NoError(t, pr.LoadIssue())
+
+	// Closing the pull request cancels its scheduled merge, it isn't merged once reopened
+	assert.NoError(t, ScheduleAutoMerge(doer, pr, "985f0301dba5e7b34be866819cd15ad3d8f508ee", MergeStyleMerge, ""))
+	_, err := pr.Issue.ChangeStatus(doer, true)
+	assert.NoError(t, err)
+	AssertNotExistsBean(t, &PullAutoMerge{PullID: pr.ID})
+	_, err = pr.Issue.ChangeStatus(doer, false)
+	assert.NoError(t, err)
+	AssertNotExistsBean(t, &PullAutoMerge{PullID: pr.ID})
+}
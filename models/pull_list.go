@@ -68,6 +68,25 @@ func GetUnmergedPullRequestsByBaseInfo(repoID int64, branch string) ([]*PullRequ
 		Find(&prs)
 }
 
+// GetMergedPullRequestsByCommitIDs returns the pull requests of a repository merged by one of the given commits.
+func GetMergedPullRequestsByCommitIDs(repoID int64, commitIDs []string) (PullRequestList, error) {
+	prs := make(PullRequestList, 0, 10)
+	for i := 0; i < len(commitIDs); {
+		chunk := i + maxQueryParameters
+		if chunk > len(commitIDs) {
+			chunk = len(commitIDs)
+		}
+		if err := x.
+			Where("base_repo_id=? AND has_merged=?", repoID, true).
+			In("merged_commit_id", commitIDs[i:chunk]).
+			Find(&prs); err != nil {
+			return nil, err
+		}
+		i = chunk
+	}
+	return prs, nil
+}
+
 // GetPullRequestIDsByCheckStatus returns all pull requests according the special checking status.
 func GetPullRequestIDsByCheckStatus(status PullRequestStatus) ([]int64, error) {
 	prs := make([]int64, 0, 10)
@@ -14,6 +14,7 @@ import (
 	"code.gitea.io/gitea/modules/structs"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
 
 	"xorm.io/builder"
 )
@@ -31,15 +32,20 @@ type Release struct {
 	LowerTagName     string
 	Target           string
 	Title            string
-	Sha1             string `xorm:"VARCHAR(40)"`
+	Sha1             string `xorm:"VARCHAR(64)"`
 	NumCommits       int64
-	NumCommitsBehind int64              `xorm:"-"`
-	Note             string             `xorm:"TEXT"`
-	IsDraft          bool               `xorm:"NOT NULL DEFAULT false"`
-	IsPrerelease     bool               `xorm:"NOT NULL DEFAULT false"`
-	IsTag            bool               `xorm:"NOT NULL DEFAULT false"`
-	Attachments      []*Attachment      `xorm:"-"`
-	CreatedUnix      timeutil.TimeStamp `xorm:"INDEX"`
+	NumCommitsBehind int64  `xorm:"-"`
+	Note             string `xorm:"TEXT"`
+	IsDraft          bool   `xorm:"NOT NULL DEFAULT false"`
+	IsPrerelease     bool   `xorm:"NOT NULL DEFAULT false"`
+	IsTag            bool   `xorm:"NOT NULL DEFAULT false"`
+	IsYanked         bool   `xorm:"NOT NULL DEFAULT false"`
+	YankedReason     string `xorm:"TEXT"`
+	YankedUnix       timeutil.TimeStamp
+	Attachments      []*Attachment           `xorm:"-"`
+	ExternalAssets   []*ReleaseExternalAsset `xorm:"-"`
+	CreatedUnix      timeutil.TimeStamp      `xorm:"INDEX"`
+	UpdatedUnix      timeutil.TimeStamp      `xorm:"INDEX updated"`
 }
 
 func (r *Release) loadAttributes(e Engine) error {
@@ -56,7 +62,10 @@ func (r *Release) loadAttributes(e Engine) error {
 			return err
 		}
 	}
-	return getReleaseAttachments(e, r)
+	if err = getReleaseAttachments(e, r); err != nil {
+		return err
+	}
+	return getReleaseExternalAssets(e, r)
 }
 
 // LoadAttributes load repo and publisher attributes for a release
@@ -85,28 +94,90 @@ func (r *Release) HTMLURL() string {
 	return fmt.Sprintf("%s/releases/tag/%s", r.Repo.HTMLURL(), r.TagName)
 }
 
+// IsProtectedFrom returns true if the release is published, protected by the releases settings
+// of the repository and the user is not an admin of the repository. release must have attributes loaded
+func (r *Release) IsProtectedFrom(doer *User) (bool, error) {
+	if r.IsDraft || r.IsTag {
+		return false, nil
+	}
+
+	unit, err := r.Repo.GetUnit(UnitTypeReleases)
+	if err != nil {
+		if IsErrUnitTypeNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if !unit.ReleasesConfig().IsTagProtected(r.TagName) {
+		return false, nil
+	}
+
+	if doer == nil {
+		return true, nil
+	}
+	perm, err := GetUserRepoPermission(r.Repo, doer)
+	if err != nil {
+		return false, err
+	}
+	return !perm.IsAdmin(), nil
+}
+
+// TagMessage returns the message used for the annotated tag of a release
+func (r *Release) TagMessage() string {
+	title := r.Title
+	if len(title) == 0 {
+		title = r.TagName
+	}
+	if len(r.Note) == 0 {
+		return title
+	}
+	return title + "\n\n" + r.Note
+}
+
 // APIFormat convert a Release to api.Release
 func (r *Release) APIFormat() *api.Release {
 	assets := make([]*api.Attachment, 0)
+	verification := &api.ReleaseVerification{
+		Signatures:   make([]*api.Attachment, 0),
+		Certificates: make([]*api.Attachment, 0),
+		Provenance:   make([]*api.Attachment, 0),
+	}
 	for _, att := range r.Attachments {
-		assets = append(assets, att.APIFormat())
+		apiAttach := att.APIFormat()
+		assets = append(assets, apiAttach)
+		switch att.Role {
+		case AttachmentRoleSignature:
+			verification.Signatures = append(verification.Signatures, apiAttach)
+		case AttachmentRoleCertificate:
+			verification.Certificates = append(verification.Certificates, apiAttach)
+		case AttachmentRoleProvenance:
+			verification.Provenance = append(verification.Provenance, apiAttach)
+		}
+	}
+	externalAssets := make([]*api.ReleaseExternalAsset, 0, len(r.ExternalAssets))
+	for _, asset := range r.ExternalAssets {
+		externalAssets = append(externalAssets, asset.APIFormat())
 	}
 	return &api.Release{
-		ID:           r.ID,
-		TagName:      r.TagName,
-		Target:       r.Target,
-		Title:        r.Title,
-		Note:         r.Note,
-		URL:          r.APIURL(),
-		HTMLURL:      r.HTMLURL(),
-		TarURL:       r.TarURL(),
-		ZipURL:       r.ZipURL(),
-		IsDraft:      r.IsDraft,
-		IsPrerelease: r.IsPrerelease,
-		CreatedAt:    r.CreatedUnix.AsTime(),
-		PublishedAt:  r.CreatedUnix.AsTime(),
-		Publisher:    r.Publisher.APIFormat(),
-		Attachments:  assets,
+		ID:             r.ID,
+		TagName:        r.TagName,
+		Target:         r.Target,
+		Title:          r.Title,
+		Note:           r.Note,
+		URL:            r.APIURL(),
+		HTMLURL:        r.HTMLURL(),
+		TarURL:         r.TarURL(),
+		ZipURL:         r.ZipURL(),
+		IsDraft:        r.IsDraft,
+		IsPrerelease:   r.IsPrerelease,
+		IsYanked:       r.IsYanked,
+		YankedReason:   r.YankedReason,
+		CreatedAt:      r.CreatedUnix.AsTime(),
+		PublishedAt:    r.CreatedUnix.AsTime(),
+		Publisher:      r.Publisher.APIFormat(),
+		Attachments:    assets,
+		ExternalAssets: externalAssets,
+		Verification:   verification,
 	}
 }
 
@@ -137,16 +208,111 @@ func UpdateRelease(ctx DBContext, rel *Release) error {
 	return err
 }
 
+// CheckReleaseAssetQuota returns ErrReleaseAssetQuotaExceeded if assets of the given sizes
+// can't be added to the release without exceeding the release asset quotas of its repository.
+func CheckReleaseAssetQuota(rel *Release, sizes ...int64) error {
+	return checkReleaseAssetQuota(x, rel, sizes)
+}
+
+func checkReleaseAssetQuota(e Engine, rel *Release, sizes []int64) (err error) {
+	if len(sizes) == 0 {
+		return nil
+	}
+	if rel.Repo == nil {
+		if rel.Repo, err = getRepositoryByID(e, rel.RepoID); err != nil {
+			return err
+		}
+	}
+
+	if maxSize := rel.Repo.MaxReleaseAssetSize(); maxSize > -1 {
+		for _, size := range sizes {
+			if size > maxSize*1024*1024 {
+				return ErrReleaseAssetQuotaExceeded{Quota: "asset size", Limit: maxSize}
+			}
+		}
+	}
+
+	if maxAssets := rel.Repo.MaxReleaseAssets(); maxAssets > -1 {
+		var count int64
+		if rel.ID > 0 {
+			if count, err = e.Where("release_id = ?", rel.ID).Count(new(Attachment)); err != nil {
+				return err
+			}
+		}
+		if count+int64(len(sizes)) > int64(maxAssets) {
+			return ErrReleaseAssetQuotaExceeded{Quota: "assets", Limit: int64(maxAssets)}
+		}
+	}
+
+	if maxTotalSize := rel.Repo.MaxReleaseTotalSize(); maxTotalSize > -1 {
+		total, err := e.
+			Join("INNER", "`release`", "`release`.id = attachment.release_id").
+			Where("`release`.repo_id = ?", rel.RepoID).
+			SumInt(new(Attachment), "attachment.size")
+		if err != nil {
+			return err
+		}
+		for _, size := range sizes {
+			total += size
+		}
+		if total > maxTotalSize*1024*1024 {
+			return ErrReleaseAssetQuotaExceeded{Quota: "total size", Limit: maxTotalSize}
+		}
+	}
+	return nil
+}
+
+// CheckReleaseAttachmentsQuota returns ErrReleaseAssetQuotaExceeded if the given attachments
+// can't be added to the release without exceeding the release asset quotas of its repository.
+func CheckReleaseAttachmentsQuota(rel *Release, attachmentUUIDs []string) error {
+	if len(attachmentUUIDs) == 0 {
+		return nil
+	}
+	attachments, err := getAttachmentsByUUIDs(x, attachmentUUIDs)
+	if err != nil {
+		return fmt.Errorf("GetAttachmentsByUUIDs [uuids: %v]: %v", attachmentUUIDs, err)
+	}
+	return checkReleaseAttachmentsQuota(x, rel, attachments)
+}
+
+func checkReleaseAttachmentsQuota(e Engine, rel *Release, attachments []*Attachment) error {
+	sizes := make([]int64, 0, len(attachments))
+	for _, attach := range attachments {
+		// Attachments which already belong to the release are counted already
+		if rel.ID == 0 || attach.ReleaseID != rel.ID {
+			sizes = append(sizes, attach.Size)
+		}
+	}
+	return checkReleaseAssetQuota(e, rel, sizes)
+}
+
 // AddReleaseAttachments adds a release attachments
 func AddReleaseAttachments(releaseID int64, attachmentUUIDs []string) (err error) {
+	if len(attachmentUUIDs) == 0 {
+		return nil
+	}
+
 	// Check attachments
 	attachments, err := GetAttachmentsByUUIDs(attachmentUUIDs)
 	if err != nil {
 		return fmt.Errorf("GetAttachmentsByUUIDs [uuids: %v]: %v", attachmentUUIDs, err)
 	}
 
+	rel, err := GetReleaseByID(releaseID)
+	if err != nil {
+		return err
+	}
+	if err = checkReleaseAttachmentsQuota(x, rel, attachments); err != nil {
+		return err
+	}
+
 	for i := range attachments {
 		attachments[i].ReleaseID = releaseID
+		if len(attachments[i].Sha256) == 0 {
+			if err = attachments[i].CalculateChecksums(); err != nil {
+				return fmt.Errorf("calculate checksums of attachment [%d]: %v", attachments[i].ID, err)
+			}
+		}
 		// No assign value could be 0, so ignore AllCols().
 		if _, err = x.ID(attachments[i].ID).Update(attachments[i]); err != nil {
 			return fmt.Errorf("update attachment [%d]: %v", attachments[i].ID, err)
@@ -190,7 +356,12 @@ type FindReleasesOptions struct {
 	ListOptions
 	IncludeDrafts bool
 	IncludeTags   bool
+	IsDraft       util.OptionalBool
+	IsPreRelease  util.OptionalBool
+	// Keyword filters the tag names by a glob pattern, or by a substring if it has no wildcards
+	Keyword       string
 	TagNames      []string
+	CreatedBefore timeutil.TimeStamp
 }
 
 func (opts *FindReleasesOptions) toConds(repoID int64) builder.Cond {
@@ -203,12 +374,53 @@ func (opts *FindReleasesOptions) toConds(repoID int64) builder.Cond {
 	if !opts.IncludeTags {
 		cond = cond.And(builder.Eq{"is_tag": false})
 	}
+	if !opts.IsDraft.IsNone() {
+		cond = cond.And(builder.Eq{"is_draft": opts.IsDraft.IsTrue()})
+	}
+	if !opts.IsPreRelease.IsNone() {
+		cond = cond.And(builder.Eq{"is_prerelease": opts.IsPreRelease.IsTrue()})
+	}
+	if len(opts.Keyword) > 0 {
+		cond = cond.And(builder.Expr("lower_tag_name LIKE ? ESCAPE '!'", tagNameLikePattern(opts.Keyword)))
+	}
 	if len(opts.TagNames) > 0 {
 		cond = cond.And(builder.In("tag_name", opts.TagNames))
 	}
+	if opts.CreatedBefore > 0 {
+		cond = cond.And(builder.Lt{"created_unix": opts.CreatedBefore})
+	}
 	return cond
 }
 
+// tagNameLikePattern converts a keyword to a case insensitive LIKE pattern using '!' as escape character.
+// The wildcards '*' and '?' of a glob are supported, a keyword without wildcards matches as substring.
+func tagNameLikePattern(keyword string) string {
+	keyword = strings.ToLower(keyword)
+	isGlob := strings.ContainsAny(keyword, "*?")
+
+	var pattern strings.Builder
+	if !isGlob {
+		pattern.WriteByte('%')
+	}
+	for _, c := range keyword {
+		switch c {
+		case '*':
+			pattern.WriteByte('%')
+		case '?':
+			pattern.WriteByte('_')
+		case '%', '_', '!':
+			pattern.WriteByte('!')
+			pattern.WriteRune(c)
+		default:
+			pattern.WriteRune(c)
+		}
+	}
+	if !isGlob {
+		pattern.WriteByte('%')
+	}
+	return pattern.String()
+}
+
 // GetReleasesByRepoID returns a list of releases of repository.
 func GetReleasesByRepoID(repoID int64, opts FindReleasesOptions) ([]*Release, error) {
 	sess := x.
@@ -223,13 +435,14 @@ func GetReleasesByRepoID(repoID int64, opts FindReleasesOptions) ([]*Release, er
 	return rels, sess.Find(&rels)
 }
 
-// GetLatestReleaseByRepoID returns the latest release for a repository
+// GetLatestReleaseByRepoID returns the latest release for a repository, yanked releases are skipped
 func GetLatestReleaseByRepoID(repoID int64) (*Release, error) {
 	cond := builder.NewCond().
 		And(builder.Eq{"repo_id": repoID}).
 		And(builder.Eq{"is_draft": false}).
 		And(builder.Eq{"is_prerelease": false}).
-		And(builder.Eq{"is_tag": false})
+		And(builder.Eq{"is_tag": false}).
+		And(builder.Eq{"is_yanked": false})
 
 	rel := new(Release)
 	has, err := x.
@@ -245,6 +458,28 @@ func GetLatestReleaseByRepoID(repoID int64) (*Release, error) {
 	return rel, nil
 }
 
+// GetPreviousRelease returns the latest published release of the repository created before the given release
+func GetPreviousRelease(rel *Release) (*Release, error) {
+	cond := builder.NewCond().
+		And(builder.Eq{"repo_id": rel.RepoID}).
+		And(builder.Eq{"is_draft": false}).
+		And(builder.Eq{"is_tag": false}).
+		And(builder.Lt{"created_unix": rel.CreatedUnix}.
+			Or(builder.Eq{"created_unix": rel.CreatedUnix}.And(builder.Lt{"id": rel.ID})))
+
+	previous := new(Release)
+	has, err := x.
+		Desc("created_unix", "id").
+		Where(cond).
+		Get(previous)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrReleaseNotExist{0, "previous"}
+	}
+	return previous, nil
+}
+
 // GetReleasesByRepoIDAndNames returns a list of releases of repository according repoID and tagNames.
 func GetReleasesByRepoIDAndNames(ctx DBContext, repoID int64, tagNames []string) (rels []*Release, err error) {
 	err = ctx.e.
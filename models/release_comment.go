@@ -0,0 +1,156 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ReleaseComment represents a comment on a published release
+type ReleaseComment struct {
+	ID              int64    `xorm:"pk autoincr"`
+	RepoID          int64    `xorm:"INDEX"`
+	ReleaseID       int64    `xorm:"INDEX"`
+	Release         *Release `xorm:"-"`
+	PosterID        int64    `xorm:"INDEX"`
+	Poster          *User    `xorm:"-"`
+	Content         string   `xorm:"TEXT"`
+	RenderedContent string   `xorm:"-"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// HashTag returns the id of the comment in the release page.
+func (c *ReleaseComment) HashTag() string {
+	return fmt.Sprintf("releasecomment-%d", c.ID)
+}
+
+// HTMLURL returns the URL of the comment in the release page, the release has to be loaded.
+func (c *ReleaseComment) HTMLURL() string {
+	return c.Release.HTMLURL() + "#" + c.HashTag()
+}
+
+// LoadPoster loads the poster of the comment, a ghost user is used if it has been deleted.
+func (c *ReleaseComment) LoadPoster() error {
+	return c.loadPoster(x)
+}
+
+func (c *ReleaseComment) loadPoster(e Engine) (err error) {
+	if c.Poster != nil {
+		return nil
+	}
+	c.Poster, err = getUserByID(e, c.PosterID)
+	if err != nil {
+		if !IsErrUserNotExist(err) {
+			return err
+		}
+		c.PosterID = -1
+		c.Poster = NewGhostUser()
+		err = nil
+	}
+	return err
+}
+
+// LoadRelease loads the release of the comment with its repository.
+func (c *ReleaseComment) LoadRelease() (err error) {
+	if c.Release == nil {
+		if c.Release, err = GetReleaseByID(c.ReleaseID); err != nil {
+			return err
+		}
+	}
+	if c.Release.Repo == nil {
+		c.Release.Repo, err = GetRepositoryByID(c.Release.RepoID)
+	}
+	return err
+}
+
+// APIFormat converts a ReleaseComment to api.ReleaseComment, the poster and the release have to be loaded.
+func (c *ReleaseComment) APIFormat() *api.ReleaseComment {
+	return &api.ReleaseComment{
+		ID:        c.ID,
+		HTMLURL:   c.HTMLURL(),
+		ReleaseID: c.ReleaseID,
+		Poster:    c.Poster.APIFormat(),
+		Body:      c.Content,
+		Created:   c.CreatedUnix.AsTime(),
+		Updated:   c.UpdatedUnix.AsTime(),
+	}
+}
+
+// CreateReleaseComment adds a comment from the doer to the release.
+func CreateReleaseComment(doer *User, rel *Release, content string) (*ReleaseComment, error) {
+	comment := &ReleaseComment{
+		RepoID:    rel.RepoID,
+		ReleaseID: rel.ID,
+		Release:   rel,
+		PosterID:  doer.ID,
+		Poster:    doer,
+		Content:   content,
+	}
+	if _, err := x.Insert(comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// GetReleaseCommentByID returns the release comment by given ID.
+func GetReleaseCommentByID(id int64) (*ReleaseComment, error) {
+	comment := new(ReleaseComment)
+	has, err := x.ID(id).Get(comment)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrReleaseCommentNotExist{id}
+	}
+	return comment, nil
+}
+
+// FindReleaseComments returns the comments of a release ordered by their creation, with their posters loaded.
+func FindReleaseComments(releaseID int64, listOptions ListOptions) ([]*ReleaseComment, error) {
+	sess := x.Where("release_id = ?", releaseID).Asc("created_unix", "id")
+	if listOptions.Page != 0 {
+		sess = listOptions.setSessionPagination(sess)
+	}
+
+	comments := make([]*ReleaseComment, 0, 10)
+	if err := sess.Find(&comments); err != nil {
+		return nil, err
+	}
+
+	posters := make(map[int64]*User)
+	for _, comment := range comments {
+		if poster, ok := posters[comment.PosterID]; ok {
+			comment.Poster = poster
+			continue
+		}
+		if err := comment.loadPoster(x); err != nil {
+			return nil, err
+		}
+		posters[comment.PosterID] = comment.Poster
+	}
+	return comments, nil
+}
+
+// UpdateReleaseComment updates the content of a release comment.
+func UpdateReleaseComment(comment *ReleaseComment) error {
+	_, err := x.ID(comment.ID).Cols("content").Update(comment)
+	return err
+}
+
+// DeleteReleaseComment deletes a release comment.
+func DeleteReleaseComment(comment *ReleaseComment) error {
+	_, err := x.ID(comment.ID).Delete(new(ReleaseComment))
+	return err
+}
+
+// DeleteReleaseCommentsByRelease deletes all the comments of the given release.
+func DeleteReleaseCommentsByRelease(releaseID int64) error {
+	_, err := x.Where("release_id = ?", releaseID).Delete(new(ReleaseComment))
+	return err
+}
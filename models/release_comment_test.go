@@ -0,0 +1,44 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleaseComments(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	rel := AssertExistsAndLoadBean(t, &Release{ID: 1}).(*Release)
+	doer := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+
+	comment, err := CreateReleaseComment(doer, rel, "second comment")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, comment.RepoID)
+
+	comments, err := FindReleaseComments(rel.ID, ListOptions{})
+	assert.NoError(t, err)
+	if assert.Len(t, comments, 2) {
+		assert.Equal(t, "first comment", comments[0].Content)
+		assert.EqualValues(t, 2, comments[0].Poster.ID)
+		assert.Equal(t, "second comment", comments[1].Content)
+		assert.EqualValues(t, 4, comments[1].Poster.ID)
+	}
+
+	comment.Content = "edited comment"
+	assert.NoError(t, UpdateReleaseComment(comment))
+	AssertExistsAndLoadBean(t, &ReleaseComment{ID: comment.ID, Content: "edited comment"})
+
+	assert.NoError(t, DeleteReleaseComment(comment))
+	_, err = GetReleaseCommentByID(comment.ID)
+	assert.True(t, IsErrReleaseCommentNotExist(err))
+
+	assert.NoError(t, DeleteReleaseCommentsByRelease(rel.ID))
+	comments, err = FindReleaseComments(rel.ID, ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, comments)
+}
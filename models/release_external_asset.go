@@ -0,0 +1,92 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ReleaseExternalAsset represents a downloadable asset of a release which is hosted outside of Gitea
+type ReleaseExternalAsset struct {
+	ID          int64              `xorm:"pk autoincr"`
+	ReleaseID   int64              `xorm:"INDEX"`
+	Name        string             `xorm:"NOT NULL"`
+	URL         string             `xorm:"TEXT NOT NULL"`
+	Size        int64              `xorm:"DEFAULT 0"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// APIFormat converts a ReleaseExternalAsset to api.ReleaseExternalAsset
+func (a *ReleaseExternalAsset) APIFormat() *api.ReleaseExternalAsset {
+	return &api.ReleaseExternalAsset{
+		ID:          a.ID,
+		Name:        a.Name,
+		Size:        a.Size,
+		Created:     a.CreatedUnix.AsTime(),
+		DownloadURL: a.URL,
+	}
+}
+
+// GetReleaseExternalAssets retrieves the external assets for releases
+func GetReleaseExternalAssets(rels ...*Release) error {
+	return getReleaseExternalAssets(x, rels...)
+}
+
+func getReleaseExternalAssets(e Engine, rels ...*Release) error {
+	if len(rels) == 0 {
+		return nil
+	}
+
+	relMap := make(map[int64]*Release, len(rels))
+	ids := make([]int64, 0, len(rels))
+	for _, rel := range rels {
+		rel.ExternalAssets = []*ReleaseExternalAsset{}
+		relMap[rel.ID] = rel
+		ids = append(ids, rel.ID)
+	}
+
+	assets := make([]*ReleaseExternalAsset, 0, len(rels))
+	if err := e.
+		In("release_id", ids).
+		Asc("id").
+		Find(&assets); err != nil {
+		return err
+	}
+
+	for _, asset := range assets {
+		rel := relMap[asset.ReleaseID]
+		rel.ExternalAssets = append(rel.ExternalAssets, asset)
+	}
+	return nil
+}
+
+// UpdateReleaseExternalAssets replaces the external assets of a release by the given ones
+func UpdateReleaseExternalAssets(releaseID int64, assets []*ReleaseExternalAsset) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := sess.Where("release_id = ?", releaseID).Delete(new(ReleaseExternalAsset)); err != nil {
+		return err
+	}
+	for _, asset := range assets {
+		asset.ID = 0
+		asset.ReleaseID = releaseID
+		if _, err := sess.Insert(asset); err != nil {
+			return err
+		}
+	}
+
+	return sess.Commit()
+}
+
+// DeleteReleaseExternalAssetsByRelease deletes all external assets of the given release
+func DeleteReleaseExternalAssetsByRelease(releaseID int64) error {
+	_, err := x.Where("release_id = ?", releaseID).Delete(new(ReleaseExternalAsset))
+	return err
+}
@@ -0,0 +1,37 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateReleaseExternalAssets(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, UpdateReleaseExternalAssets(1, []*ReleaseExternalAsset{
+		{Name: "gitea-linux-amd64", URL: "https://cdn.example.com/gitea-linux-amd64", Size: 1024},
+		{Name: "gitea-windows-amd64.exe", URL: "https://cdn.example.com/gitea-windows-amd64.exe"},
+	}))
+
+	rel := AssertExistsAndLoadBean(t, &Release{ID: 1}).(*Release)
+	assert.NoError(t, GetReleaseExternalAssets(rel))
+	assert.Len(t, rel.ExternalAssets, 2)
+	assert.EqualValues(t, "gitea-linux-amd64", rel.ExternalAssets[0].Name)
+	assert.EqualValues(t, 1024, rel.ExternalAssets[0].Size)
+
+	// assets are replaced on update
+	assert.NoError(t, UpdateReleaseExternalAssets(1, []*ReleaseExternalAsset{
+		{Name: "gitea-darwin-amd64", URL: "https://cdn.example.com/gitea-darwin-amd64"},
+	}))
+	assert.NoError(t, GetReleaseExternalAssets(rel))
+	assert.Len(t, rel.ExternalAssets, 1)
+	assert.EqualValues(t, "https://cdn.example.com/gitea-darwin-amd64", rel.ExternalAssets[0].APIFormat().DownloadURL)
+
+	assert.NoError(t, DeleteReleaseExternalAssetsByRelease(1))
+	AssertNotExistsBean(t, &ReleaseExternalAsset{ReleaseID: 1})
+}
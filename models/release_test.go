@@ -0,0 +1,98 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetReleasesByRepoID_Filters(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	for _, rel := range []*Release{
+		{TagName: "v1.2", IsPrerelease: true},
+		{TagName: "v2.0_rc1", IsDraft: true},
+		{TagName: "nightly"},
+	} {
+		rel.RepoID = 1
+		rel.PublisherID = 2
+		rel.LowerTagName = strings.ToLower(rel.TagName)
+		assert.NoError(t, InsertRelease(rel))
+	}
+
+	testSuccess := func(opts FindReleasesOptions, expectedTags ...string) {
+		releases, err := GetReleasesByRepoID(1, opts)
+		assert.NoError(t, err)
+		tags := make([]string, 0, len(releases))
+		for _, rel := range releases {
+			tags = append(tags, rel.TagName)
+		}
+		assert.ElementsMatch(t, expectedTags, tags)
+
+		count, err := GetReleaseCountByRepoID(1, opts)
+		assert.NoError(t, err)
+		assert.EqualValues(t, len(expectedTags), count)
+	}
+
+	testSuccess(FindReleasesOptions{}, "v1.1", "v1.2", "nightly")
+	testSuccess(FindReleasesOptions{IncludeDrafts: true}, "v1.1", "v1.2", "v2.0_rc1", "nightly")
+	testSuccess(FindReleasesOptions{IncludeDrafts: true, IsDraft: util.OptionalBoolTrue}, "v2.0_rc1")
+	testSuccess(FindReleasesOptions{IsDraft: util.OptionalBoolTrue})
+	testSuccess(FindReleasesOptions{IsPreRelease: util.OptionalBoolTrue}, "v1.2")
+	testSuccess(FindReleasesOptions{IsPreRelease: util.OptionalBoolFalse}, "v1.1", "nightly")
+	testSuccess(FindReleasesOptions{IncludeDrafts: true, Keyword: "V1.*"}, "v1.1", "v1.2")
+	testSuccess(FindReleasesOptions{IncludeDrafts: true, Keyword: "v?.?"}, "v1.1", "v1.2")
+	testSuccess(FindReleasesOptions{IncludeDrafts: true, Keyword: "ight"}, "nightly")
+	testSuccess(FindReleasesOptions{IncludeDrafts: true, Keyword: "_rc"}, "v2.0_rc1")
+	testSuccess(FindReleasesOptions{IncludeDrafts: true, Keyword: "1_"})
+}
+
+func TestCheckReleaseAssetQuota(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	const mb = 1024 * 1024
+	defaultQuota := setting.Repository.Release
+	defer func() {
+		setting.Repository.Release = defaultQuota
+	}()
+	setting.Repository.Release.MaxAssetSize = 2
+	setting.Repository.Release.MaxAssets = 3
+	setting.Repository.Release.MaxTotalSize = 5
+
+	rel := AssertExistsAndLoadBean(t, &Release{ID: 1}).(*Release)
+	attach := AssertExistsAndLoadBean(t, &Attachment{ID: 9}).(*Attachment)
+	attach.Size = 2 * mb
+	_, err := x.ID(attach.ID).Cols("size").Update(attach)
+	assert.NoError(t, err)
+
+	assert.NoError(t, CheckReleaseAssetQuota(rel))
+	assert.NoError(t, CheckReleaseAssetQuota(rel, 2*mb, mb))
+
+	err = CheckReleaseAssetQuota(rel, 2*mb+1)
+	assert.Equal(t, ErrReleaseAssetQuotaExceeded{Quota: "asset size", Limit: 2}, err)
+	err = CheckReleaseAssetQuota(rel, 1, 1, 1)
+	assert.Equal(t, ErrReleaseAssetQuotaExceeded{Quota: "assets", Limit: 3}, err)
+	err = CheckReleaseAssetQuota(rel, 2*mb, 2*mb)
+	assert.Equal(t, ErrReleaseAssetQuotaExceeded{Quota: "total size", Limit: 5}, err)
+
+	// The quotas of a repository override the global ones
+	rel.Repo.ReleaseMaxAssets = 10
+	rel.Repo.ReleaseMaxTotalSize = 100
+	assert.NoError(t, CheckReleaseAssetQuota(rel, 2*mb, 2*mb, 2*mb))
+
+	// Attachments which already belong to the release don't count twice
+	rel.Repo.ReleaseMaxAssets = 1
+	_, err = x.ID(rel.Repo.ID).Cols("release_max_assets").Update(rel.Repo)
+	assert.NoError(t, err)
+	assert.NoError(t, CheckReleaseAttachmentsQuota(rel, []string{attach.UUID}))
+	err = AddReleaseAttachments(rel.ID, []string{"a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a20"})
+	assert.True(t, IsErrReleaseAssetQuotaExceeded(err))
+}
@@ -26,15 +26,18 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/modules/avatar"
+	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup"
 	"code.gitea.io/gitea/modules/options"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 
 	"github.com/unknwon/com"
+	"xorm.io/builder"
 )
 
 var (
@@ -152,6 +155,7 @@ type Repository struct {
 	OriginalServiceType api.GitServiceType `xorm:"index"`
 	OriginalURL         string             `xorm:"VARCHAR(2048)"`
 	DefaultBranch       string
+	ObjectFormatName    string `xorm:"VARCHAR(6) NOT NULL DEFAULT 'sha1'"`
 
 	NumWatches          int
 	NumStars            int
@@ -191,6 +195,15 @@ type Repository struct {
 	CloseIssuesViaCommitInAnyBranch bool               `xorm:"NOT NULL DEFAULT false"`
 	Topics                          []string           `xorm:"TEXT JSON"`
 
+	// Release asset quotas overriding the global settings, 0 or -1 means the global setting is used
+	ReleaseMaxAssetSize int64 `xorm:"NOT NULL DEFAULT -1"`
+	ReleaseMaxAssets    int   `xorm:"NOT NULL DEFAULT -1"`
+	ReleaseMaxTotalSize int64 `xorm:"NOT NULL DEFAULT -1"`
+
+	// How verified commit signatures are trusted, and the keys trusted by the trusted keys model
+	TrustModel  TrustModelType `xorm:"NOT NULL DEFAULT 0"`
+	TrustedKeys string         `xorm:"TEXT"`
+
 	// Avatar: ID(10-20)-md5(32) - must fit into 64 symbols
 	Avatar string `xorm:"VARCHAR(64)"`
 
@@ -198,6 +211,30 @@ type Repository struct {
 	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
 }
 
+// MaxReleaseAssetSize returns the maximum size in MB of a release asset, -1 means no limit
+func (repo *Repository) MaxReleaseAssetSize() int64 {
+	if repo.ReleaseMaxAssetSize <= 0 {
+		return setting.Repository.Release.MaxAssetSize
+	}
+	return repo.ReleaseMaxAssetSize
+}
+
+// MaxReleaseAssets returns the maximum number of assets of a release, -1 means no limit
+func (repo *Repository) MaxReleaseAssets() int {
+	if repo.ReleaseMaxAssets <= 0 {
+		return setting.Repository.Release.MaxAssets
+	}
+	return repo.ReleaseMaxAssets
+}
+
+// MaxReleaseTotalSize returns the maximum size in MB of all release assets, -1 means no limit
+func (repo *Repository) MaxReleaseTotalSize() int64 {
+	if repo.ReleaseMaxTotalSize <= 0 {
+		return setting.Repository.Release.MaxTotalSize
+	}
+	return repo.ReleaseMaxTotalSize
+}
+
 // SanitizedOriginalURL returns a sanitized OriginalURL
 func (repo *Repository) SanitizedOriginalURL() string {
 	if repo.OriginalURL == "" {
@@ -206,6 +243,14 @@ func (repo *Repository) SanitizedOriginalURL() string {
 	return util.SanitizeURLCredentials(repo.OriginalURL, false)
 }
 
+// ObjectFormat returns the hash algorithm of the objects of the repository
+func (repo *Repository) ObjectFormat() git.ObjectFormat {
+	if repo.ObjectFormatName == "" {
+		return git.Sha1ObjectFormat
+	}
+	return git.ObjectFormat(repo.ObjectFormatName)
+}
+
 // ColorFormat returns a colored string to represent this repo
 func (repo *Repository) ColorFormat(s fmt.State) {
 	var ownerName interface{}
@@ -351,6 +396,9 @@ func (repo *Repository) innerAPIFormat(e Engine, mode AccessMode, isParent bool)
 	allowRebase := false
 	allowRebaseMerge := false
 	allowSquash := false
+	allowRebaseFastForward := false
+	allowFastForwardOnly := false
+	squashMessageTemplate := ""
 	if unit, err := repo.getUnit(e, UnitTypePullRequests); err == nil {
 		config := unit.PullRequestsConfig()
 		hasPullRequests = true
@@ -359,12 +407,20 @@ func (repo *Repository) innerAPIFormat(e Engine, mode AccessMode, isParent bool)
 		allowRebase = config.AllowRebase
 		allowRebaseMerge = config.AllowRebaseMerge
 		allowSquash = config.AllowSquash
+		allowRebaseFastForward = config.AllowRebaseFastForward
+		allowFastForwardOnly = config.AllowFastForwardOnly
+		squashMessageTemplate = config.SquashMessageTemplate
 	}
 
 	repo.mustOwner(e)
 
 	numReleases, _ := GetReleaseCountByRepoID(repo.ID, FindReleasesOptions{IncludeDrafts: false, IncludeTags: true})
 
+	topics := repo.Topics
+	if topics == nil {
+		topics = []string{}
+	}
+
 	return &api.Repository{
 		ID:                        repo.ID,
 		Owner:                     repo.Owner.APIFormat(),
@@ -390,6 +446,8 @@ func (repo *Repository) innerAPIFormat(e Engine, mode AccessMode, isParent bool)
 		OpenPulls:                 repo.NumOpenPulls,
 		Releases:                  int(numReleases),
 		DefaultBranch:             repo.DefaultBranch,
+		ObjectFormatName:          string(repo.ObjectFormat()),
+		TrustModel:                repo.GetTrustModel().String(),
 		Created:                   repo.CreatedUnix.AsTime(),
 		Updated:                   repo.UpdatedUnix.AsTime(),
 		Permissions:               permission,
@@ -404,8 +462,12 @@ func (repo *Repository) innerAPIFormat(e Engine, mode AccessMode, isParent bool)
 		AllowRebase:               allowRebase,
 		AllowRebaseMerge:          allowRebaseMerge,
 		AllowSquash:               allowSquash,
+		AllowRebaseFastForward:    allowRebaseFastForward,
+		AllowFastForwardOnly:      allowFastForwardOnly,
+		SquashMessageTemplate:     squashMessageTemplate,
 		AvatarURL:                 repo.avatarLink(e),
 		Internal:                  !repo.IsPrivate && repo.Owner.Visibility == api.VisibleTypePrivate,
+		Topics:                    topics,
 	}
 }
 
@@ -495,6 +557,11 @@ func (repo *Repository) MustGetUnit(tp UnitType) *RepoUnit {
 			Type:   tp,
 			Config: new(IssuesConfig),
 		}
+	} else if tp == UnitTypeReleases {
+		return &RepoUnit{
+			Type:   tp,
+			Config: new(ReleasesConfig),
+		}
 	}
 	return &RepoUnit{
 		Type:   tp,
@@ -1023,10 +1090,12 @@ type CreateRepoOptions struct {
 	License        string
 	Readme         string
 	DefaultBranch  string
-	IsPrivate      bool
-	IsMirror       bool
-	AutoInit       bool
-	Status         RepositoryStatus
+	// ObjectFormatName is the hash algorithm of the objects of the repository, sha1 if it is empty
+	ObjectFormatName string
+	IsPrivate        bool
+	IsMirror         bool
+	AutoInit         bool
+	Status           RepositoryStatus
 }
 
 // GetRepoInitFile returns repository init files
@@ -1267,6 +1336,11 @@ func TransferOwnership(doer *User, newOwnerName string, repo *Repository) error
 		}
 	}
 
+	// The repository isn't waiting for a transfer anymore.
+	if err = deleteRepositoryTransfer(sess, repo.ID); err != nil {
+		return fmt.Errorf("deleteRepositoryTransfer: %v", err)
+	}
+
 	// Remove old team-repository relations.
 	if oldOwner.IsOrganization() {
 		if err = oldOwner.removeOrgRepo(sess, repo.ID); err != nil {
@@ -1318,6 +1392,9 @@ func TransferOwnership(doer *User, newOwnerName string, repo *Repository) error
 	if err = os.Rename(RepoPath(oldOwner.Name, repo.Name), RepoPath(newOwner.Name, repo.Name)); err != nil {
 		return fmt.Errorf("rename repository directory: %v", err)
 	}
+	if err = relinkForks(sess, repo, RepoPath(oldOwner.Name, repo.Name), RepoPath(newOwner.Name, repo.Name)); err != nil {
+		return fmt.Errorf("relinkForks: %v", err)
+	}
 
 	// Rename remote wiki repository to new path and delete local copy.
 	wikiPath := WikiPath(oldOwner.Name, repo.Name)
@@ -1358,10 +1435,14 @@ func ChangeRepositoryName(doer *User, repo *Repository, newRepoName string) (err
 		return ErrRepoAlreadyExist{repo.Owner.Name, newRepoName}
 	}
 
+	oldRepoPath := repo.RepoPath()
 	newRepoPath := RepoPath(repo.Owner.Name, newRepoName)
-	if err = os.Rename(repo.RepoPath(), newRepoPath); err != nil {
+	if err = os.Rename(oldRepoPath, newRepoPath); err != nil {
 		return fmt.Errorf("rename repository directory: %v", err)
 	}
+	if err = relinkForks(x, repo, oldRepoPath, newRepoPath); err != nil {
+		return fmt.Errorf("relinkForks: %v", err)
+	}
 
 	wikiPath := repo.WikiPath()
 	if com.IsExist(wikiPath) {
@@ -1582,10 +1663,16 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 	}
 	releaseAttachments := make([]string, 0, len(attachments))
 	for i := 0; i < len(attachments); i++ {
-		releaseAttachments = append(releaseAttachments, attachments[i].LocalPath())
+		releaseAttachments = append(releaseAttachments, attachments[i].RelativePath())
+	}
+
+	if _, err = sess.In("pull_id", builder.Select("id").From("pull_request").Where(builder.Eq{"base_repo_id": repoID})).
+		Delete(&PullAutoMerge{}); err != nil {
+		return err
 	}
 
 	if err = deleteBeans(sess,
+		&MergeQueueEntry{BaseRepoID: repoID},
 		&Access{RepoID: repo.ID},
 		&Action{RepoID: repo.ID},
 		&Watch{RepoID: repoID},
@@ -1593,18 +1680,22 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		&Mirror{RepoID: repoID},
 		&Milestone{RepoID: repoID},
 		&Release{RepoID: repoID},
+		&ReleaseComment{RepoID: repoID},
 		&Collaboration{RepoID: repoID},
 		&PullRequest{BaseRepoID: repoID},
 		&RepoUnit{RepoID: repoID},
 		&RepoRedirect{RedirectRepoID: repoID},
 		&Webhook{RepoID: repoID},
 		&HookTask{RepoID: repoID},
+		&WebhookOptOut{RepoID: repoID},
 		&Notification{RepoID: repoID},
 		&CommitStatus{RepoID: repoID},
 		&RepoIndexerStatus{RepoID: repoID},
 		&LanguageStat{RepoID: repoID},
 		&Comment{RefRepoID: repoID},
 		&Task{RepoID: repoID},
+		&RepoTransfer{RepoID: repoID},
+		&RepoGitConfig{RepoID: repoID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
@@ -1635,6 +1726,11 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		}
 	}
 
+	// The forks can't borrow the objects of the repository anymore once its files are removed
+	if err = dissociateForks(sess, repo); err != nil {
+		return fmt.Errorf("dissociateForks: %v", err)
+	}
+
 	// FIXME: Remove repository files should be executed after transaction succeed.
 	repoPath := repo.RepoPath()
 	removeAllWithNotice(sess, "Delete repository files", repoPath)
@@ -1650,6 +1746,7 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		return err
 	}
 
+	var lfsPaths = make([]string, 0, len(lfsObjects))
 	for _, v := range lfsObjects {
 		count, err := sess.Count(&LFSMetaObject{Oid: v.Oid})
 		if err != nil {
@@ -1659,8 +1756,7 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 			continue
 		}
 
-		oidPath := filepath.Join(setting.LFS.ContentPath, v.Oid[0:2], v.Oid[2:4], v.Oid[4:len(v.Oid)])
-		removeAllWithNotice(sess, "Delete orphaned LFS file", oidPath)
+		lfsPaths = append(lfsPaths, v.RelativePath())
 	}
 
 	if _, err := sess.Delete(&LFSMetaObject{RepositoryID: repoID}); err != nil {
@@ -1691,12 +1787,17 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 
 	// Remove issue attachment files.
 	for i := range attachmentPaths {
-		removeAllWithNotice(x, "Delete issue attachment", attachmentPaths[i])
+		removeStorageWithNotice(x, storage.Attachments, "Delete issue attachment", attachmentPaths[i])
 	}
 
 	// Remove release attachment files.
 	for i := range releaseAttachments {
-		removeAllWithNotice(x, "Delete release attachment", releaseAttachments[i])
+		removeStorageWithNotice(x, storage.Attachments, "Delete release attachment", releaseAttachments[i])
+	}
+
+	// Remove orphaned LFS files.
+	for i := range lfsPaths {
+		removeStorageWithNotice(x, storage.LFS, "Delete orphaned LFS file", lfsPaths[i])
 	}
 
 	if len(repo.Avatar) > 0 {
@@ -0,0 +1,139 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// Forks can borrow the objects of the repository they are forked from through git alternates,
+// so they don't duplicate them on disk. The alternates hold the absolute path of the objects,
+// they are updated when the path of the repository changes and the forks are dissociated
+// before the repository is deleted.
+
+// objectsPath returns the path of the object database of the repository at repoPath
+func objectsPath(repoPath string) string {
+	return filepath.Join(repoPath, "objects")
+}
+
+func alternatesPath(repoPath string) string {
+	return filepath.Join(objectsPath(repoPath), "info", "alternates")
+}
+
+// ReadAlternates returns the object databases the repository at repoPath borrows objects from
+func ReadAlternates(repoPath string) ([]string, error) {
+	data, err := ioutil.ReadFile(alternatesPath(repoPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var alternates []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		alternates = append(alternates, line)
+	}
+	return alternates, nil
+}
+
+func writeAlternates(repoPath string, alternates []string) error {
+	if len(alternates) == 0 {
+		if err := os.Remove(alternatesPath(repoPath)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return ioutil.WriteFile(alternatesPath(repoPath), []byte(strings.Join(alternates, "\n")+"\n"), 0644)
+}
+
+// borrowsObjectsFrom returns whether the alternates contain the objects of the repository at repoPath
+func borrowsObjectsFrom(alternates []string, repoPath string) bool {
+	path := filepath.Clean(objectsPath(repoPath))
+	for _, alternate := range alternates {
+		if filepath.Clean(alternate) == path {
+			return true
+		}
+	}
+	return false
+}
+
+func getForks(e Engine, repo *Repository) ([]*Repository, error) {
+	forks := make([]*Repository, 0, 10)
+	return forks, e.Where("fork_id = ?", repo.ID).Find(&forks)
+}
+
+// relinkForks updates the alternates of the forks borrowing the objects of the repository,
+// after the repository has been moved from oldRepoPath to newRepoPath
+func relinkForks(e Engine, repo *Repository, oldRepoPath, newRepoPath string) error {
+	forks, err := getForks(e, repo)
+	if err != nil {
+		return fmt.Errorf("getForks: %v", err)
+	}
+
+	for _, fork := range forks {
+		forkPath := fork.RepoPath()
+		alternates, err := ReadAlternates(forkPath)
+		if err != nil {
+			return fmt.Errorf("ReadAlternates[%s]: %v", fork.FullName(), err)
+		}
+		if !borrowsObjectsFrom(alternates, oldRepoPath) {
+			continue
+		}
+		for i, alternate := range alternates {
+			if filepath.Clean(alternate) == filepath.Clean(objectsPath(oldRepoPath)) {
+				alternates[i] = objectsPath(newRepoPath)
+			}
+		}
+		if err := writeAlternates(forkPath, alternates); err != nil {
+			return fmt.Errorf("writeAlternates[%s]: %v", fork.FullName(), err)
+		}
+	}
+	return nil
+}
+
+// dissociateForks copies the objects the forks of the repository borrow from it into the forks,
+// so the repository can be deleted
+func dissociateForks(e Engine, repo *Repository) error {
+	forks, err := getForks(e, repo)
+	if err != nil {
+		return fmt.Errorf("getForks: %v", err)
+	}
+
+	repoPath := repo.RepoPath()
+	for _, fork := range forks {
+		forkPath := fork.RepoPath()
+		alternates, err := ReadAlternates(forkPath)
+		if err != nil {
+			return fmt.Errorf("ReadAlternates[%s]: %v", fork.FullName(), err)
+		}
+		if !borrowsObjectsFrom(alternates, repoPath) {
+			continue
+		}
+
+		// Like git clone --dissociate, all objects reachable in the fork are packed including the borrowed ones
+		if _, err := git.NewCommand("repack", "-a", "-d", "-q").
+			SetDescription(fmt.Sprintf("dissociateForks(git repack): %s from %s", fork.FullName(), repo.FullName())).
+			RunInDirTimeout(-1, forkPath); err != nil {
+			return fmt.Errorf("git repack [%s]: %v", fork.FullName(), err)
+		}
+		if err := writeAlternates(forkPath, nil); err != nil {
+			return fmt.Errorf("writeAlternates[%s]: %v", fork.FullName(), err)
+		}
+		log.Trace("Fork %s has been dissociated from %s", fork.FullName(), repo.FullName())
+	}
+	return nil
+}
@@ -98,6 +98,11 @@ func (repo *Repository) GetCollaborators(listOptions ListOptions) ([]*Collaborat
 	return repo.getCollaborators(x, listOptions)
 }
 
+// CountCollaborators returns the number of the collaborators of a repository
+func (repo *Repository) CountCollaborators() (int64, error) {
+	return x.Count(&Collaboration{RepoID: repo.ID})
+}
+
 func (repo *Repository) getCollaboration(e Engine, uid int64) (*Collaboration, error) {
 	collaboration := &Collaboration{
 		RepoID: repo.ID,
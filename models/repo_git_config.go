@@ -0,0 +1,198 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// maxGitConfigValueLength is the maximum length of the git config values of repositories
+const maxGitConfigValueLength = 255
+
+// RepoGitConfig is a git config value set by the admins of a repository in its bare repository
+type RepoGitConfig struct {
+	ID          int64              `xorm:"pk autoincr"`
+	RepoID      int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Name        string             `xorm:"UNIQUE(s) NOT NULL"`
+	Value       string             `xorm:"NOT NULL"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// AllowedGitConfigName returns the name of the git config key in [repository] GIT_CONFIG_ALLOWLIST
+// which matches name and whether there is one. Like git, the section and the key are case insensitive.
+func AllowedGitConfigName(name string) (string, bool) {
+	for _, allowed := range setting.Repository.GitConfigAllowlist {
+		allowed = strings.TrimSpace(allowed)
+		if len(allowed) > 0 && gitConfigNamesEqual(allowed, name) {
+			return allowed, true
+		}
+	}
+	return "", false
+}
+
+// gitConfigNamesEqual compares git config names, the subsection between the section and the key is case sensitive
+func gitConfigNamesEqual(a, b string) bool {
+	aFirst, aLast := strings.Index(a, "."), strings.LastIndex(a, ".")
+	bFirst, bLast := strings.Index(b, "."), strings.LastIndex(b, ".")
+	if aFirst < 0 || bFirst < 0 {
+		return strings.EqualFold(a, b)
+	}
+	return strings.EqualFold(a[:aFirst], b[:bFirst]) &&
+		a[aFirst:aLast] == b[bFirst:bLast] &&
+		strings.EqualFold(a[aLast:], b[bLast:])
+}
+
+// isValidGitConfigValue returns whether the value can be written to the config of a repository
+func isValidGitConfigValue(value string) bool {
+	return len(value) > 0 && len(value) <= maxGitConfigValueLength && !strings.ContainsAny(value, "\r\n\x00")
+}
+
+// GetRepoGitConfigs returns the git config values set for the repository sorted by name
+func GetRepoGitConfigs(repoID int64) ([]*RepoGitConfig, error) {
+	return getRepoGitConfigs(x, repoID)
+}
+
+func getRepoGitConfigs(e Engine, repoID int64) ([]*RepoGitConfig, error) {
+	configs := make([]*RepoGitConfig, 0, 5)
+	return configs, e.Where("repo_id = ?", repoID).Asc("name").Find(&configs)
+}
+
+// GitConfigValues returns the git config values set for the repository which are still allowed,
+// by their names in [repository] GIT_CONFIG_ALLOWLIST
+func (repo *Repository) GitConfigValues() (map[string]string, error) {
+	configs, err := GetRepoGitConfigs(repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(configs))
+	for _, config := range configs {
+		if allowed, ok := AllowedGitConfigName(config.Name); ok {
+			values[allowed] = config.Value
+		}
+	}
+	return values, nil
+}
+
+// UpdateRepoGitConfigs replaces the git config values set for the repository and writes them
+// to its bare repository. The names must be in [repository] GIT_CONFIG_ALLOWLIST, the keys
+// of the previous values which aren't given anymore are unset.
+func UpdateRepoGitConfigs(repo *Repository, values map[string]string) error {
+	configs := make([]*RepoGitConfig, 0, len(values))
+	for name, value := range values {
+		allowed, ok := AllowedGitConfigName(name)
+		if !ok || !isValidGitConfigValue(value) {
+			return ErrInvalidGitConfig{Name: name, Value: value}
+		}
+		configs = append(configs, &RepoGitConfig{RepoID: repo.ID, Name: allowed, Value: value})
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if err := updateRepoGitConfigs(sess, repo, configs); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+func updateRepoGitConfigs(e Engine, repo *Repository, configs []*RepoGitConfig) error {
+	oldConfigs, err := getRepoGitConfigs(e, repo.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := e.Delete(&RepoGitConfig{RepoID: repo.ID}); err != nil {
+		return err
+	}
+	if len(configs) > 0 {
+		if _, err := e.Insert(configs); err != nil {
+			return err
+		}
+	}
+
+	repoPath := repo.RepoPath()
+	for _, oldConfig := range oldConfigs {
+		kept := false
+		for _, config := range configs {
+			if gitConfigNamesEqual(oldConfig.Name, config.Name) {
+				kept = true
+				break
+			}
+		}
+		if !kept {
+			if err := unsetGitConfig(repoPath, oldConfig.Name); err != nil {
+				return err
+			}
+		}
+	}
+	for _, config := range configs {
+		if _, err := git.NewCommand("config", "--replace-all", config.Name, config.Value).RunInDir(repoPath); err != nil {
+			return fmt.Errorf("git config %s: %v", config.Name, err)
+		}
+	}
+	return nil
+}
+
+// unsetGitConfig removes the values of the git config key from the config of the repository
+func unsetGitConfig(repoPath, name string) error {
+	if _, err := git.NewCommand("config", "--unset-all", name).RunInDir(repoPath); err != nil {
+		// git config exits with 5 if the key isn't set
+		if strings.Contains(err.Error(), "exit status 5") {
+			return nil
+		}
+		return fmt.Errorf("git config --unset-all %s: %v", name, err)
+	}
+	return nil
+}
+
+// SyncRepoGitConfigs writes the git config values set for the repository which are still allowed
+// to its bare repository again and removes the others. It returns the names of the values which
+// differed from the ones of the bare repository or weren't allowed anymore.
+func SyncRepoGitConfigs(repo *Repository, fix bool) ([]string, error) {
+	configs, err := GetRepoGitConfigs(repo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	allowedConfigs := make([]*RepoGitConfig, 0, len(configs))
+	for _, config := range configs {
+		if _, ok := AllowedGitConfigName(config.Name); !ok {
+			changed = append(changed, config.Name)
+			continue
+		}
+		allowedConfigs = append(allowedConfigs, config)
+
+		value, err := git.NewCommand("config", "--get", config.Name).RunInDir(repo.RepoPath())
+		if err != nil || strings.TrimRight(value, "\n") != config.Value {
+			changed = append(changed, config.Name)
+		}
+	}
+	if len(changed) == 0 || !fix {
+		return changed, nil
+	}
+
+	for _, config := range allowedConfigs {
+		config.ID = 0
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+	if err := updateRepoGitConfigs(sess, repo, allowedConfigs); err != nil {
+		return nil, err
+	}
+	return changed, sess.Commit()
+}
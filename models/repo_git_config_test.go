@@ -0,0 +1,84 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getGitConfig(t *testing.T, repo *Repository, name string) string {
+	value, err := git.NewCommand("config", "--get", name).RunInDir(repo.RepoPath())
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(value)
+}
+
+func TestAllowedGitConfigName(t *testing.T) {
+	defer func(allowlist []string) {
+		setting.Repository.GitConfigAllowlist = allowlist
+	}(setting.Repository.GitConfigAllowlist)
+	setting.Repository.GitConfigAllowlist = []string{"core.bigFileThreshold", "remote.Origin.url"}
+
+	for name, expected := range map[string]string{
+		"core.bigFileThreshold": "core.bigFileThreshold",
+		"CORE.BIGFILETHRESHOLD": "core.bigFileThreshold",
+		"remote.Origin.URL":     "remote.Origin.url",
+		"remote.origin.url":     "",
+		"pack.window":           "",
+		"core":                  "",
+	} {
+		allowed, ok := AllowedGitConfigName(name)
+		assert.Equal(t, expected != "", ok, name)
+		assert.Equal(t, expected, allowed, name)
+	}
+}
+
+func TestUpdateRepoGitConfigs(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	defer func(allowlist []string) {
+		setting.Repository.GitConfigAllowlist = allowlist
+	}(setting.Repository.GitConfigAllowlist)
+	setting.Repository.GitConfigAllowlist = []string{"core.bigFileThreshold", "pack.window"}
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	assert.True(t, IsErrInvalidGitConfig(UpdateRepoGitConfigs(repo, map[string]string{"core.hooksPath": "/tmp"})))
+	assert.True(t, IsErrInvalidGitConfig(UpdateRepoGitConfigs(repo, map[string]string{"pack.window": "10\n[core]"})))
+	assert.Empty(t, getGitConfig(t, repo, "core.hooksPath"))
+
+	assert.NoError(t, UpdateRepoGitConfigs(repo, map[string]string{"core.bigfilethreshold": "1m", "pack.window": "5"}))
+	assert.Equal(t, "1m", getGitConfig(t, repo, "core.bigFileThreshold"))
+	assert.Equal(t, "5", getGitConfig(t, repo, "pack.window"))
+	values, err := repo.GitConfigValues()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"core.bigFileThreshold": "1m", "pack.window": "5"}, values)
+
+	assert.NoError(t, UpdateRepoGitConfigs(repo, map[string]string{"pack.window": "20"}))
+	assert.Empty(t, getGitConfig(t, repo, "core.bigFileThreshold"))
+	assert.Equal(t, "20", getGitConfig(t, repo, "pack.window"))
+	configs, err := GetRepoGitConfigs(repo.ID)
+	assert.NoError(t, err)
+	assert.Len(t, configs, 1)
+
+	// Values of keys which aren't allowed anymore are removed by the sync
+	setting.Repository.GitConfigAllowlist = []string{"core.bigFileThreshold"}
+	changed, err := SyncRepoGitConfigs(repo, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"pack.window"}, changed)
+	assert.Equal(t, "20", getGitConfig(t, repo, "pack.window"))
+
+	changed, err = SyncRepoGitConfigs(repo, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"pack.window"}, changed)
+	assert.Empty(t, getGitConfig(t, repo, "pack.window"))
+	AssertNotExistsBean(t, &RepoGitConfig{RepoID: repo.ID})
+}
@@ -233,3 +233,38 @@ func (repo *Repository) SignCRUDAction(u *User, tmpBasePath, parentCommit string
 	}
 	return true, signingKey, nil
 }
+
+// SignRelease determines if we should sign the tag created for a release of this repository
+func (repo *Repository) SignRelease(u *User) (bool, string, error) {
+	rules := signingModeFromStrings(setting.Repository.Signing.Releases)
+	signingKey := signingKey(repo.RepoPath())
+	if signingKey == "" {
+		return false, "", &ErrWontSign{noKey}
+	}
+
+	for _, rule := range rules {
+		switch rule {
+		case never:
+			return false, "", &ErrWontSign{never}
+		case always:
+			break
+		case pubkey:
+			keys, err := ListGPGKeys(u.ID, ListOptions{})
+			if err != nil {
+				return false, "", err
+			}
+			if len(keys) == 0 {
+				return false, "", &ErrWontSign{pubkey}
+			}
+		case twofa:
+			twofaModel, err := GetTwoFactorByUID(u.ID)
+			if err != nil && !IsErrTwoFactorNotEnrolled(err) {
+				return false, "", err
+			}
+			if twofaModel == nil {
+				return false, "", &ErrWontSign{twofa}
+			}
+		}
+	}
+	return true, signingKey, nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// RepoTransfer is a request to transfer a repository, it has to be accepted by the new owner
+type RepoTransfer struct {
+	ID          int64 `xorm:"pk autoincr"`
+	DoerID      int64
+	Doer        *User `xorm:"-"`
+	RecipientID int64
+	Recipient   *User `xorm:"-"`
+	RepoID      int64 `xorm:"UNIQUE"`
+	TeamIDs     []int64
+	Teams       []*Team `xorm:"-"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL updated"`
+}
+
+// LoadAttributes loads the doer, the recipient and the teams of the transfer
+func (r *RepoTransfer) LoadAttributes() error {
+	var err error
+	if r.Doer == nil {
+		if r.Doer, err = GetUserByID(r.DoerID); err != nil {
+			if !IsErrUserNotExist(err) {
+				return err
+			}
+			r.Doer = NewGhostUser()
+		}
+	}
+	if r.Recipient == nil {
+		if r.Recipient, err = GetUserByID(r.RecipientID); err != nil {
+			return err
+		}
+	}
+	if r.Teams == nil && len(r.TeamIDs) > 0 {
+		r.Teams = make([]*Team, 0, len(r.TeamIDs))
+		if err = x.In("id", r.TeamIDs).Find(&r.Teams); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CanUserAcceptTransfer returns whether the user can accept or reject the transfer: the
+// new owner, an owner of the new organization or a site admin
+func (r *RepoTransfer) CanUserAcceptTransfer(u *User) bool {
+	if u == nil {
+		return false
+	}
+	if u.IsAdmin || u.ID == r.RecipientID {
+		return true
+	}
+	if err := r.LoadAttributes(); err != nil {
+		return false
+	}
+	if !r.Recipient.IsOrganization() {
+		return false
+	}
+	isOwner, err := r.Recipient.IsOwnedBy(u.ID)
+	return err == nil && isOwner
+}
+
+func getPendingRepositoryTransfer(e Engine, repoID int64) (*RepoTransfer, error) {
+	transfer := new(RepoTransfer)
+	has, err := e.Where("repo_id = ?", repoID).Get(transfer)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrNoPendingRepoTransfer{RepoID: repoID}
+	}
+	return transfer, nil
+}
+
+// GetPendingRepositoryTransfer returns the pending transfer of a repository
+func GetPendingRepositoryTransfer(repo *Repository) (*RepoTransfer, error) {
+	return getPendingRepositoryTransfer(x, repo.ID)
+}
+
+// CreatePendingRepositoryTransfer creates a request to transfer the repository to the new
+// owner, it fails if the repository already has a pending transfer
+func CreatePendingRepositoryTransfer(doer, newOwner *User, repo *Repository, teams []*Team) error {
+	has, err := IsRepositoryExist(newOwner, repo.Name)
+	if err != nil {
+		return fmt.Errorf("IsRepositoryExist: %v", err)
+	} else if has {
+		return ErrRepoAlreadyExist{newOwner.Name, repo.Name}
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err = getPendingRepositoryTransfer(sess, repo.ID); err == nil {
+		return ErrRepoTransferInProgress{repo.OwnerName, repo.Name}
+	} else if !IsErrNoPendingRepoTransfer(err) {
+		return err
+	}
+
+	transfer := &RepoTransfer{
+		DoerID:      doer.ID,
+		Doer:        doer,
+		RecipientID: newOwner.ID,
+		Recipient:   newOwner,
+		RepoID:      repo.ID,
+		TeamIDs:     make([]int64, 0, len(teams)),
+		Teams:       teams,
+	}
+	for _, team := range teams {
+		transfer.TeamIDs = append(transfer.TeamIDs, team.ID)
+	}
+	if _, err = sess.Insert(transfer); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+func deleteRepositoryTransfer(e Engine, repoID int64) error {
+	_, err := e.Where("repo_id = ?", repoID).Delete(new(RepoTransfer))
+	return err
+}
+
+// CancelRepositoryTransfer removes the pending transfer of a repository
+func CancelRepositoryTransfer(repo *Repository) error {
+	return deleteRepositoryTransfer(x, repo.ID)
+}
@@ -0,0 +1,43 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepositoryTransfer(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	_, err := GetPendingRepositoryTransfer(repo)
+	assert.True(t, IsErrNoPendingRepoTransfer(err))
+
+	org := AssertExistsAndLoadBean(t, &User{ID: 3}).(*User)
+	assert.NoError(t, CreatePendingRepositoryTransfer(doer, org, repo, nil))
+
+	transfer, err := GetPendingRepositoryTransfer(repo)
+	assert.NoError(t, err)
+	assert.NoError(t, transfer.LoadAttributes())
+	assert.Equal(t, "user3", transfer.Recipient.Name)
+
+	// the owners of the organization and the site admins can accept the transfer
+	assert.True(t, transfer.CanUserAcceptTransfer(doer))
+	assert.True(t, transfer.CanUserAcceptTransfer(AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)))
+	assert.False(t, transfer.CanUserAcceptTransfer(AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)))
+	assert.False(t, transfer.CanUserAcceptTransfer(nil))
+
+	user5 := AssertExistsAndLoadBean(t, &User{ID: 5}).(*User)
+	err = CreatePendingRepositoryTransfer(doer, user5, repo, nil)
+	assert.True(t, IsErrRepoTransferInProgress(err))
+
+	assert.NoError(t, CancelRepositoryTransfer(repo))
+	AssertNotExistsBean(t, &RepoTransfer{RepoID: repo.ID})
+
+}
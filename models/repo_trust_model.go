@@ -0,0 +1,123 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// TrustModelType is the way the verified signatures of the commits of a repository are trusted
+type TrustModelType int
+
+// The trust models of repositories
+const (
+	// DefaultTrustModel uses the trust model of the instance
+	DefaultTrustModel TrustModelType = iota
+	// CollaboratorTrustModel trusts the keys of collaborators of the repository and of the instance
+	CollaboratorTrustModel
+	// CommitterTrustModel trusts the keys of the committers and of the instance
+	CommitterTrustModel
+	// AnyKeyTrustModel trusts any key known to the instance
+	AnyKeyTrustModel
+	// TrustedKeysTrustModel only trusts the keys listed as trusted by the repository or the instance
+	TrustedKeysTrustModel
+)
+
+var trustModelNames = map[TrustModelType]string{
+	DefaultTrustModel:      "default",
+	CollaboratorTrustModel: "collaborator",
+	CommitterTrustModel:    "committer",
+	AnyKeyTrustModel:       "any",
+	TrustedKeysTrustModel:  "trustedkeys",
+}
+
+// TrustModels are the trust models repositories can choose
+var TrustModels = []TrustModelType{
+	DefaultTrustModel,
+	CollaboratorTrustModel,
+	CommitterTrustModel,
+	AnyKeyTrustModel,
+	TrustedKeysTrustModel,
+}
+
+func (t TrustModelType) String() string {
+	if name, ok := trustModelNames[t]; ok {
+		return name
+	}
+	return trustModelNames[DefaultTrustModel]
+}
+
+// parseTrustModel returns the trust model of the name and whether it is known
+func parseTrustModel(name string) (TrustModelType, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for t, n := range trustModelNames {
+		if n == name {
+			return t, true
+		}
+	}
+	return DefaultTrustModel, false
+}
+
+// ToTrustModel returns the trust model of the name, the default trust model if it is unknown
+func ToTrustModel(name string) TrustModelType {
+	t, _ := parseTrustModel(name)
+	return t
+}
+
+// IsValidTrustModel returns whether the name is the one of a trust model
+func IsValidTrustModel(name string) bool {
+	_, ok := parseTrustModel(name)
+	return ok
+}
+
+// DefaultTrustModelOfInstance returns the trust model of the repositories using the default one
+func DefaultTrustModelOfInstance() TrustModelType {
+	if t := ToTrustModel(setting.Repository.Signing.DefaultTrustModel); t != DefaultTrustModel {
+		return t
+	}
+	return CollaboratorTrustModel
+}
+
+// GetTrustModel returns the trust model in effect for the repository
+func (repo *Repository) GetTrustModel() TrustModelType {
+	if repo.TrustModel == DefaultTrustModel {
+		return DefaultTrustModelOfInstance()
+	}
+	return repo.TrustModel
+}
+
+// parseTrustedKeys returns the key IDs or fingerprints of a list separated by commas or whitespace
+func parseTrustedKeys(keys string) []string {
+	return strings.FieldsFunc(strings.ToUpper(keys), func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+}
+
+// GetTrustedKeys returns the trusted key IDs or fingerprints of the repository, including the ones of the instance
+func (repo *Repository) GetTrustedKeys() []string {
+	keys := parseTrustedKeys(repo.TrustedKeys)
+	for _, key := range setting.Repository.Signing.TrustedKeys {
+		keys = append(keys, parseTrustedKeys(key)...)
+	}
+	return keys
+}
+
+// IsTrustedKey returns whether the key or its primary key is trusted by the repository.
+// Trusted keys can be given by their long key ID or their fingerprint, which ends with the key ID.
+func (repo *Repository) IsTrustedKey(key *GPGKey) bool {
+	if key == nil {
+		return false
+	}
+	for _, trusted := range repo.GetTrustedKeys() {
+		for _, keyID := range []string{key.KeyID, key.PrimaryKeyID} {
+			if len(keyID) > 0 && strings.HasSuffix(trusted, strings.ToUpper(keyID)) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToTrustModel(t *testing.T) {
+	for _, trustModel := range TrustModels {
+		assert.Equal(t, trustModel, ToTrustModel(trustModel.String()))
+		assert.True(t, IsValidTrustModel(trustModel.String()))
+	}
+	assert.Equal(t, TrustedKeysTrustModel, ToTrustModel(" TrustedKeys "))
+	assert.Equal(t, DefaultTrustModel, ToTrustModel("unknown"))
+	assert.False(t, IsValidTrustModel("unknown"))
+}
+
+func TestRepository_GetTrustModel(t *testing.T) {
+	defer func(trustModel string) { setting.Repository.Signing.DefaultTrustModel = trustModel }(setting.Repository.Signing.DefaultTrustModel)
+
+	repo := &Repository{}
+	setting.Repository.Signing.DefaultTrustModel = "committer"
+	assert.Equal(t, CommitterTrustModel, repo.GetTrustModel())
+	setting.Repository.Signing.DefaultTrustModel = "unknown"
+	assert.Equal(t, CollaboratorTrustModel, repo.GetTrustModel())
+
+	repo.TrustModel = AnyKeyTrustModel
+	assert.Equal(t, AnyKeyTrustModel, repo.GetTrustModel())
+}
+
+func TestCalculateTrustStatus(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	defer func(trustedKeys []string) { setting.Repository.Signing.TrustedKeys = trustedKeys }(setting.Repository.Signing.TrustedKeys)
+	setting.Repository.Signing.TrustedKeys = []string{"1111222233334444"}
+
+	// user2 owns repo1, user5 isn't a collaborator
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	repo.TrustedKeys = "0000111122223333444455556666777788889999,\naaaabbbbccccdddd"
+	user2 := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	user5 := AssertExistsAndLoadBean(t, &User{ID: 5}).(*User)
+	instance := &User{Name: setting.AppName}
+
+	trustStatus := func(trustModel TrustModelType, signer, committer *User, key *GPGKey) string {
+		repo.TrustModel = trustModel
+		verification := &CommitVerification{
+			Verified:       true,
+			SigningUser:    signer,
+			CommittingUser: committer,
+			SigningKey:     key,
+		}
+		assert.NoError(t, CalculateTrustStatus(verification, repo, nil))
+		assert.Equal(t, trustModel.String(), verification.TrustModel)
+		return verification.TrustStatus
+	}
+	key := &GPGKey{KeyID: "0123456789ABCDEF"}
+
+	assert.Equal(t, "trusted", trustStatus(CollaboratorTrustModel, user2, user5, key))
+	assert.Equal(t, "untrusted", trustStatus(CollaboratorTrustModel, user5, user5, key))
+	assert.Equal(t, "unmatched", trustStatus(CollaboratorTrustModel, user5, user2, key))
+	assert.Equal(t, "trusted", trustStatus(CollaboratorTrustModel, instance, user5, key))
+
+	assert.Equal(t, "trusted", trustStatus(CommitterTrustModel, user5, user5, key))
+	assert.Equal(t, "unmatched", trustStatus(CommitterTrustModel, user2, user5, key))
+	assert.Equal(t, "trusted", trustStatus(CommitterTrustModel, instance, user5, key))
+
+	assert.Equal(t, "trusted", trustStatus(AnyKeyTrustModel, user5, user2, key))
+
+	// Keys are trusted by their fingerprint or key ID, subkeys by the ones of their primary key
+	assert.Equal(t, "untrusted", trustStatus(TrustedKeysTrustModel, user2, user2, key))
+	assert.Equal(t, "trusted", trustStatus(TrustedKeysTrustModel, user5, user2, &GPGKey{KeyID: "6666777788889999"}))
+	assert.Equal(t, "trusted", trustStatus(TrustedKeysTrustModel, user5, user5, &GPGKey{KeyID: "AAAABBBBCCCCDDDD"}))
+	assert.Equal(t, "trusted", trustStatus(TrustedKeysTrustModel, user2, user2, &GPGKey{KeyID: "0123456789ABCDEF", PrimaryKeyID: "1111222233334444"}))
+	assert.Equal(t, "untrusted", trustStatus(TrustedKeysTrustModel, instance, user2, nil))
+
+	// Unverified signatures are never trusted
+	repo.TrustModel = AnyKeyTrustModel
+	verification := &CommitVerification{Verified: false}
+	assert.NoError(t, CalculateTrustStatus(verification, repo, nil))
+	assert.Equal(t, "any", verification.TrustModel)
+	assert.Empty(t, verification.TrustStatus)
+}
@@ -6,9 +6,12 @@ package models
 
 import (
 	"encoding/json"
+	"strings"
 
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/timeutil"
 
+	"github.com/gobwas/glob"
 	"github.com/unknwon/com"
 	"xorm.io/xorm"
 	"xorm.io/xorm/convert"
@@ -69,6 +72,63 @@ func (cfg *ExternalTrackerConfig) ToDB() ([]byte, error) {
 	return json.Marshal(cfg)
 }
 
+// ReleasesConfig describes releases config
+type ReleasesConfig struct {
+	// ProtectPublished prevents published releases and their tags from being changed by non admins
+	ProtectPublished bool
+	// ProtectedTagPatterns limits the protection to the tags matching one of these ';' separated globs
+	ProtectedTagPatterns string
+	// AutoReleaseTagPatterns are ';' separated globs, a release is created for pushed tags matching one of them
+	AutoReleaseTagPatterns string
+}
+
+// FromDB fills up a ReleasesConfig from serialized format.
+func (cfg *ReleasesConfig) FromDB(bs []byte) error {
+	return json.Unmarshal(bs, &cfg)
+}
+
+// ToDB exports a ReleasesConfig to a serialized format.
+func (cfg *ReleasesConfig) ToDB() ([]byte, error) {
+	return json.Marshal(cfg)
+}
+
+// IsTagProtected returns true if published releases of the given tag are protected
+func (cfg *ReleasesConfig) IsTagProtected(tagName string) bool {
+	if !cfg.ProtectPublished {
+		return false
+	}
+
+	matched, hasPattern := matchTagPatterns(cfg.ProtectedTagPatterns, tagName)
+	// Without patterns all published releases are protected
+	return matched || !hasPattern
+}
+
+// IsAutoReleaseTag returns true if a release should be created when the given tag is pushed
+func (cfg *ReleasesConfig) IsAutoReleaseTag(tagName string) bool {
+	matched, _ := matchTagPatterns(cfg.AutoReleaseTagPatterns, tagName)
+	return matched
+}
+
+// matchTagPatterns returns whether the tag matches one of the ';' separated globs and whether there is any glob
+func matchTagPatterns(patterns, tagName string) (matched, hasPattern bool) {
+	for _, expr := range strings.Split(patterns, ";") {
+		expr = strings.TrimSpace(expr)
+		if expr == "" {
+			continue
+		}
+		hasPattern = true
+		g, err := glob.Compile(expr)
+		if err != nil {
+			log.Info("Invalid glob expresion '%s' (skipped): %v", expr, err)
+			continue
+		}
+		if g.Match(tagName) {
+			return true, true
+		}
+	}
+	return false, hasPattern
+}
+
 // IssuesConfig describes issues config
 type IssuesConfig struct {
 	EnableTimetracker                bool
@@ -93,6 +153,9 @@ type PullRequestsConfig struct {
 	AllowRebase               bool
 	AllowRebaseMerge          bool
 	AllowSquash               bool
+	AllowRebaseFastForward    bool
+	AllowFastForwardOnly      bool
+	SquashMessageTemplate     string
 }
 
 // FromDB fills up a PullRequestsConfig from serialized format.
@@ -110,7 +173,9 @@ func (cfg *PullRequestsConfig) IsMergeStyleAllowed(mergeStyle MergeStyle) bool {
 	return mergeStyle == MergeStyleMerge && cfg.AllowMerge ||
 		mergeStyle == MergeStyleRebase && cfg.AllowRebase ||
 		mergeStyle == MergeStyleRebaseMerge && cfg.AllowRebaseMerge ||
-		mergeStyle == MergeStyleSquash && cfg.AllowSquash
+		mergeStyle == MergeStyleSquash && cfg.AllowSquash ||
+		mergeStyle == MergeStyleRebaseFastForward && cfg.AllowRebaseFastForward ||
+		mergeStyle == MergeStyleFastForwardOnly && cfg.AllowFastForwardOnly
 }
 
 // BeforeSet is invoked from XORM before setting the value of a field of this object.
@@ -118,8 +183,10 @@ func (r *RepoUnit) BeforeSet(colName string, val xorm.Cell) {
 	switch colName {
 	case "type":
 		switch UnitType(Cell2Int64(val)) {
-		case UnitTypeCode, UnitTypeReleases, UnitTypeWiki:
+		case UnitTypeCode, UnitTypeWiki:
 			r.Config = new(UnitConfig)
+		case UnitTypeReleases:
+			r.Config = new(ReleasesConfig)
 		case UnitTypeExternalWiki:
 			r.Config = new(ExternalWikiConfig)
 		case UnitTypeExternalTracker:
@@ -150,8 +217,8 @@ func (r *RepoUnit) PullRequestsConfig() *PullRequestsConfig {
 }
 
 // ReleasesConfig returns config for UnitTypeReleases
-func (r *RepoUnit) ReleasesConfig() *UnitConfig {
-	return r.Config.(*UnitConfig)
+func (r *RepoUnit) ReleasesConfig() *ReleasesConfig {
+	return r.Config.(*ReleasesConfig)
 }
 
 // ExternalWikiConfig returns config for UnitTypeExternalWiki
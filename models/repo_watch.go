@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
 )
 
 // RepoWatchMode specifies what kind of watch the user has on a repository
@@ -26,10 +27,11 @@ const (
 
 // Watch is connection request for receiving repository notification.
 type Watch struct {
-	ID     int64         `xorm:"pk autoincr"`
-	UserID int64         `xorm:"UNIQUE(watch)"`
-	RepoID int64         `xorm:"UNIQUE(watch)"`
-	Mode   RepoWatchMode `xorm:"SMALLINT NOT NULL DEFAULT 1"`
+	ID          int64              `xorm:"pk autoincr"`
+	UserID      int64              `xorm:"UNIQUE(watch)"`
+	RepoID      int64              `xorm:"UNIQUE(watch)"`
+	Mode        RepoWatchMode      `xorm:"SMALLINT NOT NULL DEFAULT 1"`
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
 }
 
 // getWatch gets what kind of subscription a user has on a given repository; returns dummy record if none found
@@ -87,6 +89,12 @@ func watchRepoMode(e Engine, watch Watch, mode RepoWatchMode) (err error) {
 		if _, err := e.ID(watch.ID).AllCols().Update(watch); err != nil {
 			return err
 		}
+		if repodiff > 0 {
+			// The user starts watching, the record was kept to not auto-watch
+			if _, err := e.Exec("UPDATE `watch` SET created_unix = ? WHERE id = ?", timeutil.TimeStampNow(), watch.ID); err != nil {
+				return err
+			}
+		}
 	} else if _, err = e.Delete(Watch{ID: watch.ID}); err != nil {
 		return err
 	}
@@ -172,6 +180,50 @@ func (repo *Repository) GetWatchers(opts ListOptions) ([]*User, error) {
 	return users, sess.Find(&users)
 }
 
+// Watcher is a user who watches a repository, with the time it started.
+// The time is unknown for the watches older than its recording.
+type Watcher struct {
+	User        *User
+	WatchedUnix timeutil.TimeStamp
+}
+
+// GetWatchersByDate returns the users watching the repo with the time they started, sorted
+// by it.
+func (repo *Repository) GetWatchersByDate(opts ListOptions, newestFirst bool) ([]*Watcher, error) {
+	sess := x.Where("repo_id = ?", repo.ID).
+		And("mode<>?", RepoWatchModeDont)
+	if newestFirst {
+		sess.Desc("created_unix", "id")
+	} else {
+		sess.Asc("created_unix", "id")
+	}
+	if opts.Page > 0 {
+		sess = opts.setSessionPagination(sess)
+	}
+
+	watches := make([]*Watch, 0, opts.PageSize)
+	if err := sess.Find(&watches); err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]int64, len(watches))
+	for i, watch := range watches {
+		userIDs[i] = watch.UserID
+	}
+	users, err := getUsersMapByIDs(x, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	watchers := make([]*Watcher, 0, len(watches))
+	for _, watch := range watches {
+		if user, ok := users[watch.UserID]; ok {
+			watchers = append(watchers, &Watcher{User: user, WatchedUnix: watch.CreatedUnix})
+		}
+	}
+	return watchers, nil
+}
+
 func notifyWatchers(e Engine, actions ...*Action) error {
 	var watchers []*Watch
 	var repo *Repository
@@ -179,6 +231,7 @@ func notifyWatchers(e Engine, actions ...*Action) error {
 	var permCode []bool
 	var permIssue []bool
 	var permPR []bool
+	var permRelease []bool
 
 	for _, act := range actions {
 		repoChanged := repo == nil || repo.ID != act.RepoID
@@ -222,12 +275,14 @@ func notifyWatchers(e Engine, actions ...*Action) error {
 			permCode = make([]bool, len(watchers))
 			permIssue = make([]bool, len(watchers))
 			permPR = make([]bool, len(watchers))
+			permRelease = make([]bool, len(watchers))
 			for i, watcher := range watchers {
 				user, err := getUserByID(e, watcher.UserID)
 				if err != nil {
 					permCode[i] = false
 					permIssue[i] = false
 					permPR[i] = false
+					permRelease[i] = false
 					continue
 				}
 				perm, err := getUserRepoPermission(e, repo, user)
@@ -235,11 +290,13 @@ func notifyWatchers(e Engine, actions ...*Action) error {
 					permCode[i] = false
 					permIssue[i] = false
 					permPR[i] = false
+					permRelease[i] = false
 					continue
 				}
 				permCode[i] = perm.CanRead(UnitTypeCode)
 				permIssue[i] = perm.CanRead(UnitTypeIssues)
 				permPR[i] = perm.CanRead(UnitTypePullRequests)
+				permRelease[i] = perm.CanRead(UnitTypeReleases)
 			}
 		}
 
@@ -264,6 +321,10 @@ func notifyWatchers(e Engine, actions ...*Action) error {
 				if !permPR[i] {
 					continue
 				}
+			case ActionCommentRelease, ActionPublishRelease:
+				if !permRelease[i] {
+					continue
+				}
 			}
 
 			if _, err = e.InsertOne(act); err != nil {
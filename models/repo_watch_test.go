@@ -72,6 +72,33 @@ func TestRepository_GetWatchers(t *testing.T) {
 	assert.Len(t, watchers, 0)
 }
 
+func TestRepository_GetWatchersByDate(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	userIDs := func(watchers []*Watcher) []int64 {
+		ids := make([]int64, len(watchers))
+		for i, watcher := range watchers {
+			ids[i] = watcher.User.ID
+		}
+		return ids
+	}
+
+	watchers, err := repo.GetWatchersByDate(ListOptions{}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 9, 4, 11}, userIDs(watchers))
+	assert.EqualValues(t, 946684800, watchers[0].WatchedUnix)
+
+	watchers, err = repo.GetWatchersByDate(ListOptions{Page: 1, PageSize: 2}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{11, 4}, userIDs(watchers))
+
+	// user8 didn't want to watch, the time it starts watching is recorded
+	assert.NoError(t, WatchRepo(8, repo.ID, true))
+	watch := AssertExistsAndLoadBean(t, &Watch{UserID: 8, RepoID: repo.ID}).(*Watch)
+	assert.True(t, watch.CreatedUnix > 946684830)
+}
+
 func TestNotifyWatchers(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 
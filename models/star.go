@@ -4,11 +4,16 @@
 
 package models
 
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
 // Star represents a starred repo by an user.
 type Star struct {
-	ID     int64 `xorm:"pk autoincr"`
-	UID    int64 `xorm:"UNIQUE(s)"`
-	RepoID int64 `xorm:"UNIQUE(s)"`
+	ID          int64              `xorm:"pk autoincr"`
+	UID         int64              `xorm:"UNIQUE(s)"`
+	RepoID      int64              `xorm:"UNIQUE(s)"`
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
 }
 
 // StarRepo or unstar repository.
@@ -39,7 +44,7 @@ func StarRepo(userID, repoID int64, star bool) error {
 			return nil
 		}
 
-		if _, err := sess.Delete(&Star{0, userID, repoID}); err != nil {
+		if _, err := sess.Delete(&Star{UID: userID, RepoID: repoID}); err != nil {
 			return err
 		}
 		if _, err := sess.Exec("UPDATE `repository` SET num_stars = num_stars - 1 WHERE id = ?", repoID); err != nil {
@@ -59,7 +64,7 @@ func IsStaring(userID, repoID int64) bool {
 }
 
 func isStaring(e Engine, userID, repoID int64) bool {
-	has, _ := e.Get(&Star{0, userID, repoID})
+	has, _ := e.Get(&Star{UID: userID, RepoID: repoID})
 	return has
 }
 
@@ -78,6 +83,49 @@ func (repo *Repository) GetStargazers(opts ListOptions) ([]*User, error) {
 	return users, sess.Find(&users)
 }
 
+// Stargazer is a user who starred a repository, with the time it did.
+// The time is unknown for the stars older than its recording.
+type Stargazer struct {
+	User        *User
+	StarredUnix timeutil.TimeStamp
+}
+
+// GetStargazersByDate returns the users that starred the repo with the time they did,
+// sorted by it.
+func (repo *Repository) GetStargazersByDate(opts ListOptions, newestFirst bool) ([]*Stargazer, error) {
+	sess := x.Where("repo_id = ?", repo.ID)
+	if newestFirst {
+		sess.Desc("created_unix", "id")
+	} else {
+		sess.Asc("created_unix", "id")
+	}
+	if opts.Page > 0 {
+		sess = opts.setSessionPagination(sess)
+	}
+
+	stars := make([]*Star, 0, opts.PageSize)
+	if err := sess.Find(&stars); err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]int64, len(stars))
+	for i, star := range stars {
+		userIDs[i] = star.UID
+	}
+	users, err := getUsersMapByIDs(x, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	stargazers := make([]*Stargazer, 0, len(stars))
+	for _, star := range stars {
+		if user, ok := users[star.UID]; ok {
+			stargazers = append(stargazers, &Stargazer{User: user, StarredUnix: star.CreatedUnix})
+		}
+	}
+	return stargazers, nil
+}
+
 // GetStarredRepos returns the repos the user starred.
 func (u *User) GetStarredRepos(private bool, page, pageSize int, orderBy string) (repos RepositoryList, err error) {
 	if len(orderBy) == 0 {
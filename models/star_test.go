@@ -49,6 +49,28 @@ func TestRepository_GetStargazers2(t *testing.T) {
 	assert.Len(t, gazers, 0)
 }
 
+func TestRepository_GetStargazersByDate(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, StarRepo(1, 4, true))
+	AssertExistsAndLoadBean(t, &Star{UID: 1, RepoID: 4}, "created_unix > 946684810")
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 4}).(*Repository)
+	gazers, err := repo.GetStargazersByDate(ListOptions{}, false)
+	assert.NoError(t, err)
+	if assert.Len(t, gazers, 2) {
+		assert.EqualValues(t, 2, gazers[0].User.ID)
+		assert.EqualValues(t, 946684810, gazers[0].StarredUnix)
+		assert.EqualValues(t, 1, gazers[1].User.ID)
+	}
+
+	gazers, err = repo.GetStargazersByDate(ListOptions{Page: 1, PageSize: 1}, true)
+	assert.NoError(t, err)
+	if assert.Len(t, gazers, 1) {
+		assert.EqualValues(t, 1, gazers[0].User.ID)
+	}
+}
+
 func TestUser_GetStarredRepos(t *testing.T) {
 	// user who has starred repos
 	assert.NoError(t, PrepareTestDatabase())
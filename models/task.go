@@ -146,6 +146,37 @@ func GetMigratingTask(repoID int64) (*Task, error) {
 	return &task, nil
 }
 
+// GetRepositoryTask returns the task of the given type by its id and the repo's id
+func GetRepositoryTask(repoID, id int64, tp structs.TaskType) (*Task, error) {
+	var task = Task{
+		ID:     id,
+		RepoID: repoID,
+		Type:   tp,
+	}
+	has, err := x.Get(&task)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrTaskDoesNotExist{id, repoID, tp}
+	}
+	return &task, nil
+}
+
+// GetLatestRepositoryTask returns the latest task of the given type of the repo
+func GetLatestRepositoryTask(repoID int64, tp structs.TaskType) (*Task, error) {
+	var task = Task{
+		RepoID: repoID,
+		Type:   tp,
+	}
+	has, err := x.Desc("id").Get(&task)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrTaskDoesNotExist{0, repoID, tp}
+	}
+	return &task, nil
+}
+
 // FindTaskOptions find all tasks
 type FindTaskOptions struct {
 	Status int
@@ -167,6 +198,31 @@ func FindTasks(opts FindTaskOptions) ([]*Task, error) {
 	return tasks, err
 }
 
+// FindLatestRepositoryTasks returns the page of the latest tasks of the given type of every repository,
+// the ones with the given status only if it isn't negative, with their total number.
+func FindLatestRepositoryTasks(tp structs.TaskType, status int, listOptions ListOptions) ([]*Task, int64, error) {
+	cond := builder.In("id", builder.Select("MAX(id)").From("task").
+		Where(builder.Eq{"type": tp}.And(builder.Gt{"repo_id": 0})).GroupBy("repo_id"))
+	if status >= 0 {
+		cond = cond.And(builder.Eq{"status": status})
+	}
+
+	count, err := x.Where(cond).Count(new(Task))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var tasks = make([]*Task, 0, 10)
+	sess := x.Where(cond).Desc("id")
+	if listOptions.Page != 0 {
+		sess = listOptions.setSessionPagination(sess)
+	}
+	if err := sess.Find(&tasks); err != nil {
+		return nil, 0, err
+	}
+	return tasks, count, nil
+}
+
 // CreateTask creates a task on database
 func CreateTask(task *Task) error {
 	return createTask(x, task)
@@ -25,6 +25,7 @@ type AccessToken struct {
 	TokenHash      string `xorm:"UNIQUE"` // sha256 of token
 	TokenSalt      string
 	TokenLastEight string `xorm:"token_last_eight"`
+	Scope          AccessTokenScope
 
 	CreatedUnix       timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix       timeutil.TimeStamp `xorm:"INDEX updated"`
@@ -38,8 +39,11 @@ func (t *AccessToken) AfterLoad() {
 	t.HasRecentActivity = t.UpdatedUnix.AddDuration(7*24*time.Hour) > timeutil.TimeStampNow()
 }
 
-// NewAccessToken creates new access token.
+// NewAccessToken creates new access token, it gets all the scopes if it has none.
 func NewAccessToken(t *AccessToken) error {
+	if t.Scope == "" {
+		t.Scope = AccessTokenScopeAll
+	}
 	salt, err := generate.GetRandomString(10)
 	if err != nil {
 		return err
@@ -0,0 +1,98 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strings"
+)
+
+// AccessTokenScope is the comma separated list of the scopes granted to an access token
+type AccessTokenScope string
+
+// Scopes of the access tokens
+const (
+	AccessTokenScopeAll        AccessTokenScope = "all"
+	AccessTokenScopeRepoRead   AccessTokenScope = "repo:read"
+	AccessTokenScopeRepoWrite  AccessTokenScope = "repo:write"
+	AccessTokenScopeIssueWrite AccessTokenScope = "issue:write"
+	AccessTokenScopeUser       AccessTokenScope = "user"
+	AccessTokenScopeAdmin      AccessTokenScope = "admin"
+)
+
+// AccessTokenScopes are the scopes which can be granted to an access token
+var AccessTokenScopes = []AccessTokenScope{
+	AccessTokenScopeAll,
+	AccessTokenScopeRepoRead,
+	AccessTokenScopeRepoWrite,
+	AccessTokenScopeIssueWrite,
+	AccessTokenScopeUser,
+	AccessTokenScopeAdmin,
+}
+
+// accessTokenScopeImplies maps the scopes to the other scopes they grant
+var accessTokenScopeImplies = map[AccessTokenScope][]AccessTokenScope{
+	AccessTokenScopeAll:        {AccessTokenScopeRepoWrite, AccessTokenScopeUser, AccessTokenScopeAdmin},
+	AccessTokenScopeRepoWrite:  {AccessTokenScopeRepoRead, AccessTokenScopeIssueWrite},
+	AccessTokenScopeIssueWrite: {AccessTokenScopeRepoRead},
+}
+
+// ParseAccessTokenScope returns the scope made of the given scope names, all the scopes if
+// there is none. It returns ErrInvalidAccessTokenScope if a name isn't a known scope.
+func ParseAccessTokenScope(names []string) (AccessTokenScope, error) {
+	scopes := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !isValidAccessTokenScope(AccessTokenScope(name)) {
+			return "", ErrInvalidAccessTokenScope{name}
+		}
+		scopes = append(scopes, name)
+	}
+	if len(scopes) == 0 {
+		return AccessTokenScopeAll, nil
+	}
+	return AccessTokenScope(strings.Join(scopes, ",")), nil
+}
+
+func isValidAccessTokenScope(scope AccessTokenScope) bool {
+	for _, s := range AccessTokenScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Names returns the names of the scopes
+func (s AccessTokenScope) Names() []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(string(s), ",")
+}
+
+// Has returns whether the scope grants the given one
+func (s AccessTokenScope) Has(scope AccessTokenScope) bool {
+	for _, name := range s.Names() {
+		if hasAccessTokenScope(AccessTokenScope(name), scope) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAccessTokenScope(granted, scope AccessTokenScope) bool {
+	if granted == scope {
+		return true
+	}
+	for _, implied := range accessTokenScopeImplies[granted] {
+		if hasAccessTokenScope(implied, scope) {
+			return true
+		}
+	}
+	return false
+}
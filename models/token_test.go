@@ -18,6 +18,7 @@ func TestNewAccessToken(t *testing.T) {
 	}
 	assert.NoError(t, NewAccessToken(token))
 	AssertExistsAndLoadBean(t, token)
+	assert.Equal(t, AccessTokenScopeAll, token.Scope)
 
 	invalidToken := &AccessToken{
 		ID:   token.ID, // duplicate
@@ -128,3 +129,39 @@ func TestDeleteAccessTokenByID(t *testing.T) {
 	assert.Error(t, err)
 	assert.True(t, IsErrAccessTokenNotExist(err))
 }
+
+func TestParseAccessTokenScope(t *testing.T) {
+	scope, err := ParseAccessTokenScope(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, AccessTokenScopeAll, scope)
+
+	scope, err = ParseAccessTokenScope([]string{"repo:read", " user ", ""})
+	assert.NoError(t, err)
+	assert.Equal(t, AccessTokenScope("repo:read,user"), scope)
+	assert.Equal(t, []string{"repo:read", "user"}, scope.Names())
+
+	_, err = ParseAccessTokenScope([]string{"repo:read", "repo:delete"})
+	assert.True(t, IsErrInvalidAccessTokenScope(err))
+}
+
+func TestAccessTokenScope_Has(t *testing.T) {
+	kases := []struct {
+		scope    AccessTokenScope
+		required AccessTokenScope
+		has      bool
+	}{
+		{AccessTokenScopeAll, AccessTokenScopeAdmin, true},
+		{AccessTokenScopeAll, AccessTokenScopeRepoRead, true},
+		{AccessTokenScopeRepoWrite, AccessTokenScopeIssueWrite, true},
+		{AccessTokenScopeRepoWrite, AccessTokenScopeRepoRead, true},
+		{AccessTokenScopeIssueWrite, AccessTokenScopeRepoRead, true},
+		{AccessTokenScopeIssueWrite, AccessTokenScopeRepoWrite, false},
+		{AccessTokenScopeRepoRead, AccessTokenScopeRepoWrite, false},
+		{AccessTokenScopeRepoWrite, AccessTokenScopeAdmin, false},
+		{"repo:read,user", AccessTokenScopeUser, true},
+		{"", AccessTokenScopeRepoRead, false},
+	}
+	for _, kase := range kases {
+		assert.Equal(t, kase.has, kase.scope.Has(kase.required), "%s has %s", kase.scope, kase.required)
+	}
+}
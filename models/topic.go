@@ -220,7 +220,21 @@ func AddTopic(repoID int64, topicName string) (*Topic, error) {
 		return topic, nil
 	}
 
-	return addTopicByNameToRepo(x, repoID, topicName)
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	topic, err = addTopicByNameToRepo(sess, repoID, topicName)
+	if err != nil {
+		return nil, err
+	}
+	if err = syncTopicsInRepository(sess, repoID); err != nil {
+		return nil, err
+	}
+
+	return topic, sess.Commit()
 }
 
 // DeleteTopic removes a topic name from a repository (if it has it)
@@ -234,9 +248,20 @@ func DeleteTopic(repoID int64, topicName string) (*Topic, error) {
 		return nil, nil
 	}
 
-	err = removeTopicFromRepo(x, repoID, topic)
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	if err = removeTopicFromRepo(sess, repoID, topic); err != nil {
+		return nil, err
+	}
+	if err = syncTopicsInRepository(sess, repoID); err != nil {
+		return nil, err
+	}
 
-	return topic, err
+	return topic, sess.Commit()
 }
 
 // SaveTopics save topics to a repository
@@ -301,18 +326,24 @@ func SaveTopics(repoID int64, topicNames ...string) error {
 		}
 	}
 
-	topicNames = make([]string, 0, 25)
-	if err := sess.Table("topic").Cols("name").
-		Join("INNER", "repo_topic", "repo_topic.topic_id = topic.id").
-		Where("repo_topic.repo_id = ?", repoID).Desc("topic.repo_count").Find(&topicNames); err != nil {
+	if err := syncTopicsInRepository(sess, repoID); err != nil {
 		return err
 	}
 
-	if _, err := sess.ID(repoID).Cols("topics").Update(&Repository{
-		Topics: topicNames,
-	}); err != nil {
+	return sess.Commit()
+}
+
+// syncTopicsInRepository makes sure the topics column of the repository matches its topics
+func syncTopicsInRepository(e Engine, repoID int64) error {
+	topicNames := make([]string, 0, 25)
+	if err := e.Table("topic").Cols("name").
+		Join("INNER", "repo_topic", "repo_topic.topic_id = topic.id").
+		Where("repo_topic.repo_id = ?", repoID).Desc("topic.repo_count").Find(&topicNames); err != nil {
 		return err
 	}
 
-	return sess.Commit()
+	_, err := e.ID(repoID).Cols("topics").Update(&Repository{
+		Topics: topicNames,
+	})
+	return err
 }
@@ -63,6 +63,21 @@ func TestAddTopic(t *testing.T) {
 	assert.EqualValues(t, repo2NrOfTopics, len(topics))
 }
 
+func TestAddDeleteTopicSyncRepository(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	_, err := AddTopic(2, "gitea")
+	assert.NoError(t, err)
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 2}).(*Repository)
+	assert.ElementsMatch(t, []string{"topicname1", "topicname2", "gitea"}, repo.Topics)
+
+	_, err = DeleteTopic(2, "topicname1")
+	assert.NoError(t, err)
+	repo = AssertExistsAndLoadBean(t, &Repository{ID: 2}).(*Repository)
+	assert.ElementsMatch(t, []string{"topicname2", "gitea"}, repo.Topics)
+	AssertNotExistsBean(t, &RepoTopic{RepoID: 2, TopicID: 5})
+}
+
 func TestTopicValidator(t *testing.T) {
 	assert.True(t, ValidateTopic("12345"))
 	assert.True(t, ValidateTopic("2-test"))
@@ -16,6 +16,7 @@ import (
 
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/unknwon/com"
@@ -62,6 +63,13 @@ func MainTest(m *testing.M, pathToGiteaRoot string) {
 		fatalTestError("TempDir: %v\n", err)
 	}
 	setting.AppWorkPath = pathToGiteaRoot
+	setting.AttachmentPath = filepath.Join(setting.AppDataPath, "attachments")
+	setting.AttachmentStoreType = "local"
+	setting.LFS.ContentPath = filepath.Join(setting.AppDataPath, "lfs")
+	setting.LFS.StoreType = "local"
+	if err = storage.Init(); err != nil {
+		fatalTestError("storage.Init: %v\n", err)
+	}
 	setting.StaticRootPath = pathToGiteaRoot
 	setting.GravatarSourceURL, err = url.Parse("https://secure.gravatar.com/avatar/")
 	if err != nil {
@@ -1110,6 +1110,16 @@ func ChangeUserName(u *User, newUserName string) (err error) {
 		return fmt.Errorf("Rename user directory: %v", err)
 	}
 
+	repos := make([]*Repository, 0, 10)
+	if err = sess.Where("owner_id = ?", u.ID).Find(&repos); err != nil {
+		return fmt.Errorf("Find repositories: %v", err)
+	}
+	for _, repo := range repos {
+		if err = relinkForks(sess, repo, RepoPath(u.Name, repo.Name), RepoPath(newUserName, repo.Name)); err != nil {
+			return fmt.Errorf("relinkForks: %v", err)
+		}
+	}
+
 	return sess.Commit()
 }
 
@@ -1245,6 +1255,8 @@ func deleteUser(e *xorm.Session, u *User) error {
 		&TeamUser{UID: u.ID},
 		&Collaboration{UserID: u.ID},
 		&Stopwatch{UserID: u.ID},
+		&RepoTransfer{DoerID: u.ID},
+		&RepoTransfer{RecipientID: u.ID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
@@ -1458,6 +1470,14 @@ func GetUsersByIDs(ids []int64) (UserList, error) {
 	return ous, err
 }
 
+func getUsersMapByIDs(e Engine, ids []int64) (map[int64]*User, error) {
+	users := make(map[int64]*User, len(ids))
+	if len(ids) == 0 {
+		return users, nil
+	}
+	return users, e.In("id", ids).Find(&users)
+}
+
 // GetUserIDsByNames returns a slice of ids corresponds to names.
 func GetUserIDsByNames(names []string, ignoreNonExistent bool) ([]int64, error) {
 	ids := make([]int64, 0, len(names))
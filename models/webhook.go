@@ -6,8 +6,16 @@
 package models
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"crypto/tls"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"time"
 
 	"code.gitea.io/gitea/modules/log"
@@ -26,11 +34,14 @@ const (
 	ContentTypeJSON HookContentType = iota + 1
 	// ContentTypeForm is an url-encoded form payload for web hook
 	ContentTypeForm
+	// ContentTypeCloudEvents is a JSON payload wrapped in a CloudEvents 1.0 envelope
+	ContentTypeCloudEvents
 )
 
 var hookContentTypes = map[string]HookContentType{
-	"json": ContentTypeJSON,
-	"form": ContentTypeForm,
+	"json":        ContentTypeJSON,
+	"form":        ContentTypeForm,
+	"cloudevents": ContentTypeCloudEvents,
 }
 
 // ToHookContentType returns HookContentType by given name.
@@ -45,6 +56,8 @@ func (t HookContentType) Name() string {
 		return "json"
 	case ContentTypeForm:
 		return "form"
+	case ContentTypeCloudEvents:
+		return "cloudevents"
 	}
 	return ""
 }
@@ -75,6 +88,8 @@ type HookEvents struct {
 	PullRequestSync      bool `json:"pull_request_sync"`
 	Repository           bool `json:"repository"`
 	Release              bool `json:"release"`
+	User                 bool `json:"user"`
+	Organization         bool `json:"organization"`
 }
 
 // HookEvent represents events that will delivery hook.
@@ -83,6 +98,7 @@ type HookEvent struct {
 	SendEverything bool   `json:"send_everything"`
 	ChooseEvents   bool   `json:"choose_events"`
 	BranchFilter   string `json:"branch_filter"`
+	TagFilter      string `json:"tag_filter"`
 
 	HookEvents `json:"events"`
 }
@@ -116,6 +132,15 @@ type Webhook struct {
 	Meta            string     `xorm:"TEXT"` // store hook-specific attributes
 	LastStatus      HookStatus // Last delivery status
 
+	// Algorithm to sign payloads with, empty for the legacy signature
+	SignatureAlgorithm string `xorm:"VARCHAR(10) NOT NULL DEFAULT ''"`
+	// Proxy to deliver the hook through, empty for the global proxy
+	ProxyURL string `xorm:"proxy_url TEXT"`
+	// PEM encoded client certificate presented to endpoints requiring mutual TLS,
+	// its private key is stored encrypted with the secret key of the instance
+	ClientCertificate string `xorm:"TEXT"`
+	ClientKey         string `xorm:"TEXT"`
+
 	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
 }
@@ -140,6 +165,75 @@ func (w *Webhook) UpdateEvent() error {
 	return err
 }
 
+func (w *Webhook) getEncryptionKey() []byte {
+	k := md5.Sum([]byte(setting.SecretKey))
+	return k[:]
+}
+
+// HasClientCertificate returns true if the webhook has a client certificate.
+func (w *Webhook) HasClientCertificate() bool {
+	return len(w.ClientCertificate) > 0
+}
+
+// SetClientCertificate sets the PEM encoded client certificate and private key of the webhook.
+// The current key is kept if key is empty, both are removed if cert and key are empty.
+func (w *Webhook) SetClientCertificate(cert, key string) error {
+	if len(cert) == 0 {
+		if len(key) > 0 {
+			return ErrInvalidClientCertificate{Err: errors.New("missing certificate")}
+		}
+		w.ClientCertificate = ""
+		w.ClientKey = ""
+		return nil
+	}
+
+	if len(key) == 0 {
+		if len(w.ClientKey) == 0 {
+			return ErrInvalidClientCertificate{Err: errors.New("missing private key")}
+		}
+		current, err := w.decryptClientKey()
+		if err != nil {
+			return err
+		}
+		key = string(current)
+	}
+	if _, err := tls.X509KeyPair([]byte(cert), []byte(key)); err != nil {
+		return ErrInvalidClientCertificate{Err: err}
+	}
+
+	encrypted, err := aesEncrypt(w.getEncryptionKey(), []byte(key))
+	if err != nil {
+		return err
+	}
+	w.ClientCertificate = cert
+	w.ClientKey = base64.StdEncoding.EncodeToString(encrypted)
+	return nil
+}
+
+func (w *Webhook) decryptClientKey() ([]byte, error) {
+	encrypted, err := base64.StdEncoding.DecodeString(w.ClientKey)
+	if err != nil {
+		return nil, err
+	}
+	return aesDecrypt(w.getEncryptionKey(), encrypted)
+}
+
+// TLSClientCertificate returns the client certificate of the webhook, nil if it has none.
+func (w *Webhook) TLSClientCertificate() (*tls.Certificate, error) {
+	if !w.HasClientCertificate() {
+		return nil, nil
+	}
+	key, err := w.decryptClientKey()
+	if err != nil {
+		return nil, fmt.Errorf("decrypt client key: %v", err)
+	}
+	cert, err := tls.X509KeyPair([]byte(w.ClientCertificate), key)
+	if err != nil {
+		return nil, ErrInvalidClientCertificate{Err: err}
+	}
+	return &cert, nil
+}
+
 // HasCreateEvent returns true if hook enabled create event.
 func (w *Webhook) HasCreateEvent() bool {
 	return w.SendEverything ||
@@ -260,6 +354,18 @@ func (w *Webhook) HasRepositoryEvent() bool {
 		(w.ChooseEvents && w.HookEvents.Repository)
 }
 
+// HasUserEvent returns if hook enabled user event, only system webhooks receive it.
+func (w *Webhook) HasUserEvent() bool {
+	return w.IsSystemWebhook && (w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.User))
+}
+
+// HasOrganizationEvent returns if hook enabled organization event, only system webhooks receive it.
+func (w *Webhook) HasOrganizationEvent() bool {
+	return w.IsSystemWebhook && (w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Organization))
+}
+
 // EventCheckers returns event checkers
 func (w *Webhook) EventCheckers() []struct {
 	Has  func() bool
@@ -289,6 +395,8 @@ func (w *Webhook) EventCheckers() []struct {
 		{w.HasPullRequestSyncEvent, HookEventPullRequestSync},
 		{w.HasRepositoryEvent, HookEventRepository},
 		{w.HasReleaseEvent, HookEventRelease},
+		{w.HasUserEvent, HookEventUser},
+		{w.HasOrganizationEvent, HookEventOrganization},
 	}
 }
 
@@ -373,6 +481,11 @@ func GetWebhooksByRepoID(repoID int64, listOptions ListOptions) ([]*Webhook, err
 	return webhooks, sess.Find(&webhooks, &Webhook{RepoID: repoID})
 }
 
+// CountWebhooksByRepoID returns the number of the webhooks of a repository
+func CountWebhooksByRepoID(repoID int64) (int64, error) {
+	return x.Count(&Webhook{RepoID: repoID})
+}
+
 // GetActiveWebhooksByOrgID returns all active webhooks for an organization.
 func GetActiveWebhooksByOrgID(orgID int64) (ws []*Webhook, err error) {
 	return getActiveWebhooksByOrgID(x, orgID)
@@ -450,6 +563,14 @@ func getSystemWebhooks(e Engine) ([]*Webhook, error) {
 		Find(&webhooks)
 }
 
+// GetActiveSystemWebhooks returns all active admin system webhooks.
+func GetActiveSystemWebhooks() ([]*Webhook, error) {
+	webhooks := make([]*Webhook, 0, 5)
+	return webhooks, x.
+		Where("repo_id=? AND org_id=? AND is_system_webhook=? AND is_active=?", 0, 0, true, true).
+		Find(&webhooks)
+}
+
 // UpdateWebhook updates information of webhook.
 func UpdateWebhook(w *Webhook) error {
 	_, err := x.ID(w.ID).AllCols().Update(w)
@@ -477,6 +598,8 @@ func deleteWebhook(bean *Webhook) (err error) {
 		return ErrWebhookNotExist{ID: bean.ID}
 	} else if _, err = sess.Delete(&HookTask{HookID: bean.ID}); err != nil {
 		return err
+	} else if _, err = sess.Delete(&WebhookOptOut{HookID: bean.ID}); err != nil {
+		return err
 	}
 
 	return sess.Commit()
@@ -522,6 +645,39 @@ func DeleteDefaultSystemWebhook(id int64) error {
 	return sess.Commit()
 }
 
+// WebhookOptOut represents a repository which doesn't send its events to a webhook of its organization.
+type WebhookOptOut struct {
+	ID          int64              `xorm:"pk autoincr"`
+	RepoID      int64              `xorm:"UNIQUE(s)"`
+	HookID      int64              `xorm:"UNIQUE(s) INDEX"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// GetOptedOutWebhookIDs returns the IDs of the organization webhooks the repository doesn't send its events to.
+func GetOptedOutWebhookIDs(repoID int64) ([]int64, error) {
+	ids := make([]int64, 0, 5)
+	return ids, x.Table("webhook_opt_out").
+		Where("repo_id = ?", repoID).
+		Cols("hook_id").
+		Find(&ids)
+}
+
+// SetWebhookOptOut sets whether the repository doesn't send its events to the organization webhook.
+func SetWebhookOptOut(repoID, hookID int64, optOut bool) error {
+	bean := &WebhookOptOut{RepoID: repoID, HookID: hookID}
+	if !optOut {
+		_, err := x.Delete(bean)
+		return err
+	}
+
+	has, err := x.Exist(bean)
+	if err != nil || has {
+		return err
+	}
+	_, err = x.Insert(bean)
+	return err
+}
+
 // copyDefaultWebhooksToRepo creates copies of the default webhooks in a new repo
 func copyDefaultWebhooksToRepo(e Engine, repoID int64) error {
 	ws, err := getDefaultWebhooks(e)
@@ -560,6 +716,7 @@ const (
 	MSTEAMS
 	FEISHU
 	MATRIX
+	CUSTOM
 )
 
 var hookTaskTypes = map[string]HookTaskType{
@@ -572,6 +729,7 @@ var hookTaskTypes = map[string]HookTaskType{
 	"msteams":  MSTEAMS,
 	"feishu":   FEISHU,
 	"matrix":   MATRIX,
+	"custom":   CUSTOM,
 }
 
 // ToHookTaskType returns HookTaskType by given name.
@@ -600,6 +758,8 @@ func (t HookTaskType) Name() string {
 		return "feishu"
 	case MATRIX:
 		return "matrix"
+	case CUSTOM:
+		return "custom"
 	}
 	return ""
 }
@@ -635,6 +795,8 @@ const (
 	HookEventPullRequestSync           HookEventType = "pull_request_sync"
 	HookEventRepository                HookEventType = "repository"
 	HookEventRelease                   HookEventType = "release"
+	HookEventUser                      HookEventType = "user"
+	HookEventOrganization              HookEventType = "organization"
 )
 
 // Event returns the HookEventType as an event string
@@ -700,12 +862,53 @@ type HookTask struct {
 	Delivered       int64
 	DeliveredString string `xorm:"-"`
 
+	SignatureAlgorithm string `xorm:"VARCHAR(10) NOT NULL DEFAULT ''"`
+	ProxyURL           string `xorm:"proxy_url TEXT"`
+	// IsPayloadCompressed is set if PayloadContent holds the gzipped payload encoded in base64,
+	// it is decompressed when the task is loaded.
+	IsPayloadCompressed bool `xorm:"NOT NULL DEFAULT false"`
+
 	// History info.
 	IsSucceed       bool
 	RequestContent  string        `xorm:"TEXT"`
 	RequestInfo     *HookRequest  `xorm:"-"`
 	ResponseContent string        `xorm:"TEXT"`
 	ResponseInfo    *HookResponse `xorm:"-"`
+
+	// Retry info.
+	Attempts       int
+	NextAttempt    int64          `xorm:"INDEX NOT NULL DEFAULT 0"`
+	AttemptContent string         `xorm:"TEXT"`
+	AttemptHistory []*HookAttempt `xorm:"-"`
+	// IsDeadLetter is set once all attempts to deliver the task have failed
+	IsDeadLetter bool `xorm:"INDEX NOT NULL DEFAULT false"`
+}
+
+// HookAttempt represents the outcome of one delivery attempt of a hook task.
+type HookAttempt struct {
+	Delivered int64  `json:"delivered"`
+	Status    int    `json:"status"`
+	Error     string `json:"error,omitempty"`
+	// Duration of the attempt in nanoseconds, unknown for the older attempts
+	Duration int64 `json:"duration,omitempty"`
+}
+
+// DeliveredString returns the time of the attempt as a string
+func (a *HookAttempt) DeliveredString() string {
+	return time.Unix(0, a.Delivered).Format("2006-01-02 15:04:05 MST")
+}
+
+// DurationString returns the duration of the attempt as a string
+func (a *HookAttempt) DurationString() string {
+	return time.Duration(a.Duration).Round(time.Millisecond).String()
+}
+
+// LastAttempt returns the last attempt to deliver the task, nil if there is none
+func (t *HookTask) LastAttempt() *HookAttempt {
+	if len(t.AttemptHistory) == 0 {
+		return nil
+	}
+	return t.AttemptHistory[len(t.AttemptHistory)-1]
 }
 
 // BeforeUpdate will be invoked by XORM before updating a record
@@ -717,12 +920,27 @@ func (t *HookTask) BeforeUpdate() {
 	if t.ResponseInfo != nil {
 		t.ResponseContent = t.simpleMarshalJSON(t.ResponseInfo)
 	}
+	if t.AttemptHistory != nil {
+		t.AttemptContent = t.simpleMarshalJSON(t.AttemptHistory)
+	}
 }
 
 // AfterLoad updates the webhook object upon setting a column
 func (t *HookTask) AfterLoad() {
 	t.DeliveredString = time.Unix(0, t.Delivered).Format("2006-01-02 15:04:05 MST")
 
+	if t.IsPayloadCompressed {
+		if err := t.decompressPayload(); err != nil {
+			log.Error("Decompress PayloadContent[%d]: %v", t.ID, err)
+		}
+	}
+
+	if len(t.AttemptContent) > 0 {
+		if err := json.Unmarshal([]byte(t.AttemptContent), &t.AttemptHistory); err != nil {
+			log.Error("Unmarshal AttemptContent[%d]: %v", t.ID, err)
+		}
+	}
+
 	if len(t.RequestContent) == 0 {
 		return
 	}
@@ -740,6 +958,45 @@ func (t *HookTask) AfterLoad() {
 	}
 }
 
+// CompressPayload compresses the payload content of the task to save space
+// once it has been delivered.
+func (t *HookTask) CompressPayload() error {
+	if t.IsPayloadCompressed {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(t.PayloadContent)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	t.PayloadContent = base64.StdEncoding.EncodeToString(buf.Bytes())
+	t.IsPayloadCompressed = true
+	return nil
+}
+
+func (t *HookTask) decompressPayload() error {
+	data, err := base64.StdEncoding.DecodeString(t.PayloadContent)
+	if err != nil {
+		return err
+	}
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	payload, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	t.PayloadContent = string(payload)
+	t.IsPayloadCompressed = false
+	return nil
+}
+
 func (t *HookTask) simpleMarshalJSON(v interface{}) string {
 	p, err := json.Marshal(v)
 	if err != nil {
@@ -758,6 +1015,76 @@ func HookTasks(hookID int64, page int) ([]*HookTask, error) {
 		Find(&tasks)
 }
 
+// FindHookTasksOptions represents the options to find the tasks of a webhook
+type FindHookTasksOptions struct {
+	ListOptions
+	HookID         int64
+	DeadLetterOnly bool
+}
+
+// FindHookTasks returns the tasks of a webhook, the latest first
+func FindHookTasks(opts FindHookTasksOptions) ([]*HookTask, error) {
+	sess := x.Where("hook_id=?", opts.HookID)
+	if opts.DeadLetterOnly {
+		sess.And("is_dead_letter=?", true)
+	}
+	if opts.Page > 0 {
+		sess = opts.setSessionPagination(sess)
+	}
+	tasks := make([]*HookTask, 0, opts.PageSize)
+	return tasks, sess.Desc("id").Find(&tasks)
+}
+
+// GetHookTaskByHookID returns the task of a webhook by given ID
+func GetHookTaskByHookID(hookID, id int64) (*HookTask, error) {
+	t := &HookTask{
+		ID:     id,
+		HookID: hookID,
+	}
+	has, err := x.Get(t)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrHookTaskNotExist{HookID: hookID, ID: id}
+	}
+	return t, nil
+}
+
+// ReplayHookTask creates a new task delivering the payload of the given one again,
+// the given task isn't a dead letter any more.
+func ReplayHookTask(t *HookTask) (*HookTask, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	replay := &HookTask{
+		RepoID:             t.RepoID,
+		HookID:             t.HookID,
+		UUID:               gouuid.NewV4().String(),
+		Type:               t.Type,
+		URL:                t.URL,
+		Signature:          t.Signature,
+		SignatureAlgorithm: t.SignatureAlgorithm,
+		ProxyURL:           t.ProxyURL,
+		PayloadContent:     t.PayloadContent,
+		HTTPMethod:         t.HTTPMethod,
+		ContentType:        t.ContentType,
+		EventType:          t.EventType,
+		IsSSL:              t.IsSSL,
+	}
+	if _, err := sess.Insert(replay); err != nil {
+		return nil, err
+	}
+
+	t.IsDeadLetter = false
+	if _, err := sess.ID(t.ID).Cols("is_dead_letter").Update(t); err != nil {
+		return nil, err
+	}
+	return replay, sess.Commit()
+}
+
 // CreateHookTask creates a new hook task,
 // it handles conversion from Payload to PayloadContent.
 func CreateHookTask(t *HookTask) error {
@@ -781,19 +1108,82 @@ func UpdateHookTask(t *HookTask) error {
 	return err
 }
 
+// DeleteDeliveredHookTasks deletes the delivered hook tasks which were delivered more than
+// olderThan ago and, if numberToKeep is positive, all but the latest numberToKeep delivered tasks
+// of each webhook. Tasks waiting for a retry are kept. It returns the number of deleted tasks.
+func DeleteDeliveredHookTasks(ctx context.Context, olderThan time.Duration, numberToKeep int) (int64, error) {
+	log.Trace("Doing: DeleteDeliveredHookTasks")
+
+	var deleted int64
+	if olderThan > 0 {
+		n, err := x.
+			Where("is_delivered=? AND delivered<?", true, time.Now().Add(-olderThan).UnixNano()).
+			Delete(new(HookTask))
+		if err != nil {
+			return deleted, err
+		}
+		deleted += n
+	}
+
+	if numberToKeep > 0 {
+		hookIDs := make([]int64, 0, 10)
+		if err := x.Table("hook_task").Distinct("hook_id").Find(&hookIDs); err != nil {
+			return deleted, err
+		}
+		for _, hookID := range hookIDs {
+			select {
+			case <-ctx.Done():
+				return deleted, ErrCancelledf("before deleting hook tasks of webhook %d", hookID)
+			default:
+			}
+
+			// The ID of the oldest task to keep
+			ids := make([]int64, 0, 1)
+			if err := x.Table("hook_task").Cols("id").
+				Where("hook_id=? AND is_delivered=?", hookID, true).
+				Desc("id").Limit(1, numberToKeep-1).
+				Find(&ids); err != nil {
+				return deleted, err
+			}
+			if len(ids) == 0 {
+				continue
+			}
+
+			n, err := x.
+				Where("hook_id=? AND is_delivered=? AND id<?", hookID, true, ids[0]).
+				Delete(new(HookTask))
+			if err != nil {
+				return deleted, err
+			}
+			deleted += n
+		}
+	}
+
+	log.Trace("Finished: DeleteDeliveredHookTasks: %d deleted", deleted)
+	return deleted, nil
+}
+
 // FindUndeliveredHookTasks represents find the undelivered hook tasks
+// which are due, including the ones waiting for a retry
 func FindUndeliveredHookTasks() ([]*HookTask, error) {
 	tasks := make([]*HookTask, 0, 10)
-	if err := x.Where("is_delivered=?", false).Find(&tasks); err != nil {
+	if err := x.Where("is_delivered=? AND next_attempt<=?", false, time.Now().UnixNano()).Find(&tasks); err != nil {
 		return nil, err
 	}
 	return tasks, nil
 }
 
+// CountUndeliveredHookTasks returns the number of the hook tasks which are not delivered,
+// including the ones waiting for a retry
+func CountUndeliveredHookTasks() (int64, error) {
+	return x.Where("is_delivered=?", false).Count(new(HookTask))
+}
+
 // FindRepoUndeliveredHookTasks represents find the undelivered hook tasks of one repository
+// which are due, including the ones waiting for a retry
 func FindRepoUndeliveredHookTasks(repoID int64) ([]*HookTask, error) {
 	tasks := make([]*HookTask, 0, 5)
-	if err := x.Where("repo_id=? AND is_delivered=?", repoID, false).Find(&tasks); err != nil {
+	if err := x.Where("repo_id=? AND is_delivered=? AND next_attempt<=?", repoID, false, time.Now().UnixNano()).Find(&tasks); err != nil {
 		return nil, err
 	}
 	return tasks, nil
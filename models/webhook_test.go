@@ -5,8 +5,17 @@
 package models
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
+	"math/big"
 	"testing"
+	"time"
 
 	api "code.gitea.io/gitea/modules/structs"
 
@@ -163,6 +172,63 @@ func TestUpdateWebhook(t *testing.T) {
 	AssertExistsAndLoadBean(t, hook)
 }
 
+func generateClientCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "gitea"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	privateKey, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKey}))
+}
+
+func TestWebhook_SetClientCertificate(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	webhook := AssertExistsAndLoadBean(t, &Webhook{ID: 1}).(*Webhook)
+	assert.False(t, webhook.HasClientCertificate())
+	cert, err := webhook.TLSClientCertificate()
+	assert.NoError(t, err)
+	assert.Nil(t, cert)
+
+	certPEM, keyPEM := generateClientCertificate(t)
+	assert.NoError(t, webhook.SetClientCertificate(certPEM, keyPEM))
+	assert.True(t, webhook.HasClientCertificate())
+	assert.NotContains(t, webhook.ClientKey, "PRIVATE KEY")
+	assert.NoError(t, UpdateWebhook(webhook))
+
+	webhook = AssertExistsAndLoadBean(t, &Webhook{ID: 1}).(*Webhook)
+	cert, err = webhook.TLSClientCertificate()
+	assert.NoError(t, err)
+	assert.NotNil(t, cert)
+
+	// the current key is kept
+	assert.NoError(t, webhook.SetClientCertificate(certPEM, ""))
+	cert, err = webhook.TLSClientCertificate()
+	assert.NoError(t, err)
+	assert.NotNil(t, cert)
+
+	// the key must match the certificate
+	_, otherKeyPEM := generateClientCertificate(t)
+	err = webhook.SetClientCertificate(certPEM, otherKeyPEM)
+	assert.True(t, IsErrInvalidClientCertificate(err))
+	err = webhook.SetClientCertificate("", keyPEM)
+	assert.True(t, IsErrInvalidClientCertificate(err))
+
+	assert.NoError(t, webhook.SetClientCertificate("", ""))
+	assert.False(t, webhook.HasClientCertificate())
+	assert.Empty(t, webhook.ClientKey)
+	err = webhook.SetClientCertificate(certPEM, "")
+	assert.True(t, IsErrInvalidClientCertificate(err))
+}
+
 func TestDeleteWebhookByRepoID(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	AssertExistsAndLoadBean(t, &Webhook{ID: 2, RepoID: 1})
@@ -185,6 +251,26 @@ func TestDeleteWebhookByOrgID(t *testing.T) {
 	assert.True(t, IsErrWebhookNotExist(err))
 }
 
+func TestSetWebhookOptOut(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, SetWebhookOptOut(3, 3, true))
+	assert.NoError(t, SetWebhookOptOut(3, 3, true))
+	ids, err := GetOptedOutWebhookIDs(3)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3}, ids)
+
+	assert.NoError(t, SetWebhookOptOut(3, 3, false))
+	ids, err = GetOptedOutWebhookIDs(3)
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+
+	// the opt-outs of a webhook are deleted with it
+	assert.NoError(t, SetWebhookOptOut(5, 3, true))
+	assert.NoError(t, DeleteWebhookByOrgID(3, 3))
+	AssertNotExistsBean(t, &WebhookOptOut{HookID: 3})
+}
+
 func TestToHookTaskType(t *testing.T) {
 	assert.Equal(t, GOGS, ToHookTaskType("gogs"))
 	assert.Equal(t, SLACK, ToHookTaskType("slack"))
@@ -245,3 +331,86 @@ func TestUpdateHookTask(t *testing.T) {
 	assert.NoError(t, UpdateHookTask(hook))
 	AssertExistsAndLoadBean(t, hook)
 }
+
+func TestFindHookTasks(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	hookTasks, err := FindHookTasks(FindHookTasksOptions{HookID: 4})
+	assert.NoError(t, err)
+	if assert.Len(t, hookTasks, 1) {
+		assert.Equal(t, int64(2), hookTasks[0].ID)
+	}
+
+	hookTasks, err = FindHookTasks(FindHookTasksOptions{HookID: 1, DeadLetterOnly: true})
+	assert.NoError(t, err)
+	assert.Len(t, hookTasks, 0)
+}
+
+func TestReplayHookTask(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	_, err := GetHookTaskByHookID(1, 2)
+	assert.True(t, IsErrHookTaskNotExist(err))
+
+	hookTask, err := GetHookTaskByHookID(4, 2)
+	assert.NoError(t, err)
+	assert.True(t, hookTask.IsDeadLetter)
+
+	replay, err := ReplayHookTask(hookTask)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hookTask.UUID, replay.UUID)
+	assert.False(t, replay.IsDelivered)
+	assert.Equal(t, hookTask.PayloadContent, replay.PayloadContent)
+	AssertExistsAndLoadBean(t, &HookTask{ID: replay.ID, HookID: 4})
+	AssertExistsAndLoadBean(t, &HookTask{ID: 2}, Cond("is_dead_letter = ?", false))
+}
+
+func TestHookTask_CompressPayload(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	hookTask := AssertExistsAndLoadBean(t, &HookTask{ID: 2}).(*HookTask)
+	assert.NoError(t, hookTask.CompressPayload())
+	assert.True(t, hookTask.IsPayloadCompressed)
+	assert.NotEqual(t, `{"action":"published"}`, hookTask.PayloadContent)
+	assert.NoError(t, UpdateHookTask(hookTask))
+
+	// the payload is decompressed when loaded
+	hookTask = AssertExistsAndLoadBean(t, &HookTask{ID: 2}, Cond("is_payload_compressed = ?", true)).(*HookTask)
+	assert.Equal(t, `{"action":"published"}`, hookTask.PayloadContent)
+	assert.False(t, hookTask.IsPayloadCompressed)
+}
+
+func TestDeleteDeliveredHookTasks(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	newTask := func(delivered bool) *HookTask {
+		hookTask := &HookTask{
+			RepoID:    1,
+			HookID:    1,
+			Type:      GITEA,
+			Payloader: &api.PushPayload{},
+		}
+		assert.NoError(t, CreateHookTask(hookTask))
+		hookTask.IsDelivered = delivered
+		hookTask.Delivered = time.Now().UnixNano()
+		assert.NoError(t, UpdateHookTask(hookTask))
+		return hookTask
+	}
+	recent := []*HookTask{newTask(true), newTask(true), newTask(true)}
+	pending := newTask(false)
+
+	// the fixtures were delivered long ago
+	deleted, err := DeleteDeliveredHookTasks(context.Background(), time.Hour, 0)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, deleted)
+	AssertNotExistsBean(t, &HookTask{ID: 1})
+	AssertNotExistsBean(t, &HookTask{ID: 2})
+
+	deleted, err = DeleteDeliveredHookTasks(context.Background(), 0, 2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, deleted)
+	AssertNotExistsBean(t, &HookTask{ID: recent[0].ID})
+	AssertExistsAndLoadBean(t, &HookTask{ID: recent[1].ID})
+	AssertExistsAndLoadBean(t, &HookTask{ID: recent[2].ID})
+	AssertExistsAndLoadBean(t, &HookTask{ID: pending.ID})
+}
@@ -37,6 +37,8 @@ type CreateRepoForm struct {
 	IssueLabels   string
 	License       string
 	Readme        string
+	// ObjectFormatName is sha1 if it is empty
+	ObjectFormatName string `binding:"In(,sha1,sha256)"`
 
 	RepoTemplate int64
 	GitContent   bool
@@ -134,14 +136,27 @@ type RepoSettingForm struct {
 	PullsAllowRebase                 bool
 	PullsAllowRebaseMerge            bool
 	PullsAllowSquash                 bool
+	PullsAllowRebaseFastForward      bool
+	PullsAllowFastForwardOnly        bool
+	PullsSquashMessageTemplate       string
 	EnableTimetracker                bool
 	AllowOnlyContributorsToTrackTime bool
 	EnableIssueDependencies          bool
+	ReleasesProtectPublished         bool
+	ReleasesProtectedTagPatterns     string
+	ReleasesAutoReleaseTagPatterns   string
 	IsArchived                       bool
 
+	// Signing settings
+	TrustModel  string
+	TrustedKeys string
+
 	// Admin settings
 	EnableHealthCheck                     bool
 	EnableCloseIssuesViaCommitInAnyBranch bool
+	ReleaseMaxAssetSize                   int64
+	ReleaseMaxAssets                      int
+	ReleaseMaxTotalSize                   int64
 }
 
 // Validate validates the fields
@@ -177,6 +192,8 @@ type ProtectBranchForm struct {
 	DismissStaleApprovals    bool
 	RequireSignedCommits     bool
 	ProtectedFilePatterns    string
+	EnableMergeQueue         bool
+	RequireCodeOwnerReviews  bool
 }
 
 // Validate validates the fields
@@ -212,8 +229,12 @@ type WebhookForm struct {
 	PullRequestReview    bool
 	PullRequestSync      bool
 	Repository           bool
+	User                 bool
+	Organization         bool
 	Active               bool
 	BranchFilter         string `binding:"GlobPattern"`
+	TagFilter            string `binding:"GlobPattern"`
+	ProxyURL             string `binding:"ValidProxyUrl"`
 }
 
 // PushOnly if the hook will be triggered when push
@@ -233,10 +254,13 @@ func (f WebhookForm) ChooseEvents() bool {
 
 // NewWebhookForm form for creating web hook
 type NewWebhookForm struct {
-	PayloadURL  string `binding:"Required;ValidUrl"`
-	HTTPMethod  string `binding:"Required;In(POST,GET)"`
-	ContentType int    `binding:"Required"`
-	Secret      string
+	PayloadURL         string `binding:"Required;ValidUrl"`
+	HTTPMethod         string `binding:"Required;In(POST,GET)"`
+	ContentType        int    `binding:"Required"`
+	Secret             string
+	SignatureAlgorithm string `binding:"In(,sha256,sha512)"`
+	ClientCertificate  string
+	ClientKey          string
 	WebhookForm
 }
 
@@ -247,9 +271,11 @@ func (f *NewWebhookForm) Validate(ctx *macaron.Context, errs binding.Errors) bin
 
 // NewGogshookForm form for creating gogs hook
 type NewGogshookForm struct {
-	PayloadURL  string `binding:"Required;ValidUrl"`
-	ContentType int    `binding:"Required"`
-	Secret      string
+	PayloadURL        string `binding:"Required;ValidUrl"`
+	ContentType       int    `binding:"Required"`
+	Secret            string
+	ClientCertificate string
+	ClientKey         string
 	WebhookForm
 }
 
@@ -320,6 +346,7 @@ type NewMatrixHookForm struct {
 	RoomID        string `binding:"Required"`
 	AccessToken   string `binding:"Required"`
 	MessageType   int
+	PlainText     bool
 	WebhookForm
 }
 
@@ -328,9 +355,26 @@ func (f *NewMatrixHookForm) Validate(ctx *macaron.Context, errs binding.Errors)
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// NewCustomHookForm form for creating custom hook
+type NewCustomHookForm struct {
+	PayloadURL         string `binding:"Required;ValidUrl"`
+	Template           string `binding:"Required"`
+	Secret             string
+	SignatureAlgorithm string `binding:"In(,sha256,sha512)"`
+	ClientCertificate  string
+	ClientKey          string
+	WebhookForm
+}
+
+// Validate validates the fields
+func (f *NewCustomHookForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // NewMSTeamsHookForm form for creating MS Teams hook
 type NewMSTeamsHookForm struct {
 	PayloadURL string `binding:"Required;ValidUrl"`
+	CardType   string `binding:"In(,message_card,adaptive_card)"`
 	WebhookForm
 }
 
@@ -367,6 +411,7 @@ type CreateIssueForm struct {
 	AssigneeID  int64
 	Content     string
 	Files       []string
+	Draft       bool
 }
 
 // Validate validates the fields
@@ -482,11 +527,13 @@ func (f *InitializeLabelsForm) Validate(ctx *macaron.Context, errs binding.Error
 // swagger:model MergePullRequestOption
 type MergePullRequestForm struct {
 	// required: true
-	// enum: merge,rebase,rebase-merge,squash
-	Do                string `binding:"Required;In(merge,rebase,rebase-merge,squash)"`
+	// enum: merge,rebase,rebase-merge,squash,rebase-fast-forward,fast-forward-only
+	Do                string `binding:"Required;In(merge,rebase,rebase-merge,squash,rebase-fast-forward,fast-forward-only)"`
 	MergeTitleField   string
 	MergeMessageField string
 	ForceMerge        *bool `json:"force_merge,omitempty"`
+	// schedule the merge for once the branch protection is satisfied if it isn't yet
+	MergeWhenChecksSucceed bool `json:"merge_when_checks_succeed,omitempty"`
 }
 
 // Validate validates the fields
@@ -581,6 +628,16 @@ func (f *EditReleaseForm) Validate(ctx *macaron.Context, errs binding.Errors) bi
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// CreateReleaseCommentForm form for commenting on a release
+type CreateReleaseCommentForm struct {
+	Content string `form:"content" binding:"Required"`
+}
+
+// Validate validates the fields
+func (f *CreateReleaseCommentForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 //  __      __.__ __   .__
 // /  \    /  \__|  | _|__|
 // \   \/\/   /  |  |/ /  |
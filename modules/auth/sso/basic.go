@@ -76,6 +76,7 @@ func (b *Basic) VerifyAuthData(ctx *macaron.Context, sess session.Store) *models
 	if uid != 0 {
 		var err error
 		ctx.Data["IsApiToken"] = true
+		ctx.Data["ApiTokenScope"] = models.AccessTokenScopeAll
 
 		u, err = models.GetUserByID(uid)
 		if err != nil {
@@ -85,12 +86,19 @@ func (b *Basic) VerifyAuthData(ctx *macaron.Context, sess session.Store) *models
 	}
 	token, err := models.GetAccessTokenBySHA(authToken)
 	if err == nil {
+		if !isTokenScopeAllowed(ctx, token.Scope) {
+			log.Debug("Access token %d with the scope %s refused for %s", token.ID, token.Scope, ctx.Req.URL.Path)
+			return nil
+		}
+
 		u, err = models.GetUserByID(token.UID)
 		if err != nil {
 			log.Error("GetUserByID:  %v", err)
 			return nil
 		}
 
+		ctx.Data["ApiTokenScope"] = token.Scope
+
 		token.UpdatedUnix = timeutil.TimeStampNow()
 		if err = models.UpdateAccessToken(token); err != nil {
 			log.Error("UpdateAccessToken:  %v", err)
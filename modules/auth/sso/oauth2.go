@@ -88,6 +88,7 @@ func (o *OAuth2) userIDFromToken(ctx *macaron.Context) int64 {
 		uid := CheckOAuthAccessToken(tokenSHA)
 		if uid != 0 {
 			ctx.Data["IsApiToken"] = true
+			ctx.Data["ApiTokenScope"] = models.AccessTokenScopeAll
 		}
 		return uid
 	}
@@ -98,11 +99,16 @@ func (o *OAuth2) userIDFromToken(ctx *macaron.Context) int64 {
 		}
 		return 0
 	}
+	if !isTokenScopeAllowed(ctx, t.Scope) {
+		log.Debug("Access token %d with the scope %s refused for %s", t.ID, t.Scope, ctx.Req.URL.Path)
+		return 0
+	}
 	t.UpdatedUnix = timeutil.TimeStampNow()
 	if err = models.UpdateAccessToken(t); err != nil {
 		log.Error("UpdateAccessToken: %v", err)
 	}
 	ctx.Data["IsApiToken"] = true
+	ctx.Data["ApiTokenScope"] = t.Scope
 	return t.UID
 }
 
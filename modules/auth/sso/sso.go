@@ -105,6 +105,24 @@ func isAttachmentDownload(ctx *macaron.Context) bool {
 	return strings.HasPrefix(ctx.Req.URL.Path, "/attachments/") && ctx.Req.Method == "GET"
 }
 
+// isLFSPath returns true if the specified URL is a path of the LFS server
+func isLFSPath(ctx *macaron.Context) bool {
+	return strings.Contains(ctx.Req.URL.Path, ".git/info/lfs/")
+}
+
+// isTokenScopeAllowed returns true if an access token of the scope may sign in for the request.
+// The API and the LFS server check the scopes themselves, the other routes only accept the
+// unscoped tokens, except for the attachment downloads which require the repo:read scope.
+func isTokenScopeAllowed(ctx *macaron.Context, scope models.AccessTokenScope) bool {
+	switch {
+	case scope.Has(models.AccessTokenScopeAll), isAPIPath(ctx), isLFSPath(ctx):
+		return true
+	case isAttachmentDownload(ctx):
+		return scope.Has(models.AccessTokenScopeRepoRead)
+	}
+	return false
+}
+
 // handleSignIn clears existing session variables and stores new ones for the specified user object
 func handleSignIn(ctx *macaron.Context, sess session.Store, user *models.User) {
 	_ = sess.Delete("openid_verified_uri")
@@ -133,6 +133,28 @@ func GetInt64(key string, getFunc func() (int64, error)) (int64, error) {
 	}
 }
 
+// IncrInt64 increases the counter of the key and returns its new value, a missing counter
+// is created with the given timeout in seconds
+func IncrInt64(key string, timeout int64) (int64, error) {
+	if conn == nil {
+		return 0, fmt.Errorf("cache is disabled")
+	}
+	if !conn.IsExist(key) {
+		return 1, conn.Put(key, int64(1), timeout)
+	}
+	if err := conn.Incr(key); err != nil {
+		return 0, err
+	}
+	switch value := conn.Get(key).(type) {
+	case int64:
+		return value, nil
+	case string:
+		return strconv.ParseInt(value, 10, 64)
+	default:
+		return 0, fmt.Errorf("Unsupported cached value type: %v", value)
+	}
+}
+
 // Remove key from cache
 func Remove(key string) {
 	if conn == nil {
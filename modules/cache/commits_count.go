@@ -0,0 +1,43 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"strconv"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// GetCommitsCount returns the number of commits reachable from the commit.
+// The history of a commit only changes through replace refs, so unless the repository
+// has some the count is cached by commit ID for as long as the last commits and shared between forks.
+func GetCommitsCount(commit *git.Commit) (int64, error) {
+	if conn == nil || !setting.CacheService.LastCommit.Enabled || commit.HasReplacements() {
+		return commit.CommitsCount()
+	}
+
+	key := "commits_count:" + commit.ID.String()
+	switch value := conn.Get(key).(type) {
+	case int64:
+		log.Trace("CommitsCountCache hit: [%s]", commit.ID)
+		return value, nil
+	case string:
+		if count, err := strconv.ParseInt(value, 10, 64); err == nil {
+			log.Trace("CommitsCountCache hit: [%s]", commit.ID)
+			return count, nil
+		}
+	}
+
+	count, err := commit.CommitsCount()
+	if err != nil {
+		return 0, err
+	}
+	if err := conn.Put(key, count, int64(setting.CacheService.LastCommit.TTL.Seconds())); err != nil {
+		log.Error("Unable to cache the commits count of %s: %v", commit.ID, err)
+	}
+	return count, nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getMasterCommit(t *testing.T, repoPath string) *git.Commit {
+	repo, err := git.OpenRepository(repoPath)
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	commit, err := repo.GetBranchCommit("master")
+	assert.NoError(t, err)
+	return commit
+}
+
+func TestGetCommitsCount(t *testing.T) {
+	oldConn, oldEnabled := conn, setting.CacheService.LastCommit.Enabled
+	defer func() {
+		conn, setting.CacheService.LastCommit.Enabled = oldConn, oldEnabled
+	}()
+
+	var err error
+	conn, err = newCache(setting.Cache{Adapter: "memory", Interval: 60})
+	assert.NoError(t, err)
+	setting.CacheService.LastCommit.Enabled = true
+
+	tmpDir, err := ioutil.TempDir("", "commits_count")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	repoPath := filepath.Join(tmpDir, "repo1_bare")
+	assert.NoError(t, git.Clone(filepath.Join("..", "git", "tests", "repos", "repo1_bare"), repoPath, git.CloneRepoOptions{Mirror: true}))
+
+	commit := getMasterCommit(t, repoPath)
+	count, err := commit.CommitsCount()
+	assert.NoError(t, err)
+
+	// The count is served from the cache once computed
+	cached, err := GetCommitsCount(commit)
+	assert.NoError(t, err)
+	assert.Equal(t, count, cached)
+	assert.NoError(t, conn.Put("commits_count:"+commit.ID.String(), count+100, 60))
+	cached, err = GetCommitsCount(commit)
+	assert.NoError(t, err)
+	assert.Equal(t, count+100, cached)
+
+	// "Added short link" is replaced by the root commit "Add file1.txt", which cuts the history of master
+	// without changing its ID, so the cache must not be used anymore
+	_, err = git.NewCommand("replace", "37991dec2c8e592043f47155ce4808d4580f9123", "95bb4d39648ee7e325106df01a621c530863a653").RunInDir(repoPath)
+	assert.NoError(t, err)
+
+	commit = getMasterCommit(t, repoPath)
+	assert.True(t, commit.HasReplacements())
+	replacedCount, err := commit.CommitsCount()
+	assert.NoError(t, err)
+	assert.Less(t, replacedCount, count)
+	cached, err = GetCommitsCount(commit)
+	assert.NoError(t, err)
+	assert.Equal(t, replacedCount, cached)
+}
@@ -0,0 +1,115 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package codeowners
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/gobwas/glob"
+)
+
+// Paths are the paths a CODEOWNERS file is looked for in a branch, in order
+var Paths = []string{".gitea/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// Rule is a line of a CODEOWNERS file: the files matching its pattern are owned by its owners,
+// users as @name, teams as @org/team or users by their email addresses
+type Rule struct {
+	Pattern string
+	Owners  []string
+	globs   []glob.Glob
+}
+
+// File is a parsed CODEOWNERS file
+type File struct {
+	Rules []*Rule
+}
+
+// Parse parses the content of a CODEOWNERS file, the lines whose pattern is invalid are skipped
+func Parse(content string) *File {
+	file := &File{}
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		globs, err := compilePattern(fields[0])
+		if err != nil {
+			log.Info("Invalid CODEOWNERS pattern '%s' (skipped): %v", fields[0], err)
+			continue
+		}
+		file.Rules = append(file.Rules, &Rule{
+			Pattern: fields[0],
+			Owners:  fields[1:],
+			globs:   globs,
+		})
+	}
+	return file
+}
+
+// compilePattern compiles a pattern like in gitignore files: patterns without any slash but a trailing one match
+// at any depth and the patterns match the files of the directories they match, unless they end with a star.
+func compilePattern(pattern string) ([]glob.Glob, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	patterns := []string{pattern}
+	if !anchored {
+		patterns = append(patterns, "**/"+pattern)
+	} else if strings.HasPrefix(pattern, "**/") {
+		// The leading directories may be none
+		patterns = append(patterns, pattern[3:])
+	}
+	if strings.Contains(pattern, "/**/") {
+		// Like the intermediate ones
+		patterns = append(patterns, strings.Replace(pattern, "/**/", "/", -1))
+	}
+	alternatives := make([]string, 0, 2*len(patterns))
+	for _, p := range patterns {
+		if !dirOnly {
+			alternatives = append(alternatives, p)
+		}
+		if dirOnly || !strings.HasSuffix(p, "*") {
+			alternatives = append(alternatives, p+"/**")
+		}
+	}
+	globs := make([]glob.Glob, 0, len(alternatives))
+	for _, alternative := range alternatives {
+		g, err := glob.Compile(alternative, '/')
+		if err != nil {
+			return nil, err
+		}
+		globs = append(globs, g)
+	}
+	return globs, nil
+}
+
+// Match returns whether the file at the path matches the pattern of the rule
+func (r *Rule) Match(path string) bool {
+	for _, g := range r.globs {
+		if g.Match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Owners returns the owners of the file at the path, which are the owners of the last rule matching it,
+// none if the file isn't owned
+func (f *File) Owners(path string) []string {
+	for i := len(f.Rules) - 1; i >= 0; i-- {
+		if f.Rules[i].Match(path) {
+			return f.Rules[i].Owners
+		}
+	}
+	return nil
+}
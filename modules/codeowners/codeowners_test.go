@@ -0,0 +1,92 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package codeowners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	file := Parse(`# The default owners
+*       @user1
+
+*.go    @user2 @org3/team1 # Go code
+/docs/  user5@example.com
+build/  @user4
+cmd/*   @user6
+/README.md
+`)
+	assert.Len(t, file.Rules, 6)
+	assert.Equal(t, "*.go", file.Rules[1].Pattern)
+	assert.Equal(t, []string{"@user2", "@org3/team1"}, file.Rules[1].Owners)
+
+	for path, owners := range map[string][]string{
+		"LICENSE":               {"@user1"},
+		"main.go":               {"@user2", "@org3/team1"},
+		"models/user.go":        {"@user2", "@org3/team1"},
+		"docs/index.md":         {"user5@example.com"},
+		"docs/content/index.md": {"user5@example.com"},
+		"models/docs/index.md":  {"@user1"},
+		"build/Makefile":        {"@user4"},
+		"scripts/build/run.sh":  {"@user4"},
+		"build":                 {"@user1"},
+		"cmd/serv.go":           {"@user6"},
+		"cmd/main/main.txt":     {"@user1"},
+		"README.md":             {},
+	} {
+		assert.Equal(t, owners, file.Owners(path), path)
+	}
+
+	assert.Nil(t, Parse("").Owners("README.md"))
+}
+
+func TestGlobs(t *testing.T) {
+	for _, c := range []struct {
+		pattern   string
+		matches   []string
+		unmatches []string
+	}{
+		{"*", []string{"README.md", "docs/index.md", "a/b/c"}, nil},
+		{"*.js", []string{"app.js", "src/app.js"}, []string{"app.jsx", "src/app.ts"}},
+		{"/*.js", []string{"app.js"}, []string{"src/app.js"}},
+		{"docs/*", []string{"docs/index.md"}, []string{"docs/content/index.md", "src/docs/index.md"}},
+		{"docs/**/*.md", []string{"docs/index.md", "docs/a/index.md", "docs/a/b/index.md"}, []string{"docs/a/index.txt", "src/docs/a/index.md"}},
+		{"**/logs", []string{"logs", "logs/a.log", "build/logs/a.log"}, []string{"build/logs.txt"}},
+		{"apps/", []string{"apps/a.go", "src/apps/a/b.go"}, []string{"apps", "apps.go"}},
+		{"/build/", []string{"build/a.o"}, []string{"src/build/a.o", "build"}},
+		{"Makefile", []string{"Makefile", "src/Makefile", "Makefile/a"}, []string{"Makefile.am"}},
+	} {
+		file := Parse(c.pattern + " @user1")
+		assert.Len(t, file.Rules, 1, c.pattern)
+		for _, path := range c.matches {
+			assert.True(t, file.Rules[0].Match(path), "%s should match %s", c.pattern, path)
+		}
+		for _, path := range c.unmatches {
+			assert.False(t, file.Rules[0].Match(path), "%s shouldn't match %s", c.pattern, path)
+		}
+	}
+}
+
+func TestPrecedence(t *testing.T) {
+	// The last matching rule applies, even if an earlier one is more specific
+	file := Parse(`docs/index.md @user1
+*.md @user2
+docs/ @user3
+/docs/internal/ @user4
+`)
+	assert.Equal(t, []string{"@user3"}, file.Owners("docs/index.md"))
+	assert.Equal(t, []string{"@user4"}, file.Owners("docs/internal/index.md"))
+	assert.Equal(t, []string{"@user2"}, file.Owners("README.md"))
+	assert.Nil(t, file.Owners("main.go"))
+
+	// A rule without owners makes the files it matches unowned
+	file = Parse(`* @user1
+/vendor/
+`)
+	assert.Empty(t, file.Owners("vendor/modules.txt"))
+	assert.Equal(t, []string{"@user1"}, file.Owners("main.go"))
+}
@@ -6,10 +6,14 @@
 package context
 
 import (
+	"crypto/sha1"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
@@ -158,6 +162,67 @@ func (ctx *APIContext) SetLinkHeader(total, pageSize int) {
 	}
 }
 
+// SetTotalCountHeader sets the X-Total-Count header to the total number of items of a list.
+func (ctx *APIContext) SetTotalCountHeader(total int64) {
+	ctx.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+}
+
+// SetLastModified sets the Last-Modified header of the response, a successful response to
+// a GET request is then not modified if the client has a version not older than t.
+func (ctx *APIContext) SetLastModified(t time.Time) {
+	ctx.Header().Set("Last-Modified", t.UTC().Format(http.TimeFormat))
+}
+
+// JSON renders obj as JSON. A successful response to a GET or HEAD request gets an ETag
+// header and is replaced by 304 Not Modified if the client already has the same version.
+func (ctx *APIContext) JSON(status int, obj interface{}) {
+	if status != http.StatusOK || (ctx.Req.Method != http.MethodGet && ctx.Req.Method != http.MethodHead) {
+		ctx.Context.JSON(status, obj)
+		return
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+	etag := fmt.Sprintf(`"%x"`, sha1.Sum(data))
+	ctx.Header().Set("ETag", etag)
+	if isNotModified(ctx.Req.Request, ctx.Header(), etag) {
+		ctx.Header().Del("Content-Type")
+		ctx.Resp.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	ctx.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	ctx.Resp.WriteHeader(status)
+	_, _ = ctx.Resp.Write(data)
+}
+
+// isNotModified returns whether the conditional headers of the request match the response
+// validators. If-Modified-Since is ignored if If-None-Match is sent, as by RFC 7232.
+func isNotModified(req *http.Request, header http.Header, etag string) bool {
+	if ifNoneMatch := req.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, tag := range strings.Split(ifNoneMatch, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	ifModifiedSince, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	lastModified, err := http.ParseTime(header.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+	return !lastModified.After(ifModifiedSince)
+}
+
 // RequireCSRF requires a validated a CSRF token
 func (ctx *APIContext) RequireCSRF() {
 	headerToken := ctx.Req.Header.Get(ctx.csrf.GetHeaderName())
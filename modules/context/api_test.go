@@ -5,9 +5,11 @@
 package context
 
 import (
+	"net/http"
 	"net/url"
 	"strconv"
 	"testing"
+	"time"
 
 	"code.gitea.io/gitea/modules/setting"
 
@@ -49,3 +51,37 @@ func TestGenAPILinks(t *testing.T) {
 		assert.EqualValues(t, links, response)
 	}
 }
+
+func TestIsNotModified(t *testing.T) {
+	lastModified := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	header := http.Header{}
+	header.Set("Last-Modified", lastModified.Format(http.TimeFormat))
+
+	kases := []struct {
+		ifNoneMatch     string
+		ifModifiedSince time.Time
+		notModified     bool
+	}{
+		{"", time.Time{}, false},
+		{`"abc"`, time.Time{}, true},
+		{`W/"abc"`, time.Time{}, true},
+		{`"def", "abc"`, time.Time{}, true},
+		{"*", time.Time{}, true},
+		{`"def"`, time.Time{}, false},
+		{"", lastModified, true},
+		{"", lastModified.Add(time.Hour), true},
+		{"", lastModified.Add(-time.Hour), false},
+		{`"def"`, lastModified, false},
+	}
+	for _, kase := range kases {
+		req, err := http.NewRequest("GET", "/", nil)
+		assert.NoError(t, err)
+		if kase.ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", kase.ifNoneMatch)
+		}
+		if !kase.ifModifiedSince.IsZero() {
+			req.Header.Set("If-Modified-Since", kase.ifModifiedSince.Format(http.TimeFormat))
+		}
+		assert.Equal(t, kase.notModified, isNotModified(req, header, `"abc"`), "%+v", kase)
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToActivity convert an action to api format, its user and repository must be loaded
+func ToActivity(act *models.Action, doer *models.User) (*api.Activity, error) {
+	mode, err := models.AccessLevel(doer, act.Repo)
+	if err != nil {
+		return nil, err
+	}
+	if act.ActUser == nil {
+		act.ActUser = models.NewGhostUser()
+	}
+
+	return &api.Activity{
+		ID:        act.ID,
+		OpType:    act.OpType.String(),
+		ActUserID: act.ActUserID,
+		ActUser:   ToUser(act.ActUser, doer != nil, doer != nil && (doer.IsAdmin || doer.ID == act.ActUserID)),
+		RepoID:    act.RepoID,
+		Repo:      act.Repo.APIFormat(mode),
+		CommentID: act.CommentID,
+		RefName:   act.RefName,
+		IsPrivate: act.IsPrivate,
+		Content:   act.Content,
+		Created:   act.CreatedUnix.AsTime(),
+	}, nil
+}
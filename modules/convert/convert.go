@@ -6,12 +6,14 @@ package convert
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/util"
@@ -74,6 +76,18 @@ func ToBranch(repo *models.Repository, b *git.Branch, c *git.Commit, bp *models.
 	return branch, nil
 }
 
+// ToDeletedBranch convert a DeletedBranch to api.DeletedBranch
+func ToDeletedBranch(deletedBranch *models.DeletedBranch, doer *models.User) *api.DeletedBranch {
+	deletedBranch.LoadUser()
+	return &api.DeletedBranch{
+		ID:        deletedBranch.ID,
+		Name:      deletedBranch.Name,
+		CommitID:  deletedBranch.Commit,
+		DeletedBy: ToUser(deletedBranch.DeletedBy, doer != nil, doer != nil && (doer.IsAdmin || doer.ID == deletedBranch.DeletedByID)),
+		Deleted:   deletedBranch.DeletedUnix.AsTime(),
+	}
+}
+
 // ToBranchProtection convert a ProtectedBranch to api.BranchProtection
 func ToBranchProtection(bp *models.ProtectedBranch) *api.BranchProtection {
 	pushWhitelistUsernames, err := models.GetUserNamesByIDs(bp.WhitelistUserIDs)
@@ -119,6 +133,8 @@ func ToBranchProtection(bp *models.ProtectedBranch) *api.BranchProtection {
 		ApprovalsWhitelistTeams:     approvalsWhitelistTeams,
 		BlockOnRejectedReviews:      bp.BlockOnRejectedReviews,
 		BlockOnOutdatedBranch:       bp.BlockOnOutdatedBranch,
+		EnableMergeQueue:            bp.EnableMergeQueue,
+		RequireCodeOwnerReviews:     bp.RequireCodeOwnerReviews,
 		DismissStaleApprovals:       bp.DismissStaleApprovals,
 		RequireSignedCommits:        bp.RequireSignedCommits,
 		ProtectedFilePatterns:       bp.ProtectedFilePatterns,
@@ -169,16 +185,22 @@ func ToCommit(repo *models.Repository, c *git.Commit) *api.PayloadCommit {
 			UserName: committerUsername,
 		},
 		Timestamp:    c.Author.When,
-		Verification: ToVerification(c),
+		Verification: ToVerification(repo, c),
 	}
 }
 
-// ToVerification convert a git.Commit.Signature to an api.PayloadCommitVerification
-func ToVerification(c *git.Commit) *api.PayloadCommitVerification {
+// ToVerification convert a git.Commit.Signature to an api.PayloadCommitVerification,
+// whose signer is trusted according to the trust model of the repository
+func ToVerification(repo *models.Repository, c *git.Commit) *api.PayloadCommitVerification {
 	verif := models.ParseCommitWithSignature(c)
+	if err := models.CalculateTrustStatus(verif, repo, nil); err != nil {
+		log.Error("CalculateTrustStatus: %v", err)
+	}
 	commitVerification := &api.PayloadCommitVerification{
-		Verified: verif.Verified,
-		Reason:   verif.Reason,
+		Verified:    verif.Verified,
+		Reason:      verif.Reason,
+		TrustModel:  verif.TrustModel,
+		TrustStatus: verif.TrustStatus,
 	}
 	if c.Signature != nil {
 		commitVerification.Signature = c.Signature.Signature
@@ -193,6 +215,30 @@ func ToVerification(c *git.Commit) *api.PayloadCommitVerification {
 	return commitVerification
 }
 
+// ToTagVerification convert the signature of a tag to an api.PayloadCommitVerification
+func ToTagVerification(repo *models.Repository, gitRepo *git.Repository, tagName string) (*api.PayloadCommitVerification, error) {
+	tag, err := gitRepo.GetTag(tagName)
+	if err != nil {
+		return nil, err
+	}
+	if git.ObjectType(tag.Type) != git.ObjectTag {
+		// lightweight tags can not be signed
+		return &api.PayloadCommitVerification{
+			Verified:   false,
+			Reason:     "gpg.error.not_signed_commit",
+			TrustModel: repo.GetTrustModel().String(),
+		}, nil
+	}
+
+	// the commit returned for a tag object carries the signature of the tag
+	c, err := gitRepo.GetCommit(tag.ID.String())
+	if err != nil {
+		return nil, err
+	}
+	c.Committer = tag.Tagger
+	return ToVerification(repo, c), nil
+}
+
 // ToPublicKey convert models.PublicKey to api.PublicKey
 func ToPublicKey(apiLink string, key *models.PublicKey) *api.PublicKey {
 	return &api.PublicKey{
@@ -256,6 +302,15 @@ func ToHook(repoLink string, w *models.Webhook) *api.Hook {
 		"url":          w.URL,
 		"content_type": w.ContentType.Name(),
 	}
+	if len(w.SignatureAlgorithm) > 0 {
+		config["signature_algorithm"] = w.SignatureAlgorithm
+	}
+	if len(w.ProxyURL) > 0 {
+		config["proxy_url"] = w.ProxyURL
+	}
+	if w.HasClientCertificate() {
+		config["client_certificate"] = w.ClientCertificate
+	}
 	if w.HookTaskType == models.SLACK {
 		s := webhook.GetSlackHook(w)
 		config["channel"] = s.Channel
@@ -263,6 +318,22 @@ func ToHook(repoLink string, w *models.Webhook) *api.Hook {
 		config["icon_url"] = s.IconURL
 		config["color"] = s.Color
 	}
+	if w.HookTaskType == models.CUSTOM {
+		config["template"] = webhook.GetCustomHook(w).Template
+	}
+	if w.HookTaskType == models.MSTEAMS {
+		config["card_type"] = webhook.GetMSTeamsHook(w).CardType
+		if len(config["card_type"]) == 0 {
+			config["card_type"] = webhook.MSTeamsMessageCard
+		}
+	}
+	if w.HookTaskType == models.MATRIX {
+		m := webhook.GetMatrixHook(w)
+		config["homeserver_url"] = m.HomeserverURL
+		config["room_id"] = m.Room
+		config["message_type"] = webhook.MatrixMessageTypeName(m.MessageType)
+		config["plain_text"] = strconv.FormatBool(m.PlainText)
+	}
 
 	return &api.Hook{
 		ID:      w.ID,
@@ -276,6 +347,60 @@ func ToHook(repoLink string, w *models.Webhook) *api.Hook {
 	}
 }
 
+// ToSystemHook convert models.Webhook of an admin system webhook to api.Hook
+func ToSystemHook(w *models.Webhook) *api.Hook {
+	hook := ToHook("", w)
+	hook.URL = fmt.Sprintf("%s/admin/system-hooks/%d", setting.AppSubURL, w.ID)
+	return hook
+}
+
+// ToInheritedHook convert models.Webhook of an organization to api.InheritedHook
+func ToInheritedHook(w *models.Webhook, optedOut bool) *api.InheritedHook {
+	return &api.InheritedHook{
+		ID:       w.ID,
+		Type:     w.HookTaskType.Name(),
+		Events:   w.EventsArray(),
+		Active:   w.IsActive,
+		OptedOut: optedOut,
+	}
+}
+
+// ToHookDelivery convert models.HookTask to api.HookDelivery
+func ToHookDelivery(t *models.HookTask) *api.HookDelivery {
+	delivery := &api.HookDelivery{
+		ID:         t.ID,
+		UUID:       t.UUID,
+		Event:      t.EventType.Event(),
+		URL:        t.URL,
+		Delivered:  t.IsDelivered,
+		Succeeded:  t.IsSucceed,
+		DeadLetter: t.IsDeadLetter,
+		Attempts:   t.Attempts,
+		Request: &api.HookDeliveryRequest{
+			Headers: map[string]string{},
+			Payload: t.PayloadContent,
+		},
+	}
+	if t.Delivered > 0 {
+		deliveredAt := time.Unix(0, t.Delivered)
+		delivery.DeliveredAt = &deliveredAt
+	}
+	if attempt := t.LastAttempt(); attempt != nil {
+		delivery.Duration = time.Duration(attempt.Duration).Seconds()
+	}
+	if t.RequestInfo != nil {
+		delivery.Request.Headers = t.RequestInfo.Headers
+	}
+	if t.ResponseInfo != nil {
+		delivery.Response = &api.HookDeliveryResponse{
+			Status:  t.ResponseInfo.Status,
+			Headers: t.ResponseInfo.Headers,
+			Body:    t.ResponseInfo.Body,
+		}
+	}
+	return delivery
+}
+
 // ToGitHook convert git.Hook to api.GitHook
 func ToGitHook(h *git.Hook) *api.GitHook {
 	return &api.GitHook{
@@ -327,6 +452,21 @@ func ToTeam(team *models.Team) *api.Team {
 	}
 }
 
+// ToRepoTransfer convert a models.RepoTransfer to api.RepoTransfer, its attributes must be loaded
+func ToRepoTransfer(t *models.RepoTransfer, doer *models.User) *api.RepoTransfer {
+	teams := make([]*api.Team, len(t.Teams))
+	for i := range t.Teams {
+		teams[i] = ToTeam(t.Teams[i])
+	}
+
+	return &api.RepoTransfer{
+		Doer:      ToUser(t.Doer, doer != nil, doer != nil && (doer.IsAdmin || doer.ID == t.DoerID)),
+		Recipient: ToUser(t.Recipient, doer != nil, doer != nil && (doer.IsAdmin || doer.ID == t.RecipientID)),
+		Teams:     teams,
+		Created:   t.CreatedUnix.AsTime(),
+	}
+}
+
 // ToUser convert models.User to api.User
 // signed shall only be set if requester is logged in. authed shall only be set if user is site admin or user himself
 func ToUser(user *models.User, signed, authed bool) *api.User {
@@ -359,7 +499,7 @@ func ToAnnotatedTag(repo *models.Repository, t *git.Tag, c *git.Commit) *api.Ann
 		Message:      t.Message,
 		URL:          util.URLJoin(repo.APIURL(), "git/tags", t.ID.String()),
 		Tagger:       ToCommitUser(t.Tagger),
-		Verification: ToVerification(c),
+		Verification: ToVerification(repo, c),
 	}
 }
 
@@ -59,6 +59,7 @@ func ToAPIPullRequest(pr *models.PullRequest) *api.PullRequest {
 		DiffURL:   pr.Issue.DiffURL(),
 		PatchURL:  pr.Issue.PatchURL(),
 		HasMerged: pr.HasMerged,
+		Draft:     pr.IsDraft,
 		MergeBase: pr.MergeBase,
 		Deadline:  apiIssue.Deadline,
 		Created:   pr.Issue.CreatedUnix.AsTimePtr(),
@@ -146,3 +147,18 @@ func ToAPIPullRequest(pr *models.PullRequest) *api.PullRequest {
 
 	return apiPullRequest
 }
+
+// ToPullRequestMergeQueueEntry converts the entry of a pull request in its merge queue at the position to its API format
+// Required - Doer
+func ToPullRequestMergeQueueEntry(entry *models.MergeQueueEntry, position int64) *api.PullRequestMergeQueueEntry {
+	return &api.PullRequestMergeQueueEntry{
+		Position:      position,
+		BaseBranch:    entry.BaseBranch,
+		Merger:        entry.Doer.APIFormat(),
+		MergeStyle:    string(entry.MergeStyle),
+		Testing:       entry.IsTesting(),
+		TestBranch:    entry.TestBranch,
+		TestCommitSHA: entry.TestCommitID,
+		Added:         entry.CreatedUnix.AsTime(),
+	}
+}
@@ -91,33 +91,66 @@ func ToPullReviewCommentList(review *models.Review, doer *models.User) ([]*api.P
 	for _, lines := range review.CodeComments {
 		for _, comments := range lines {
 			for _, comment := range comments {
-				apiComment := &api.PullReviewComment{
-					ID:           comment.ID,
-					Body:         comment.Content,
-					Reviewer:     ToUser(review.Reviewer, doer != nil, auth),
-					ReviewID:     review.ID,
-					Created:      comment.CreatedUnix.AsTime(),
-					Updated:      comment.UpdatedUnix.AsTime(),
-					Path:         comment.TreePath,
-					CommitID:     comment.CommitSHA,
-					OrigCommitID: comment.OldRef,
-					DiffHunk:     patch2diff(comment.Patch),
-					HTMLURL:      comment.HTMLURL(),
-					HTMLPullURL:  review.Issue.HTMLURL(),
-				}
-
-				if comment.Line < 0 {
-					apiComment.OldLineNum = comment.UnsignedLine()
-				} else {
-					apiComment.LineNum = comment.UnsignedLine()
-				}
-				apiComments = append(apiComments, apiComment)
+				apiComments = append(apiComments, toPullReviewComment(comment, review.Reviewer, review.Issue, doer != nil, auth))
 			}
 		}
 	}
 	return apiComments, nil
 }
 
+// ToPullReviewThread convert a code conversation of a pull request to api format
+func ToPullReviewThread(conv models.CodeConversation, doer *models.User) *api.PullReviewThread {
+	first := conv[0]
+	thread := &api.PullReviewThread{
+		ID:       first.ID,
+		Path:     first.TreePath,
+		Outdated: first.Invalidated,
+		Resolved: conv.IsResolved(),
+		Comments: make([]*api.PullReviewComment, 0, len(conv)),
+	}
+	if first.Line < 0 {
+		thread.OldLineNum = first.UnsignedLine()
+	} else {
+		thread.LineNum = first.UnsignedLine()
+	}
+	if thread.Resolved {
+		thread.ResolvedBy = ToUser(first.ResolveDoer, doer != nil, doer != nil && doer.IsAdmin)
+	}
+
+	for _, comment := range conv {
+		auth := false
+		if doer != nil {
+			auth = doer.IsAdmin || doer.ID == comment.PosterID
+		}
+		thread.Comments = append(thread.Comments, toPullReviewComment(comment, comment.Poster, comment.Issue, doer != nil, auth))
+	}
+	return thread
+}
+
+func toPullReviewComment(comment *models.Comment, reviewer *models.User, issue *models.Issue, signed, authed bool) *api.PullReviewComment {
+	apiComment := &api.PullReviewComment{
+		ID:           comment.ID,
+		Body:         comment.Content,
+		Reviewer:     ToUser(reviewer, signed, authed),
+		ReviewID:     comment.ReviewID,
+		Created:      comment.CreatedUnix.AsTime(),
+		Updated:      comment.UpdatedUnix.AsTime(),
+		Path:         comment.TreePath,
+		CommitID:     comment.CommitSHA,
+		OrigCommitID: comment.OldRef,
+		DiffHunk:     patch2diff(comment.Patch),
+		HTMLURL:      comment.HTMLURL(),
+		HTMLPullURL:  issue.HTMLURL(),
+	}
+
+	if comment.Line < 0 {
+		apiComment.OldLineNum = comment.UnsignedLine()
+	} else {
+		apiComment.LineNum = comment.UnsignedLine()
+	}
+	return apiComment
+}
+
 func patch2diff(patch string) string {
 	split := strings.Split(patch, "\n@@")
 	if len(split) == 2 {
@@ -11,6 +11,7 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/migrations"
 	repository_service "code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/services/archiver"
 	mirror_service "code.gitea.io/gitea/services/mirror"
 )
 
@@ -68,6 +69,24 @@ func registerArchiveCleanup() {
 	})
 }
 
+func registerArchiveEviction() {
+	type ArchiveEvictionConfig struct {
+		BaseConfig
+		MaxSize int64
+	}
+	RegisterTaskFatal("archive_eviction", &ArchiveEvictionConfig{
+		BaseConfig: BaseConfig{
+			Enabled:    true,
+			RunAtStart: false,
+			Schedule:   "@every 1h",
+		},
+		MaxSize: 10 * 1024 * 1024 * 1024,
+	}, func(ctx context.Context, _ *models.User, config Config) error {
+		aeConfig := config.(*ArchiveEvictionConfig)
+		return archiver.EvictRepositoryArchives(ctx, aeConfig.MaxSize)
+	})
+}
+
 func registerSyncExternalUsers() {
 	RegisterTaskFatal("sync_external_users", &UpdateExistingConfig{
 		BaseConfig: BaseConfig{
@@ -97,6 +116,28 @@ func registerDeletedBranchesCleanup() {
 	})
 }
 
+func registerHookTaskCleanup() {
+	type HookTaskCleanupConfig struct {
+		OlderThanConfig
+		NumberToKeep int
+	}
+	RegisterTaskFatal("cleanup_hook_tasks", &HookTaskCleanupConfig{
+		OlderThanConfig: OlderThanConfig{
+			BaseConfig: BaseConfig{
+				Enabled:    true,
+				RunAtStart: false,
+				Schedule:   "@midnight",
+			},
+			OlderThan: 168 * time.Hour,
+		},
+		NumberToKeep: 0,
+	}, func(ctx context.Context, _ *models.User, config Config) error {
+		realConfig := config.(*HookTaskCleanupConfig)
+		_, err := models.DeleteDeliveredHookTasks(ctx, realConfig.OlderThan, realConfig.NumberToKeep)
+		return err
+	})
+}
+
 func registerUpdateMigrationPosterID() {
 	RegisterTaskFatal("update_migration_poster_id", &BaseConfig{
 		Enabled:    true,
@@ -107,12 +148,25 @@ func registerUpdateMigrationPosterID() {
 	})
 }
 
+func registerDeleteExpiredAttachmentUploads() {
+	RegisterTaskFatal("delete_expired_attachment_uploads", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: true,
+		Schedule:   "@every 1h",
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return models.DeleteExpiredAttachmentUploads(ctx)
+	})
+}
+
 func initBasicTasks() {
 	registerUpdateMirrorTask()
 	registerRepoHealthCheck()
 	registerCheckRepoStats()
 	registerArchiveCleanup()
+	registerArchiveEviction()
 	registerSyncExternalUsers()
 	registerDeletedBranchesCleanup()
 	registerUpdateMigrationPosterID()
+	registerHookTaskCleanup()
+	registerDeleteExpiredAttachmentUploads()
 }
@@ -11,6 +11,7 @@ import (
 	"code.gitea.io/gitea/models"
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/task"
 )
 
 func registerDeleteInactiveUsers() {
@@ -57,6 +58,62 @@ func registerGarbageCollectRepositories() {
 	})
 }
 
+func registerGarbageCollectLFSMetaObjects() {
+	type GarbageCollectLFSConfig struct {
+		OlderThanConfig
+		DryRun bool
+	}
+	RegisterTaskFatal("gc_lfs", &GarbageCollectLFSConfig{
+		OlderThanConfig: OlderThanConfig{
+			BaseConfig: BaseConfig{
+				Enabled:    false,
+				RunAtStart: false,
+				Schedule:   "@every 24h",
+			},
+			OlderThan: 168 * time.Hour,
+		},
+		DryRun: false,
+	}, func(ctx context.Context, _ *models.User, config Config) error {
+		if !setting.LFS.StartServer {
+			return nil
+		}
+		gcConfig := config.(*GarbageCollectLFSConfig)
+		return repo_module.GarbageCollectLFSMetaObjects(ctx, repo_module.GarbageCollectLFSMetaObjectsOptions{
+			OlderThan: gcConfig.OlderThan,
+			DryRun:    gcConfig.DryRun,
+		})
+	})
+}
+
+func registerRepositoryMaintenance() {
+	type RepoMaintenanceConfig struct {
+		BaseConfig
+		Interval     time.Duration
+		MinSize      int64
+		LooseObjects int64
+		Packs        int64
+	}
+	RegisterTaskFatal("repo_maintenance", &RepoMaintenanceConfig{
+		BaseConfig: BaseConfig{
+			Enabled:    false,
+			RunAtStart: false,
+			Schedule:   "@every 24h",
+		},
+		Interval:     168 * time.Hour,
+		MinSize:      1024 * 1024,
+		LooseObjects: 1000,
+		Packs:        50,
+	}, func(ctx context.Context, doer *models.User, config Config) error {
+		rmConfig := config.(*RepoMaintenanceConfig)
+		return task.ScheduleMaintenance(ctx, doer, task.MaintenanceOptions{
+			Interval:     rmConfig.Interval,
+			MinSize:      rmConfig.MinSize,
+			LooseObjects: rmConfig.LooseObjects,
+			Packs:        rmConfig.Packs,
+		})
+	})
+}
+
 func registerRewriteAllPublicKeys() {
 	RegisterTaskFatal("resync_all_sshkeys", &BaseConfig{
 		Enabled:    false,
@@ -111,6 +168,8 @@ func initExtendedTasks() {
 	registerDeleteInactiveUsers()
 	registerDeleteRepositoryArchives()
 	registerGarbageCollectRepositories()
+	registerGarbageCollectLFSMetaObjects()
+	registerRepositoryMaintenance()
 	registerRewriteAllPublicKeys()
 	registerRepositoryUpdateHook()
 	registerReinitMissingRepositories()
@@ -12,14 +12,16 @@ import (
 type Manager struct {
 	mutex sync.Mutex
 
-	messengers map[int64]*Messenger
+	messengers     map[int64]*Messenger
+	repoMessengers map[int64]*Messenger
 }
 
 var manager *Manager
 
 func init() {
 	manager = &Manager{
-		messengers: make(map[int64]*Messenger),
+		messengers:     make(map[int64]*Messenger),
+		repoMessengers: make(map[int64]*Messenger),
 	}
 }
 
@@ -61,6 +63,10 @@ func (m *Manager) UnregisterAll() {
 		messenger.UnregisterAll()
 	}
 	m.messengers = map[int64]*Messenger{}
+	for _, messenger := range m.repoMessengers {
+		messenger.UnregisterAll()
+	}
+	m.repoMessengers = map[int64]*Messenger{}
 }
 
 // SendMessage sends a message to a particular user
@@ -82,3 +88,47 @@ func (m *Manager) SendMessageBlocking(uid int64, message *Event) {
 		messenger.SendMessageBlocking(message)
 	}
 }
+
+// RegisterRepo registers a message channel for the events of a repository
+func (m *Manager) RegisterRepo(repoID int64) <-chan *Event {
+	m.mutex.Lock()
+	messenger, ok := m.repoMessengers[repoID]
+	if !ok {
+		messenger = NewMessenger(repoID)
+		messenger.bufferSize = repoEventBufferSize
+		m.repoMessengers[repoID] = messenger
+	}
+	m.mutex.Unlock()
+	return messenger.Register()
+}
+
+// UnregisterRepo removes a message channel for the events of a repository
+func (m *Manager) UnregisterRepo(repoID int64, channel <-chan *Event) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	messenger, ok := m.repoMessengers[repoID]
+	if !ok {
+		return
+	}
+	if messenger.Unregister(channel) {
+		delete(m.repoMessengers, repoID)
+	}
+}
+
+// HasRepoListeners returns whether anyone listens to the events of a repository
+func (m *Manager) HasRepoListeners(repoID int64) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	_, ok := m.repoMessengers[repoID]
+	return ok
+}
+
+// SendRepoMessage sends a message to the listeners of a repository
+func (m *Manager) SendRepoMessage(repoID int64, message *Event) {
+	m.mutex.Lock()
+	messenger, ok := m.repoMessengers[repoID]
+	m.mutex.Unlock()
+	if ok {
+		messenger.SendMessage(message)
+	}
+}
@@ -6,18 +6,24 @@ package eventsource
 
 import "sync"
 
+// repoEventBufferSize is the number of repository events queued for a listener,
+// as a single push or merge emits several events at once
+const repoEventBufferSize = 10
+
 // Messenger is a per uid message store
 type Messenger struct {
-	mutex    sync.Mutex
-	uid      int64
-	channels []chan *Event
+	mutex      sync.Mutex
+	uid        int64
+	bufferSize int
+	channels   []chan *Event
 }
 
 // NewMessenger creates a messenger for a particular uid
 func NewMessenger(uid int64) *Messenger {
 	return &Messenger{
-		uid:      uid,
-		channels: [](chan *Event){},
+		uid:        uid,
+		bufferSize: 1,
+		channels:   [](chan *Event){},
 	}
 }
 
@@ -25,7 +31,7 @@ func NewMessenger(uid int64) *Messenger {
 func (m *Messenger) Register() <-chan *Event {
 	m.mutex.Lock()
 	// TODO: Limit the number of messengers per uid
-	channel := make(chan *Event, 1)
+	channel := make(chan *Event, m.bufferSize)
 	m.channels = append(m.channels, channel)
 	m.mutex.Unlock()
 	return channel
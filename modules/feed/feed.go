@@ -0,0 +1,202 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package feed
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+const (
+	// RSSContentType is the content type of a RSS 2.0 feed
+	RSSContentType = "application/rss+xml;charset=utf-8"
+	// AtomContentType is the content type of an Atom feed
+	AtomContentType = "application/atom+xml;charset=utf-8"
+)
+
+// Feed represents a syndication feed which can be rendered as RSS or Atom
+type Feed struct {
+	ID          string
+	Title       string
+	Link        string
+	Description string
+	Updated     time.Time
+	Items       []*Item
+}
+
+// Item represents a single entry of a feed
+type Item struct {
+	ID     string
+	Title  string
+	Link   string
+	Author string
+	// Content is the HTML content of the item
+	Content string
+	Created time.Time
+	Updated time.Time
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	XMLName       xml.Name  `xml:"channel"`
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	XMLName     xml.Name `xml:"item"`
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	Author      string   `xml:"author,omitempty"`
+	GUID        string   `xml:"guid"`
+	PubDate     string   `xml:"pubDate,omitempty"`
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	XMLNS    string      `xml:"xmlns,attr"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Link     atomLink    `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	XMLName xml.Name `xml:"link"`
+	Href    string   `xml:"href,attr"`
+	Rel     string   `xml:"rel,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	XMLName xml.Name `xml:"author"`
+	Name    string   `xml:"name"`
+}
+
+type atomContent struct {
+	XMLName xml.Name `xml:"content"`
+	Type    string   `xml:"type,attr"`
+	Body    string   `xml:",chardata"`
+}
+
+type atomEntry struct {
+	XMLName   xml.Name    `xml:"entry"`
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Updated   string      `xml:"updated"`
+	Published string      `xml:"published,omitempty"`
+	Link      atomLink    `xml:"link"`
+	Author    *atomAuthor `xml:"author"`
+	Content   atomContent `xml:"content"`
+}
+
+func formatRSSTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC1123Z)
+}
+
+func formatAtomTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// latest returns the updated time of the item, falling back to its creation time
+func (item *Item) latest() time.Time {
+	if item.Updated.IsZero() {
+		return item.Created
+	}
+	return item.Updated
+}
+
+// updated returns the updated time of the feed, computed from its items if not set
+func (f *Feed) updated() time.Time {
+	if !f.Updated.IsZero() {
+		return f.Updated
+	}
+	var updated time.Time
+	for _, item := range f.Items {
+		if t := item.latest(); t.After(updated) {
+			updated = t
+		}
+	}
+	return updated
+}
+
+// RSS renders the feed as RSS 2.0 document
+func (f *Feed) RSS() ([]byte, error) {
+	channel := rssChannel{
+		Title:         f.Title,
+		Link:          f.Link,
+		Description:   f.Description,
+		LastBuildDate: formatRSSTime(f.updated()),
+		Items:         make([]rssItem, 0, len(f.Items)),
+	}
+	for _, item := range f.Items {
+		channel.Items = append(channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Content,
+			Author:      item.Author,
+			GUID:        item.ID,
+			PubDate:     formatRSSTime(item.Created),
+		})
+	}
+
+	data, err := xml.MarshalIndent(&rssFeed{Version: "2.0", Channel: channel}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// Atom renders the feed as Atom document
+func (f *Feed) Atom() ([]byte, error) {
+	feed := atomFeed{
+		XMLNS:    "http://www.w3.org/2005/Atom",
+		ID:       f.ID,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Updated:  formatAtomTime(f.updated()),
+		Link:     atomLink{Href: f.Link, Rel: "alternate"},
+		Entries:  make([]atomEntry, 0, len(f.Items)),
+	}
+	if feed.ID == "" {
+		feed.ID = f.Link
+	}
+	for _, item := range f.Items {
+		entry := atomEntry{
+			ID:        item.ID,
+			Title:     item.Title,
+			Updated:   formatAtomTime(item.latest()),
+			Published: formatAtomTime(item.Created),
+			Link:      atomLink{Href: item.Link, Rel: "alternate"},
+			Content:   atomContent{Type: "html", Body: item.Content},
+		}
+		if item.Author != "" {
+			entry.Author = &atomAuthor{Name: item.Author}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	data, err := xml.MarshalIndent(&feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
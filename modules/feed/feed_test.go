@@ -0,0 +1,67 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package feed
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testFeed() *Feed {
+	created := time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)
+	return &Feed{
+		Title:       "user2/repo1 releases",
+		Link:        "https://try.gitea.io/user2/repo1/releases",
+		Description: "Releases of user2/repo1",
+		Items: []*Item{
+			{
+				ID:      "https://try.gitea.io/user2/repo1/releases/tag/v1.1",
+				Title:   "v1.1",
+				Link:    "https://try.gitea.io/user2/repo1/releases/tag/v1.1",
+				Author:  "user2",
+				Content: "<p>first <b>release</b></p>",
+				Created: created,
+				Updated: created.Add(time.Hour),
+			},
+		},
+	}
+}
+
+func TestFeed_RSS(t *testing.T) {
+	data, err := testFeed().RSS()
+	assert.NoError(t, err)
+
+	var rss rssFeed
+	assert.NoError(t, xml.Unmarshal(data, &rss))
+	assert.Equal(t, "2.0", rss.Version)
+	assert.Equal(t, "user2/repo1 releases", rss.Channel.Title)
+	assert.Equal(t, "Fri, 01 May 2020 11:00:00 +0000", rss.Channel.LastBuildDate)
+	if assert.Len(t, rss.Channel.Items, 1) {
+		item := rss.Channel.Items[0]
+		assert.Equal(t, "v1.1", item.Title)
+		assert.Equal(t, "<p>first <b>release</b></p>", item.Description)
+		assert.Equal(t, "Fri, 01 May 2020 10:00:00 +0000", item.PubDate)
+	}
+}
+
+func TestFeed_Atom(t *testing.T) {
+	data, err := testFeed().Atom()
+	assert.NoError(t, err)
+
+	var atom atomFeed
+	assert.NoError(t, xml.Unmarshal(data, &atom))
+	assert.Equal(t, "https://try.gitea.io/user2/repo1/releases", atom.ID)
+	assert.Equal(t, "2020-05-01T11:00:00Z", atom.Updated)
+	if assert.Len(t, atom.Entries, 1) {
+		entry := atom.Entries[0]
+		assert.Equal(t, "v1.1", entry.Title)
+		assert.Equal(t, "2020-05-01T10:00:00Z", entry.Published)
+		assert.Equal(t, "html", entry.Content.Type)
+		assert.Equal(t, "user2", entry.Author.Name)
+	}
+}
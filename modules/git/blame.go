@@ -12,6 +12,8 @@ import (
 	"os"
 	"os/exec"
 	"regexp"
+	"strconv"
+	"strings"
 
 	"code.gitea.io/gitea/modules/process"
 )
@@ -25,7 +27,6 @@ type BlamePart struct {
 // BlameReader returns part of file blame one by one
 type BlameReader struct {
 	cmd     *exec.Cmd
-	pid     int64
 	output  io.ReadCloser
 	scanner *bufio.Scanner
 	lastSha *string
@@ -78,7 +79,6 @@ func (r *BlameReader) NextPart() (*BlamePart, error) {
 
 // Close BlameReader - don't run NextPart after invoking that
 func (r *BlameReader) Close() error {
-	defer process.GetManager().Remove(r.pid)
 	defer r.cancel()
 
 	if err := r.cmd.Wait(); err != nil {
@@ -88,20 +88,38 @@ func (r *BlameReader) Close() error {
 	return nil
 }
 
-// CreateBlameReader creates reader for given repository, commit and file
-func CreateBlameReader(repoPath, commitID, file string) (*BlameReader, error) {
+// CreateBlameReader creates reader for given repository, commit and file.
+// git blame is killed once the context is done, like when the request has been abandoned.
+func CreateBlameReader(ctx context.Context, repoPath, commitID, file string) (*BlameReader, error) {
 	gitRepo, err := OpenRepository(repoPath)
 	if err != nil {
 		return nil, err
 	}
 	gitRepo.Close()
 
-	return createBlameReader(repoPath, GitExecutable, "blame", commitID, "--porcelain", "--", file)
+	return createBlameReader(ctx, repoPath, GitExecutable, "blame", commitID, "--porcelain", "--", file)
 }
 
-func createBlameReader(dir string, command ...string) (*BlameReader, error) {
-	// FIXME: graceful: This should have a timeout
-	ctx, cancel := context.WithCancel(DefaultContext)
+func createBlameReader(ctx context.Context, dir string, command ...string) (*BlameReader, error) {
+	cmd, stdout, cancel, err := startBlame(ctx, dir, command...)
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+
+	return &BlameReader{
+		cmd,
+		stdout,
+		scanner,
+		nil,
+		cancel,
+	}, nil
+}
+
+// startBlame starts the blame command and returns its output, it is killed once the context is done
+func startBlame(ctx context.Context, dir string, command ...string) (*exec.Cmd, io.ReadCloser, context.CancelFunc, error) {
+	ctx, cancel, _ := process.GetManager().AddContextTimeout(ctx, OperationTimeout(OperationBlame), fmt.Sprintf("GetBlame [repo_path: %s]", dir))
 	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
 	cmd.Dir = dir
 	cmd.Stderr = os.Stderr
@@ -109,24 +127,89 @@ func createBlameReader(dir string, command ...string) (*BlameReader, error) {
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		defer cancel()
-		return nil, fmt.Errorf("StdoutPipe: %v", err)
+		return nil, nil, nil, fmt.Errorf("StdoutPipe: %v", err)
 	}
 
 	if err = cmd.Start(); err != nil {
 		defer cancel()
-		return nil, fmt.Errorf("Start: %v", err)
+		return nil, nil, nil, fmt.Errorf("Start: %v", err)
 	}
+	return cmd, stdout, cancel, nil
+}
 
-	pid := process.GetManager().Add(fmt.Sprintf("GetBlame [repo_path: %s]", dir), cancel)
+// BlameEntry represents a range of lines of a file and the commit which last changed them
+type BlameEntry struct {
+	Sha string
+	// StartLine is the number of the first line of the range, starting at 1
+	StartLine int
+	NumLines  int
+}
 
-	scanner := bufio.NewScanner(stdout)
+// BlameIncrementalReader returns the entries of the blame of a file one by one as soon as git finds them,
+// which isn't in the order of the lines. Only the entries are kept in memory, not the lines of the file.
+type BlameIncrementalReader struct {
+	cmd     *exec.Cmd
+	scanner *bufio.Scanner
+	cancel  context.CancelFunc
+}
 
-	return &BlameReader{
-		cmd,
-		pid,
-		stdout,
-		scanner,
-		nil,
-		cancel,
+var blameEntryHeaderRegex = regexp.MustCompile(`^([0-9a-f]{40,64}) (\d+) (\d+) (\d+)$`)
+
+// NextEntry returns the next entry of the blame, nil once all of them have been read
+func (r *BlameIncrementalReader) NextEntry() (*BlameEntry, error) {
+	var entry *BlameEntry
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+		if entry == nil {
+			match := blameEntryHeaderRegex.FindStringSubmatch(line)
+			if match == nil {
+				return nil, fmt.Errorf("invalid blame entry header: %q", line)
+			}
+			startLine, _ := strconv.Atoi(match[3])
+			numLines, _ := strconv.Atoi(match[4])
+			entry = &BlameEntry{Sha: match[1], StartLine: startLine, NumLines: numLines}
+			continue
+		}
+		// The headers of an entry, with the info of its commit the first time it appears, always end with its filename
+		if strings.HasPrefix(line, "filename ") {
+			return entry, nil
+		}
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	if entry != nil {
+		return nil, fmt.Errorf("incomplete blame entry of %s", entry.Sha)
+	}
+	return nil, nil
+}
+
+// Close BlameIncrementalReader - don't run NextEntry after invoking that
+func (r *BlameIncrementalReader) Close() error {
+	defer r.cancel()
+
+	if err := r.cmd.Wait(); err != nil {
+		return fmt.Errorf("Wait: %v", err)
+	}
+
+	return nil
+}
+
+// CreateBlameIncrementalReader creates an incremental reader of the blame of the file at the given commit of the repository.
+// git blame is killed once the context is done, like when the request has been abandoned.
+func CreateBlameIncrementalReader(ctx context.Context, repoPath, commitID, file string) (*BlameIncrementalReader, error) {
+	return createBlameIncrementalReader(ctx, repoPath, GitExecutable, "blame", "--incremental", commitID, "--", file)
+}
+
+func createBlameIncrementalReader(ctx context.Context, dir string, command ...string) (*BlameIncrementalReader, error) {
+	cmd, stdout, cancel, err := startBlame(ctx, dir, command...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BlameIncrementalReader{
+		cmd:     cmd,
+		scanner: bufio.NewScanner(stdout),
+		cancel:  cancel,
 	}, nil
 }
@@ -5,6 +5,7 @@
 package git
 
 import (
+	"context"
 	"io/ioutil"
 	"testing"
 
@@ -94,7 +95,7 @@ func TestReadingBlameOutput(t *testing.T) {
 		panic(err)
 	}
 
-	blameReader, err := createBlameReader("", "cat", tempFile.Name())
+	blameReader, err := createBlameReader(context.Background(), "", "cat", tempFile.Name())
 	if err != nil {
 		panic(err)
 	}
@@ -139,3 +140,76 @@ func TestReadingBlameOutput(t *testing.T) {
 		assert.Equal(t, part, actualPart)
 	}
 }
+
+const exampleIncrementalBlame = `e2aa991e10ffd924a828ec149951f2f20eecead2 6 6 2
+author Lunny Xiao
+author-mail <xiaolunwen@gmail.com>
+author-time 1478872595
+author-tz +0800
+committer Sandro Santilli
+committer-mail <strk@kbt.io>
+committer-time 1478872595
+committer-tz +0100
+summary ask for go get from code.gitea.io/gitea and change gogs to gitea on main file (#146)
+previous 5fc370e332171b8658caed771b48585576f11737 main.go
+filename main.go
+ce21ed6c3490cdfad797319cbb1145e2330a8fef 2 2 1
+author Joubert RedRat
+author-mail <eu+github@redrat.com.br>
+author-time 1482322397
+author-tz -0200
+committer Lunny Xiao
+committer-mail <xiaolunwen@gmail.com>
+committer-time 1482322397
+committer-tz +0800
+summary Remove remaining Gogs reference on locales and cmd (#430)
+previous 618407c018cdf668ceedde7454c42fb22ba422d8 main.go
+filename main.go
+4b92a6c2df28054ad766bc262f308db9f6066596 1 1 1
+author Unknown
+author-mail <joe2010xtmf@163.com>
+author-time 1392833071
+author-tz -0500
+committer Unknown
+committer-mail <joe2010xtmf@163.com>
+committer-time 1392833071
+committer-tz -0500
+summary Add code of delete user
+boundary
+filename gogs.go
+4b92a6c2df28054ad766bc262f308db9f6066596 2 3 3
+filename gogs.go
+`
+
+func TestReadingIncrementalBlameOutput(t *testing.T) {
+	tempFile, err := ioutil.TempFile("", ".txt")
+	if err != nil {
+		panic(err)
+	}
+
+	defer tempFile.Close()
+
+	if _, err = tempFile.WriteString(exampleIncrementalBlame); err != nil {
+		panic(err)
+	}
+
+	blameReader, err := createBlameIncrementalReader(context.Background(), "", "cat", tempFile.Name())
+	if err != nil {
+		panic(err)
+	}
+	defer blameReader.Close()
+
+	entries := []*BlameEntry{
+		{"e2aa991e10ffd924a828ec149951f2f20eecead2", 6, 2},
+		{"ce21ed6c3490cdfad797319cbb1145e2330a8fef", 2, 1},
+		{"4b92a6c2df28054ad766bc262f308db9f6066596", 1, 1},
+		{"4b92a6c2df28054ad766bc262f308db9f6066596", 3, 3},
+		nil,
+	}
+
+	for _, entry := range entries {
+		actualEntry, err := blameReader.NextEntry()
+		assert.NoError(t, err)
+		assert.Equal(t, entry, actualEntry)
+	}
+}
@@ -20,14 +20,42 @@ type Blob struct {
 
 	gogitEncodedObj plumbing.EncodedObject
 	name            string
+	repoPath        string
 }
 
 // DataAsync gets a ReadCloser for the contents of a blob without reading it all.
-// Calling the Close function on the result will discard all unread output.
+// Calling the Close function on the result will discard all unread output, or stop the
+// git process reading it if a lot hasn't been read.
 func (b *Blob) DataAsync() (io.ReadCloser, error) {
+	if b.repoPath != "" {
+		// git reads large blobs of packs a lot faster than go-git
+		batch, err := GetCatFileBatch(b.repoPath)
+		if err == nil {
+			var reader io.Reader
+			if _, reader, err = batch.Contents(b.ID.String()); err == nil {
+				return &blobReader{Reader: reader, batch: batch}, nil
+			}
+			batch.Release()
+		}
+		log("Unable to read blob %s with git cat-file: %v", b.ID, err)
+	}
 	return b.gogitEncodedObj.Reader()
 }
 
+// blobReader reads a blob from a cat-file process, which is put back into the pool on Close
+type blobReader struct {
+	io.Reader
+	batch *CatFileBatch
+}
+
+func (r *blobReader) Close() error {
+	if r.batch != nil {
+		r.batch.Release()
+		r.batch = nil
+	}
+	return nil
+}
+
 // Size returns the uncompressed size of the blob
 func (b *Blob) Size() int64 {
 	return b.gogitEncodedObj.Size()
@@ -0,0 +1,299 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// catFileBatchMaxIdle is the number of idle cat-file processes kept for every repository and mode
+	catFileBatchMaxIdle = 4
+	// catFileBatchIdleTimeout is how long an idle cat-file process is kept
+	catFileBatchIdleTimeout = time.Minute
+	// catFileBatchMaxAge is how long a cat-file process is reused, it is killed after catFileBatchTimeout
+	catFileBatchMaxAge  = 10 * time.Minute
+	catFileBatchTimeout = time.Hour
+	// catFileBatchMaxDiscard is the most unread bytes of an object skipped to read the next one with the same
+	// process, the process is replaced instead of reading the rest of larger objects
+	catFileBatchMaxDiscard = 64 * 1024
+)
+
+// ObjectInfo is the type and the size of an object read by git cat-file
+type ObjectInfo struct {
+	ID   SHA1
+	Type ObjectType
+	Size int64
+}
+
+// CatFileBatch is a git cat-file --batch or --batch-check process reading the objects of a repository
+// one after the other. It is taken from a pool of running processes and has to be put back with Release.
+type CatFileBatch struct {
+	repoPath string
+	// the repository directory the process has been started in, which may have been replaced since
+	dir     os.FileInfo
+	check   bool
+	started time.Time
+	idle    time.Time
+
+	cancel context.CancelFunc
+	stdin  *io.PipeWriter
+	stdout *bufio.Reader
+	// the number of bytes of the last object which haven't been read yet, with its trailing newline
+	remaining int64
+	broken    bool
+}
+
+type catFileBatchKey struct {
+	repoPath string
+	check    bool
+}
+
+var catFileBatchPool = struct {
+	sync.Mutex
+	idle map[catFileBatchKey][]*CatFileBatch
+}{
+	idle: make(map[catFileBatchKey][]*CatFileBatch),
+}
+
+// GetCatFileBatch returns a git cat-file --batch process of the repository reading the contents of objects
+func GetCatFileBatch(repoPath string) (*CatFileBatch, error) {
+	return getCatFileBatch(repoPath, false)
+}
+
+// GetCatFileBatchCheck returns a git cat-file --batch-check process of the repository reading the type and size of objects
+func GetCatFileBatchCheck(repoPath string) (*CatFileBatch, error) {
+	return getCatFileBatch(repoPath, true)
+}
+
+func getCatFileBatch(repoPath string, check bool) (*CatFileBatch, error) {
+	dir, err := os.Stat(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	key := catFileBatchKey{repoPath, check}
+	catFileBatchPool.Lock()
+	for idle := catFileBatchPool.idle[key]; len(idle) > 0; idle = catFileBatchPool.idle[key] {
+		b := idle[len(idle)-1]
+		catFileBatchPool.idle[key] = idle[:len(idle)-1]
+		// the repository may have been deleted and created again since the process has been started
+		if !os.SameFile(b.dir, dir) {
+			b.Close()
+			continue
+		}
+		catFileBatchPool.Unlock()
+		return b, nil
+	}
+	catFileBatchPool.Unlock()
+
+	return newCatFileBatch(repoPath, dir, check)
+}
+
+func newCatFileBatch(repoPath string, dir os.FileInfo, check bool) (*CatFileBatch, error) {
+	mode := "--batch"
+	if check {
+		mode = "--batch-check"
+	}
+
+	ctx, cancel := context.WithCancel(DefaultContext)
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	b := &CatFileBatch{
+		repoPath: repoPath,
+		dir:      dir,
+		check:    check,
+		started:  time.Now(),
+		cancel:   cancel,
+		stdin:    stdinWriter,
+		stdout:   bufio.NewReader(stdoutReader),
+	}
+
+	go func() {
+		stderr := new(strings.Builder)
+		err := NewCommandContext(ctx, "cat-file", mode).
+			SetDescription(fmt.Sprintf("%s cat-file %s [repo_path: %s]", GitExecutable, mode, repoPath)).
+			RunInDirTimeoutEnvFullPipeline(nil, catFileBatchTimeout, repoPath, stdoutWriter, stderr, stdinReader)
+		if err != nil {
+			err = concatenateError(err, stderr.String())
+		}
+		_ = stdoutWriter.CloseWithError(err)
+		_ = stdinReader.CloseWithError(err)
+	}()
+
+	return b, nil
+}
+
+// Info returns the type and the size of the object of the revision, the process has to be a --batch-check one
+func (b *CatFileBatch) Info(rev string) (*ObjectInfo, error) {
+	if !b.check {
+		return nil, fmt.Errorf("git cat-file --batch can't only read the info of objects")
+	}
+	return b.readInfo(rev)
+}
+
+// Contents returns the type and the size of the object of the revision with a reader of its contents,
+// which is valid until the next object is read. The process has to be a --batch one.
+func (b *CatFileBatch) Contents(rev string) (*ObjectInfo, io.Reader, error) {
+	if b.check {
+		return nil, nil, fmt.Errorf("git cat-file --batch-check can't read the contents of objects")
+	}
+	info, err := b.readInfo(rev)
+	if err != nil {
+		return nil, nil, err
+	}
+	b.remaining = info.Size + 1
+	return info, &catFileBatchReader{b}, nil
+}
+
+func (b *CatFileBatch) readInfo(rev string) (*ObjectInfo, error) {
+	if strings.ContainsAny(rev, "\r\n") {
+		return nil, fmt.Errorf("invalid revision: %q", rev)
+	}
+	if b.remaining > catFileBatchMaxDiscard {
+		if err := b.replace(); err != nil {
+			return nil, err
+		}
+	} else if err := b.discard(); err != nil {
+		return nil, err
+	}
+
+	if _, err := b.stdin.Write([]byte(rev + "\n")); err != nil {
+		b.broken = true
+		return nil, err
+	}
+	header, err := b.stdout.ReadString('\n')
+	if err != nil {
+		b.broken = true
+		return nil, err
+	}
+
+	// <sha> SP <type> SP <size> LF, or <rev> SP missing LF
+	fields := strings.Fields(header)
+	if len(fields) == 2 && (fields[1] == "missing" || fields[1] == "ambiguous") {
+		return nil, ErrNotExist{ID: rev}
+	} else if len(fields) != 3 {
+		b.broken = true
+		return nil, fmt.Errorf("invalid git cat-file output: %q", header)
+	}
+	id, err := NewIDFromString(fields[0])
+	if err != nil {
+		b.broken = true
+		return nil, err
+	}
+	size, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		b.broken = true
+		return nil, err
+	}
+	return &ObjectInfo{
+		ID:   id,
+		Type: ObjectType(fields[1]),
+		Size: size,
+	}, nil
+}
+
+// discard skips what hasn't been read of the last object
+func (b *CatFileBatch) discard() error {
+	for b.remaining > 0 {
+		n := b.remaining
+		if n > 32*1024 {
+			n = 32 * 1024
+		}
+		discarded, err := b.stdout.Discard(int(n))
+		b.remaining -= int64(discarded)
+		if err != nil {
+			b.broken = true
+			return err
+		}
+	}
+	return nil
+}
+
+// replace stops the process and starts a new one, which is faster than reading the rest of a large object
+func (b *CatFileBatch) replace() error {
+	b.Close()
+	replacement, err := newCatFileBatch(b.repoPath, b.dir, b.check)
+	if err != nil {
+		return err
+	}
+	*b = *replacement
+	return nil
+}
+
+// Release puts the process back into the pool, it is stopped if it is broken, enough are idle
+// or too much of the last object hasn't been read
+func (b *CatFileBatch) Release() {
+	if b.broken || b.remaining > catFileBatchMaxDiscard || b.discard() != nil || time.Since(b.started) > catFileBatchMaxAge {
+		b.Close()
+		return
+	}
+
+	key := catFileBatchKey{b.repoPath, b.check}
+	catFileBatchPool.Lock()
+	defer catFileBatchPool.Unlock()
+	if len(catFileBatchPool.idle[key]) >= catFileBatchMaxIdle {
+		b.Close()
+		return
+	}
+	idle := time.Now()
+	b.idle = idle
+	catFileBatchPool.idle[key] = append(catFileBatchPool.idle[key], b)
+
+	time.AfterFunc(catFileBatchIdleTimeout, func() {
+		catFileBatchPool.Lock()
+		defer catFileBatchPool.Unlock()
+		batches := catFileBatchPool.idle[key]
+		for i, batch := range batches {
+			// it may have been taken and put back since
+			if batch == b && b.idle == idle {
+				catFileBatchPool.idle[key] = append(batches[:i], batches[i+1:]...)
+				if len(catFileBatchPool.idle[key]) == 0 {
+					delete(catFileBatchPool.idle, key)
+				}
+				b.Close()
+				return
+			}
+		}
+	})
+}
+
+// Close stops the process instead of putting it back into the pool
+func (b *CatFileBatch) Close() {
+	b.broken = true
+	_ = b.stdin.Close()
+	b.cancel()
+}
+
+type catFileBatchReader struct {
+	b *CatFileBatch
+}
+
+func (r *catFileBatchReader) Read(p []byte) (int, error) {
+	// the contents are followed by a newline
+	if r.b.remaining <= 1 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > r.b.remaining-1 {
+		p = p[:r.b.remaining-1]
+	}
+	n, err := r.b.stdout.Read(p)
+	r.b.remaining -= int64(n)
+	if err != nil {
+		r.b.broken = true
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}
+	return n, err
+}
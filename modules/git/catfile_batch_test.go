@@ -0,0 +1,130 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatFileBatch(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+
+	check, err := GetCatFileBatchCheck(bareRepo1Path)
+	assert.NoError(t, err)
+	info, err := check.Info("master")
+	assert.NoError(t, err)
+	assert.Equal(t, "feaf4ba6bc635fec442f46ddd4512416ec43c2c2", info.ID.String())
+	assert.Equal(t, ObjectCommit, info.Type)
+	_, err = check.Info("not-a-branch")
+	assert.True(t, IsErrNotExist(err))
+	_, _, err = check.Contents("master")
+	assert.Error(t, err)
+	check.Release()
+
+	batch, err := GetCatFileBatch(bareRepo1Path)
+	assert.NoError(t, err)
+	info, reader, err := batch.Contents("master:file1.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, ObjectBlob, info.Type)
+	// the unread contents are skipped
+	_, err = reader.Read(make([]byte, 1))
+	assert.NoError(t, err)
+
+	info, reader, err = batch.Contents("master:file1.txt")
+	assert.NoError(t, err)
+	data, err := ioutil.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.EqualValues(t, info.Size, len(data))
+	assert.Equal(t, "file1\n", string(data))
+	batch.Release()
+
+	// the process is reused
+	reused, err := GetCatFileBatch(bareRepo1Path)
+	assert.NoError(t, err)
+	assert.True(t, reused == batch)
+	_, _, err = reused.Contents("0000000000000000000000000000000000000001")
+	assert.True(t, IsErrNotExist(err))
+	reused.Close()
+}
+
+func TestCatFileBatch_RecreatedRepository(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "repo_catfile")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	repoPath := filepath.Join(tmpDir, "repo.git")
+
+	assert.NoError(t, InitRepository(repoPath, true, Sha1ObjectFormat))
+	check, err := GetCatFileBatchCheck(repoPath)
+	assert.NoError(t, err)
+	check.Release()
+
+	// the process of the deleted repository isn't reused
+	assert.NoError(t, os.RemoveAll(repoPath))
+	assert.NoError(t, InitRepository(repoPath, true, Sha1ObjectFormat))
+	recreated, err := GetCatFileBatchCheck(repoPath)
+	assert.NoError(t, err)
+	assert.False(t, recreated == check)
+	recreated.Close()
+}
+
+func TestCatFileBatch_LargeObject(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "repo_catfile")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	repoPath := filepath.Join(tmpDir, "repo.git")
+	assert.NoError(t, InitRepository(repoPath, true, Sha1ObjectFormat))
+
+	largePath := filepath.Join(tmpDir, "large")
+	assert.NoError(t, ioutil.WriteFile(largePath, bytes.Repeat([]byte("0123456789abcdef"), 1024*1024), 0644))
+	stdout, err := NewCommand("hash-object", "-w", largePath).RunInDir(repoPath)
+	assert.NoError(t, err)
+	largeID := strings.TrimSpace(stdout)
+	smallPath := filepath.Join(tmpDir, "small")
+	assert.NoError(t, ioutil.WriteFile(smallPath, []byte("small\n"), 0644))
+	stdout, err = NewCommand("hash-object", "-w", smallPath).RunInDir(repoPath)
+	assert.NoError(t, err)
+	smallID := strings.TrimSpace(stdout)
+
+	// The process is replaced instead of reading the rest of the large object to read the next one
+	batch, err := GetCatFileBatch(repoPath)
+	assert.NoError(t, err)
+	info, reader, err := batch.Contents(largeID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 16*1024*1024, info.Size)
+	_, err = io.ReadFull(reader, make([]byte, 1024))
+	assert.NoError(t, err)
+	started := batch.started
+	_, reader, err = batch.Contents(smallID)
+	assert.NoError(t, err)
+	assert.True(t, batch.started.After(started))
+	data, err := ioutil.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "small\n", string(data))
+
+	// The process isn't put back into the pool when the large object is closed early
+	_, reader, err = batch.Contents(largeID)
+	assert.NoError(t, err)
+	_, err = io.ReadFull(reader, make([]byte, 1024))
+	assert.NoError(t, err)
+	batch.Release()
+	assert.True(t, batch.broken)
+	next, err := GetCatFileBatch(repoPath)
+	assert.NoError(t, err)
+	assert.False(t, next == batch)
+	_, reader, err = next.Contents(smallID)
+	assert.NoError(t, err)
+	data, err = ioutil.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "small\n", string(data))
+	next.Close()
+}
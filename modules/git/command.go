@@ -110,6 +110,10 @@ func (c *Command) RunInDirTimeoutEnvFullPipeline(env []string, timeout time.Dura
 func (c *Command) RunInDirTimeoutEnvFullPipelineFunc(env []string, timeout time.Duration, dir string, stdout, stderr io.Writer, stdin io.Reader, fn func(context.Context, context.CancelFunc) error) error {
 	if timeout == -1 {
 		timeout = DefaultCommandExecutionTimeout
+		// The commands of an operation share the deadline of its context
+		if deadline, ok := c.parentContext.Deadline(); ok {
+			timeout = time.Until(deadline)
+		}
 	}
 
 	if len(dir) == 0 {
@@ -35,6 +35,9 @@ type Commit struct {
 
 	Parents        []SHA1 // SHA1 strings
 	submoduleCache *ObjectCache
+
+	// ReplacedBy is the ID of the commit replacing this one by a replace ref, whose contents are read instead
+	ReplacedBy SHA1
 }
 
 // CommitGPGSignature represents a git commit signature part.
@@ -98,6 +101,17 @@ func convertCommit(c *object.Commit) *Commit {
 	}
 }
 
+// IsReplaced returns whether the commit is replaced by another one by a replace ref
+func (c *Commit) IsReplaced() bool {
+	return !c.ReplacedBy.IsZero()
+}
+
+// HasReplacements returns whether objects of the repository of the commit are replaced by replace refs,
+// its history may then change without its ID changing
+func (c *Commit) HasReplacements() bool {
+	return c.repo != nil && c.repo.HasReplacements()
+}
+
 // Message returns the commit message. Same as retrieving CommitMessage directly.
 func (c *Commit) Message() string {
 	return c.CommitMessage
@@ -426,27 +440,10 @@ func (c *Commit) GetSubModules() (*ObjectCache, error) {
 	}
 
 	defer rd.Close()
-	scanner := bufio.NewScanner(rd)
-	c.submoduleCache = newObjectCache()
-	var ismodule bool
-	var path string
-	for scanner.Scan() {
-		if strings.HasPrefix(scanner.Text(), "[submodule") {
-			ismodule = true
-			continue
-		}
-		if ismodule {
-			fields := strings.Split(scanner.Text(), "=")
-			k := strings.TrimSpace(fields[0])
-			if k == "path" {
-				path = strings.TrimSpace(fields[1])
-			} else if k == "url" {
-				c.submoduleCache.Set(path, &SubModule{path, strings.TrimSpace(fields[1])})
-				ismodule = false
-			}
-		}
+	c.submoduleCache, err = parseSubModules(rd)
+	if err != nil {
+		return nil, err
 	}
-
 	return c.submoduleCache, nil
 }
 
@@ -6,7 +6,10 @@
 package git
 
 import (
+	"archive/tar"
+	"bytes"
 	"fmt"
+	"io"
 	"path/filepath"
 	"strings"
 )
@@ -58,6 +61,43 @@ func (c *Commit) CreateArchive(target string, opts CreateArchiveOpts) error {
 		c.ID.String(),
 	)
 
-	_, err := NewCommand(args...).RunInDir(c.repo.Path)
+	_, err := NewCommand(args...).RunInDirTimeout(OperationTimeout(OperationArchive), c.repo.Path)
 	return err
 }
+
+// WriteTarArchive writes the files of the commit into tw with the given path prefix.
+// The global header git stores the commit ID in is left out, so the entries of
+// several commits can be combined into one archive.
+func (c *Commit) WriteTarArchive(tw *tar.Writer, prefix string) error {
+	pr, pw := io.Pipe()
+	defer pr.Close()
+
+	go func() {
+		stderr := new(bytes.Buffer)
+		err := NewCommand("archive", "--format=tar", "--prefix="+prefix, c.ID.String()).
+			RunInDirTimeoutPipeline(OperationTimeout(OperationArchive), c.repo.Path, pw, stderr)
+		if err != nil {
+			err = concatenateError(err, stderr.String())
+		}
+		_ = pw.CloseWithError(err)
+	}()
+
+	tr := tar.NewReader(pr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if hdr.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+}
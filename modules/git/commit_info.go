@@ -97,6 +97,7 @@ func (tes Entries) GetCommitsInfo(commit *Commit, treePath string, cache LastCom
 	} else if rev, ok := revs[""]; ok {
 		treeCommit = convertCommit(rev)
 		treeCommit.repo = commit.repo
+		treeCommit.ReplacedBy, _ = commit.repo.GetReplacement(treeCommit.ID)
 	}
 	return commitsInfo, treeCommit, nil
 }
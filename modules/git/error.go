@@ -72,6 +72,21 @@ func (err ErrUnsupportedVersion) Error() string {
 	return fmt.Sprintf("Operation requires higher version [required: %s]", err.Required)
 }
 
+// ErrUnsupportedObjectFormat represents an error if an object format is unknown or not supported by git
+type ErrUnsupportedObjectFormat struct {
+	Name string
+}
+
+// IsErrUnsupportedObjectFormat checks if an error is a ErrUnsupportedObjectFormat.
+func IsErrUnsupportedObjectFormat(err error) bool {
+	_, ok := err.(ErrUnsupportedObjectFormat)
+	return ok
+}
+
+func (err ErrUnsupportedObjectFormat) Error() string {
+	return fmt.Sprintf("object format is not supported [name: %s]", err.Name)
+}
+
 // ErrBranchNotExist represents a "BranchNotExist" kind of error.
 type ErrBranchNotExist struct {
 	Name string
@@ -5,7 +5,10 @@
 package git
 
 import (
+	"bytes"
 	"io/ioutil"
+	"os"
+	"time"
 
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
@@ -21,6 +24,7 @@ type Note struct {
 }
 
 // GetNote retrieves the git-notes data for a given commit.
+// It returns ErrNotExist if the commit has no note.
 func GetNote(repo *Repository, commitID string, note *Note) error {
 	notes, err := repo.GetCommit(NotesRef)
 	if err != nil {
@@ -42,10 +46,16 @@ func GetNote(repo *Repository, commitID string, note *Note) error {
 			path += remainingCommitID[0:2] + "/"
 			remainingCommitID = remainingCommitID[2:]
 		}
+		if err == object.ErrDirectoryNotFound {
+			return ErrNotExist{ID: commitID, RelPath: path}
+		}
 		if err != nil {
 			return err
 		}
 	}
+	if file == nil {
+		return ErrNotExist{ID: commitID, RelPath: path}
+	}
 
 	blob := file.Blob
 	dataRc, err := blob.Reader()
@@ -78,3 +88,24 @@ func GetNote(repo *Repository, commitID string, note *Note) error {
 
 	return nil
 }
+
+// AddNote adds the git-notes data to a given commit, replacing the existing note if any.
+func AddNote(repo *Repository, sig *Signature, commitID string, message []byte) error {
+	commitTimeStr := time.Now().Format(time.RFC3339)
+	env := append(os.Environ(),
+		"GIT_AUTHOR_NAME="+sig.Name,
+		"GIT_AUTHOR_EMAIL="+sig.Email,
+		"GIT_AUTHOR_DATE="+commitTimeStr,
+		"GIT_COMMITTER_NAME="+sig.Name,
+		"GIT_COMMITTER_EMAIL="+sig.Email,
+		"GIT_COMMITTER_DATE="+commitTimeStr,
+	)
+
+	stderr := new(bytes.Buffer)
+	err := NewCommand("notes", "--ref="+NotesRef, "add", "-f", "-F", "-", commitID).
+		RunInDirTimeoutEnvFullPipeline(env, -1, repo.Path, nil, stderr, bytes.NewReader(message))
+	if err != nil {
+		return concatenateError(err, stderr.String())
+	}
+	return nil
+}
@@ -5,6 +5,8 @@
 package git
 
 import (
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -38,3 +40,42 @@ func TestGetNestedNotes(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, []byte("Note 1"), note.Message)
 }
+
+func TestGetNonExistentNote(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	bareRepo1, err := OpenRepository(bareRepo1Path)
+	assert.NoError(t, err)
+	defer bareRepo1.Close()
+
+	note := Note{}
+	err = GetNote(bareRepo1, "feaf4ba6bc635fec442f46ddd4512416ec43c2c2", &note)
+	assert.True(t, IsErrNotExist(err))
+}
+
+func TestAddNote(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "repo1_notes")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	repoPath := filepath.Join(tmpDir, "repo1_bare")
+	assert.NoError(t, Clone(filepath.Join(testReposDir, "repo1_bare"), repoPath, CloneRepoOptions{Mirror: true}))
+	repo, err := OpenRepository(repoPath)
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	sig := &Signature{Name: "Test User", Email: "test@example.com"}
+	commitID := "feaf4ba6bc635fec442f46ddd4512416ec43c2c2"
+	assert.NoError(t, AddNote(repo, sig, commitID, []byte("Build 42\n")))
+
+	note := Note{}
+	assert.NoError(t, GetNote(repo, commitID, &note))
+	assert.Equal(t, []byte("Build 42\n"), note.Message)
+	assert.Equal(t, "Test User", note.Commit.Author.Name)
+
+	// the existing note is replaced and the other notes are kept
+	assert.NoError(t, AddNote(repo, sig, commitID, []byte("Build 43\n")))
+	assert.NoError(t, GetNote(repo, commitID, &note))
+	assert.Equal(t, []byte("Build 43\n"), note.Message)
+	assert.NoError(t, GetNote(repo, "95bb4d39648ee7e325106df01a621c530863a653", &note))
+	assert.Equal(t, []byte("Note contents\n"), note.Message)
+}
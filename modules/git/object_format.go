@@ -0,0 +1,105 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mcuadros/go-version"
+)
+
+// ObjectFormat is the hash algorithm naming the objects of a repository
+type ObjectFormat string
+
+// The object formats of repositories
+const (
+	Sha1ObjectFormat   ObjectFormat = "sha1"
+	Sha256ObjectFormat ObjectFormat = "sha256"
+)
+
+// objectFormatVersionRequired is the minimum Git version able to create and read sha256 repositories
+const objectFormatVersionRequired = "2.29"
+
+// ObjectFormatFromName returns the object format of the name, sha1 if it is empty
+func ObjectFormatFromName(name string) (ObjectFormat, error) {
+	switch format := ObjectFormat(name); format {
+	case "":
+		return Sha1ObjectFormat, nil
+	case Sha1ObjectFormat, Sha256ObjectFormat:
+		return format, nil
+	default:
+		return "", ErrUnsupportedObjectFormat{Name: name}
+	}
+}
+
+// HexLen returns the length of the hexadecimal object IDs of the format
+func (format ObjectFormat) HexLen() int {
+	if format == Sha256ObjectFormat {
+		return 64
+	}
+	return 40
+}
+
+// EmptyObjectID returns the null object ID of the format, which stands for missing refs
+func (format ObjectFormat) EmptyObjectID() string {
+	return strings.Repeat("0", format.HexLen())
+}
+
+// EmptyTree returns the ID of the empty tree in the format
+func (format ObjectFormat) EmptyTree() string {
+	if format == Sha256ObjectFormat {
+		return "6ef19b41225c5369f1c104d45d8d85efa9b057b53b14b4b9b939dd74decc5321"
+	}
+	return EmptyTreeSHA
+}
+
+// IsSupported returns whether repositories of the format can be created and read.
+// Only sha1 is, go-git and the parsing of object IDs don't handle sha256 repositories yet.
+func (format ObjectFormat) IsSupported() bool {
+	return format == Sha1ObjectFormat
+}
+
+// SupportedObjectFormats returns the object formats repositories can be created with
+func SupportedObjectFormats() []ObjectFormat {
+	formats := make([]ObjectFormat, 0, 2)
+	for _, format := range []ObjectFormat{Sha1ObjectFormat, Sha256ObjectFormat} {
+		if format.IsSupported() {
+			formats = append(formats, format)
+		}
+	}
+	return formats
+}
+
+// gitKnowsObjectFormats returns whether the git binary knows other object formats than sha1
+func gitKnowsObjectFormats() bool {
+	gitVersion, err := BinVersion()
+	if err != nil {
+		return false
+	}
+	return version.Compare(gitVersion, objectFormatVersionRequired, ">=")
+}
+
+// IsEmptyCommitID returns whether the ID is empty or the null object ID of any object format,
+// which the hooks receive for created and deleted refs
+func IsEmptyCommitID(id string) bool {
+	if len(id) == 0 {
+		return true
+	}
+	return id == Sha1ObjectFormat.EmptyObjectID() || id == Sha256ObjectFormat.EmptyObjectID()
+}
+
+// GetObjectFormatOfRepo returns the object format of the repository at repoPath
+func GetObjectFormatOfRepo(repoPath string) (ObjectFormat, error) {
+	if !gitKnowsObjectFormats() {
+		// Older versions of git only know sha1 repositories
+		return Sha1ObjectFormat, nil
+	}
+	stdout, err := NewCommand("rev-parse", "--show-object-format").RunInDir(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("rev-parse --show-object-format: %v", err)
+	}
+	return ObjectFormatFromName(strings.TrimSpace(stdout))
+}
@@ -0,0 +1,60 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjectFormatFromName(t *testing.T) {
+	for name, expected := range map[string]ObjectFormat{
+		"":       Sha1ObjectFormat,
+		"sha1":   Sha1ObjectFormat,
+		"sha256": Sha256ObjectFormat,
+	} {
+		format, err := ObjectFormatFromName(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, format)
+	}
+
+	_, err := ObjectFormatFromName("md5")
+	assert.True(t, IsErrUnsupportedObjectFormat(err))
+}
+
+func TestIsEmptyCommitID(t *testing.T) {
+	assert.True(t, IsEmptyCommitID(""))
+	assert.True(t, IsEmptyCommitID(EmptySHA))
+	assert.True(t, IsEmptyCommitID(strings.Repeat("0", 64)))
+	assert.False(t, IsEmptyCommitID("2839944139e0de9737a044f78b0e4b40d989a9e3"))
+	assert.False(t, IsEmptyCommitID(strings.Repeat("0", 39)))
+}
+
+func TestInitRepositoryObjectFormat(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "object-format")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	sha1Path := filepath.Join(tmpDir, "sha1.git")
+	assert.NoError(t, InitRepository(sha1Path, true, Sha1ObjectFormat))
+	format, err := GetObjectFormatOfRepo(sha1Path)
+	assert.NoError(t, err)
+	assert.Equal(t, Sha1ObjectFormat, format)
+
+	// sha256 repositories can't be read yet, so they aren't created either
+	assert.False(t, Sha256ObjectFormat.IsSupported())
+	assert.Equal(t, []ObjectFormat{Sha1ObjectFormat}, SupportedObjectFormats())
+	sha256Path := filepath.Join(tmpDir, "sha256.git")
+	err = InitRepository(sha256Path, true, Sha256ObjectFormat)
+	assert.True(t, IsErrUnsupportedObjectFormat(err))
+
+	_, err = NewIDFromString(Sha256ObjectFormat.EmptyTree())
+	assert.True(t, IsErrUnsupportedObjectFormat(err))
+}
@@ -0,0 +1,56 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Operation is a class of git operations sharing a timeout
+type Operation string
+
+// The operations with a timeout of their own
+const (
+	OperationClone   Operation = "clone"
+	OperationMigrate Operation = "migrate"
+	OperationMerge   Operation = "merge"
+	OperationArchive Operation = "archive"
+	OperationBlame   Operation = "blame"
+)
+
+var operationTimeouts = struct {
+	sync.RWMutex
+	timeouts map[Operation]time.Duration
+}{
+	timeouts: make(map[Operation]time.Duration),
+}
+
+// SetOperationTimeout sets the timeout of the git commands of the operation
+func SetOperationTimeout(op Operation, timeout time.Duration) {
+	operationTimeouts.Lock()
+	operationTimeouts.timeouts[op] = timeout
+	operationTimeouts.Unlock()
+}
+
+// OperationTimeout returns the timeout of the git commands of the operation,
+// which is DefaultCommandExecutionTimeout unless it has been set
+func OperationTimeout(op Operation) time.Duration {
+	operationTimeouts.RLock()
+	timeout, ok := operationTimeouts.timeouts[op]
+	operationTimeouts.RUnlock()
+	if !ok || timeout <= 0 {
+		return DefaultCommandExecutionTimeout
+	}
+	return timeout
+}
+
+// NewOperationContext returns a context of the parent for the git commands of the operation,
+// which is canceled once the timeout of the operation has passed. The commands run in it
+// with the default timeout share its deadline instead of getting DefaultCommandExecutionTimeout each.
+func NewOperationContext(parent context.Context, op Operation) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(parent, OperationTimeout(op))
+}
@@ -0,0 +1,45 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationTimeout(t *testing.T) {
+	assert.Equal(t, DefaultCommandExecutionTimeout, OperationTimeout(OperationMerge))
+
+	SetOperationTimeout(OperationMerge, time.Minute)
+	defer SetOperationTimeout(OperationMerge, 0)
+	assert.Equal(t, time.Minute, OperationTimeout(OperationMerge))
+}
+
+func TestNewOperationContext(t *testing.T) {
+	SetOperationTimeout(OperationBlame, 50*time.Millisecond)
+	defer SetOperationTimeout(OperationBlame, 0)
+
+	ctx, cancel := NewOperationContext(context.Background(), OperationBlame)
+	defer cancel()
+
+	// The commands run with the default timeout are killed once the operation has timed out
+	start := time.Now()
+	err := NewCommandContext(ctx, "hash-object", "--stdin").RunInDirTimeoutFullPipeline(-1, "", nil, nil, &blockingReader{ctx: ctx})
+	assert.Error(t, err)
+	assert.True(t, time.Since(start) < DefaultCommandExecutionTimeout)
+}
+
+// blockingReader blocks until the context is done
+type blockingReader struct {
+	ctx context.Context
+}
+
+func (r *blockingReader) Read(p []byte) (int, error) {
+	<-r.ctx.Done()
+	return 0, r.ctx.Err()
+}
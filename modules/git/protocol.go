@@ -0,0 +1,115 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// protocolParametersPattern matches the wire protocol parameters of GIT_PROTOCOL,
+// a colon separated list of key=value pairs like "version=2"
+var protocolParametersPattern = regexp.MustCompile(`^[0-9a-zA-Z]+=[0-9a-zA-Z]+(:[0-9a-zA-Z]+=[0-9a-zA-Z]+)*$`)
+
+// IsValidProtocolParameters returns whether the wire protocol parameters requested by a client,
+// through the Git-Protocol header or the GIT_PROTOCOL environment variable, can be passed to git.
+func IsValidProtocolParameters(parameters string) bool {
+	return protocolParametersPattern.MatchString(parameters)
+}
+
+// maxPktLinePrefix is the length of the start of a pkt-line kept to recognize it
+const maxPktLinePrefix = 256
+
+// UploadPackRequestReader reads an upload-pack request and picks up the object filter of a partial clone
+// from its pkt-lines on the way, for both version 0 and version 2 of the wire protocol.
+type UploadPackRequestReader struct {
+	r         io.Reader
+	header    []byte
+	remaining int
+	prefix    []byte
+	done      bool
+
+	// Filter is the object filter spec requested by the client, like "blob:none"
+	Filter string
+}
+
+// NewUploadPackRequestReader creates a reader for an upload-pack request
+func NewUploadPackRequestReader(r io.Reader) *UploadPackRequestReader {
+	return &UploadPackRequestReader{r: r}
+}
+
+// Read reads the request
+func (r *UploadPackRequestReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if !r.done {
+		r.parse(p[:n])
+	}
+	return n, err
+}
+
+func (r *UploadPackRequestReader) parse(b []byte) {
+	for len(b) > 0 && !r.done {
+		if r.remaining == 0 {
+			need := 4 - len(r.header)
+			if need > len(b) {
+				r.header = append(r.header, b...)
+				return
+			}
+			r.header = append(r.header, b[:need]...)
+			b = b[need:]
+			length, err := strconv.ParseUint(string(r.header), 16, 16)
+			r.header = r.header[:0]
+			if err != nil {
+				// Not a pkt-line, give up
+				r.done = true
+				return
+			}
+			// The flush, delimiter and response end packets have no payload
+			if length > 4 {
+				r.remaining = int(length) - 4
+				r.prefix = r.prefix[:0]
+			}
+			continue
+		}
+
+		n := r.remaining
+		if n > len(b) {
+			n = len(b)
+		}
+		if keep := maxPktLinePrefix - len(r.prefix); keep > 0 {
+			if keep > n {
+				keep = n
+			}
+			r.prefix = append(r.prefix, b[:keep]...)
+		}
+		r.remaining -= n
+		b = b[n:]
+
+		if r.remaining == 0 && bytes.HasPrefix(r.prefix, []byte("filter ")) {
+			r.Filter = strings.TrimSpace(string(r.prefix[len("filter "):]))
+			r.done = true
+		}
+	}
+}
+
+// ObjectFilterKind returns the kind of an object filter spec without its parameters,
+// like "blob:limit" for "blob:limit=1m", or "other" for an unknown one
+func ObjectFilterKind(spec string) string {
+	switch {
+	case spec == "blob:none":
+		return spec
+	case strings.HasPrefix(spec, "blob:limit="):
+		return "blob:limit"
+	}
+	for _, kind := range []string{"tree", "sparse", "object", "combine"} {
+		if strings.HasPrefix(spec, kind+":") {
+			return kind
+		}
+	}
+	return "other"
+}
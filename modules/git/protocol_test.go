@@ -0,0 +1,43 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadPackRequestReader(t *testing.T) {
+	kases := []struct {
+		request string
+		filter  string
+	}{
+		// version 0: the filter follows the wants
+		{"0032want 65f1bf27bc3bf70f64657658635e66094edbcb4d\n0019filter blob:limit=1m\n00000009done\n", "blob:limit=1m"},
+		// version 2: the filter is an argument of the fetch command
+		{"0012command=fetch\n0001000ethin-pack\n0015filter blob:none\n0032want 65f1bf27bc3bf70f64657658635e66094edbcb4d\n0009done\n0000", "blob:none"},
+		{"0032want 65f1bf27bc3bf70f64657658635e66094edbcb4d\n00000009done\n", ""},
+		{"not a request", ""},
+	}
+	for _, kase := range kases {
+		// Read a byte at a time to split the pkt-lines
+		r := NewUploadPackRequestReader(iotest.OneByteReader(strings.NewReader(kase.request)))
+		data, err := ioutil.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Equal(t, kase.request, string(data))
+		assert.Equal(t, kase.filter, r.Filter)
+	}
+}
+
+func TestObjectFilterKind(t *testing.T) {
+	assert.Equal(t, "blob:none", ObjectFilterKind("blob:none"))
+	assert.Equal(t, "blob:limit", ObjectFilterKind("blob:limit=1m"))
+	assert.Equal(t, "tree", ObjectFilterKind("tree:0"))
+	assert.Equal(t, "other", ObjectFilterKind("unknown"))
+}
@@ -32,7 +32,7 @@ type Repository struct {
 	tagCache *ObjectCache
 
 	gogitRepo    *gogit.Repository
-	gogitStorage *filesystem.Storage
+	gogitStorage *replacingStorage
 	gpgSettings  *GPGSettings
 }
 
@@ -78,7 +78,11 @@ func IsRepoURLAccessible(url string) bool {
 }
 
 // InitRepository initializes a new Git repository.
-func InitRepository(repoPath string, bare bool) error {
+func InitRepository(repoPath string, bare bool, objectFormat ObjectFormat) error {
+	if !objectFormat.IsSupported() {
+		return ErrUnsupportedObjectFormat{Name: string(objectFormat)}
+	}
+
 	err := os.MkdirAll(repoPath, os.ModePerm)
 	if err != nil {
 		return err
@@ -88,6 +92,9 @@ func InitRepository(repoPath string, bare bool) error {
 	if bare {
 		cmd.AddArguments("--bare")
 	}
+	if objectFormat != Sha1ObjectFormat {
+		cmd.AddArguments("--object-format=" + string(objectFormat))
+	}
 	_, err = cmd.RunInDir(repoPath)
 	return err
 }
@@ -109,7 +116,9 @@ func OpenRepository(repoPath string) (*Repository, error) {
 			return nil, err
 		}
 	}
-	storage := filesystem.NewStorageWithOptions(fs, cache.NewObjectLRUDefault(), filesystem.Options{KeepDescriptors: true})
+	storage := &replacingStorage{
+		Storage: filesystem.NewStorageWithOptions(fs, cache.NewObjectLRUDefault(), filesystem.Options{KeepDescriptors: true}),
+	}
 	gogitRepo, err := gogit.Open(storage, fs)
 	if err != nil {
 		return nil, err
@@ -204,7 +213,7 @@ func CloneWithArgs(from, to string, args []string, opts CloneRepoOptions) (err e
 	cmd.AddArguments("--", from, to)
 
 	if opts.Timeout <= 0 {
-		opts.Timeout = -1
+		opts.Timeout = OperationTimeout(OperationClone)
 	}
 
 	_, err = cmd.RunTimeout(opts.Timeout)
@@ -17,6 +17,7 @@ func (repo *Repository) getBlob(id SHA1) (*Blob, error) {
 	return &Blob{
 		ID:              id,
 		gogitEncodedObj: encodedObj,
+		repoPath:        repo.Path,
 	}, nil
 }
 
@@ -51,11 +51,14 @@ func (repo *Repository) GetHEADBranch() (*Branch, error) {
 	if repo == nil {
 		return nil, fmt.Errorf("nil repo")
 	}
-	stdout, err := NewCommand("symbolic-ref", "HEAD").RunInDir(repo.Path)
+	head, err := repo.gogitRepo.Reference(plumbing.HEAD, false)
 	if err != nil {
 		return nil, err
 	}
-	stdout = strings.TrimSpace(stdout)
+	if head.Type() != plumbing.SymbolicReference {
+		return nil, fmt.Errorf("HEAD is detached at %s", head.Hash())
+	}
+	stdout := head.Target().String()
 
 	if !strings.HasPrefix(stdout, BranchPrefix) {
 		return nil, fmt.Errorf("invalid HEAD branch: %v", stdout)
@@ -130,6 +133,28 @@ func GetBranchesByPath(path string) ([]*Branch, error) {
 	return branches, nil
 }
 
+// BranchesRangeSize is the default number of branches of a page
+var BranchesRangeSize = 20
+
+// GetBranchesPage returns the page of the branches of the repository,
+// with the total number of branches matching the options
+func (repo *Repository) GetBranchesPage(opts ListRefsOptions) ([]*Branch, int, error) {
+	names, total, err := repo.GetBranchNames(opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	branches := make([]*Branch, len(names))
+	for i := range names {
+		branches[i] = &Branch{
+			Path:    repo.Path,
+			Name:    names[i],
+			gitRepo: repo,
+		}
+	}
+	return branches, total, nil
+}
+
 // DeleteBranchOptions Option(s) for delete branch
 type DeleteBranchOptions struct {
 	Force bool
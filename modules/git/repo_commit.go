@@ -46,14 +46,27 @@ func (repo *Repository) GetBranchCommitID(name string) (string, error) {
 
 // GetTagCommitID returns last commit ID string of given tag.
 func (repo *Repository) GetTagCommitID(name string) (string, error) {
-	stdout, err := NewCommand("rev-list", "-n", "1", name).RunInDir(repo.Path)
+	ref, err := repo.gogitRepo.Reference(plumbing.ReferenceName(TagPrefix+name), true)
 	if err != nil {
-		if strings.Contains(err.Error(), "unknown revision or path") {
+		if err == plumbing.ErrReferenceNotFound {
 			return "", ErrNotExist{name, ""}
 		}
 		return "", err
 	}
-	return strings.TrimSpace(stdout), nil
+	return repo.peelTag(ref.Hash())
+}
+
+// peelTag returns the ID of the object the (annotated) tag with the given ID finally points to
+func (repo *Repository) peelTag(id SHA1) (string, error) {
+	for {
+		tag, err := repo.gogitRepo.TagObject(id)
+		if err == plumbing.ErrObjectNotFound {
+			return id.String(), nil
+		} else if err != nil {
+			return "", err
+		}
+		id = tag.Target
+	}
 }
 
 func convertPGPSignatureForTag(t *object.Tag) *CommitGPGSignature {
@@ -110,6 +123,7 @@ func (repo *Repository) getCommit(id SHA1) (*Commit, error) {
 
 	commit := convertCommit(gogitCommit)
 	commit.repo = repo
+	commit.ReplacedBy, _ = repo.GetReplacement(commit.ID)
 
 	if tagObject != nil {
 		commit.CommitMessage = strings.TrimSpace(tagObject.Message)
@@ -131,7 +145,9 @@ func (repo *Repository) getCommit(id SHA1) (*Commit, error) {
 // ConvertToSHA1 returns a Hash object from a potential ID string
 func (repo *Repository) ConvertToSHA1(commitID string) (SHA1, error) {
 	if len(commitID) != 40 {
-		var err error
+		if id, ok := repo.resolveReference(commitID); ok {
+			return id, nil
+		}
 		actualCommitID, err := NewCommand("rev-parse", "--verify", commitID).RunInDir(repo.Path)
 		if err != nil {
 			if strings.Contains(err.Error(), "unknown revision or path") ||
@@ -145,6 +161,23 @@ func (repo *Repository) ConvertToSHA1(commitID string) (SHA1, error) {
 	return NewIDFromString(commitID)
 }
 
+// resolveReference looks a branch, tag or other reference up by its short name like
+// git rev-parse does, without running git. Revisions like "master~1" and names which
+// aren't valid reference names are left to git.
+func (repo *Repository) resolveReference(name string) (SHA1, bool) {
+	if name == "" || strings.ContainsAny(name, "^~:@{}\\*?[ \t\n") || strings.Contains(name, "..") ||
+		strings.HasPrefix(name, "/") || strings.HasPrefix(name, "-") {
+		return SHA1{}, false
+	}
+	for _, rule := range append([]string{"%s"}, plumbing.RefRevParseRules...) {
+		ref, err := repo.gogitRepo.Reference(plumbing.ReferenceName(fmt.Sprintf(rule, name)), true)
+		if err == nil {
+			return ref.Hash(), true
+		}
+	}
+	return SHA1{}, false
+}
+
 // GetCommit returns commit object of by ID string.
 func (repo *Repository) GetCommit(commitID string) (*Commit, error) {
 	id, err := repo.ConvertToSHA1(commitID)
@@ -63,3 +63,45 @@ func TestGetCommitWithBadCommitID(t *testing.T) {
 	assert.Error(t, err)
 	assert.True(t, IsErrNotExist(err))
 }
+
+func TestRepository_ConvertToSHA1(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	bareRepo1, err := OpenRepository(bareRepo1Path)
+	assert.NoError(t, err)
+	defer bareRepo1.Close()
+
+	testCases := []struct {
+		Name       string
+		ExpectedID string
+	}{
+		{"HEAD", "feaf4ba6bc635fec442f46ddd4512416ec43c2c2"},
+		{"branch1", "2839944139e0de9737a044f78b0e4b40d989a9e3"},
+		{"refs/heads/branch2", "5c80b0245c1c6f8343fa418ec374b13b5d4ee658"},
+		// annotated tags resolve to the tag object like with git rev-parse
+		{"test", "3ad28a9149a2864384548f3d17ed7f38014c9e8a"},
+		{"feaf4ba", "feaf4ba6bc635fec442f46ddd4512416ec43c2c2"},
+		{"branch1~1", "9c9aef8dd84e02bc7ec12641deb4c930a7c30185"},
+	}
+	for _, testCase := range testCases {
+		id, err := bareRepo1.ConvertToSHA1(testCase.Name)
+		assert.NoError(t, err)
+		assert.Equal(t, testCase.ExpectedID, id.String(), testCase.Name)
+	}
+
+	_, err = bareRepo1.ConvertToSHA1("not-a-branch")
+	assert.True(t, IsErrNotExist(err))
+}
+
+func TestRepository_GetTagCommitID(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	bareRepo1, err := OpenRepository(bareRepo1Path)
+	assert.NoError(t, err)
+	defer bareRepo1.Close()
+
+	commitID, err := bareRepo1.GetTagCommitID("test")
+	assert.NoError(t, err)
+	assert.Equal(t, "37991dec2c8e592043f47155ce4808d4580f9123", commitID)
+
+	_, err = bareRepo1.GetTagCommitID("master")
+	assert.True(t, IsErrNotExist(err))
+}
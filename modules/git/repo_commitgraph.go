@@ -17,6 +17,11 @@ import (
 
 // CommitNodeIndex returns the index for walking commit graph
 func (r *Repository) CommitNodeIndex() (cgobject.CommitNodeIndex, *os.File) {
+	// Like git, ignore the commit-graph which doesn't know about the commits replaced by replace refs
+	if r.HasReplacements() {
+		return cgobject.NewObjectCommitNodeIndex(r.gogitRepo.Storer), nil
+	}
+
 	indexPath := path.Join(r.Path, "objects", "info", "commit-graph")
 
 	file, err := os.Open(indexPath)
@@ -34,3 +39,9 @@ func (r *Repository) CommitNodeIndex() (cgobject.CommitNodeIndex, *os.File) {
 
 	return cgobject.NewObjectCommitNodeIndex(r.gogitRepo.Storer), nil
 }
+
+// WriteCommitGraph writes the commit-graph file of all commits reachable from the references of the repository
+func WriteCommitGraph(repoPath string) error {
+	_, err := NewCommand("commit-graph", "write", "--reachable").RunInDir(repoPath)
+	return err
+}
@@ -120,6 +120,20 @@ func (repo *Repository) GetDiffNumChangedFiles(base, head string) (int, error) {
 	return w.numLines, nil
 }
 
+// GetFilesChangedBetween returns the files changed between the merge base of base and head and head,
+// both the old and new paths of the renamed files
+func (repo *Repository) GetFilesChangedBetween(base, head string) ([]string, error) {
+	stdout, err := NewCommand("diff", "-z", "--name-only", "--no-renames", base+"..."+head).RunInDirBytes(repo.Path)
+	if err != nil {
+		return nil, err
+	}
+	files := strings.Split(string(stdout), "\x00")
+	if len(files) > 0 && files[len(files)-1] == "" {
+		files = files[:len(files)-1]
+	}
+	return files, nil
+}
+
 // GetDiffShortStat counts number of changed files, number of additions and deletions
 func (repo *Repository) GetDiffShortStat(base, head string) (numFiles, totalAdditions, totalDeletions int, err error) {
 	return GetDiffShortStat(repo.Path, base+"..."+head)
@@ -0,0 +1,110 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// RefSortType is the order branches or tags are listed in
+type RefSortType string
+
+// The orders branches or tags can be listed in
+const (
+	RefSortAlphabetically RefSortType = "alphabetically"
+	RefSortNewest         RefSortType = "newest"
+	RefSortOldest         RefSortType = "oldest"
+)
+
+// ListRefsOptions are the options to list a page of the branches or tags of a repository
+type ListRefsOptions struct {
+	// Prefix only lists the refs whose names start with it
+	Prefix string
+	// Sort is the order of the refs, alphabetically if it is empty
+	Sort RefSortType
+	// Page is the 1-based page of the refs, all of them are listed if it is 0
+	Page     int
+	PageSize int
+}
+
+// sortKey returns the for-each-ref sort key of the order, the tags being sorted by the date
+// of the tag objects of annotated tags and of the commits of lightweight tags
+func (sort RefSortType) sortKey(refPrefix string) string {
+	dateKey := "committerdate"
+	if refPrefix == TagPrefix {
+		dateKey = "creatordate"
+	}
+	switch sort {
+	case RefSortNewest:
+		return "-" + dateKey
+	case RefSortOldest:
+		return dateKey
+	default:
+		return "refname"
+	}
+}
+
+// listRefNames returns the names without refPrefix of the page of the refs starting with refPrefix,
+// with the total number of matching refs. Only the names of the refs are read by for-each-ref,
+// so the refs of the other pages cost a line of its output.
+func (repo *Repository) listRefNames(refPrefix string, opts ListRefsOptions) ([]string, int, error) {
+	// The patterns of for-each-ref match whole path components, so git only lists
+	// the refs in the directory of the prefix, which are filtered by their names here
+	pattern := refPrefix
+	if i := strings.LastIndex(opts.Prefix, "/"); i >= 0 {
+		pattern += opts.Prefix[:i+1]
+	}
+
+	skip, limit := 0, -1
+	if opts.Page > 0 && opts.PageSize > 0 {
+		skip, limit = (opts.Page-1)*opts.PageSize, opts.PageSize
+	}
+
+	stdout, w := io.Pipe()
+	done := make(chan struct{})
+	var names []string
+	var total int
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			name := strings.TrimPrefix(scanner.Text(), refPrefix)
+			if !strings.HasPrefix(name, opts.Prefix) {
+				continue
+			}
+			if total >= skip && (limit < 0 || len(names) < limit) {
+				names = append(names, name)
+			}
+			total++
+		}
+		// Drain the output of for-each-ref if a line was too long for the scanner
+		_, _ = io.Copy(ioutil.Discard, stdout)
+		close(done)
+	}()
+
+	stderr := new(bytes.Buffer)
+	err := NewCommand("for-each-ref", "--format=%(refname)", "--sort="+opts.Sort.sortKey(refPrefix), pattern).RunInDirPipeline(repo.Path, w, stderr)
+	w.Close() // Close writer to exit parsing goroutine
+	<-done
+	if err != nil {
+		return nil, 0, concatenateError(err, stderr.String())
+	}
+	return names, total, nil
+}
+
+// GetBranchNames returns the names of the page of the branches of the repository,
+// with the total number of branches matching the options
+func (repo *Repository) GetBranchNames(opts ListRefsOptions) ([]string, int, error) {
+	return repo.listRefNames(BranchPrefix, opts)
+}
+
+// GetTagNames returns the names of the page of the tags of the repository,
+// with the total number of tags matching the options
+func (repo *Repository) GetTagNames(opts ListRefsOptions) ([]string, int, error) {
+	return repo.listRefNames(TagPrefix, opts)
+}
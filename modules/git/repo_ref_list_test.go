@@ -0,0 +1,57 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_GetBranchNames(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	bareRepo1, err := OpenRepository(bareRepo1Path)
+	assert.NoError(t, err)
+	defer bareRepo1.Close()
+
+	for _, test := range []struct {
+		opts          ListRefsOptions
+		expectedNames []string
+		expectedTotal int
+	}{
+		{ListRefsOptions{}, []string{"branch1", "branch2", "master"}, 3},
+		{ListRefsOptions{Sort: RefSortNewest}, []string{"master", "branch2", "branch1"}, 3},
+		{ListRefsOptions{Sort: RefSortOldest, Page: 1, PageSize: 2}, []string{"branch1", "branch2"}, 3},
+		{ListRefsOptions{Page: 2, PageSize: 2}, []string{"master"}, 3},
+		{ListRefsOptions{Page: 3, PageSize: 2}, nil, 3},
+		{ListRefsOptions{Prefix: "branch"}, []string{"branch1", "branch2"}, 2},
+		{ListRefsOptions{Prefix: "branch*"}, nil, 0},
+		{ListRefsOptions{Prefix: "branch1/"}, nil, 0},
+		{ListRefsOptions{Prefix: "ma", Page: 1, PageSize: 2}, []string{"master"}, 1},
+	} {
+		names, total, err := bareRepo1.GetBranchNames(test.opts)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expectedNames, names, "%+v", test.opts)
+		assert.Equal(t, test.expectedTotal, total, "%+v", test.opts)
+	}
+}
+
+func TestRepository_GetTagNames(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	bareRepo1, err := OpenRepository(bareRepo1Path)
+	assert.NoError(t, err)
+	defer bareRepo1.Close()
+
+	names, total, err := bareRepo1.GetTagNames(ListRefsOptions{Sort: RefSortNewest})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"test"}, names)
+	assert.Equal(t, 1, total)
+
+	names, total, err = bareRepo1.GetTagNames(ListRefsOptions{Prefix: "branch"})
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+	assert.Equal(t, 0, total)
+}
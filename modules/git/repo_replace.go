@@ -0,0 +1,124 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	gitealog "code.gitea.io/gitea/modules/log"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// ReplacePrefix is the prefix of the refs replacing objects by other ones, named after the ID of the replaced object
+const ReplacePrefix = "refs/replace/"
+
+// replacingStorage is the storage of a repository which reads the replacement of the objects replaced by refs,
+// like git does. The objects keep the ID of the object they replace.
+type replacingStorage struct {
+	*filesystem.Storage
+
+	once         sync.Once
+	replacements map[SHA1]SHA1
+}
+
+// replacedObject is an object read in place of a replaced one
+type replacedObject struct {
+	plumbing.EncodedObject
+	id SHA1
+}
+
+// Hash returns the ID of the replaced object
+func (o *replacedObject) Hash() plumbing.Hash {
+	return o.id
+}
+
+func (s *replacingStorage) getReplacements() map[SHA1]SHA1 {
+	s.once.Do(func() {
+		replacements, err := s.readReplacements()
+		if err != nil {
+			gitealog.Error("Unable to read the replace refs: %v", err)
+		}
+		s.replacements = replacements
+	})
+	return s.replacements
+}
+
+// readReplacements reads the replace refs of the repository, unless they are disabled
+// by the core.useReplaceRefs config or the GIT_NO_REPLACE_OBJECTS environment variable
+func (s *replacingStorage) readReplacements() (map[SHA1]SHA1, error) {
+	if _, ok := os.LookupEnv("GIT_NO_REPLACE_OBJECTS"); ok {
+		return nil, nil
+	}
+	cfg, err := s.Config()
+	if err != nil {
+		return nil, err
+	}
+	if use, err := strconv.ParseBool(cfg.Raw.Section("core").Option("useReplaceRefs")); err == nil && !use {
+		return nil, nil
+	}
+
+	refs, err := s.IterReferences()
+	if err != nil {
+		return nil, err
+	}
+
+	replacements := make(map[SHA1]SHA1)
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference || !strings.HasPrefix(ref.Name().String(), ReplacePrefix) {
+			return nil
+		}
+		if id, err := NewIDFromString(strings.TrimPrefix(ref.Name().String(), ReplacePrefix)); err == nil {
+			replacements[id] = ref.Hash()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return replacements, nil
+}
+
+// EncodedObject returns the object with the given ID, or its replacement
+func (s *replacingStorage) EncodedObject(t plumbing.ObjectType, id plumbing.Hash) (plumbing.EncodedObject, error) {
+	replacement, ok := s.getReplacements()[id]
+	if !ok {
+		return s.Storage.EncodedObject(t, id)
+	}
+	obj, err := s.Storage.EncodedObject(t, replacement)
+	if err != nil {
+		return nil, err
+	}
+	return &replacedObject{EncodedObject: obj, id: id}, nil
+}
+
+// EncodedObjectSize returns the size of the object with the given ID, or the one of its replacement
+func (s *replacingStorage) EncodedObjectSize(id plumbing.Hash) (int64, error) {
+	if replacement, ok := s.getReplacements()[id]; ok {
+		id = replacement
+	}
+	return s.Storage.EncodedObjectSize(id)
+}
+
+// HasReplacements returns whether objects of the repository are replaced by replace refs
+func (repo *Repository) HasReplacements() bool {
+	if repo.gogitStorage == nil {
+		return false
+	}
+	return len(repo.gogitStorage.getReplacements()) > 0
+}
+
+// GetReplacement returns the ID of the object replacing the object with the given ID, if it is replaced
+func (repo *Repository) GetReplacement(id SHA1) (SHA1, bool) {
+	if repo.gogitStorage == nil {
+		return SHA1{}, false
+	}
+	replacement, ok := repo.gogitStorage.getReplacements()[id]
+	return replacement, ok
+}
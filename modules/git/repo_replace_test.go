@@ -0,0 +1,82 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_GetReplacement(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "repo1_replace")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	repoPath := filepath.Join(tmpDir, "repo1_bare")
+	assert.NoError(t, Clone(filepath.Join(testReposDir, "repo1_bare"), repoPath, CloneRepoOptions{Mirror: true}))
+
+	// "Added short link" is replaced by the root commit "Add file1.txt", which cuts the history of master
+	replacedID := "37991dec2c8e592043f47155ce4808d4580f9123"
+	replacementID := "95bb4d39648ee7e325106df01a621c530863a653"
+	_, err = NewCommand("replace", replacedID, replacementID).RunInDir(repoPath)
+	assert.NoError(t, err)
+
+	repo, err := OpenRepository(repoPath)
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	assert.True(t, repo.HasReplacements())
+	replacement, ok := repo.GetReplacement(MustIDFromString(replacedID))
+	assert.True(t, ok)
+	assert.Equal(t, replacementID, replacement.String())
+	_, ok = repo.GetReplacement(MustIDFromString(replacementID))
+	assert.False(t, ok)
+
+	commit, err := repo.GetCommit(replacedID)
+	assert.NoError(t, err)
+	assert.Equal(t, replacedID, commit.ID.String())
+	assert.True(t, commit.IsReplaced())
+	assert.Equal(t, replacementID, commit.ReplacedBy.String())
+	assert.Equal(t, "Add file1.txt", commit.Summary())
+	assert.Empty(t, commit.Parents)
+
+	master, err := repo.GetBranchCommit("master")
+	assert.NoError(t, err)
+	assert.False(t, master.IsReplaced())
+	commits, err := master.CommitsByRange(1, 10)
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, commits.Len()) {
+		assert.Equal(t, replacedID, commits.Back().Value.(*Commit).ID.String())
+		assert.True(t, commits.Back().Value.(*Commit).IsReplaced())
+	}
+
+	// file2.txt isn't in the tree of the replacement, so it has last been changed by master
+	entries, err := master.ListEntries()
+	assert.NoError(t, err)
+	commitsInfo, _, err := entries.GetCommitsInfo(master, "", nil)
+	assert.NoError(t, err)
+	for _, commitInfo := range commitsInfo {
+		if commitInfo[0].(*TreeEntry).Name() == "file2.txt" {
+			assert.Equal(t, master.ID, commitInfo[1].(*Commit).ID)
+		}
+	}
+
+	// Replacements are ignored like git does when core.useReplaceRefs is false
+	_, err = NewCommand("config", "core.useReplaceRefs", "false").RunInDir(repoPath)
+	assert.NoError(t, err)
+	repo2, err := OpenRepository(repoPath)
+	assert.NoError(t, err)
+	defer repo2.Close()
+
+	assert.False(t, repo2.HasReplacements())
+	commit, err = repo2.GetCommit(replacedID)
+	assert.NoError(t, err)
+	assert.False(t, commit.IsReplaced())
+	assert.Equal(t, "Added short link", commit.Summary())
+}
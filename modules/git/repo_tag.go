@@ -7,6 +7,7 @@ package git
 
 import (
 	"fmt"
+	"io/ioutil"
 	"strings"
 
 	"github.com/go-git/go-git/v5/plumbing"
@@ -39,6 +40,12 @@ func (repo *Repository) CreateAnnotatedTag(name, message, revision string) error
 	return err
 }
 
+// CreateSignedTag create one annotated tag signed by the given key in the repository
+func (repo *Repository) CreateSignedTag(name, message, revision, keyID string, env []string) error {
+	_, err := NewCommand("tag", "-u", keyID, "-m", message, "--", name, revision).RunInDirWithEnv(repo.Path, env)
+	return err
+}
+
 func (repo *Repository) getTag(id SHA1) (*Tag, error) {
 	t, ok := repo.tagCache.Get(id.String())
 	if ok {
@@ -105,7 +112,16 @@ func (repo *Repository) getTag(id SHA1) (*Tag, error) {
 	}
 
 	// The tag is an annotated tag with a message.
-	data, err := NewCommand("cat-file", "-p", id.String()).RunInDirBytes(repo.Path)
+	obj, err := repo.gogitRepo.Storer.EncodedObject(plumbing.TagObject, id)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := obj.Reader()
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(reader)
+	reader.Close()
 	if err != nil {
 		return nil, err
 	}
@@ -130,41 +146,51 @@ func (repo *Repository) GetTagNameBySHA(sha string) (string, error) {
 		return "", fmt.Errorf("SHA is too short: %s", sha)
 	}
 
-	stdout, err := NewCommand("show-ref", "--tags", "-d").RunInDir(repo.Path)
+	tags, err := repo.gogitRepo.Tags()
 	if err != nil {
 		return "", err
 	}
 
-	tagRefs := strings.Split(stdout, "\n")
-	for _, tagRef := range tagRefs {
-		if len(strings.TrimSpace(tagRef)) > 0 {
-			fields := strings.Fields(tagRef)
-			if strings.HasPrefix(fields[0], sha) && strings.HasPrefix(fields[1], TagPrefix) {
-				name := fields[1][len(TagPrefix):]
-				// annotated tags show up twice, we should only return if is not the ^{} ref
-				if !strings.HasSuffix(name, "^{}") {
-					return name, nil
-				}
-			}
+	// Pick the first matching tag by name like git show-ref does
+	var name string
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		tagName := strings.TrimPrefix(ref.Name().String(), TagPrefix)
+		if name != "" && name < tagName {
+			return nil
+		}
+		// annotated tags match both their tag object SHA and the SHA they point to
+		if strings.HasPrefix(ref.Hash().String(), sha) {
+			name = tagName
+			return nil
+		}
+		target, err := repo.peelTag(ref.Hash())
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(target, sha) {
+			name = tagName
 		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if name != "" {
+		return name, nil
 	}
 	return "", ErrNotExist{ID: sha}
 }
 
 // GetTagID returns the object ID for a tag (annotated tags have both an object SHA AND a commit SHA)
 func (repo *Repository) GetTagID(name string) (string, error) {
-	stdout, err := NewCommand("show-ref", "--tags", "--", name).RunInDir(repo.Path)
+	ref, err := repo.gogitRepo.Reference(plumbing.ReferenceName(TagPrefix+name), false)
 	if err != nil {
-		return "", err
-	}
-	// Make sure exact match is used: "v1" != "release/v1"
-	for _, line := range strings.Split(stdout, "\n") {
-		fields := strings.Fields(line)
-		if len(fields) == 2 && fields[1] == "refs/tags/"+name {
-			return fields[0], nil
+		if err == plumbing.ErrReferenceNotFound {
+			return "", ErrNotExist{ID: name}
 		}
+		return "", err
 	}
-	return "", ErrNotExist{ID: name}
+	return ref.Hash().String(), nil
 }
 
 // GetTag returns a Git tag by given name.
@@ -186,25 +212,13 @@ func (repo *Repository) GetTag(name string) (*Tag, error) {
 	return tag, nil
 }
 
-// GetTagInfos returns all tag infos of the repository.
-func (repo *Repository) GetTagInfos(page, pageSize int) ([]*Tag, error) {
-	// TODO this a slow implementation, makes one git command per tag
-	stdout, err := NewCommand("tag").RunInDir(repo.Path)
+// GetTagInfos returns the tag infos of the page of the tags of the repository, all of them if
+// the page is 0, with the total number of tags matching the options. Unless they are sorted
+// otherwise, the pages are in the order of git tag and the tags of a page are sorted by time.
+func (repo *Repository) GetTagInfos(opts ListRefsOptions) ([]*Tag, int, error) {
+	tagNames, total, err := repo.GetTagNames(opts)
 	if err != nil {
-		return nil, err
-	}
-
-	tagNames := strings.Split(strings.TrimRight(stdout, "\n"), "\n")
-
-	if page != 0 {
-		skip := (page - 1) * pageSize
-		if skip >= len(tagNames) {
-			return nil, nil
-		}
-		if (len(tagNames) - skip) < pageSize {
-			pageSize = len(tagNames) - skip
-		}
-		tagNames = tagNames[skip : skip+pageSize]
+		return nil, 0, err
 	}
 
 	var tags = make([]*Tag, 0, len(tagNames))
@@ -216,13 +230,15 @@ func (repo *Repository) GetTagInfos(page, pageSize int) ([]*Tag, error) {
 
 		tag, err := repo.GetTag(tagName)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 		tag.Name = tagName
 		tags = append(tags, tag)
 	}
-	sortTagsByTime(tags)
-	return tags, nil
+	if len(opts.Sort) == 0 {
+		sortTagsByTime(tags)
+	}
+	return tags, total, nil
 }
 
 // GetTags returns all tags of the repository.
@@ -253,14 +269,14 @@ func (repo *Repository) GetTags() ([]string, error) {
 // GetTagType gets the type of the tag, either commit (simple) or tag (annotated)
 func (repo *Repository) GetTagType(id SHA1) (string, error) {
 	// Get tag type
-	stdout, err := NewCommand("cat-file", "-t", id.String()).RunInDir(repo.Path)
+	obj, err := repo.gogitRepo.Storer.EncodedObject(plumbing.AnyObject, id)
 	if err != nil {
+		if err == plumbing.ErrObjectNotFound {
+			return "", ErrNotExist{ID: id.String()}
+		}
 		return "", err
 	}
-	if len(stdout) == 0 {
-		return "", ErrNotExist{ID: id.String()}
-	}
-	return strings.TrimSpace(stdout), nil
+	return obj.Type().String(), nil
 }
 
 // GetAnnotatedTag returns a Git tag by its SHA, must be an annotated tag
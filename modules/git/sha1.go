@@ -20,8 +20,8 @@ const EmptySHA = "0000000000000000000000000000000000000000"
 // EmptyTreeSHA is the SHA of an empty tree
 const EmptyTreeSHA = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
 
-// SHAPattern can be used to determine if a string is an valid sha
-var SHAPattern = regexp.MustCompile(`^[0-9a-f]{4,40}$`)
+// SHAPattern can be used to determine if a string is an valid sha, of sha1 or sha256 repositories
+var SHAPattern = regexp.MustCompile(`^[0-9a-f]{4,64}$`)
 
 // SHA1 a git commit name
 type SHA1 = plumbing.Hash
@@ -51,6 +51,10 @@ func MustIDFromString(s string) SHA1 {
 func NewIDFromString(s string) (SHA1, error) {
 	var id SHA1
 	s = strings.TrimSpace(s)
+	if len(s) == Sha256ObjectFormat.HexLen() {
+		// The objects of sha256 repositories can't be read with go-git
+		return id, ErrUnsupportedObjectFormat{Name: string(Sha256ObjectFormat)}
+	}
 	if len(s) != 40 {
 		return id, fmt.Errorf("Length must be 40: %s", s)
 	}
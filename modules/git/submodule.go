@@ -6,7 +6,9 @@
 package git
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"net"
 	"net/url"
 	"path"
@@ -16,10 +18,59 @@ import (
 
 var scpSyntax = regexp.MustCompile(`^([a-zA-Z0-9_]+@)?([a-zA-Z0-9._-]+):(.*)$`)
 
+var submoduleSectionPattern = regexp.MustCompile(`^\[submodule\s+"(.*)"\]$`)
+
 // SubModule submodule is a reference on git repository
 type SubModule struct {
-	Name string
-	URL  string
+	Name   string
+	Path   string
+	URL    string
+	Branch string
+}
+
+// parseSubModules parses the submodules of a .gitmodules file by their path
+func parseSubModules(r io.Reader) (*ObjectCache, error) {
+	modules := newObjectCache()
+	var module *SubModule
+	addModule := func() {
+		if module != nil && module.Path != "" && module.URL != "" {
+			modules.Set(module.Path, module)
+		}
+		module = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' {
+			addModule()
+			if match := submoduleSectionPattern.FindStringSubmatch(line); match != nil {
+				module = &SubModule{Name: match[1]}
+			}
+			continue
+		}
+		if module == nil {
+			continue
+		}
+		fields := strings.SplitN(line, "=", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		value := strings.Trim(strings.TrimSpace(fields[1]), `"`)
+		switch strings.ToLower(strings.TrimSpace(fields[0])) {
+		case "path":
+			module.Path = value
+		case "url":
+			module.URL = value
+		case "branch":
+			module.Branch = value
+		}
+	}
+	addModule()
+	return modules, scanner.Err()
 }
 
 // SubModuleFile represents a file with submodule type.
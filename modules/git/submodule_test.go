@@ -5,6 +5,7 @@
 package git
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -38,3 +39,26 @@ func TestGetRefURL(t *testing.T) {
 		assert.EqualValues(t, kase.expect, getRefURL(kase.refURL, kase.prefixURL, kase.parentPath))
 	}
 }
+
+func TestParseSubModules(t *testing.T) {
+	modules, err := parseSubModules(strings.NewReader(`# comment
+[submodule "libs/first"]
+	path = libs/first
+	url = https://example.com/user/first.git?a=b
+[core]
+	path = ignored
+[submodule "second"]
+	url = ../second.git
+	branch = "stable"
+	path = libs/second
+[submodule "no-url"]
+	path = libs/no-url
+`))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"libs/first", "libs/second"}, modules.Keys())
+
+	module, _ := modules.Get("libs/first")
+	assert.Equal(t, &SubModule{Name: "libs/first", Path: "libs/first", URL: "https://example.com/user/first.git?a=b"}, module)
+	module, _ = modules.Get("libs/second")
+	assert.Equal(t, &SubModule{Name: "second", Path: "libs/second", URL: "../second.git", Branch: "stable"}, module)
+}
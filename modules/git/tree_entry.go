@@ -124,6 +124,7 @@ func (te *TreeEntry) Blob() *Blob {
 		ID:              te.gogitTreeEntry.Hash,
 		gogitEncodedObj: encodedObj,
 		name:            te.Name(),
+		repoPath:        te.ptree.repo.Path,
 	}
 }
 
@@ -41,6 +41,18 @@ func (oc *ObjectCache) Get(id string) (interface{}, bool) {
 	return obj, has
 }
 
+// Keys returns the ids of all cached objects
+func (oc *ObjectCache) Keys() []string {
+	oc.lock.RLock()
+	defer oc.lock.RUnlock()
+
+	keys := make([]string, 0, len(oc.cache))
+	for id := range oc.cache {
+		keys = append(keys, id)
+	}
+	return keys
+}
+
 // isDir returns true if given path is a directory,
 // or returns false when it's a file or does not exist.
 func isDir(dir string) bool {
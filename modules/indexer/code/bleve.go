@@ -5,7 +5,7 @@
 package code
 
 import (
-	"fmt"
+	"io/ioutil"
 	"os"
 	"strconv"
 	"strings"
@@ -103,24 +103,19 @@ func (d *RepoIndexerData) Type() string {
 	return repoIndexerDocType
 }
 
-func addUpdate(commitSha string, update fileUpdate, repo *models.Repository, batch rupture.FlushingBatch) error {
+func addUpdate(commitSha string, update fileUpdate, repo *models.Repository, catFileBatch *git.CatFileBatch, batch rupture.FlushingBatch) error {
 	// Ignore vendored files in code search
 	if setting.Indexer.ExcludeVendored && enry.IsVendor(update.Filename) {
 		return nil
 	}
-	stdout, err := git.NewCommand("cat-file", "-s", update.BlobSha).
-		RunInDir(repo.RepoPath())
+	info, reader, err := catFileBatch.Contents(update.BlobSha)
 	if err != nil {
 		return err
-	}
-	if size, err := strconv.Atoi(strings.TrimSpace(stdout)); err != nil {
-		return fmt.Errorf("Misformatted git cat-file output: %v", err)
-	} else if int64(size) > setting.Indexer.MaxIndexerFileSize {
+	} else if info.Size > setting.Indexer.MaxIndexerFileSize {
 		return addDelete(update.Filename, repo, batch)
 	}
 
-	fileContents, err := git.NewCommand("cat-file", "blob", update.BlobSha).
-		RunInDirBytes(repo.RepoPath())
+	fileContents, err := ioutil.ReadAll(reader)
 	if err != nil {
 		return err
 	} else if !base.IsTextFile(fileContents) {
@@ -279,10 +274,18 @@ func (b *BleveIndexer) Index(repoID int64) error {
 	}
 
 	batch := rupture.NewFlushingBatch(b.indexer, maxBatchSize)
-	for _, update := range changes.Updates {
-		if err := addUpdate(sha, update, repo, batch); err != nil {
+	if len(changes.Updates) > 0 {
+		catFileBatch, err := git.GetCatFileBatch(repo.RepoPath())
+		if err != nil {
 			return err
 		}
+		defer catFileBatch.Release()
+
+		for _, update := range changes.Updates {
+			if err := addUpdate(sha, update, repo, catFileBatch, batch); err != nil {
+				return err
+			}
+		}
 	}
 	for _, filename := range changes.RemovedFilenames {
 		if err := addDelete(filename, repo, batch); err != nil {
@@ -8,12 +8,14 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
+	"hash"
 	"io"
-	"os"
-	"path/filepath"
+	"io/ioutil"
+	"net/url"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/storage"
 )
 
 var (
@@ -21,23 +23,31 @@ var (
 	errSizeMismatch = errors.New("Content size does not match")
 )
 
-// ContentStore provides a simple file system based storage.
+// ContentStore stores the content of the lfs objects in an object storage.
 type ContentStore struct {
-	BasePath string
+	storage.ObjectStorage
+}
+
+// NewContentStore returns the content store of the lfs objects
+func NewContentStore() *ContentStore {
+	return &ContentStore{ObjectStorage: storage.LFS}
 }
 
 // Get takes a Meta object and retrieves the content from the store, returning
 // it as an io.Reader. If fromByte > 0, the reader starts from that byte
 func (s *ContentStore) Get(meta *models.LFSMetaObject, fromByte int64) (io.ReadCloser, error) {
-	path := filepath.Join(s.BasePath, transformKey(meta.Oid))
-
-	f, err := os.Open(path)
+	f, err := s.Open(meta.RelativePath())
 	if err != nil {
-		log.Error("Whilst trying to read LFS OID[%s]: Unable to open %s Error: %v", meta.Oid, path, err)
+		log.Error("Whilst trying to read LFS OID[%s]: Unable to open Error: %v", meta.Oid, err)
 		return nil, err
 	}
 	if fromByte > 0 {
-		_, err = f.Seek(fromByte, os.SEEK_CUR)
+		// The objects of remote storages are streamed from their beginning, so the skipped bytes are read
+		if seeker, ok := f.(io.Seeker); ok {
+			_, err = seeker.Seek(fromByte, io.SeekCurrent)
+		} else {
+			_, err = io.CopyN(ioutil.Discard, f, fromByte)
+		}
 		if err != nil {
 			log.Error("Whilst trying to read LFS OID[%s]: Unable to seek to %d Error: %v", meta.Oid, fromByte, err)
 		}
@@ -47,45 +57,30 @@ func (s *ContentStore) Get(meta *models.LFSMetaObject, fromByte int64) (io.ReadC
 
 // Put takes a Meta object and an io.Reader and writes the content to the store.
 func (s *ContentStore) Put(meta *models.LFSMetaObject, r io.Reader) error {
-	path := filepath.Join(s.BasePath, transformKey(meta.Oid))
-	tmpPath := path + ".tmp"
-
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0750); err != nil {
-		log.Error("Whilst putting LFS OID[%s]: Unable to create the LFS directory: %s Error: %v", meta.Oid, dir, err)
-		return err
-	}
+	p := meta.RelativePath()
 
-	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0640)
-	if err != nil {
-		log.Error("Whilst putting LFS OID[%s]: Unable to open temporary file for writing: %s Error: %v", tmpPath, err)
-		return err
+	hr := &hashingReader{
+		Reader: r,
+		hash:   sha256.New(),
 	}
-	defer os.Remove(tmpPath)
-
-	hash := sha256.New()
-	hw := io.MultiWriter(hash, file)
-
-	written, err := io.Copy(hw, r)
+	written, err := s.Save(p, hr)
 	if err != nil {
-		log.Error("Whilst putting LFS OID[%s]: Failed to copy to tmpPath: %s Error: %v", meta.Oid, tmpPath, err)
-		file.Close()
+		log.Error("Whilst putting LFS OID[%s]: Failed to copy to %s Error: %v", meta.Oid, p, err)
 		return err
 	}
-	file.Close()
 
+	// The content is verified once it is stored, so an invalid object has to be removed again
+	var verifyErr error
 	if written != meta.Size {
-		return errSizeMismatch
+		verifyErr = errSizeMismatch
+	} else if hex.EncodeToString(hr.hash.Sum(nil)) != meta.Oid {
+		verifyErr = errHashMismatch
 	}
-
-	shaStr := hex.EncodeToString(hash.Sum(nil))
-	if shaStr != meta.Oid {
-		return errHashMismatch
-	}
-
-	if err := os.Rename(tmpPath, path); err != nil {
-		log.Error("Whilst putting LFS OID[%s]: Unable to move tmp file to final destination: %s Error: %v", meta.Oid, path, err)
-		return err
+	if verifyErr != nil {
+		if err := s.Delete(p); err != nil {
+			log.Error("Whilst cleaning up invalid LFS OID[%s]: Unable to delete %s Error: %v", meta.Oid, p, err)
+		}
+		return verifyErr
 	}
 
 	return nil
@@ -93,32 +88,40 @@ func (s *ContentStore) Put(meta *models.LFSMetaObject, r io.Reader) error {
 
 // Exists returns true if the object exists in the content store.
 func (s *ContentStore) Exists(meta *models.LFSMetaObject) bool {
-	path := filepath.Join(s.BasePath, transformKey(meta.Oid))
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return false
-	}
-	return true
+	_, err := s.Stat(meta.RelativePath())
+	return err == nil
 }
 
 // Verify returns true if the object exists in the content store and size is correct.
 func (s *ContentStore) Verify(meta *models.LFSMetaObject) (bool, error) {
-	path := filepath.Join(s.BasePath, transformKey(meta.Oid))
-
-	fi, err := os.Stat(path)
-	if os.IsNotExist(err) || err == nil && fi.Size() != meta.Size {
+	p := meta.RelativePath()
+	fi, err := s.Stat(p)
+	if err == storage.ErrObjectNotExist || err == nil && fi.Size() != meta.Size {
 		return false, nil
 	} else if err != nil {
-		log.Error("Unable stat file: %s for LFS OID[%s] Error: %v", path, meta.Oid, err)
+		log.Error("Unable stat file: %s for LFS OID[%s] Error: %v", p, meta.Oid, err)
 		return false, err
 	}
 
 	return true, nil
 }
 
-func transformKey(key string) string {
-	if len(key) < 5 {
-		return key
-	}
+// URL returns a temporary url the clients can download the content of the object from as a file of the name,
+// storage.ErrURLNotSupported if the store can't serve it directly
+func (s *ContentStore) URL(meta *models.LFSMetaObject, name string) (*url.URL, error) {
+	return s.ObjectStorage.URL(meta.RelativePath(), name)
+}
 
-	return filepath.Join(key[0:2], key[2:4], key[4:])
+// hashingReader computes the hash of the content read through it
+type hashingReader struct {
+	io.Reader
+	hash hash.Hash
+}
+
+func (r *hashingReader) Read(b []byte) (int, error) {
+	n, err := r.Reader.Read(b)
+	if n > 0 {
+		_, _ = r.hash.Write(b[:n])
+	}
+	return n, err
 }
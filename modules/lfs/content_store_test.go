@@ -0,0 +1,58 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lfs")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	local, err := storage.NewLocalStorage(dir)
+	assert.NoError(t, err)
+	contentStore := &ContentStore{ObjectStorage: local}
+
+	content := "lfs object content"
+	sum := sha256.Sum256([]byte(content))
+	meta := &models.LFSMetaObject{Oid: hex.EncodeToString(sum[:]), Size: int64(len(content))}
+
+	assert.False(t, contentStore.Exists(meta))
+	assert.NoError(t, contentStore.Put(meta, strings.NewReader(content)))
+	assert.True(t, contentStore.Exists(meta))
+	ok, err := contentStore.Verify(meta)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	r, err := contentStore.Get(meta, 4)
+	assert.NoError(t, err)
+	data, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	r.Close()
+	assert.Equal(t, content[4:], string(data))
+
+	_, err = contentStore.URL(meta, "object.bin")
+	assert.Equal(t, storage.ErrURLNotSupported, err)
+
+	// Invalid contents are not kept
+	invalid := &models.LFSMetaObject{Oid: strings.Repeat("a", 64), Size: int64(len(content))}
+	assert.Equal(t, errHashMismatch, contentStore.Put(invalid, strings.NewReader(content)))
+	assert.False(t, contentStore.Exists(invalid))
+	invalid.Size++
+	assert.Equal(t, errSizeMismatch, contentStore.Put(invalid, strings.NewReader(content)))
+	assert.False(t, contentStore.Exists(invalid))
+}
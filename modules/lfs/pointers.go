@@ -14,13 +14,16 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 )
 
+// MetaFileMaxSize is the maximum size of a pointer file, larger blobs are never read as pointers
+const MetaFileMaxSize = 1024
+
 // ReadPointerFile will return a partially filled LFSMetaObject if the provided reader is a pointer file
 func ReadPointerFile(reader io.Reader) (*models.LFSMetaObject, *[]byte) {
 	if !setting.LFS.StartServer {
 		return nil, nil
 	}
 
-	buf := make([]byte, 1024)
+	buf := make([]byte, MetaFileMaxSize)
 	n, _ := reader.Read(buf)
 	buf = buf[:n]
 
@@ -37,7 +40,23 @@ func IsPointerFile(buf *[]byte) *models.LFSMetaObject {
 		return nil
 	}
 
-	headString := string(*buf)
+	meta := ParsePointer(*buf)
+	if meta == nil {
+		return nil
+	}
+
+	contentStore := NewContentStore()
+	if !contentStore.Exists(meta) {
+		return nil
+	}
+
+	return meta
+}
+
+// ParsePointer returns a partially filled LFSMetaObject if the provided byte slice is a pointer file,
+// whether the content of the object is stored or not
+func ParsePointer(buf []byte) *models.LFSMetaObject {
+	headString := string(buf)
 	if !strings.HasPrefix(headString, models.LFSMetaFileIdentifier) {
 		return nil
 	}
@@ -53,17 +72,11 @@ func IsPointerFile(buf *[]byte) *models.LFSMetaObject {
 		return nil
 	}
 
-	contentStore := &ContentStore{BasePath: setting.LFS.ContentPath}
-	meta := &models.LFSMetaObject{Oid: oid, Size: size}
-	if !contentStore.Exists(meta) {
-		return nil
-	}
-
-	return meta
+	return &models.LFSMetaObject{Oid: oid, Size: size}
 }
 
 // ReadMetaObject will read a models.LFSMetaObject and return a reader
 func ReadMetaObject(meta *models.LFSMetaObject) (io.ReadCloser, error) {
-	contentStore := &ContentStore{BasePath: setting.LFS.ContentPath}
+	contentStore := NewContentStore()
 	return contentStore.Get(meta, 0)
 }
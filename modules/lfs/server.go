@@ -20,6 +20,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 
 	"gitea.com/macaron/macaron"
 	"github.com/dgrijalva/jwt-go"
@@ -164,6 +165,24 @@ func getContentHandler(ctx *context.Context) {
 		return
 	}
 
+	contentStore := NewContentStore()
+	if setting.LFS.ServeDirect {
+		// Let the clients download the content from the storage if it can serve it directly
+		name := meta.Oid
+		if decodedFilename, err := base64.RawURLEncoding.DecodeString(ctx.Params("filename")); err == nil && len(decodedFilename) > 0 {
+			name = string(decodedFilename)
+		}
+		u, err := contentStore.URL(meta, name)
+		if err == nil {
+			ctx.Redirect(u.String(), http.StatusTemporaryRedirect)
+			logRequest(ctx.Req, http.StatusTemporaryRedirect)
+			return
+		}
+		if err != storage.ErrURLNotSupported {
+			log.Error("Unable to get the url of LFS OID[%s] Error: %v", meta.Oid, err)
+		}
+	}
+
 	// Support resume download using Range header
 	var fromByte, toByte int64
 	toByte = meta.Size - 1
@@ -186,7 +205,6 @@ func getContentHandler(ctx *context.Context) {
 		}
 	}
 
-	contentStore := &ContentStore{BasePath: setting.LFS.ContentPath}
 	content, err := contentStore.Get(meta, fromByte)
 	if err != nil {
 		// Errors are logged in contentStore.Get
@@ -286,7 +304,7 @@ func PostHandler(ctx *context.Context) {
 	ctx.Resp.Header().Set("Content-Type", metaMediaType)
 
 	sentStatus := 202
-	contentStore := &ContentStore{BasePath: setting.LFS.ContentPath}
+	contentStore := NewContentStore()
 	if meta.Existing && contentStore.Exists(meta) {
 		sentStatus = 200
 	}
@@ -341,7 +359,7 @@ func BatchHandler(ctx *context.Context) {
 			return
 		}
 
-		contentStore := &ContentStore{BasePath: setting.LFS.ContentPath}
+		contentStore := NewContentStore()
 
 		meta, err := repository.GetLFSMetaObjectByOid(object.Oid)
 		if err == nil && contentStore.Exists(meta) { // Object is found and exists
@@ -385,7 +403,7 @@ func PutHandler(ctx *context.Context) {
 		return
 	}
 
-	contentStore := &ContentStore{BasePath: setting.LFS.ContentPath}
+	contentStore := NewContentStore()
 	bodyReader := ctx.Req.Body().ReadCloser()
 	defer bodyReader.Close()
 	if err := contentStore.Put(meta, bodyReader); err != nil {
@@ -427,7 +445,7 @@ func VerifyHandler(ctx *context.Context) {
 		return
 	}
 
-	contentStore := &ContentStore{BasePath: setting.LFS.ContentPath}
+	contentStore := NewContentStore()
 	ok, err := contentStore.Verify(meta)
 	if err != nil {
 		// Error will be logged in Verify
@@ -580,7 +598,7 @@ func authenticate(ctx *context.Context, repository *models.Repository, authoriza
 	}
 
 	canRead := perm.CanAccess(accessMode, models.UnitTypeCode)
-	if canRead {
+	if canRead && hasTokenScope(ctx, requireWrite) {
 		return true
 	}
 
@@ -608,6 +626,19 @@ func authenticate(ctx *context.Context, repository *models.Repository, authoriza
 	return false
 }
 
+// hasTokenScope returns whether the access token the user signed in with, if any,
+// grants the repository scope required by the access
+func hasTokenScope(ctx *context.Context, requireWrite bool) bool {
+	scope, ok := ctx.Data["ApiTokenScope"].(models.AccessTokenScope)
+	if !ok {
+		return true
+	}
+	if requireWrite {
+		return scope.Has(models.AccessTokenScopeRepoWrite)
+	}
+	return scope.Has(models.AccessTokenScopeRepoRead)
+}
+
 func parseToken(authorization string) (*models.User, *models.Repository, string, error) {
 	if authorization == "" {
 		return nil, nil, "unknown", fmt.Errorf("No token")
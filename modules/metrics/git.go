@@ -0,0 +1,26 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// GitFilteredFetches counts the fetches of partial clones over HTTP by the kind of object filter,
+	// like blob:none or blob:limit
+	GitFilteredFetches = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: namespace + "git_filtered_fetches_total",
+			Help: "Number of git fetches with an object filter",
+		},
+		[]string{"filter"},
+	)
+)
+
+// RegisterGitMetrics registers the metrics of the git smart HTTP server
+func RegisterGitMetrics() {
+	prometheus.MustRegister(GitFilteredFetches)
+}
@@ -0,0 +1,66 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Statuses of the webhook deliveries
+const (
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryRetrying  = "retrying"
+	WebhookDeliveryFailed    = "failed"
+)
+
+var (
+	// WebhookDeliveries counts the webhook delivery attempts by hook type and status,
+	// a failed attempt which will be retried has the retrying status.
+	WebhookDeliveries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: namespace + "webhook_deliveries_total",
+			Help: "Number of webhook delivery attempts",
+		},
+		[]string{"type", "status"},
+	)
+	// WebhookDeliveryRetries counts the webhook delivery attempts which are retries by hook type
+	WebhookDeliveryRetries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: namespace + "webhook_delivery_retries_total",
+			Help: "Number of webhook delivery retries",
+		},
+		[]string{"type"},
+	)
+	// WebhookDeliveryDuration observes the duration of the webhook delivery attempts by hook type
+	WebhookDeliveryDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    namespace + "webhook_delivery_duration_seconds",
+			Help:    "Duration of webhook delivery attempts",
+			Buckets: []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		},
+		[]string{"type"},
+	)
+	webhookQueueDepth = prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: namespace + "webhook_queue_depth",
+			Help: "Number of webhook deliveries waiting to be delivered or retried",
+		},
+		func() float64 {
+			count, err := models.CountUndeliveredHookTasks()
+			if err != nil {
+				log.Error("CountUndeliveredHookTasks: %v", err)
+			}
+			return float64(count)
+		},
+	)
+)
+
+// RegisterWebhookMetrics registers the metrics of the webhook delivery pipeline
+func RegisterWebhookMetrics() {
+	prometheus.MustRegister(WebhookDeliveries, WebhookDeliveryRetries, WebhookDeliveryDuration, webhookQueueDepth)
+}
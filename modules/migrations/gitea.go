@@ -13,9 +13,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
-	"path"
 	"path/filepath"
-	"strings"
 	"sync"
 	"time"
 
@@ -28,8 +26,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
-
-	gouuid "github.com/satori/go.uuid"
+	release_service "code.gitea.io/gitea/services/release"
 )
 
 var (
@@ -211,15 +208,13 @@ func (g *GiteaLocalUploader) CreateLabels(labels ...*base.Label) error {
 
 // CreateReleases creates releases
 func (g *GiteaLocalUploader) CreateReleases(releases ...*base.Release) error {
-	var rels = make([]*models.Release, 0, len(releases))
 	for _, release := range releases {
 		var rel = models.Release{
 			RepoID:       g.repo.ID,
+			Repo:         g.repo,
 			TagName:      release.TagName,
-			LowerTagName: strings.ToLower(release.TagName),
 			Target:       release.TargetCommitish,
 			Title:        release.Name,
-			Sha1:         release.TargetCommitish,
 			Note:         release.Body,
 			IsDraft:      release.Draft,
 			IsPrerelease: release.Prerelease,
@@ -248,57 +243,42 @@ func (g *GiteaLocalUploader) CreateReleases(releases ...*base.Release) error {
 			rel.OriginalAuthorID = release.PublisherID
 		}
 
-		// calc NumCommits
-		commit, err := g.gitRepo.GetCommit(rel.TagName)
-		if err != nil {
-			return fmt.Errorf("GetCommit: %v", err)
-		}
-		rel.NumCommits, err = commit.CommitsCount()
-		if err != nil {
-			return fmt.Errorf("CommitsCount: %v", err)
-		}
-
+		attachmentUUIDs := make([]string, 0, len(release.Assets))
 		for _, asset := range release.Assets {
-			var attach = models.Attachment{
-				UUID:          gouuid.NewV4().String(),
-				Name:          asset.Name,
-				DownloadCount: int64(*asset.DownloadCount),
-				Size:          int64(*asset.Size),
-				CreatedUnix:   timeutil.TimeStamp(asset.Created.Unix()),
-			}
-
-			// download attachment
-			err = func() error {
-				resp, err := http.Get(asset.URL)
-				if err != nil {
-					return err
-				}
-				defer resp.Body.Close()
-
-				localPath := attach.LocalPath()
-				if err = os.MkdirAll(path.Dir(localPath), os.ModePerm); err != nil {
-					return fmt.Errorf("MkdirAll: %v", err)
-				}
-
-				fw, err := os.Create(localPath)
-				if err != nil {
-					return fmt.Errorf("Create: %v", err)
-				}
-				defer fw.Close()
-
-				_, err = io.Copy(fw, resp.Body)
-				return err
-			}()
+			attach, err := g.downloadReleaseAsset(rel.PublisherID, asset)
 			if err != nil {
-				return err
+				return fmt.Errorf("download asset %s of release %s: %v", asset.Name, release.TagName, err)
 			}
-			rel.Attachments = append(rel.Attachments, &attach)
+			attachmentUUIDs = append(attachmentUUIDs, attach.UUID)
 		}
 
-		rels = append(rels, &rel)
+		if err := release_service.CreateMigratedRelease(g.gitRepo, &rel, attachmentUUIDs); err != nil {
+			return fmt.Errorf("CreateMigratedRelease: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// downloadReleaseAsset stores a release asset as a new attachment which isn't linked to a release yet
+func (g *GiteaLocalUploader) downloadReleaseAsset(uploaderID int64, asset base.ReleaseAsset) (*models.Attachment, error) {
+	resp, err := http.Get(asset.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
 	}
 
-	return models.InsertReleases(rels...)
+	attach := &models.Attachment{
+		UploaderID: uploaderID,
+		Name:       asset.Name,
+	}
+	if asset.DownloadCount != nil {
+		attach.DownloadCount = int64(*asset.DownloadCount)
+	}
+	return models.NewAttachment(attach, nil, resp.Body)
 }
 
 // SyncTags syncs releases with tags in the database
@@ -797,7 +777,7 @@ func (g *GiteaLocalUploader) CreateReviews(reviews ...*base.Review) error {
 
 			var patch string
 			patchBuf := new(bytes.Buffer)
-			if err := git.GetRepoRawDiffForFile(g.gitRepo, pr.MergeBase, headCommitID, git.RawDiffNormal, comment.TreePath, patchBuf); err != nil {
+			if err := git.GetRepoRawDiffForFile(g.ctx, g.gitRepo, pr.MergeBase, headCommitID, git.RawDiffNormal, comment.TreePath, patchBuf); err != nil {
 				// We should ignore the error since the commit maybe removed when force push to the pull request
 				log.Warn("GetRepoRawDiffForFile failed when migrating [%s, %s, %s, %s]: %v", g.gitRepo.Path, pr.MergeBase, headCommitID, comment.TreePath, err)
 			} else {
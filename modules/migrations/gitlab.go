@@ -282,11 +282,10 @@ func (g *GitlabDownloader) convertGitlabRelease(rel *gitlab.Release) *base.Relea
 		PublisherName:   rel.Author.Username,
 	}
 
-	for k, asset := range rel.Assets.Links {
+	for _, asset := range rel.Assets.Links {
 		r.Assets = append(r.Assets, base.ReleaseAsset{
-			URL:         asset.URL,
-			Name:        asset.Name,
-			ContentType: &rel.Assets.Sources[k].Format,
+			URL:  asset.URL,
+			Name: asset.Name,
 		})
 	}
 	return r
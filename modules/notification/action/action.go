@@ -86,6 +86,47 @@ func (a *actionNotifier) NotifyIssueChangeStatus(doer *models.User, issue *model
 	}
 }
 
+// NotifyCreateReleaseComment notifies comment on a release to notifiers
+func (a *actionNotifier) NotifyCreateReleaseComment(doer *models.User, rel *models.Release, comment *models.ReleaseComment) {
+	if err := rel.LoadAttributes(); err != nil {
+		log.Error("LoadAttributes: %v", err)
+		return
+	}
+
+	if err := models.NotifyWatchers(&models.Action{
+		ActUserID: doer.ID,
+		ActUser:   doer,
+		OpType:    models.ActionCommentRelease,
+		Content:   fmt.Sprintf("%s|%s", rel.TagName, comment.Content),
+		RepoID:    rel.RepoID,
+		Repo:      rel.Repo,
+		IsPrivate: rel.Repo.IsPrivate,
+	}); err != nil {
+		log.Error("NotifyWatchers: %v", err)
+	}
+}
+
+// NotifyNewRelease notifies a published release to notifiers
+func (a *actionNotifier) NotifyNewRelease(rel *models.Release) {
+	if err := rel.LoadAttributes(); err != nil {
+		log.Error("LoadAttributes: %v", err)
+		return
+	}
+
+	if err := models.NotifyWatchers(&models.Action{
+		ActUserID: rel.PublisherID,
+		ActUser:   rel.Publisher,
+		OpType:    models.ActionPublishRelease,
+		Content:   fmt.Sprintf("%s|%s", rel.TagName, rel.Title),
+		RepoID:    rel.RepoID,
+		Repo:      rel.Repo,
+		RefName:   rel.TagName,
+		IsPrivate: rel.Repo.IsPrivate,
+	}); err != nil {
+		log.Error("NotifyWatchers: %v", err)
+	}
+}
+
 // NotifyCreateIssueComment notifies comment on an issue to notifiers
 func (a *actionNotifier) NotifyCreateIssueComment(doer *models.User, repo *models.Repository,
 	issue *models.Issue, comment *models.Comment) {
@@ -19,6 +19,14 @@ type Notifier interface {
 	NotifyForkRepository(doer *models.User, oldRepo, repo *models.Repository)
 	NotifyRenameRepository(doer *models.User, repo *models.Repository, oldRepoName string)
 	NotifyTransferRepository(doer *models.User, repo *models.Repository, oldOwnerName string)
+	NotifyRepoPendingTransfer(doer, newOwner *models.User, repo *models.Repository)
+	NotifyRejectRepoTransfer(doer, newOwner *models.User, repo *models.Repository)
+	NotifyArchiveRepository(doer *models.User, repo *models.Repository)
+	NotifyUnarchiveRepository(doer *models.User, repo *models.Repository)
+
+	NotifyCreateUser(doer *models.User, u *models.User)
+	NotifyRenameUser(doer *models.User, u *models.User, oldName string)
+	NotifyDeleteUser(doer *models.User, u *models.User)
 
 	NotifyNewIssue(*models.Issue)
 	NotifyIssueChangeStatus(*models.User, *models.Issue, *models.Comment, bool)
@@ -37,6 +45,8 @@ type Notifier interface {
 	NotifyPullRequestReview(*models.PullRequest, *models.Review, *models.Comment)
 	NotifyPullRequestChangeTargetBranch(doer *models.User, pr *models.PullRequest, oldBranch string)
 	NotifyPullRequestPushCommits(doer *models.User, pr *models.PullRequest, comment *models.Comment)
+	NotifyPullRequestAutoMergeScheduled(doer *models.User, pr *models.PullRequest)
+	NotifyPullRequestAutoMergeCancelled(doer *models.User, pr *models.PullRequest)
 
 	NotifyCreateIssueComment(*models.User, *models.Repository,
 		*models.Issue, *models.Comment)
@@ -46,10 +56,17 @@ type Notifier interface {
 	NotifyNewRelease(rel *models.Release)
 	NotifyUpdateRelease(doer *models.User, rel *models.Release)
 	NotifyDeleteRelease(doer *models.User, rel *models.Release)
+	NotifyYankRelease(doer *models.User, rel *models.Release)
+	NotifyReleaseAssetUploaded(doer *models.User, rel *models.Release, attach *models.Attachment)
+	NotifyReleaseAssetDeleted(doer *models.User, rel *models.Release, attach *models.Attachment)
+	NotifyCreateReleaseComment(doer *models.User, rel *models.Release, comment *models.ReleaseComment)
+	NotifyUpdateReleaseComment(doer *models.User, comment *models.ReleaseComment, oldContent string)
+	NotifyDeleteReleaseComment(doer *models.User, comment *models.ReleaseComment)
 
 	NotifyPushCommits(pusher *models.User, repo *models.Repository, refName, oldCommitID, newCommitID string, commits *repository.PushCommits)
 	NotifyCreateRef(doer *models.User, repo *models.Repository, refType, refFullName string)
 	NotifyDeleteRef(doer *models.User, repo *models.Repository, refType, refFullName string)
+	NotifyCreateCommitStatus(creator *models.User, repo *models.Repository, sha string, status *models.CommitStatus)
 
 	NotifySyncPushCommits(pusher *models.User, repo *models.Repository, refName, oldCommitID, newCommitID string, commits *repository.PushCommits)
 	NotifySyncCreateRef(doer *models.User, repo *models.Repository, refType, refFullName string)
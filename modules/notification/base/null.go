@@ -58,6 +58,14 @@ func (*NullNotifier) NotifyPullRequestChangeTargetBranch(doer *models.User, pr *
 func (*NullNotifier) NotifyPullRequestPushCommits(doer *models.User, pr *models.PullRequest, comment *models.Comment) {
 }
 
+// NotifyPullRequestAutoMergeScheduled places a place holder function
+func (*NullNotifier) NotifyPullRequestAutoMergeScheduled(doer *models.User, pr *models.PullRequest) {
+}
+
+// NotifyPullRequestAutoMergeCancelled places a place holder function
+func (*NullNotifier) NotifyPullRequestAutoMergeCancelled(doer *models.User, pr *models.PullRequest) {
+}
+
 // NotifyUpdateComment places a place holder function
 func (*NullNotifier) NotifyUpdateComment(doer *models.User, c *models.Comment, oldContent string) {
 }
@@ -78,6 +86,30 @@ func (*NullNotifier) NotifyUpdateRelease(doer *models.User, rel *models.Release)
 func (*NullNotifier) NotifyDeleteRelease(doer *models.User, rel *models.Release) {
 }
 
+// NotifyYankRelease places a place holder function
+func (*NullNotifier) NotifyYankRelease(doer *models.User, rel *models.Release) {
+}
+
+// NotifyReleaseAssetUploaded places a place holder function
+func (*NullNotifier) NotifyReleaseAssetUploaded(doer *models.User, rel *models.Release, attach *models.Attachment) {
+}
+
+// NotifyReleaseAssetDeleted places a place holder function
+func (*NullNotifier) NotifyReleaseAssetDeleted(doer *models.User, rel *models.Release, attach *models.Attachment) {
+}
+
+// NotifyCreateReleaseComment places a place holder function
+func (*NullNotifier) NotifyCreateReleaseComment(doer *models.User, rel *models.Release, comment *models.ReleaseComment) {
+}
+
+// NotifyUpdateReleaseComment places a place holder function
+func (*NullNotifier) NotifyUpdateReleaseComment(doer *models.User, comment *models.ReleaseComment, oldContent string) {
+}
+
+// NotifyDeleteReleaseComment places a place holder function
+func (*NullNotifier) NotifyDeleteReleaseComment(doer *models.User, comment *models.ReleaseComment) {
+}
+
 // NotifyIssueChangeMilestone places a place holder function
 func (*NullNotifier) NotifyIssueChangeMilestone(doer *models.User, issue *models.Issue, oldMilestoneID int64) {
 }
@@ -127,6 +159,10 @@ func (*NullNotifier) NotifyMigrateRepository(doer *models.User, u *models.User,
 func (*NullNotifier) NotifyPushCommits(pusher *models.User, repo *models.Repository, refName, oldCommitID, newCommitID string, commits *repository.PushCommits) {
 }
 
+// NotifyCreateCommitStatus places a place holder function
+func (*NullNotifier) NotifyCreateCommitStatus(creator *models.User, repo *models.Repository, sha string, status *models.CommitStatus) {
+}
+
 // NotifyCreateRef notifies branch or tag creation to notifiers
 func (*NullNotifier) NotifyCreateRef(doer *models.User, repo *models.Repository, refType, refFullName string) {
 }
@@ -143,6 +179,34 @@ func (*NullNotifier) NotifyRenameRepository(doer *models.User, repo *models.Repo
 func (*NullNotifier) NotifyTransferRepository(doer *models.User, repo *models.Repository, oldOwnerName string) {
 }
 
+// NotifyRepoPendingTransfer places a place holder function
+func (*NullNotifier) NotifyRepoPendingTransfer(doer, newOwner *models.User, repo *models.Repository) {
+}
+
+// NotifyRejectRepoTransfer places a place holder function
+func (*NullNotifier) NotifyRejectRepoTransfer(doer, newOwner *models.User, repo *models.Repository) {
+}
+
+// NotifyArchiveRepository places a place holder function
+func (*NullNotifier) NotifyArchiveRepository(doer *models.User, repo *models.Repository) {
+}
+
+// NotifyUnarchiveRepository places a place holder function
+func (*NullNotifier) NotifyUnarchiveRepository(doer *models.User, repo *models.Repository) {
+}
+
+// NotifyCreateUser places a place holder function
+func (*NullNotifier) NotifyCreateUser(doer *models.User, u *models.User) {
+}
+
+// NotifyRenameUser places a place holder function
+func (*NullNotifier) NotifyRenameUser(doer *models.User, u *models.User, oldName string) {
+}
+
+// NotifyDeleteUser places a place holder function
+func (*NullNotifier) NotifyDeleteUser(doer *models.User, u *models.User) {
+}
+
 // NotifySyncPushCommits places a place holder function
 func (*NullNotifier) NotifySyncPushCommits(pusher *models.User, repo *models.Repository, refName, oldCommitID, newCommitID string, commits *repository.PushCommits) {
 }
@@ -0,0 +1,175 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package eventsource
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/convert"
+	eventsource_module "code.gitea.io/gitea/modules/eventsource"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification/base"
+	"code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+type eventSourceNotifier struct {
+	base.NullNotifier
+}
+
+var (
+	_ base.Notifier = &eventSourceNotifier{}
+)
+
+// NewNotifier create a new eventSourceNotifier notifier which streams
+// the pushes, issues, pull requests and releases of a repository to its listeners
+func NewNotifier() base.Notifier {
+	return &eventSourceNotifier{}
+}
+
+// sendRepoEvent sends the payload to the listeners of the repository, the payload
+// is only built if anyone listens as the events are sent for every repository
+func sendRepoEvent(repo *models.Repository, event models.HookEventType, payload func() (api.Payloader, error)) {
+	manager := eventsource_module.GetManager()
+	if !manager.HasRepoListeners(repo.ID) {
+		return
+	}
+	p, err := payload()
+	if err != nil {
+		log.Error("Unable to create %s event for repository %d: %v", event, repo.ID, err)
+		return
+	}
+	manager.SendRepoMessage(repo.ID, &eventsource_module.Event{
+		Name: string(event),
+		Data: p,
+	})
+}
+
+func sendPushEvent(pusher *models.User, repo *models.Repository, refName, oldCommitID, newCommitID string, commits *repository.PushCommits) {
+	sendRepoEvent(repo, models.HookEventPush, func() (api.Payloader, error) {
+		apiPusher := pusher.APIFormat()
+		apiCommits, err := commits.ToAPIPayloadCommits(repo.RepoPath(), repo.HTMLURL())
+		if err != nil {
+			return nil, err
+		}
+		return &api.PushPayload{
+			Ref:        refName,
+			Before:     oldCommitID,
+			After:      newCommitID,
+			CompareURL: setting.AppURL + commits.CompareURL,
+			Commits:    apiCommits,
+			Repo:       repo.APIFormat(models.AccessModeRead),
+			Pusher:     apiPusher,
+			Sender:     apiPusher,
+		}, nil
+	})
+}
+
+func (m *eventSourceNotifier) NotifyPushCommits(pusher *models.User, repo *models.Repository, refName, oldCommitID, newCommitID string, commits *repository.PushCommits) {
+	sendPushEvent(pusher, repo, refName, oldCommitID, newCommitID, commits)
+}
+
+func (m *eventSourceNotifier) NotifySyncPushCommits(pusher *models.User, repo *models.Repository, refName, oldCommitID, newCommitID string, commits *repository.PushCommits) {
+	sendPushEvent(pusher, repo, refName, oldCommitID, newCommitID, commits)
+}
+
+func sendIssueEvent(doer *models.User, issue *models.Issue, action api.HookIssueAction) {
+	if err := issue.LoadRepo(); err != nil {
+		log.Error("issue.LoadRepo: %v", err)
+		return
+	}
+	if issue.IsPull {
+		sendRepoEvent(issue.Repo, models.HookEventPullRequest, func() (api.Payloader, error) {
+			if err := issue.LoadPullRequest(); err != nil {
+				return nil, err
+			}
+			return &api.PullRequestPayload{
+				Action:      action,
+				Index:       issue.Index,
+				PullRequest: convert.ToAPIPullRequest(issue.PullRequest),
+				Repository:  issue.Repo.APIFormat(models.AccessModeRead),
+				Sender:      doer.APIFormat(),
+			}, nil
+		})
+		return
+	}
+	sendRepoEvent(issue.Repo, models.HookEventIssues, func() (api.Payloader, error) {
+		return &api.IssuePayload{
+			Action:     action,
+			Index:      issue.Index,
+			Issue:      convert.ToAPIIssue(issue),
+			Repository: issue.Repo.APIFormat(models.AccessModeRead),
+			Sender:     doer.APIFormat(),
+		}, nil
+	})
+}
+
+func (m *eventSourceNotifier) NotifyNewIssue(issue *models.Issue) {
+	if err := issue.LoadPoster(); err != nil {
+		log.Error("issue.LoadPoster: %v", err)
+		return
+	}
+	sendIssueEvent(issue.Poster, issue, api.HookIssueOpened)
+}
+
+func (m *eventSourceNotifier) NotifyIssueChangeStatus(doer *models.User, issue *models.Issue, actionComment *models.Comment, isClosed bool) {
+	if isClosed {
+		sendIssueEvent(doer, issue, api.HookIssueClosed)
+	} else {
+		sendIssueEvent(doer, issue, api.HookIssueReOpened)
+	}
+}
+
+func (m *eventSourceNotifier) NotifyNewPullRequest(pull *models.PullRequest) {
+	if err := pull.LoadIssue(); err != nil {
+		log.Error("pull.LoadIssue: %v", err)
+		return
+	}
+	if err := pull.Issue.LoadPoster(); err != nil {
+		log.Error("pull.Issue.LoadPoster: %v", err)
+		return
+	}
+	sendIssueEvent(pull.Issue.Poster, pull.Issue, api.HookIssueOpened)
+}
+
+func (m *eventSourceNotifier) NotifyMergePullRequest(pr *models.PullRequest, doer *models.User) {
+	if err := pr.LoadIssue(); err != nil {
+		log.Error("pr.LoadIssue: %v", err)
+		return
+	}
+	// Merge pull request calls issue.changeStatus so we need to handle separately.
+	sendIssueEvent(doer, pr.Issue, api.HookIssueClosed)
+}
+
+func sendReleaseEvent(doer *models.User, rel *models.Release, action api.HookReleaseAction) {
+	if err := rel.LoadAttributes(); err != nil {
+		log.Error("LoadAttributes: %v", err)
+		return
+	}
+	if doer == nil {
+		doer = rel.Publisher
+	}
+	sendRepoEvent(rel.Repo, models.HookEventRelease, func() (api.Payloader, error) {
+		return &api.ReleasePayload{
+			Action:     action,
+			Release:    rel.APIFormat(),
+			Repository: rel.Repo.APIFormat(models.AccessModeRead),
+			Sender:     doer.APIFormat(),
+		}, nil
+	})
+}
+
+func (m *eventSourceNotifier) NotifyNewRelease(rel *models.Release) {
+	// the publisher is the sender of a new release
+	sendReleaseEvent(nil, rel, api.HookReleasePublished)
+}
+
+func (m *eventSourceNotifier) NotifyUpdateRelease(doer *models.User, rel *models.Release) {
+	sendReleaseEvent(doer, rel, api.HookReleaseUpdated)
+}
+
+func (m *eventSourceNotifier) NotifyDeleteRelease(doer *models.User, rel *models.Release) {
+	sendReleaseEvent(doer, rel, api.HookReleaseDeleted)
+}
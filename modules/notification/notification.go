@@ -8,6 +8,7 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/notification/action"
 	"code.gitea.io/gitea/modules/notification/base"
+	"code.gitea.io/gitea/modules/notification/eventsource"
 	"code.gitea.io/gitea/modules/notification/indexer"
 	"code.gitea.io/gitea/modules/notification/mail"
 	"code.gitea.io/gitea/modules/notification/ui"
@@ -35,6 +36,7 @@ func NewContext() {
 	RegisterNotifier(indexer.NewNotifier())
 	RegisterNotifier(webhook.NewNotifier())
 	RegisterNotifier(action.NewNotifier())
+	RegisterNotifier(eventsource.NewNotifier())
 }
 
 // NotifyCreateIssueComment notifies issue comment related message to notifiers
@@ -101,6 +103,20 @@ func NotifyPullRequestPushCommits(doer *models.User, pr *models.PullRequest, com
 	}
 }
 
+// NotifyPullRequestAutoMergeScheduled notifies when the merge of a pull request is scheduled for once it is ready
+func NotifyPullRequestAutoMergeScheduled(doer *models.User, pr *models.PullRequest) {
+	for _, notifier := range notifiers {
+		notifier.NotifyPullRequestAutoMergeScheduled(doer, pr)
+	}
+}
+
+// NotifyPullRequestAutoMergeCancelled notifies when the scheduled merge of a pull request is cancelled
+func NotifyPullRequestAutoMergeCancelled(doer *models.User, pr *models.PullRequest) {
+	for _, notifier := range notifiers {
+		notifier.NotifyPullRequestAutoMergeCancelled(doer, pr)
+	}
+}
+
 // NotifyUpdateComment notifies update comment to notifiers
 func NotifyUpdateComment(doer *models.User, c *models.Comment, oldContent string) {
 	for _, notifier := range notifiers {
@@ -136,6 +152,48 @@ func NotifyDeleteRelease(doer *models.User, rel *models.Release) {
 	}
 }
 
+// NotifyYankRelease notifies yank release to notifiers
+func NotifyYankRelease(doer *models.User, rel *models.Release) {
+	for _, notifier := range notifiers {
+		notifier.NotifyYankRelease(doer, rel)
+	}
+}
+
+// NotifyReleaseAssetUploaded notifies the upload of a release asset to notifiers
+func NotifyReleaseAssetUploaded(doer *models.User, rel *models.Release, attach *models.Attachment) {
+	for _, notifier := range notifiers {
+		notifier.NotifyReleaseAssetUploaded(doer, rel, attach)
+	}
+}
+
+// NotifyReleaseAssetDeleted notifies the deletion of a release asset to notifiers
+func NotifyReleaseAssetDeleted(doer *models.User, rel *models.Release, attach *models.Attachment) {
+	for _, notifier := range notifiers {
+		notifier.NotifyReleaseAssetDeleted(doer, rel, attach)
+	}
+}
+
+// NotifyCreateReleaseComment notifies release comment creation to notifiers
+func NotifyCreateReleaseComment(doer *models.User, rel *models.Release, comment *models.ReleaseComment) {
+	for _, notifier := range notifiers {
+		notifier.NotifyCreateReleaseComment(doer, rel, comment)
+	}
+}
+
+// NotifyUpdateReleaseComment notifies update release comment to notifiers
+func NotifyUpdateReleaseComment(doer *models.User, comment *models.ReleaseComment, oldContent string) {
+	for _, notifier := range notifiers {
+		notifier.NotifyUpdateReleaseComment(doer, comment, oldContent)
+	}
+}
+
+// NotifyDeleteReleaseComment notifies delete release comment to notifiers
+func NotifyDeleteReleaseComment(doer *models.User, comment *models.ReleaseComment) {
+	for _, notifier := range notifiers {
+		notifier.NotifyDeleteReleaseComment(doer, comment)
+	}
+}
+
 // NotifyIssueChangeMilestone notifies change milestone to notifiers
 func NotifyIssueChangeMilestone(doer *models.User, issue *models.Issue, oldMilestoneID int64) {
 	for _, notifier := range notifiers {
@@ -200,10 +258,59 @@ func NotifyMigrateRepository(doer *models.User, u *models.User, repo *models.Rep
 	}
 }
 
-// NotifyTransferRepository notifies create repository to notifiers
-func NotifyTransferRepository(doer *models.User, repo *models.Repository, newOwnerName string) {
+// NotifyTransferRepository notifies transfer repository to notifiers
+func NotifyTransferRepository(doer *models.User, repo *models.Repository, oldOwnerName string) {
 	for _, notifier := range notifiers {
-		notifier.NotifyTransferRepository(doer, repo, newOwnerName)
+		notifier.NotifyTransferRepository(doer, repo, oldOwnerName)
+	}
+}
+
+// NotifyRepoPendingTransfer notifies a request to transfer a repository to notifiers
+func NotifyRepoPendingTransfer(doer, newOwner *models.User, repo *models.Repository) {
+	for _, notifier := range notifiers {
+		notifier.NotifyRepoPendingTransfer(doer, newOwner, repo)
+	}
+}
+
+// NotifyRejectRepoTransfer notifies a rejected or cancelled request to transfer a repository to notifiers
+func NotifyRejectRepoTransfer(doer, newOwner *models.User, repo *models.Repository) {
+	for _, notifier := range notifiers {
+		notifier.NotifyRejectRepoTransfer(doer, newOwner, repo)
+	}
+}
+
+// NotifyArchiveRepository notifies archive repository to notifiers
+func NotifyArchiveRepository(doer *models.User, repo *models.Repository) {
+	for _, notifier := range notifiers {
+		notifier.NotifyArchiveRepository(doer, repo)
+	}
+}
+
+// NotifyUnarchiveRepository notifies unarchive repository to notifiers
+func NotifyUnarchiveRepository(doer *models.User, repo *models.Repository) {
+	for _, notifier := range notifiers {
+		notifier.NotifyUnarchiveRepository(doer, repo)
+	}
+}
+
+// NotifyCreateUser notifies the creation of a user or an organization to notifiers
+func NotifyCreateUser(doer *models.User, u *models.User) {
+	for _, notifier := range notifiers {
+		notifier.NotifyCreateUser(doer, u)
+	}
+}
+
+// NotifyRenameUser notifies the renaming of a user or an organization to notifiers
+func NotifyRenameUser(doer *models.User, u *models.User, oldName string) {
+	for _, notifier := range notifiers {
+		notifier.NotifyRenameUser(doer, u, oldName)
+	}
+}
+
+// NotifyDeleteUser notifies the deletion of a user or an organization to notifiers
+func NotifyDeleteUser(doer *models.User, u *models.User) {
+	for _, notifier := range notifiers {
+		notifier.NotifyDeleteUser(doer, u)
 	}
 }
 
@@ -235,6 +342,13 @@ func NotifyPushCommits(pusher *models.User, repo *models.Repository, refName, ol
 	}
 }
 
+// NotifyCreateCommitStatus notifies commit status creation to notifiers
+func NotifyCreateCommitStatus(creator *models.User, repo *models.Repository, sha string, status *models.CommitStatus) {
+	for _, notifier := range notifiers {
+		notifier.NotifyCreateCommitStatus(creator, repo, sha, status)
+	}
+}
+
 // NotifyCreateRef notifies branch or tag creation to notifiers
 func NotifyCreateRef(pusher *models.User, repo *models.Repository, refType, refFullName string) {
 	for _, notifier := range notifiers {
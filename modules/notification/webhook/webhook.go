@@ -126,6 +126,59 @@ func (m *webhookNotifier) NotifyDeleteRepository(doer *models.User, repo *models
 	}
 }
 
+// prepareRepositoryWebhooks sends a repository event with the given payload.
+func prepareRepositoryWebhooks(doer *models.User, repo *models.Repository, p *api.RepositoryPayload) {
+	if u := repo.MustOwner(); u.IsOrganization() {
+		p.Organization = u.APIFormat()
+	}
+	p.Repository = repo.APIFormat(models.AccessModeOwner)
+	p.Sender = doer.APIFormat()
+
+	if err := webhook_module.PrepareWebhooks(repo, models.HookEventRepository, p); err != nil {
+		log.Error("PrepareWebhooks [repo_id: %d]: %v", repo.ID, err)
+	}
+}
+
+func (m *webhookNotifier) NotifyRenameRepository(doer *models.User, repo *models.Repository, oldRepoName string) {
+	prepareRepositoryWebhooks(doer, repo, &api.RepositoryPayload{
+		Action:  api.HookRepoRenamed,
+		OldName: oldRepoName,
+	})
+}
+
+func (m *webhookNotifier) NotifyTransferRepository(doer *models.User, repo *models.Repository, oldOwnerName string) {
+	prepareRepositoryWebhooks(doer, repo, &api.RepositoryPayload{
+		Action:   api.HookRepoTransferred,
+		OldOwner: oldOwnerName,
+	})
+}
+
+func (m *webhookNotifier) NotifyRepoPendingTransfer(doer, newOwner *models.User, repo *models.Repository) {
+	prepareRepositoryWebhooks(doer, repo, &api.RepositoryPayload{
+		Action:   api.HookRepoTransferRequested,
+		NewOwner: newOwner.Name,
+	})
+}
+
+func (m *webhookNotifier) NotifyRejectRepoTransfer(doer, newOwner *models.User, repo *models.Repository) {
+	prepareRepositoryWebhooks(doer, repo, &api.RepositoryPayload{
+		Action:   api.HookRepoTransferRejected,
+		NewOwner: newOwner.Name,
+	})
+}
+
+func (m *webhookNotifier) NotifyArchiveRepository(doer *models.User, repo *models.Repository) {
+	prepareRepositoryWebhooks(doer, repo, &api.RepositoryPayload{
+		Action: api.HookRepoArchived,
+	})
+}
+
+func (m *webhookNotifier) NotifyUnarchiveRepository(doer *models.User, repo *models.Repository) {
+	prepareRepositoryWebhooks(doer, repo, &api.RepositoryPayload{
+		Action: api.HookRepoUnarchived,
+	})
+}
+
 func (m *webhookNotifier) NotifyIssueChangeAssignee(doer *models.User, issue *models.Issue, assignee *models.User, removed bool, comment *models.Comment) {
 	if issue.IsPull {
 		mode, _ := models.AccessLevelUnit(doer, issue.Repo, models.UnitTypePullRequests)
@@ -552,6 +605,30 @@ func (m *webhookNotifier) NotifyIssueChangeMilestone(doer *models.User, issue *m
 	}
 }
 
+// loadCommitVerifications adds the signature verification of the commits to a push payload,
+// so the receivers can check the commits are signed without calling back the API.
+func loadCommitVerifications(repo *models.Repository, commits []*api.PayloadCommit) {
+	if len(commits) == 0 {
+		return
+	}
+
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		log.Error("OpenRepository[%s]: %v", repo.RepoPath(), err)
+		return
+	}
+	defer gitRepo.Close()
+
+	for _, c := range commits {
+		commit, err := gitRepo.GetCommit(c.ID)
+		if err != nil {
+			log.Error("GetCommit[%s]: %v", c.ID, err)
+			continue
+		}
+		c.Verification = convert.ToVerification(repo, commit)
+	}
+}
+
 func (m *webhookNotifier) NotifyPushCommits(pusher *models.User, repo *models.Repository, refName, oldCommitID, newCommitID string, commits *repository.PushCommits) {
 	apiPusher := pusher.APIFormat()
 	apiCommits, err := commits.ToAPIPayloadCommits(repo.RepoPath(), repo.HTMLURL())
@@ -559,6 +636,7 @@ func (m *webhookNotifier) NotifyPushCommits(pusher *models.User, repo *models.Re
 		log.Error("commits.ToAPIPayloadCommits failed: %v", err)
 		return
 	}
+	loadCommitVerifications(repo, apiCommits)
 
 	if err := webhook_module.PrepareWebhooks(repo, models.HookEventPush, &api.PushPayload{
 		Ref:        refName,
@@ -735,6 +813,36 @@ func (m *webhookNotifier) NotifyPullRequestSynchronized(doer *models.User, pr *m
 	}
 }
 
+func (m *webhookNotifier) NotifyPullRequestAutoMergeScheduled(doer *models.User, pr *models.PullRequest) {
+	sendPullRequestAutoMergeHook(doer, pr, api.HookIssueAutoMergeScheduled)
+}
+
+func (m *webhookNotifier) NotifyPullRequestAutoMergeCancelled(doer *models.User, pr *models.PullRequest) {
+	sendPullRequestAutoMergeHook(doer, pr, api.HookIssueAutoMergeCancelled)
+}
+
+func sendPullRequestAutoMergeHook(doer *models.User, pr *models.PullRequest, action api.HookIssueAction) {
+	if err := pr.LoadIssue(); err != nil {
+		log.Error("pr.LoadIssue: %v", err)
+		return
+	}
+	if err := pr.Issue.LoadAttributes(); err != nil {
+		log.Error("LoadAttributes: %v", err)
+		return
+	}
+
+	mode, _ := models.AccessLevel(doer, pr.Issue.Repo)
+	if err := webhook_module.PrepareWebhooks(pr.Issue.Repo, models.HookEventPullRequest, &api.PullRequestPayload{
+		Action:      action,
+		Index:       pr.Issue.Index,
+		PullRequest: convert.ToAPIPullRequest(pr),
+		Repository:  pr.Issue.Repo.APIFormat(mode),
+		Sender:      doer.APIFormat(),
+	}); err != nil {
+		log.Error("PrepareWebhooks [pull_id: %v]: %v", pr.ID, err)
+	}
+}
+
 func (m *webhookNotifier) NotifyDeleteRef(pusher *models.User, repo *models.Repository, refType, refFullName string) {
 	apiPusher := pusher.APIFormat()
 	apiRepo := repo.APIFormat(models.AccessModeNone)
@@ -751,33 +859,54 @@ func (m *webhookNotifier) NotifyDeleteRef(pusher *models.User, repo *models.Repo
 	}
 }
 
-func sendReleaseHook(doer *models.User, rel *models.Release, action api.HookReleaseAction) {
+func sendReleaseHook(doer *models.User, rel *models.Release, action api.HookReleaseAction, asset *models.Attachment) {
 	if err := rel.LoadAttributes(); err != nil {
 		log.Error("LoadAttributes: %v", err)
 		return
 	}
+	if doer == nil {
+		doer = rel.Publisher
+	}
 
-	mode, _ := models.AccessLevel(rel.Publisher, rel.Repo)
-	if err := webhook_module.PrepareWebhooks(rel.Repo, models.HookEventRelease, &api.ReleasePayload{
-		Action:     action,
-		Release:    rel.APIFormat(),
-		Repository: rel.Repo.APIFormat(mode),
-		Sender:     rel.Publisher.APIFormat(),
-	}); err != nil {
+	payload := &api.ReleasePayload{
+		Action:  action,
+		Release: rel.APIFormat(),
+		Sender:  doer.APIFormat(),
+	}
+	if asset != nil {
+		payload.Asset = asset.APIFormat()
+	}
+
+	mode, _ := models.AccessLevel(doer, rel.Repo)
+	payload.Repository = rel.Repo.APIFormat(mode)
+	if err := webhook_module.PrepareWebhooks(rel.Repo, models.HookEventRelease, payload); err != nil {
 		log.Error("PrepareWebhooks: %v", err)
 	}
 }
 
 func (m *webhookNotifier) NotifyNewRelease(rel *models.Release) {
-	sendReleaseHook(rel.Publisher, rel, api.HookReleasePublished)
+	// the publisher is the sender of a new release
+	sendReleaseHook(nil, rel, api.HookReleasePublished, nil)
 }
 
 func (m *webhookNotifier) NotifyUpdateRelease(doer *models.User, rel *models.Release) {
-	sendReleaseHook(doer, rel, api.HookReleaseUpdated)
+	sendReleaseHook(doer, rel, api.HookReleaseUpdated, nil)
 }
 
 func (m *webhookNotifier) NotifyDeleteRelease(doer *models.User, rel *models.Release) {
-	sendReleaseHook(doer, rel, api.HookReleaseDeleted)
+	sendReleaseHook(doer, rel, api.HookReleaseDeleted, nil)
+}
+
+func (m *webhookNotifier) NotifyYankRelease(doer *models.User, rel *models.Release) {
+	sendReleaseHook(doer, rel, api.HookReleaseYanked, nil)
+}
+
+func (m *webhookNotifier) NotifyReleaseAssetUploaded(doer *models.User, rel *models.Release, attach *models.Attachment) {
+	sendReleaseHook(doer, rel, api.HookReleaseAssetUploaded, attach)
+}
+
+func (m *webhookNotifier) NotifyReleaseAssetDeleted(doer *models.User, rel *models.Release, attach *models.Attachment) {
+	sendReleaseHook(doer, rel, api.HookReleaseAssetDeleted, attach)
 }
 
 func (m *webhookNotifier) NotifySyncPushCommits(pusher *models.User, repo *models.Repository, refName, oldCommitID, newCommitID string, commits *repository.PushCommits) {
@@ -787,6 +916,7 @@ func (m *webhookNotifier) NotifySyncPushCommits(pusher *models.User, repo *model
 		log.Error("commits.ToAPIPayloadCommits failed: %v", err)
 		return
 	}
+	loadCommitVerifications(repo, apiCommits)
 
 	if err := webhook_module.PrepareWebhooks(repo, models.HookEventPush, &api.PushPayload{
 		Ref:        refName,
@@ -801,3 +931,41 @@ func (m *webhookNotifier) NotifySyncPushCommits(pusher *models.User, repo *model
 		log.Error("PrepareWebhooks: %v", err)
 	}
 }
+
+func sendAccountHook(doer *models.User, u *models.User, action api.HookAccountAction, oldName string) {
+	if doer == nil {
+		doer = u
+	}
+
+	var err error
+	if u.IsOrganization() {
+		err = webhook_module.PrepareSystemWebhooks(models.HookEventOrganization, &api.OrganizationPayload{
+			Action:       action,
+			Organization: convert.ToOrganization(u),
+			OldName:      oldName,
+			Sender:       doer.APIFormat(),
+		})
+	} else {
+		err = webhook_module.PrepareSystemWebhooks(models.HookEventUser, &api.UserPayload{
+			Action:  action,
+			User:    u.APIFormat(),
+			OldName: oldName,
+			Sender:  doer.APIFormat(),
+		})
+	}
+	if err != nil {
+		log.Error("PrepareSystemWebhooks: %v", err)
+	}
+}
+
+func (m *webhookNotifier) NotifyCreateUser(doer *models.User, u *models.User) {
+	sendAccountHook(doer, u, api.HookAccountCreated, "")
+}
+
+func (m *webhookNotifier) NotifyRenameUser(doer *models.User, u *models.User, oldName string) {
+	sendAccountHook(doer, u, api.HookAccountRenamed, oldName)
+}
+
+func (m *webhookNotifier) NotifyDeleteUser(doer *models.User, u *models.User) {
+	sendAccountHook(doer, u, api.HookAccountDeleted, "")
+}
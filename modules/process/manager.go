@@ -72,6 +72,19 @@ func (pm *Manager) Add(description string, cancel context.CancelFunc) int64 {
 	return pid
 }
 
+// AddContextTimeout adds a process to the ProcessManager with a context of the parent,
+// which is canceled after the timeout or when the process is cancelled. The returned
+// cancel function has to be called once the process has finished, it removes it again.
+func (pm *Manager) AddContextTimeout(parent context.Context, timeout time.Duration, description string) (ctx context.Context, cancel context.CancelFunc, pid int64) {
+	ctx, cancelCtx := context.WithTimeout(parent, timeout)
+	pid = pm.Add(description, cancelCtx)
+	cancel = func() {
+		pm.Remove(pid)
+		cancelCtx()
+	}
+	return ctx, cancel, pid
+}
+
 // Remove a process from the ProcessManager.
 func (pm *Manager) Remove(pid int64) {
 	pm.mutex.Lock()
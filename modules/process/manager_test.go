@@ -52,6 +52,26 @@ func TestManager_Remove(t *testing.T) {
 	assert.False(t, exists, "PID %d is in the list but shouldn't", pid2)
 }
 
+func TestManager_AddContextTimeout(t *testing.T) {
+	pm := Manager{processes: make(map[int64]*Process)}
+
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel, pid := pm.AddContextTimeout(parent, time.Minute, "foo")
+	_, exists := pm.processes[pid]
+	assert.True(t, exists, "PID %d should be in the list", pid)
+
+	cancelParent()
+	select {
+	case <-ctx.Done():
+	default:
+		assert.Fail(t, "Canceling the parent should cancel the context of the process")
+	}
+
+	cancel()
+	_, exists = pm.processes[pid]
+	assert.False(t, exists, "PID %d is in the list but shouldn't", pid)
+}
+
 func TestExecTimeoutNever(t *testing.T) {
 
 	// TODO Investigate how to improve the time elapsed per round.
@@ -0,0 +1,55 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repofiles
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// RestoreDeletedBranch recreates a deleted branch at the commit it pointed to when it was deleted
+func RestoreDeletedBranch(doer *models.User, repo *models.Repository, deletedBranch *models.DeletedBranch) error {
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer gitRepo.Close()
+
+	// The commit may have been pruned by the garbage collection since
+	if !gitRepo.IsCommitExist(deletedBranch.Commit) {
+		return git.ErrNotExist{ID: deletedBranch.Commit}
+	}
+	if gitRepo.IsBranchExist(deletedBranch.Name) {
+		return models.ErrBranchAlreadyExists{
+			BranchName: deletedBranch.Name,
+		}
+	}
+
+	if err := gitRepo.CreateBranch(deletedBranch.Name, deletedBranch.Commit); err != nil {
+		return err
+	}
+
+	if err := models.RemoveDeletedBranch(repo.ID, deletedBranch.Name); err != nil {
+		return err
+	}
+
+	// Don't return error below this
+	if err := PushUpdate(
+		repo,
+		deletedBranch.Name,
+		PushUpdateOptions{
+			RefFullName:  git.BranchPrefix + deletedBranch.Name,
+			OldCommitID:  git.EmptySHA,
+			NewCommitID:  deletedBranch.Commit,
+			PusherID:     doer.ID,
+			PusherName:   doer.Name,
+			RepoUserName: repo.MustOwner().Name,
+			RepoName:     repo.Name,
+		}); err != nil {
+		log.Error("Update: %v", err)
+	}
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/lfs"
 	api "code.gitea.io/gitea/modules/structs"
 )
 
@@ -168,6 +169,13 @@ func GetContents(repo *models.Repository, treePath, ref string, forList bool) (*
 			contentsResponse.Encoding = &blobResponse.Encoding
 			contentsResponse.Content = &blobResponse.Content
 		}
+		if entry.Blob().Size() < lfs.MetaFileMaxSize {
+			content, err := entry.Blob().GetBlobContent()
+			if err != nil {
+				return nil, err
+			}
+			contentsResponse.LFS = lfs.ParsePointer([]byte(content)) != nil
+		}
 	} else if entry.IsDir() {
 		contentsResponse.Type = string(ContentTypeDir)
 	} else if entry.IsLink() {
@@ -188,7 +196,12 @@ func GetContents(repo *models.Repository, treePath, ref string, forList bool) (*
 	}
 	// Handle links
 	if entry.IsRegular() || entry.IsLink() {
-		downloadURL, err := url.Parse(fmt.Sprintf("%s/raw/%s/%s/%s", repo.HTMLURL(), refType, ref, treePath))
+		// The content of LFS files is downloaded from the media link instead of their pointer
+		download := "raw"
+		if contentsResponse.LFS {
+			download = "media"
+		}
+		downloadURL, err := url.Parse(fmt.Sprintf("%s/%s/%s/%s/%s", repo.HTMLURL(), download, refType, ref, treePath))
 		if err != nil {
 			return nil, err
 		}
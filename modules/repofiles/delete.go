@@ -96,6 +96,11 @@ func DeleteRepoFile(repo *models.Repository, doer *models.User, opts *DeleteRepo
 		}
 	}
 
+	// Check the file is not lfs locked
+	if err := checkTreePathLock(repo, doer, treePath); err != nil {
+		return nil, err
+	}
+
 	message := strings.TrimSpace(opts.Message)
 
 	author, committer := GetAuthorAndCommitterUsers(opts.Author, opts.Committer, doer)
@@ -108,9 +113,6 @@ func DeleteRepoFile(repo *models.Repository, doer *models.User, opts *DeleteRepo
 	if err := t.Clone(opts.OldBranch); err != nil {
 		return nil, err
 	}
-	if err := t.SetDefaultIndex(); err != nil {
-		return nil, err
-	}
 
 	// Get the commit of the original branch
 	commit, err := t.GetBranchCommit(opts.OldBranch)
@@ -129,31 +131,21 @@ func DeleteRepoFile(repo *models.Repository, doer *models.User, opts *DeleteRepo
 		opts.LastCommitID = lastCommitID.String()
 	}
 
-	// Get the files in the index
-	filesInIndex, err := t.LsFiles(opts.TreePath)
+	// Get the entry of treePath, which must be a file, and check if the SHA given is the same as the file
+	entry, err := commit.GetTreeEntryByPath(treePath)
 	if err != nil {
-		return nil, fmt.Errorf("DeleteRepoFile: %v", err)
-	}
-
-	// Find the file we want to delete in the index
-	inFilelist := false
-	for _, file := range filesInIndex {
-		if file == opts.TreePath {
-			inFilelist = true
-			break
+		if git.IsErrNotExist(err) {
+			return nil, models.ErrRepoFileDoesNotExist{
+				Path: opts.TreePath,
+			}
 		}
+		return nil, err
 	}
-	if !inFilelist {
+	if entry.IsDir() || entry.IsSubModule() {
 		return nil, models.ErrRepoFileDoesNotExist{
 			Path: opts.TreePath,
 		}
 	}
-
-	// Get the entry of treePath and check if the SHA given is the same as the file
-	entry, err := commit.GetTreeEntryByPath(treePath)
-	if err != nil {
-		return nil, err
-	}
 	if opts.SHA != "" {
 		// If a SHA was given and the SHA given doesn't match the SHA of the fromTreePath, throw error
 		if opts.SHA != entry.ID.String() {
@@ -185,13 +177,8 @@ func DeleteRepoFile(repo *models.Repository, doer *models.User, opts *DeleteRepo
 		return nil, models.ErrSHAOrCommitIDNotProvided{}
 	}
 
-	// Remove the file from the index
-	if err := t.RemoveFilesFromIndex(opts.TreePath); err != nil {
-		return nil, err
-	}
-
-	// Now write the tree
-	treeHash, err := t.WriteTree()
+	// Remove the file from the tree, only the trees of its directories are written
+	treeHash, err := t.UpdateTreeEntry(commit.Tree.ID.String(), treePath, "", "")
 	if err != nil {
 		return nil, err
 	}
@@ -24,7 +24,8 @@ func GetDiffPreview(repo *models.Repository, branch, treePath, content string) (
 	if err := t.Clone(branch); err != nil {
 		return nil, err
 	}
-	if err := t.SetDefaultIndex(); err != nil {
+	commit, err := t.GetBranchCommit(branch)
+	if err != nil {
 		return nil, err
 	}
 
@@ -34,9 +35,10 @@ func GetDiffPreview(repo *models.Repository, branch, treePath, content string) (
 		return nil, err
 	}
 
-	// Add the object to the index
-	if err := t.AddObjectToIndex("100644", objectHash, treePath); err != nil {
+	// Add the object to the tree
+	treeHash, err := t.UpdateTreeEntry(commit.Tree.ID.String(), treePath, "100644", objectHash)
+	if err != nil {
 		return nil, err
 	}
-	return t.DiffIndex()
+	return t.DiffTree(treeHash)
 }
@@ -72,10 +72,11 @@ func getExpectedFileResponse() *api.FileResponse {
 			},
 		},
 		Verification: &api.PayloadCommitVerification{
-			Verified:  false,
-			Reason:    "gpg.error.not_signed_commit",
-			Signature: "",
-			Payload:   "",
+			Verified:   false,
+			Reason:     "gpg.error.not_signed_commit",
+			Signature:  "",
+			Payload:    "",
+			TrustModel: "collaborator",
 		},
 	}
 }
@@ -7,6 +7,8 @@ package repofiles
 import (
 	"path"
 	"strings"
+
+	"code.gitea.io/gitea/models"
 )
 
 // CleanUploadFileName Trims a filename and returns empty string if it is a .git directory
@@ -21,3 +23,15 @@ func CleanUploadFileName(name string) string {
 	}
 	return name
 }
+
+// checkTreePathLock returns an ErrLFSFileLocked if the file at treePath is locked by another user than the doer
+func checkTreePathLock(repo *models.Repository, doer *models.User, treePath string) error {
+	lfsLock, err := repo.GetTreePathLock(treePath)
+	if err != nil {
+		return err
+	}
+	if lfsLock != nil && lfsLock.OwnerID != doer.ID {
+		return models.ErrLFSFileLocked{RepoID: repo.ID, Path: treePath, UserName: lfsLock.Owner.Name}
+	}
+	return nil
+}
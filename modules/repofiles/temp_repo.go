@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"regexp"
 	"strings"
 	"time"
@@ -168,6 +169,138 @@ func (t *TemporaryUploadRepository) WriteTree() (string, error) {
 	return strings.TrimSpace(stdout), nil
 }
 
+// SetAttributesIndex sets the git index to the .gitattributes files of the directories of the paths in the commit,
+// which is all check-attr needs to check the attributes of the paths without reading the whole tree
+func (t *TemporaryUploadRepository) SetAttributesIndex(commit *git.Commit, treePaths ...string) error {
+	stdIn := new(bytes.Buffer)
+	added := make(map[string]bool)
+	for _, treePath := range treePaths {
+		for dir := path.Dir(treePath); ; dir = path.Dir(dir) {
+			attributesPath := path.Join(dir, ".gitattributes")
+			if added[attributesPath] {
+				break
+			}
+			added[attributesPath] = true
+			entry, err := commit.GetTreeEntryByPath(attributesPath)
+			if err != nil && !git.IsErrNotExist(err) {
+				return err
+			}
+			if entry != nil && entry.IsRegular() {
+				stdIn.WriteString(fmt.Sprintf("%06o %s\t%s\000", entry.Mode(), entry.ID.String(), attributesPath))
+			}
+			if dir == "." {
+				break
+			}
+		}
+	}
+
+	stdOut := new(bytes.Buffer)
+	stdErr := new(bytes.Buffer)
+	if err := git.NewCommand("update-index", "--add", "-z", "--index-info").RunInDirFullPipeline(t.basePath, stdOut, stdErr, stdIn); err != nil {
+		log.Error("Unable to update-index for temporary repo: %s (%s) Error: %v\nstdout: %s\nstderr: %s", t.repo.FullName(), t.basePath, err, stdOut.String(), stdErr.String())
+		return fmt.Errorf("Unable to update-index for temporary repo: %s Error: %v\nstdout: %s\nstderr: %s", t.repo.FullName(), err, stdOut.String(), stdErr.String())
+	}
+	return nil
+}
+
+// lsTree returns the entries of the tree in the format of mktree, with their names
+func (t *TemporaryUploadRepository) lsTree(treeHash string) (entries, names []string, err error) {
+	stdOut := new(bytes.Buffer)
+	stdErr := new(bytes.Buffer)
+	if err := git.NewCommand("ls-tree", "-z", treeHash).RunInDirPipeline(t.basePath, stdOut, stdErr); err != nil {
+		log.Error("Unable to ls-tree %s in temporary repo: %s (%s) Error: %v\nstderr: %s", treeHash, t.repo.FullName(), t.basePath, err, stdErr.String())
+		return nil, nil, fmt.Errorf("Unable to ls-tree %s in temporary repo: %s Error: %v\nstderr: %s", treeHash, t.repo.FullName(), err, stdErr.String())
+	}
+	for _, entry := range strings.Split(stdOut.String(), "\000") {
+		tab := strings.IndexByte(entry, '\t')
+		if tab < 0 {
+			continue
+		}
+		entries = append(entries, entry)
+		names = append(names, entry[tab+1:])
+	}
+	return entries, names, nil
+}
+
+// UpdateTreeEntry writes the tree with the entry at treePath set to the object with the mode, or removed if
+// objectHash is empty, and returns its hash. Only the trees of the directories of treePath are read and written,
+// so unlike the index of the whole tree the cost of the change doesn't grow with the size of the repository.
+func (t *TemporaryUploadRepository) UpdateTreeEntry(treeHash, treePath, mode, objectHash string) (string, error) {
+	// Reject the paths update-index rejects
+	for _, part := range strings.Split(treePath, "/") {
+		if part == "" || part == "." || part == ".." || strings.EqualFold(part, ".git") {
+			return "", models.ErrFilePathInvalid{
+				Message: treePath,
+				Path:    treePath,
+			}
+		}
+	}
+	return t.updateTreeEntry(treeHash, treePath, mode, objectHash)
+}
+
+func (t *TemporaryUploadRepository) updateTreeEntry(treeHash, treePath, mode, objectHash string) (string, error) {
+	name, subPath := treePath, ""
+	if i := strings.IndexByte(treePath, '/'); i >= 0 {
+		name, subPath = treePath[:i], treePath[i+1:]
+	}
+
+	var entries, names []string
+	if treeHash != "" {
+		var err error
+		if entries, names, err = t.lsTree(treeHash); err != nil {
+			return "", err
+		}
+	}
+
+	newEntries := make([]string, 0, len(entries)+1)
+	subTreeHash := ""
+	for i, entry := range entries {
+		if names[i] != name {
+			newEntries = append(newEntries, entry)
+			continue
+		}
+		if subPath != "" {
+			// mode SP type SP object TAB name
+			fields := strings.Fields(entry[:strings.IndexByte(entry, '\t')])
+			if len(fields) != 3 || fields[1] != "tree" {
+				return "", models.ErrFilePathInvalid{
+					Message: fmt.Sprintf("a file exists where you’re trying to create a subdirectory [path: %s]", name),
+					Path:    name,
+					Name:    name,
+					Type:    git.EntryModeBlob,
+				}
+			}
+			subTreeHash = fields[2]
+		}
+	}
+
+	if subPath != "" {
+		newSubTreeHash, err := t.updateTreeEntry(subTreeHash, subPath, mode, objectHash)
+		if err != nil {
+			return "", err
+		}
+		// Directories left empty are removed like git does
+		if newSubTreeHash != t.repo.ObjectFormat().EmptyTree() {
+			newEntries = append(newEntries, fmt.Sprintf("040000 tree %s\t%s", newSubTreeHash, name))
+		}
+	} else if objectHash != "" {
+		newEntries = append(newEntries, fmt.Sprintf("%s blob %s\t%s", mode, objectHash, name))
+	}
+
+	stdIn := new(bytes.Buffer)
+	for _, entry := range newEntries {
+		stdIn.WriteString(entry)
+		stdIn.WriteByte('\000')
+	}
+	stdOut := new(bytes.Buffer)
+	stdErr := new(bytes.Buffer)
+	if err := git.NewCommand("mktree", "-z").RunInDirFullPipeline(t.basePath, stdOut, stdErr, stdIn); err != nil {
+		log.Error("Unable to mktree in temporary repo: %s (%s) Error: %v\nstderr: %s", t.repo.FullName(), t.basePath, err, stdErr.String())
+		return "", fmt.Errorf("Unable to mktree in temporary repo: %s Error: %v\nstderr: %s", t.repo.FullName(), err, stdErr.String())
+	}
+	return strings.TrimSpace(stdOut.String()), nil
+}
+
 // GetLastCommit gets the last commit ID SHA of the repo
 func (t *TemporaryUploadRepository) GetLastCommit() (string, error) {
 	return t.GetLastCommitByRef("HEAD")
@@ -263,8 +396,8 @@ func (t *TemporaryUploadRepository) Push(doer *models.User, commitHash string, b
 	return nil
 }
 
-// DiffIndex returns a Diff of the current index to the head
-func (t *TemporaryUploadRepository) DiffIndex() (*gitdiff.Diff, error) {
+// DiffTree returns a Diff of the tree to the head
+func (t *TemporaryUploadRepository) DiffTree(treeHash string) (*gitdiff.Diff, error) {
 	stdoutReader, stdoutWriter, err := os.Pipe()
 	if err != nil {
 		log.Error("Unable to open stdout pipe: %v", err)
@@ -278,7 +411,7 @@ func (t *TemporaryUploadRepository) DiffIndex() (*gitdiff.Diff, error) {
 	var diff *gitdiff.Diff
 	var finalErr error
 
-	if err := git.NewCommand("diff-index", "--cached", "-p", "HEAD").
+	if err := git.NewCommand("diff-tree", "-p", "-r", "HEAD", treeHash).
 		RunInDirTimeoutEnvFullPipelineFunc(nil, 30*time.Second, t.basePath, stdoutWriter, stderr, nil, func(ctx context.Context, cancel context.CancelFunc) error {
 			_ = stdoutWriter.Close()
 			diff, finalErr = gitdiff.ParsePatch(setting.Git.MaxGitDiffLines, setting.Git.MaxGitDiffLineCharacters, setting.Git.MaxGitDiffFiles, stdoutReader)
@@ -293,13 +426,13 @@ func (t *TemporaryUploadRepository) DiffIndex() (*gitdiff.Diff, error) {
 			log.Error("Unable to ParsePatch in temporary repo %s (%s). Error: %v", t.repo.FullName(), t.basePath, finalErr)
 			return nil, finalErr
 		}
-		log.Error("Unable to run diff-index pipeline in temporary repo %s (%s). Error: %v\nStderr: %s",
+		log.Error("Unable to run diff-tree pipeline in temporary repo %s (%s). Error: %v\nStderr: %s",
 			t.repo.FullName(), t.basePath, err, stderr)
-		return nil, fmt.Errorf("Unable to run diff-index pipeline in temporary repo %s. Error: %v\nStderr: %s",
+		return nil, fmt.Errorf("Unable to run diff-tree pipeline in temporary repo %s. Error: %v\nStderr: %s",
 			t.repo.FullName(), err, stderr)
 	}
 
-	diff.NumFiles, diff.TotalAddition, diff.TotalDeletion, err = git.GetDiffShortStat(t.basePath, "--cached", "HEAD")
+	diff.NumFiles, diff.TotalAddition, diff.TotalDeletion, err = git.GetDiffShortStat(t.basePath, "HEAD", treeHash)
 	if err != nil {
 		return nil, err
 	}
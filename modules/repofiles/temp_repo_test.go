@@ -0,0 +1,88 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repofiles
+
+import (
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemporaryUploadRepository_UpdateTreeEntry(t *testing.T) {
+	models.PrepareTestEnv(t)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+
+	tmp, err := NewTemporaryUploadRepository(repo)
+	assert.NoError(t, err)
+	defer tmp.Close()
+	assert.NoError(t, tmp.Clone(repo.DefaultBranch))
+	commit, err := tmp.GetBranchCommit(repo.DefaultBranch)
+	assert.NoError(t, err)
+	treeHash := commit.Tree.ID.String()
+
+	objectHash, err := tmp.HashObject(strings.NewReader("new file"))
+	assert.NoError(t, err)
+
+	// The trees are the same as the ones written from an index of the whole tree
+	assert.NoError(t, tmp.SetDefaultIndex())
+	for _, treePath := range []string{"README.md", "new.txt", "a/b/c.txt"} {
+		newTreeHash, err := tmp.UpdateTreeEntry(treeHash, treePath, "100644", objectHash)
+		assert.NoError(t, err)
+
+		assert.NoError(t, tmp.SetDefaultIndex())
+		assert.NoError(t, tmp.AddObjectToIndex("100644", objectHash, treePath))
+		indexTreeHash, err := tmp.WriteTree()
+		assert.NoError(t, err)
+		assert.Equal(t, indexTreeHash, newTreeHash, treePath)
+
+		// Removing the new entries gives back the tree, the directories left empty are removed
+		if treePath != "README.md" {
+			oldTreeHash, err := tmp.UpdateTreeEntry(newTreeHash, treePath, "", "")
+			assert.NoError(t, err)
+			assert.Equal(t, treeHash, oldTreeHash, treePath)
+		}
+	}
+
+	// Files can't be replaced by directories
+	_, err = tmp.UpdateTreeEntry(treeHash, "README.md/file.txt", "100644", objectHash)
+	assert.True(t, models.IsErrFilePathInvalid(err))
+
+	for _, treePath := range []string{"", "a//b.txt", "../b.txt", ".git/config", "a/"} {
+		_, err = tmp.UpdateTreeEntry(treeHash, treePath, "100644", objectHash)
+		assert.True(t, models.IsErrFilePathInvalid(err), treePath)
+	}
+}
+
+func TestTemporaryUploadRepository_SetAttributesIndex(t *testing.T) {
+	models.PrepareTestEnv(t)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+
+	tmp, err := NewTemporaryUploadRepository(repo)
+	assert.NoError(t, err)
+	defer tmp.Close()
+	assert.NoError(t, tmp.Clone(repo.DefaultBranch))
+	commit, err := tmp.GetBranchCommit(repo.DefaultBranch)
+	assert.NoError(t, err)
+
+	// Track the files of a subdirectory with LFS
+	objectHash, err := tmp.HashObject(strings.NewReader("*.bin filter=lfs diff=lfs merge=lfs -text\n"))
+	assert.NoError(t, err)
+	treeHash, err := tmp.UpdateTreeEntry(commit.Tree.ID.String(), "lfs/.gitattributes", "100644", objectHash)
+	assert.NoError(t, err)
+	commitHash, err := tmp.CommitTree(user, user, treeHash, "Track binary files with LFS")
+	assert.NoError(t, err)
+	commit, err = tmp.GetCommit(commitHash)
+	assert.NoError(t, err)
+
+	assert.NoError(t, tmp.SetAttributesIndex(commit, "lfs/sub/file.bin", "file.bin"))
+	filename2attribute2info, err := tmp.CheckAttribute("filter", "lfs/sub/file.bin", "file.bin")
+	assert.NoError(t, err)
+	assert.Equal(t, "lfs", filename2attribute2info["lfs/sub/file.bin"]["filter"])
+	assert.Equal(t, "unspecified", filename2attribute2info["file.bin"]["filter"])
+}
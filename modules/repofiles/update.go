@@ -22,6 +22,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
 	pull_service "code.gitea.io/gitea/services/pull"
+	release_service "code.gitea.io/gitea/services/release"
 
 	stdcharset "golang.org/x/net/html/charset"
 	"golang.org/x/text/transform"
@@ -204,6 +205,16 @@ func CreateOrUpdateRepoFile(repo *models.Repository, doer *models.User, opts *Up
 		}
 	}
 
+	// Check the files are not lfs locked, both of them if the file is moved
+	if err := checkTreePathLock(repo, doer, treePath); err != nil {
+		return nil, err
+	}
+	if fromTreePath != "" && fromTreePath != treePath {
+		if err := checkTreePathLock(repo, doer, fromTreePath); err != nil {
+			return nil, err
+		}
+	}
+
 	message := strings.TrimSpace(opts.Message)
 
 	author, committer := GetAuthorAndCommitterUsers(opts.Author, opts.Committer, doer)
@@ -216,9 +227,6 @@ func CreateOrUpdateRepoFile(repo *models.Repository, doer *models.User, opts *Up
 	if err := t.Clone(opts.OldBranch); err != nil {
 		return nil, err
 	}
-	if err := t.SetDefaultIndex(); err != nil {
-		return nil, err
-	}
 
 	// Get the commit of the original branch
 	commit, err := t.GetBranchCommit(opts.OldBranch)
@@ -327,30 +335,14 @@ func CreateOrUpdateRepoFile(repo *models.Repository, doer *models.User, opts *Up
 
 	}
 
-	// Get the two paths (might be the same if not moving) from the index if they exist
-	filesInIndex, err := t.LsFiles(opts.TreePath, opts.FromTreePath)
-	if err != nil {
-		return nil, fmt.Errorf("UpdateRepoFile: %v", err)
-	}
-	// If is a new file (not updating) then the given path shouldn't exist
-	if opts.IsNewFile {
-		for _, file := range filesInIndex {
-			if file == opts.TreePath {
-				return nil, models.ErrRepoFileAlreadyExists{
-					Path: opts.TreePath,
-				}
-			}
-		}
-	}
+	// The new tree is written from the trees of the directories of the paths only,
+	// so editing a file doesn't read the whole tree of the commit into an index
+	treeHash := commit.Tree.ID.String()
 
 	// Remove the old path from the tree
-	if fromTreePath != treePath && len(filesInIndex) > 0 {
-		for _, file := range filesInIndex {
-			if file == fromTreePath {
-				if err := t.RemoveFilesFromIndex(opts.FromTreePath); err != nil {
-					return nil, err
-				}
-			}
+	if !opts.IsNewFile && fromTreePath != treePath {
+		if treeHash, err = t.UpdateTreeEntry(treeHash, fromTreePath, "", ""); err != nil {
+			return nil, err
 		}
 	}
 
@@ -377,6 +369,9 @@ func CreateOrUpdateRepoFile(repo *models.Repository, doer *models.User, opts *Up
 	var lfsMetaObject *models.LFSMetaObject
 
 	if setting.LFS.StartServer {
+		if err := t.SetAttributesIndex(commit, treePath); err != nil {
+			return nil, err
+		}
 		// Check there is no way this can return multiple infos
 		filename2attribute2info, err := t.CheckAttribute("filter", treePath)
 		if err != nil {
@@ -399,19 +394,12 @@ func CreateOrUpdateRepoFile(repo *models.Repository, doer *models.User, opts *Up
 		return nil, err
 	}
 
-	// Add the object to the index
+	// Add the object to the tree
+	mode := "100644"
 	if executable {
-		if err := t.AddObjectToIndex("100755", objectHash, treePath); err != nil {
-			return nil, err
-		}
-	} else {
-		if err := t.AddObjectToIndex("100644", objectHash, treePath); err != nil {
-			return nil, err
-		}
+		mode = "100755"
 	}
-
-	// Now write the tree
-	treeHash, err := t.WriteTree()
+	treeHash, err = t.UpdateTreeEntry(treeHash, treePath, mode, objectHash)
 	if err != nil {
 		return nil, err
 	}
@@ -433,7 +421,7 @@ func CreateOrUpdateRepoFile(repo *models.Repository, doer *models.User, opts *Up
 		if err != nil {
 			return nil, err
 		}
-		contentStore := &lfs.ContentStore{BasePath: setting.LFS.ContentPath}
+		contentStore := lfs.NewContentStore()
 		if !contentStore.Exists(lfsMetaObject) {
 			if err := contentStore.Put(lfsMetaObject, strings.NewReader(opts.Content)); err != nil {
 				if _, err2 := repo.RemoveLFSMetaObjectByOid(lfsMetaObject.Oid); err2 != nil {
@@ -475,12 +463,12 @@ type PushUpdateOptions struct {
 
 // IsNewRef return true if it's a first-time push to a branch, tag or etc.
 func (opts PushUpdateOptions) IsNewRef() bool {
-	return opts.OldCommitID == git.EmptySHA
+	return git.IsEmptyCommitID(opts.OldCommitID)
 }
 
 // IsDelRef return true if it's a deletion to a branch or tag
 func (opts PushUpdateOptions) IsDelRef() bool {
-	return opts.NewCommitID == git.EmptySHA
+	return git.IsEmptyCommitID(opts.NewCommitID)
 }
 
 // IsUpdateRef return true if it's an update operation
@@ -571,6 +559,9 @@ func PushUpdate(repo *models.Repository, branch string, opts PushUpdateOptions)
 				return fmt.Errorf("PushUpdateDeleteTag: %v", err)
 			}
 		} else {
+			if opts.IsNewRef() {
+				createAutoRelease(repo, gitRepo, &opts)
+			}
 			// Clear cache for tag commit count
 			cache.Remove(repo.GetCommitsCountCacheKey(tagName, true))
 			if err := repo_module.PushUpdateAddTag(repo, gitRepo, tagName); err != nil {
@@ -619,9 +610,16 @@ func PushUpdate(repo *models.Repository, branch string, opts PushUpdateOptions)
 			log.Trace("TriggerTask '%s/%s' by %s", repo.Name, branch, pusher.Name)
 
 			go pull_service.AddTestPullRequestTask(pusher, repo.ID, branch, true, opts.OldCommitID, opts.NewCommitID)
-		} else if err = pull_service.CloseBranchPulls(pusher, repo.ID, branch); err != nil {
+		} else {
+			// Remember the deleted branch, so it can be restored
+			if err := repo.AddDeletedBranch(opts.BranchName(), opts.OldCommitID, opts.PusherID); err != nil {
+				log.Warn("AddDeletedBranch: %v", err)
+			}
+
 			// close all related pulls
-			log.Error("close related pull request failed: %v", err)
+			if err = pull_service.CloseBranchPulls(pusher, repo.ID, branch); err != nil {
+				log.Error("close related pull request failed: %v", err)
+			}
 		}
 	}
 
@@ -653,6 +651,12 @@ func PushUpdates(repo *models.Repository, optsList []*PushUpdateOptions) error {
 		log.Error("Failed to update size for repository: %v", err)
 	}
 
+	for _, opts := range optsList {
+		if opts.IsNewTag() {
+			createAutoRelease(repo, gitRepo, opts)
+		}
+	}
+
 	actions, err := createCommitRepoActions(repo, gitRepo, optsList)
 	if err != nil {
 		return err
@@ -690,12 +694,21 @@ func PushUpdates(repo *models.Repository, optsList []*PushUpdateOptions) error {
 			log.Trace("TriggerTask '%s/%s' by %s", repo.Name, branch, pusher.Name)
 
 			go pull_service.AddTestPullRequestTask(pusher, repo.ID, branch, true, opts.OldCommitID, opts.NewCommitID)
+		} else {
+			// Remember the deleted branch, so it can be restored
+			if err := repo.AddDeletedBranch(branch, opts.OldCommitID, opts.PusherID); err != nil {
+				log.Warn("AddDeletedBranch: %v", err)
+			}
+
 			// close all related pulls
-		} else if err = pull_service.CloseBranchPulls(pusher, repo.ID, branch); err != nil {
-			log.Error("close related pull request failed: %v", err)
+			if err = pull_service.CloseBranchPulls(pusher, repo.ID, branch); err != nil {
+				log.Error("close related pull request failed: %v", err)
+			}
 		}
 	}
 
+	repo_module.AddToCommitGraphQueue(repo)
+
 	return nil
 }
 
@@ -756,3 +769,16 @@ func createCommitRepoActions(repo *models.Repository, gitRepo *git.Repository, o
 	}
 	return actions, nil
 }
+
+// createAutoRelease creates a release for a new tag if the repository asks for it.
+// It runs before the tags are synchronized, failures don't prevent the push from being processed.
+func createAutoRelease(repo *models.Repository, gitRepo *git.Repository, opts *PushUpdateOptions) {
+	pusher, err := models.GetUserByID(opts.PusherID)
+	if err != nil {
+		log.Error("GetUserByID [%d]: %v", opts.PusherID, err)
+		return
+	}
+	if err = release_service.CreateReleaseForPushedTag(gitRepo, repo, pusher, opts.TagName()); err != nil {
+		log.Error("CreateReleaseForPushedTag %s in %-v: %v", opts.TagName(), repo, err)
+	}
+}
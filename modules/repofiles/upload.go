@@ -60,13 +60,9 @@ func UploadRepoFiles(repo *models.Repository, doer *models.User, opts *UploadRep
 	for i, upload := range uploads {
 		// Check file is not lfs locked, will return nil if lock setting not enabled
 		filepath := path.Join(opts.TreePath, upload.Name)
-		lfsLock, err := repo.GetTreePathLock(filepath)
-		if err != nil {
+		if err := checkTreePathLock(repo, doer, filepath); err != nil {
 			return err
 		}
-		if lfsLock != nil && lfsLock.OwnerID != doer.ID {
-			return models.ErrLFSFileLocked{RepoID: repo.ID, Path: filepath, UserName: lfsLock.Owner.Name}
-		}
 
 		names[i] = upload.Name
 		infos[i] = uploadInfo{upload: upload}
@@ -163,7 +159,7 @@ func UploadRepoFiles(repo *models.Repository, doer *models.User, opts *UploadRep
 
 	// OK now we can insert the data into the store - there's no way to clean up the store
 	// once it's in there, it's in there.
-	contentStore := &lfs.ContentStore{BasePath: setting.LFS.ContentPath}
+	contentStore := lfs.NewContentStore()
 	for _, uploadInfo := range infos {
 		if uploadInfo.lfsMetaObject == nil {
 			continue
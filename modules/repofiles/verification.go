@@ -7,13 +7,17 @@ package repofiles
 import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/structs"
 )
 
-// GetPayloadCommitVerification returns the verification information of a commit
-func GetPayloadCommitVerification(commit *git.Commit) *structs.PayloadCommitVerification {
+// GetPayloadCommitVerification returns the verification information of a commit of the repository
+func GetPayloadCommitVerification(repo *models.Repository, commit *git.Commit) *structs.PayloadCommitVerification {
 	verification := &structs.PayloadCommitVerification{}
 	commitVerification := models.ParseCommitWithSignature(commit)
+	if err := models.CalculateTrustStatus(commitVerification, repo, nil); err != nil {
+		log.Error("CalculateTrustStatus: %v", err)
+	}
 	if commit.Signature != nil {
 		verification.Signature = commit.Signature.Signature
 		verification.Payload = commit.Signature.Payload
@@ -26,6 +30,8 @@ func GetPayloadCommitVerification(commit *git.Commit) *structs.PayloadCommitVeri
 	}
 	verification.Verified = commitVerification.Verified
 	verification.Reason = commitVerification.Reason
+	verification.TrustModel = commitVerification.TrustModel
+	verification.TrustStatus = commitVerification.TrustStatus
 	if verification.Reason == "" && !verification.Verified {
 		verification.Reason = "gpg.error.not_signed_commit"
 	}
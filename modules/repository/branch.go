@@ -28,6 +28,18 @@ func GetBranches(repo *models.Repository) ([]*git.Branch, error) {
 	return git.GetBranchesByPath(repo.RepoPath())
 }
 
+// GetBranchesPage returns the page of the branches of a repository,
+// with the total number of branches matching the options
+func GetBranchesPage(repo *models.Repository, opts git.ListRefsOptions) ([]*git.Branch, int, error) {
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer gitRepo.Close()
+
+	return gitRepo.GetBranchesPage(opts)
+}
+
 // checkBranchName validates branch name with existing repository branches
 func checkBranchName(repo *models.Repository, name string) error {
 	gitRepo, err := git.OpenRepository(repo.RepoPath())
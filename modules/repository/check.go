@@ -55,7 +55,6 @@ func GitFsck(ctx context.Context, timeout time.Duration, args []string) error {
 // GitGcRepos calls 'git gc' to remove unnecessary files and optimize the local repository
 func GitGcRepos(ctx context.Context, timeout time.Duration, args ...string) error {
 	log.Trace("Doing: GitGcRepos")
-	args = append([]string{"gc"}, args...)
 
 	if err := models.Iterate(
 		models.DefaultDBContext(),
@@ -68,24 +67,8 @@ func GitGcRepos(ctx context.Context, timeout time.Duration, args ...string) erro
 				return models.ErrCancelledf("before GC of %s", repo.FullName())
 			default:
 			}
-			log.Trace("Running git gc on %v", repo)
-			command := git.NewCommandContext(ctx, args...).
-				SetDescription(fmt.Sprintf("Repository Garbage Collection: %s", repo.FullName()))
-			var stdout string
-			var err error
-			if timeout > 0 {
-				var stdoutBytes []byte
-				stdoutBytes, err = command.RunInDirTimeout(
-					timeout,
-					repo.RepoPath())
-				stdout = string(stdoutBytes)
-			} else {
-				stdout, err = command.RunInDir(repo.RepoPath())
-			}
-
-			if err != nil {
-				log.Error("Repository garbage collection failed for %v. Stdout: %s\nError: %v", repo, stdout, err)
-				desc := fmt.Sprintf("Repository garbage collection failed for %s. Stdout: %s\nError: %v", repo.RepoPath(), stdout, err)
+			if err := GitGcRepo(ctx, repo, timeout, args...); err != nil {
+				desc := fmt.Sprintf("Repository garbage collection failed for %s. %v", repo.RepoPath(), err)
 				if err = models.CreateRepositoryNotice(desc); err != nil {
 					log.Error("CreateRepositoryNotice: %v", err)
 				}
@@ -101,6 +84,30 @@ func GitGcRepos(ctx context.Context, timeout time.Duration, args ...string) erro
 	return nil
 }
 
+// GitGcRepo calls 'git gc' on a single repository
+func GitGcRepo(ctx context.Context, repo *models.Repository, timeout time.Duration, args ...string) error {
+	log.Trace("Running git gc on %v", repo)
+	command := git.NewCommandContext(ctx, append([]string{"gc"}, args...)...).
+		SetDescription(fmt.Sprintf("Repository Garbage Collection: %s", repo.FullName()))
+	var stdout string
+	var err error
+	if timeout > 0 {
+		var stdoutBytes []byte
+		stdoutBytes, err = command.RunInDirTimeout(
+			timeout,
+			repo.RepoPath())
+		stdout = string(stdoutBytes)
+	} else {
+		stdout, err = command.RunInDir(repo.RepoPath())
+	}
+
+	if err != nil {
+		log.Error("Repository garbage collection failed for %v. Stdout: %s\nError: %v", repo, stdout, err)
+		return fmt.Errorf("Stdout: %s\nError: %v", stdout, err)
+	}
+	return nil
+}
+
 func gatherMissingRepoRecords(ctx context.Context) ([]*models.Repository, error) {
 	repos := make([]*models.Repository, 0, 10)
 	if err := models.Iterate(
@@ -177,7 +184,7 @@ func ReinitMissingRepositories(ctx context.Context) error {
 		default:
 		}
 		log.Trace("Initializing %d/%d...", repo.OwnerID, repo.ID)
-		if err := git.InitRepository(repo.RepoPath(), true); err != nil {
+		if err := git.InitRepository(repo.RepoPath(), true, repo.ObjectFormat()); err != nil {
 			log.Error("Unable (re)initialize repository %d at %s. Error: %v", repo.ID, repo.RepoPath(), err)
 			if err2 := models.CreateRepositoryNotice("InitRepository [%d]: %v", repo.ID, err); err2 != nil {
 				log.Error("CreateRepositoryNotice: %v", err2)
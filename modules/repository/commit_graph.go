@@ -0,0 +1,71 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// commitGraphQueue represents a queue of repositories to write the commit-graph files of
+var commitGraphQueue queue.UniqueQueue
+
+func handleCommitGraph(data ...queue.Data) {
+	for _, datum := range data {
+		id := datum.(int64)
+		if err := WriteCommitGraph(id); err != nil {
+			log.Error("WriteCommitGraph[%d]: %v", id, err)
+		}
+	}
+}
+
+// InitCommitGraphQueue runs the queue writing the commit-graph files of pushed repositories
+func InitCommitGraphQueue() error {
+	if setting.Git.DisableCommitGraph {
+		return nil
+	}
+	commitGraphQueue = queue.CreateUniqueQueue("repo_commit_graph", handleCommitGraph, int64(0)).(queue.UniqueQueue)
+	if commitGraphQueue == nil {
+		return fmt.Errorf("Unable to create repo_commit_graph Queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(commitGraphQueue.Run)
+	return nil
+}
+
+// AddToCommitGraphQueue schedules writing the commit-graph file of a repository
+func AddToCommitGraphQueue(repo *models.Repository) {
+	if commitGraphQueue == nil {
+		return
+	}
+	if err := commitGraphQueue.Push(repo.ID); err != nil && err != queue.ErrAlreadyInQueue {
+		log.Error("Unable to add repository %d to the commit-graph queue: %v", repo.ID, err)
+	}
+}
+
+// WriteCommitGraph writes the commit-graph file of a repository, which git and the commit
+// walks of Gitea use to count and traverse the commits without parsing every one of them.
+func WriteCommitGraph(repoID int64) error {
+	repo, err := models.GetRepositoryByID(repoID)
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("GetRepositoryByID: %v", err)
+	}
+	if repo.IsEmpty {
+		return nil
+	}
+	if err := git.WriteCommitGraph(repo.RepoPath()); err != nil {
+		return fmt.Errorf("git commit-graph write [%s]: %v", repo.FullName(), err)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteCommitGraph(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	assert.NoError(t, WriteCommitGraph(repo.ID))
+	assert.FileExists(t, filepath.Join(repo.RepoPath(), "objects", "info", "commit-graph"))
+
+	// deleted repositories are skipped
+	assert.NoError(t, WriteCommitGraph(9999))
+}
@@ -23,6 +23,14 @@ func CreateRepository(doer, u *models.User, opts models.CreateRepoOptions) (_ *m
 		}
 	}
 
+	objectFormat, err := git.ObjectFormatFromName(opts.ObjectFormatName)
+	if err != nil {
+		return nil, err
+	}
+	if !objectFormat.IsSupported() {
+		return nil, git.ErrUnsupportedObjectFormat{Name: opts.ObjectFormatName}
+	}
+
 	repo := &models.Repository{
 		OwnerID:                         u.ID,
 		Owner:                           u,
@@ -37,6 +45,7 @@ func CreateRepository(doer, u *models.User, opts models.CreateRepoOptions) (_ *m
 		CloseIssuesViaCommitInAnyBranch: setting.Repository.DefaultCloseIssuesViaCommitsInAnyBranch,
 		Status:                          opts.Status,
 		IsEmpty:                         !opts.AutoInit,
+		ObjectFormatName:                string(objectFormat),
 	}
 
 	err = models.WithTx(func(ctx models.DBContext) error {
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
 
 	"github.com/stretchr/testify/assert"
@@ -143,3 +144,28 @@ func TestIncludesAllRepositoriesTeams(t *testing.T) {
 	}
 	assert.NoError(t, models.DeleteOrganization(org), "DeleteOrganization")
 }
+
+func TestCreateRepositoryReleaseQuotas(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	defaultQuota := setting.Repository.Release
+	defer func() {
+		setting.Repository.Release = defaultQuota
+	}()
+
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	r, err := CreateRepository(user, user, models.CreateRepoOptions{Name: "release-quotas"})
+	assert.NoError(t, err)
+	r, err = models.GetRepositoryByID(r.ID)
+	assert.NoError(t, err)
+
+	// A new repository follows the global quotas
+	rel := &models.Release{RepoID: r.ID, Repo: r}
+	assert.NoError(t, models.CheckReleaseAssetQuota(rel, 1024*1024))
+
+	setting.Repository.Release.MaxAssets = 1
+	assert.EqualValues(t, 1, r.MaxReleaseAssets())
+	assert.EqualValues(t, -1, r.MaxReleaseAssetSize())
+	assert.NoError(t, models.CheckReleaseAssetQuota(rel, 1024*1024))
+	assert.True(t, models.IsErrReleaseAssetQuotaExceeded(models.CheckReleaseAssetQuota(rel, 1, 1)))
+}
@@ -12,6 +12,7 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
 )
 
@@ -41,6 +42,8 @@ func ForkRepository(doer, owner *models.User, oldRepo *models.Repository, name,
 		IsEmpty:       oldRepo.IsEmpty,
 		IsFork:        true,
 		ForkID:        oldRepo.ID,
+		// Forks borrow the objects of the repository, so they have the same object format
+		ObjectFormatName: oldRepo.ObjectFormatName,
 	}
 
 	oldRepoPath := oldRepo.RepoPath()
@@ -54,9 +57,20 @@ func ForkRepository(doer, owner *models.User, oldRepo *models.Repository, name,
 			return err
 		}
 
+		args := []string{"clone", "--bare"}
+		if setting.Repository.ShareForkObjects {
+			// The objects borrowed by forks must never be pruned from the forked repository
+			if stdout, err := git.NewCommand("config", "gc.pruneExpire", "never").
+				SetDescription(fmt.Sprintf("ForkRepository(git config): %s", oldRepo.FullName())).
+				RunInDir(oldRepoPath); err != nil {
+				log.Error("Fork Repository (git config) Failed for %v:\nStdout: %s\nError: %v", oldRepo, stdout, err)
+				return fmt.Errorf("git config: %v", err)
+			}
+			args = append(args, "--shared")
+		}
+
 		repoPath := models.RepoPath(owner.Name, repo.Name)
-		if stdout, err := git.NewCommand(
-			"clone", "--bare", oldRepoPath, repoPath).
+		if stdout, err := git.NewCommand(append(args, oldRepoPath, repoPath)...).
 			SetDescription(fmt.Sprintf("ForkRepository(git clone): %s to %s", oldRepo.FullName(), repo.FullName())).
 			RunInDirTimeout(10*time.Minute, ""); err != nil {
 			log.Error("Fork Repository (git clone) Failed for %v (from %v):\nStdout: %s\nError: %v", repo, oldRepo, stdout, err)
@@ -5,9 +5,14 @@
 package repository
 
 import (
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -23,3 +28,45 @@ func TestForkRepository(t *testing.T) {
 	assert.Error(t, err)
 	assert.True(t, models.IsErrForkAlreadyExist(err))
 }
+
+func TestForkRepository_ShareObjects(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	defer func(share bool) {
+		setting.Repository.ShareForkObjects = share
+	}(setting.Repository.ShareForkObjects)
+	setting.Repository.ShareForkObjects = true
+
+	user2 := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	user4 := models.AssertExistsAndLoadBean(t, &models.User{ID: 4}).(*models.User)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	assert.NoError(t, repo.GetOwner())
+
+	fork, err := ForkRepository(user4, user4, repo, "shared-fork", "")
+	assert.NoError(t, err)
+	alternates, err := models.ReadAlternates(fork.RepoPath())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(repo.RepoPath(), "objects")}, alternates)
+	pruneExpire, err := git.NewCommand("config", "gc.pruneExpire").RunInDir(repo.RepoPath())
+	assert.NoError(t, err)
+	assert.Equal(t, "never", strings.TrimSpace(pruneExpire))
+
+	// the alternates follow the forked repository
+	repo = models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	assert.NoError(t, models.ChangeRepositoryName(user2, repo, "renamed-repo1"))
+	repo.Name = "renamed-repo1"
+	repo.LowerName = repo.Name
+	assert.NoError(t, models.UpdateRepository(repo, false))
+	alternates, err = models.ReadAlternates(fork.RepoPath())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(repo.RepoPath(), "objects")}, alternates)
+	_, err = git.NewCommand("cat-file", "-e", "master^{tree}").RunInDir(fork.RepoPath())
+	assert.NoError(t, err)
+
+	// the fork keeps the objects of the deleted repository
+	assert.NoError(t, models.DeleteRepository(user2, repo.OwnerID, repo.ID))
+	alternates, err = models.ReadAlternates(fork.RepoPath())
+	assert.NoError(t, err)
+	assert.Empty(t, alternates)
+	_, err = git.NewCommand("fsck", "--connectivity-only").RunInDir(fork.RepoPath())
+	assert.NoError(t, err)
+}
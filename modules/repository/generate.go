@@ -169,7 +169,7 @@ func generateRepoCommit(repo, templateRepo, generateRepo *models.Repository, tmp
 		}
 	}
 
-	if err := git.InitRepository(tmpDir, false); err != nil {
+	if err := git.InitRepository(tmpDir, false, generateRepo.ObjectFormat()); err != nil {
 		return err
 	}
 
@@ -242,6 +242,8 @@ func GenerateRepository(ctx models.DBContext, doer, owner *models.User, template
 		IsEmpty:       !opts.GitContent || templateRepo.IsEmpty,
 		IsFsckEnabled: templateRepo.IsFsckEnabled,
 		TemplateID:    templateRepo.ID,
+		// The commits of the template are rewritten in the generated repository, so it has the same object format
+		ObjectFormatName: templateRepo.ObjectFormatName,
 	}
 
 	if err = models.CreateRepository(ctx, doer, owner, generateRepo); err != nil {
@@ -249,7 +251,7 @@ func GenerateRepository(ctx models.DBContext, doer, owner *models.User, template
 	}
 
 	repoPath := models.RepoPath(owner.Name, generateRepo.Name)
-	if err = checkInitRepository(repoPath); err != nil {
+	if err = checkInitRepository(repoPath, generateRepo.ObjectFormat()); err != nil {
 		return generateRepo, err
 	}
 
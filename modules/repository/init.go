@@ -160,14 +160,14 @@ func initRepoCommit(tmpPath string, repo *models.Repository, u *models.User, def
 	return nil
 }
 
-func checkInitRepository(repoPath string) (err error) {
+func checkInitRepository(repoPath string, objectFormat git.ObjectFormat) (err error) {
 	// Somehow the directory could exist.
 	if com.IsExist(repoPath) {
 		return fmt.Errorf("checkInitRepository: path already exists: %s", repoPath)
 	}
 
 	// Init git bare new repository.
-	if err = git.InitRepository(repoPath, true); err != nil {
+	if err = git.InitRepository(repoPath, true, objectFormat); err != nil {
 		return fmt.Errorf("git.InitRepository: %v", err)
 	} else if err = createDelegateHooks(repoPath); err != nil {
 		return fmt.Errorf("createDelegateHooks: %v", err)
@@ -177,7 +177,7 @@ func checkInitRepository(repoPath string) (err error) {
 
 // InitRepository initializes README and .gitignore if needed.
 func initRepository(ctx models.DBContext, repoPath string, u *models.User, repo *models.Repository, opts models.CreateRepoOptions) (err error) {
-	if err = checkInitRepository(repoPath); err != nil {
+	if err = checkInitRepository(repoPath, repo.ObjectFormat()); err != nil {
 		return err
 	}
 
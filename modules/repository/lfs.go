@@ -0,0 +1,186 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git/pipeline"
+	"code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// GarbageCollectLFSMetaObjectsOptions are the options of the garbage collection of the lfs objects
+type GarbageCollectLFSMetaObjectsOptions struct {
+	// OlderThan is the grace period of the lfs objects, which are uploaded before the commits referencing them are pushed
+	OlderThan time.Duration
+	// DryRun only reports the unreferenced lfs objects as repository notices instead of deleting them
+	DryRun bool
+}
+
+// GarbageCollectLFSMetaObjects removes the lfs objects of the repositories which are not referenced by the commits
+// reachable from any of their refs, and deletes their content once no repository is associated with it anymore
+func GarbageCollectLFSMetaObjects(ctx context.Context, opts GarbageCollectLFSMetaObjectsOptions) error {
+	log.Trace("Doing: GarbageCollectLFSMetaObjects")
+
+	if err := models.Iterate(
+		models.DefaultDBContext(),
+		new(models.Repository),
+		builder.In("id", builder.Select("repository_id").From("lfs_meta_object")),
+		func(idx int, bean interface{}) error {
+			repo := bean.(*models.Repository)
+			select {
+			case <-ctx.Done():
+				return models.ErrCancelledf("before LFS garbage collection of %s", repo.FullName())
+			default:
+			}
+			if err := GarbageCollectLFSMetaObjectsForRepo(repo, opts); err != nil {
+				log.Warn("Failed to garbage collect the LFS objects of %v: %v", repo, err)
+				if err = models.CreateRepositoryNotice("Failed to garbage collect the LFS objects of %s: %v", repo.FullName(), err); err != nil {
+					log.Error("CreateRepositoryNotice: %v", err)
+				}
+			}
+			return nil
+		},
+	); err != nil {
+		log.Trace("Error: GarbageCollectLFSMetaObjects: %v", err)
+		return err
+	}
+
+	log.Trace("Finished: GarbageCollectLFSMetaObjects")
+	return nil
+}
+
+// GarbageCollectLFSMetaObjectsForRepo removes the lfs objects of the repository older than the grace period
+// which are not referenced by the commits reachable from any of its refs
+func GarbageCollectLFSMetaObjectsForRepo(repo *models.Repository, opts GarbageCollectLFSMetaObjectsOptions) error {
+	metas, err := repo.GetLFSMetaObjectsCreatedBefore(timeutil.TimeStamp(time.Now().Add(-opts.OlderThan).Unix()))
+	if err != nil {
+		return fmt.Errorf("GetLFSMetaObjectsCreatedBefore: %v", err)
+	}
+	if len(metas) == 0 {
+		return nil
+	}
+
+	referenced, err := referencedLFSOids(repo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("referencedLFSOids: %v", err)
+	}
+
+	var unreferenced []string
+	var size int64
+	for _, meta := range metas {
+		if referenced[meta.Oid] {
+			continue
+		}
+		unreferenced = append(unreferenced, meta.Oid)
+		size += meta.Size
+	}
+	if len(unreferenced) == 0 {
+		return nil
+	}
+
+	if opts.DryRun {
+		log.Info("%d unreferenced LFS objects of %d bytes in %v: %s", len(unreferenced), size, repo, strings.Join(unreferenced, ", "))
+		return models.CreateRepositoryNotice("Repository %s has %d unreferenced LFS objects of %d bytes: %s", repo.FullName(), len(unreferenced), size, strings.Join(unreferenced, ", "))
+	}
+
+	for _, oid := range unreferenced {
+		count, err := repo.RemoveLFSMetaObjectByOid(oid)
+		if err != nil {
+			return fmt.Errorf("RemoveLFSMetaObjectByOid[%s]: %v", oid, err)
+		}
+		if count > 0 {
+			// The content is still used by other repositories
+			continue
+		}
+		if err := storage.LFS.Delete((&models.LFSMetaObject{Oid: oid}).RelativePath()); err != nil {
+			return fmt.Errorf("Delete LFS OID[%s]: %v", oid, err)
+		}
+	}
+	log.Trace("Removed %d unreferenced LFS objects of %d bytes from %v", len(unreferenced), size, repo)
+	return nil
+}
+
+// referencedLFSOids returns the oids of the lfs pointers in the commits reachable from any ref of the repository
+func referencedLFSOids(repoPath string) (map[string]bool, error) {
+	revListReader, revListWriter := io.Pipe()
+	shasToCheckReader, shasToCheckWriter := io.Pipe()
+	catFileCheckReader, catFileCheckWriter := io.Pipe()
+	shasToBatchReader, shasToBatchWriter := io.Pipe()
+	catFileBatchReader, catFileBatchWriter := io.Pipe()
+	errChan := make(chan error, 1)
+	wg := sync.WaitGroup{}
+	wg.Add(6)
+
+	referenced := make(map[string]bool)
+	var readErr error
+	go func() {
+		defer wg.Done()
+		defer catFileBatchReader.Close()
+		readErr = readLFSOidsFromCatFileBatch(catFileBatchReader, referenced)
+		if readErr != nil {
+			_ = catFileBatchReader.CloseWithError(readErr)
+		}
+	}()
+	go pipeline.CatFileBatch(shasToBatchReader, catFileBatchWriter, &wg, repoPath)
+	go pipeline.BlobsLessThan1024FromCatFileBatchCheck(catFileCheckReader, shasToBatchWriter, &wg)
+	go pipeline.CatFileBatchCheck(shasToCheckReader, catFileCheckWriter, &wg, repoPath)
+	go pipeline.BlobsFromRevListObjects(revListReader, shasToCheckWriter, &wg)
+	go pipeline.RevListAllObjects(revListWriter, &wg, repoPath, errChan)
+	wg.Wait()
+
+	select {
+	case err := <-errChan:
+		return nil, err
+	default:
+	}
+	if readErr != nil {
+		return nil, readErr
+	}
+	return referenced, nil
+}
+
+// readLFSOidsFromCatFileBatch adds the oids of the lfs pointers among the blobs read from cat-file --batch to the set
+func readLFSOidsFromCatFileBatch(catFileBatchReader io.Reader, oids map[string]bool) error {
+	bufferedReader := bufio.NewReader(catFileBatchReader)
+	for {
+		// File descriptor line: sha type size
+		header, err := bufferedReader.ReadString('\n')
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		fields := strings.Fields(header)
+		if len(fields) < 3 {
+			return fmt.Errorf("invalid cat-file --batch header: %q", header)
+		}
+		size, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return err
+		}
+		// The content is followed by a newline
+		content := make([]byte, size+1)
+		if _, err := io.ReadFull(bufferedReader, content); err != nil {
+			return err
+		}
+		if meta := lfs.ParsePointer(content[:size]); meta != nil {
+			oids[meta.Oid] = true
+		}
+	}
+}
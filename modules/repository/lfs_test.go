@@ -0,0 +1,82 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// commitLFSPointer commits a pointer to the lfs object to the ref, which is not a branch
+func commitLFSPointer(t *testing.T, repoPath, ref string, meta *models.LFSMetaObject) {
+	env := append(os.Environ(),
+		"GIT_AUTHOR_NAME=User Two", "GIT_AUTHOR_EMAIL=user2@example.com",
+		"GIT_COMMITTER_NAME=User Two", "GIT_COMMITTER_EMAIL=user2@example.com")
+
+	stdout := new(strings.Builder)
+	assert.NoError(t, git.NewCommand("hash-object", "-w", "--stdin").
+		RunInDirFullPipeline(repoPath, stdout, nil, strings.NewReader(meta.Pointer())))
+	blobID := strings.TrimSpace(stdout.String())
+
+	stdout.Reset()
+	assert.NoError(t, git.NewCommand("mktree").
+		RunInDirFullPipeline(repoPath, stdout, nil, strings.NewReader("100644 blob "+blobID+"\tobject.bin\n")))
+	treeID := strings.TrimSpace(stdout.String())
+
+	commitID, err := git.NewCommand("commit-tree", treeID, "-m", "Add an LFS object").RunInDirWithEnv(repoPath, env)
+	assert.NoError(t, err)
+	_, err = git.NewCommand("update-ref", ref, strings.TrimSpace(commitID)).RunInDir(repoPath)
+	assert.NoError(t, err)
+}
+
+func TestGarbageCollectLFSMetaObjectsForRepo(t *testing.T) {
+	models.PrepareTestEnv(t)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+
+	referenced := &models.LFSMetaObject{Oid: strings.Repeat("1", 64), Size: 5, RepositoryID: repo.ID}
+	unreferenced := &models.LFSMetaObject{Oid: strings.Repeat("2", 64), Size: 5, RepositoryID: repo.ID}
+	commitLFSPointer(t, repo.RepoPath(), "refs/keep/lfs-gc", referenced)
+	for _, meta := range []*models.LFSMetaObject{referenced, unreferenced} {
+		_, err := models.NewLFSMetaObject(meta)
+		assert.NoError(t, err)
+		_, err = storage.LFS.Save(meta.RelativePath(), strings.NewReader("12345"))
+		assert.NoError(t, err)
+	}
+	assertLFSObjectExists := func(meta *models.LFSMetaObject, exists bool) {
+		_, err := repo.GetLFSMetaObjectByOid(meta.Oid)
+		_, statErr := storage.LFS.Stat(meta.RelativePath())
+		if exists {
+			assert.NoError(t, err)
+			assert.NoError(t, statErr)
+		} else {
+			assert.Equal(t, models.ErrLFSObjectNotExist, err)
+			assert.Equal(t, storage.ErrObjectNotExist, statErr)
+		}
+	}
+
+	// Both objects are in their grace period
+	assert.NoError(t, GarbageCollectLFSMetaObjectsForRepo(repo, GarbageCollectLFSMetaObjectsOptions{OlderThan: time.Hour}))
+	assertLFSObjectExists(referenced, true)
+	assertLFSObjectExists(unreferenced, true)
+
+	// A dry run only reports the unreferenced object
+	numNotices := models.CountNotices()
+	assert.NoError(t, GarbageCollectLFSMetaObjectsForRepo(repo, GarbageCollectLFSMetaObjectsOptions{DryRun: true}))
+	assert.EqualValues(t, numNotices+1, models.CountNotices())
+	assertLFSObjectExists(referenced, true)
+	assertLFSObjectExists(unreferenced, true)
+
+	assert.NoError(t, GarbageCollectLFSMetaObjectsForRepo(repo, GarbageCollectLFSMetaObjectsOptions{}))
+	assertLFSObjectExists(referenced, true)
+	assertLFSObjectExists(unreferenced, false)
+}
@@ -0,0 +1,70 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+)
+
+// CreateRef creates a branch or lightweight tag pointing to the commit, refName being the full name of the ref.
+// The ref is pushed to the repository, so its protections are enforced by the hooks like for any push.
+func CreateRef(doer *models.User, repo *models.Repository, refName, commitID string) error {
+	switch {
+	case strings.HasPrefix(refName, git.BranchPrefix):
+		if err := checkBranchName(repo, strings.TrimPrefix(refName, git.BranchPrefix)); err != nil {
+			return err
+		}
+	case strings.HasPrefix(refName, git.TagPrefix):
+		if tagName := strings.TrimPrefix(refName, git.TagPrefix); git.IsTagExist(repo.RepoPath(), tagName) {
+			return models.ErrTagAlreadyExists{
+				TagName: tagName,
+			}
+		}
+	default:
+		return fmt.Errorf("ref %s is neither a branch nor a tag", refName)
+	}
+
+	return pushRef(doer, repo, commitID+":"+refName)
+}
+
+// DeleteRef deletes a branch or tag, refName being the full name of the ref.
+// The deletion is pushed to the repository, so its protections are enforced by the hooks like for any push.
+func DeleteRef(doer *models.User, repo *models.Repository, refName string) error {
+	if !strings.HasPrefix(refName, git.BranchPrefix) && !strings.HasPrefix(refName, git.TagPrefix) {
+		return fmt.Errorf("ref %s is neither a branch nor a tag", refName)
+	}
+
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer gitRepo.Close()
+
+	if _, err := gitRepo.GetRefCommitID(refName); err != nil {
+		return err
+	}
+
+	// The deleted branches are remembered by the post-receive hook
+	return pushRef(doer, repo, ":"+refName)
+}
+
+// pushRef pushes the refspec from the repository to itself
+func pushRef(doer *models.User, repo *models.Repository, refSpec string) error {
+	if err := git.Push(repo.RepoPath(), git.PushOptions{
+		Remote: repo.RepoPath(),
+		Branch: refSpec,
+		Env:    models.PushingEnvironment(doer, repo),
+	}); err != nil {
+		if git.IsErrPushOutOfDate(err) || git.IsErrPushRejected(err) {
+			return err
+		}
+		return fmt.Errorf("Push: %v", err)
+	}
+	return nil
+}
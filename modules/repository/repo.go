@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
@@ -54,7 +55,7 @@ func MigrateRepositoryGitData(doer, u *models.User, repo *models.Repository, opt
 		repo.NumWatches = 1
 	}
 
-	migrateTimeout := time.Duration(setting.Git.Timeout.Migrate) * time.Second
+	migrateTimeout := git.OperationTimeout(git.OperationMigrate)
 
 	var err error
 	if err = os.RemoveAll(repoPath); err != nil {
@@ -69,6 +70,15 @@ func MigrateRepositoryGitData(doer, u *models.User, repo *models.Repository, opt
 		return repo, fmt.Errorf("Clone: %v", err)
 	}
 
+	objectFormat, err := git.GetObjectFormatOfRepo(repoPath)
+	if err != nil {
+		return repo, fmt.Errorf("GetObjectFormatOfRepo: %v", err)
+	}
+	if !objectFormat.IsSupported() {
+		return repo, git.ErrUnsupportedObjectFormat{Name: string(objectFormat)}
+	}
+	repo.ObjectFormatName = string(objectFormat)
+
 	if opts.Wiki {
 		wikiPath := models.WikiPath(u.Name, opts.RepoName)
 		wikiRemotePath := wikiRemoteURL(opts.CloneAddr)
@@ -254,7 +264,7 @@ func PushUpdateAddTag(repo *models.Repository, gitRepo *git.Repository, tagName
 		createdAt = sig.When
 	}
 
-	commitsCount, err := commit.CommitsCount()
+	commitsCount, err := cache.GetCommitsCount(commit)
 	if err != nil {
 		return fmt.Errorf("CommitsCount: %v", err)
 	}
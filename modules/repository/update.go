@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/timeutil"
 )
@@ -83,7 +84,7 @@ func pushUpdateAddTags(ctx models.DBContext, repo *models.Repository, gitRepo *g
 			createdAt = sig.When
 		}
 
-		commitsCount, err := commit.CommitsCount()
+		commitsCount, err := cache.GetCommitsCount(commit)
 		if err != nil {
 			return fmt.Errorf("CommitsCount: %v", err)
 		}
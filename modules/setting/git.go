@@ -25,6 +25,8 @@ var (
 		VerbosePushDelay          time.Duration
 		GCArgs                    []string `ini:"GC_ARGS" delim:" "`
 		EnableAutoGitWireProtocol bool
+		DisablePartialClone       bool
+		DisableCommitGraph        bool
 		PullRequestPushMessage    bool
 		Timeout                   struct {
 			Default int
@@ -33,6 +35,9 @@ var (
 			Clone   int
 			Pull    int
 			GC      int `ini:"GC"`
+			Merge   int
+			Archive int
+			Blame   int
 		} `ini:"git.timeout"`
 	}{
 		DisableDiffHighlight:      false,
@@ -43,6 +48,8 @@ var (
 		VerbosePushDelay:          5 * time.Second,
 		GCArgs:                    []string{},
 		EnableAutoGitWireProtocol: true,
+		DisablePartialClone:       false,
+		DisableCommitGraph:        false,
 		PullRequestPushMessage:    true,
 		Timeout: struct {
 			Default int
@@ -51,6 +58,9 @@ var (
 			Clone   int
 			Pull    int
 			GC      int `ini:"GC"`
+			Merge   int
+			Archive int
+			Blame   int
 		}{
 			Default: int(git.DefaultCommandExecutionTimeout / time.Second),
 			Migrate: 600,
@@ -58,6 +68,9 @@ var (
 			Clone:   300,
 			Pull:    300,
 			GC:      60,
+			Merge:   600,
+			Archive: 600,
+			Blame:   120,
 		},
 	}
 )
@@ -70,6 +83,11 @@ func newGit() {
 		log.Fatal("Failed to initialize Git settings", err)
 	}
 	git.DefaultCommandExecutionTimeout = time.Duration(Git.Timeout.Default) * time.Second
+	git.SetOperationTimeout(git.OperationClone, time.Duration(Git.Timeout.Clone)*time.Second)
+	git.SetOperationTimeout(git.OperationMigrate, time.Duration(Git.Timeout.Migrate)*time.Second)
+	git.SetOperationTimeout(git.OperationMerge, time.Duration(Git.Timeout.Merge)*time.Second)
+	git.SetOperationTimeout(git.OperationArchive, time.Duration(Git.Timeout.Archive)*time.Second)
+	git.SetOperationTimeout(git.OperationBlame, time.Duration(Git.Timeout.Blame)*time.Second)
 
 	binVersion, err := git.BinVersion()
 	if err != nil {
@@ -88,6 +106,27 @@ func newGit() {
 		git.GlobalCommandArgs = append(git.GlobalCommandArgs, "-c", "protocol.version=2")
 		format += ", Wire Protocol %s Enabled"
 		args = append(args, "Version 2") // for focus color
+	} else {
+		// Clients can't negotiate the protocol version with the HTTP and SSH servers either
+		Git.EnableAutoGitWireProtocol = false
+	}
+
+	// Partial clones are served since git v2.22
+	if !Git.DisablePartialClone && version.Compare(binVersion, "2.22", ">=") {
+		// The missing objects of a partial clone are fetched by their ID later on
+		git.GlobalCommandArgs = append(git.GlobalCommandArgs, "-c", "uploadpack.allowfilter=true", "-c", "uploadpack.allowAnySHA1InWant=true")
+		format += ", Partial Clone Enabled"
+	} else {
+		Git.DisablePartialClone = true
+	}
+
+	// The commit-graph files written after pushes are read by git since v2.20
+	if !Git.DisableCommitGraph && version.Compare(binVersion, "2.20", ">=") {
+		// Keep them up to date when repositories are garbage collected as well
+		git.GlobalCommandArgs = append(git.GlobalCommandArgs, "-c", "core.commitGraph=true", "-c", "gc.writeCommitGraph=true")
+		format += ", Commit Graph Enabled"
+	} else {
+		Git.DisableCommitGraph = true
 	}
 
 	log.Info(format, args...)
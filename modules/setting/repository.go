@@ -8,6 +8,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/modules/log"
 
@@ -43,6 +44,9 @@ var (
 		DefaultRepoUnits                        []string
 		PrefixArchiveFiles                      bool
 		DisableMirrors                          bool
+		ShareForkObjects                        bool
+		BundleQueueMinSize                      int64
+		GitConfigAllowlist                      []string
 
 		// Repository editor settings
 		Editor struct {
@@ -81,14 +85,26 @@ var (
 			LockReasons []string
 		} `ini:"repository.issue"`
 
+		// Release settings, the sizes are in MB and -1 means no limit
+		Release struct {
+			MaxAssetSize  int64
+			MaxAssets     int
+			MaxTotalSize  int64
+			MaxUploadSize int64
+			UploadExpiry  time.Duration
+		} `ini:"repository.release"`
+
 		Signing struct {
-			SigningKey    string
-			SigningName   string
-			SigningEmail  string
-			InitialCommit []string
-			CRUDActions   []string `ini:"CRUD_ACTIONS"`
-			Merges        []string
-			Wiki          []string
+			SigningKey        string
+			SigningName       string
+			SigningEmail      string
+			InitialCommit     []string
+			CRUDActions       []string `ini:"CRUD_ACTIONS"`
+			Merges            []string
+			Wiki              []string
+			Releases          []string
+			DefaultTrustModel string
+			TrustedKeys       []string
 		} `ini:"repository.signing"`
 	}{
 		DetectedCharsetsOrder: []string{
@@ -144,6 +160,9 @@ var (
 		DefaultRepoUnits:                        []string{},
 		PrefixArchiveFiles:                      true,
 		DisableMirrors:                          false,
+		ShareForkObjects:                        false,
+		BundleQueueMinSize:                      100 << 20,
+		GitConfigAllowlist:                      []string{},
 
 		// Repository editor settings
 		Editor: struct {
@@ -206,23 +225,44 @@ var (
 			LockReasons: strings.Split("Too heated,Off-topic,Spam,Resolved", ","),
 		},
 
+		// Release settings
+		Release: struct {
+			MaxAssetSize  int64
+			MaxAssets     int
+			MaxTotalSize  int64
+			MaxUploadSize int64
+			UploadExpiry  time.Duration
+		}{
+			MaxAssetSize:  -1,
+			MaxAssets:     -1,
+			MaxTotalSize:  -1,
+			MaxUploadSize: 4096,
+			UploadExpiry:  24 * time.Hour,
+		},
+
 		// Signing settings
 		Signing: struct {
-			SigningKey    string
-			SigningName   string
-			SigningEmail  string
-			InitialCommit []string
-			CRUDActions   []string `ini:"CRUD_ACTIONS"`
-			Merges        []string
-			Wiki          []string
+			SigningKey        string
+			SigningName       string
+			SigningEmail      string
+			InitialCommit     []string
+			CRUDActions       []string `ini:"CRUD_ACTIONS"`
+			Merges            []string
+			Wiki              []string
+			Releases          []string
+			DefaultTrustModel string
+			TrustedKeys       []string
 		}{
-			SigningKey:    "default",
-			SigningName:   "",
-			SigningEmail:  "",
-			InitialCommit: []string{"always"},
-			CRUDActions:   []string{"pubkey", "twofa", "parentsigned"},
-			Merges:        []string{"pubkey", "twofa", "basesigned", "commitssigned"},
-			Wiki:          []string{"never"},
+			SigningKey:        "default",
+			SigningName:       "",
+			SigningEmail:      "",
+			InitialCommit:     []string{"always"},
+			CRUDActions:       []string{"pubkey", "twofa", "parentsigned"},
+			Merges:            []string{"pubkey", "twofa", "basesigned", "commitssigned"},
+			Wiki:              []string{"never"},
+			Releases:          []string{"never"},
+			DefaultTrustModel: "collaborator",
+			TrustedKeys:       []string{},
 		},
 	}
 	RepoRootPath string
@@ -264,6 +304,8 @@ func newRepository() {
 		log.Fatal("Failed to map Repository.Local settings: %v", err)
 	} else if err = Cfg.Section("repository.pull-request").MapTo(&Repository.PullRequest); err != nil {
 		log.Fatal("Failed to map Repository.PullRequest settings: %v", err)
+	} else if err = Cfg.Section("repository.release").MapTo(&Repository.Release); err != nil {
+		log.Fatal("Failed to map Repository.Release settings: %v", err)
 	}
 
 	preferred := make([]string, 0, len(Repository.DetectedCharsetsOrder))
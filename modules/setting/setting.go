@@ -142,6 +142,18 @@ var (
 		HTTPAuthExpiry  time.Duration `ini:"LFS_HTTP_AUTH_EXPIRY"`
 		MaxFileSize     int64         `ini:"LFS_MAX_FILE_SIZE"`
 		LocksPagingNum  int           `ini:"LFS_LOCKS_PAGING_NUM"`
+
+		// StoreType is either "local" or "minio" for S3 compatible object storages,
+		// configured in the lfs section like the storage of attachments
+		StoreType            string `ini:"-"`
+		ServeDirect          bool   `ini:"-"`
+		MinioEndpoint        string `ini:"-"`
+		MinioAccessKeyID     string `ini:"-"`
+		MinioSecretAccessKey string `ini:"-"`
+		MinioBucket          string `ini:"-"`
+		MinioLocation        string `ini:"-"`
+		MinioBasePath        string `ini:"-"`
+		MinioUseSSL          bool   `ini:"-"`
 	}
 
 	// Security settings
@@ -305,6 +317,34 @@ var (
 	AttachmentMaxFiles     int
 	AttachmentEnabled      bool
 
+	// AttachmentReleaseChecksums enables the generated checksums file on releases
+	AttachmentReleaseChecksums bool
+
+	// AttachmentStoreType is either "local" or "minio" for S3 compatible object storages
+	AttachmentStoreType            string
+	AttachmentMinioEndpoint        string
+	AttachmentMinioAccessKeyID     string
+	AttachmentMinioSecretAccessKey string
+	AttachmentMinioBucket          string
+	AttachmentMinioLocation        string
+	AttachmentMinioBasePath        string
+	AttachmentMinioUseSSL          bool
+
+	// AttachmentDownloadCountFlushInterval is the interval to write the buffered download counts, zero disables the buffering
+	AttachmentDownloadCountFlushInterval time.Duration
+
+	// AttachmentCDNEnabled enables mirroring the assets of published releases of public repositories
+	// to a S3 compatible bucket which is served under AttachmentCDNBaseURL
+	AttachmentCDNEnabled          bool
+	AttachmentCDNBaseURL          string
+	AttachmentCDNMinioEndpoint    string
+	AttachmentCDNMinioAccessKeyID string
+	AttachmentCDNMinioSecretKey   string
+	AttachmentCDNMinioBucket      string
+	AttachmentCDNMinioLocation    string
+	AttachmentCDNMinioBasePath    string
+	AttachmentCDNMinioUseSSL      bool
+
 	// Time settings
 	TimeFormat string
 	// UILocation is the location on the UI, so that we can display the time on UI.
@@ -323,17 +363,29 @@ var (
 	API = struct {
 		EnableSwagger          bool
 		SwaggerURL             string
+		EnableGraphQL          bool
 		MaxResponseItems       int
 		DefaultPagingNum       int
 		DefaultGitTreesPerPage int
 		DefaultMaxBlobSize     int64
+		MaxBatchIssues         int
+		EnableRateLimit        bool
+		RateLimitPeriod        time.Duration
+		RateLimitPerIP         int `ini:"RATE_LIMIT_PER_IP"`
+		RateLimitPerUser       int
 	}{
 		EnableSwagger:          true,
 		SwaggerURL:             "",
+		EnableGraphQL:          false,
 		MaxResponseItems:       50,
 		DefaultPagingNum:       30,
 		DefaultGitTreesPerPage: 1000,
 		DefaultMaxBlobSize:     10485760,
+		MaxBatchIssues:         50,
+		EnableRateLimit:        false,
+		RateLimitPeriod:        time.Hour,
+		RateLimitPerIP:         60,
+		RateLimitPerUser:       5000,
 	}
 
 	OAuth2 = struct {
@@ -751,6 +803,17 @@ func NewContext() {
 
 	LFS.HTTPAuthExpiry = sec.Key("LFS_HTTP_AUTH_EXPIRY").MustDuration(20 * time.Minute)
 
+	lfsSec := Cfg.Section("lfs")
+	LFS.StoreType = lfsSec.Key("STORE_TYPE").In("local", []string{"local", "minio"})
+	LFS.ServeDirect = lfsSec.Key("SERVE_DIRECT").MustBool(false)
+	LFS.MinioEndpoint = lfsSec.Key("MINIO_ENDPOINT").MustString("localhost:9000")
+	LFS.MinioAccessKeyID = lfsSec.Key("MINIO_ACCESS_KEY_ID").MustString("")
+	LFS.MinioSecretAccessKey = lfsSec.Key("MINIO_SECRET_ACCESS_KEY").MustString("")
+	LFS.MinioBucket = lfsSec.Key("MINIO_BUCKET").MustString("gitea")
+	LFS.MinioLocation = lfsSec.Key("MINIO_LOCATION").MustString("us-east-1")
+	LFS.MinioBasePath = lfsSec.Key("MINIO_BASE_PATH").MustString("lfs/")
+	LFS.MinioUseSSL = lfsSec.Key("MINIO_USE_SSL").MustBool(false)
+
 	if LFS.StartServer {
 		LFS.JWTSecretBytes = make([]byte, 32)
 		n, err := base64.RawURLEncoding.Decode(LFS.JWTSecretBytes, []byte(LFS.JWTSecretBase64))
@@ -856,6 +919,31 @@ func NewContext() {
 	AttachmentMaxSize = sec.Key("MAX_SIZE").MustInt64(4)
 	AttachmentMaxFiles = sec.Key("MAX_FILES").MustInt(5)
 	AttachmentEnabled = sec.Key("ENABLED").MustBool(true)
+	AttachmentReleaseChecksums = sec.Key("RELEASE_CHECKSUMS").MustBool(false)
+	AttachmentStoreType = sec.Key("STORE_TYPE").In("local", []string{"local", "minio"})
+	AttachmentMinioEndpoint = sec.Key("MINIO_ENDPOINT").MustString("localhost:9000")
+	AttachmentMinioAccessKeyID = sec.Key("MINIO_ACCESS_KEY_ID").MustString("")
+	AttachmentMinioSecretAccessKey = sec.Key("MINIO_SECRET_ACCESS_KEY").MustString("")
+	AttachmentMinioBucket = sec.Key("MINIO_BUCKET").MustString("gitea")
+	AttachmentMinioLocation = sec.Key("MINIO_LOCATION").MustString("us-east-1")
+	AttachmentMinioBasePath = sec.Key("MINIO_BASE_PATH").MustString("attachments/")
+	AttachmentMinioUseSSL = sec.Key("MINIO_USE_SSL").MustBool(false)
+	AttachmentDownloadCountFlushInterval = sec.Key("DOWNLOAD_COUNT_FLUSH_INTERVAL").MustDuration(10 * time.Second)
+	AttachmentCDNEnabled = sec.Key("CDN_ENABLED").MustBool(false)
+	AttachmentCDNBaseURL = sec.Key("CDN_BASE_URL").MustString("")
+	if AttachmentCDNBaseURL != "" && !strings.HasSuffix(AttachmentCDNBaseURL, "/") {
+		AttachmentCDNBaseURL += "/"
+	}
+	AttachmentCDNMinioEndpoint = sec.Key("CDN_MINIO_ENDPOINT").MustString("localhost:9000")
+	AttachmentCDNMinioAccessKeyID = sec.Key("CDN_MINIO_ACCESS_KEY_ID").MustString("")
+	AttachmentCDNMinioSecretKey = sec.Key("CDN_MINIO_SECRET_ACCESS_KEY").MustString("")
+	AttachmentCDNMinioBucket = sec.Key("CDN_MINIO_BUCKET").MustString("gitea-releases")
+	AttachmentCDNMinioLocation = sec.Key("CDN_MINIO_LOCATION").MustString("us-east-1")
+	AttachmentCDNMinioBasePath = sec.Key("CDN_MINIO_BASE_PATH").MustString("")
+	AttachmentCDNMinioUseSSL = sec.Key("CDN_MINIO_USE_SSL").MustBool(false)
+	if AttachmentCDNEnabled && AttachmentCDNBaseURL == "" {
+		log.Fatal("CDN_BASE_URL in [attachment] is required when CDN_ENABLED is true")
+	}
 
 	timeFormatKey := Cfg.Section("time").Key("FORMAT").MustString("")
 	if timeFormatKey != "" {
@@ -1108,7 +1196,7 @@ func loadOrGenerateInternalToken(sec *ini.Section) string {
 }
 
 func ensureLFSDirectory() {
-	if LFS.StartServer {
+	if LFS.StartServer && LFS.StoreType == "local" {
 		if err := os.MkdirAll(LFS.ContentPath, 0700); err != nil {
 			log.Fatal("Failed to create '%s': %v", LFS.ContentPath, err)
 		}
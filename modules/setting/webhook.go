@@ -6,6 +6,8 @@ package setting
 
 import (
 	"net/url"
+	"path/filepath"
+	"time"
 
 	"code.gitea.io/gitea/modules/log"
 )
@@ -21,6 +23,22 @@ var (
 		ProxyURL       string
 		ProxyURLFixed  *url.URL
 		ProxyHosts     []string
+		AllowedHosts   []string
+		BlockedHosts   []string
+		MaxAttempts    int
+		RetryBackoff   time.Duration
+		// Compress the payloads of delivered hook tasks
+		CompressDeliveredPayloads bool
+		// Number of hooks delivered at the same time
+		DeliverWorkers int
+		// Limits of the deliveries to a single host, 0 for no limit
+		HostMaxConcurrency int
+		HostRateLimit      float64
+		HostRateBurst      int
+		// Client certificate presented to endpoints requiring mutual TLS
+		// by the webhooks without their own certificate
+		ClientCertFile string
+		ClientKeyFile  string
 	}{
 		QueueLength:    1000,
 		DeliverTimeout: 5,
@@ -28,6 +46,11 @@ var (
 		PagingNum:      10,
 		ProxyURL:       "",
 		ProxyHosts:     []string{},
+		AllowedHosts:   []string{},
+		BlockedHosts:   []string{},
+		MaxAttempts:    5,
+		RetryBackoff:   10 * time.Second,
+		DeliverWorkers: 4,
 	}
 )
 
@@ -36,7 +59,7 @@ func newWebhookService() {
 	Webhook.QueueLength = sec.Key("QUEUE_LENGTH").MustInt(1000)
 	Webhook.DeliverTimeout = sec.Key("DELIVER_TIMEOUT").MustInt(5)
 	Webhook.SkipTLSVerify = sec.Key("SKIP_TLS_VERIFY").MustBool()
-	Webhook.Types = []string{"gitea", "gogs", "slack", "discord", "dingtalk", "telegram", "msteams", "feishu", "matrix"}
+	Webhook.Types = []string{"gitea", "gogs", "slack", "discord", "dingtalk", "telegram", "msteams", "feishu", "matrix", "custom"}
 	Webhook.PagingNum = sec.Key("PAGING_NUM").MustInt(10)
 	Webhook.ProxyURL = sec.Key("PROXY_URL").MustString("")
 	if Webhook.ProxyURL != "" {
@@ -45,7 +68,39 @@ func newWebhookService() {
 		if err != nil {
 			log.Error("Webhook PROXY_URL is not valid")
 			Webhook.ProxyURL = ""
+		} else if scheme := Webhook.ProxyURLFixed.Scheme; scheme != "http" && scheme != "https" && scheme != "socks5" {
+			log.Error("Webhook PROXY_URL scheme %q is not supported", scheme)
+			Webhook.ProxyURL = ""
 		}
 	}
 	Webhook.ProxyHosts = sec.Key("PROXY_HOSTS").Strings(",")
+	Webhook.AllowedHosts = sec.Key("ALLOWED_HOSTS").Strings(",")
+	Webhook.BlockedHosts = sec.Key("BLOCKED_HOSTS").Strings(",")
+	Webhook.MaxAttempts = sec.Key("MAX_ATTEMPTS").MustInt(5)
+	if Webhook.MaxAttempts < 1 {
+		Webhook.MaxAttempts = 1
+	}
+	Webhook.RetryBackoff = sec.Key("RETRY_BACKOFF").MustDuration(10 * time.Second)
+	if Webhook.RetryBackoff <= 0 {
+		Webhook.RetryBackoff = 10 * time.Second
+	}
+	Webhook.CompressDeliveredPayloads = sec.Key("COMPRESS_DELIVERED_PAYLOADS").MustBool(false)
+	Webhook.DeliverWorkers = sec.Key("DELIVER_WORKERS").MustInt(4)
+	if Webhook.DeliverWorkers < 1 {
+		Webhook.DeliverWorkers = 1
+	}
+	Webhook.HostMaxConcurrency = sec.Key("HOST_MAX_CONCURRENCY").MustInt(2)
+	Webhook.HostRateLimit = sec.Key("HOST_RATE_LIMIT").MustFloat64(0)
+	Webhook.HostRateBurst = sec.Key("HOST_RATE_BURST").MustInt(1)
+	if Webhook.HostRateBurst < 1 {
+		Webhook.HostRateBurst = 1
+	}
+	Webhook.ClientCertFile = sec.Key("CLIENT_CERT_FILE").String()
+	if len(Webhook.ClientCertFile) > 0 && !filepath.IsAbs(Webhook.ClientCertFile) {
+		Webhook.ClientCertFile = filepath.Join(CustomPath, Webhook.ClientCertFile)
+	}
+	Webhook.ClientKeyFile = sec.Key("CLIENT_KEY_FILE").String()
+	if len(Webhook.ClientKeyFile) > 0 && !filepath.IsAbs(Webhook.ClientKeyFile) {
+		Webhook.ClientKeyFile = filepath.Join(CustomPath, Webhook.ClientKeyFile)
+	}
 }
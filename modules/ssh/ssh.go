@@ -70,6 +70,12 @@ func sessionHandler(session ssh.Session) {
 		"SSH_ORIGINAL_COMMAND="+command,
 		"SKIP_MINWINSVC=1",
 	)
+	// Pass on the wire protocol version requested by the client, the serv command validates it
+	for _, env := range session.Environ() {
+		if strings.HasPrefix(env, "GIT_PROTOCOL=") {
+			cmd.Env = append(cmd.Env, env)
+		}
+	}
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -0,0 +1,95 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+var (
+	_ ObjectStorage = &LocalStorage{}
+)
+
+// LocalStorage represents a local files storage
+type LocalStorage struct {
+	dir string
+}
+
+// NewLocalStorage returns a local files
+func NewLocalStorage(bucket string) (*LocalStorage, error) {
+	if err := os.MkdirAll(bucket, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	return &LocalStorage{
+		dir: bucket,
+	}, nil
+}
+
+// Open a file
+func (l *LocalStorage) Open(path string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(l.dir, path))
+	if os.IsNotExist(err) {
+		return nil, ErrObjectNotExist
+	}
+	return f, err
+}
+
+// Save a file
+func (l *LocalStorage) Save(path string, r io.Reader) (int64, error) {
+	p := filepath.Join(l.dir, path)
+	if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+		return 0, err
+	}
+
+	// The content is written to a temporary file first, so the file is never read partially written
+	tmp, err := ioutil.TempFile(filepath.Dir(p), filepath.Base(p)+".*.tmp")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	size, err := io.Copy(tmp, r)
+	if err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+
+	// always override
+	if err := os.RemoveAll(p); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// Stat returns the info of a file
+func (l *LocalStorage) Stat(path string) (os.FileInfo, error) {
+	fi, err := os.Stat(filepath.Join(l.dir, path))
+	if os.IsNotExist(err) {
+		return nil, ErrObjectNotExist
+	}
+	return fi, err
+}
+
+// Delete delete a file
+func (l *LocalStorage) Delete(path string) error {
+	p := filepath.Join(l.dir, path)
+	return os.RemoveAll(p)
+}
+
+// URL gets the redirect URL to a file, which local files don't have
+func (l *LocalStorage) URL(path, name string) (*url.URL, error) {
+	return nil, ErrURLNotSupported
+}
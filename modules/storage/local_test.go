@@ -0,0 +1,47 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "storage")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	l, err := NewLocalStorage(dir)
+	assert.NoError(t, err)
+
+	size, err := l.Save("a/b/file", strings.NewReader("hello world"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 11, size)
+
+	r, err := l.Open("a/b/file")
+	assert.NoError(t, err)
+	data, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	r.Close()
+	assert.Equal(t, "hello world", string(data))
+
+	fi, err := l.Stat("a/b/file")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 11, fi.Size())
+
+	_, err = l.URL("a/b/file", "file")
+	assert.Equal(t, ErrURLNotSupported, err)
+
+	assert.NoError(t, l.Delete("a/b/file"))
+	_, err = l.Open("a/b/file")
+	assert.Equal(t, ErrObjectNotExist, err)
+	_, err = l.Stat("a/b/file")
+	assert.Equal(t, ErrObjectNotExist, err)
+}
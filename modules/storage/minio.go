@@ -0,0 +1,567 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	_ ObjectStorage = &MinioStorage{}
+
+	// minioIdleTimeout is how long the storage may stall before a request is aborted:
+	// until the headers of its response are received, then between two reads of its content
+	minioIdleTimeout = time.Minute
+
+	// minioPartSize is the size of the parts larger objects are uploaded in,
+	// a single upload is limited to 5GB
+	minioPartSize int64 = 64 * 1024 * 1024
+)
+
+const (
+	minioAlgorithm       = "AWS4-HMAC-SHA256"
+	minioService         = "s3"
+	minioUnsignedBody    = "UNSIGNED-PAYLOAD"
+	minioTimeFormat      = "20060102T150405Z"
+	minioShortTimeLayout = "20060102"
+
+	// minioURLExpiry is how long the urls the clients download the objects from are valid
+	minioURLExpiry = 5 * time.Minute
+
+	// minioMaxParts is the maximum number of parts of a multipart upload
+	minioMaxParts = 10000
+)
+
+// MinioStorage represents a S3 compatible object storage, e.g. MinIO or AWS S3
+type MinioStorage struct {
+	client          *http.Client
+	endpoint        *url.URL
+	accessKeyID     string
+	secretAccessKey string
+	bucket          string
+	location        string
+	basePath        string
+}
+
+// NewMinioStorage returns a S3 compatible storage and creates the bucket if it does not exist yet
+func NewMinioStorage(endpoint, accessKeyID, secretAccessKey, bucket, location, basePath string, useSSL bool) (*MinioStorage, error) {
+	scheme := "http"
+	if useSSL {
+		scheme = "https"
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = minioIdleTimeout
+
+	m := &MinioStorage{
+		client:          &http.Client{Transport: transport},
+		endpoint:        &url.URL{Scheme: scheme, Host: endpoint},
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		bucket:          bucket,
+		location:        location,
+		basePath:        basePath,
+	}
+
+	if err := m.ensureBucket(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *MinioStorage) ensureBucket() error {
+	resp, err := m.do(http.MethodHead, "", nil, nil, -1, minioUnsignedBody)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+	default:
+		return fmt.Errorf("HeadBucket %s: unexpected status %s", m.bucket, resp.Status)
+	}
+
+	var body []byte
+	if m.location != "" && m.location != "us-east-1" {
+		body = []byte(fmt.Sprintf(`<CreateBucketConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><LocationConstraint>%s</LocationConstraint></CreateBucketConfiguration>`, m.location))
+	}
+	sum := sha256.Sum256(body)
+	resp, err = m.do(http.MethodPut, "", nil, bytes.NewReader(body), int64(len(body)), hex.EncodeToString(sum[:]))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return minioResponseError("MakeBucket", m.bucket, resp)
+	}
+	return nil
+}
+
+func (m *MinioStorage) objectKey(p string) string {
+	return strings.TrimPrefix(path.Join(m.basePath, p), "/")
+}
+
+// Open returns a reader streaming the content of the object
+func (m *MinioStorage) Open(path string) (io.ReadCloser, error) {
+	resp, err := m.do(http.MethodGet, m.objectKey(path), nil, nil, -1, minioUnsignedBody)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrObjectNotExist
+	default:
+		defer resp.Body.Close()
+		return nil, minioResponseError("GetObject", path, resp)
+	}
+}
+
+// Save uploads the content of the reader as the object, in parts if it is too large for a single upload
+func (m *MinioStorage) Save(path string, r io.Reader) (int64, error) {
+	// S3 requires the content length to be known in advance, so spool the
+	// content to a temporary file if the reader does not provide it.
+	var content io.ReaderAt
+	var offset, size int64 = 0, -1
+	if f, ok := r.(*os.File); ok {
+		if fi, err := f.Stat(); err == nil && fi.Mode().IsRegular() {
+			if offset, err = f.Seek(0, io.SeekCurrent); err == nil {
+				content, size = f, fi.Size()-offset
+			}
+		}
+	}
+	if size < 0 {
+		tmp, err := ioutil.TempFile("", "gitea-storage")
+		if err != nil {
+			return 0, err
+		}
+		defer func() {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}()
+
+		if size, err = io.Copy(tmp, r); err != nil {
+			return 0, err
+		}
+		content, offset = tmp, 0
+	}
+
+	if size > minioPartSize {
+		return size, m.saveMultipart(path, content, offset, size)
+	}
+
+	resp, err := m.do(http.MethodPut, m.objectKey(path), nil, io.NewSectionReader(content, offset, size), size, minioUnsignedBody)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, minioResponseError("PutObject", path, resp)
+	}
+	return size, nil
+}
+
+type minioInitiateMultipartUploadResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+type minioCompletedPart struct {
+	PartNumber int
+	ETag       string
+}
+
+type minioCompleteMultipartUpload struct {
+	XMLName xml.Name             `xml:"CompleteMultipartUpload"`
+	Parts   []minioCompletedPart `xml:"Part"`
+}
+
+// minioErrorResponse is the error a storage may return with a successful status
+// once it has started to answer a long running request
+type minioErrorResponse struct {
+	XMLName xml.Name
+	Code    string
+	Message string
+}
+
+// saveMultipart uploads the content in parts, the upload is aborted if one of them fails
+func (m *MinioStorage) saveMultipart(path string, content io.ReaderAt, offset, size int64) error {
+	key := m.objectKey(path)
+
+	partSize := minioPartSize
+	if minSize := (size + minioMaxParts - 1) / minioMaxParts; partSize < minSize {
+		partSize = minSize
+	}
+
+	resp, err := m.do(http.MethodPost, key, url.Values{"uploads": {""}}, nil, 0, minioUnsignedBody)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return minioResponseError("CreateMultipartUpload", path, resp)
+	}
+	var initiated minioInitiateMultipartUploadResult
+	if err := xml.NewDecoder(resp.Body).Decode(&initiated); err != nil {
+		return fmt.Errorf("CreateMultipartUpload %s: %v", path, err)
+	}
+	if initiated.UploadID == "" {
+		return fmt.Errorf("CreateMultipartUpload %s: no upload id returned", path)
+	}
+
+	complete := minioCompleteMultipartUpload{}
+	for partOffset := int64(0); partOffset < size; partOffset += partSize {
+		partLength := partSize
+		if partOffset+partLength > size {
+			partLength = size - partOffset
+		}
+		etag, err := m.uploadPart(key, initiated.UploadID, len(complete.Parts)+1, io.NewSectionReader(content, offset+partOffset, partLength), partLength)
+		if err != nil {
+			m.abortMultipart(key, initiated.UploadID)
+			return fmt.Errorf("UploadPart %s: %v", path, err)
+		}
+		complete.Parts = append(complete.Parts, minioCompletedPart{PartNumber: len(complete.Parts) + 1, ETag: etag})
+	}
+
+	if err := m.completeMultipart(key, initiated.UploadID, &complete); err != nil {
+		m.abortMultipart(key, initiated.UploadID)
+		return fmt.Errorf("CompleteMultipartUpload %s: %v", path, err)
+	}
+	return nil
+}
+
+// uploadPart uploads a part of a multipart upload and returns its ETag
+func (m *MinioStorage) uploadPart(key, uploadID string, partNumber int, part io.Reader, size int64) (string, error) {
+	query := url.Values{
+		"partNumber": {strconv.Itoa(partNumber)},
+		"uploadId":   {uploadID},
+	}
+	resp, err := m.do(http.MethodPut, key, query, part, size, minioUnsignedBody)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", minioResponseError("UploadPart", strconv.Itoa(partNumber), resp)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		return "", fmt.Errorf("no ETag returned for part %d", partNumber)
+	}
+	return etag, nil
+}
+
+func (m *MinioStorage) completeMultipart(key, uploadID string, complete *minioCompleteMultipartUpload) error {
+	body, err := xml.Marshal(complete)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(body)
+	resp, err := m.do(http.MethodPost, key, url.Values{"uploadId": {uploadID}}, bytes.NewReader(body), int64(len(body)), hex.EncodeToString(sum[:]))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return minioResponseError("CompleteMultipartUpload", key, resp)
+	}
+
+	// The storage may only fail once it has started to answer with a successful status
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var errResp minioErrorResponse
+	if xml.Unmarshal(data, &errResp) == nil && errResp.XMLName.Local == "Error" {
+		return fmt.Errorf("%s: %s", errResp.Code, errResp.Message)
+	}
+	return nil
+}
+
+// abortMultipart aborts a multipart upload so that the storage discards its uploaded parts,
+// if it fails too they are left to the lifecycle rules of the bucket
+func (m *MinioStorage) abortMultipart(key, uploadID string) {
+	resp, err := m.do(http.MethodDelete, key, url.Values{"uploadId": {uploadID}}, nil, -1, minioUnsignedBody)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+}
+
+// Stat returns the information of the object
+func (m *MinioStorage) Stat(p string) (os.FileInfo, error) {
+	resp, err := m.do(http.MethodHead, m.objectKey(p), nil, nil, -1, minioUnsignedBody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrObjectNotExist
+	default:
+		return nil, minioResponseError("StatObject", p, resp)
+	}
+
+	size, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("StatObject %s: invalid content length: %v", p, err)
+	}
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return &minioFileInfo{name: path.Base(p), size: size, modTime: modTime}, nil
+}
+
+// Delete removes the object
+func (m *MinioStorage) Delete(path string) error {
+	resp, err := m.do(http.MethodDelete, m.objectKey(path), nil, nil, -1, minioUnsignedBody)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return minioResponseError("RemoveObject", path, resp)
+	}
+	return nil
+}
+
+// URL returns a presigned url the clients can download the object from as a file of the name
+func (m *MinioStorage) URL(path, name string) (*url.URL, error) {
+	u := m.objectURL(m.objectKey(path))
+	query := url.Values{}
+	query.Set("response-content-disposition", fmt.Sprintf("attachment; filename=\"%s\"", strings.Replace(name, "\"", "", -1)))
+	u.RawQuery = query.Encode()
+	m.presign(u, http.MethodGet, minioURLExpiry, time.Now())
+	return u, nil
+}
+
+func (m *MinioStorage) objectURL(key string) *url.URL {
+	p := "/" + m.bucket
+	if key != "" {
+		p += "/" + key
+	}
+	u := *m.endpoint
+	u.Path = p
+	u.RawPath = minioEscapePath(p)
+	return &u
+}
+
+// do sends a signed request to the storage, the content of the response has to be closed
+func (m *MinioStorage) do(method, key string, query url.Values, body io.Reader, size int64, payloadHash string) (*http.Response, error) {
+	u := m.objectURL(key)
+	if len(query) > 0 {
+		u.RawQuery = minioCanonicalQuery(query)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if size >= 0 {
+		req.ContentLength = size
+	}
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	m.sign(req, payloadHash, time.Now())
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &minioIdleReader{
+		ReadCloser: resp.Body,
+		timer:      time.AfterFunc(minioIdleTimeout, cancel),
+		cancel:     cancel,
+	}
+	return resp, nil
+}
+
+// minioIdleReader is the content of a response which aborts its request if the storage stalls
+type minioIdleReader struct {
+	io.ReadCloser
+	timer  *time.Timer
+	cancel context.CancelFunc
+}
+
+func (r *minioIdleReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.timer.Reset(minioIdleTimeout)
+	return n, err
+}
+
+func (r *minioIdleReader) Close() error {
+	r.timer.Stop()
+	defer r.cancel()
+	return r.ReadCloser.Close()
+}
+
+// sign adds an AWS Signature Version 4 authorization header to the request
+func (m *MinioStorage) sign(req *http.Request, payloadHash string, t time.Time) {
+	t = t.UTC()
+	amzDate := t.Format(minioTimeFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		minioEscapePath(req.URL.Path),
+		minioCanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	signature := m.signature(canonicalRequest, t)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		minioAlgorithm, m.accessKeyID, m.scope(t), signedHeaders, signature))
+}
+
+// presign adds the query parameters of an AWS Signature Version 4 presigned url to the url,
+// which is valid for the expiry
+func (m *MinioStorage) presign(u *url.URL, method string, expiry time.Duration, t time.Time) {
+	t = t.UTC()
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", minioAlgorithm)
+	query.Set("X-Amz-Credential", m.accessKeyID+"/"+m.scope(t))
+	query.Set("X-Amz-Date", t.Format(minioTimeFormat))
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(expiry/time.Second), 10))
+	query.Set("X-Amz-SignedHeaders", "host")
+	canonicalQuery := minioCanonicalQuery(query)
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		minioEscapePath(u.Path),
+		canonicalQuery,
+		"host:" + u.Host + "\n",
+		"host",
+		minioUnsignedBody,
+	}, "\n")
+
+	u.RawQuery = canonicalQuery + "&X-Amz-Signature=" + m.signature(canonicalRequest, t)
+}
+
+// scope returns the credential scope of the signatures made at the time
+func (m *MinioStorage) scope(t time.Time) string {
+	return strings.Join([]string{t.UTC().Format(minioShortTimeLayout), m.location, minioService, "aws4_request"}, "/")
+}
+
+// signature returns the signature of the canonical request made at the time
+func (m *MinioStorage) signature(canonicalRequest string, t time.Time) string {
+	t = t.UTC()
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		minioAlgorithm,
+		t.Format(minioTimeFormat),
+		m.scope(t),
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := minioHMAC([]byte("AWS4"+m.secretAccessKey), t.Format(minioShortTimeLayout))
+	key = minioHMAC(key, m.location)
+	key = minioHMAC(key, minioService)
+	key = minioHMAC(key, "aws4_request")
+	return hex.EncodeToString(minioHMAC(key, stringToSign))
+}
+
+// minioFileInfo is the information of an object of a S3 compatible storage
+type minioFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (fi *minioFileInfo) Name() string       { return fi.name }
+func (fi *minioFileInfo) Size() int64        { return fi.size }
+func (fi *minioFileInfo) Mode() os.FileMode  { return 0644 }
+func (fi *minioFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *minioFileInfo) IsDir() bool        { return false }
+func (fi *minioFileInfo) Sys() interface{}   { return nil }
+
+func minioHMAC(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// minioEscapePath escapes every byte of the path except the unreserved characters and slashes
+func minioEscapePath(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func minioEscapeQuery(s string) string {
+	return strings.Replace(minioEscapePath(s), "/", "%2F", -1)
+}
+
+func minioCanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values := query[k]
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, minioEscapeQuery(k)+"="+minioEscapeQuery(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func minioResponseError(op, path string, resp *http.Response) error {
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("%s %s: unexpected status %s: %s", op, path, resp.Status, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,128 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// ErrObjectNotExist is returned when the requested object does not exist in the storage
+var ErrObjectNotExist = errors.New("object does not exist")
+
+// ErrURLNotSupported is returned when the storage can't serve its objects directly to the clients
+var ErrURLNotSupported = errors.New("url method not supported")
+
+// ObjectStorage represents an object storage to handle a bucket and files
+type ObjectStorage interface {
+	// Open returns a reader to stream the content of the object
+	Open(path string) (io.ReadCloser, error)
+	// Save stores the content of the reader as the object and returns the written size
+	Save(path string, r io.Reader) (int64, error)
+	// Stat returns the information of the object, ErrObjectNotExist if it does not exist
+	Stat(path string) (os.FileInfo, error)
+	// Delete removes the object, deleting a non existing object is not an error
+	Delete(path string) error
+	// URL returns a temporary url the clients can download the object from as a file of the name,
+	// ErrURLNotSupported if the storage can't serve it directly
+	URL(path, name string) (*url.URL, error)
+}
+
+// Copy copies a file from source ObjectStorage to dest ObjectStorage
+func Copy(dstStorage ObjectStorage, dstPath string, srcStorage ObjectStorage, srcPath string) (int64, error) {
+	f, err := srcStorage.Open(srcPath)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	return dstStorage.Save(dstPath, f)
+}
+
+var (
+	// Attachments represents attachments storage
+	Attachments ObjectStorage
+
+	// ReleaseCDN represents the storage the release assets are mirrored to, nil if disabled
+	ReleaseCDN ObjectStorage
+
+	// LFS represents lfs objects storage
+	LFS ObjectStorage
+)
+
+// Init init the storage
+func Init() error {
+	if err := initAttachments(); err != nil {
+		return err
+	}
+	if err := initLFS(); err != nil {
+		return err
+	}
+	return initReleaseCDN()
+}
+
+func initAttachments() error {
+	var err error
+	switch setting.AttachmentStoreType {
+	case "local":
+		Attachments, err = NewLocalStorage(setting.AttachmentPath)
+	case "minio":
+		Attachments, err = NewMinioStorage(
+			setting.AttachmentMinioEndpoint,
+			setting.AttachmentMinioAccessKeyID,
+			setting.AttachmentMinioSecretAccessKey,
+			setting.AttachmentMinioBucket,
+			setting.AttachmentMinioLocation,
+			setting.AttachmentMinioBasePath,
+			setting.AttachmentMinioUseSSL,
+		)
+	default:
+		return fmt.Errorf("Unsupported attachment store type: %s", setting.AttachmentStoreType)
+	}
+	return err
+}
+
+func initLFS() error {
+	var err error
+	switch setting.LFS.StoreType {
+	case "local":
+		LFS, err = NewLocalStorage(setting.LFS.ContentPath)
+	case "minio":
+		LFS, err = NewMinioStorage(
+			setting.LFS.MinioEndpoint,
+			setting.LFS.MinioAccessKeyID,
+			setting.LFS.MinioSecretAccessKey,
+			setting.LFS.MinioBucket,
+			setting.LFS.MinioLocation,
+			setting.LFS.MinioBasePath,
+			setting.LFS.MinioUseSSL,
+		)
+	default:
+		return fmt.Errorf("Unsupported lfs store type: %s", setting.LFS.StoreType)
+	}
+	return err
+}
+
+func initReleaseCDN() (err error) {
+	if !setting.AttachmentCDNEnabled {
+		ReleaseCDN = nil
+		return nil
+	}
+	ReleaseCDN, err = NewMinioStorage(
+		setting.AttachmentCDNMinioEndpoint,
+		setting.AttachmentCDNMinioAccessKeyID,
+		setting.AttachmentCDNMinioSecretKey,
+		setting.AttachmentCDNMinioBucket,
+		setting.AttachmentCDNMinioLocation,
+		setting.AttachmentCDNMinioBasePath,
+		setting.AttachmentCDNMinioUseSSL,
+	)
+	return err
+}
@@ -0,0 +1,26 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// Activity represents an action performed in a repository, as shown in the activity feeds
+type Activity struct {
+	ID int64 `json:"id"`
+	// the name of the type of the action, like `commit_repo`, `create_issue` or `publish_release`
+	OpType    string      `json:"op_type"`
+	ActUserID int64       `json:"act_user_id"`
+	ActUser   *User       `json:"act_user"`
+	RepoID    int64       `json:"repo_id"`
+	Repo      *Repository `json:"repo"`
+	CommentID int64       `json:"comment_id"`
+	RefName   string      `json:"ref_name"`
+	IsPrivate bool        `json:"is_private"`
+	Content   string      `json:"content"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created"`
+}
@@ -19,10 +19,45 @@ type Attachment struct {
 	Created     time.Time `json:"created_at"`
 	UUID        string    `json:"uuid"`
 	DownloadURL string    `json:"browser_download_url"`
+	SHA256      string    `json:"sha256"`
+	SHA512      string    `json:"sha512"`
+	// role of a release attachment, one of signature, certificate or provenance, empty for a regular asset
+	Role string `json:"role,omitempty"`
 }
 
 // EditAttachmentOptions options for editing attachments
 // swagger:model
 type EditAttachmentOptions struct {
 	Name string `json:"name"`
+	// role of a release attachment, one of signature, certificate or provenance, empty for a regular asset
+	Role *string `json:"role"`
+}
+
+// AttachmentUpload represents a resumable upload of an attachment
+// swagger:model
+type AttachmentUpload struct {
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+	// total size of the attachment in bytes
+	Size int64 `json:"size"`
+	// amount of bytes received so far, the next chunk has to start at this offset
+	Offset   int64 `json:"offset"`
+	Complete bool  `json:"complete"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+	// the upload is deleted if it isn't continued before this time
+	// swagger:strfmt date-time
+	Expires time.Time `json:"expires_at"`
+}
+
+// CreateAttachmentUploadOptions options for starting a resumable upload of an attachment
+// swagger:model
+type CreateAttachmentUploadOptions struct {
+	// required: true
+	Name string `json:"name" binding:"Required"`
+	// total size of the attachment in bytes
+	// required: true
+	Size int64 `json:"size" binding:"Required"`
 }
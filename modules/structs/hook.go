@@ -34,19 +34,38 @@ type Hook struct {
 // HookList represents a list of API hook.
 type HookList []*Hook
 
+// InheritedHook represents a webhook of an organization receiving the events of its repositories,
+// its configuration is only shown to the organization owners.
+type InheritedHook struct {
+	ID     int64    `json:"id"`
+	Type   string   `json:"type"`
+	Events []string `json:"events"`
+	Active bool     `json:"active"`
+	// whether the repository doesn't send its events to the webhook
+	OptedOut bool `json:"opted_out"`
+}
+
 // CreateHookOptionConfig has all config options in it
 // required are "content_type" and "url" Required
+// "signature_algorithm" can be "sha256" or "sha512" to sign payloads without sending the secret
+// "proxy_url" can be a HTTP(S) or SOCKS5 proxy to deliver the hook through
+// "client_certificate" and "client_key" are the PEM encoded client certificate and private key
+// presented to endpoints requiring mutual TLS, the key is never returned
+// matrix hooks require "homeserver_url", "room_id" and "access_token" instead of "url",
+// "message_type" can be "m.notice" (default) or "m.text" and "plain_text" "true" or "false"
+// msteams hooks accept "card_type", "message_card" (default) or "adaptive_card"
 type CreateHookOptionConfig map[string]string
 
 // CreateHookOption options when create a hook
 type CreateHookOption struct {
 	// required: true
-	// enum: dingtalk,discord,gitea,gogs,msteams,slack,telegram,feishu
+	// enum: dingtalk,discord,gitea,gogs,msteams,slack,telegram,feishu,matrix,custom
 	Type string `json:"type" binding:"Required"`
 	// required: true
 	Config       CreateHookOptionConfig `json:"config" binding:"Required"`
 	Events       []string               `json:"events"`
 	BranchFilter string                 `json:"branch_filter" binding:"GlobPattern"`
+	TagFilter    string                 `json:"tag_filter" binding:"GlobPattern"`
 	// default: false
 	Active bool `json:"active"`
 }
@@ -56,9 +75,59 @@ type EditHookOption struct {
 	Config       map[string]string `json:"config"`
 	Events       []string          `json:"events"`
 	BranchFilter string            `json:"branch_filter" binding:"GlobPattern"`
+	TagFilter    string            `json:"tag_filter" binding:"GlobPattern"`
 	Active       *bool             `json:"active"`
 }
 
+// HookDelivery represents a delivery of a webhook
+type HookDelivery struct {
+	ID    int64  `json:"id"`
+	UUID  string `json:"uuid"`
+	Event string `json:"event"`
+	URL   string `json:"url"`
+	// whether the delivery has been attempted for the last time
+	Delivered bool `json:"delivered"`
+	Succeeded bool `json:"succeeded"`
+	// whether all attempts to deliver the hook have failed
+	DeadLetter bool `json:"dead_letter"`
+	Attempts   int  `json:"attempts"`
+	// duration of the last attempt in seconds
+	Duration float64 `json:"duration"`
+	// swagger:strfmt date-time
+	DeliveredAt *time.Time            `json:"delivered_at"`
+	Request     *HookDeliveryRequest  `json:"request"`
+	Response    *HookDeliveryResponse `json:"response"`
+}
+
+// HookDeliveryList represents a list of deliveries of a webhook
+type HookDeliveryList []*HookDelivery
+
+// HookDeliveryRequest represents the request of a webhook delivery
+type HookDeliveryRequest struct {
+	Headers map[string]string `json:"headers"`
+	Payload string            `json:"payload"`
+}
+
+// HookDeliveryResponse represents the response to a webhook delivery
+type HookDeliveryResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+// PruneHookDeliveriesOption options to delete the old deliveries of all webhooks
+type PruneHookDeliveriesOption struct {
+	// delete the deliveries made more than this duration ago, e.g. "168h"
+	OlderThan string `json:"older_than"`
+	// number of latest deliveries to keep for each webhook
+	NumberToKeep int `json:"number_to_keep"`
+}
+
+// PruneHookDeliveriesResult represents the result of deleting old deliveries
+type PruneHookDeliveriesResult struct {
+	Deleted int64 `json:"deleted"`
+}
+
 // Payloader payload is some part of one hook
 type Payloader interface {
 	SetSecret(string)
@@ -100,6 +169,10 @@ type PayloadCommitVerification struct {
 	Signature string       `json:"signature"`
 	Signer    *PayloadUser `json:"signer"`
 	Payload   string       `json:"payload"`
+	// the trust model of the repository the signer is trusted by: collaborator, committer, any or trustedkeys
+	TrustModel string `json:"trust_model"`
+	// whether the signer of a verified signature is trusted by the trust model: trusted, untrusted or unmatched
+	TrustStatus string `json:"trust_status"`
 }
 
 var (
@@ -268,6 +341,11 @@ const (
 	HookReleasePublished HookReleaseAction = "published"
 	HookReleaseUpdated   HookReleaseAction = "updated"
 	HookReleaseDeleted   HookReleaseAction = "deleted"
+	HookReleaseYanked    HookReleaseAction = "yanked"
+	// An asset has been added to or removed from a published release,
+	// the asset is given in the payload
+	HookReleaseAssetUploaded HookReleaseAction = "asset_uploaded"
+	HookReleaseAssetDeleted  HookReleaseAction = "asset_deleted"
 )
 
 // ReleasePayload represents a payload information of release event.
@@ -275,6 +353,7 @@ type ReleasePayload struct {
 	Secret     string            `json:"secret"`
 	Action     HookReleaseAction `json:"action"`
 	Release    *Release          `json:"release"`
+	Asset      *Attachment       `json:"asset,omitempty"`
 	Repository *Repository       `json:"repository"`
 	Sender     *User             `json:"sender"`
 }
@@ -376,6 +455,10 @@ const (
 	HookIssueDemilestoned HookIssueAction = "demilestoned"
 	// HookIssueReviewed is an issue action for when a pull request is reviewed
 	HookIssueReviewed HookIssueAction = "reviewed"
+	// HookIssueAutoMergeScheduled is a pull request action for when its merge is scheduled for once it is ready
+	HookIssueAutoMergeScheduled HookIssueAction = "auto_merge_scheduled"
+	// HookIssueAutoMergeCancelled is a pull request action for when its scheduled merge is cancelled
+	HookIssueAutoMergeCancelled HookIssueAction = "auto_merge_cancelled"
 )
 
 // IssuePayload represents the payload information that is sent along with an issue event.
@@ -461,6 +544,18 @@ const (
 	HookRepoCreated HookRepoAction = "created"
 	// HookRepoDeleted deleted
 	HookRepoDeleted HookRepoAction = "deleted"
+	// HookRepoRenamed renamed
+	HookRepoRenamed HookRepoAction = "renamed"
+	// HookRepoTransferred transferred
+	HookRepoTransferred HookRepoAction = "transferred"
+	// HookRepoTransferRequested transfer requested, waiting for the new owner to accept it
+	HookRepoTransferRequested HookRepoAction = "transfer_requested"
+	// HookRepoTransferRejected transfer rejected by the new owner or cancelled
+	HookRepoTransferRejected HookRepoAction = "transfer_rejected"
+	// HookRepoArchived archived
+	HookRepoArchived HookRepoAction = "archived"
+	// HookRepoUnarchived unarchived
+	HookRepoUnarchived HookRepoAction = "unarchived"
 )
 
 // RepositoryPayload payload for repository webhooks
@@ -469,7 +564,13 @@ type RepositoryPayload struct {
 	Action       HookRepoAction `json:"action"`
 	Repository   *Repository    `json:"repository"`
 	Organization *User          `json:"organization"`
-	Sender       *User          `json:"sender"`
+	// previous name of a renamed repository
+	OldName string `json:"old_name,omitempty"`
+	// previous owner of a transferred repository
+	OldOwner string `json:"old_owner,omitempty"`
+	// new owner of a repository whose transfer is requested or rejected
+	NewOwner string `json:"new_owner,omitempty"`
+	Sender   *User  `json:"sender"`
 }
 
 // SetSecret modifies the secret of the RepositoryPayload
@@ -481,3 +582,55 @@ func (p *RepositoryPayload) SetSecret(secret string) {
 func (p *RepositoryPayload) JSONPayload() ([]byte, error) {
 	return json.MarshalIndent(p, "", " ")
 }
+
+// HookAccountAction an action that happens to a user or an organization
+type HookAccountAction string
+
+const (
+	// HookAccountCreated created
+	HookAccountCreated HookAccountAction = "created"
+	// HookAccountRenamed renamed
+	HookAccountRenamed HookAccountAction = "renamed"
+	// HookAccountDeleted deleted
+	HookAccountDeleted HookAccountAction = "deleted"
+)
+
+// UserPayload payload for user webhooks, only sent to system webhooks
+type UserPayload struct {
+	Secret string            `json:"secret"`
+	Action HookAccountAction `json:"action"`
+	User   *User             `json:"user"`
+	// previous name of a renamed user
+	OldName string `json:"old_name,omitempty"`
+	Sender  *User  `json:"sender"`
+}
+
+// SetSecret modifies the secret of the UserPayload
+func (p *UserPayload) SetSecret(secret string) {
+	p.Secret = secret
+}
+
+// JSONPayload JSON representation of the payload
+func (p *UserPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", " ")
+}
+
+// OrganizationPayload payload for organization webhooks, only sent to system webhooks
+type OrganizationPayload struct {
+	Secret       string            `json:"secret"`
+	Action       HookAccountAction `json:"action"`
+	Organization *Organization     `json:"organization"`
+	// previous name of a renamed organization
+	OldName string `json:"old_name,omitempty"`
+	Sender  *User  `json:"sender"`
+}
+
+// SetSecret modifies the secret of the OrganizationPayload
+func (p *OrganizationPayload) SetSecret(secret string) {
+	p.Secret = secret
+}
+
+// JSONPayload JSON representation of the payload
+func (p *OrganizationPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", " ")
+}
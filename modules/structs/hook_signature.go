@@ -0,0 +1,73 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// Algorithms that can be used to sign the payload of a webhook.
+// An empty algorithm is the legacy behavior: the secret is sent in the payload
+// and its HMAC-SHA256 hex digest in the X-Gitea-Signature header.
+const (
+	HookSignatureSHA256 = "sha256"
+	HookSignatureSHA512 = "sha512"
+)
+
+var hookSignatureAlgorithms = map[string]struct {
+	header string
+	hash   func() hash.Hash
+}{
+	HookSignatureSHA256: {"X-Gitea-Signature-256", sha256.New},
+	HookSignatureSHA512: {"X-Gitea-Signature-512", sha512.New},
+}
+
+// IsValidHookSignatureAlgorithm returns true if given name is a supported signature algorithm.
+func IsValidHookSignatureAlgorithm(name string) bool {
+	_, ok := hookSignatureAlgorithms[name]
+	return ok
+}
+
+// HookSignatureHeader returns the header the signature of given algorithm is sent in,
+// e.g. X-Gitea-Signature-256.
+func HookSignatureHeader(algorithm string) string {
+	return hookSignatureAlgorithms[algorithm].header
+}
+
+// SignHookPayload returns the signature of payload as "<algorithm>=<hex digest>",
+// the digest being the HMAC of the payload keyed with the secret of the webhook.
+func SignHookPayload(algorithm, secret string, payload []byte) (string, error) {
+	alg, ok := hookSignatureAlgorithms[algorithm]
+	if !ok {
+		return "", fmt.Errorf("unsupported signature algorithm: %s", algorithm)
+	}
+	mac := hmac.New(alg.hash, []byte(secret))
+	if _, err := mac.Write(payload); err != nil {
+		return "", err
+	}
+	return algorithm + "=" + hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// VerifyHookSignature returns true if signature, as received in the header returned by
+// HookSignatureHeader, is valid for the payload and the secret of the webhook.
+// The comparison is done in constant time, receivers should use it rather than
+// comparing the signatures themselves.
+func VerifyHookSignature(secret string, payload []byte, signature string) bool {
+	idx := strings.IndexByte(signature, '=')
+	if idx < 0 {
+		return false
+	}
+	expected, err := SignHookPayload(signature[:idx], secret, payload)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
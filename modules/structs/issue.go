@@ -93,6 +93,19 @@ type CreateIssueOption struct {
 	Closed bool    `json:"closed"`
 }
 
+// CreateIssuesOption options to create several issues at once
+type CreateIssuesOption struct {
+	// required:true
+	Issues []*CreateIssueOption `json:"issues" binding:"Required"`
+}
+
+// CreateIssueResult represents the result of the creation of an issue of a batch, the issue
+// if it is created or the error making it invalid
+type CreateIssueResult struct {
+	Issue *Issue `json:"issue,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
 // EditIssueOption options for editing an issue
 type EditIssueOption struct {
 	Title     string   `json:"title"`
@@ -26,7 +26,8 @@ type MarkdownOption struct {
 	//
 	// in: body
 	Mode string
-	// Context to render
+	// Context to render, the URL or path of a repository
+	// to resolve issue references and relative links against
 	//
 	// in: body
 	Context string
@@ -30,6 +30,8 @@ type PullRequest struct {
 
 	Mergeable bool `json:"mergeable"`
 	HasMerged bool `json:"merged"`
+	// whether the pull request is a draft, which can't be merged until it is marked as ready for review
+	Draft bool `json:"draft"`
 	// swagger:strfmt date-time
 	Merged         *time.Time `json:"merged_at"`
 	MergedCommitID *string    `json:"merge_commit_sha"`
@@ -77,6 +79,8 @@ type CreatePullRequestOption struct {
 	Labels    []int64  `json:"labels"`
 	// swagger:strfmt date-time
 	Deadline *time.Time `json:"due_date"`
+	// create the pull request as a draft, which can't be merged until it is marked as ready for review
+	Draft bool `json:"draft"`
 }
 
 // EditPullRequestOption options when modify pull request
@@ -93,3 +97,30 @@ type EditPullRequestOption struct {
 	Deadline       *time.Time `json:"due_date"`
 	RemoveDeadline *bool      `json:"unset_due_date"`
 }
+
+// PullRequestCodeOwners represents the owners of the files changed by a pull request who still have to approve it
+type PullRequestCodeOwners struct {
+	// whether the protection of the base branch requires the approvals of the code owners to merge the pull request
+	Required bool `json:"required"`
+	// owners, as written in the CODEOWNERS file of the base branch, who haven't approved the pull request yet,
+	// at least one owner of each changed file has to approve it
+	Unapproved []string `json:"unapproved"`
+}
+
+// PullRequestMergeQueueEntry represents a pull request waiting in the merge queue of its base branch
+type PullRequestMergeQueueEntry struct {
+	// position of the pull request in the merge queue, starting at 1
+	Position   int64  `json:"position"`
+	BaseBranch string `json:"base_branch"`
+	// user who added the pull request to the merge queue, who it is merged on behalf of
+	Merger     *User  `json:"merger"`
+	MergeStyle string `json:"merge_style"`
+	// whether the merge of the pull request is being tested
+	Testing bool `json:"testing"`
+	// branch of the base repository the merge is tested on
+	TestBranch string `json:"test_branch"`
+	// tested merge, which is merged into the base branch once its required status checks succeed
+	TestCommitSHA string `json:"test_commit_sha"`
+	// swagger:strfmt date-time
+	Added time.Time `json:"added_at"`
+}
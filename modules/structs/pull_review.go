@@ -66,6 +66,21 @@ type PullReviewComment struct {
 	HTMLPullURL string `json:"pull_request_url"`
 }
 
+// PullReviewThread represents a conversation of review comments on a line of a pull request,
+// it is resolved when its first comment is
+type PullReviewThread struct {
+	// the id of the first comment of the thread
+	ID         int64  `json:"id"`
+	Path       string `json:"path"`
+	LineNum    uint64 `json:"position"`
+	OldLineNum uint64 `json:"original_position"`
+	// whether the lines of the thread were changed since it was started
+	Outdated   bool                 `json:"outdated"`
+	Resolved   bool                 `json:"resolved"`
+	ResolvedBy *User                `json:"resolved_by"`
+	Comments   []*PullReviewComment `json:"comments"`
+}
+
 // CreatePullReviewOptions are options to create a pull review
 type CreatePullReviewOptions struct {
 	Event    ReviewStateType           `json:"event"`
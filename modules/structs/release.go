@@ -21,23 +21,48 @@ type Release struct {
 	ZipURL       string `json:"zipball_url"`
 	IsDraft      bool   `json:"draft"`
 	IsPrerelease bool   `json:"prerelease"`
+	// whether the release has been withdrawn, its tag and assets are kept
+	IsYanked bool `json:"yanked"`
+	// the reason the release has been yanked for
+	YankedReason string `json:"yanked_reason,omitempty"`
 	// swagger:strfmt date-time
 	CreatedAt time.Time `json:"created_at"`
 	// swagger:strfmt date-time
 	PublishedAt time.Time     `json:"published_at"`
 	Publisher   *User         `json:"author"`
 	Attachments []*Attachment `json:"assets"`
+	// Assets hosted outside of Gitea
+	ExternalAssets []*ReleaseExternalAsset `json:"external_assets"`
+	// Source code archives of the release tag, they are generated when the release is published
+	SourceArchives []*ReleaseSourceArchive `json:"source_archives"`
+	// Signatures and provenance of the release
+	Verification *ReleaseVerification `json:"verification,omitempty"`
+}
+
+// ReleaseVerification represents the signing and provenance metadata of a release
+type ReleaseVerification struct {
+	// verification of the signature of the release tag, it is not returned when listing releases
+	Tag *PayloadCommitVerification `json:"tag,omitempty"`
+	// detached signatures of the release assets
+	Signatures []*Attachment `json:"signatures"`
+	// certificates of the keys the assets are signed with
+	Certificates []*Attachment `json:"certificates"`
+	// provenance attestations of the release assets, e.g. SLSA provenance
+	Provenance []*Attachment `json:"provenance"`
 }
 
 // CreateReleaseOption options when creating a release
 type CreateReleaseOption struct {
 	// required: true
-	TagName      string `json:"tag_name" binding:"Required"`
-	Target       string `json:"target_commitish"`
-	Title        string `json:"name"`
+	TagName string `json:"tag_name" binding:"Required"`
+	Target  string `json:"target_commitish"`
+	Title   string `json:"name"`
+	// defaults to the release template of the repository if empty
 	Note         string `json:"body"`
 	IsDraft      bool   `json:"draft"`
 	IsPrerelease bool   `json:"prerelease"`
+	// Assets hosted outside of Gitea
+	ExternalAssets []*ReleaseExternalAssetOption `json:"external_assets"`
 }
 
 // EditReleaseOption options when editing a release
@@ -48,4 +73,93 @@ type EditReleaseOption struct {
 	Note         string `json:"body"`
 	IsDraft      *bool  `json:"draft"`
 	IsPrerelease *bool  `json:"prerelease"`
+	// Replaces the assets hosted outside of Gitea if set
+	ExternalAssets []*ReleaseExternalAssetOption `json:"external_assets"`
+}
+
+// YankReleaseOption options when yanking a release
+type YankReleaseOption struct {
+	// required: true
+	Reason string `json:"reason" binding:"Required"`
+}
+
+// ReleaseExternalAsset represents a release asset hosted outside of Gitea
+type ReleaseExternalAsset struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	// swagger:strfmt date-time
+	Created     time.Time `json:"created_at"`
+	DownloadURL string    `json:"browser_download_url"`
+}
+
+// ReleaseSourceArchive represents a source code archive of a release
+type ReleaseSourceArchive struct {
+	Name string `json:"name"`
+	// size of the archive, 0 if it isn't generated yet
+	Size        int64  `json:"size"`
+	DownloadURL string `json:"browser_download_url"`
+}
+
+// ReleaseExternalAssetOption options for a release asset hosted outside of Gitea
+type ReleaseExternalAssetOption struct {
+	// required: true
+	Name string `json:"name" binding:"Required"`
+	// required: true
+	DownloadURL string `json:"browser_download_url" binding:"Required;ValidUrl"`
+	Size        int64  `json:"size"`
+}
+
+// ReleaseChangelog represents the changes between two releases
+type ReleaseChangelog struct {
+	Base         *Release              `json:"base"`
+	Head         *Release              `json:"head"`
+	Commits      []*Commit             `json:"commits"`
+	PullRequests []*PullRequest        `json:"pull_requests"`
+	Contributors []*ReleaseContributor `json:"contributors"`
+}
+
+// ReleaseContributor represents an author of the commits between two releases
+type ReleaseContributor struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	// the user of the email, null if there is none
+	User    *User `json:"user"`
+	Commits int   `json:"commits"`
+}
+
+// ReleasesJob represents a background job on the releases of a repository
+type ReleasesJob struct {
+	ID int64 `json:"id"`
+	// the status of the job, one of queued, running, stopped, failed or finished
+	Status string `json:"status"`
+	// the errors of the job if it has failed
+	Errors string `json:"errors,omitempty"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// ReleaseComment represents a comment on a release
+type ReleaseComment struct {
+	ID        int64  `json:"id"`
+	HTMLURL   string `json:"html_url"`
+	ReleaseID int64  `json:"release_id"`
+	Poster    *User  `json:"user"`
+	Body      string `json:"body"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CreateReleaseCommentOption options for creating a comment on a release
+type CreateReleaseCommentOption struct {
+	// required:true
+	Body string `json:"body" binding:"Required"`
+}
+
+// EditReleaseCommentOption options for editing a comment on a release
+type EditReleaseCommentOption struct {
+	// required: true
+	Body string `json:"body" binding:"Required"`
 }
@@ -70,7 +70,13 @@ type Repository struct {
 	OpenPulls     int         `json:"open_pr_counter"`
 	Releases      int         `json:"release_counter"`
 	DefaultBranch string      `json:"default_branch"`
-	Archived      bool        `json:"archived"`
+	// ObjectFormatName is the hash algorithm of the objects of the repository
+	// enum: sha1,sha256
+	ObjectFormatName string `json:"object_format_name"`
+	// TrustModel is the way verified commit signatures are trusted in the repository
+	// enum: collaborator,committer,any,trustedkeys
+	TrustModel string `json:"trust_model"`
+	Archived   bool   `json:"archived"`
 	// swagger:strfmt date-time
 	Created time.Time `json:"created_at"`
 	// swagger:strfmt date-time
@@ -87,8 +93,12 @@ type Repository struct {
 	AllowRebase               bool             `json:"allow_rebase"`
 	AllowRebaseMerge          bool             `json:"allow_rebase_explicit"`
 	AllowSquash               bool             `json:"allow_squash_merge"`
+	AllowRebaseFastForward    bool             `json:"allow_rebase_fast_forward"`
+	AllowFastForwardOnly      bool             `json:"allow_fast_forward_only"`
+	SquashMessageTemplate     string           `json:"squash_message_template"`
 	AvatarURL                 string           `json:"avatar_url"`
 	Internal                  bool             `json:"internal"`
+	Topics                    []string         `json:"topics"`
 }
 
 // CreateRepoOption options when creating repository
@@ -115,6 +125,9 @@ type CreateRepoOption struct {
 	Readme string `json:"readme"`
 	// DefaultBranch of the repository (used when initializes and in template)
 	DefaultBranch string `json:"default_branch" binding:"GitRefName;MaxSize(100)"`
+	// ObjectFormatName of the repository, sha1 if it is empty
+	// enum: sha1
+	ObjectFormatName string `json:"object_format_name" binding:"In(,sha1,sha256)"`
 }
 
 // EditRepoOption options when editing a repository's properties
@@ -157,8 +170,21 @@ type EditRepoOption struct {
 	AllowRebaseMerge *bool `json:"allow_rebase_explicit,omitempty"`
 	// either `true` to allow squash-merging pull requests, or `false` to prevent squash-merging. `has_pull_requests` must be `true`.
 	AllowSquash *bool `json:"allow_squash_merge,omitempty"`
+	// either `true` to allow fast-forwarding pull requests, rebasing them first if needed, or `false` to prevent it. `has_pull_requests` must be `true`.
+	AllowRebaseFastForward *bool `json:"allow_rebase_fast_forward,omitempty"`
+	// either `true` to allow fast-forwarding pull requests without rebasing them, or `false` to prevent it. `has_pull_requests` must be `true`.
+	AllowFastForwardOnly *bool `json:"allow_fast_forward_only,omitempty"`
+	// template of the messages the pull requests are squashed with by default, whose first line is the title. Its placeholders are
+	// `${PullRequestTitle}`, `${PullRequestIndex}`, `${PullRequestReference}`, `${PullRequestDescription}`, `${PullRequestURL}`,
+	// `${BaseBranch}`, `${HeadBranch}`, `${CommitMessages}`, `${CoAuthors}` and `${Approvers}`. `has_pull_requests` must be `true`.
+	SquashMessageTemplate *string `json:"squash_message_template,omitempty"`
 	// set to `true` to archive this repository.
 	Archived *bool `json:"archived,omitempty"`
+	// the way verified commit signatures are trusted, `default` to use the trust model of the instance.
+	// enum: default,collaborator,committer,any,trustedkeys
+	TrustModel *string `json:"trust_model,omitempty"`
+	// the key IDs or fingerprints trusted by the `trustedkeys` trust model, separated by commas or whitespace.
+	TrustedKeys *string `json:"trusted_keys,omitempty"`
 }
 
 // CreateBranchRepoOption options when creating a branch in a repository
@@ -186,6 +212,15 @@ type TransferRepoOption struct {
 	TeamIDs *[]int64 `json:"team_ids"`
 }
 
+// RepoTransfer represents a pending transfer of a repository, waiting for the new owner to accept it
+type RepoTransfer struct {
+	Doer      *User   `json:"doer"`
+	Recipient *User   `json:"recipient"`
+	Teams     []*Team `json:"teams"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
 // GitServiceType represents a git service
 type GitServiceType int
 
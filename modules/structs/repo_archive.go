@@ -0,0 +1,13 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// RepoArchive represents the generation of an archive of a repository
+type RepoArchive struct {
+	// the status of the generation, pending or complete
+	Status string `json:"status"`
+	// the URL the archive is downloaded from
+	DownloadURL string `json:"download_url"`
+}
@@ -0,0 +1,26 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// BlameCommit contains information of the commit which last changed a range of lines
+type BlameCommit struct {
+	*CommitMeta
+	HTMLURL   string      `json:"html_url"`
+	Author    *CommitUser `json:"author"`
+	Committer *CommitUser `json:"committer"`
+	Summary   string      `json:"summary"`
+	// Age is the number of seconds since the commit was authored
+	Age int64 `json:"age"`
+}
+
+// BlameRange represents consecutive lines of a file last changed by the same commit
+type BlameRange struct {
+	// StartLine is the number of the first line of the range, starting at 1
+	StartLine int `json:"start_line"`
+	// EndLine is the number of the last line of the range
+	EndLine int          `json:"end_line"`
+	Lines   []string     `json:"lines"`
+	Commit  *BlameCommit `json:"commit"`
+}
@@ -21,6 +21,16 @@ type Branch struct {
 	EffectiveBranchProtectionName string         `json:"effective_branch_protection_name"`
 }
 
+// DeletedBranch represents a deleted branch of a repository, which can be restored
+type DeletedBranch struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	CommitID  string `json:"commit_id"`
+	DeletedBy *User  `json:"deleted_by"`
+	// swagger:strfmt date-time
+	Deleted time.Time `json:"deleted_at"`
+}
+
 // BranchProtection represents a branch protection for a repository
 type BranchProtection struct {
 	BranchName                  string   `json:"branch_name"`
@@ -43,6 +53,8 @@ type BranchProtection struct {
 	DismissStaleApprovals       bool     `json:"dismiss_stale_approvals"`
 	RequireSignedCommits        bool     `json:"require_signed_commits"`
 	ProtectedFilePatterns       string   `json:"protected_file_patterns"`
+	EnableMergeQueue            bool     `json:"enable_merge_queue"`
+	RequireCodeOwnerReviews     bool     `json:"require_code_owner_reviews"`
 	// swagger:strfmt date-time
 	Created time.Time `json:"created_at"`
 	// swagger:strfmt date-time
@@ -71,6 +83,8 @@ type CreateBranchProtectionOption struct {
 	DismissStaleApprovals       bool     `json:"dismiss_stale_approvals"`
 	RequireSignedCommits        bool     `json:"require_signed_commits"`
 	ProtectedFilePatterns       string   `json:"protected_file_patterns"`
+	EnableMergeQueue            bool     `json:"enable_merge_queue"`
+	RequireCodeOwnerReviews     bool     `json:"require_code_owner_reviews"`
 }
 
 // EditBranchProtectionOption options for editing a branch protection
@@ -94,4 +108,6 @@ type EditBranchProtectionOption struct {
 	DismissStaleApprovals       *bool    `json:"dismiss_stale_approvals"`
 	RequireSignedCommits        *bool    `json:"require_signed_commits"`
 	ProtectedFilePatterns       *string  `json:"protected_file_patterns"`
+	EnableMergeQueue            *bool    `json:"enable_merge_queue"`
+	RequireCodeOwnerReviews     *bool    `json:"require_code_owner_reviews"`
 }
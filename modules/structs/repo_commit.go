@@ -35,6 +35,8 @@ type RepoCommit struct {
 	Committer *CommitUser `json:"committer"`
 	Message   string      `json:"message"`
 	Tree      *CommitMeta `json:"tree"`
+	// the verification of the signature of the commit
+	Verification *PayloadCommitVerification `json:"verification"`
 }
 
 // Commit contains information generated from a Git commit.
@@ -54,3 +56,30 @@ type CommitDateOptions struct {
 	// swagger:strfmt date-time
 	Committer time.Time `json:"committer"`
 }
+
+// CherryPickCommitOption options for cherry-picking or reverting a commit onto a branch
+type CherryPickCommitOption struct {
+	// sha of the commit to apply
+	// required: true
+	SHA string `json:"sha" binding:"Required;MaxSize(40)"`
+	// branch to apply the commit onto, the default branch if empty
+	Branch string `json:"branch" binding:"GitRefName;MaxSize(100)"`
+	// branch to create from `branch` with the new commit instead of updating `branch`
+	NewBranch string `json:"new_branch" binding:"GitRefName;MaxSize(100)"`
+	// message of the new commit, generated from the applied commit if empty
+	Message string `json:"message"`
+	// on conflicts, commit the conflict markers to a new branch and open a
+	// pull request into `branch` to resolve them instead of failing
+	PullOnConflict bool `json:"pull_on_conflict"`
+}
+
+// CherryPickCommitResponse contains the commit created by cherry-picking or reverting a commit
+type CherryPickCommitResponse struct {
+	Commit *Commit `json:"commit"`
+	// branch the commit was pushed to
+	Branch string `json:"branch"`
+	// files committed with conflict markers
+	Conflicts []string `json:"conflicts"`
+	// pull request opened to resolve the conflicts
+	PullRequest *PullRequest `json:"pull_request"`
+}
@@ -67,6 +67,9 @@ type ContentsResponse struct {
 	Encoding *string `json:"encoding"`
 	// `content` is populated when `type` is `file`, otherwise null
 	Content *string `json:"content"`
+	// `lfs` is true when `type` is `file` and the file is a pointer to an LFS object,
+	// whose content is downloaded from the `download_url`
+	LFS bool `json:"lfs"`
 	// `target` is populated when `type` is `symlink`, otherwise null
 	Target      *string `json:"target"`
 	URL         *string `json:"url"`
@@ -0,0 +1,15 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// LanguageShare represents the share of a language in the code of a repository,
+// as shown in the language bar of the repository
+type LanguageShare struct {
+	// name of the language, "other" for the remaining languages
+	Language   string  `json:"language"`
+	Percentage float32 `json:"percentage"`
+	// color of the language in the language bar
+	Color string `json:"color"`
+}
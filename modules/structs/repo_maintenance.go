@@ -0,0 +1,38 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// RepoGarbageCollection represents a background job running git gc on a repository
+type RepoGarbageCollection struct {
+	ID int64 `json:"id"`
+	// the full name of the repository
+	Repository string `json:"repository"`
+	// the status of the job, one of queued, running, stopped, failed or finished
+	Status string `json:"status"`
+	// the errors of the job if it has failed
+	Errors string `json:"errors,omitempty"`
+	// the size of the repository in bytes before the garbage collection, set once the job has finished
+	SizeBefore int64 `json:"size_before"`
+	// the size of the repository in bytes after the garbage collection, set once the job has finished
+	SizeAfter int64 `json:"size_after"`
+	// the number of loose objects before the garbage collection, set once the job has finished
+	LooseObjectsBefore int64 `json:"loose_objects_before"`
+	// the number of loose objects after the garbage collection, set once the job has finished
+	LooseObjectsAfter int64 `json:"loose_objects_after"`
+	// the number of packs before the garbage collection, set once the job has finished
+	PacksBefore int64 `json:"packs_before"`
+	// the number of packs after the garbage collection, set once the job has finished
+	PacksAfter int64 `json:"packs_after"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Started *time.Time `json:"started_at"`
+	// swagger:strfmt date-time
+	Finished *time.Time `json:"finished_at"`
+}
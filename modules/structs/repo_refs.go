@@ -17,3 +17,10 @@ type GitObject struct {
 	SHA  string `json:"sha"`
 	URL  string `json:"url"`
 }
+
+// CreateGitRefOption options for creating a branch or lightweight tag
+type CreateGitRefOption struct {
+	// sha of the commit the ref points to
+	// required: true
+	SHA string `json:"sha" binding:"Required;MaxSize(40)"`
+}
@@ -0,0 +1,30 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Stargazer represents a user who starred a repository
+type Stargazer struct {
+	*User
+	// the time the user starred the repository, unknown for the older stars
+	// swagger:strfmt date-time
+	StarredAt *time.Time `json:"starred_at,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface for Stargazer, keeping the fields of
+// the user at the top level
+func (s Stargazer) MarshalJSON() ([]byte, error) {
+	// Re-declaring User to avoid using its MarshalJSON
+	type shadow User
+	return json.Marshal(struct {
+		*shadow
+		CompatUserName string     `json:"username"`
+		StarredAt      *time.Time `json:"starred_at,omitempty"`
+	}{(*shadow)(s.User), s.User.UserName, s.StarredAt})
+}
@@ -0,0 +1,24 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// Submodule represents a submodule of a repository at a commit
+type Submodule struct {
+	// the name of the submodule in .gitmodules
+	Name string `json:"name"`
+	// the path of the submodule in the repository
+	Path string `json:"path"`
+	// the URL of the submodule in .gitmodules
+	URL    string `json:"url"`
+	Branch string `json:"branch"`
+	// the commit of the submodule repository the submodule is pinned at
+	SHA string `json:"sha"`
+	// the web link of the submodule repository, empty if it can't be guessed from its URL
+	HTMLURL string `json:"html_url"`
+	// the web link of the commit the submodule is pinned at, empty if it can't be guessed from its URL
+	CommitURL string `json:"commit_url"`
+	// the repository of this instance the submodule refers to, null if there is none or it can't be read
+	Repository *Repository `json:"repository"`
+}
@@ -5,6 +5,7 @@
 package structs
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -17,3 +18,23 @@ type WatchInfo struct {
 	URL           string      `json:"url"`
 	RepositoryURL string      `json:"repository_url"`
 }
+
+// Watcher represents a user watching a repository
+type Watcher struct {
+	*User
+	// the time the user started watching the repository, unknown for the older watches
+	// swagger:strfmt date-time
+	WatchedAt *time.Time `json:"watched_at,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface for Watcher, keeping the fields of
+// the user at the top level
+func (w Watcher) MarshalJSON() ([]byte, error) {
+	// Re-declaring User to avoid using its MarshalJSON
+	type shadow User
+	return json.Marshal(struct {
+		*shadow
+		CompatUserName string     `json:"username"`
+		WatchedAt      *time.Time `json:"watched_at,omitempty"`
+	}{(*shadow)(w.User), w.User.UserName, w.WatchedAt})
+}
@@ -9,7 +9,9 @@ type TaskType int
 
 // all kinds of task types
 const (
-	TaskTypeMigrateRepo TaskType = iota // migrate repository from external or local disk
+	TaskTypeMigrateRepo    TaskType = iota // migrate repository from external or local disk
+	TaskTypeDeleteReleases                 // delete the releases of a repository matching a filter
+	TaskTypeGarbageCollect                 // run git gc on a repository
 )
 
 // Name returns the task type name
@@ -17,6 +19,10 @@ func (taskType TaskType) Name() string {
 	switch taskType {
 	case TaskTypeMigrateRepo:
 		return "Migrate Repository"
+	case TaskTypeDeleteReleases:
+		return "Delete Releases"
+	case TaskTypeGarbageCollect:
+		return "Garbage Collect Repository"
 	}
 	return ""
 }
@@ -32,3 +38,20 @@ const (
 	TaskStatusFailed                     // 3 task is failed
 	TaskStatusFinished                   // 4 task is finished
 )
+
+// Name returns the task status name
+func (status TaskStatus) Name() string {
+	switch status {
+	case TaskStatusQueue:
+		return "queued"
+	case TaskStatusRunning:
+		return "running"
+	case TaskStatusStopped:
+		return "stopped"
+	case TaskStatusFailed:
+		return "failed"
+	case TaskStatusFinished:
+		return "finished"
+	}
+	return ""
+}
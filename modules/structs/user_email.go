@@ -23,3 +23,10 @@ type DeleteEmailOption struct {
 	// email addresses to delete
 	Emails []string `json:"emails"`
 }
+
+// UserEmailOption options when verifying an email address of a user or making it primary
+type UserEmailOption struct {
+	// required: true
+	// swagger:strfmt email
+	Email string `json:"email" binding:"Required;Email;MaxSize(254)"`
+}
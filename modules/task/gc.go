@@ -0,0 +1,131 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package task
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	repo_module "code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// GarbageCollectResult holds the sizes and objects of a repository around a garbage collection task
+type GarbageCollectResult struct {
+	SizeBefore         int64
+	SizeAfter          int64
+	LooseObjectsBefore int64
+	LooseObjectsAfter  int64
+	PacksBefore        int64
+	PacksAfter         int64
+}
+
+// GarbageCollect adds a task running git gc on the repository, it returns the queued or running one if any
+func GarbageCollect(doer *models.User, repo *models.Repository) (*models.Task, error) {
+	task, err := models.GetLatestRepositoryTask(repo.ID, structs.TaskTypeGarbageCollect)
+	if err == nil && (task.Status == structs.TaskStatusQueue || task.Status == structs.TaskStatusRunning) {
+		return task, nil
+	} else if err != nil && !models.IsErrTaskDoesNotExist(err) {
+		return nil, err
+	}
+
+	task = &models.Task{
+		DoerID:  doer.ID,
+		OwnerID: repo.OwnerID,
+		RepoID:  repo.ID,
+		Type:    structs.TaskTypeGarbageCollect,
+		Status:  structs.TaskStatusQueue,
+	}
+	if err := models.CreateTask(task); err != nil {
+		return nil, err
+	}
+
+	return task, taskQueue.Push(task)
+}
+
+// GetGarbageCollectResult returns the result of a finished garbage collection task
+func GetGarbageCollectResult(t *models.Task) (*GarbageCollectResult, error) {
+	var result GarbageCollectResult
+	if t.PayloadContent == "" {
+		return &result, nil
+	}
+	if err := json.Unmarshal([]byte(t.PayloadContent), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func runGarbageCollectTask(t *models.Task) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			var buf bytes.Buffer
+			fmt.Fprintf(&buf, "Handler crashed with error: %v", log.Stack(2))
+
+			err = errors.New(buf.String())
+		}
+
+		t.EndTime = timeutil.TimeStampNow()
+		if err != nil {
+			t.Status = structs.TaskStatusFailed
+			t.Errors = err.Error()
+		} else {
+			t.Status = structs.TaskStatusFinished
+		}
+		if err := t.UpdateCols("status", "errors", "end_time", "payload_content"); err != nil {
+			log.Error("Task UpdateCols failed: %s", err.Error())
+		}
+	}()
+
+	if err := t.LoadRepo(); err != nil {
+		return err
+	}
+	t.StartTime = timeutil.TimeStampNow()
+	t.Status = structs.TaskStatusRunning
+	if err := t.UpdateCols("start_time", "status"); err != nil {
+		return err
+	}
+
+	var result GarbageCollectResult
+	if err := t.Repo.UpdateSize(models.DefaultDBContext()); err != nil {
+		return err
+	}
+	result.SizeBefore = t.Repo.Size
+	objects, err := git.CountObjects(t.Repo.RepoPath())
+	if err != nil {
+		return err
+	}
+	result.LooseObjectsBefore, result.PacksBefore = objects.Count, objects.Packs
+
+	timeout := time.Duration(setting.Git.Timeout.GC) * time.Second
+	if err := repo_module.GitGcRepo(graceful.GetManager().ShutdownContext(), t.Repo, timeout, setting.Git.GCArgs...); err != nil {
+		return err
+	}
+
+	if err := t.Repo.UpdateSize(models.DefaultDBContext()); err != nil {
+		return err
+	}
+	result.SizeAfter = t.Repo.Size
+	objects, err = git.CountObjects(t.Repo.RepoPath())
+	if err != nil {
+		return err
+	}
+	result.LooseObjectsAfter, result.PacksAfter = objects.Count, objects.Packs
+
+	bs, err := json.Marshal(&result)
+	if err != nil {
+		return err
+	}
+	t.PayloadContent = string(bs)
+	return nil
+}
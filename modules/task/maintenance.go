@@ -0,0 +1,105 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package task
+
+import (
+	"context"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// MaintenanceOptions holds the thresholds from which a repository is due for maintenance
+type MaintenanceOptions struct {
+	// Repositories of at least MinSize bytes are maintained at least every Interval
+	Interval time.Duration
+	MinSize  int64
+	// Repositories with at least as many loose objects or packs are maintained, 0 disables the check
+	LooseObjects int64
+	Packs        int64
+	// Force maintains all repositories regardless of the thresholds
+	Force bool
+}
+
+// ScheduleMaintenance adds a garbage collection task for every repository which is due for maintenance
+func ScheduleMaintenance(ctx context.Context, doer *models.User, opts MaintenanceOptions) error {
+	log.Trace("Doing: ScheduleMaintenance")
+
+	var scheduled int
+	if err := models.Iterate(
+		models.DefaultDBContext(),
+		new(models.Repository),
+		builder.Gt{"id": 0},
+		func(idx int, bean interface{}) error {
+			repo := bean.(*models.Repository)
+			select {
+			case <-ctx.Done():
+				return models.ErrCancelledf("before scheduling the maintenance of %s", repo.FullName())
+			default:
+			}
+			due, err := isDueForMaintenance(repo, opts)
+			if err != nil {
+				// A broken repository shouldn't keep the others from being maintained
+				log.Error("Unable to check whether %s is due for maintenance: %v", repo.FullName(), err)
+				return nil
+			}
+			if !due {
+				return nil
+			}
+			if _, err := GarbageCollect(doer, repo); err != nil {
+				return err
+			}
+			scheduled++
+			return nil
+		},
+	); err != nil {
+		return err
+	}
+
+	log.Trace("Finished: ScheduleMaintenance: %d repositories scheduled", scheduled)
+	return nil
+}
+
+func isDueForMaintenance(repo *models.Repository, opts MaintenanceOptions) (bool, error) {
+	if repo.IsEmpty || repo.Status != models.RepositoryReady {
+		return false, nil
+	}
+
+	var lastMaintained timeutil.TimeStamp
+	t, err := models.GetLatestRepositoryTask(repo.ID, structs.TaskTypeGarbageCollect)
+	if err == nil {
+		switch t.Status {
+		case structs.TaskStatusQueue, structs.TaskStatusRunning:
+			return false, nil
+		case structs.TaskStatusFinished:
+			lastMaintained = t.EndTime
+		}
+	} else if !models.IsErrTaskDoesNotExist(err) {
+		return false, err
+	}
+
+	if opts.Force {
+		return true, nil
+	}
+	if opts.Interval > 0 && repo.Size >= opts.MinSize && lastMaintained.AsTime().Add(opts.Interval).Before(time.Now()) {
+		return true, nil
+	}
+	if opts.LooseObjects <= 0 && opts.Packs <= 0 {
+		return false, nil
+	}
+
+	objects, err := git.CountObjects(repo.RepoPath())
+	if err != nil {
+		return false, err
+	}
+	return (opts.LooseObjects > 0 && objects.Count >= opts.LooseObjects) ||
+		(opts.Packs > 0 && objects.Packs >= opts.Packs), nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package task
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+	release_service "code.gitea.io/gitea/services/release"
+
+	"github.com/gobwas/glob"
+)
+
+// DeleteReleasesOptions describes the releases deleted by a task, only the releases matching all the set conditions are deleted
+type DeleteReleasesOptions struct {
+	DraftOnly     bool
+	CreatedBefore timeutil.TimeStamp
+	// TagPattern is a glob the tag names have to match
+	TagPattern string
+}
+
+// DeleteReleases adds a task deleting the releases of the repository matching the options
+func DeleteReleases(doer *models.User, repo *models.Repository, opts DeleteReleasesOptions) (*models.Task, error) {
+	bs, err := json.Marshal(&opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var task = models.Task{
+		DoerID:         doer.ID,
+		OwnerID:        repo.OwnerID,
+		RepoID:         repo.ID,
+		Type:           structs.TaskTypeDeleteReleases,
+		Status:         structs.TaskStatusQueue,
+		PayloadContent: string(bs),
+	}
+	if err := models.CreateTask(&task); err != nil {
+		return nil, err
+	}
+
+	return &task, taskQueue.Push(&task)
+}
+
+func runDeleteReleasesTask(t *models.Task) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			var buf bytes.Buffer
+			fmt.Fprintf(&buf, "Handler crashed with error: %v", log.Stack(2))
+
+			err = errors.New(buf.String())
+		}
+
+		t.EndTime = timeutil.TimeStampNow()
+		if err != nil {
+			t.Status = structs.TaskStatusFailed
+			t.Errors = err.Error()
+		} else {
+			t.Status = structs.TaskStatusFinished
+		}
+		if err := t.UpdateCols("status", "errors", "end_time"); err != nil {
+			log.Error("Task UpdateCols failed: %s", err.Error())
+		}
+	}()
+
+	if err := t.LoadDoer(); err != nil {
+		return err
+	}
+	t.StartTime = timeutil.TimeStampNow()
+	t.Status = structs.TaskStatusRunning
+	if err := t.UpdateCols("start_time", "status"); err != nil {
+		return err
+	}
+
+	var opts DeleteReleasesOptions
+	if err := json.Unmarshal([]byte(t.PayloadContent), &opts); err != nil {
+		return err
+	}
+
+	var tagGlob glob.Glob
+	if opts.TagPattern != "" {
+		if tagGlob, err = glob.Compile(opts.TagPattern); err != nil {
+			return err
+		}
+	}
+
+	findOpts := models.FindReleasesOptions{
+		IncludeDrafts: true,
+		CreatedBefore: opts.CreatedBefore,
+	}
+	if opts.DraftOnly {
+		findOpts.IsDraft = util.OptionalBoolTrue
+	}
+	rels, err := models.GetReleasesByRepoID(t.RepoID, findOpts)
+	if err != nil {
+		return fmt.Errorf("GetReleasesByRepoID: %v", err)
+	}
+
+	// A release which can't be deleted doesn't stop the task, its error is reported at the end
+	var failures []string
+	for _, rel := range rels {
+		if tagGlob != nil && !tagGlob.Match(rel.TagName) {
+			continue
+		}
+		if err := release_service.DeleteReleaseByID(rel.ID, t.Doer, false); err != nil {
+			log.Error("DeleteReleaseByID [%d]: %v", rel.ID, err)
+			failures = append(failures, fmt.Sprintf("%s: %v", rel.TagName, err))
+		}
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "\n"))
+	}
+	return nil
+}
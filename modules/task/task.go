@@ -26,6 +26,10 @@ func Run(t *models.Task) error {
 	switch t.Type {
 	case structs.TaskTypeMigrateRepo:
 		return runMigrateTask(t)
+	case structs.TaskTypeDeleteReleases:
+		return runDeleteReleasesTask(t)
+	case structs.TaskTypeGarbageCollect:
+		return runGarbageCollectTask(t)
 	default:
 		return fmt.Errorf("Unknown task type: %d", t.Type)
 	}
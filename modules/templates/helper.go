@@ -581,10 +581,12 @@ func ActionIcon(opType models.ActionType) string {
 		return "issue-opened"
 	case models.ActionCreatePullRequest:
 		return "git-pull-request"
-	case models.ActionCommentIssue, models.ActionCommentPull:
+	case models.ActionCommentIssue, models.ActionCommentPull, models.ActionCommentRelease:
 		return "comment-discussion"
 	case models.ActionMergePullRequest:
 		return "git-merge"
+	case models.ActionPublishRelease:
+		return "tag"
 	case models.ActionCloseIssue, models.ActionClosePullRequest:
 		return "issue-closed"
 	case models.ActionReopenIssue, models.ActionReopenPullRequest:
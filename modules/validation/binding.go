@@ -52,6 +52,7 @@ func CheckGitRefAdditionalRulesValid(name string) bool {
 func AddBindingRules() {
 	addGitRefNameBindingRule()
 	addValidURLBindingRule()
+	addValidProxyURLBindingRule()
 	addGlobPatternRule()
 }
 
@@ -97,6 +98,24 @@ func addValidURLBindingRule() {
 	})
 }
 
+func addValidProxyURLBindingRule() {
+	// Proxy URL validation rule
+	binding.AddRule(&binding.Rule{
+		IsMatch: func(rule string) bool {
+			return rule == "ValidProxyUrl"
+		},
+		IsValid: func(errs binding.Errors, name string, val interface{}) (bool, binding.Errors) {
+			str := fmt.Sprintf("%v", val)
+			if len(str) != 0 && !IsValidProxyURL(str) {
+				errs.Add([]string{name}, binding.ERR_URL, "Url")
+				return false, errs
+			}
+
+			return true, errs
+		},
+	})
+}
+
 func addGlobPatternRule() {
 	binding.AddRule(&binding.Rule{
 		IsMatch: func(rule string) bool {
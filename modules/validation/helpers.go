@@ -52,6 +52,17 @@ func IsValidURL(uri string) bool {
 	return true
 }
 
+// IsValidProxyURL checks if URL is a valid HTTP(S) or SOCKS5 proxy URL
+func IsValidProxyURL(uri string) bool {
+	if u, err := url.ParseRequestURI(uri); err != nil ||
+		(u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") ||
+		len(u.Hostname()) == 0 || !validPort(portOnly(u.Host)) {
+		return false
+	}
+
+	return true
+}
+
 // IsAPIURL checks if URL is current Gitea instance API URL
 func IsAPIURL(uri string) bool {
 	return strings.HasPrefix(strings.ToLower(uri), strings.ToLower(setting.AppURL+"api"))
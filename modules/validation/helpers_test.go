@@ -47,6 +47,41 @@ func Test_IsValidURL(t *testing.T) {
 	}
 }
 
+func Test_IsValidProxyURL(t *testing.T) {
+	cases := []struct {
+		description string
+		url         string
+		valid       bool
+	}{
+		{
+			description: "HTTP proxy",
+			url:         "http://proxy.lan:3128",
+			valid:       true,
+		},
+		{
+			description: "SOCKS5 proxy with credentials",
+			url:         "socks5://user:password@[::1]:1080",
+			valid:       true,
+		},
+		{
+			description: "Unsupported scheme",
+			url:         "ftp://proxy.lan",
+			valid:       false,
+		},
+		{
+			description: "Missing host",
+			url:         "http:///",
+			valid:       false,
+		},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.description, func(t *testing.T) {
+			assert.Equal(t, testCase.valid, IsValidProxyURL(testCase.url))
+		})
+	}
+}
+
 func Test_IsValidExternalURL(t *testing.T) {
 	setting.AppURL = "https://try.gitea.io/"
 
@@ -0,0 +1,93 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// clientCertClient is the client presenting the client certificate of a webhook,
+// it is kept to reuse its connections until the certificate changes.
+type clientCertClient struct {
+	certificate string
+	client      *http.Client
+}
+
+var (
+	clientCertClientsLock sync.Mutex
+	clientCertClients     = make(map[int64]*clientCertClient)
+)
+
+// loadClientCertificate returns the client certificate configured for all webhooks, nil if none is.
+func loadClientCertificate() (*tls.Certificate, error) {
+	if len(setting.Webhook.ClientCertFile) == 0 {
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(setting.Webhook.ClientCertFile, setting.Webhook.ClientKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// getDeliveryClient returns the client to deliver the hook task with. The client of a webhook
+// with a client certificate presents it to the endpoints requiring mutual TLS.
+func getDeliveryClient(t *models.HookTask) (*http.Client, error) {
+	w, err := models.GetWebhookByID(t.HookID)
+	if err != nil {
+		if models.IsErrWebhookNotExist(err) {
+			return webhookHTTPClient, nil
+		}
+		return nil, err
+	}
+	if !w.HasClientCertificate() {
+		return webhookHTTPClient, nil
+	}
+
+	clientCertClientsLock.Lock()
+	defer clientCertClientsLock.Unlock()
+
+	// The encrypted key changes whenever the certificate is set
+	certificate := w.ClientCertificate + w.ClientKey
+	c, ok := clientCertClients[w.ID]
+	if ok && c.certificate == certificate {
+		return c.client, nil
+	}
+
+	cert, err := w.TLSClientCertificate()
+	if err != nil {
+		return nil, err
+	}
+	// The transport of the client certificate has to dial and proxy like the one of the other deliveries
+	var transport *http.Transport
+	switch t := webhookHTTPClient.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return nil, fmt.Errorf("unsupported transport %T of the webhook client", t)
+	}
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	transport.TLSClientConfig.Certificates = []tls.Certificate{*cert}
+
+	if c != nil {
+		c.client.CloseIdleConnections()
+	}
+	c = &clientCertClient{
+		certificate: certificate,
+		client:      &http.Client{Transport: transport},
+	}
+	clientCertClients[w.ID] = c
+	return c.client, nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func generateClientCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "gitea"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	privateKey, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKey}))
+}
+
+func TestDeliverClientCertificate(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	var subjects []string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		subjects = append(subjects, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	defer func(client *http.Client) {
+		webhookHTTPClient = client
+	}(webhookHTTPClient)
+	webhookHTTPClient = server.Client()
+
+	newTask := func() *models.HookTask {
+		task := &models.HookTask{
+			RepoID:      1,
+			HookID:      1,
+			Type:        models.GITEA,
+			URL:         server.URL,
+			Payloader:   &api.PushPayload{},
+			HTTPMethod:  http.MethodPost,
+			ContentType: models.ContentTypeJSON,
+			EventType:   models.HookEventPush,
+		}
+		assert.NoError(t, models.CreateHookTask(task))
+		return task
+	}
+
+	// the endpoint rejects deliveries without a client certificate
+	task := newTask()
+	assert.Error(t, Deliver(task))
+	assert.False(t, task.IsSucceed)
+
+	w := models.AssertExistsAndLoadBean(t, &models.Webhook{ID: 1}).(*models.Webhook)
+	cert, key := generateClientCertificate(t)
+	assert.NoError(t, w.SetClientCertificate(cert, key))
+	assert.NoError(t, models.UpdateWebhook(w))
+
+	task = newTask()
+	assert.NoError(t, Deliver(task))
+	assert.True(t, task.IsSucceed)
+	assert.Equal(t, []string{"gitea"}, subjects)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestGetDeliveryClient(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	defer func(client *http.Client) {
+		webhookHTTPClient = client
+	}(webhookHTTPClient)
+	proxy := func(*http.Request) (*url.URL, error) {
+		return url.Parse("http://proxy.example.com")
+	}
+	webhookHTTPClient = &http.Client{Transport: &http.Transport{Proxy: proxy}}
+
+	w := models.AssertExistsAndLoadBean(t, &models.Webhook{ID: 1}).(*models.Webhook)
+	cert, key := generateClientCertificate(t)
+	assert.NoError(t, w.SetClientCertificate(cert, key))
+	assert.NoError(t, models.UpdateWebhook(w))
+	task := &models.HookTask{HookID: w.ID}
+
+	// the client certificate is added to the transport of the other deliveries
+	client, err := getDeliveryClient(task)
+	assert.NoError(t, err)
+	transport := client.Transport.(*http.Transport)
+	assert.Len(t, transport.TLSClientConfig.Certificates, 1)
+	proxyURL, err := transport.Proxy(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "proxy.example.com", proxyURL.Host)
+
+	// a transport which can't be extended isn't replaced
+	delete(clientCertClients, w.ID)
+	webhookHTTPClient = &http.Client{Transport: roundTripperFunc(http.DefaultTransport.RoundTrip)}
+	_, err = getDeliveryClient(task)
+	assert.Error(t, err)
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	cloudEventsSpecVersion = "1.0"
+	cloudEventsTypePrefix  = "io.gitea."
+	cloudEventsContentType = "application/cloudevents+json; charset=utf-8"
+)
+
+// CloudEvent is a hook payload wrapped in the envelope of the
+// structured JSON mode of CloudEvents 1.0
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Time            string          `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// getCloudEvent wraps the payload of the hook task, the id of the event is the
+// uuid of the task so retried deliveries can be recognized by the receiver.
+func getCloudEvent(t *models.HookTask) *CloudEvent {
+	// Events of system webhooks like the creation of a user aren't sent from a repository
+	source := setting.AppURL
+	if t.RepoID > 0 {
+		if repo, err := models.GetRepositoryByID(t.RepoID); err == nil {
+			source = repo.HTMLURL()
+		} else {
+			log.Error("GetRepositoryByID[%d]: %v", t.RepoID, err)
+		}
+	}
+
+	return &CloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              t.UUID,
+		Source:          source,
+		Type:            cloudEventsTypePrefix + t.EventType.Event(),
+		Time:            time.Now().UTC().Format(time.RFC3339),
+		DataContentType: "application/json",
+		Data:            json.RawMessage(t.PayloadContent),
+	}
+}
+
+func getCloudEventsHookRequest(t *models.HookTask) (*http.Request, error) {
+	payload, err := json.Marshal(getCloudEvent(t))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", t.URL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", cloudEventsContentType)
+	return req, nil
+}
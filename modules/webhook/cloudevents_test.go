@@ -0,0 +1,44 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCloudEventsHookRequest(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	task := &models.HookTask{
+		RepoID:         1,
+		UUID:           "uuid1",
+		URL:            "http://localhost/hook",
+		EventType:      models.HookEventPullRequestLabel,
+		ContentType:    models.ContentTypeCloudEvents,
+		PayloadContent: `{"action":"label_updated"}`,
+	}
+	req, err := getCloudEventsHookRequest(task)
+	require.NoError(t, err)
+	assert.Equal(t, "application/cloudevents+json; charset=utf-8", req.Header.Get("Content-Type"))
+
+	body, err := ioutil.ReadAll(req.Body)
+	require.NoError(t, err)
+	var event CloudEvent
+	require.NoError(t, json.Unmarshal(body, &event))
+	assert.Equal(t, "1.0", event.SpecVersion)
+	assert.Equal(t, "uuid1", event.ID)
+	assert.Equal(t, "io.gitea.pull_request", event.Type)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	assert.Equal(t, repo.HTMLURL(), event.Source)
+	assert.Equal(t, "application/json", event.DataContentType)
+	assert.JSONEq(t, task.PayloadContent, string(event.Data))
+}
@@ -0,0 +1,158 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+const customPayloadSizeLimit = 1024 * 64
+
+var errCustomPayloadTooLarge = fmt.Errorf("payload is larger than %d bytes", customPayloadSizeLimit)
+
+// CustomMeta contains the template rendering the payloads of a custom webhook
+type CustomMeta struct {
+	Template string `json:"template"`
+}
+
+// GetCustomHook returns custom metadata
+func GetCustomHook(w *models.Webhook) *CustomMeta {
+	s := &CustomMeta{}
+	if err := json.Unmarshal([]byte(w.Meta), s); err != nil {
+		log.Error("webhook.GetCustomHook(%d): %v", w.ID, err)
+	}
+	return s
+}
+
+// CustomPayload contains the body rendered for a custom webhook
+type CustomPayload struct {
+	Body string
+}
+
+// SetSecret sets the custom secret
+func (p *CustomPayload) SetSecret(_ string) {}
+
+// JSONPayload returns the rendered body, it is sent as is
+func (p *CustomPayload) JSONPayload() ([]byte, error) {
+	return []byte(p.Body), nil
+}
+
+func newCustomTemplate(event models.HookEventType) *template.Template {
+	return template.New("custom").Option("missingkey=zero").Funcs(template.FuncMap{
+		"event": func() string {
+			return event.Event()
+		},
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	})
+}
+
+// parseCustomTemplate parses the template of a custom webhook, the templates can't be nested
+// so that it can't call itself or other templates
+func parseCustomTemplate(event models.HookEventType, text string) (*template.Template, error) {
+	tmpl, err := newCustomTemplate(event).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(tmpl.Templates()) > 1 || (tmpl.Tree != nil && hasTemplateNode(tmpl.Tree.Root)) {
+		return nil, errors.New("define, template and block actions are not allowed")
+	}
+	return tmpl, nil
+}
+
+// hasTemplateNode returns whether the node contains a template or block action
+func hasTemplateNode(node parse.Node) bool {
+	switch n := node.(type) {
+	case *parse.TemplateNode:
+		return true
+	case *parse.ListNode:
+		if n == nil {
+			return false
+		}
+		for _, child := range n.Nodes {
+			if hasTemplateNode(child) {
+				return true
+			}
+		}
+	case *parse.IfNode:
+		return hasTemplateNode(n.List) || hasTemplateNode(n.ElseList)
+	case *parse.RangeNode:
+		return hasTemplateNode(n.List) || hasTemplateNode(n.ElseList)
+	case *parse.WithNode:
+		return hasTemplateNode(n.List) || hasTemplateNode(n.ElseList)
+	}
+	return false
+}
+
+// ParseCustomTemplate checks that the template of a custom webhook can be parsed
+func ParseCustomTemplate(tmpl string) error {
+	_, err := parseCustomTemplate("", tmpl)
+	return err
+}
+
+// customPayloadWriter collects a rendered payload and fails once it exceeds the size limit
+type customPayloadWriter struct {
+	body strings.Builder
+}
+
+func (w *customPayloadWriter) Write(p []byte) (int, error) {
+	if w.body.Len()+len(p) >= customPayloadSizeLimit {
+		return 0, errCustomPayloadTooLarge
+	}
+	return w.body.Write(p)
+}
+
+// GetCustomPayload renders the template of a custom webhook with the payload of the event.
+// The template accesses the fields of the payload by their JSON names, e.g. {{.repository.full_name}}.
+func GetCustomPayload(p api.Payloader, event models.HookEventType, meta string) (*CustomPayload, error) {
+	customMeta := &CustomMeta{}
+	if err := json.Unmarshal([]byte(meta), customMeta); err != nil {
+		return nil, fmt.Errorf("GetCustomPayload meta json: %v", err)
+	}
+
+	tmpl, err := parseCustomTemplate(event, customMeta.Template)
+	if err != nil {
+		return nil, fmt.Errorf("GetCustomPayload parse template: %v", err)
+	}
+
+	data, err := p.JSONPayload()
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	// the payload object is shared with the other webhooks of the event, it may carry their secret
+	delete(fields, "secret")
+
+	var w customPayloadWriter
+	if err := tmpl.Execute(&w, fields); err != nil {
+		return nil, fmt.Errorf("GetCustomPayload execute template: %v", err)
+	}
+	return &CustomPayload{Body: w.body.String()}, nil
+}
+
+func getCustomHookRequest(t *models.HookTask) (*http.Request, error) {
+	req, err := http.NewRequest("POST", t.URL, strings.NewReader(t.PayloadContent))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func customTestMeta(t *testing.T, tmpl string) string {
+	meta, err := json.Marshal(&CustomMeta{Template: tmpl})
+	require.NoError(t, err)
+	return string(meta)
+}
+
+func TestCustomPayload(t *testing.T) {
+	p := issueTestPayload()
+	p.Secret = "secret of another hook"
+
+	meta := customTestMeta(t, `{"summary": {{json (printf "%s: #%v %s" .repository.full_name .issue.number .issue.title)}}, "event": "{{event}}", "secret": {{json .secret}}}`)
+	pl, err := GetCustomPayload(p, models.HookEventIssues, meta)
+	require.NoError(t, err)
+	require.NotNil(t, pl)
+	assert.JSONEq(t, `{"summary": "test/repo: #2 crash", "event": "issues", "secret": null}`, pl.Body)
+
+	data, err := pl.JSONPayload()
+	require.NoError(t, err)
+	assert.Equal(t, pl.Body, string(data))
+}
+
+func TestCustomPayloadInvalidTemplate(t *testing.T) {
+	assert.Error(t, ParseCustomTemplate("{{.repository"))
+	assert.NoError(t, ParseCustomTemplate(`{{json .sender}} {{event}}`))
+
+	_, err := GetCustomPayload(issueTestPayload(), models.HookEventIssues, customTestMeta(t, "{{.repository"))
+	assert.Error(t, err)
+}
+
+func TestCustomPayloadNestedTemplates(t *testing.T) {
+	for _, tmpl := range []string{
+		`{{define "a"}}{{.}}{{end}}{{template "a" .}}`,
+		`{{template "custom" .}}{{template "custom" .}}`,
+		`{{if .issue}}{{range .commits}}{{block "b" .}}{{.}}{{end}}{{end}}{{end}}`,
+		`{{with .issue}}{{else}}{{template "x"}}{{end}}`,
+	} {
+		assert.Error(t, ParseCustomTemplate(tmpl), tmpl)
+		_, err := GetCustomPayload(issueTestPayload(), models.HookEventIssues, customTestMeta(t, tmpl))
+		assert.Error(t, err, tmpl)
+	}
+	assert.NoError(t, ParseCustomTemplate(`{{if .issue}}{{range .commits}}{{.id}}{{end}}{{else}}{{with .sender}}{{.login}}{{end}}{{end}}`))
+}
+
+func TestCustomPayloadSizeLimit(t *testing.T) {
+	// The rendering stops as soon as the payload exceeds the limit
+	tmpl := `{{range .}}{{range $}}{{range $}}{{printf "%1000000s" ""}}{{end}}{{end}}{{end}}`
+	_, err := GetCustomPayload(issueTestPayload(), models.HookEventIssues, customTestMeta(t, tmpl))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), errCustomPayloadTooLarge.Error())
+
+	pl, err := GetCustomPayload(issueTestPayload(), models.HookEventIssues, customTestMeta(t, `{{printf "%60000s" ""}}`))
+	assert.NoError(t, err)
+	assert.Len(t, pl.Body, 60000)
+}
+
+func TestGetCustomHookRequest(t *testing.T) {
+	task := &models.HookTask{
+		Type:           models.CUSTOM,
+		URL:            "http://localhost/hook",
+		PayloadContent: `{"summary": "test"}`,
+	}
+	req, err := getCustomHookRequest(task)
+	require.NoError(t, err)
+	assert.Equal(t, "POST", req.Method)
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+}
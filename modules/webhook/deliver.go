@@ -7,6 +7,7 @@ package webhook
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net"
@@ -14,14 +15,17 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/metrics"
+	"code.gitea.io/gitea/modules/queue"
 	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
 	"github.com/gobwas/glob"
-	"github.com/unknwon/com"
 )
 
 // Deliver deliver hook task
@@ -64,6 +68,11 @@ func Deliver(t *models.HookTask) error {
 			}
 
 			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		case models.ContentTypeCloudEvents:
+			req, err = getCloudEventsHookRequest(t)
+			if err != nil {
+				return err
+			}
 		}
 	case http.MethodGet:
 		u, err := url.Parse(t.URL)
@@ -81,19 +90,29 @@ func Deliver(t *models.HookTask) error {
 		return fmt.Errorf("Invalid http method for webhook: [%d] %v", t.ID, t.HTTPMethod)
 	}
 
-	if t.Type == models.MATRIX {
+	switch t.Type {
+	case models.MATRIX:
 		req, err = getMatrixHookRequest(t)
 		if err != nil {
 			return err
 		}
+	case models.CUSTOM:
+		req, err = getCustomHookRequest(t)
+		if err != nil {
+			return err
+		}
 	}
 
 	req.Header.Add("X-Gitea-Delivery", t.UUID)
 	req.Header.Add("X-Gitea-Event", t.EventType.Event())
-	req.Header.Add("X-Gitea-Signature", t.Signature)
 	req.Header.Add("X-Gogs-Delivery", t.UUID)
 	req.Header.Add("X-Gogs-Event", t.EventType.Event())
-	req.Header.Add("X-Gogs-Signature", t.Signature)
+	if len(t.SignatureAlgorithm) == 0 {
+		req.Header.Add("X-Gitea-Signature", t.Signature)
+		req.Header.Add("X-Gogs-Signature", t.Signature)
+	} else if len(t.Signature) > 0 {
+		req.Header.Add(api.HookSignatureHeader(t.SignatureAlgorithm), t.Signature)
+	}
 	req.Header["X-GitHub-Delivery"] = []string{t.UUID}
 	req.Header["X-GitHub-Event"] = []string{t.EventType.Event()}
 
@@ -109,14 +128,50 @@ func Deliver(t *models.HookTask) error {
 		Headers: map[string]string{},
 	}
 
+	// Deliveries to blocked hosts are never retried
+	var blocked bool
+
+	start := time.Now()
 	defer func() {
+		duration := time.Since(start)
 		t.Delivered = time.Now().UnixNano()
+		t.Attempts++
+		attempt := &models.HookAttempt{
+			Delivered: t.Delivered,
+			Status:    t.ResponseInfo.Status,
+			Duration:  duration.Nanoseconds(),
+		}
+		if t.ResponseInfo.Status == 0 {
+			attempt.Error = t.ResponseInfo.Body
+		}
+		t.AttemptHistory = append(t.AttemptHistory, attempt)
+
+		status := metrics.WebhookDeliverySucceeded
 		if t.IsSucceed {
 			log.Trace("Hook delivered: %s", t.UUID)
+		} else if !blocked && t.Attempts < setting.Webhook.MaxAttempts && shouldRetry(t.ResponseInfo.Status) {
+			status = metrics.WebhookDeliveryRetrying
+			t.IsDelivered = false
+			t.NextAttempt = time.Now().Add(retryBackoff(t.Attempts)).UnixNano()
+			log.Trace("Hook delivery failed: %s, attempt %d of %d", t.UUID, t.Attempts, setting.Webhook.MaxAttempts)
 		} else {
+			status = metrics.WebhookDeliveryFailed
+			t.IsDeadLetter = true
 			log.Trace("Hook delivery failed: %s", t.UUID)
 		}
 
+		metrics.WebhookDeliveries.WithLabelValues(t.Type.Name(), status).Inc()
+		metrics.WebhookDeliveryDuration.WithLabelValues(t.Type.Name()).Observe(duration.Seconds())
+		if t.Attempts > 1 {
+			metrics.WebhookDeliveryRetries.WithLabelValues(t.Type.Name()).Inc()
+		}
+
+		if t.IsDelivered && setting.Webhook.CompressDeliveredPayloads {
+			if err := t.CompressPayload(); err != nil {
+				log.Error("CompressPayload [%d]: %v", t.ID, err)
+			}
+		}
+
 		if err := models.UpdateHookTask(t); err != nil {
 			log.Error("UpdateHookTask [%d]: %v", t.ID, err)
 		}
@@ -138,11 +193,32 @@ func Deliver(t *models.HookTask) error {
 		}
 	}()
 
-	resp, err := webhookHTTPClient.Do(req)
+	if !isHostAllowed(req.URL.Hostname()) {
+		blocked = true
+		err = ErrHostBlocked{Host: req.URL.Hostname()}
+		t.ResponseInfo.Body = fmt.Sprintf("Delivery: %v", err)
+		return err
+	}
+
+	proxy, err := getDeliveryProxy(t, req)
 	if err != nil {
 		t.ResponseInfo.Body = fmt.Sprintf("Delivery: %v", err)
 		return err
 	}
+	req = req.WithContext(context.WithValue(req.Context(), deliveryProxyKey{}, proxy))
+
+	client, err := getDeliveryClient(t)
+	if err != nil {
+		t.ResponseInfo.Body = fmt.Sprintf("Delivery: %v", err)
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		blocked = errors.As(err, &ErrHostBlocked{})
+		t.ResponseInfo.Body = fmt.Sprintf("Delivery: %v", err)
+		return err
+	}
 	defer resp.Body.Close()
 
 	// Status code is 20x can be seen as succeed.
@@ -161,9 +237,24 @@ func Deliver(t *models.HookTask) error {
 	return nil
 }
 
-// DeliverHooks checks and delivers undelivered hooks.
-// FIXME: graceful: This would likely benefit from either a worker pool with dummy queue
-// or a full queue. Then more hooks could be sent at same time.
+// shouldRetry returns true if a delivery which got the given response status
+// may succeed later, client errors other than timeouts and rate limits are final.
+// A status of 0 means that no response has been received.
+func shouldRetry(status int) bool {
+	if status/100 != 4 {
+		return true
+	}
+	return status == http.StatusRequestTimeout || status == http.StatusTooManyRequests
+}
+
+// retryBackoff returns the delay before the next delivery attempt,
+// it doubles with every failed attempt.
+func retryBackoff(attempts int) time.Duration {
+	return setting.Webhook.RetryBackoff * time.Duration(1<<uint(attempts-1))
+}
+
+// DeliverHooks delivers the hooks left undelivered, e.g. by a shutdown, and retries the
+// failed deliveries. New hooks are delivered by the hook queue.
 func DeliverHooks(ctx context.Context) {
 	select {
 	case <-ctx.Done():
@@ -177,51 +268,53 @@ func DeliverHooks(ctx context.Context) {
 	}
 
 	// Update hook task status.
-	for _, t := range tasks {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-		}
-		if err = Deliver(t); err != nil {
-			log.Error("deliver: %v", err)
-		}
+	if !deliverTasks(ctx, tasks) {
+		return
 	}
 
+	// Failed deliveries are retried once their backoff has elapsed.
+	retryInterval := setting.Webhook.RetryBackoff
+	if retryInterval > time.Minute {
+		retryInterval = time.Minute
+	}
+	retryTicker := time.NewTicker(retryInterval)
+	defer retryTicker.Stop()
+
 	// Start listening on new hook requests.
 	for {
 		select {
 		case <-ctx.Done():
-			hookQueue.Close()
 			return
-		case repoIDStr := <-hookQueue.Queue():
-			log.Trace("DeliverHooks [repo_id: %v]", repoIDStr)
-			hookQueue.Remove(repoIDStr)
-
-			repoID, err := com.StrTo(repoIDStr).Int64()
-			if err != nil {
-				log.Error("Invalid repo ID: %s", repoIDStr)
-				continue
-			}
-
-			tasks, err := models.FindRepoUndeliveredHookTasks(repoID)
+		case <-retryTicker.C:
+			tasks, err := models.FindUndeliveredHookTasks()
 			if err != nil {
-				log.Error("Get repository [%d] hook tasks: %v", repoID, err)
+				log.Error("DeliverHooks: %v", err)
 				continue
 			}
-			for _, t := range tasks {
-				select {
-				case <-ctx.Done():
-					return
-				default:
-				}
-				if err = Deliver(t); err != nil {
-					log.Error("deliver: %v", err)
-				}
+			if !deliverTasks(ctx, tasks) {
+				return
 			}
 		}
 	}
+}
+
+// handle delivers the hook tasks of the queued repositories
+func handle(data ...queue.Data) {
+	ctx := graceful.GetManager().ShutdownContext()
+	for _, datum := range data {
+		repoID := datum.(int64)
+		log.Trace("DeliverHooks [repo_id: %v]", repoID)
 
+		tasks, err := models.FindRepoUndeliveredHookTasks(repoID)
+		if err != nil {
+			log.Error("Get repository [%d] hook tasks: %v", repoID, err)
+			continue
+		}
+		if !deliverTasks(ctx, tasks) {
+			// The tasks left undelivered are delivered on startup
+			return
+		}
+	}
 }
 
 var (
@@ -255,24 +348,105 @@ func webhookProxy() func(req *http.Request) (*url.URL, error) {
 	}
 }
 
+type deliveryProxyKey struct{}
+
+// deliveryProxy is the proxy a delivery is sent through, proxies configured by
+// the administrator are trusted and not subject to the blocked hosts.
+type deliveryProxy struct {
+	URL     *url.URL
+	Trusted bool
+}
+
+// getDeliveryProxy returns the proxy to send the request of the hook task through,
+// the proxy of the webhook takes precedence over the global one.
+func getDeliveryProxy(t *models.HookTask, req *http.Request) (*deliveryProxy, error) {
+	if len(t.ProxyURL) > 0 {
+		u, err := url.Parse(t.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %v", err)
+		}
+		return &deliveryProxy{URL: u}, nil
+	}
+
+	u, err := webhookProxy()(req)
+	if err != nil {
+		return nil, err
+	}
+	return &deliveryProxy{URL: u, Trusted: true}, nil
+}
+
+// dialContext connects to addr unless the host is blocked. The resolved address
+// is checked as well, unless the host name is explicitly allowed.
+func dialContext(ctx context.Context, network, addr string, timeout time.Duration) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+
+	// The connection of a delivery sent through a trusted proxy is the one to the proxy
+	proxy, _ := ctx.Value(deliveryProxyKey{}).(*deliveryProxy)
+	if proxy == nil || proxy.URL == nil || !proxy.Trusted {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		loadHostLists()
+		if !allowedHosts.matchHost(host) {
+			if blockedHosts.matchHost(host) {
+				return nil, ErrHostBlocked{Host: host}
+			}
+			dialer.Control = func(network, address string, c syscall.RawConn) error {
+				ip, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				if !isIPAllowed(net.ParseIP(ip)) {
+					return ErrHostBlocked{Host: host + " (" + ip + ")"}
+				}
+				return nil
+			}
+		}
+	}
+
+	conn, err := dialer.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return conn, conn.SetDeadline(time.Now().Add(timeout))
+}
+
 // InitDeliverHooks starts the hooks delivery thread
-func InitDeliverHooks() {
+func InitDeliverHooks() error {
 	timeout := time.Duration(setting.Webhook.DeliverTimeout) * time.Second
 
+	tlsConfig := &tls.Config{InsecureSkipVerify: setting.Webhook.SkipTLSVerify}
+	cert, err := loadClientCertificate()
+	if err != nil {
+		return fmt.Errorf("Unable to load the webhook client certificate: %v", err)
+	}
+	if cert != nil {
+		tlsConfig.Certificates = []tls.Certificate{*cert}
+	}
+
 	webhookHTTPClient = &http.Client{
 		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: setting.Webhook.SkipTLSVerify},
-			Proxy:           webhookProxy(),
-			Dial: func(netw, addr string) (net.Conn, error) {
-				conn, err := net.DialTimeout(netw, addr, timeout)
-				if err != nil {
-					return nil, err
+			TLSClientConfig: tlsConfig,
+			Proxy: func(req *http.Request) (*url.URL, error) {
+				if proxy, ok := req.Context().Value(deliveryProxyKey{}).(*deliveryProxy); ok {
+					return proxy.URL, nil
 				}
-
-				return conn, conn.SetDeadline(time.Now().Add(timeout))
+				return webhookProxy()(req)
+			},
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return dialContext(ctx, network, addr, timeout)
 			},
 		},
 	}
 
+	hookQueue = queue.CreateUniqueQueue("webhook_sender", handle, int64(0)).(queue.UniqueQueue)
+	if hookQueue == nil {
+		return fmt.Errorf("Unable to create webhook_sender Queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(hookQueue.Run)
 	go graceful.GetManager().RunWithShutdownContext(DeliverHooks)
+	return nil
 }
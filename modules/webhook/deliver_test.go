@@ -5,11 +5,21 @@
 package webhook
 
 import (
+	"context"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/metrics"
+	"code.gitea.io/gitea/modules/queue"
 	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -37,3 +47,292 @@ func TestWebhookProxy(t *testing.T) {
 		}
 	}
 }
+
+func TestShouldRetry(t *testing.T) {
+	assert.True(t, shouldRetry(0))
+	assert.True(t, shouldRetry(http.StatusInternalServerError))
+	assert.True(t, shouldRetry(http.StatusBadGateway))
+	assert.True(t, shouldRetry(http.StatusTooManyRequests))
+	assert.True(t, shouldRetry(http.StatusRequestTimeout))
+	assert.False(t, shouldRetry(http.StatusNotFound))
+	assert.False(t, shouldRetry(http.StatusUnauthorized))
+}
+
+func TestRetryBackoff(t *testing.T) {
+	defer func(backoff time.Duration) {
+		setting.Webhook.RetryBackoff = backoff
+	}(setting.Webhook.RetryBackoff)
+	setting.Webhook.RetryBackoff = 10 * time.Second
+
+	assert.Equal(t, 10*time.Second, retryBackoff(1))
+	assert.Equal(t, 20*time.Second, retryBackoff(2))
+	assert.Equal(t, 80*time.Second, retryBackoff(4))
+}
+
+func TestDeliverRetry(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	defer func(client *http.Client, maxAttempts int, backoff time.Duration) {
+		webhookHTTPClient = client
+		setting.Webhook.MaxAttempts = maxAttempts
+		setting.Webhook.RetryBackoff = backoff
+	}(webhookHTTPClient, setting.Webhook.MaxAttempts, setting.Webhook.RetryBackoff)
+	webhookHTTPClient = http.DefaultClient
+	setting.Webhook.MaxAttempts = 2
+	setting.Webhook.RetryBackoff = time.Hour
+
+	status := http.StatusInternalServerError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	newTask := func() *models.HookTask {
+		task := &models.HookTask{
+			RepoID:      1,
+			HookID:      1,
+			Type:        models.GITEA,
+			URL:         server.URL,
+			Payloader:   &api.PushPayload{},
+			HTTPMethod:  http.MethodPost,
+			ContentType: models.ContentTypeJSON,
+			EventType:   models.HookEventPush,
+		}
+		assert.NoError(t, models.CreateHookTask(task))
+		return task
+	}
+
+	task := newTask()
+	assert.NoError(t, Deliver(task))
+	task = models.AssertExistsAndLoadBean(t, &models.HookTask{ID: task.ID}).(*models.HookTask)
+	assert.False(t, task.IsDelivered)
+	assert.False(t, task.IsSucceed)
+	assert.Equal(t, 1, task.Attempts)
+	assert.True(t, task.NextAttempt > time.Now().Add(59*time.Minute).UnixNano())
+	if assert.Len(t, task.AttemptHistory, 1) {
+		assert.Equal(t, http.StatusInternalServerError, task.AttemptHistory[0].Status)
+		assert.True(t, task.AttemptHistory[0].Duration > 0)
+	}
+
+	// the retry isn't due yet
+	tasks, err := models.FindRepoUndeliveredHookTasks(1)
+	assert.NoError(t, err)
+	for _, undelivered := range tasks {
+		assert.NotEqual(t, task.ID, undelivered.ID)
+	}
+
+	// the last attempt is final
+	assert.NoError(t, Deliver(task))
+	task = models.AssertExistsAndLoadBean(t, &models.HookTask{ID: task.ID}).(*models.HookTask)
+	assert.True(t, task.IsDelivered)
+	assert.False(t, task.IsSucceed)
+	assert.Equal(t, 2, task.Attempts)
+	assert.Len(t, task.AttemptHistory, 2)
+
+	// client errors aren't retried
+	status = http.StatusNotFound
+	task = newTask()
+	assert.NoError(t, Deliver(task))
+	task = models.AssertExistsAndLoadBean(t, &models.HookTask{ID: task.ID}).(*models.HookTask)
+	assert.True(t, task.IsDelivered)
+	assert.Equal(t, 1, task.Attempts)
+
+	status = http.StatusOK
+	task = newTask()
+	assert.NoError(t, Deliver(task))
+	task = models.AssertExistsAndLoadBean(t, &models.HookTask{ID: task.ID}).(*models.HookTask)
+	assert.True(t, task.IsDelivered)
+	assert.True(t, task.IsSucceed)
+	assert.Equal(t, 1, task.Attempts)
+}
+
+func TestDeliverMetrics(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	defer func(client *http.Client, maxAttempts int) {
+		webhookHTTPClient = client
+		setting.Webhook.MaxAttempts = maxAttempts
+	}(webhookHTTPClient, setting.Webhook.MaxAttempts)
+	webhookHTTPClient = http.DefaultClient
+	setting.Webhook.MaxAttempts = 2
+
+	status := http.StatusInternalServerError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	value := func(c prometheus.Collector) float64 {
+		m := &dto.Metric{}
+		switch c := c.(type) {
+		case prometheus.Counter:
+			assert.NoError(t, c.Write(m))
+			return m.GetCounter().GetValue()
+		case prometheus.Histogram:
+			assert.NoError(t, c.Write(m))
+			return float64(m.GetHistogram().GetSampleCount())
+		}
+		return 0
+	}
+	succeeded := value(metrics.WebhookDeliveries.WithLabelValues("slack", metrics.WebhookDeliverySucceeded))
+	retrying := value(metrics.WebhookDeliveries.WithLabelValues("slack", metrics.WebhookDeliveryRetrying))
+	failed := value(metrics.WebhookDeliveries.WithLabelValues("slack", metrics.WebhookDeliveryFailed))
+	retries := value(metrics.WebhookDeliveryRetries.WithLabelValues("slack"))
+	observed := value(metrics.WebhookDeliveryDuration.WithLabelValues("slack").(prometheus.Histogram))
+
+	task := &models.HookTask{
+		RepoID:      1,
+		HookID:      1,
+		Type:        models.SLACK,
+		URL:         server.URL,
+		Payloader:   &api.PushPayload{},
+		HTTPMethod:  http.MethodPost,
+		ContentType: models.ContentTypeJSON,
+		EventType:   models.HookEventPush,
+	}
+	assert.NoError(t, models.CreateHookTask(task))
+
+	undelivered, err := models.CountUndeliveredHookTasks()
+	assert.NoError(t, err)
+	assert.True(t, undelivered > 0)
+
+	assert.NoError(t, Deliver(task))
+	assert.Equal(t, retrying+1, value(metrics.WebhookDeliveries.WithLabelValues("slack", metrics.WebhookDeliveryRetrying)))
+	assert.Equal(t, retries, value(metrics.WebhookDeliveryRetries.WithLabelValues("slack")))
+
+	assert.NoError(t, Deliver(task))
+	assert.Equal(t, failed+1, value(metrics.WebhookDeliveries.WithLabelValues("slack", metrics.WebhookDeliveryFailed)))
+	assert.Equal(t, retries+1, value(metrics.WebhookDeliveryRetries.WithLabelValues("slack")))
+	assert.Equal(t, succeeded, value(metrics.WebhookDeliveries.WithLabelValues("slack", metrics.WebhookDeliverySucceeded)))
+	assert.Equal(t, observed+2, value(metrics.WebhookDeliveryDuration.WithLabelValues("slack").(prometheus.Histogram)))
+
+	// the delivered task isn't waiting anymore
+	count, err := models.CountUndeliveredHookTasks()
+	assert.NoError(t, err)
+	assert.Equal(t, undelivered-1, count)
+}
+
+func TestDeliverSignatureHeaders(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	defer func(client *http.Client) {
+		webhookHTTPClient = client
+	}(webhookHTTPClient)
+	webhookHTTPClient = http.DefaultClient
+
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+	}))
+	defer server.Close()
+
+	deliver := func(algorithm, signature string) {
+		task := &models.HookTask{
+			RepoID:             1,
+			HookID:             1,
+			Type:               models.GITEA,
+			URL:                server.URL,
+			Signature:          signature,
+			SignatureAlgorithm: algorithm,
+			Payloader:          &api.PushPayload{},
+			HTTPMethod:         http.MethodPost,
+			ContentType:        models.ContentTypeJSON,
+			EventType:          models.HookEventPush,
+		}
+		assert.NoError(t, models.CreateHookTask(task))
+		assert.NoError(t, Deliver(task))
+	}
+
+	deliver("", "abc")
+	assert.Equal(t, "abc", header.Get("X-Gitea-Signature"))
+	assert.Equal(t, "abc", header.Get("X-Gogs-Signature"))
+
+	deliver(api.HookSignatureSHA512, "sha512=abc")
+	assert.Equal(t, "sha512=abc", header.Get("X-Gitea-Signature-512"))
+	assert.Empty(t, header.Get("X-Gitea-Signature"))
+	assert.Empty(t, header.Get("X-Gogs-Signature"))
+}
+
+func TestDeliverCompressPayload(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	defer func(client *http.Client, compress bool) {
+		webhookHTTPClient = client
+		setting.Webhook.CompressDeliveredPayloads = compress
+	}(webhookHTTPClient, setting.Webhook.CompressDeliveredPayloads)
+	webhookHTTPClient = http.DefaultClient
+	setting.Webhook.CompressDeliveredPayloads = true
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	task := &models.HookTask{
+		RepoID:      1,
+		HookID:      1,
+		Type:        models.GITEA,
+		URL:         server.URL,
+		Payloader:   &api.PushPayload{Ref: "refs/heads/master"},
+		HTTPMethod:  http.MethodPost,
+		ContentType: models.ContentTypeJSON,
+		EventType:   models.HookEventPush,
+	}
+	assert.NoError(t, models.CreateHookTask(task))
+	payload := task.PayloadContent
+	assert.NoError(t, Deliver(task))
+
+	task = models.AssertExistsAndLoadBean(t, &models.HookTask{ID: task.ID}, models.Cond("is_payload_compressed = ?", true)).(*models.HookTask)
+	assert.True(t, task.IsSucceed)
+	assert.Equal(t, payload, task.PayloadContent)
+}
+
+func TestHookQueue(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	defer func(client *http.Client) {
+		webhookHTTPClient = client
+		hookQueue = nil
+	}(webhookHTTPClient)
+	webhookHTTPClient = http.DefaultClient
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	q, err := queue.NewChannelUniqueQueue(handle, queue.ChannelUniqueQueueConfiguration{
+		WorkerPoolConfiguration: queue.WorkerPoolConfiguration{
+			QueueLength: 10,
+			BatchLength: 1,
+		},
+		Workers: 1,
+		Name:    "temporary-queue",
+	}, int64(0))
+	assert.NoError(t, err)
+	hookQueue = q.(queue.UniqueQueue)
+
+	var shutdown, terminate func()
+	go hookQueue.Run(func(_ context.Context, fn func()) {
+		shutdown = fn
+	}, func(_ context.Context, fn func()) {
+		terminate = fn
+	})
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	w := &models.Webhook{
+		RepoID:       repo.ID,
+		URL:          server.URL,
+		ContentType:  models.ContentTypeJSON,
+		HTTPMethod:   http.MethodPost,
+		IsActive:     true,
+		HookTaskType: models.GITEA,
+		HookEvent:    &models.HookEvent{PushOnly: true},
+	}
+	assert.NoError(t, w.UpdateEvent())
+	assert.NoError(t, models.CreateWebhook(w))
+
+	assert.NoError(t, PrepareWebhook(w, repo, models.HookEventPush, &api.PushPayload{}))
+	assert.Eventually(t, func() bool {
+		task := models.AssertExistsAndLoadBean(t, &models.HookTask{HookID: w.ID}).(*models.HookTask)
+		return task.IsSucceed
+	}, 5*time.Second, 100*time.Millisecond)
+
+	if shutdown != nil {
+		shutdown()
+	}
+	if terminate != nil {
+		terminate()
+	}
+}
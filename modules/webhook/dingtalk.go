@@ -230,6 +230,18 @@ func getDingtalkRepositoryPayload(p *api.RepositoryPayload) (*DingtalkPayload, e
 				Content: title,
 			},
 		}, nil
+	case api.HookRepoRenamed, api.HookRepoTransferred, api.HookRepoTransferRequested, api.HookRepoTransferRejected, api.HookRepoArchived, api.HookRepoUnarchived:
+		title, _ = getRepositoryChangePayloadInfo(p, noneLinkFormatter, true)
+		return &DingtalkPayload{
+			MsgType: "actionCard",
+			ActionCard: dingtalk.ActionCard{
+				Text:        title,
+				Title:       title,
+				HideAvatar:  "0",
+				SingleTitle: "view repository",
+				SingleURL:   p.Repository.HTMLURL,
+			},
+		}, nil
 	}
 
 	return nil, nil
@@ -347,6 +347,9 @@ func getDiscordRepositoryPayload(p *api.RepositoryPayload, meta *DiscordMeta) (*
 	case api.HookRepoDeleted:
 		title = fmt.Sprintf("[%s] Repository deleted", p.Repository.FullName)
 		color = redColor
+	case api.HookRepoRenamed, api.HookRepoTransferred, api.HookRepoTransferRequested, api.HookRepoTransferRejected, api.HookRepoArchived, api.HookRepoUnarchived:
+		title, color = getRepositoryChangePayloadInfo(p, noneLinkFormatter, false)
+		url = p.Repository.HTMLURL
 	}
 
 	return &DiscordPayload{
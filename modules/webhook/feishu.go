@@ -156,6 +156,12 @@ func getFeishuRepositoryPayload(p *api.RepositoryPayload) (*FeishuPayload, error
 			Title: title,
 			Text:  title,
 		}, nil
+	case api.HookRepoRenamed, api.HookRepoTransferred, api.HookRepoTransferRequested, api.HookRepoTransferRejected, api.HookRepoArchived, api.HookRepoUnarchived:
+		title, _ = getRepositoryChangePayloadInfo(p, noneLinkFormatter, true)
+		return &FeishuPayload{
+			Title: title,
+			Text:  title,
+		}, nil
 	}
 
 	return nil, nil
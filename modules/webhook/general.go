@@ -146,6 +146,45 @@ func getReleasePayloadInfo(p *api.ReleasePayload, linkFormatter linkFormatter, w
 	case api.HookReleaseDeleted:
 		text = fmt.Sprintf("[%s] Release deleted: %s", repoLink, refLink)
 		color = redColor
+	case api.HookReleaseAssetUploaded:
+		text = fmt.Sprintf("[%s] Release asset uploaded to %s: %s", repoLink, refLink,
+			linkFormatter(p.Asset.DownloadURL, p.Asset.Name))
+		color = greenColor
+	case api.HookReleaseAssetDeleted:
+		text = fmt.Sprintf("[%s] Release asset deleted from %s: %s", repoLink, refLink, p.Asset.Name)
+		color = redColor
+	}
+	if withSender {
+		text += fmt.Sprintf(" by %s", linkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName))
+	}
+
+	return text, color
+}
+
+// getRepositoryChangePayloadInfo returns the text of a renamed, transferred, archived or
+// unarchived repository event, or of a requested or rejected transfer.
+func getRepositoryChangePayloadInfo(p *api.RepositoryPayload, linkFormatter linkFormatter, withSender bool) (text string, color int) {
+	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+
+	switch p.Action {
+	case api.HookRepoRenamed:
+		text = fmt.Sprintf("[%s] Repository renamed from %s", repoLink, p.OldName)
+		color = yellowColor
+	case api.HookRepoTransferred:
+		text = fmt.Sprintf("[%s] Repository transferred from %s", repoLink, p.OldOwner)
+		color = yellowColor
+	case api.HookRepoTransferRequested:
+		text = fmt.Sprintf("[%s] Repository transfer to %s requested", repoLink, p.NewOwner)
+		color = yellowColor
+	case api.HookRepoTransferRejected:
+		text = fmt.Sprintf("[%s] Repository transfer to %s rejected", repoLink, p.NewOwner)
+		color = greyColor
+	case api.HookRepoArchived:
+		text = fmt.Sprintf("[%s] Repository archived", repoLink)
+		color = greyColor
+	case api.HookRepoUnarchived:
+		text = fmt.Sprintf("[%s] Repository unarchived", repoLink)
+		color = greenColor
 	}
 	if withSender {
 		text += fmt.Sprintf(" by %s", linkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName))
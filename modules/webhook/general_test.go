@@ -123,3 +123,18 @@ func pullRequestTestPayload() *api.PullRequestPayload {
 		},
 	}
 }
+
+func repositoryRenamedTestPayload() *api.RepositoryPayload {
+	return &api.RepositoryPayload{
+		Action:  api.HookRepoRenamed,
+		OldName: "old-repo",
+		Sender: &api.User{
+			UserName: "user1",
+		},
+		Repository: &api.Repository{
+			HTMLURL:  "http://localhost:3000/test/repo",
+			Name:     "repo",
+			FullName: "test/repo",
+		},
+	}
+}
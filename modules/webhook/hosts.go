@@ -0,0 +1,142 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/gobwas/glob"
+)
+
+// ErrHostBlocked represents a delivery to a host webhooks must not be delivered to.
+type ErrHostBlocked struct {
+	Host string
+}
+
+func (err ErrHostBlocked) Error() string {
+	return fmt.Sprintf("webhooks must not be delivered to host %s", err.Host)
+}
+
+var (
+	loopbackNetworks = mustParseCIDRs("127.0.0.0/8", "::1/128", "0.0.0.0/32", "::/128")
+	privateNetworks  = mustParseCIDRs(
+		"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "100.64.0.0/10", // IPv4 private and shared address space
+		"169.254.0.0/16", "fe80::/10", // link-local
+		"fc00::/7", // IPv6 unique local
+	)
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// hostList is a list of hosts, entries are host name glob patterns, IP addresses,
+// networks in CIDR notation and the keywords "loopback" and "private".
+type hostList struct {
+	globs    []glob.Glob
+	networks []*net.IPNet
+}
+
+func newHostList(entries []string) *hostList {
+	l := &hostList{}
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+		case entry == "loopback":
+			l.networks = append(l.networks, loopbackNetworks...)
+		case entry == "private":
+			l.networks = append(l.networks, privateNetworks...)
+		case net.ParseIP(entry) != nil:
+			ip := net.ParseIP(entry)
+			if ip4 := ip.To4(); ip4 != nil {
+				ip = ip4
+			}
+			l.networks = append(l.networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+		case strings.Contains(entry, "/"):
+			if _, network, err := net.ParseCIDR(entry); err == nil {
+				l.networks = append(l.networks, network)
+			} else {
+				log.Error("Invalid webhook host network %s: %v", entry, err)
+			}
+		default:
+			if g, err := glob.Compile(entry); err == nil {
+				l.globs = append(l.globs, g)
+			} else {
+				log.Error("glob.Compile %s failed: %v", entry, err)
+			}
+		}
+	}
+	return l
+}
+
+// matchHost returns true if the host name or IP address is in the list.
+func (l *hostList) matchHost(host string) bool {
+	if ip := net.ParseIP(host); ip != nil {
+		return l.matchIP(ip)
+	}
+	host = strings.ToLower(host)
+	for _, g := range l.globs {
+		if g.Match(host) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchIP returns true if the IP address is in the list.
+func (l *hostList) matchIP(ip net.IP) bool {
+	for _, network := range l.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	// Glob patterns like * apply to addresses as well
+	for _, g := range l.globs {
+		if g.Match(ip.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	hostListsOnce sync.Once
+	allowedHosts  *hostList
+	blockedHosts  *hostList
+)
+
+func loadHostLists() {
+	hostListsOnce.Do(func() {
+		allowedHosts = newHostList(setting.Webhook.AllowedHosts)
+		blockedHosts = newHostList(setting.Webhook.BlockedHosts)
+	})
+}
+
+// isHostAllowed returns true if webhooks may be delivered to the host name or IP address.
+func isHostAllowed(host string) bool {
+	loadHostLists()
+	return allowedHosts.matchHost(host) || !blockedHosts.matchHost(host)
+}
+
+// isIPAllowed returns true if webhooks may be delivered to the IP address.
+func isIPAllowed(ip net.IP) bool {
+	loadHostLists()
+	return allowedHosts.matchIP(ip) || !blockedHosts.matchIP(ip)
+}
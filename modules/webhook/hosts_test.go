@@ -0,0 +1,148 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setHostLists(allowed, blocked []string) func() {
+	oldAllowed, oldBlocked := setting.Webhook.AllowedHosts, setting.Webhook.BlockedHosts
+	setting.Webhook.AllowedHosts, setting.Webhook.BlockedHosts = allowed, blocked
+	hostListsOnce = sync.Once{}
+	return func() {
+		setting.Webhook.AllowedHosts, setting.Webhook.BlockedHosts = oldAllowed, oldBlocked
+		hostListsOnce = sync.Once{}
+	}
+}
+
+func TestHostList(t *testing.T) {
+	l := newHostList([]string{"loopback", "private", "*.Internal.example.com", "192.0.2.1", "2001:db8::/32", "[invalid"})
+
+	var kases = map[string]bool{
+		"127.0.0.1":               true,
+		"::1":                     true,
+		"10.1.2.3":                true,
+		"172.20.0.1":              true,
+		"169.254.169.254":         true,
+		"fd00::1":                 true,
+		"192.0.2.1":               true,
+		"192.0.2.2":               false,
+		"2001:db8::1":             true,
+		"ci.internal.example.com": true,
+		"CI.INTERNAL.EXAMPLE.COM": true,
+		"internal.example.com":    false,
+		"8.8.8.8":                 false,
+		"gitea.io":                false,
+	}
+	for host, match := range kases {
+		assert.Equal(t, match, l.matchHost(host), host)
+	}
+
+	assert.True(t, newHostList([]string{"*"}).matchIP(net.ParseIP("8.8.8.8")))
+	assert.False(t, newHostList(nil).matchHost("localhost"))
+}
+
+func TestIsHostAllowed(t *testing.T) {
+	defer setHostLists([]string{"ci.lan", "10.0.0.1"}, []string{"private", "*.lan"})()
+
+	assert.True(t, isHostAllowed("gitea.io"))
+	assert.True(t, isHostAllowed("ci.lan"))
+	assert.False(t, isHostAllowed("db.lan"))
+	assert.True(t, isHostAllowed("10.0.0.1"))
+	assert.False(t, isHostAllowed("10.0.0.2"))
+	assert.False(t, isIPAllowed(net.ParseIP("192.168.1.1")))
+}
+
+func TestDialContextBlockedHosts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	addr := net.JoinHostPort("localhost", u.Port())
+
+	// localhost isn't blocked by name but resolves to a loopback address
+	reset := setHostLists(nil, []string{"loopback"})
+	_, err := dialContext(context.Background(), "tcp", addr, time.Second)
+	assert.True(t, errors.As(err, &ErrHostBlocked{}), "%v", err)
+
+	// connections to trusted proxies aren't checked
+	ctx := context.WithValue(context.Background(), deliveryProxyKey{}, &deliveryProxy{URL: u, Trusted: true})
+	conn, err := dialContext(ctx, "tcp", u.Host, time.Second)
+	if assert.NoError(t, err) {
+		conn.Close()
+	}
+
+	// but proxies of webhooks are
+	ctx = context.WithValue(context.Background(), deliveryProxyKey{}, &deliveryProxy{URL: u})
+	_, err = dialContext(ctx, "tcp", u.Host, time.Second)
+	assert.True(t, errors.As(err, &ErrHostBlocked{}), "%v", err)
+	reset()
+
+	defer setHostLists([]string{"localhost"}, []string{"loopback"})()
+	conn, err = dialContext(context.Background(), "tcp", addr, time.Second)
+	if assert.NoError(t, err) {
+		conn.Close()
+	}
+}
+
+func TestDeliverBlockedHost(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	defer setHostLists(nil, []string{"loopback"})()
+	defer func(client *http.Client) {
+		webhookHTTPClient = client
+	}(webhookHTTPClient)
+	webhookHTTPClient = http.DefaultClient
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	task := &models.HookTask{
+		RepoID:      1,
+		HookID:      1,
+		Type:        models.GITEA,
+		URL:         server.URL,
+		Payloader:   &api.PushPayload{},
+		HTTPMethod:  http.MethodPost,
+		ContentType: models.ContentTypeJSON,
+		EventType:   models.HookEventPush,
+	}
+	assert.NoError(t, models.CreateHookTask(task))
+	assert.Error(t, Deliver(task))
+
+	// deliveries to blocked hosts aren't retried
+	task = models.AssertExistsAndLoadBean(t, &models.HookTask{ID: task.ID}).(*models.HookTask)
+	assert.True(t, task.IsDelivered)
+	assert.False(t, task.IsSucceed)
+	assert.True(t, task.IsDeadLetter)
+	assert.Equal(t, 1, task.Attempts)
+}
+
+func TestGetDeliveryProxy(t *testing.T) {
+	req, err := http.NewRequest("POST", "http://localhost/hook", nil)
+	assert.NoError(t, err)
+
+	proxy, err := getDeliveryProxy(&models.HookTask{ProxyURL: "socks5://proxy.lan:1080"}, req)
+	assert.NoError(t, err)
+	assert.Equal(t, "socks5://proxy.lan:1080", proxy.URL.String())
+	assert.False(t, proxy.Trusted)
+
+	proxy, err = getDeliveryProxy(&models.HookTask{}, req)
+	assert.NoError(t, err)
+	assert.True(t, proxy.Trusted)
+}
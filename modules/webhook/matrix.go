@@ -28,6 +28,8 @@ type MatrixMeta struct {
 	Room          string `json:"room_id"`
 	AccessToken   string `json:"access_token"`
 	MessageType   int    `json:"message_type"`
+	// PlainText sends messages without their HTML formatted body
+	PlainText bool `json:"plain_text"`
 }
 
 var messageTypeText = map[int]string{
@@ -35,6 +37,26 @@ var messageTypeText = map[int]string{
 	2: "m.text",
 }
 
+// ToMatrixMessageType returns the message type of given name, 0 if it is unknown
+func ToMatrixMessageType(name string) int {
+	for typ, text := range messageTypeText {
+		if text == name {
+			return typ
+		}
+	}
+	return 0
+}
+
+// MatrixMessageTypeName returns the name of given message type, e.g. m.notice
+func MatrixMessageTypeName(typ int) string {
+	return messageTypeText[typ]
+}
+
+// GetMatrixRoomURL returns the URL to send messages to a Matrix room
+func GetMatrixRoomURL(homeserverURL, roomID string) string {
+	return fmt.Sprintf("%s/_matrix/client/r0/rooms/%s/send/m.room.message", homeserverURL, roomID)
+}
+
 // GetMatrixHook returns Matrix metadata
 func GetMatrixHook(w *models.Webhook) *MatrixMeta {
 	s := &MatrixMeta{}
@@ -65,8 +87,8 @@ func (p *MatrixPayloadUnsafe) safePayload() *MatrixPayloadSafe {
 type MatrixPayloadSafe struct {
 	Body          string               `json:"body"`
 	MsgType       string               `json:"msgtype"`
-	Format        string               `json:"format"`
-	FormattedBody string               `json:"formatted_body"`
+	Format        string               `json:"format,omitempty"`
+	FormattedBody string               `json:"formatted_body,omitempty"`
 	Commits       []*api.PayloadCommit `json:"io.gitea.commits,omitempty"`
 }
 
@@ -206,6 +228,8 @@ func getMatrixRepositoryPayload(p *api.RepositoryPayload, matrix *MatrixMeta) (*
 		text = fmt.Sprintf("[%s] Repository created by %s", repoLink, senderLink)
 	case api.HookRepoDeleted:
 		text = fmt.Sprintf("[%s] Repository deleted by %s", repoLink, senderLink)
+	case api.HookRepoRenamed, api.HookRepoTransferred, api.HookRepoTransferRequested, api.HookRepoTransferRejected, api.HookRepoArchived, api.HookRepoUnarchived:
+		text, _ = getRepositoryChangePayloadInfo(p, MatrixLinkFormatter, true)
 	}
 
 	return getMatrixPayloadUnsafe(text, nil, matrix), nil
@@ -250,9 +274,11 @@ func GetMatrixPayload(p api.Payloader, event models.HookEventType, meta string)
 func getMatrixPayloadUnsafe(text string, commits []*api.PayloadCommit, matrix *MatrixMeta) *MatrixPayloadUnsafe {
 	p := MatrixPayloadUnsafe{}
 	p.AccessToken = matrix.AccessToken
-	p.FormattedBody = text
 	p.Body = getMessageBody(text)
-	p.Format = "org.matrix.custom.html"
+	if !matrix.PlainText {
+		p.FormattedBody = text
+		p.Format = "org.matrix.custom.html"
+	}
 	p.MsgType = messageTypeText[matrix.MessageType]
 	p.Commits = commits
 	return &p
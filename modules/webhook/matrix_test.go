@@ -85,6 +85,30 @@ func TestMatrixPullRequestPayload(t *testing.T) {
 	assert.Equal(t, "[<a href=\"http://localhost:3000/test/repo\">test/repo</a>] Pull request opened: <a href=\"http://localhost:3000/test/repo/pulls/12\">#2 Fix bug</a> by <a href=\"https://try.gitea.io/user1\">user1</a>", pl.FormattedBody)
 }
 
+func TestMatrixPlainTextPayload(t *testing.T) {
+	p := pullReleaseTestPayload()
+
+	sl := &MatrixMeta{MessageType: 2, PlainText: true}
+
+	pl, err := getMatrixReleasePayload(p, sl)
+	require.Nil(t, err)
+	require.NotNil(t, pl)
+
+	assert.Equal(t, "m.text", pl.MsgType)
+	assert.Equal(t, "[[test/repo](http://localhost:3000/test/repo)] Release created: [v1.0](http://localhost:3000/test/repo/src/v1.0) by [user1](https://try.gitea.io/user1)", pl.Body)
+	assert.Empty(t, pl.Format)
+	assert.Empty(t, pl.FormattedBody)
+
+	data, err := pl.JSONPayload()
+	require.Nil(t, err)
+	assert.NotContains(t, string(data), "formatted_body")
+}
+
+func TestGetMatrixRoomURL(t *testing.T) {
+	assert.Equal(t, "https://matrix.example.com/_matrix/client/r0/rooms/!room:example.com/send/m.room.message",
+		GetMatrixRoomURL("https://matrix.example.com", "!room:example.com"))
+}
+
 func TestMatrixHookRequest(t *testing.T) {
 	h := &models.HookTask{
 		PayloadContent: `{
@@ -11,9 +11,38 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
 	api "code.gitea.io/gitea/modules/structs"
 )
 
+// Card formats the payloads of Microsoft Teams webhooks can be sent in
+const (
+	MSTeamsMessageCard  = "message_card"
+	MSTeamsAdaptiveCard = "adaptive_card"
+)
+
+// MSTeamsMeta contains the Microsoft Teams metadata
+type MSTeamsMeta struct {
+	CardType string `json:"card_type"`
+}
+
+// GetMSTeamsHook returns Microsoft Teams metadata
+func GetMSTeamsHook(w *models.Webhook) *MSTeamsMeta {
+	s := &MSTeamsMeta{}
+	if len(w.Meta) == 0 {
+		return s
+	}
+	if err := json.Unmarshal([]byte(w.Meta), s); err != nil {
+		log.Error("webhook.GetMSTeamsHook(%d): %v", w.ID, err)
+	}
+	return s
+}
+
+// IsValidMSTeamsCardType returns true if given name is a supported card type, empty being a MessageCard
+func IsValidMSTeamsCardType(name string) bool {
+	return name == "" || name == MSTeamsMessageCard || name == MSTeamsAdaptiveCard
+}
+
 type (
 	// MSTeamsFact for Fact Structure
 	MSTeamsFact struct {
@@ -55,6 +84,53 @@ type (
 	}
 )
 
+type (
+	// MSTeamsCardFact is a fact of an Adaptive Card FactSet
+	MSTeamsCardFact struct {
+		Title string `json:"title"`
+		Value string `json:"value"`
+	}
+
+	// MSTeamsCardElement is an element of the body of an Adaptive Card
+	MSTeamsCardElement struct {
+		Type     string            `json:"type"`
+		Text     string            `json:"text,omitempty"`
+		Size     string            `json:"size,omitempty"`
+		Weight   string            `json:"weight,omitempty"`
+		IsSubtle bool              `json:"isSubtle,omitempty"`
+		Wrap     bool              `json:"wrap,omitempty"`
+		Facts    []MSTeamsCardFact `json:"facts,omitempty"`
+	}
+
+	// MSTeamsCardAction is an action of an Adaptive Card
+	MSTeamsCardAction struct {
+		Type  string `json:"type"`
+		Title string `json:"title"`
+		URL   string `json:"url"`
+	}
+
+	// MSTeamsCard is an Adaptive Card
+	MSTeamsCard struct {
+		Schema  string               `json:"$schema"`
+		Type    string               `json:"type"`
+		Version string               `json:"version"`
+		Body    []MSTeamsCardElement `json:"body"`
+		Actions []MSTeamsCardAction  `json:"actions,omitempty"`
+	}
+
+	// MSTeamsCardAttachment is an attachment of a message
+	MSTeamsCardAttachment struct {
+		ContentType string       `json:"contentType"`
+		Content     *MSTeamsCard `json:"content"`
+	}
+
+	// MSTeamsCardPayload is a message with an Adaptive Card attached
+	MSTeamsCardPayload struct {
+		Type        string                  `json:"type"`
+		Attachments []MSTeamsCardAttachment `json:"attachments"`
+	}
+)
+
 // SetSecret sets the MSTeams secret
 func (p *MSTeamsPayload) SetSecret(_ string) {}
 
@@ -67,6 +143,82 @@ func (p *MSTeamsPayload) JSONPayload() ([]byte, error) {
 	return data, nil
 }
 
+// SetSecret sets the MSTeams secret
+func (p *MSTeamsCardPayload) SetSecret(_ string) {}
+
+// JSONPayload Marshals the MSTeamsCardPayload to json
+func (p *MSTeamsCardPayload) JSONPayload() ([]byte, error) {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return []byte{}, err
+	}
+	return data, nil
+}
+
+// toMSTeamsCardPayload converts a MessageCard into a message with an Adaptive Card attached
+func toMSTeamsCardPayload(m *MSTeamsPayload) *MSTeamsCardPayload {
+	card := &MSTeamsCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.2",
+		Body: []MSTeamsCardElement{
+			{
+				Type:   "TextBlock",
+				Text:   m.Title,
+				Size:   "Medium",
+				Weight: "Bolder",
+				Wrap:   true,
+			},
+		},
+	}
+	for _, section := range m.Sections {
+		if len(section.ActivityTitle) > 0 || len(section.ActivitySubtitle) > 0 {
+			card.Body = append(card.Body, MSTeamsCardElement{
+				Type:     "TextBlock",
+				Text:     strings.TrimSpace(section.ActivityTitle + " " + section.ActivitySubtitle),
+				IsSubtle: true,
+				Wrap:     true,
+			})
+		}
+		if len(section.Facts) > 0 {
+			facts := make([]MSTeamsCardFact, 0, len(section.Facts))
+			for _, fact := range section.Facts {
+				facts = append(facts, MSTeamsCardFact{Title: fact.Name, Value: fact.Value})
+			}
+			card.Body = append(card.Body, MSTeamsCardElement{
+				Type:  "FactSet",
+				Facts: facts,
+			})
+		}
+		if len(section.Text) > 0 {
+			card.Body = append(card.Body, MSTeamsCardElement{
+				Type: "TextBlock",
+				Text: section.Text,
+				Wrap: true,
+			})
+		}
+	}
+	for _, action := range m.PotentialAction {
+		for _, target := range action.Targets {
+			card.Actions = append(card.Actions, MSTeamsCardAction{
+				Type:  "Action.OpenUrl",
+				Title: action.Name,
+				URL:   target.URI,
+			})
+		}
+	}
+
+	return &MSTeamsCardPayload{
+		Type: "message",
+		Attachments: []MSTeamsCardAttachment{
+			{
+				ContentType: "application/vnd.microsoft.card.adaptive",
+				Content:     card,
+			},
+		},
+	}
+}
+
 func getMSTeamsCreatePayload(p *api.CreatePayload) (*MSTeamsPayload, error) {
 	// created tag/branch
 	refName := git.RefEndName(p.Ref)
@@ -466,6 +618,9 @@ func getMSTeamsRepositoryPayload(p *api.RepositoryPayload) (*MSTeamsPayload, err
 	case api.HookRepoDeleted:
 		title = fmt.Sprintf("[%s] Repository deleted", p.Repository.FullName)
 		color = yellowColor
+	case api.HookRepoRenamed, api.HookRepoTransferred, api.HookRepoTransferRequested, api.HookRepoTransferRejected, api.HookRepoArchived, api.HookRepoUnarchived:
+		title, color = getRepositoryChangePayloadInfo(p, noneLinkFormatter, false)
+		url = p.Repository.HTMLURL
 	}
 
 	return &MSTeamsPayload{
@@ -544,8 +699,27 @@ func getMSTeamsReleasePayload(p *api.ReleasePayload) (*MSTeamsPayload, error) {
 	}, nil
 }
 
-// GetMSTeamsPayload converts a MSTeams webhook into a MSTeamsPayload
-func GetMSTeamsPayload(p api.Payloader, event models.HookEventType, meta string) (*MSTeamsPayload, error) {
+// GetMSTeamsPayload converts a MSTeams webhook into a MSTeamsPayload, or into
+// a MSTeamsCardPayload if the webhook sends Adaptive Cards
+func GetMSTeamsPayload(p api.Payloader, event models.HookEventType, meta string) (api.Payloader, error) {
+	card, err := getMSTeamsMessageCard(p, event)
+	if err != nil {
+		return nil, err
+	}
+
+	msteams := &MSTeamsMeta{}
+	if len(meta) > 0 {
+		if err := json.Unmarshal([]byte(meta), msteams); err != nil {
+			return nil, fmt.Errorf("GetMSTeamsPayload meta json: %v", err)
+		}
+	}
+	if msteams.CardType == MSTeamsAdaptiveCard {
+		return toMSTeamsCardPayload(card), nil
+	}
+	return card, nil
+}
+
+func getMSTeamsMessageCard(p api.Payloader, event models.HookEventType) (*MSTeamsPayload, error) {
 	s := new(MSTeamsPayload)
 
 	switch event {
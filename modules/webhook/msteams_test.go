@@ -0,0 +1,60 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMSTeamsReleasePayload(t *testing.T) {
+	p := pullReleaseTestPayload()
+
+	pl, err := GetMSTeamsPayload(p, models.HookEventRelease, "")
+	require.Nil(t, err)
+	require.IsType(t, &MSTeamsPayload{}, pl)
+
+	card := pl.(*MSTeamsPayload)
+	assert.Equal(t, "MessageCard", card.Type)
+	assert.Equal(t, "[test/repo] Release created: v1.0", card.Title)
+	require.Len(t, card.Sections, 1)
+	assert.Equal(t, "v1.0", card.Sections[0].Facts[1].Value)
+}
+
+func TestMSTeamsAdaptiveCardPayload(t *testing.T) {
+	p := pullRequestTestPayload()
+
+	pl, err := GetMSTeamsPayload(p, models.HookEventPullRequest, `{"card_type":"adaptive_card"}`)
+	require.Nil(t, err)
+	require.IsType(t, &MSTeamsCardPayload{}, pl)
+
+	msg := pl.(*MSTeamsCardPayload)
+	assert.Equal(t, "message", msg.Type)
+	require.Len(t, msg.Attachments, 1)
+	assert.Equal(t, "application/vnd.microsoft.card.adaptive", msg.Attachments[0].ContentType)
+
+	card := msg.Attachments[0].Content
+	assert.Equal(t, "AdaptiveCard", card.Type)
+	require.NotEmpty(t, card.Body)
+	assert.Equal(t, "[test/repo] Pull request opened: #2 Fix bug", card.Body[0].Text)
+	assert.Equal(t, "user1", card.Body[1].Text)
+	assert.Equal(t, "FactSet", card.Body[2].Type)
+	assert.Equal(t, "fixes bug #2", card.Body[3].Text)
+	require.Len(t, card.Actions, 1)
+	assert.Equal(t, "Action.OpenUrl", card.Actions[0].Type)
+
+	data, err := pl.JSONPayload()
+	require.Nil(t, err)
+	assert.Contains(t, string(data), `"$schema": "http://adaptivecards.io/schemas/adaptive-card.json"`)
+}
+
+func TestMSTeamsPayloadInvalidMeta(t *testing.T) {
+	_, err := GetMSTeamsPayload(pullRequestTestPayload(), models.HookEventPullRequest, "{")
+	assert.Error(t, err)
+}
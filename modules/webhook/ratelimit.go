@@ -0,0 +1,168 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	"golang.org/x/time/rate"
+)
+
+// hostLimiter limits the rate of the deliveries to a host, it is kept
+// between batches of deliveries so that the rate holds over time.
+type hostLimiter struct {
+	limiter *rate.Limiter
+}
+
+// wait blocks until a delivery to the host is allowed or ctx is cancelled.
+func (l *hostLimiter) wait(ctx context.Context) error {
+	if l.limiter == nil {
+		return nil
+	}
+	return l.limiter.Wait(ctx)
+}
+
+var (
+	hostLimitersLock sync.Mutex
+	hostLimiters     = make(map[string]*hostLimiter)
+)
+
+// getHostLimiter returns the limiter of the deliveries to the given host.
+func getHostLimiter(host string) *hostLimiter {
+	hostLimitersLock.Lock()
+	defer hostLimitersLock.Unlock()
+
+	l, ok := hostLimiters[host]
+	if !ok {
+		l = &hostLimiter{}
+		if setting.Webhook.HostRateLimit > 0 {
+			l.limiter = rate.NewLimiter(rate.Limit(setting.Webhook.HostRateLimit), setting.Webhook.HostRateBurst)
+		}
+		hostLimiters[host] = l
+	}
+	return l
+}
+
+var (
+	deliveringLock sync.Mutex
+	delivering     = make(map[int64]bool)
+
+	deliverWorkersOnce sync.Once
+	deliverWorkers     chan struct{}
+)
+
+// claimTasks returns the given hook tasks which are not being delivered and marks them
+// as being delivered, as the hook queue workers and the retries run at the same time.
+func claimTasks(tasks []*models.HookTask) []*models.HookTask {
+	deliveringLock.Lock()
+	defer deliveringLock.Unlock()
+
+	claimed := make([]*models.HookTask, 0, len(tasks))
+	for _, t := range tasks {
+		if !delivering[t.ID] {
+			delivering[t.ID] = true
+			claimed = append(claimed, t)
+		}
+	}
+	return claimed
+}
+
+// releaseTasks marks the given hook tasks as not being delivered.
+func releaseTasks(tasks []*models.HookTask) {
+	deliveringLock.Lock()
+	defer deliveringLock.Unlock()
+
+	for _, t := range tasks {
+		delete(delivering, t.ID)
+	}
+}
+
+// getDeliverWorkers returns the semaphore limiting the number of deliveries at the same time.
+func getDeliverWorkers() chan struct{} {
+	deliverWorkersOnce.Do(func() {
+		deliverWorkers = make(chan struct{}, setting.Webhook.DeliverWorkers)
+	})
+	return deliverWorkers
+}
+
+// deliveryHost returns the host, including the port, a hook task is delivered to.
+func deliveryHost(t *models.HookTask) string {
+	u, err := url.Parse(t.URL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
+
+// deliverTasks delivers the given hook tasks with at most setting.Webhook.DeliverWorkers
+// deliveries at the same time. The tasks to a host are queued and delivered in order by
+// at most setting.Webhook.HostMaxConcurrency workers, at the rate allowed for the host.
+// Tasks already being delivered by another call are skipped.
+// It returns once all the tasks have been delivered, false if ctx has been cancelled.
+func deliverTasks(ctx context.Context, tasks []*models.HookTask) bool {
+	tasks = claimTasks(tasks)
+	defer releaseTasks(tasks)
+
+	var hosts []string
+	queues := make(map[string][]*models.HookTask)
+	for _, t := range tasks {
+		host := deliveryHost(t)
+		if _, ok := queues[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		queues[host] = append(queues[host], t)
+	}
+
+	workers := getDeliverWorkers()
+	var wg sync.WaitGroup
+	for _, host := range hosts {
+		queue := make(chan *models.HookTask, len(queues[host]))
+		for _, t := range queues[host] {
+			queue <- t
+		}
+		close(queue)
+
+		concurrency := setting.Webhook.HostMaxConcurrency
+		if concurrency <= 0 || concurrency > len(queues[host]) {
+			concurrency = len(queues[host])
+		}
+		limiter := getHostLimiter(host)
+		for i := 0; i < concurrency; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for t := range queue {
+					if err := limiter.wait(ctx); err != nil {
+						return
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case workers <- struct{}{}:
+					}
+					if err := Deliver(t); err != nil {
+						log.Error("deliver: %v", err)
+					}
+					<-workers
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	select {
+	case <-ctx.Done():
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createRateLimitTestTasks(t *testing.T, url string, n int) []*models.HookTask {
+	tasks := make([]*models.HookTask, 0, n)
+	for i := 0; i < n; i++ {
+		task := &models.HookTask{
+			RepoID:      1,
+			HookID:      1,
+			Type:        models.GITEA,
+			URL:         url,
+			Payloader:   &api.PushPayload{},
+			HTTPMethod:  http.MethodPost,
+			ContentType: models.ContentTypeJSON,
+			EventType:   models.HookEventPush,
+		}
+		assert.NoError(t, models.CreateHookTask(task))
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+func TestDeliveryHost(t *testing.T) {
+	assert.Equal(t, "example.com:8080", deliveryHost(&models.HookTask{URL: "http://Example.com:8080/hook"}))
+	assert.Equal(t, "example.com", deliveryHost(&models.HookTask{URL: "https://example.com/hook"}))
+	assert.Equal(t, "", deliveryHost(&models.HookTask{URL: "://"}))
+}
+
+func TestDeliverTasksHostConcurrency(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	defer func(client *http.Client, workers, concurrency int) {
+		webhookHTTPClient = client
+		setting.Webhook.DeliverWorkers = workers
+		setting.Webhook.HostMaxConcurrency = concurrency
+	}(webhookHTTPClient, setting.Webhook.DeliverWorkers, setting.Webhook.HostMaxConcurrency)
+	webhookHTTPClient = http.DefaultClient
+	setting.Webhook.DeliverWorkers = 4
+
+	var lock sync.Mutex
+	var current, max int
+	var deliveries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		current++
+		if current > max {
+			max = current
+		}
+		deliveries = append(deliveries, r.Header.Get("X-Gitea-Delivery"))
+		lock.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		lock.Lock()
+		current--
+		lock.Unlock()
+	}))
+	defer server.Close()
+
+	// hooks to a host are delivered in order when one at a time is allowed
+	setting.Webhook.HostMaxConcurrency = 1
+	tasks := createRateLimitTestTasks(t, server.URL, 4)
+	assert.True(t, deliverTasks(context.Background(), tasks))
+	assert.Equal(t, 1, max)
+	if assert.Len(t, deliveries, 4) {
+		for i, task := range tasks {
+			assert.Equal(t, task.UUID, deliveries[i])
+			assert.True(t, task.IsSucceed)
+		}
+	}
+
+	max = 0
+	setting.Webhook.HostMaxConcurrency = 2
+	assert.True(t, deliverTasks(context.Background(), createRateLimitTestTasks(t, server.URL, 6)))
+	assert.Equal(t, 2, max)
+}
+
+func TestDeliverTasksHostRateLimit(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	defer func(client *http.Client, rateLimit float64, burst int) {
+		webhookHTTPClient = client
+		setting.Webhook.HostRateLimit = rateLimit
+		setting.Webhook.HostRateBurst = burst
+		hostLimiters = make(map[string]*hostLimiter)
+	}(webhookHTTPClient, setting.Webhook.HostRateLimit, setting.Webhook.HostRateBurst)
+	webhookHTTPClient = http.DefaultClient
+	setting.Webhook.HostRateLimit = 20
+	setting.Webhook.HostRateBurst = 1
+	hostLimiters = make(map[string]*hostLimiter)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// hooks over the limit are queued, not dropped
+	tasks := createRateLimitTestTasks(t, server.URL, 3)
+	start := time.Now()
+	assert.True(t, deliverTasks(context.Background(), tasks))
+	assert.True(t, time.Since(start) >= 90*time.Millisecond)
+	for _, task := range tasks {
+		assert.True(t, task.IsSucceed)
+	}
+
+	// waiting for the limit stops when the context is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tasks = createRateLimitTestTasks(t, server.URL, 2)
+	assert.False(t, deliverTasks(ctx, tasks))
+	assert.False(t, tasks[1].IsDelivered)
+}
+
+func TestDeliverTasksSkipsTasksBeingDelivered(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	defer func(client *http.Client) {
+		webhookHTTPClient = client
+	}(webhookHTTPClient)
+	webhookHTTPClient = http.DefaultClient
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	tasks := createRateLimitTestTasks(t, server.URL, 2)
+	claimed := claimTasks(tasks[:1])
+	assert.True(t, deliverTasks(context.Background(), tasks))
+	assert.False(t, tasks[0].IsDelivered)
+	assert.True(t, tasks[1].IsSucceed)
+
+	releaseTasks(claimed)
+	assert.True(t, deliverTasks(context.Background(), tasks[:1]))
+	assert.True(t, tasks[0].IsSucceed)
+}
@@ -0,0 +1,231 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// GetSamplePayload returns a payload of the given event for test deliveries, it is built
+// from the repository, the user testing the webhook and the latest commit of the repository,
+// a fake commit is used if the repository is empty. The issue, pull request, comment and
+// release of the payload are samples, they don't exist in the repository.
+func GetSamplePayload(event models.HookEventType, repo *models.Repository, doer *models.User, commit *git.Commit) (api.Payloader, error) {
+	if commit == nil {
+		ghost := models.NewGhostUser()
+		commit = &git.Commit{
+			ID:            git.MustIDFromString(git.EmptySHA),
+			Author:        ghost.NewGitSig(),
+			Committer:     ghost.NewGitSig(),
+			CommitMessage: "This is a fake commit",
+		}
+	}
+
+	apiRepo := repo.APIFormat(models.AccessModeNone)
+	apiUser := doer.APIFormat()
+	now := time.Now()
+
+	issue := &api.Issue{
+		ID:        1,
+		URL:       repo.APIURL() + "/issues/1",
+		HTMLURL:   repo.HTMLURL() + "/issues/1",
+		Index:     1,
+		Poster:    apiUser,
+		Title:     "Sample issue",
+		Body:      "This is a sample issue",
+		Labels:    []*api.Label{{ID: 1, Name: "bug", Color: "ee0701", URL: repo.APIURL() + "/labels/1"}},
+		Milestone: &api.Milestone{ID: 1, Title: "v1.0", State: api.StateOpen},
+		Assignee:  apiUser,
+		Assignees: []*api.User{apiUser},
+		State:     api.StateOpen,
+		Created:   now,
+		Updated:   now,
+		Repo: &api.RepositoryMeta{
+			ID:       repo.ID,
+			Name:     repo.Name,
+			Owner:    repo.OwnerName,
+			FullName: repo.FullName(),
+		},
+	}
+	branch := &api.PRBranchInfo{
+		Name:       repo.DefaultBranch,
+		Ref:        repo.DefaultBranch,
+		Sha:        commit.ID.String(),
+		RepoID:     repo.ID,
+		Repository: apiRepo,
+	}
+	pull := &api.PullRequest{
+		ID:        1,
+		URL:       repo.HTMLURL() + "/pulls/1",
+		Index:     1,
+		Poster:    apiUser,
+		Title:     "Sample pull request",
+		Body:      "This is a sample pull request",
+		Labels:    issue.Labels,
+		Milestone: issue.Milestone,
+		Assignee:  apiUser,
+		Assignees: []*api.User{apiUser},
+		State:     api.StateOpen,
+		HTMLURL:   repo.HTMLURL() + "/pulls/1",
+		DiffURL:   repo.HTMLURL() + "/pulls/1.diff",
+		PatchURL:  repo.HTMLURL() + "/pulls/1.patch",
+		Mergeable: true,
+		Base:      branch,
+		Head:      branch,
+		MergeBase: commit.ID.String(),
+		Created:   &now,
+		Updated:   &now,
+	}
+	comment := &api.Comment{
+		ID:      1,
+		Poster:  apiUser,
+		Body:    "This is a sample comment",
+		Created: now,
+		Updated: now,
+	}
+
+	switch event {
+	case models.HookEventPush:
+		payloadCommit := &api.PayloadCommit{
+			ID:      commit.ID.String(),
+			Message: commit.Message(),
+			URL:     repo.HTMLURL() + "/commit/" + commit.ID.String(),
+			Author: &api.PayloadUser{
+				Name:  commit.Author.Name,
+				Email: commit.Author.Email,
+			},
+			Committer: &api.PayloadUser{
+				Name:  commit.Committer.Name,
+				Email: commit.Committer.Email,
+			},
+			Timestamp: commit.Author.When,
+		}
+		return &api.PushPayload{
+			Ref:        git.BranchPrefix + repo.DefaultBranch,
+			Before:     commit.ID.String(),
+			After:      commit.ID.String(),
+			Commits:    []*api.PayloadCommit{payloadCommit},
+			HeadCommit: payloadCommit,
+			Repo:       apiRepo,
+			Pusher:     apiUser,
+			Sender:     apiUser,
+		}, nil
+	case models.HookEventCreate:
+		return &api.CreatePayload{
+			Sha:     commit.ID.String(),
+			Ref:     repo.DefaultBranch,
+			RefType: "branch",
+			Repo:    apiRepo,
+			Sender:  apiUser,
+		}, nil
+	case models.HookEventDelete:
+		return &api.DeletePayload{
+			Ref:        repo.DefaultBranch,
+			RefType:    "branch",
+			PusherType: api.PusherTypeUser,
+			Repo:       apiRepo,
+			Sender:     apiUser,
+		}, nil
+	case models.HookEventFork:
+		return &api.ForkPayload{
+			Forkee: apiRepo,
+			Repo:   apiRepo,
+			Sender: apiUser,
+		}, nil
+	case models.HookEventIssues, models.HookEventIssueAssign, models.HookEventIssueLabel, models.HookEventIssueMilestone:
+		actions := map[models.HookEventType]api.HookIssueAction{
+			models.HookEventIssues:         api.HookIssueOpened,
+			models.HookEventIssueAssign:    api.HookIssueAssigned,
+			models.HookEventIssueLabel:     api.HookIssueLabelUpdated,
+			models.HookEventIssueMilestone: api.HookIssueMilestoned,
+		}
+		return &api.IssuePayload{
+			Action:     actions[event],
+			Index:      issue.Index,
+			Issue:      issue,
+			Repository: apiRepo,
+			Sender:     apiUser,
+		}, nil
+	case models.HookEventIssueComment, models.HookEventPullRequestComment:
+		isPull := event == models.HookEventPullRequestComment
+		if isPull {
+			issue.HTMLURL = pull.HTMLURL
+			issue.PullRequest = &api.PullRequestMeta{}
+			comment.PRURL = pull.HTMLURL
+		} else {
+			comment.IssueURL = issue.HTMLURL
+		}
+		comment.HTMLURL = fmt.Sprintf("%s#issuecomment-%d", issue.HTMLURL, comment.ID)
+		return &api.IssueCommentPayload{
+			Action:     api.HookIssueCommentCreated,
+			Issue:      issue,
+			Comment:    comment,
+			Repository: apiRepo,
+			Sender:     apiUser,
+			IsPull:     isPull,
+		}, nil
+	case models.HookEventPullRequest, models.HookEventPullRequestAssign, models.HookEventPullRequestLabel,
+		models.HookEventPullRequestMilestone, models.HookEventPullRequestSync:
+		actions := map[models.HookEventType]api.HookIssueAction{
+			models.HookEventPullRequest:          api.HookIssueOpened,
+			models.HookEventPullRequestAssign:    api.HookIssueAssigned,
+			models.HookEventPullRequestLabel:     api.HookIssueLabelUpdated,
+			models.HookEventPullRequestMilestone: api.HookIssueMilestoned,
+			models.HookEventPullRequestSync:      api.HookIssueSynchronized,
+		}
+		return &api.PullRequestPayload{
+			Action:      actions[event],
+			Index:       pull.Index,
+			PullRequest: pull,
+			Repository:  apiRepo,
+			Sender:      apiUser,
+		}, nil
+	case models.HookEventPullRequestReviewApproved, models.HookEventPullRequestReviewRejected, models.HookEventPullRequestReviewComment:
+		return &api.PullRequestPayload{
+			Action:      api.HookIssueReviewed,
+			Index:       pull.Index,
+			PullRequest: pull,
+			Repository:  apiRepo,
+			Sender:      apiUser,
+			Review: &api.ReviewPayload{
+				Type:    string(event),
+				Content: "This is a sample review",
+			},
+		}, nil
+	case models.HookEventRepository:
+		return &api.RepositoryPayload{
+			Action:     api.HookRepoCreated,
+			Repository: apiRepo,
+			Sender:     apiUser,
+		}, nil
+	case models.HookEventRelease:
+		return &api.ReleasePayload{
+			Action: api.HookReleasePublished,
+			Release: &api.Release{
+				ID:          1,
+				TagName:     "v1.0",
+				Target:      repo.DefaultBranch,
+				Title:       "v1.0",
+				Note:        "This is a sample release",
+				URL:         repo.APIURL() + "/releases/1",
+				HTMLURL:     repo.HTMLURL() + "/releases/tag/v1.0",
+				TarURL:      repo.HTMLURL() + "/archive/v1.0.tar.gz",
+				ZipURL:      repo.HTMLURL() + "/archive/v1.0.zip",
+				CreatedAt:   now,
+				PublishedAt: now,
+				Publisher:   apiUser,
+				Attachments: []*api.Attachment{},
+			},
+			Repository: apiRepo,
+			Sender:     apiUser,
+		}, nil
+	}
+	return nil, fmt.Errorf("no sample payload for event: %s", event)
+}
@@ -0,0 +1,49 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSamplePayload(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	w := &models.Webhook{HookEvent: &models.HookEvent{SendEverything: true}}
+
+	for _, event := range w.EventsArray() {
+		p, err := GetSamplePayload(models.HookEventType(event), repo, doer, nil)
+		if assert.NoError(t, err, event) {
+			_, err = p.JSONPayload()
+			assert.NoError(t, err)
+		}
+	}
+
+	p, err := GetSamplePayload(models.HookEventPush, repo, doer, nil)
+	assert.NoError(t, err)
+	push := p.(*api.PushPayload)
+	assert.Equal(t, "refs/heads/master", push.Ref)
+	assert.Len(t, push.Commits, 1)
+	assert.Equal(t, doer.Name, push.Pusher.UserName)
+
+	p, err = GetSamplePayload(models.HookEventPullRequestComment, repo, doer, nil)
+	assert.NoError(t, err)
+	assert.True(t, p.(*api.IssueCommentPayload).IsPull)
+
+	p, err = GetSamplePayload(models.HookEventPullRequestReviewApproved, repo, doer, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, api.HookIssueReviewed, p.(*api.PullRequestPayload).Action)
+	assert.Equal(t, "pull_request_review_approved", p.(*api.PullRequestPayload).Review.Type)
+
+	_, err = GetSamplePayload(models.HookEventUser, repo, doer, nil)
+	assert.Error(t, err)
+}
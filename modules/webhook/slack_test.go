@@ -86,3 +86,28 @@ func TestSlackPullRequestPayload(t *testing.T) {
 
 	assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] Pull request opened: <http://localhost:3000/test/repo/pulls/12|#2 Fix bug> by <https://try.gitea.io/user1|user1>", pl.Text)
 }
+
+func TestSlackRepositoryPayload(t *testing.T) {
+	p := repositoryRenamedTestPayload()
+
+	sl := &SlackMeta{
+		Username: p.Sender.UserName,
+	}
+
+	pl, err := getSlackRepositoryPayload(p, sl)
+	require.Nil(t, err)
+	require.NotNil(t, pl)
+
+	assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] Repository renamed from old-repo by <https://try.gitea.io/user1|user1>", pl.Text)
+
+	p.Action = api.HookRepoTransferred
+	p.OldOwner = "user2"
+	pl, err = getSlackRepositoryPayload(p, sl)
+	require.Nil(t, err)
+	assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] Repository transferred from user2 by <https://try.gitea.io/user1|user1>", pl.Text)
+
+	p.Action = api.HookRepoArchived
+	pl, err = getSlackRepositoryPayload(p, sl)
+	require.Nil(t, err)
+	assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] Repository archived by <https://try.gitea.io/user1|user1>", pl.Text)
+}
@@ -180,6 +180,11 @@ func getTelegramRepositoryPayload(p *api.RepositoryPayload) (*TelegramPayload, e
 		return &TelegramPayload{
 			Message: title,
 		}, nil
+	case api.HookRepoRenamed, api.HookRepoTransferred, api.HookRepoTransferRequested, api.HookRepoTransferRejected, api.HookRepoArchived, api.HookRepoUnarchived:
+		title, _ = getRepositoryChangePayloadInfo(p, htmlLinkFormatter, true)
+		return &TelegramPayload{
+			Message: title,
+		}, nil
 	}
 	return nil, nil
 }
@@ -14,14 +14,27 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
-	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/queue"
 	api "code.gitea.io/gitea/modules/structs"
-	"code.gitea.io/gitea/modules/sync"
+	"code.gitea.io/gitea/modules/util"
 	"github.com/gobwas/glob"
 )
 
-// hookQueue is a global queue of web hooks
-var hookQueue = sync.NewUniqueQueue(setting.Webhook.QueueLength)
+// hookQueue is a queue of the repositories having hook tasks to deliver, the hook tasks
+// of events which don't happen in a repository are queued with 0.
+var hookQueue queue.UniqueQueue
+
+// addToHookQueue queues the delivery of the hook tasks of a repository. As hook tasks are
+// stored in the database, the ones left undelivered by a shutdown are delivered on startup.
+func addToHookQueue(repoID int64) {
+	if hookQueue == nil {
+		// Deliveries are not running, e.g. in unit tests
+		return
+	}
+	if err := hookQueue.Push(repoID); err != nil && err != queue.ErrAlreadyInQueue {
+		log.Error("Unable to add repository %d to the hook queue: %v", repoID, err)
+	}
+}
 
 // getPayloadBranch returns branch for hook event, if applicable.
 func getPayloadBranch(p api.Payloader) string {
@@ -42,32 +55,74 @@ func getPayloadBranch(p api.Payloader) string {
 	return ""
 }
 
+// getPayloadTag returns tag for hook event, if applicable.
+func getPayloadTag(p api.Payloader) string {
+	switch pp := p.(type) {
+	case *api.CreatePayload:
+		if pp.RefType == "tag" {
+			return pp.Ref
+		}
+	case *api.DeletePayload:
+		if pp.RefType == "tag" {
+			return pp.Ref
+		}
+	case *api.PushPayload:
+		if strings.HasPrefix(pp.Ref, git.TagPrefix) {
+			return pp.Ref[len(git.TagPrefix):]
+		}
+	case *api.ReleasePayload:
+		if pp.Release != nil {
+			return pp.Release.TagName
+		}
+	}
+	return ""
+}
+
 // PrepareWebhook adds special webhook to task queue for given payload.
 func PrepareWebhook(w *models.Webhook, repo *models.Repository, event models.HookEventType, p api.Payloader) error {
-	if err := prepareWebhook(w, repo, event, p); err != nil {
+	if err := prepareWebhook(w, repo.ID, event, p); err != nil {
 		return err
 	}
 
-	go hookQueue.Add(repo.ID)
+	addToHookQueue(repo.ID)
 	return nil
 }
 
+// ReplayHookTask delivers the payload of the given hook task again
+func ReplayHookTask(t *models.HookTask) (*models.HookTask, error) {
+	replay, err := models.ReplayHookTask(t)
+	if err != nil {
+		return nil, err
+	}
+
+	addToHookQueue(replay.RepoID)
+	return replay, nil
+}
+
 func checkBranch(w *models.Webhook, branch string) bool {
-	if w.BranchFilter == "" || w.BranchFilter == "*" {
+	return matchRefFilter(w.BranchFilter, branch)
+}
+
+func checkTag(w *models.Webhook, tag string) bool {
+	return matchRefFilter(w.TagFilter, tag)
+}
+
+func matchRefFilter(filter, name string) bool {
+	if filter == "" || filter == "*" {
 		return true
 	}
 
-	g, err := glob.Compile(w.BranchFilter)
+	g, err := glob.Compile(filter)
 	if err != nil {
-		// should not really happen as BranchFilter is validated
-		log.Error("CheckBranch failed: %s", err)
+		// should not really happen as the filters are validated
+		log.Error("glob.Compile %q failed: %s", filter, err)
 		return false
 	}
 
-	return g.Match(branch)
+	return g.Match(name)
 }
 
-func prepareWebhook(w *models.Webhook, repo *models.Repository, event models.HookEventType, p api.Payloader) error {
+func prepareWebhook(w *models.Webhook, repoID int64, event models.HookEventType, p api.Payloader) error {
 	for _, e := range w.EventCheckers() {
 		if event == e.Type {
 			if !e.Has() {
@@ -85,6 +140,14 @@ func prepareWebhook(w *models.Webhook, repo *models.Repository, event models.Hoo
 		}
 	}
 
+	// Likewise the tag filter only applies to events of a tag.
+	if tag := getPayloadTag(p); tag != "" {
+		if !checkTag(w, tag) {
+			log.Info("Tag %q doesn't match tag filter %q, skipping", tag, w.TagFilter)
+			return nil
+		}
+	}
+
 	var payloader api.Payloader
 	var err error
 	// Use separate objects so modifications won't be made on payload on non-Gogs/Gitea type hooks.
@@ -124,8 +187,19 @@ func prepareWebhook(w *models.Webhook, repo *models.Repository, event models.Hoo
 		if err != nil {
 			return fmt.Errorf("GetMatrixPayload: %v", err)
 		}
+	case models.CUSTOM:
+		payloader, err = GetCustomPayload(p, event, w.Meta)
+		if err != nil {
+			return fmt.Errorf("GetCustomPayload: %v", err)
+		}
 	default:
-		p.SetSecret(w.Secret)
+		// The secret is only sent in the payload for the legacy signature,
+		// the payload is shared by all the webhooks of the repository.
+		if len(w.SignatureAlgorithm) == 0 {
+			p.SetSecret(w.Secret)
+		} else {
+			p.SetSecret("")
+		}
 		payloader = p
 	}
 
@@ -135,25 +209,34 @@ func prepareWebhook(w *models.Webhook, repo *models.Repository, event models.Hoo
 		if err != nil {
 			log.Error("prepareWebhooks.JSONPayload: %v", err)
 		}
-		sig := hmac.New(sha256.New, []byte(w.Secret))
-		_, err = sig.Write(data)
-		if err != nil {
-			log.Error("prepareWebhooks.sigWrite: %v", err)
+		if len(w.SignatureAlgorithm) > 0 {
+			signature, err = api.SignHookPayload(w.SignatureAlgorithm, w.Secret, data)
+			if err != nil {
+				log.Error("prepareWebhooks.SignHookPayload: %v", err)
+			}
+		} else {
+			sig := hmac.New(sha256.New, []byte(w.Secret))
+			_, err = sig.Write(data)
+			if err != nil {
+				log.Error("prepareWebhooks.sigWrite: %v", err)
+			}
+			signature = hex.EncodeToString(sig.Sum(nil))
 		}
-		signature = hex.EncodeToString(sig.Sum(nil))
 	}
 
 	if err = models.CreateHookTask(&models.HookTask{
-		RepoID:      repo.ID,
-		HookID:      w.ID,
-		Type:        w.HookTaskType,
-		URL:         w.URL,
-		Signature:   signature,
-		Payloader:   payloader,
-		HTTPMethod:  w.HTTPMethod,
-		ContentType: w.ContentType,
-		EventType:   event,
-		IsSSL:       w.IsSSL,
+		RepoID:             repoID,
+		HookID:             w.ID,
+		Type:               w.HookTaskType,
+		URL:                w.URL,
+		Signature:          signature,
+		SignatureAlgorithm: w.SignatureAlgorithm,
+		ProxyURL:           w.ProxyURL,
+		Payloader:          payloader,
+		HTTPMethod:         w.HTTPMethod,
+		ContentType:        w.ContentType,
+		EventType:          event,
+		IsSSL:              w.IsSSL,
 	}); err != nil {
 		return fmt.Errorf("CreateHookTask: %v", err)
 	}
@@ -166,7 +249,7 @@ func PrepareWebhooks(repo *models.Repository, event models.HookEventType, p api.
 		return err
 	}
 
-	go hookQueue.Add(repo.ID)
+	addToHookQueue(repo.ID)
 	return nil
 }
 
@@ -183,13 +266,23 @@ func prepareWebhooks(repo *models.Repository, event models.HookEventType, p api.
 		if err != nil {
 			return fmt.Errorf("GetActiveWebhooksByOrgID: %v", err)
 		}
-		ws = append(ws, orgHooks...)
+		if len(orgHooks) > 0 {
+			optedOut, err := models.GetOptedOutWebhookIDs(repo.ID)
+			if err != nil {
+				return fmt.Errorf("GetOptedOutWebhookIDs: %v", err)
+			}
+			for _, w := range orgHooks {
+				if !util.IsInt64InSlice(w.ID, optedOut) {
+					ws = append(ws, w)
+				}
+			}
+		}
 	}
 
 	// Add any admin-defined system webhooks
-	systemHooks, err := models.GetSystemWebhooks()
+	systemHooks, err := models.GetActiveSystemWebhooks()
 	if err != nil {
-		return fmt.Errorf("GetSystemWebhooks: %v", err)
+		return fmt.Errorf("GetActiveSystemWebhooks: %v", err)
 	}
 	ws = append(ws, systemHooks...)
 
@@ -198,9 +291,33 @@ func prepareWebhooks(repo *models.Repository, event models.HookEventType, p api.
 	}
 
 	for _, w := range ws {
-		if err = prepareWebhook(w, repo, event, p); err != nil {
+		if err = prepareWebhook(w, repo.ID, event, p); err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// PrepareSystemWebhooks adds the system webhooks to task queue for given payload of an
+// event which doesn't happen in a repository, e.g. the creation of a user. Chat services
+// can't format these events, they are only sent to Gitea, Gogs and custom webhooks.
+func PrepareSystemWebhooks(event models.HookEventType, p api.Payloader) error {
+	ws, err := models.GetActiveSystemWebhooks()
+	if err != nil {
+		return fmt.Errorf("GetActiveSystemWebhooks: %v", err)
+	}
+
+	for _, w := range ws {
+		switch w.HookTaskType {
+		case models.GITEA, models.GOGS, models.CUSTOM:
+		default:
+			continue
+		}
+		if err = prepareWebhook(w, 0, event, p); err != nil {
+			return err
+		}
+	}
+
+	addToHookQueue(0)
+	return nil
+}
@@ -5,6 +5,7 @@
 package webhook
 
 import (
+	"strings"
 	"testing"
 
 	"code.gitea.io/gitea/models"
@@ -40,6 +41,37 @@ func TestPrepareWebhooks(t *testing.T) {
 	}
 }
 
+func TestPrepareWebhooksOrgOptOut(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	w := &models.Webhook{
+		OrgID:        3,
+		URL:          "http://www.example.com/org",
+		ContentType:  models.ContentTypeJSON,
+		HookEvent:    &models.HookEvent{PushOnly: true},
+		IsActive:     true,
+		HookTaskType: models.GITEA,
+	}
+	assert.NoError(t, w.UpdateEvent())
+	assert.NoError(t, models.CreateWebhook(w))
+
+	// the organization webhook receives the events of every repository of the organization
+	// but the ones opted out
+	assert.NoError(t, models.SetWebhookOptOut(5, w.ID, true))
+	for _, repoID := range []int64{3, 5, 32} {
+		repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: repoID}).(*models.Repository)
+		assert.NoError(t, PrepareWebhooks(repo, models.HookEventPush, &api.PushPayload{}))
+	}
+	models.AssertExistsAndLoadBean(t, &models.HookTask{RepoID: 3, HookID: w.ID})
+	models.AssertNotExistsBean(t, &models.HookTask{RepoID: 5, HookID: w.ID})
+	models.AssertExistsAndLoadBean(t, &models.HookTask{RepoID: 32, HookID: w.ID})
+
+	assert.NoError(t, models.SetWebhookOptOut(5, w.ID, false))
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 5}).(*models.Repository)
+	assert.NoError(t, PrepareWebhooks(repo, models.HookEventPush, &api.PushPayload{}))
+	models.AssertExistsAndLoadBean(t, &models.HookTask{RepoID: 5, HookID: w.ID})
+}
+
 func TestPrepareWebhooksBranchFilterMatch(t *testing.T) {
 	assert.NoError(t, models.PrepareTestDatabase())
 
@@ -74,6 +106,75 @@ func TestPrepareWebhooksBranchFilterNoMatch(t *testing.T) {
 	}
 }
 
+func TestPrepareWebhooksTagFilter(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	w := models.AssertExistsAndLoadBean(t, &models.Webhook{ID: 4}).(*models.Webhook)
+	w.TagFilter = "v*"
+	assert.NoError(t, w.UpdateEvent())
+	assert.NoError(t, models.UpdateWebhook(w))
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 2}).(*models.Repository)
+	hookTask := &models.HookTask{RepoID: repo.ID, HookID: 4, EventType: models.HookEventPush}
+	assert.NoError(t, PrepareWebhooks(repo, models.HookEventPush, &api.PushPayload{Ref: "refs/tags/nightly"}))
+	models.AssertNotExistsBean(t, hookTask)
+
+	// the branch filter doesn't apply to tags
+	assert.NoError(t, PrepareWebhooks(repo, models.HookEventPush, &api.PushPayload{Ref: "refs/tags/v1.0"}))
+	models.AssertExistsAndLoadBean(t, hookTask)
+}
+
+func TestPrepareWebhooksSignatureAlgorithm(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	w := models.AssertExistsAndLoadBean(t, &models.Webhook{ID: 4}).(*models.Webhook)
+	w.Secret = "s3cr3t"
+	w.SignatureAlgorithm = api.HookSignatureSHA256
+	assert.NoError(t, models.UpdateWebhook(w))
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 2}).(*models.Repository)
+	assert.NoError(t, PrepareWebhooks(repo, models.HookEventPush, &api.PushPayload{Ref: "refs/heads/master"}))
+	hookTask := models.AssertExistsAndLoadBean(t, &models.HookTask{RepoID: repo.ID, HookID: 4, EventType: models.HookEventPush}).(*models.HookTask)
+	assert.Equal(t, api.HookSignatureSHA256, hookTask.SignatureAlgorithm)
+	assert.NotContains(t, hookTask.PayloadContent, "s3cr3t")
+	assert.True(t, strings.HasPrefix(hookTask.Signature, "sha256="))
+	assert.True(t, api.VerifyHookSignature("s3cr3t", []byte(hookTask.PayloadContent), hookTask.Signature))
+	assert.False(t, api.VerifyHookSignature("wrong", []byte(hookTask.PayloadContent), hookTask.Signature))
+	assert.False(t, api.VerifyHookSignature("s3cr3t", []byte(hookTask.PayloadContent), strings.TrimPrefix(hookTask.Signature, "sha256=")))
+}
+
+func TestPrepareSystemWebhooks(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	hooks := []*models.Webhook{
+		{URL: "http://example.com/gitea", HookTaskType: models.GITEA, HookEvent: &models.HookEvent{ChooseEvents: true, HookEvents: models.HookEvents{User: true}}},
+		{URL: "http://example.com/slack", HookTaskType: models.SLACK, HookEvent: &models.HookEvent{SendEverything: true}},
+		{URL: "http://example.com/push", HookTaskType: models.GITEA, HookEvent: &models.HookEvent{ChooseEvents: true, HookEvents: models.HookEvents{Push: true}}},
+	}
+	for _, w := range hooks {
+		w.IsActive = true
+		w.IsSystemWebhook = true
+		w.ContentType = models.ContentTypeJSON
+		assert.NoError(t, w.UpdateEvent())
+		assert.NoError(t, models.CreateWebhook(w))
+	}
+
+	assert.NoError(t, PrepareSystemWebhooks(models.HookEventUser, &api.UserPayload{Action: api.HookAccountCreated}))
+	models.AssertExistsAndLoadBean(t, &models.HookTask{RepoID: 0, HookID: hooks[0].ID, EventType: models.HookEventUser})
+	// chat services can't format user events
+	models.AssertNotExistsBean(t, &models.HookTask{HookID: hooks[1].ID})
+	models.AssertNotExistsBean(t, &models.HookTask{HookID: hooks[2].ID})
+}
+
+func TestGetPayloadTag(t *testing.T) {
+	assert.Equal(t, "v1.0", getPayloadTag(&api.PushPayload{Ref: "refs/tags/v1.0"}))
+	assert.Equal(t, "", getPayloadTag(&api.PushPayload{Ref: "refs/heads/master"}))
+	assert.Equal(t, "v1.0", getPayloadTag(&api.CreatePayload{Ref: "v1.0", RefType: "tag"}))
+	assert.Equal(t, "", getPayloadTag(&api.DeletePayload{Ref: "master", RefType: "branch"}))
+	assert.Equal(t, "v1.0", getPayloadTag(&api.ReleasePayload{Release: &api.Release{TagName: "v1.0"}}))
+	assert.Equal(t, "", getPayloadTag(&api.IssuePayload{}))
+}
+
 // TODO TestHookTask_deliver
 
 // TODO TestDeliverHooks
@@ -0,0 +1,182 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package graphql
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/graphql-go/graphql"
+)
+
+const cursorPrefix = "cursor:"
+
+// encodeCursor returns the opaque cursor of the item at the given offset of a list
+func encodeCursor(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+// decodeCursor returns the offset of the item of the cursor
+func decodeCursor(cursor string) (int, error) {
+	data, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(data), cursorPrefix) {
+		return 0, fmt.Errorf("invalid cursor: %s", cursor)
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(data), cursorPrefix))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid cursor: %s", cursor)
+	}
+	return offset, nil
+}
+
+type pageInfo struct {
+	HasNextPage     bool   `json:"has_next_page"`
+	HasPreviousPage bool   `json:"has_previous_page"`
+	StartCursor     string `json:"start_cursor"`
+	EndCursor       string `json:"end_cursor"`
+}
+
+type edge struct {
+	Cursor string      `json:"cursor"`
+	Node   interface{} `json:"node"`
+}
+
+type connection struct {
+	Edges      []*edge       `json:"edges"`
+	Nodes      []interface{} `json:"nodes"`
+	PageInfo   *pageInfo     `json:"page_info"`
+	TotalCount int64         `json:"total_count"`
+}
+
+// newConnection returns the connection of the nodes starting at the given offset of a list
+func newConnection(nodes []interface{}, offset int, totalCount int64) *connection {
+	c := &connection{
+		Edges: make([]*edge, len(nodes)),
+		Nodes: nodes,
+		PageInfo: &pageInfo{
+			HasNextPage:     int64(offset+len(nodes)) < totalCount,
+			HasPreviousPage: offset > 0,
+		},
+		TotalCount: totalCount,
+	}
+	for i, node := range nodes {
+		c.Edges[i] = &edge{
+			Cursor: encodeCursor(offset + i),
+			Node:   node,
+		}
+	}
+	if len(c.Edges) > 0 {
+		c.PageInfo.StartCursor = c.Edges[0].Cursor
+		c.PageInfo.EndCursor = c.Edges[len(c.Edges)-1].Cursor
+	}
+	return c
+}
+
+var pageInfoType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "PageInfo",
+	Fields: graphql.Fields{
+		"has_next_page":     &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		"has_previous_page": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		"start_cursor":      &graphql.Field{Type: graphql.String},
+		"end_cursor":        &graphql.Field{Type: graphql.String},
+	},
+})
+
+// newConnectionType returns the type of the connections to the nodes of the given type
+func newConnectionType(nodeType *graphql.Object) *graphql.Object {
+	edgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: nodeType.Name() + "Edge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"node":   &graphql.Field{Type: nodeType},
+		},
+	})
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: nodeType.Name() + "Connection",
+		Fields: graphql.Fields{
+			"edges":       &graphql.Field{Type: graphql.NewList(edgeType)},
+			"nodes":       &graphql.Field{Type: graphql.NewList(nodeType)},
+			"page_info":   &graphql.Field{Type: graphql.NewNonNull(pageInfoType)},
+			"total_count": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+}
+
+// connectionArgs returns the pagination arguments of a connection with the given other arguments
+func connectionArgs(args graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+	if args == nil {
+		args = graphql.FieldConfigArgument{}
+	}
+	args["first"] = &graphql.ArgumentConfig{
+		Type:        graphql.Int,
+		Description: "Number of items to return",
+	}
+	args["after"] = &graphql.ArgumentConfig{
+		Type:        graphql.String,
+		Description: "Cursor of the item to return the items after",
+	}
+	return args
+}
+
+// getPagination returns the offset and the number of the items to return from the arguments of a connection
+func getPagination(args map[string]interface{}) (offset, limit int, err error) {
+	limit = setting.API.DefaultPagingNum
+	if first, ok := args["first"].(int); ok {
+		if first < 0 {
+			return 0, 0, fmt.Errorf("first must not be negative")
+		}
+		limit = first
+	}
+	if limit > setting.API.MaxResponseItems {
+		limit = setting.API.MaxResponseItems
+	}
+	if after, ok := args["after"].(string); ok && after != "" {
+		if offset, err = decodeCursor(after); err != nil {
+			return 0, 0, err
+		}
+		offset++
+	}
+	return offset, limit, nil
+}
+
+// findRange calls find with the pages covering limit items from the given offset, as the
+// models paginate by pages, and returns the number of the found items to skip.
+// find returns the number of items it found.
+func findRange(offset, limit int, find func(opts models.ListOptions) (int, error)) (int, error) {
+	if limit == 0 {
+		return 0, nil
+	}
+	page := offset/limit + 1
+	n, err := find(models.ListOptions{Page: page, PageSize: limit})
+	if err != nil {
+		return 0, err
+	}
+	if offset%limit != 0 && n == limit {
+		if _, err = find(models.ListOptions{Page: page + 1, PageSize: limit}); err != nil {
+			return 0, err
+		}
+	}
+	return offset % limit, nil
+}
+
+// sliceRange returns at most limit nodes after the first skip ones
+func sliceRange(nodes []interface{}, skip, limit int) []interface{} {
+	if skip > len(nodes) {
+		skip = len(nodes)
+	}
+	nodes = nodes[skip:]
+	if len(nodes) > limit {
+		nodes = nodes[:limit]
+	}
+	if nodes == nil {
+		return []interface{}{}
+	}
+	return nodes
+}
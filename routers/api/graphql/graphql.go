@@ -0,0 +1,101 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package graphql implements the GraphQL API, it exposes the repositories with their
+// issues, pull requests, milestones and releases. The fields are named like the ones
+// of the REST API and the lists are paginated with cursors.
+package graphql
+
+import (
+	go_context "context"
+	"encoding/json"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+
+	"github.com/graphql-go/graphql"
+)
+
+type doerKey struct{}
+
+// getDoer returns the user sending the query, nil if anonymous
+func getDoer(ctx go_context.Context) *models.User {
+	doer, _ := ctx.Value(doerKey{}).(*models.User)
+	return doer
+}
+
+var schema = mustNewSchema()
+
+func mustNewSchema() graphql.Schema {
+	s, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"repository": &graphql.Field{
+					Type:        repositoryType,
+					Description: "A repository by its owner and name, null if it doesn't exist or isn't visible",
+					Args: graphql.FieldConfigArgument{
+						"owner": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+						"name":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					},
+					Resolve: resolveRepository,
+				},
+				"viewer": &graphql.Field{
+					Type:        userType,
+					Description: "The user sending the query, null if anonymous",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						if doer := getDoer(p.Context); doer != nil {
+							return doer.APIFormat(), nil
+						}
+						return nil, nil
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+type queryOptions struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Query executes a GraphQL query
+func Query(ctx *context.APIContext) {
+	var opts queryOptions
+	if ctx.Req.Method == http.MethodGet {
+		opts.Query = ctx.Query("query")
+		opts.OperationName = ctx.Query("operationName")
+		if variables := ctx.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &opts.Variables); err != nil {
+				ctx.Error(http.StatusBadRequest, "", err)
+				return
+			}
+		}
+	} else {
+		body, err := ctx.Req.Body().Bytes()
+		if err != nil {
+			ctx.Error(http.StatusBadRequest, "", err)
+			return
+		}
+		if err := json.Unmarshal(body, &opts); err != nil {
+			ctx.Error(http.StatusBadRequest, "", err)
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, graphql.Do(graphql.Params{
+		Schema:         schema,
+		RequestString:  opts.Query,
+		OperationName:  opts.OperationName,
+		VariableValues: opts.Variables,
+		Context:        go_context.WithValue(ctx.Req.Context(), doerKey{}, ctx.User),
+	}))
+}
@@ -0,0 +1,174 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package graphql
+
+import (
+	go_context "context"
+	"encoding/json"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+)
+
+func query(t *testing.T, doer *models.User, requestString string, variables map[string]interface{}) map[string]interface{} {
+	result := graphql.Do(graphql.Params{
+		Schema:         schema,
+		RequestString:  requestString,
+		VariableValues: variables,
+		Context:        go_context.WithValue(go_context.Background(), doerKey{}, doer),
+	})
+	assert.Empty(t, result.Errors)
+
+	// round trip the result to get plain JSON values
+	data, err := json.Marshal(result.Data)
+	assert.NoError(t, err)
+	var res map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &res))
+	return res
+}
+
+func TestCursor(t *testing.T) {
+	offset, err := decodeCursor(encodeCursor(42))
+	assert.NoError(t, err)
+	assert.Equal(t, 42, offset)
+
+	_, err = decodeCursor("invalid")
+	assert.Error(t, err)
+	_, err = decodeCursor(encodeCursor(-1))
+	assert.Error(t, err)
+}
+
+func TestFindRange(t *testing.T) {
+	items := []int{0, 1, 2, 3, 4, 5, 6}
+	find := func(found *[]interface{}) func(opts models.ListOptions) (int, error) {
+		return func(opts models.ListOptions) (int, error) {
+			n := 0
+			for i := (opts.Page - 1) * opts.PageSize; i < len(items) && n < opts.PageSize; i++ {
+				*found = append(*found, items[i])
+				n++
+			}
+			return n, nil
+		}
+	}
+
+	for _, c := range []struct {
+		offset, limit int
+		expected      []interface{}
+	}{
+		{0, 3, []interface{}{0, 1, 2}},
+		{3, 3, []interface{}{3, 4, 5}},
+		{2, 3, []interface{}{2, 3, 4}},
+		{5, 3, []interface{}{5, 6}},
+		{8, 3, []interface{}{}},
+		{1, 0, []interface{}{}},
+	} {
+		var found []interface{}
+		skip, err := findRange(c.offset, c.limit, find(&found))
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, sliceRange(found, skip, c.limit), "offset %d, limit %d", c.offset, c.limit)
+	}
+}
+
+func TestQueryRepository(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	res := query(t, nil, `{
+		repository(owner: "user2", name: "repo1") {
+			full_name
+			latest_release {
+				tag_name
+				author { login }
+				assets { name }
+			}
+			milestones(state: "all") {
+				total_count
+				nodes { title state }
+			}
+			issue(number: 1) { title state labels { name } user { login } }
+		}
+	}`, nil)
+	repo := res["repository"].(map[string]interface{})
+	assert.Equal(t, "user2/repo1", repo["full_name"])
+
+	release := repo["latest_release"].(map[string]interface{})
+	assert.Equal(t, "v1.1", release["tag_name"])
+	assert.Equal(t, "user2", release["author"].(map[string]interface{})["login"])
+	assert.Len(t, release["assets"], 1)
+
+	milestones := repo["milestones"].(map[string]interface{})
+	assert.EqualValues(t, 3, milestones["total_count"])
+	assert.Len(t, milestones["nodes"], 3)
+
+	issue := repo["issue"].(map[string]interface{})
+	assert.Equal(t, "issue1", issue["title"])
+	assert.Equal(t, "open", issue["state"])
+	assert.Len(t, issue["labels"], 1)
+	assert.Equal(t, "user1", issue["user"].(map[string]interface{})["login"])
+
+	// a pull request isn't an issue
+	res = query(t, nil, `{ repository(owner: "user2", name: "repo1") { issue(number: 2) { title } } }`, nil)
+	assert.Nil(t, res["repository"].(map[string]interface{})["issue"])
+}
+
+func TestQueryRepositoryNotVisible(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	const q = `{ repository(owner: "user2", name: "repo2") { full_name } }`
+	assert.Nil(t, query(t, nil, q, nil)["repository"])
+	assert.Nil(t, query(t, models.AssertExistsAndLoadBean(t, &models.User{ID: 4}).(*models.User), q, nil)["repository"])
+
+	repo := query(t, models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User), q, nil)["repository"]
+	assert.Equal(t, "user2/repo2", repo.(map[string]interface{})["full_name"])
+
+	assert.Nil(t, query(t, nil, `{ repository(owner: "user2", name: "missing") { full_name } }`, nil)["repository"])
+}
+
+func TestQueryPullRequestsPagination(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	const q = `query($after: String) {
+		repository(owner: "user2", name: "repo1") {
+			pull_requests(first: 2, after: $after) {
+				total_count
+				page_info { has_next_page has_previous_page end_cursor }
+				edges { cursor node { number head { ref } } }
+			}
+		}
+	}`
+	getPulls := func(res map[string]interface{}) map[string]interface{} {
+		return res["repository"].(map[string]interface{})["pull_requests"].(map[string]interface{})
+	}
+
+	pulls := getPulls(query(t, nil, q, nil))
+	assert.EqualValues(t, 3, pulls["total_count"])
+	assert.Len(t, pulls["edges"], 2)
+	pageInfo := pulls["page_info"].(map[string]interface{})
+	assert.Equal(t, true, pageInfo["has_next_page"])
+	assert.Equal(t, false, pageInfo["has_previous_page"])
+
+	numbers := map[float64]bool{}
+	for _, e := range pulls["edges"].([]interface{}) {
+		numbers[e.(map[string]interface{})["node"].(map[string]interface{})["number"].(float64)] = true
+	}
+
+	pulls = getPulls(query(t, nil, q, map[string]interface{}{"after": pageInfo["end_cursor"]}))
+	assert.Len(t, pulls["edges"], 1)
+	pageInfo = pulls["page_info"].(map[string]interface{})
+	assert.Equal(t, false, pageInfo["has_next_page"])
+	assert.Equal(t, true, pageInfo["has_previous_page"])
+	numbers[pulls["edges"].([]interface{})[0].(map[string]interface{})["node"].(map[string]interface{})["number"].(float64)] = true
+	assert.Len(t, numbers, 3)
+}
+
+func TestQueryViewer(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	assert.Nil(t, query(t, nil, `{ viewer { login } }`, nil)["viewer"])
+	viewer := query(t, models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User), `{ viewer { login } }`, nil)["viewer"]
+	assert.Equal(t, "user2", viewer.(map[string]interface{})["login"])
+}
@@ -0,0 +1,393 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package graphql
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/graphql-go/graphql"
+)
+
+// repository is a repository with the permission of the user sending the query,
+// its fields are resolved from its API format.
+type repository struct {
+	repo *models.Repository
+	perm models.Permission
+	api  *api.Repository
+}
+
+// Resolve implements graphql.FieldResolver
+func (r *repository) Resolve(p graphql.ResolveParams) (interface{}, error) {
+	p.Source = r.api
+	return graphql.DefaultResolveFn(p)
+}
+
+var stateArg = &graphql.ArgumentConfig{
+	Type:         graphql.String,
+	Description:  "State of the items to return, one of open, closed or all",
+	DefaultValue: string(api.StateOpen),
+}
+
+var repositoryType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Repository",
+	Fields: graphql.Fields{
+		"id":                &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"owner":             &graphql.Field{Type: userType},
+		"name":              &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"full_name":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"description":       &graphql.Field{Type: graphql.String},
+		"empty":             &graphql.Field{Type: graphql.Boolean},
+		"private":           &graphql.Field{Type: graphql.Boolean},
+		"fork":              &graphql.Field{Type: graphql.Boolean},
+		"mirror":            &graphql.Field{Type: graphql.Boolean},
+		"archived":          &graphql.Field{Type: graphql.Boolean},
+		"html_url":          &graphql.Field{Type: graphql.String},
+		"ssh_url":           &graphql.Field{Type: graphql.String},
+		"clone_url":         &graphql.Field{Type: graphql.String},
+		"website":           &graphql.Field{Type: graphql.String},
+		"default_branch":    &graphql.Field{Type: graphql.String},
+		"stars_count":       &graphql.Field{Type: graphql.Int},
+		"forks_count":       &graphql.Field{Type: graphql.Int},
+		"watchers_count":    &graphql.Field{Type: graphql.Int},
+		"open_issues_count": &graphql.Field{Type: graphql.Int},
+		"open_pr_counter":   &graphql.Field{Type: graphql.Int},
+		"release_counter":   &graphql.Field{Type: graphql.Int},
+		"created_at":        &graphql.Field{Type: graphql.DateTime},
+		"updated_at":        &graphql.Field{Type: graphql.DateTime},
+		"issues": &graphql.Field{
+			Type:    issueConnectionType,
+			Args:    connectionArgs(graphql.FieldConfigArgument{"state": stateArg}),
+			Resolve: resolveIssues,
+		},
+		"issue": &graphql.Field{
+			Type: issueType,
+			Args: graphql.FieldConfigArgument{
+				"number": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+			},
+			Resolve: resolveIssue,
+		},
+		"pull_requests": &graphql.Field{
+			Type:    pullRequestConnectionType,
+			Args:    connectionArgs(graphql.FieldConfigArgument{"state": stateArg}),
+			Resolve: resolvePullRequests,
+		},
+		"pull_request": &graphql.Field{
+			Type: pullRequestType,
+			Args: graphql.FieldConfigArgument{
+				"number": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+			},
+			Resolve: resolvePullRequest,
+		},
+		"milestones": &graphql.Field{
+			Type:    milestoneConnectionType,
+			Args:    connectionArgs(graphql.FieldConfigArgument{"state": stateArg}),
+			Resolve: resolveMilestones,
+		},
+		"releases": &graphql.Field{
+			Type:    releaseConnectionType,
+			Args:    connectionArgs(nil),
+			Resolve: resolveReleases,
+		},
+		"release": &graphql.Field{
+			Type: releaseType,
+			Args: graphql.FieldConfigArgument{
+				"tag": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+			},
+			Resolve: resolveRelease,
+		},
+		"latest_release": &graphql.Field{
+			Type:        releaseType,
+			Description: "The latest published release which isn't a pre-release",
+			Resolve:     resolveLatestRelease,
+		},
+	},
+})
+
+func resolveRepository(p graphql.ResolveParams) (interface{}, error) {
+	repo, err := models.GetRepositoryByOwnerAndName(p.Args["owner"].(string), p.Args["name"].(string))
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	perm, err := models.GetUserRepoPermission(repo, getDoer(p.Context))
+	if err != nil {
+		return nil, err
+	}
+	if !perm.HasAccess() {
+		return nil, nil
+	}
+	return &repository{
+		repo: repo,
+		perm: perm,
+		api:  repo.APIFormat(perm.AccessMode),
+	}, nil
+}
+
+// getStateArg returns the state argument, closed and all are the only other states than open
+func getStateArg(args map[string]interface{}) api.StateType {
+	switch state, _ := args["state"].(string); api.StateType(state) {
+	case api.StateClosed, api.StateAll:
+		return api.StateType(state)
+	}
+	return api.StateOpen
+}
+
+func stateIsClosed(state api.StateType) util.OptionalBool {
+	switch state {
+	case api.StateClosed:
+		return util.OptionalBoolTrue
+	case api.StateAll:
+		return util.OptionalBoolNone
+	}
+	return util.OptionalBoolFalse
+}
+
+func resolveIssues(p graphql.ResolveParams) (interface{}, error) {
+	r := p.Source.(*repository)
+	if !r.perm.CanRead(models.UnitTypeIssues) {
+		return nil, nil
+	}
+	offset, limit, err := getPagination(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := models.IssuesOptions{
+		RepoIDs:  []int64{r.repo.ID},
+		IsClosed: stateIsClosed(getStateArg(p.Args)),
+		IsPull:   util.OptionalBoolFalse,
+	}
+	var nodes []interface{}
+	skip, err := findRange(offset, limit, func(listOptions models.ListOptions) (int, error) {
+		opts.ListOptions = listOptions
+		issues, err := models.Issues(&opts)
+		if err != nil {
+			return 0, err
+		}
+		for _, issue := range issues {
+			nodes = append(nodes, convert.ToAPIIssue(issue))
+		}
+		return len(issues), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	opts.ListOptions = models.ListOptions{}
+	counts, err := models.CountIssuesByRepo(&opts)
+	if err != nil {
+		return nil, err
+	}
+	return newConnection(sliceRange(nodes, skip, limit), offset, counts[r.repo.ID]), nil
+}
+
+func resolveIssue(p graphql.ResolveParams) (interface{}, error) {
+	r := p.Source.(*repository)
+	if !r.perm.CanRead(models.UnitTypeIssues) {
+		return nil, nil
+	}
+	issue, err := models.GetIssueByIndex(r.repo.ID, int64(p.Args["number"].(int)))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if issue.IsPull {
+		return nil, nil
+	}
+	return convert.ToAPIIssue(issue), nil
+}
+
+func resolvePullRequests(p graphql.ResolveParams) (interface{}, error) {
+	r := p.Source.(*repository)
+	if !r.perm.CanRead(models.UnitTypePullRequests) {
+		return nil, nil
+	}
+	offset, limit, err := getPagination(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := models.PullRequestsOptions{
+		State: string(getStateArg(p.Args)),
+	}
+	var nodes []interface{}
+	var total int64
+	skip, err := findRange(offset, limit, func(listOptions models.ListOptions) (int, error) {
+		opts.ListOptions = listOptions
+		prs, count, err := models.PullRequests(r.repo.ID, &opts)
+		if err != nil {
+			return 0, err
+		}
+		total = count
+		for _, pr := range prs {
+			if err = pr.LoadIssue(); err != nil {
+				return 0, err
+			}
+			if err = pr.LoadAttributes(); err != nil {
+				return 0, err
+			}
+			nodes = append(nodes, convert.ToAPIPullRequest(pr))
+		}
+		return len(prs), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if limit == 0 {
+		if _, total, err = models.PullRequests(r.repo.ID, &opts); err != nil {
+			return nil, err
+		}
+	}
+	return newConnection(sliceRange(nodes, skip, limit), offset, total), nil
+}
+
+func resolvePullRequest(p graphql.ResolveParams) (interface{}, error) {
+	r := p.Source.(*repository)
+	if !r.perm.CanRead(models.UnitTypePullRequests) {
+		return nil, nil
+	}
+	pr, err := models.GetPullRequestByIndex(r.repo.ID, int64(p.Args["number"].(int)))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err = pr.LoadIssue(); err != nil {
+		return nil, err
+	}
+	if err = pr.LoadAttributes(); err != nil {
+		return nil, err
+	}
+	return convert.ToAPIPullRequest(pr), nil
+}
+
+func resolveMilestones(p graphql.ResolveParams) (interface{}, error) {
+	r := p.Source.(*repository)
+	if !r.perm.CanRead(models.UnitTypeIssues) && !r.perm.CanRead(models.UnitTypePullRequests) {
+		return nil, nil
+	}
+	offset, limit, err := getPagination(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	state := getStateArg(p.Args)
+	var nodes []interface{}
+	skip, err := findRange(offset, limit, func(listOptions models.ListOptions) (int, error) {
+		milestones, err := models.GetMilestonesByRepoID(r.repo.ID, state, listOptions)
+		if err != nil {
+			return 0, err
+		}
+		for _, milestone := range milestones {
+			nodes = append(nodes, convert.ToAPIMilestone(milestone))
+		}
+		return len(milestones), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	total := r.repo.NumOpenMilestones
+	switch state {
+	case api.StateClosed:
+		total = r.repo.NumClosedMilestones
+	case api.StateAll:
+		total = r.repo.NumMilestones
+	}
+	return newConnection(sliceRange(nodes, skip, limit), offset, int64(total)), nil
+}
+
+// canReadDrafts returns true if the user sending the query can see the draft releases
+func (r *repository) canReadDrafts() bool {
+	return r.perm.CanWrite(models.UnitTypeReleases)
+}
+
+func toAPIRelease(rel *models.Release) (*api.Release, error) {
+	if err := rel.LoadAttributes(); err != nil {
+		return nil, err
+	}
+	return rel.APIFormat(), nil
+}
+
+func resolveReleases(p graphql.ResolveParams) (interface{}, error) {
+	r := p.Source.(*repository)
+	if !r.perm.CanRead(models.UnitTypeReleases) {
+		return nil, nil
+	}
+	offset, limit, err := getPagination(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := models.FindReleasesOptions{
+		IncludeDrafts: r.canReadDrafts(),
+	}
+	var nodes []interface{}
+	skip, err := findRange(offset, limit, func(listOptions models.ListOptions) (int, error) {
+		opts.ListOptions = listOptions
+		releases, err := models.GetReleasesByRepoID(r.repo.ID, opts)
+		if err != nil {
+			return 0, err
+		}
+		for _, rel := range releases {
+			apiRel, err := toAPIRelease(rel)
+			if err != nil {
+				return 0, err
+			}
+			nodes = append(nodes, apiRel)
+		}
+		return len(releases), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := models.GetReleaseCountByRepoID(r.repo.ID, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newConnection(sliceRange(nodes, skip, limit), offset, total), nil
+}
+
+func resolveRelease(p graphql.ResolveParams) (interface{}, error) {
+	r := p.Source.(*repository)
+	if !r.perm.CanRead(models.UnitTypeReleases) {
+		return nil, nil
+	}
+	rel, err := models.GetRelease(r.repo.ID, p.Args["tag"].(string))
+	if err != nil {
+		if models.IsErrReleaseNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if rel.IsTag || (rel.IsDraft && !r.canReadDrafts()) {
+		return nil, nil
+	}
+	return toAPIRelease(rel)
+}
+
+func resolveLatestRelease(p graphql.ResolveParams) (interface{}, error) {
+	r := p.Source.(*repository)
+	if !r.perm.CanRead(models.UnitTypeReleases) {
+		return nil, nil
+	}
+	rel, err := models.GetLatestReleaseByRepoID(r.repo.ID)
+	if err != nil {
+		if models.IsErrReleaseNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return toAPIRelease(rel)
+}
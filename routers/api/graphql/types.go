@@ -0,0 +1,138 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package graphql
+
+import (
+	"github.com/graphql-go/graphql"
+)
+
+var userType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "User",
+	Fields: graphql.Fields{
+		"id":         &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"login":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"full_name":  &graphql.Field{Type: graphql.String},
+		"avatar_url": &graphql.Field{Type: graphql.String},
+	},
+})
+
+var labelType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Label",
+	Fields: graphql.Fields{
+		"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"name":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"color":       &graphql.Field{Type: graphql.String},
+		"description": &graphql.Field{Type: graphql.String},
+	},
+})
+
+var milestoneType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Milestone",
+	Fields: graphql.Fields{
+		"id":            &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"title":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"description":   &graphql.Field{Type: graphql.String},
+		"state":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"open_issues":   &graphql.Field{Type: graphql.Int},
+		"closed_issues": &graphql.Field{Type: graphql.Int},
+		"closed_at":     &graphql.Field{Type: graphql.DateTime},
+		"due_on":        &graphql.Field{Type: graphql.DateTime},
+	},
+})
+
+// issueFields returns the fields shared by issues and pull requests
+func issueFields() graphql.Fields {
+	return graphql.Fields{
+		"id":         &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"number":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"url":        &graphql.Field{Type: graphql.String},
+		"html_url":   &graphql.Field{Type: graphql.String},
+		"user":       &graphql.Field{Type: userType},
+		"title":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"body":       &graphql.Field{Type: graphql.String},
+		"labels":     &graphql.Field{Type: graphql.NewList(labelType)},
+		"milestone":  &graphql.Field{Type: milestoneType},
+		"assignees":  &graphql.Field{Type: graphql.NewList(userType)},
+		"state":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"is_locked":  &graphql.Field{Type: graphql.Boolean},
+		"comments":   &graphql.Field{Type: graphql.Int},
+		"created_at": &graphql.Field{Type: graphql.DateTime},
+		"updated_at": &graphql.Field{Type: graphql.DateTime},
+		"closed_at":  &graphql.Field{Type: graphql.DateTime},
+		"due_date":   &graphql.Field{Type: graphql.DateTime},
+	}
+}
+
+var issueType = graphql.NewObject(graphql.ObjectConfig{
+	Name:   "Issue",
+	Fields: issueFields(),
+})
+
+var pullRequestBranchType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "PullRequestBranch",
+	Fields: graphql.Fields{
+		"label": &graphql.Field{Type: graphql.String},
+		"ref":   &graphql.Field{Type: graphql.String},
+		"sha":   &graphql.Field{Type: graphql.String},
+	},
+})
+
+var pullRequestType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "PullRequest",
+	Fields: func() graphql.Fields {
+		fields := issueFields()
+		fields["diff_url"] = &graphql.Field{Type: graphql.String}
+		fields["patch_url"] = &graphql.Field{Type: graphql.String}
+		fields["mergeable"] = &graphql.Field{Type: graphql.Boolean}
+		fields["merged"] = &graphql.Field{Type: graphql.Boolean}
+		fields["merged_at"] = &graphql.Field{Type: graphql.DateTime}
+		fields["merge_commit_sha"] = &graphql.Field{Type: graphql.String}
+		fields["merged_by"] = &graphql.Field{Type: userType}
+		fields["base"] = &graphql.Field{Type: pullRequestBranchType}
+		fields["head"] = &graphql.Field{Type: pullRequestBranchType}
+		fields["merge_base"] = &graphql.Field{Type: graphql.String}
+		return fields
+	}(),
+})
+
+var releaseAssetType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "ReleaseAsset",
+	Fields: graphql.Fields{
+		"id":                   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"name":                 &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"size":                 &graphql.Field{Type: graphql.Int},
+		"download_count":       &graphql.Field{Type: graphql.Int},
+		"created_at":           &graphql.Field{Type: graphql.DateTime},
+		"browser_download_url": &graphql.Field{Type: graphql.String},
+	},
+})
+
+var releaseType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Release",
+	Fields: graphql.Fields{
+		"id":               &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"tag_name":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"target_commitish": &graphql.Field{Type: graphql.String},
+		"name":             &graphql.Field{Type: graphql.String},
+		"body":             &graphql.Field{Type: graphql.String},
+		"url":              &graphql.Field{Type: graphql.String},
+		"html_url":         &graphql.Field{Type: graphql.String},
+		"tarball_url":      &graphql.Field{Type: graphql.String},
+		"zipball_url":      &graphql.Field{Type: graphql.String},
+		"draft":            &graphql.Field{Type: graphql.Boolean},
+		"prerelease":       &graphql.Field{Type: graphql.Boolean},
+		"created_at":       &graphql.Field{Type: graphql.DateTime},
+		"published_at":     &graphql.Field{Type: graphql.DateTime},
+		"author":           &graphql.Field{Type: userType},
+		"assets":           &graphql.Field{Type: graphql.NewList(releaseAssetType)},
+	},
+})
+
+var (
+	issueConnectionType       = newConnectionType(issueType)
+	pullRequestConnectionType = newConnectionType(pullRequestType)
+	milestoneConnectionType   = newConnectionType(milestoneType)
+	releaseConnectionType     = newConnectionType(releaseType)
+)
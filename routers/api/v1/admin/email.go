@@ -0,0 +1,317 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/user"
+)
+
+// getUserByParams returns the user of the URL parameter, it responds with an error if
+// there is none or if it is an organization
+func getUserByParams(ctx *context.APIContext) *models.User {
+	u := user.GetUserByParams(ctx)
+	if ctx.Written() {
+		return nil
+	}
+	if u.IsOrganization() {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("%s is an organization not a user", u.Name))
+		return nil
+	}
+	return u
+}
+
+// ListUserEmails api for listing the email addresses of a user
+func ListUserEmails(ctx *context.APIContext) {
+	// swagger:operation GET /admin/users/{username}/emails admin adminListUserEmails
+	// ---
+	// summary: List a user's email addresses
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: username of the user
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/EmailList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	u := getUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	user.ListUserEmails(ctx, u.ID)
+}
+
+// AddUserEmails api for adding email addresses to a user
+func AddUserEmails(ctx *context.APIContext, form api.CreateEmailOption) {
+	// swagger:operation POST /admin/users/{username}/emails admin adminAddUserEmails
+	// ---
+	// summary: Add email addresses on behalf of a user
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: username of the user
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateEmailOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/EmailList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	u := getUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	user.AddUserEmails(ctx, form, u.ID)
+}
+
+// DeleteUserEmails api for deleting email addresses of a user
+func DeleteUserEmails(ctx *context.APIContext, form api.DeleteEmailOption) {
+	// swagger:operation DELETE /admin/users/{username}/emails admin adminDeleteUserEmails
+	// ---
+	// summary: Delete email addresses of a user
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: username of the user
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/DeleteEmailOption"
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	u := getUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	user.DeleteUserEmails(ctx, form, u.ID)
+}
+
+// VerifyUserEmail api for marking an email address of a user as verified
+func VerifyUserEmail(ctx *context.APIContext, form api.UserEmailOption) {
+	// swagger:operation POST /admin/users/{username}/emails/verify admin adminVerifyUserEmail
+	// ---
+	// summary: Mark an email address of a user as verified
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: username of the user
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/UserEmailOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/EmailList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	u := getUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	emails, err := models.GetEmailAddresses(u.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetEmailAddresses", err)
+		return
+	}
+	var email *models.EmailAddress
+	for _, e := range emails {
+		if strings.EqualFold(e.Email, form.Email) {
+			email = e
+			break
+		}
+	}
+	if email == nil {
+		ctx.NotFound()
+		return
+	}
+
+	// The primary address which isn't in the email address table is verified with the account
+	if err = models.ActivateUserEmail(u.ID, email.Email, email.ID == 0, true); err != nil {
+		if models.IsErrEmailAlreadyUsed(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "ActivateUserEmail", err)
+		}
+		return
+	}
+	log.Trace("Email address verified by admin(%s): %s", ctx.User.Name, u.Name)
+
+	user.ListUserEmails(ctx, u.ID)
+}
+
+// SetUserPrimaryEmail api for making an email address of a user its primary one
+func SetUserPrimaryEmail(ctx *context.APIContext, form api.UserEmailOption) {
+	// swagger:operation POST /admin/users/{username}/emails/primary admin adminSetUserPrimaryEmail
+	// ---
+	// summary: Make a verified email address of a user its primary one
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: username of the user
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/UserEmailOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/EmailList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	u := getUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if !strings.EqualFold(u.Email, form.Email) {
+		email := &models.EmailAddress{
+			UID:   u.ID,
+			Email: strings.ToLower(strings.TrimSpace(form.Email)),
+		}
+		if err := models.MakeEmailPrimary(email); err != nil {
+			if err == models.ErrEmailNotExist {
+				ctx.NotFound()
+			} else if err == models.ErrEmailNotActivated {
+				ctx.Error(http.StatusUnprocessableEntity, "", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "MakeEmailPrimary", err)
+			}
+			return
+		}
+		log.Trace("Primary email address changed by admin(%s): %s", ctx.User.Name, u.Name)
+	}
+
+	user.ListUserEmails(ctx, u.ID)
+}
+
+// ActivateUser api for activating the account of a user without its confirmation
+func ActivateUser(ctx *context.APIContext) {
+	// swagger:operation POST /admin/users/{username}/activate admin adminActivateUser
+	// ---
+	// summary: Activate a user, verifying its primary email address
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: username of the user
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/User"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	u := getUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	emails, err := models.GetEmailAddresses(u.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetEmailAddresses", err)
+		return
+	}
+	for _, email := range emails {
+		if !email.IsPrimary {
+			continue
+		}
+		// The primary address may also be in the email address table if it was a secondary one
+		if err = models.ActivateUserEmail(u.ID, email.Email, true, true); err == nil && email.ID != 0 {
+			err = models.ActivateUserEmail(u.ID, email.Email, false, true)
+		}
+		if err != nil {
+			if models.IsErrEmailAlreadyUsed(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "ActivateUserEmail", err)
+			}
+			return
+		}
+	}
+
+	u, err = models.GetUserByID(u.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetUserByID", err)
+		return
+	}
+	log.Trace("Account activated by admin(%s): %s", ctx.User.Name, u.Name)
+
+	ctx.JSON(http.StatusOK, convert.ToUser(u, ctx.IsSigned, ctx.User.IsAdmin))
+}
@@ -0,0 +1,212 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListHooks list the system webhooks
+func ListHooks(ctx *context.APIContext) {
+	// swagger:operation GET /admin/hooks admin adminListHooks
+	// ---
+	// summary: List the system webhooks
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/HookList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	sysHooks, err := models.GetSystemWebhooks()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetSystemWebhooks", err)
+		return
+	}
+	hooks := make([]*api.Hook, len(sysHooks))
+	for i, hook := range sysHooks {
+		hooks[i] = convert.ToSystemHook(hook)
+	}
+	ctx.JSON(http.StatusOK, hooks)
+}
+
+// GetHook get a system webhook by id
+func GetHook(ctx *context.APIContext) {
+	// swagger:operation GET /admin/hooks/{id} admin adminGetHook
+	// ---
+	// summary: Get a system webhook
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the hook to get
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Hook"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	hook, err := utils.GetSystemHook(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToSystemHook(hook))
+}
+
+// CreateHook create a system webhook
+func CreateHook(ctx *context.APIContext, form api.CreateHookOption) {
+	// swagger:operation POST /admin/hooks admin adminCreateHook
+	// ---
+	// summary: Create a system webhook, it receives the events of all the repositories
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CreateHookOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Hook"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	if !utils.CheckCreateHookOption(ctx, &form) {
+		return
+	}
+	utils.AddSystemHook(ctx, &form)
+}
+
+// EditHook modify a system webhook
+func EditHook(ctx *context.APIContext, form api.EditHookOption) {
+	// swagger:operation PATCH /admin/hooks/{id} admin adminEditHook
+	// ---
+	// summary: Update a system webhook
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the hook to update
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditHookOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Hook"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	utils.EditSystemHook(ctx, &form, ctx.ParamsInt64(":id"))
+}
+
+// DeleteHook delete a system webhook
+func DeleteHook(ctx *context.APIContext) {
+	// swagger:operation DELETE /admin/hooks/{id} admin adminDeleteHook
+	// ---
+	// summary: Delete a system webhook
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the hook to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	hook, err := utils.GetSystemHook(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	if err := models.DeleteDefaultSystemWebhook(hook.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteDefaultSystemWebhook", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// PruneHookDeliveries deletes old deliveries of all webhooks
+func PruneHookDeliveries(ctx *context.APIContext, form api.PruneHookDeliveriesOption) {
+	// swagger:operation POST /admin/hooks/deliveries/prune admin adminPruneHookDeliveries
+	// ---
+	// summary: Delete old deliveries of all webhooks, deliveries waiting for a retry are kept
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/PruneHookDeliveriesOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PruneHookDeliveriesResult"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	var olderThan time.Duration
+	if len(form.OlderThan) > 0 {
+		var err error
+		olderThan, err = time.ParseDuration(form.OlderThan)
+		if err != nil || olderThan <= 0 {
+			ctx.Error(http.StatusUnprocessableEntity, "", "Invalid older_than duration")
+			return
+		}
+	}
+	if form.NumberToKeep < 0 {
+		ctx.Error(http.StatusUnprocessableEntity, "", "number_to_keep must not be negative")
+		return
+	}
+	if olderThan == 0 && form.NumberToKeep == 0 {
+		ctx.Error(http.StatusUnprocessableEntity, "", "older_than or number_to_keep is required")
+		return
+	}
+
+	deleted, err := models.DeleteDeliveredHookTasks(ctx.Req.Context(), olderThan, form.NumberToKeep)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteDeliveredHookTasks", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, &api.PruneHookDeliveriesResult{Deleted: deleted})
+}
@@ -0,0 +1,125 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	go_context "context"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/cron"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/task"
+	"code.gitea.io/gitea/routers/api/v1/repo"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListRepoMaintenance lists the latest garbage collection of every repository
+func ListRepoMaintenance(ctx *context.APIContext) {
+	// swagger:operation GET /admin/repos/maintenance admin adminListRepoMaintenance
+	// ---
+	// summary: List the latest garbage collection of every repository, most recent first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: status
+	//   in: query
+	//   description: only list the garbage collections with this status
+	//   type: string
+	//   enum: [queued, running, stopped, failed, finished]
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepoGarbageCollectionList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	status := -1
+	if name := ctx.Query("status"); name != "" {
+		for s := api.TaskStatusQueue; s <= api.TaskStatusFinished; s++ {
+			if s.Name() == name {
+				status = int(s)
+			}
+		}
+		if status < 0 {
+			ctx.Error(http.StatusUnprocessableEntity, "", "Invalid status")
+			return
+		}
+	}
+
+	listOptions := utils.GetListOptions(ctx)
+	tasks, count, err := models.FindLatestRepositoryTasks(api.TaskTypeGarbageCollect, status, listOptions)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindLatestRepositoryTasks", err)
+		return
+	}
+
+	gcs := make([]*api.RepoGarbageCollection, 0, len(tasks))
+	for _, t := range tasks {
+		gc, err := repo.ToRepoGarbageCollection(t)
+		if err != nil {
+			if models.IsErrRepoNotExist(err) {
+				continue
+			}
+			ctx.Error(http.StatusInternalServerError, "ToRepoGarbageCollection", err)
+			return
+		}
+		gcs = append(gcs, gc)
+	}
+
+	ctx.SetLinkHeader(int(count), listOptions.PageSize)
+	ctx.SetTotalCountHeader(count)
+	ctx.JSON(http.StatusOK, &gcs)
+}
+
+// RunRepoMaintenance schedules the garbage collection of the repositories due for maintenance
+func RunRepoMaintenance(ctx *context.APIContext) {
+	// swagger:operation POST /admin/repos/maintenance admin adminRunRepoMaintenance
+	// ---
+	// summary: Schedule the garbage collection of the repositories due for maintenance in the background
+	// description: The repositories are due for maintenance according to the settings of the repo_maintenance cron task,
+	//              a repository whose garbage collection is queued or running isn't scheduled again.
+	// parameters:
+	// - name: force
+	//   in: query
+	//   description: schedule the garbage collection of all repositories
+	//   type: boolean
+	// responses:
+	//   "202":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	if ctx.QueryBool("force") {
+		doer := ctx.User
+		go graceful.GetManager().RunWithShutdownContext(func(baseCtx go_context.Context) {
+			if err := task.ScheduleMaintenance(baseCtx, doer, task.MaintenanceOptions{Force: true}); err != nil {
+				log.Error("ScheduleMaintenance: %v", err)
+			}
+		})
+		ctx.Status(http.StatusAccepted)
+		return
+	}
+
+	t := cron.GetTask("repo_maintenance")
+	if t == nil {
+		ctx.Error(http.StatusInternalServerError, "GetTask", "repo_maintenance cron task isn't registered")
+		return
+	}
+	go t.RunWithUser(ctx.User, nil)
+	ctx.Status(http.StatusAccepted)
+}
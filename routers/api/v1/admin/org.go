@@ -11,6 +11,7 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/notification"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/routers/api/v1/user"
 	"code.gitea.io/gitea/routers/api/v1/utils"
@@ -75,6 +76,7 @@ func CreateOrg(ctx *context.APIContext, form api.CreateOrgOption) {
 		}
 		return
 	}
+	notification.NotifyCreateUser(ctx.User, org)
 
 	ctx.JSON(http.StatusCreated, convert.ToOrganization(org))
 }
@@ -59,11 +59,15 @@
 package v1
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
@@ -89,6 +93,10 @@ func sudo() macaron.Handler {
 
 		if len(sudo) > 0 {
 			if ctx.IsSigned && ctx.User.IsAdmin {
+				if scope, ok := ctx.Data["ApiTokenScope"].(models.AccessTokenScope); ok && !scope.Has(models.AccessTokenScopeAdmin) {
+					ctx.Error(http.StatusForbidden, "", fmt.Sprintf("the access token doesn't grant the %s scope", models.AccessTokenScopeAdmin))
+					return
+				}
 				user, err := models.GetUserByName(sudo)
 				if err != nil {
 					if models.IsErrUserNotExist(err) {
@@ -110,8 +118,149 @@ func sudo() macaron.Handler {
 	}
 }
 
+// rateLimit counts the requests of the signed in user, or of the IP address for anonymous
+// requests, and rejects them once the limit of the current period is exceeded.
+func rateLimit() macaron.Handler {
+	return func(ctx *context.APIContext) {
+		if !setting.API.EnableRateLimit {
+			return
+		}
+
+		limit := int64(setting.API.RateLimitPerIP)
+		key := "api_rate_limit_ip_" + ctx.RemoteAddr()
+		if ctx.IsSigned {
+			limit = int64(setting.API.RateLimitPerUser)
+			key = fmt.Sprintf("api_rate_limit_user_%d", ctx.User.ID)
+		}
+		period := int64(setting.API.RateLimitPeriod / time.Second)
+		if period <= 0 {
+			period = 1
+		}
+		window := time.Now().Unix() / period
+		reset := (window + 1) * period
+
+		count, err := cache.IncrInt64(fmt.Sprintf("%s_%d", key, window), period)
+		if err != nil {
+			log.Error("Unable to count the API requests of %s: %v", key, err)
+			return
+		}
+		remaining := limit - count
+		if remaining < 0 {
+			remaining = 0
+		}
+		ctx.Header().Set("X-RateLimit-Limit", strconv.FormatInt(limit, 10))
+		ctx.Header().Set("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+		ctx.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+
+		if count > limit {
+			ctx.Header().Set("Retry-After", strconv.FormatInt(reset-time.Now().Unix(), 10))
+			ctx.Error(http.StatusTooManyRequests, "", "API rate limit exceeded")
+		}
+	}
+}
+
+// requiredTokenScope returns the scope an access token must grant to be used for a request,
+// an empty scope if any access token can be used.
+func requiredTokenScope(method, path string) models.AccessTokenScope {
+	// The GraphQL queries only read, whatever their method
+	if strings.TrimSuffix(path, "/") == "/api/graphql" {
+		return models.AccessTokenScopeRepoRead
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(path, "/api/v1"), "/"), "/")
+	isRead := method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+	isRepoPart := func(names ...string) bool {
+		if parts[0] != "repos" || len(parts) < 4 {
+			return false
+		}
+		for _, name := range names {
+			if parts[3] == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case parts[0] == "admin":
+		return models.AccessTokenScopeAdmin
+	case parts[0] == "notifications" || isRepoPart("notifications"):
+		return models.AccessTokenScopeUser
+	case parts[0] == "user" || parts[0] == "users":
+		if len(parts) > 1 && parts[len(parts)-1] == "repos" {
+			if isRead {
+				return models.AccessTokenScopeRepoRead
+			}
+			return models.AccessTokenScopeRepoWrite
+		}
+		if isRead {
+			return ""
+		}
+		return models.AccessTokenScopeUser
+	case parts[0] == "version" || parts[0] == "settings" || parts[0] == "markdown" || parts[0] == "signing-key.gpg":
+		return ""
+	case isRead:
+		return models.AccessTokenScopeRepoRead
+	case isRepoPart("issues", "labels", "milestones"):
+		return models.AccessTokenScopeIssueWrite
+	default:
+		return models.AccessTokenScopeRepoWrite
+	}
+}
+
+// checkTokenScope rejects the requests authenticated with an access token which doesn't
+// grant the scope they require
+func checkTokenScope() macaron.Handler {
+	return func(ctx *context.APIContext) {
+		scope, ok := ctx.Data["ApiTokenScope"].(models.AccessTokenScope)
+		if !ok {
+			return
+		}
+		if required := requiredTokenScope(ctx.Req.Method, ctx.Req.URL.Path); required != "" && !scope.Has(required) {
+			ctx.Error(http.StatusForbidden, "", fmt.Sprintf("the access token doesn't grant the %s scope", required))
+		}
+	}
+}
+
 func repoAssignment() macaron.Handler {
 	return func(ctx *context.APIContext) {
+		if !assignRepository(ctx) {
+			return
+		}
+
+		if !ctx.Repo.HasAccess() {
+			ctx.NotFound()
+		}
+	}
+}
+
+// repoTransferAssignment assigns the repository like repoAssignment, but it is also
+// visible to the users who can accept its pending transfer
+func repoTransferAssignment() macaron.Handler {
+	return func(ctx *context.APIContext) {
+		if !assignRepository(ctx) || ctx.Repo.HasAccess() {
+			return
+		}
+
+		transfer, err := models.GetPendingRepositoryTransfer(ctx.Repo.Repository)
+		if err != nil {
+			if models.IsErrNoPendingRepoTransfer(err) {
+				ctx.NotFound()
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetPendingRepositoryTransfer", err)
+			}
+			return
+		}
+		if !transfer.CanUserAcceptTransfer(ctx.User) {
+			ctx.NotFound()
+		}
+	}
+}
+
+// assignRepository loads the repository of the request and the permission of the user on
+// it, it returns false if it failed and the response is written
+func assignRepository(ctx *context.APIContext) bool {
+	{
 		userName := ctx.Params(":username")
 		repoName := ctx.Params(":reponame")
 
@@ -131,7 +280,7 @@ func repoAssignment() macaron.Handler {
 				} else {
 					ctx.Error(http.StatusInternalServerError, "GetUserByName", err)
 				}
-				return
+				return false
 			}
 		}
 		ctx.Repo.Owner = owner
@@ -151,7 +300,7 @@ func repoAssignment() macaron.Handler {
 			} else {
 				ctx.Error(http.StatusInternalServerError, "GetRepositoryByName", err)
 			}
-			return
+			return false
 		}
 
 		repo.Owner = owner
@@ -160,13 +309,9 @@ func repoAssignment() macaron.Handler {
 		ctx.Repo.Permission, err = models.GetUserRepoPermission(repo, ctx.User)
 		if err != nil {
 			ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err)
-			return
-		}
-
-		if !ctx.Repo.HasAccess() {
-			ctx.NotFound()
-			return
+			return false
 		}
+		return true
 	}
 }
 
@@ -525,6 +670,10 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Combo("/threads/:id").
 				Get(notify.GetThread).
 				Patch(notify.ReadThread)
+			m.Combo("/threads/:id/subscription").
+				Get(notify.GetThreadSubscription).
+				Put(notify.SetThreadSubscription).
+				Delete(notify.DeleteThreadSubscription)
 		}, reqToken())
 
 		// Users
@@ -644,6 +793,16 @@ func RegisterRoutes(m *macaron.Macaron) {
 							Patch(bind(api.EditHookOption{}), repo.EditHook).
 							Delete(repo.DeleteHook)
 						m.Post("/tests", context.RepoRef(), repo.TestHook)
+						m.Get("/deliveries", repo.ListHookDeliveries)
+						m.Group("/deliveries/:delivery", func() {
+							m.Get("", repo.GetHookDelivery)
+							m.Post("/redeliver", repo.RedeliverHook)
+						})
+					})
+					m.Group("/org", func() {
+						m.Get("", repo.ListInheritedHooks)
+						m.Combo("/:id/opt_out").Put(repo.OptOutInheritedHook).
+							Delete(repo.OptInInheritedHook)
 					})
 					m.Group("/git", func() {
 						m.Combo("").Get(repo.ListGitHooks)
@@ -661,7 +820,15 @@ func RegisterRoutes(m *macaron.Macaron) {
 						Delete(reqAdmin(), repo.DeleteCollaborator)
 				}, reqToken())
 				m.Get("/raw/*", context.RepoRefByType(context.RepoRefAny), reqRepoReader(models.UnitTypeCode), repo.GetRawFile)
-				m.Get("/archive/*", reqRepoReader(models.UnitTypeCode), repo.GetArchive)
+				m.Get("/media/*", context.RepoRefByType(context.RepoRefAny), reqRepoReader(models.UnitTypeCode), repo.GetRawFileOrLFS)
+				m.Get("/blame/*", context.RepoRefByType(context.RepoRefAny), reqRepoReader(models.UnitTypeCode), repo.GetBlame)
+				m.Group("/maintenance", func() {
+					m.Get("", repo.GetMaintenance)
+					m.Post("/gc", repo.GarbageCollect)
+				}, reqToken(), reqOwner())
+				m.Combo("/archive/*", reqRepoReader(models.UnitTypeCode)).Get(repo.GetArchive).
+					Post(reqToken(), repo.RequestArchive)
+				m.Get("/bundle", reqRepoReader(models.UnitTypeCode), repo.GetBundle)
 				m.Combo("/forks").Get(repo.ListForks).
 					Post(reqToken(), reqRepoReader(models.UnitTypeCode), bind(api.CreateForkOption{}), repo.CreateFork)
 				m.Group("/branches", func() {
@@ -670,6 +837,10 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Delete("/*", reqRepoWriter(models.UnitTypeCode), context.RepoRefByType(context.RepoRefBranch), repo.DeleteBranch)
 					m.Post("", reqRepoWriter(models.UnitTypeCode), bind(api.CreateBranchRepoOption{}), repo.CreateBranch)
 				}, reqRepoReader(models.UnitTypeCode))
+				m.Group("/deleted_branches", func() {
+					m.Get("", repo.ListDeletedBranches)
+					m.Post("/:id/restore", repo.RestoreDeletedBranch)
+				}, reqToken(), reqRepoWriter(models.UnitTypeCode))
 				m.Group("/branch_protections", func() {
 					m.Get("", repo.ListBranchProtections)
 					m.Post("", bind(api.CreateBranchProtectionOption{}), repo.CreateBranchProtection)
@@ -695,6 +866,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Group("/issues", func() {
 					m.Combo("").Get(repo.ListIssues).
 						Post(reqToken(), mustNotBeArchived, bind(api.CreateIssueOption{}), repo.CreateIssue)
+					m.Post("/batch", reqToken(), mustNotBeArchived, bind(api.CreateIssuesOption{}), repo.CreateIssues)
 					m.Group("/comments", func() {
 						m.Get("", repo.ListRepoIssueComments)
 						m.Group("/:id", func() {
@@ -767,6 +939,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 				})
 				m.Get("/stargazers", repo.ListStargazers)
 				m.Get("/subscribers", repo.ListSubscribers)
+				m.Get("/events/stream", reqToken(), reqAnyRepoReader(), repo.StreamEvents)
 				m.Group("/subscription", func() {
 					m.Get("", user.IsWatching)
 					m.Put("", reqToken(), user.Watch)
@@ -774,20 +947,40 @@ func RegisterRoutes(m *macaron.Macaron) {
 				})
 				m.Group("/releases", func() {
 					m.Combo("").Get(repo.ListReleases).
-						Post(reqToken(), reqRepoWriter(models.UnitTypeReleases), context.ReferencesGitRepo(false), bind(api.CreateReleaseOption{}), repo.CreateRelease)
+						Post(reqToken(), reqRepoWriter(models.UnitTypeReleases), context.ReferencesGitRepo(false), bind(api.CreateReleaseOption{}), repo.CreateRelease).
+						Delete(reqToken(), reqRepoWriter(models.UnitTypeReleases), repo.DeleteReleases)
+					m.Get("/latest", repo.GetLatestRelease)
+					m.Get("/jobs/:id", reqToken(), reqRepoWriter(models.UnitTypeReleases), repo.GetReleasesJob)
+					m.Get("/compare", reqRepoReader(models.UnitTypeCode), repo.CompareReleases)
 					m.Group("/:id", func() {
 						m.Combo("").Get(repo.GetRelease).
 							Patch(reqToken(), reqRepoWriter(models.UnitTypeReleases), context.ReferencesGitRepo(false), bind(api.EditReleaseOption{}), repo.EditRelease).
 							Delete(reqToken(), reqRepoWriter(models.UnitTypeReleases), repo.DeleteRelease)
+						m.Combo("/yank", reqToken(), reqRepoWriter(models.UnitTypeReleases)).
+							Post(bind(api.YankReleaseOption{}), repo.YankRelease).
+							Delete(repo.UnyankRelease)
+						m.Group("/comments", func() {
+							m.Combo("").Get(repo.ListReleaseComments).
+								Post(reqToken(), mustNotBeArchived, bind(api.CreateReleaseCommentOption{}), repo.CreateReleaseComment)
+							m.Combo("/:comment", reqToken(), mustNotBeArchived).
+								Patch(bind(api.EditReleaseCommentOption{}), repo.EditReleaseComment).
+								Delete(repo.DeleteReleaseComment)
+						})
 						m.Group("/assets", func() {
 							m.Combo("").Get(repo.ListReleaseAttachments).
 								Post(reqToken(), reqRepoWriter(models.UnitTypeReleases), repo.CreateReleaseAttachment)
 							m.Combo("/:asset").Get(repo.GetReleaseAttachment).
 								Patch(reqToken(), reqRepoWriter(models.UnitTypeReleases), bind(api.EditAttachmentOptions{}), repo.EditReleaseAttachment).
 								Delete(reqToken(), reqRepoWriter(models.UnitTypeReleases), repo.DeleteReleaseAttachment)
+							m.Group("/uploads", func() {
+								m.Post("", bind(api.CreateAttachmentUploadOptions{}), repo.CreateReleaseAttachmentUpload)
+								m.Combo("/:uuid").Get(repo.GetReleaseAttachmentUpload).
+									Patch(repo.UploadReleaseAttachmentChunk).
+									Delete(repo.DeleteReleaseAttachmentUpload)
+							}, reqToken(), reqRepoWriter(models.UnitTypeReleases))
 						})
 					})
-				}, reqRepoReader(models.UnitTypeReleases))
+				}, reqRepoReader(models.UnitTypeReleases), context.ReferencesGitRepo(false))
 				m.Post("/mirror-sync", reqToken(), reqRepoWriter(models.UnitTypeCode), repo.MirrorSync)
 				m.Get("/editorconfig/:filename", context.RepoRef(), reqRepoReader(models.UnitTypeCode), repo.GetEditorconfig)
 				m.Group("/pulls", func() {
@@ -799,7 +992,12 @@ func RegisterRoutes(m *macaron.Macaron) {
 						m.Get(".diff", repo.DownloadPullDiff)
 						m.Get(".patch", repo.DownloadPullPatch)
 						m.Combo("/merge").Get(repo.IsPullRequestMerged).
-							Post(reqToken(), mustNotBeArchived, bind(auth.MergePullRequestForm{}), repo.MergePullRequest)
+							Post(reqToken(), mustNotBeArchived, bind(auth.MergePullRequestForm{}), repo.MergePullRequest).
+							Delete(reqToken(), mustNotBeArchived, repo.CancelScheduledAutoMerge)
+						m.Combo("/merge_queue").Get(repo.GetPullRequestMergeQueueEntry).
+							Delete(reqToken(), mustNotBeArchived, repo.RemovePullRequestFromMergeQueue)
+						m.Get("/code_owners", repo.GetPullRequestCodeOwners)
+						m.Post("/ready_for_review", reqToken(), mustNotBeArchived, repo.ReadyForReviewPullRequest)
 						m.Group("/reviews", func() {
 							m.Combo("").
 								Get(repo.ListPullReviews).
@@ -813,6 +1011,14 @@ func RegisterRoutes(m *macaron.Macaron) {
 									Get(repo.GetPullReviewComments)
 							})
 						})
+						m.Group("/threads", func() {
+							m.Get("", repo.ListPullReviewThreads)
+							m.Group("/:id", func() {
+								m.Get("", repo.GetPullReviewThread)
+								m.Post("/resolve", reqToken(), repo.ResolvePullReviewThread)
+								m.Post("/unresolve", reqToken(), repo.UnresolvePullReviewThread)
+							})
+						})
 
 					})
 				}, mustAllowPulls, reqRepoReader(models.UnitTypeCode), context.ReferencesGitRepo(false))
@@ -832,11 +1038,20 @@ func RegisterRoutes(m *macaron.Macaron) {
 						m.Get("/:sha", repo.GetSingleCommit)
 					})
 					m.Get("/refs", repo.GetGitAllRefs)
-					m.Get("/refs/*", repo.GetGitRefs)
+					m.Combo("/refs/*").Get(repo.GetGitRefs).
+						Post(reqToken(), reqRepoWriter(models.UnitTypeCode), mustNotBeArchived, context.ReferencesGitRepo(false), bind(api.CreateGitRefOption{}), repo.CreateGitRef).
+						Delete(reqToken(), reqRepoWriter(models.UnitTypeCode), mustNotBeArchived, repo.DeleteGitRef)
 					m.Get("/trees/:sha", context.RepoRef(), repo.GetTree)
 					m.Get("/blobs/:sha", context.RepoRef(), repo.GetBlob)
 					m.Get("/tags/:sha", context.RepoRef(), repo.GetTag)
+					m.Combo("/notes/:sha", context.ReferencesGitRepo(false)).Get(repo.GetNote).
+						Post(reqToken(), reqRepoWriter(models.UnitTypeCode), mustNotBeArchived, bind(api.CreateNoteOption{}), repo.AddNote)
+					m.Group("", func() {
+						m.Post("/cherry-pick", bind(api.CherryPickCommitOption{}), repo.CherryPickCommit)
+						m.Post("/revert", bind(api.CherryPickCommitOption{}), repo.RevertCommit)
+					}, reqToken(), reqRepoWriter(models.UnitTypeCode), mustNotBeArchived, context.ReferencesGitRepo(false))
 				}, reqRepoReader(models.UnitTypeCode))
+				m.Get("/submodules", reqRepoReader(models.UnitTypeCode), context.ReferencesGitRepo(false), repo.ListSubmodules)
 				m.Group("/contents", func() {
 					m.Get("", repo.GetContentsList)
 					m.Get("/*", repo.GetContents)
@@ -855,8 +1070,18 @@ func RegisterRoutes(m *macaron.Macaron) {
 							Delete(reqToken(), repo.DeleteTopic)
 					}, reqAdmin())
 				}, reqAnyRepoReader())
-				m.Get("/languages", reqRepoReader(models.UnitTypeCode), repo.GetLanguages)
+				m.Group("/languages", func() {
+					m.Get("", repo.GetLanguages)
+					m.Get("/bar", repo.GetLanguageBar)
+				}, reqRepoReader(models.UnitTypeCode))
 			}, repoAssignment())
+
+			m.Group("/:username/:reponame/transfer", func() {
+				m.Combo("").Get(repo.GetTransfer).
+					Delete(reqOwner(), repo.CancelTransfer)
+				m.Post("/accept", repo.AcceptTransfer)
+				m.Post("/reject", repo.RejectTransfer)
+			}, reqToken(), repoTransferAssignment())
 		})
 
 		// Organizations
@@ -893,6 +1118,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 					Patch(reqToken(), reqOrgOwnership(), bind(api.EditLabelOption{}), org.EditLabel).
 					Delete(reqToken(), reqOrgOwnership(), org.DeleteLabel)
 			})
+			m.Get("/activities/feeds", org.ListActivityFeeds)
 			m.Group("/hooks", func() {
 				m.Combo("").Get(org.ListHooks).
 					Post(bind(api.CreateHookOption{}), org.CreateHook)
@@ -926,6 +1152,16 @@ func RegisterRoutes(m *macaron.Macaron) {
 
 		m.Group("/admin", func() {
 			m.Get("/orgs", admin.GetAllOrgs)
+			m.Combo("/repos/maintenance").Get(admin.ListRepoMaintenance).
+				Post(admin.RunRepoMaintenance)
+			m.Group("/hooks", func() {
+				m.Combo("").Get(admin.ListHooks).
+					Post(bind(api.CreateHookOption{}), admin.CreateHook)
+				m.Post("/deliveries/prune", bind(api.PruneHookDeliveriesOption{}), admin.PruneHookDeliveries)
+				m.Combo("/:id").Get(admin.GetHook).
+					Patch(bind(api.EditHookOption{}), admin.EditHook).
+					Delete(admin.DeleteHook)
+			})
 			m.Group("/users", func() {
 				m.Get("", admin.GetAllUsers)
 				m.Post("", bind(api.CreateUserOption{}), admin.CreateUser)
@@ -936,6 +1172,14 @@ func RegisterRoutes(m *macaron.Macaron) {
 						m.Post("", bind(api.CreateKeyOption{}), admin.CreatePublicKey)
 						m.Delete("/:id", admin.DeleteUserPublicKey)
 					})
+					m.Group("/emails", func() {
+						m.Combo("").Get(admin.ListUserEmails).
+							Post(bind(api.CreateEmailOption{}), admin.AddUserEmails).
+							Delete(bind(api.DeleteEmailOption{}), admin.DeleteUserEmails)
+						m.Post("/verify", bind(api.UserEmailOption{}), admin.VerifyUserEmail)
+						m.Post("/primary", bind(api.UserEmailOption{}), admin.SetUserPrimaryEmail)
+					})
+					m.Post("/activate", admin.ActivateUser)
 					m.Get("/orgs", org.ListUserOrgs)
 					m.Post("/orgs", bind(api.CreateOrgOption{}), admin.CreateOrg)
 					m.Post("/repos", bind(api.CreateRepoOption{}), admin.CreateRepo)
@@ -946,7 +1190,13 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Group("/topics", func() {
 			m.Get("/search", repo.TopicSearch)
 		})
-	}, securityHeaders(), context.APIContexter(), sudo())
+	}, Middlewares()...)
+}
+
+// Middlewares returns the handlers every API request goes through before its route,
+// they are shared by the v1 and the GraphQL APIs
+func Middlewares() []macaron.Handler {
+	return []macaron.Handler{securityHeaders(), context.APIContexter(), rateLimit(), checkTokenScope(), sudo()}
 }
 
 func securityHeaders() macaron.Handler {
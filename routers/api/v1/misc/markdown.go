@@ -8,7 +8,9 @@ import (
 	"net/http"
 	"strings"
 
+	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup/markdown"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
@@ -62,12 +64,13 @@ func Markdown(ctx *context.APIContext, form api.MarkdownOption) {
 				urlPrefix = util.URLJoin(setting.AppURL, form.Context)
 			}
 		}
-		if ctx.Repo != nil && ctx.Repo.Repository != nil {
+		repo := contextRepository(ctx, urlPrefix)
+		if repo != nil {
 			// "gfm" = Github Flavored Markdown - set this to render as a document
 			if form.Mode == "gfm" {
-				meta = ctx.Repo.Repository.ComposeDocumentMetas()
+				meta = repo.ComposeDocumentMetas()
 			} else {
-				meta = ctx.Repo.Repository.ComposeMetas()
+				meta = repo.ComposeMetas()
 			}
 		}
 		if form.Mode == "gfm" {
@@ -95,6 +98,40 @@ func Markdown(ctx *context.APIContext, form api.MarkdownOption) {
 	}
 }
 
+// contextRepository returns the repository to render references to issues and commits for,
+// which is the repository of the request or the one the context URL belongs to if the doer can read it
+func contextRepository(ctx *context.APIContext, urlPrefix string) *models.Repository {
+	if ctx.Repo != nil && ctx.Repo.Repository != nil {
+		return ctx.Repo.Repository
+	}
+
+	appURL := strings.TrimSuffix(setting.AppURL, "/") + "/"
+	if !strings.HasPrefix(urlPrefix, appURL) {
+		return nil
+	}
+	fields := strings.SplitN(strings.TrimPrefix(urlPrefix, appURL), "/", 3)
+	if len(fields) < 2 {
+		return nil
+	}
+
+	repo, err := models.GetRepositoryByOwnerAndName(fields[0], fields[1])
+	if err != nil {
+		if !models.IsErrRepoNotExist(err) {
+			log.Error("GetRepositoryByOwnerAndName: %v", err)
+		}
+		return nil
+	}
+	perm, err := models.GetUserRepoPermission(repo, ctx.User)
+	if err != nil {
+		log.Error("GetUserRepoPermission: %v", err)
+		return nil
+	}
+	if !perm.HasAccess() {
+		return nil
+	}
+	return repo
+}
+
 // MarkdownRaw render raw markdown HTML
 func MarkdownRaw(ctx *context.APIContext) {
 	// swagger:operation POST /markdown/raw miscellaneous renderMarkdownRaw
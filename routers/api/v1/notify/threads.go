@@ -10,6 +10,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
 )
 
 // GetThread get notification by ID
@@ -83,6 +84,131 @@ func ReadThread(ctx *context.APIContext) {
 	ctx.Status(http.StatusResetContent)
 }
 
+// GetThreadSubscription get whether the user of a notification thread is subscribed to its issue
+func GetThreadSubscription(ctx *context.APIContext) {
+	// swagger:operation GET /notifications/threads/{id}/subscription notification notifyGetThreadSubscription
+	// ---
+	// summary: Check if the user is subscribed to the issue of a notification thread
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of notification thread
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WatchInfo"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	n := getThreadWithIssue(ctx)
+	if n == nil {
+		return
+	}
+	threadSubscription(ctx, n)
+}
+
+// SetThreadSubscription subscribe the user of a notification thread to its issue
+func SetThreadSubscription(ctx *context.APIContext) {
+	// swagger:operation PUT /notifications/threads/{id}/subscription notification notifySetThreadSubscription
+	// ---
+	// summary: Subscribe the user to the issue of a notification thread
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of notification thread
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WatchInfo"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	n := getThreadWithIssue(ctx)
+	if n == nil {
+		return
+	}
+	if err := models.CreateOrUpdateIssueWatch(n.UserID, n.IssueID, true); err != nil {
+		ctx.Error(http.StatusInternalServerError, "CreateOrUpdateIssueWatch", err)
+		return
+	}
+	threadSubscription(ctx, n)
+}
+
+// DeleteThreadSubscription unsubscribe the user of a notification thread from its issue
+func DeleteThreadSubscription(ctx *context.APIContext) {
+	// swagger:operation DELETE /notifications/threads/{id}/subscription notification notifyDeleteThreadSubscription
+	// ---
+	// summary: Unsubscribe the user from the issue of a notification thread
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of notification thread
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	n := getThreadWithIssue(ctx)
+	if n == nil {
+		return
+	}
+	if err := models.CreateOrUpdateIssueWatch(n.UserID, n.IssueID, false); err != nil {
+		ctx.Error(http.StatusInternalServerError, "CreateOrUpdateIssueWatch", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func threadSubscription(ctx *context.APIContext, n *models.Notification) {
+	watching, err := models.CheckIssueWatch(n.User, n.Issue)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "CheckIssueWatch", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, api.WatchInfo{
+		Subscribed:    watching,
+		Ignored:       !watching,
+		Reason:        nil,
+		CreatedAt:     n.Issue.CreatedUnix.AsTime(),
+		URL:           n.APIURL() + "/subscription",
+		RepositoryURL: n.Repository.APIURL(),
+	})
+}
+
+// getThreadWithIssue returns the notification thread with its issue, user and repository loaded
+func getThreadWithIssue(ctx *context.APIContext) *models.Notification {
+	n := getThread(ctx)
+	if n == nil {
+		return nil
+	}
+	if n.IssueID == 0 {
+		ctx.NotFound()
+		return nil
+	}
+	if err := n.LoadAttributes(); err != nil {
+		ctx.InternalServerError(err)
+		return nil
+	}
+	return n
+}
+
 func getThread(ctx *context.APIContext) *models.Notification {
 	n, err := models.GetNotificationByID(ctx.ParamsInt64(":id"))
 	if err != nil {
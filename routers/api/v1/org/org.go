@@ -6,11 +6,13 @@
 package org
 
 import (
+	"fmt"
 	"net/http"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/notification"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/routers/api/v1/user"
 	"code.gitea.io/gitea/routers/api/v1/utils"
@@ -187,6 +189,7 @@ func Create(ctx *context.APIContext, form api.CreateOrgOption) {
 		}
 		return
 	}
+	notification.NotifyCreateUser(ctx.User, org)
 
 	ctx.JSON(http.StatusCreated, convert.ToOrganization(org))
 }
@@ -276,5 +279,112 @@ func Delete(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "DeleteOrganization", err)
 		return
 	}
+	notification.NotifyDeleteUser(ctx.User, ctx.Org.Organization)
 	ctx.Status(http.StatusNoContent)
 }
+
+// ListActivityFeeds lists the activities of the repositories of an organization
+func ListActivityFeeds(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/activities/feeds organization orgListActivityFeeds
+	// ---
+	// summary: List the activities of the repositories of an organization, the latest first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: actor
+	//   in: query
+	//   description: only show the activities performed by this user
+	//   type: string
+	// - name: type
+	//   in: query
+	//   description: only show the activities of these types, like `commit_repo`, `create_issue` or `publish_release`
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	// - name: since
+	//   in: query
+	//   description: only show the activities performed at or after the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: before
+	//   in: query
+	//   description: only show the activities performed at or before the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results, maximum page size is 50
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActivityFeedsList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	if !models.HasOrgVisible(ctx.Org.Organization, ctx.User) {
+		ctx.NotFound("HasOrgVisible", nil)
+		return
+	}
+
+	opts := models.GetFeedsOptions{
+		ListOptions:    utils.GetPaginatedListOptions(ctx),
+		RequestedUser:  ctx.Org.Organization,
+		Actor:          ctx.User,
+		IncludePrivate: true,
+	}
+
+	if actor := ctx.Query("actor"); actor != "" {
+		u, err := models.GetUserByName(actor)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetUserByName", err)
+			}
+			return
+		}
+		opts.ActUserID = u.ID
+	}
+
+	for _, name := range ctx.QueryStrings("type") {
+		opType, ok := models.ActionTypeFromName(name)
+		if !ok {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("unknown activity type: %s", name))
+			return
+		}
+		opts.OpTypes = append(opts.OpTypes, opType)
+	}
+
+	var err error
+	if opts.Before, opts.Since, err = utils.GetQueryBeforeSince(ctx); err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	feeds, err := models.GetFeeds(opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetFeeds", err)
+		return
+	}
+
+	activities := make([]*api.Activity, len(feeds))
+	for i, feed := range feeds {
+		if activities[i], err = convert.ToActivity(feed, ctx.User); err != nil {
+			ctx.Error(http.StatusInternalServerError, "ToActivity", err)
+			return
+		}
+	}
+	ctx.JSON(http.StatusOK, &activities)
+}
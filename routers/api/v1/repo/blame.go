@@ -0,0 +1,162 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// GetBlame returns the blame of a file
+func GetBlame(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/blame/{ref}/{filepath} repository repoGetBlame
+	// ---
+	// summary: Get the blame of a file, the ranges of lines with the commit which last changed them
+	// description: The ranges are streamed as they are computed, the list ends early if an error happens meanwhile.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: path
+	//   description: name of the branch/tag/commit
+	//   type: string
+	//   required: true
+	// - name: filepath
+	//   in: path
+	//   description: filepath of the file to blame
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/BlameRangeList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if ctx.Repo.Repository.IsEmpty || len(ctx.Repo.TreePath) == 0 {
+		ctx.NotFound()
+		return
+	}
+
+	entry, err := ctx.Repo.Commit.GetTreeEntryByPath(ctx.Repo.TreePath)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetTreeEntryByPath", err)
+		}
+		return
+	}
+	if entry.IsDir() || entry.IsSubModule() {
+		ctx.NotFound()
+		return
+	}
+
+	blameReader, err := git.CreateBlameReader(ctx.Req.Context(), ctx.Repo.Repository.RepoPath(), ctx.Repo.CommitID, ctx.Repo.TreePath)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "CreateBlameReader", err)
+		return
+	}
+	defer blameReader.Close()
+
+	repo := ctx.Repo.Repository
+	commits := make(map[string]*api.BlameCommit)
+	now := time.Now()
+
+	// The ranges are written as soon as they are read, so the whole blame of large files
+	// doesn't have to be kept in memory. The status can't be changed once the first range
+	// is written, errors only end the list then. The reader is still read to the end so
+	// git blame can exit.
+	ctx.Resp.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	ctx.Resp.WriteHeader(http.StatusOK)
+	_, _ = ctx.Resp.Write([]byte("["))
+	encoder := json.NewEncoder(ctx.Resp)
+	failed := false
+	line := 1
+	for {
+		part, err := blameReader.NextPart()
+		if err != nil {
+			log.Error("NextPart: %v", err)
+			break
+		}
+		if part == nil {
+			break
+		}
+		if failed {
+			continue
+		}
+
+		commit, ok := commits[part.Sha]
+		if !ok {
+			gitCommit, err := ctx.Repo.GitRepo.GetCommit(part.Sha)
+			if err != nil {
+				log.Error("GetCommit[%s]: %v", part.Sha, err)
+				failed = true
+				continue
+			}
+			commit = toBlameCommit(repo, gitCommit, now)
+			commits[part.Sha] = commit
+		}
+
+		if line > 1 {
+			_, _ = ctx.Resp.Write([]byte(","))
+		}
+		if err := encoder.Encode(&api.BlameRange{
+			StartLine: line,
+			EndLine:   line + len(part.Lines) - 1,
+			Lines:     part.Lines,
+			Commit:    commit,
+		}); err != nil {
+			log.Error("Encode: %v", err)
+			failed = true
+			continue
+		}
+		line += len(part.Lines)
+	}
+	_, _ = ctx.Resp.Write([]byte("]"))
+}
+
+func toBlameCommit(repo *models.Repository, commit *git.Commit, now time.Time) *api.BlameCommit {
+	sha := commit.ID.String()
+	return &api.BlameCommit{
+		CommitMeta: &api.CommitMeta{
+			URL: repo.APIURL() + "/git/commits/" + sha,
+			SHA: sha,
+		},
+		HTMLURL: repo.HTMLURL() + "/commit/" + sha,
+		Author: &api.CommitUser{
+			Identity: api.Identity{
+				Name:  commit.Author.Name,
+				Email: commit.Author.Email,
+			},
+			Date: commit.Author.When.Format(time.RFC3339),
+		},
+		Committer: &api.CommitUser{
+			Identity: api.Identity{
+				Name:  commit.Committer.Name,
+				Email: commit.Committer.Email,
+			},
+			Date: commit.Committer.When.Format(time.RFC3339),
+		},
+		Summary: commit.Summary(),
+		Age:     int64(now.Sub(commit.Author.When).Seconds()),
+	}
+}
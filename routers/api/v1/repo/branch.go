@@ -17,6 +17,7 @@ import (
 	"code.gitea.io/gitea/modules/repofiles"
 	repo_module "code.gitea.io/gitea/modules/repository"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
 // GetBranch get a branch of a repository
@@ -175,10 +176,6 @@ func DeleteBranch(ctx *context.APIContext) {
 		log.Error("Update: %v", err)
 	}
 
-	if err := ctx.Repo.Repository.AddDeletedBranch(ctx.Repo.BranchName, c.ID.String(), ctx.User.ID); err != nil {
-		log.Warn("AddDeletedBranch: %v", err)
-	}
-
 	ctx.Status(http.StatusNoContent)
 }
 
@@ -290,13 +287,36 @@ func ListBranches(ctx *context.APIContext) {
 	//   description: name of the repo
 	//   type: string
 	//   required: true
+	// - name: prefix
+	//   in: query
+	//   description: only list the branches whose names start with the prefix
+	//   type: string
+	// - name: sort
+	//   in: query
+	//   description: sort order of the branches, alphabetically by default
+	//   type: string
+	//   enum: [alphabetically, newest, oldest]
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results, maximum page size is 50
+	//   type: integer
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/BranchList"
 
-	branches, err := repo_module.GetBranches(ctx.Repo.Repository)
+	listOptions := utils.GetPaginatedListOptions(ctx)
+	branches, total, err := repo_module.GetBranchesPage(ctx.Repo.Repository, git.ListRefsOptions{
+		Prefix:   ctx.Query("prefix"),
+		Sort:     git.RefSortType(ctx.Query("sort")),
+		Page:     listOptions.Page,
+		PageSize: listOptions.PageSize,
+	})
 	if err != nil {
-		ctx.Error(http.StatusInternalServerError, "GetBranches", err)
+		ctx.Error(http.StatusInternalServerError, "GetBranchesPage", err)
 		return
 	}
 
@@ -319,9 +339,126 @@ func ListBranches(ctx *context.APIContext) {
 		}
 	}
 
+	utils.SetPaginationHeaders(ctx, int64(total), listOptions)
 	ctx.JSON(http.StatusOK, &apiBranches)
 }
 
+// ListDeletedBranches list the deleted branches of a repository, which can be restored
+func ListDeletedBranches(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/deleted_branches repository repoListDeletedBranches
+	// ---
+	// summary: List a repository's deleted branches, which can be restored
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/DeletedBranchList"
+
+	deletedBranches, err := ctx.Repo.Repository.GetDeletedBranches()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetDeletedBranches", err)
+		return
+	}
+
+	apiDeletedBranches := make([]*api.DeletedBranch, len(deletedBranches))
+	for i := range deletedBranches {
+		apiDeletedBranches[i] = convert.ToDeletedBranch(deletedBranches[i], ctx.User)
+	}
+	ctx.JSON(http.StatusOK, &apiDeletedBranches)
+}
+
+// RestoreDeletedBranch recreates a deleted branch of a repository
+func RestoreDeletedBranch(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/deleted_branches/{id}/restore repository repoRestoreDeletedBranch
+	// ---
+	// summary: Restore a deleted branch at the commit it pointed to
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the deleted branch
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Branch"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+
+	deletedBranch, err := ctx.Repo.Repository.GetDeletedBranchByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetDeletedBranchByID", err)
+		return
+	}
+	if deletedBranch == nil {
+		ctx.NotFound()
+		return
+	}
+
+	if err := repofiles.RestoreDeletedBranch(ctx.User, ctx.Repo.Repository, deletedBranch); err != nil {
+		switch {
+		case git.IsErrNotExist(err):
+			ctx.NotFound(err)
+		case models.IsErrBranchAlreadyExists(err):
+			ctx.Error(http.StatusConflict, "", err)
+		default:
+			ctx.Error(http.StatusInternalServerError, "RestoreDeletedBranch", err)
+		}
+		return
+	}
+
+	branch, err := repo_module.GetBranch(ctx.Repo.Repository, deletedBranch.Name)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetBranch", err)
+		return
+	}
+
+	commit, err := branch.GetCommit()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCommit", err)
+		return
+	}
+
+	branchProtection, err := ctx.Repo.Repository.GetBranchProtection(branch.Name)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetBranchProtection", err)
+		return
+	}
+
+	br, err := convert.ToBranch(ctx.Repo.Repository, branch, commit, branchProtection, ctx.User, ctx.Repo.IsAdmin())
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "convert.ToBranch", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, br)
+}
+
 // GetBranchProtection gets a branch protection
 func GetBranchProtection(ctx *context.APIContext) {
 	// swagger:operation GET /repos/{owner}/{repo}/branch_protections/{name} repository repoGetBranchProtection
@@ -532,6 +669,8 @@ func CreateBranchProtection(ctx *context.APIContext, form api.CreateBranchProtec
 		RequireSignedCommits:     form.RequireSignedCommits,
 		ProtectedFilePatterns:    form.ProtectedFilePatterns,
 		BlockOnOutdatedBranch:    form.BlockOnOutdatedBranch,
+		EnableMergeQueue:         form.EnableMergeQueue,
+		RequireCodeOwnerReviews:  form.RequireCodeOwnerReviews,
 	}
 
 	err = models.UpdateProtectBranch(ctx.Repo.Repository, protectBranch, models.WhitelistOptions{
@@ -639,7 +778,9 @@ func EditBranchProtection(ctx *context.APIContext, form api.EditBranchProtection
 	if form.EnableStatusCheck != nil {
 		protectBranch.EnableStatusCheck = *form.EnableStatusCheck
 	}
-	if protectBranch.EnableStatusCheck {
+	if !protectBranch.EnableStatusCheck {
+		protectBranch.StatusCheckContexts = nil
+	} else if form.StatusCheckContexts != nil {
 		protectBranch.StatusCheckContexts = form.StatusCheckContexts
 	}
 
@@ -671,6 +812,14 @@ func EditBranchProtection(ctx *context.APIContext, form api.EditBranchProtection
 		protectBranch.BlockOnOutdatedBranch = *form.BlockOnOutdatedBranch
 	}
 
+	if form.EnableMergeQueue != nil {
+		protectBranch.EnableMergeQueue = *form.EnableMergeQueue
+	}
+
+	if form.RequireCodeOwnerReviews != nil {
+		protectBranch.RequireCodeOwnerReviews = *form.RequireCodeOwnerReviews
+	}
+
 	var whitelistUsers []int64
 	if form.PushWhitelistUsernames != nil {
 		whitelistUsers, err = models.GetUserIDsByNames(form.PushWhitelistUsernames, false)
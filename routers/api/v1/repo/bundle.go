@@ -0,0 +1,104 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"net/url"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/services/archiver"
+)
+
+// GetBundle downloads a git bundle of branches and tags of a repository
+func GetBundle(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/bundle repository repoGetBundle
+	// ---
+	// summary: Download a git bundle of branches and tags of a repository
+	// description: The bundles of large repositories are generated in the background and the request answers with 202 until they can be downloaded.
+	// produces:
+	// - application/octet-stream
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: refs
+	//   in: query
+	//   description: branches and tags to include, all of them if empty
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	// responses:
+	//   200:
+	//     description: success
+	//   "202":
+	//     "$ref": "#/responses/RepoArchive"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if ctx.Repo.Repository.IsEmpty {
+		ctx.NotFound()
+		return
+	}
+
+	refNames := ctx.QueryStrings("refs")
+	heads, err := archiver.ResolveBundleHeads(ctx.Repo.Repository.RepoPath(), refNames)
+	if err != nil {
+		if archiver.IsErrBundleRefNotExist(err) {
+			ctx.Error(http.StatusNotFound, "ResolveBundleHeads", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "ResolveBundleHeads", err)
+		}
+		return
+	}
+	if len(heads) == 0 {
+		ctx.NotFound()
+		return
+	}
+
+	var bundlePath string
+	if ctx.Repo.Repository.Size >= setting.Repository.BundleQueueMinSize {
+		var complete bool
+		bundlePath, complete, err = archiver.RequestBundle(ctx.Repo.Repository, heads)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "RequestBundle", err)
+			return
+		}
+		if !complete {
+			downloadURL := ctx.Repo.Repository.APIURL() + "/bundle"
+			if len(refNames) > 0 {
+				downloadURL += "?" + url.Values{"refs": refNames}.Encode()
+			}
+			ctx.JSON(http.StatusAccepted, &api.RepoArchive{
+				Status:      "pending",
+				DownloadURL: downloadURL,
+			})
+			return
+		}
+	} else {
+		bundlePath, err = archiver.WaitForBundle(ctx.Req.Context(), ctx.Repo.Repository, heads)
+		if err != nil {
+			if ctx.Req.Context().Err() != nil {
+				// The client is gone, the bundle is still generated in the background
+				return
+			}
+			ctx.Error(http.StatusInternalServerError, "WaitForBundle", err)
+			return
+		}
+	}
+
+	ctx.ServeFile(bundlePath, ctx.Repo.Repository.Name+".bundle")
+}
@@ -0,0 +1,157 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+	repo_service "code.gitea.io/gitea/services/repository"
+)
+
+// CherryPickCommit applies a commit onto a branch
+func CherryPickCommit(ctx *context.APIContext, form api.CherryPickCommitOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/git/cherry-pick repository repoCherryPickCommit
+	// ---
+	// summary: Cherry-pick a commit onto a branch
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CherryPickCommitOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/CherryPickCommitResponse"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	cherryPickCommit(ctx, form, false)
+}
+
+// RevertCommit applies the revert of a commit onto a branch
+func RevertCommit(ctx *context.APIContext, form api.CherryPickCommitOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/git/revert repository repoRevertCommit
+	// ---
+	// summary: Revert a commit on a branch
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CherryPickCommitOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/CherryPickCommitResponse"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	cherryPickCommit(ctx, form, true)
+}
+
+func cherryPickCommit(ctx *context.APIContext, form api.CherryPickCommitOption, revert bool) {
+	if !git.SHAPattern.MatchString(form.SHA) {
+		ctx.Error(http.StatusUnprocessableEntity, "", "no valid sha: "+form.SHA)
+		return
+	}
+	commit, err := ctx.Repo.GitRepo.GetCommit(form.SHA)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCommit", err)
+		}
+		return
+	}
+	if commit.ParentCount() > 1 {
+		ctx.Error(http.StatusUnprocessableEntity, "", "merge commits cannot be applied")
+		return
+	}
+
+	result, err := repo_service.CherryPick(ctx.User, ctx.Repo.Repository, commit, repo_service.CherryPickOptions{
+		Revert:         revert,
+		Branch:         form.Branch,
+		NewBranch:      form.NewBranch,
+		Message:        form.Message,
+		PullOnConflict: form.PullOnConflict,
+	})
+	if err != nil {
+		switch {
+		case git.IsErrBranchNotExist(err):
+			ctx.NotFound(err)
+		case models.IsErrBranchAlreadyExists(err):
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		case models.IsErrUserCannotCommit(err), git.IsErrPushRejected(err):
+			ctx.Error(http.StatusForbidden, "", err)
+		case models.IsErrCherryPickConflicts(err), git.IsErrPushOutOfDate(err):
+			ctx.Error(http.StatusConflict, "", err)
+		default:
+			ctx.Error(http.StatusInternalServerError, "CherryPick", err)
+		}
+		return
+	}
+
+	newCommit, err := ctx.Repo.GitRepo.GetCommit(result.CommitID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCommit", err)
+		return
+	}
+	apiCommit, err := toCommit(ctx, ctx.Repo.Repository, newCommit, nil)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "toCommit", err)
+		return
+	}
+	resp := &api.CherryPickCommitResponse{
+		Commit:    apiCommit,
+		Branch:    result.Branch,
+		Conflicts: result.Conflicts,
+	}
+	if result.Pull != nil {
+		resp.PullRequest = convert.ToAPIPullRequest(result.Pull)
+	}
+	ctx.JSON(http.StatusCreated, resp)
+}
@@ -46,15 +46,23 @@ func ListCollaborators(ctx *context.APIContext) {
 	//   "200":
 	//     "$ref": "#/responses/UserList"
 
-	collaborators, err := ctx.Repo.Repository.GetCollaborators(utils.GetListOptions(ctx))
+	listOptions := utils.GetPaginatedListOptions(ctx)
+	collaborators, err := ctx.Repo.Repository.GetCollaborators(listOptions)
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "ListCollaborators", err)
 		return
 	}
+	count, err := ctx.Repo.Repository.CountCollaborators()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "CountCollaborators", err)
+		return
+	}
 	users := make([]*api.User, len(collaborators))
 	for i, collaborator := range collaborators {
 		users[i] = convert.ToUser(collaborator.User, ctx.IsSigned, ctx.User != nil && ctx.User.IsAdmin)
 	}
+
+	utils.SetPaginationHeaders(ctx, count, listOptions)
 	ctx.JSON(http.StatusOK, users)
 }
 
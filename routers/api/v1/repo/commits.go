@@ -14,6 +14,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
@@ -301,6 +302,7 @@ func toCommit(ctx *context.APIContext, repo *models.Repository, commit *git.Comm
 				URL: repo.APIURL() + "/git/trees/" + commit.ID.String(),
 				SHA: commit.ID.String(),
 			},
+			Verification: convert.ToVerification(repo, commit),
 		},
 		Author:    apiAuthor,
 		Committer: apiCommitter,
@@ -0,0 +1,126 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/eventsource"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// repoEventUnits are the units a user needs to be able to read to receive the events of a repository
+var repoEventUnits = map[string]models.UnitType{
+	string(models.HookEventPush):        models.UnitTypeCode,
+	string(models.HookEventIssues):      models.UnitTypeIssues,
+	string(models.HookEventPullRequest): models.UnitTypePullRequests,
+	string(models.HookEventRelease):     models.UnitTypeReleases,
+}
+
+// StreamEvents streams the events of a repository
+func StreamEvents(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/events/stream repository repoStreamEvents
+	// ---
+	// summary: Stream the pushes, issues, pull requests and releases of a repository as server-sent events
+	// description: The events are named after the webhook events and carry the same payloads.
+	//              Events of units the user can't read are left out.
+	// produces:
+	// - text/event-stream
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     description: stream of server-sent events
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	ctx.Resp.Header().Set("Content-Type", "text/event-stream")
+	ctx.Resp.Header().Set("Cache-Control", "no-cache")
+	ctx.Resp.Header().Set("Connection", "keep-alive")
+	ctx.Resp.Header().Set("X-Accel-Buffering", "no")
+	ctx.Resp.WriteHeader(http.StatusOK)
+
+	// Listen to connection close and un-register messageChan
+	notify := ctx.Req.Context().Done()
+	ctx.Resp.Flush()
+
+	shutdownCtx := graceful.GetManager().ShutdownContext()
+
+	repoID := ctx.Repo.Repository.ID
+	messageChan := eventsource.GetManager().RegisterRepo(repoID)
+
+	unregister := func() {
+		eventsource.GetManager().UnregisterRepo(repoID, messageChan)
+		// ensure the messageChan is closed
+		for {
+			_, ok := <-messageChan
+			if !ok {
+				break
+			}
+		}
+	}
+
+	if _, err := ctx.Resp.Write([]byte("\n")); err != nil {
+		log.Error("Unable to write to EventStream: %v", err)
+		unregister()
+		return
+	}
+
+	timer := time.NewTicker(30 * time.Second)
+
+loop:
+	for {
+		select {
+		case <-timer.C:
+			event := &eventsource.Event{
+				Name: "ping",
+			}
+			_, err := event.WriteTo(ctx.Resp)
+			if err != nil {
+				log.Error("Unable to write to EventStream for repository %s: %v", ctx.Repo.Repository.FullName(), err)
+				go unregister()
+				break loop
+			}
+			ctx.Resp.Flush()
+		case <-notify:
+			go unregister()
+			break loop
+		case <-shutdownCtx.Done():
+			go unregister()
+			break loop
+		case event, ok := <-messageChan:
+			if !ok {
+				break loop
+			}
+
+			unitType, ok := repoEventUnits[event.Name]
+			if !ok || !ctx.Repo.CanRead(unitType) {
+				continue
+			}
+
+			_, err := event.WriteTo(ctx.Resp)
+			if err != nil {
+				log.Error("Unable to write to EventStream for repository %s: %v", ctx.Repo.Repository.FullName(), err)
+				go unregister()
+				break loop
+			}
+			ctx.Resp.Flush()
+		}
+	}
+	timer.Stop()
+}
@@ -9,6 +9,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/models"
@@ -17,6 +18,7 @@ import (
 	"code.gitea.io/gitea/modules/repofiles"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/routers/repo"
+	"code.gitea.io/gitea/services/archiver"
 )
 
 // GetRawFile get a file by path on a repository
@@ -67,6 +69,54 @@ func GetRawFile(ctx *context.APIContext) {
 	}
 }
 
+// GetRawFileOrLFS get a file by path on a repository, or its content if it is an LFS pointer
+func GetRawFileOrLFS(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/media/{filepath} repository repoGetRawFileOrLFS
+	// ---
+	// summary: Get a file or its LFS object from a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: filepath
+	//   in: path
+	//   description: filepath of the file to get
+	//   type: string
+	//   required: true
+	// responses:
+	//   200:
+	//     description: success
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if ctx.Repo.Repository.IsEmpty {
+		ctx.NotFound()
+		return
+	}
+
+	blob, err := ctx.Repo.Commit.GetBlobByPath(ctx.Repo.TreePath)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetBlobByPath", err)
+		}
+		return
+	}
+	if err = repo.ServeBlobOrLFS(ctx.Context, blob); err != nil {
+		ctx.Error(http.StatusInternalServerError, "ServeBlobOrLFS", err)
+	}
+}
+
 // GetArchive get archive of a repository
 func GetArchive(ctx *context.APIContext) {
 	// swagger:operation GET /repos/{owner}/{repo}/archive/{archive} repository repoGetArchive
@@ -90,9 +140,15 @@ func GetArchive(ctx *context.APIContext) {
 	//   description: archive to download, consisting of a git reference and archive
 	//   type: string
 	//   required: true
+	// - name: submodules
+	//   in: query
+	//   description: include the content of submodules hosted on this instance at their pinned commits, only supported for tar.gz archives
+	//   type: boolean
 	// responses:
 	//   200:
 	//     description: success
+	//   "400":
+	//     "$ref": "#/responses/error"
 	//   "404":
 	//     "$ref": "#/responses/notFound"
 
@@ -108,6 +164,85 @@ func GetArchive(ctx *context.APIContext) {
 	repo.Download(ctx.Context)
 }
 
+// RequestArchive queues the generation of an archive of a repository
+func RequestArchive(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/archive/{archive} repository repoRequestArchive
+	// ---
+	// summary: Request the generation of an archive of a repository
+	// description: The archive is generated in the background and can be downloaded without waiting once its status is complete.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: archive
+	//   in: path
+	//   description: archive to generate, consisting of a git reference and archive
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepoArchive"
+	//   "202":
+	//     "$ref": "#/responses/RepoArchive"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	archive := ctx.Params("*")
+	var archiveType git.ArchiveType
+	switch {
+	case strings.HasSuffix(archive, ".zip"):
+		archiveType = git.ZIP
+	case strings.HasSuffix(archive, ".tar.gz"):
+		archiveType = git.TARGZ
+	default:
+		ctx.NotFound()
+		return
+	}
+	refName := strings.TrimSuffix(archive, archiver.Extension(archiveType))
+
+	gitRepo, err := git.OpenRepository(ctx.Repo.Repository.RepoPath())
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "OpenRepository", err)
+		return
+	}
+	defer gitRepo.Close()
+
+	commit, err := gitRepo.GetCommit(refName)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCommit", err)
+		}
+		return
+	}
+
+	_, complete, err := archiver.RequestArchive(ctx.Repo.Repository, commit.ID.String(), archiveType)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "RequestArchive", err)
+		return
+	}
+	result := &api.RepoArchive{
+		Status:      "pending",
+		DownloadURL: ctx.Repo.Repository.APIURL() + "/archive/" + archive,
+	}
+	if complete {
+		result.Status = "complete"
+		ctx.JSON(http.StatusOK, result)
+		return
+	}
+	ctx.JSON(http.StatusAccepted, result)
+}
+
 // GetEditorconfig get editor config of a repository
 func GetEditorconfig(ctx *context.APIContext) {
 	// swagger:operation GET /repos/{owner}/{repo}/editorconfig/{filepath} repository repoGetEditorConfig
@@ -341,7 +476,7 @@ func UpdateFile(ctx *context.APIContext, apiOpts api.UpdateFileOptions) {
 }
 
 func handleCreateOrUpdateFileError(ctx *context.APIContext, err error) {
-	if models.IsErrUserCannotCommit(err) || models.IsErrFilePathProtected(err) {
+	if models.IsErrUserCannotCommit(err) || models.IsErrFilePathProtected(err) || models.IsErrLFSFileLocked(err) {
 		ctx.Error(http.StatusForbidden, "Access", err)
 		return
 	}
@@ -469,7 +604,7 @@ func DeleteFile(ctx *context.APIContext, apiOpts api.DeleteFileOptions) {
 			models.IsErrSHAOrCommitIDNotProvided(err) {
 			ctx.Error(http.StatusBadRequest, "DeleteFile", err)
 			return
-		} else if models.IsErrUserCannotCommit(err) {
+		} else if models.IsErrUserCannotCommit(err) || models.IsErrLFSFileLocked(err) {
 			ctx.Error(http.StatusForbidden, "DeleteFile", err)
 			return
 		}
@@ -6,10 +6,14 @@ package repo
 
 import (
 	"net/http"
+	"strings"
 
+	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
+	repo_module "code.gitea.io/gitea/modules/repository"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/validation"
 )
 
 // GetGitAllRefs get ref or an list all the refs of a repository
@@ -101,15 +105,7 @@ func getGitRefsInternal(ctx *context.APIContext, filter string) {
 
 	apiRefs := make([]*api.Reference, len(refs))
 	for i := range refs {
-		apiRefs[i] = &api.Reference{
-			Ref: refs[i].Name,
-			URL: ctx.Repo.Repository.APIURL() + "/git/" + refs[i].Name,
-			Object: &api.GitObject{
-				SHA:  refs[i].Object.String(),
-				Type: refs[i].Type,
-				URL:  ctx.Repo.Repository.APIURL() + "/git/" + refs[i].Type + "s/" + refs[i].Object.String(),
-			},
-		}
+		apiRefs[i] = toGitRef(ctx.Repo.Repository, refs[i])
 	}
 	// If single reference is found and it matches filter exactly return it as object
 	if len(apiRefs) == 1 && apiRefs[0].Ref == filter {
@@ -118,3 +114,165 @@ func getGitRefsInternal(ctx *context.APIContext, filter string) {
 	}
 	ctx.JSON(http.StatusOK, &apiRefs)
 }
+
+func toGitRef(repo *models.Repository, ref *git.Reference) *api.Reference {
+	return &api.Reference{
+		Ref: ref.Name,
+		URL: repo.APIURL() + "/git/" + ref.Name,
+		Object: &api.GitObject{
+			SHA:  ref.Object.String(),
+			Type: ref.Type,
+			URL:  repo.APIURL() + "/git/" + ref.Type + "s/" + ref.Object.String(),
+		},
+	}
+}
+
+// CreateGitRef creates a branch or lightweight tag
+func CreateGitRef(ctx *context.APIContext, form api.CreateGitRefOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/git/refs/{ref} repository repoCreateGitRef
+	// ---
+	// summary: Create a branch or lightweight tag pointing to a commit
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: path
+	//   description: name of the ref without the "refs/" prefix, starting with "heads/" or "tags/"
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateGitRefOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Reference"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	refName := getRefNameParam(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !git.SHAPattern.MatchString(form.SHA) {
+		ctx.Error(http.StatusUnprocessableEntity, "", "no valid sha: "+form.SHA)
+		return
+	}
+	commit, err := ctx.Repo.GitRepo.GetCommit(form.SHA)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCommit", err)
+		}
+		return
+	}
+
+	if err := repo_module.CreateRef(ctx.User, ctx.Repo.Repository, refName, commit.ID.String()); err != nil {
+		switch {
+		case models.IsErrBranchAlreadyExists(err), models.IsErrBranchNameConflict(err), models.IsErrTagAlreadyExists(err), git.IsErrPushOutOfDate(err):
+			ctx.Error(http.StatusConflict, "", err)
+		case git.IsErrPushRejected(err):
+			ctx.Error(http.StatusForbidden, "", err)
+		default:
+			ctx.Error(http.StatusInternalServerError, "CreateRef", err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, toGitRef(ctx.Repo.Repository, &git.Reference{
+		Name:   refName,
+		Object: commit.ID,
+		Type:   "commit",
+	}))
+}
+
+// DeleteGitRef deletes a branch or tag
+func DeleteGitRef(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/git/refs/{ref} repository repoDeleteGitRef
+	// ---
+	// summary: Delete a branch or tag
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: path
+	//   description: name of the ref without the "refs/" prefix, starting with "heads/" or "tags/"
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	refName := getRefNameParam(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := repo_module.DeleteRef(ctx.User, ctx.Repo.Repository, refName); err != nil {
+		switch {
+		case git.IsErrNotExist(err):
+			ctx.NotFound()
+		case git.IsErrPushRejected(err):
+			ctx.Error(http.StatusForbidden, "", err)
+		default:
+			ctx.Error(http.StatusInternalServerError, "DeleteRef", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// getRefNameParam returns the full name of the branch or tag ref given as path
+func getRefNameParam(ctx *context.APIContext) string {
+	refName := "refs/" + ctx.Params("*")
+	var name string
+	switch {
+	case strings.HasPrefix(refName, git.BranchPrefix):
+		name = strings.TrimPrefix(refName, git.BranchPrefix)
+	case strings.HasPrefix(refName, git.TagPrefix):
+		name = strings.TrimPrefix(refName, git.TagPrefix)
+	default:
+		ctx.Error(http.StatusUnprocessableEntity, "", "only branches and tags are supported: "+refName)
+		return ""
+	}
+	if name == "" || validation.GitRefNamePatternInvalid.MatchString(name) || !validation.CheckGitRefAdditionalRulesValid(name) {
+		ctx.Error(http.StatusUnprocessableEntity, "", "no valid ref name: "+refName)
+		return ""
+	}
+	return refName
+}
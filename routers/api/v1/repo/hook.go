@@ -6,15 +6,18 @@
 package repo
 
 import (
+	"fmt"
 	"net/http"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
-	"code.gitea.io/gitea/modules/git"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/webhook"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+
+	"github.com/unknwon/com"
 )
 
 // ListHooks list all hooks of a repository
@@ -47,16 +50,24 @@ func ListHooks(ctx *context.APIContext) {
 	//   "200":
 	//     "$ref": "#/responses/HookList"
 
-	hooks, err := models.GetWebhooksByRepoID(ctx.Repo.Repository.ID, utils.GetListOptions(ctx))
+	listOptions := utils.GetPaginatedListOptions(ctx)
+	hooks, err := models.GetWebhooksByRepoID(ctx.Repo.Repository.ID, listOptions)
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "GetWebhooksByRepoID", err)
 		return
 	}
+	count, err := models.CountWebhooksByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "CountWebhooksByRepoID", err)
+		return
+	}
 
 	apiHooks := make([]*api.Hook, len(hooks))
 	for i := range hooks {
 		apiHooks[i] = convert.ToHook(ctx.Repo.RepoLink, hooks[i])
 	}
+
+	utils.SetPaginationHeaders(ctx, count, listOptions)
 	ctx.JSON(http.StatusOK, &apiHooks)
 }
 
@@ -103,7 +114,7 @@ func GetHook(ctx *context.APIContext) {
 func TestHook(ctx *context.APIContext) {
 	// swagger:operation POST /repos/{owner}/{repo}/hooks/{id}/tests repository repoTestHook
 	// ---
-	// summary: Test a push webhook
+	// summary: Test a webhook with a sample payload of one of its events
 	// produces:
 	// - application/json
 	// parameters:
@@ -123,9 +134,17 @@ func TestHook(ctx *context.APIContext) {
 	//   type: integer
 	//   format: int64
 	//   required: true
+	// - name: event
+	//   in: query
+	//   description: event of the sample payload, defaults to push
+	//   type: string
 	// responses:
 	//   "204":
 	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	if ctx.Repo.Commit == nil {
 		// if repo does not have any commits, then don't send a webhook
@@ -139,17 +158,21 @@ func TestHook(ctx *context.APIContext) {
 		return
 	}
 
-	if err := webhook.PrepareWebhook(hook, ctx.Repo.Repository, models.HookEventPush, &api.PushPayload{
-		Ref:    git.BranchPrefix + ctx.Repo.Repository.DefaultBranch,
-		Before: ctx.Repo.Commit.ID.String(),
-		After:  ctx.Repo.Commit.ID.String(),
-		Commits: []*api.PayloadCommit{
-			convert.ToCommit(ctx.Repo.Repository, ctx.Repo.Commit),
-		},
-		Repo:   ctx.Repo.Repository.APIFormat(models.AccessModeNone),
-		Pusher: convert.ToUser(ctx.User, ctx.IsSigned, false),
-		Sender: convert.ToUser(ctx.User, ctx.IsSigned, false),
-	}); err != nil {
+	event := models.HookEventPush
+	if len(ctx.Query("event")) > 0 {
+		event = models.HookEventType(ctx.Query("event"))
+	}
+	if !com.IsSliceContainsStr(hook.EventsArray(), string(event)) {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("hook is not triggered by the %s event", event))
+		return
+	}
+
+	p, err := webhook.GetSamplePayload(event, ctx.Repo.Repository, ctx.User, ctx.Repo.Commit)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetSamplePayload", err)
+		return
+	}
+	if err := webhook.PrepareWebhook(hook, ctx.Repo.Repository, event, p); err != nil {
 		ctx.Error(http.StatusInternalServerError, "PrepareWebhook: ", err)
 		return
 	}
@@ -264,3 +287,307 @@ func DeleteHook(ctx *context.APIContext) {
 	}
 	ctx.Status(http.StatusNoContent)
 }
+
+// ListHookDeliveries list the deliveries of a repo's hook
+func ListHookDeliveries(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/hooks/{id}/deliveries repository repoListHookDeliveries
+	// ---
+	// summary: List the deliveries of a hook
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: dead_letter
+	//   in: query
+	//   description: only list the deliveries of which all attempts have failed
+	//   type: boolean
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results, maximum page size is 50
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/HookDeliveryList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	hook, err := utils.GetRepoHook(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+
+	tasks, err := models.FindHookTasks(models.FindHookTasksOptions{
+		ListOptions:    utils.GetListOptions(ctx),
+		HookID:         hook.ID,
+		DeadLetterOnly: ctx.QueryBool("dead_letter"),
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindHookTasks", err)
+		return
+	}
+
+	deliveries := make([]*api.HookDelivery, len(tasks))
+	for i := range tasks {
+		deliveries[i] = convert.ToHookDelivery(tasks[i])
+	}
+	ctx.JSON(http.StatusOK, &deliveries)
+}
+
+// getHookDelivery loads the delivery given in the url and checks that it belongs to a hook of the repository
+func getHookDelivery(ctx *context.APIContext) *models.HookTask {
+	hook, err := utils.GetRepoHook(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return nil
+	}
+
+	task, err := models.GetHookTaskByHookID(hook.ID, ctx.ParamsInt64(":delivery"))
+	if err != nil {
+		if models.IsErrHookTaskNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetHookTaskByHookID", err)
+		}
+		return nil
+	}
+	return task
+}
+
+// GetHookDelivery get a delivery of a repo's hook
+func GetHookDelivery(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/hooks/{id}/deliveries/{delivery} repository repoGetHookDelivery
+	// ---
+	// summary: Get a delivery of a hook
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: delivery
+	//   in: path
+	//   description: id of the delivery to get
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/HookDelivery"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	task := getHookDelivery(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToHookDelivery(task))
+}
+
+// RedeliverHook delivers the payload of a delivery of a repo's hook again
+func RedeliverHook(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/hooks/{id}/deliveries/{delivery}/redeliver repository repoRedeliverHook
+	// ---
+	// summary: Deliver the payload of a delivery of a hook again
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: delivery
+	//   in: path
+	//   description: id of the delivery to replay
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "202":
+	//     "$ref": "#/responses/HookDelivery"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	task := getHookDelivery(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	replay, err := webhook.ReplayHookTask(task)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ReplayHookTask", err)
+		return
+	}
+	ctx.JSON(http.StatusAccepted, convert.ToHookDelivery(replay))
+}
+
+// ListInheritedHooks list the hooks of the organization receiving the events of a repository
+func ListInheritedHooks(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/hooks/org repository repoListInheritedHooks
+	// ---
+	// summary: List the organization hooks receiving the events of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/InheritedHookList"
+
+	apiHooks := make([]*api.InheritedHook, 0, 5)
+	if !ctx.Repo.Owner.IsOrganization() {
+		ctx.JSON(http.StatusOK, &apiHooks)
+		return
+	}
+
+	hooks, err := models.GetWebhooksByOrgID(ctx.Repo.Owner.ID, models.ListOptions{})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetWebhooksByOrgID", err)
+		return
+	}
+	optedOut, err := models.GetOptedOutWebhookIDs(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetOptedOutWebhookIDs", err)
+		return
+	}
+
+	for _, hook := range hooks {
+		apiHooks = append(apiHooks, convert.ToInheritedHook(hook, util.IsInt64InSlice(hook.ID, optedOut)))
+	}
+	ctx.JSON(http.StatusOK, &apiHooks)
+}
+
+// OptOutInheritedHook stop sending the events of a repository to a hook of its organization
+func OptOutInheritedHook(ctx *context.APIContext) {
+	// swagger:operation PUT /repos/{owner}/{repo}/hooks/org/{id}/opt_out repository repoOptOutInheritedHook
+	// ---
+	// summary: Stop sending the events of a repository to a hook of its organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the organization hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	setInheritedHookOptOut(ctx, true)
+}
+
+// OptInInheritedHook send the events of a repository to a hook of its organization again
+func OptInInheritedHook(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/hooks/org/{id}/opt_out repository repoOptInInheritedHook
+	// ---
+	// summary: Send the events of a repository to a hook of its organization again
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the organization hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	setInheritedHookOptOut(ctx, false)
+}
+
+func setInheritedHookOptOut(ctx *context.APIContext, optOut bool) {
+	hook, err := models.GetWebhookByOrgID(ctx.Repo.Owner.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrWebhookNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetWebhookByOrgID", err)
+		}
+		return
+	}
+
+	if err := models.SetWebhookOptOut(ctx.Repo.Repository.ID, hook.ID, optOut); err != nil {
+		ctx.Error(http.StatusInternalServerError, "SetWebhookOptOut", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -364,6 +364,7 @@ func GetIssue(ctx *context.APIContext) {
 		}
 		return
 	}
+	ctx.SetLastModified(issue.UpdatedUnix.AsTime())
 	ctx.JSON(http.StatusOK, convert.ToAPIIssue(issue))
 }
 
@@ -482,6 +483,181 @@ func CreateIssue(ctx *context.APIContext, form api.CreateIssueOption) {
 	ctx.JSON(http.StatusCreated, convert.ToAPIIssue(issue))
 }
 
+// CreateIssues create several issues of a repository at once
+func CreateIssues(ctx *context.APIContext, form api.CreateIssuesOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/issues/batch issue issueCreateIssues
+	// ---
+	// summary: Create several issues at once, either all of them are created or none.
+	// description: The issues are validated first, if one of them is invalid none is created
+	//   and the errors are returned at the positions of the invalid ones.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateIssuesOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/CreateIssueResultList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/CreateIssueResultList"
+
+	if len(form.Issues) > setting.API.MaxBatchIssues {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("Too many issues, at most %d can be created at once", setting.API.MaxBatchIssues))
+		return
+	}
+
+	results := make([]*api.CreateIssueResult, len(form.Issues))
+	opts := make([]models.NewIssueOptions, len(form.Issues))
+	valid := true
+	for i, issueForm := range form.Issues {
+		results[i] = &api.CreateIssueResult{}
+		opt, msg, err := prepareBatchIssue(ctx, issueForm)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "prepareBatchIssue", err)
+			return
+		}
+		if msg != "" {
+			results[i].Error = msg
+			valid = false
+			continue
+		}
+		opts[i] = *opt
+	}
+	if !valid {
+		ctx.JSON(http.StatusUnprocessableEntity, results)
+		return
+	}
+
+	if err := issue_service.NewIssues(ctx.Repo.Repository, opts); err != nil {
+		ctx.Error(http.StatusInternalServerError, "NewIssues", err)
+		return
+	}
+
+	for i, opt := range opts {
+		if form.Issues[i].Closed {
+			if err := issue_service.ChangeStatus(opt.Issue, ctx.User, true); err != nil {
+				ctx.Error(http.StatusInternalServerError, "ChangeStatus", err)
+				return
+			}
+		}
+
+		// Refetch from database to assign some automatic values
+		issue, err := models.GetIssueByID(opt.Issue.ID)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "GetIssueByID", err)
+			return
+		}
+		results[i].Issue = convert.ToAPIIssue(issue)
+	}
+	ctx.JSON(http.StatusCreated, results)
+}
+
+// prepareBatchIssue returns the options to create the issue of a batch, or the message of
+// the error making it invalid. Like for a single issue, the labels, the assignees, the
+// milestone and the deadline are only set by the writers.
+func prepareBatchIssue(ctx *context.APIContext, form *api.CreateIssueOption) (*models.NewIssueOptions, string, error) {
+	if form == nil || strings.TrimSpace(form.Title) == "" {
+		return nil, "Title is required", nil
+	}
+
+	issue := &models.Issue{
+		RepoID:   ctx.Repo.Repository.ID,
+		Repo:     ctx.Repo.Repository,
+		Title:    form.Title,
+		PosterID: ctx.User.ID,
+		Poster:   ctx.User,
+		Content:  form.Body,
+	}
+	opt := &models.NewIssueOptions{
+		Repo:  ctx.Repo.Repository,
+		Issue: issue,
+	}
+	if !ctx.Repo.CanWrite(models.UnitTypeIssues) {
+		return opt, "", nil
+	}
+
+	if form.Deadline != nil {
+		issue.DeadlineUnix = timeutil.TimeStamp(form.Deadline.Unix())
+	}
+
+	if form.Milestone > 0 {
+		if _, err := models.GetMilestoneByRepoID(ctx.Repo.Repository.ID, form.Milestone); err != nil {
+			if models.IsErrMilestoneNotExist(err) {
+				return nil, fmt.Sprintf("Milestone does not exist: [id: %d]", form.Milestone), nil
+			}
+			return nil, "", err
+		}
+		issue.MilestoneID = form.Milestone
+	}
+
+	if len(form.Labels) > 0 {
+		labels, err := models.GetLabelsInRepoByIDs(ctx.Repo.Repository.ID, form.Labels)
+		if err != nil {
+			return nil, "", err
+		}
+		if ctx.Repo.Owner.IsOrganization() {
+			orgLabels, err := models.GetLabelsInOrgByIDs(ctx.Repo.Owner.ID, form.Labels)
+			if err != nil {
+				return nil, "", err
+			}
+			labels = append(labels, orgLabels...)
+		}
+		found := make(map[int64]bool, len(labels))
+		for _, label := range labels {
+			found[label.ID] = true
+		}
+		for _, id := range form.Labels {
+			if !found[id] {
+				return nil, fmt.Sprintf("Label does not exist: [id: %d]", id), nil
+			}
+		}
+		opt.LabelIDs = form.Labels
+	}
+
+	assigneeIDs, err := models.MakeIDsFromAPIAssigneesToAdd(form.Assignee, form.Assignees)
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			return nil, fmt.Sprintf("Assignee does not exist: [name: %s]", err), nil
+		}
+		return nil, "", err
+	}
+	for _, aID := range assigneeIDs {
+		if util.IsInt64InSlice(aID, opt.AssigneeIDs) {
+			continue
+		}
+		assignee, err := models.GetUserByID(aID)
+		if err != nil {
+			return nil, "", err
+		}
+		valid, err := models.CanBeAssigned(assignee, ctx.Repo.Repository, false)
+		if err != nil {
+			return nil, "", err
+		}
+		if !valid {
+			return nil, models.ErrUserDoesNotHaveAccessToRepo{UserID: aID, RepoName: ctx.Repo.Repository.Name}.Error(), nil
+		}
+		opt.AssigneeIDs = append(opt.AssigneeIDs, aID)
+	}
+
+	return opt, "", nil
+}
+
 // EditIssue modify an issue of a repository
 func EditIssue(ctx *context.APIContext, form api.EditIssueOption) {
 	// swagger:operation PATCH /repos/{owner}/{repo}/issues/{index} issue issueEditIssue
@@ -12,6 +12,7 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
 )
 
 type languageResponse []*models.LanguageStat
@@ -82,3 +83,56 @@ func GetLanguages(ctx *context.APIContext) {
 
 	ctx.JSON(http.StatusOK, resp)
 }
+
+// GetLanguageBar returns the shares of the top languages as shown in the language bar
+func GetLanguageBar(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/languages/bar repository repoGetLanguageBar
+	// ---
+	// summary: Get the shares and colors of the top languages of a repository
+	// produces:
+	//   - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: limit
+	//   in: query
+	//   description: number of languages to return before summarizing the remaining ones as "other", 5 by default
+	//   type: integer
+	// responses:
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "200":
+	//     "$ref": "#/responses/LanguageShareList"
+
+	limit := ctx.QueryInt("limit")
+	if limit <= 0 {
+		limit = 5
+	} else if limit > 50 {
+		limit = 50
+	}
+
+	langs, err := ctx.Repo.Repository.GetTopLanguageStats(limit)
+	if err != nil {
+		log.Error("GetTopLanguageStats failed: %v", err)
+		ctx.InternalServerError(err)
+		return
+	}
+
+	shares := make([]*api.LanguageShare, len(langs))
+	for i, lang := range langs {
+		shares[i] = &api.LanguageShare{
+			Language:   lang.Language,
+			Percentage: lang.Percentage,
+			Color:      lang.Color,
+		}
+	}
+	ctx.JSON(http.StatusOK, shares)
+}
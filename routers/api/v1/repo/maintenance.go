@@ -0,0 +1,125 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/task"
+)
+
+// GetMaintenance get the status of the latest garbage collection of a repository
+func GetMaintenance(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/maintenance repository repoGetMaintenance
+	// ---
+	// summary: Get the status of the latest garbage collection of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepoGarbageCollection"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	t, err := models.GetLatestRepositoryTask(ctx.Repo.Repository.ID, api.TaskTypeGarbageCollect)
+	if err != nil {
+		if models.IsErrTaskDoesNotExist(err) {
+			ctx.NotFound()
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, "GetLatestRepositoryTask", err)
+		return
+	}
+	writeRepoGarbageCollection(ctx, http.StatusOK, t)
+}
+
+// GarbageCollect run git gc on a repository in the background
+func GarbageCollect(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/maintenance/gc repository repoGarbageCollect
+	// ---
+	// summary: Run git gc on a repository in the background, the queued or running job is returned if any
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "202":
+	//     "$ref": "#/responses/RepoGarbageCollection"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	t, err := task.GarbageCollect(ctx.User, ctx.Repo.Repository)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GarbageCollect", err)
+		return
+	}
+	writeRepoGarbageCollection(ctx, http.StatusAccepted, t)
+}
+
+func writeRepoGarbageCollection(ctx *context.APIContext, status int, t *models.Task) {
+	gc, err := ToRepoGarbageCollection(t)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToRepoGarbageCollection", err)
+		return
+	}
+	ctx.JSON(status, gc)
+}
+
+// ToRepoGarbageCollection converts a garbage collection task to its API format
+func ToRepoGarbageCollection(t *models.Task) (*api.RepoGarbageCollection, error) {
+	if err := t.LoadRepo(); err != nil {
+		return nil, err
+	}
+	result, err := task.GetGarbageCollectResult(t)
+	if err != nil {
+		return nil, err
+	}
+
+	gc := &api.RepoGarbageCollection{
+		ID:                 t.ID,
+		Repository:         t.Repo.FullName(),
+		Status:             t.Status.Name(),
+		Errors:             t.Errors,
+		SizeBefore:         result.SizeBefore,
+		SizeAfter:          result.SizeAfter,
+		LooseObjectsBefore: result.LooseObjectsBefore,
+		LooseObjectsAfter:  result.LooseObjectsAfter,
+		PacksBefore:        result.PacksBefore,
+		PacksAfter:         result.PacksAfter,
+		Created:            t.Created.AsTime(),
+	}
+	if t.StartTime > 0 {
+		started := t.StartTime.AsTime()
+		gc.Started = &started
+	}
+	if t.EndTime > 0 {
+		finished := t.EndTime.AsTime()
+		gc.Finished = &finished
+	}
+	return gc, nil
+}
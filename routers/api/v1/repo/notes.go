@@ -0,0 +1,139 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/validation"
+)
+
+// GetNote Get a note corresponding to a single commit from a repository
+func GetNote(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/git/notes/{sha} repository repoGetNote
+	// ---
+	// summary: Get a note corresponding to a single commit from a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: sha
+	//   in: path
+	//   description: a git ref or commit sha
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Note"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	commit := getNoteCommit(ctx)
+	if ctx.Written() {
+		return
+	}
+	getNote(ctx, commit, http.StatusOK)
+}
+
+// AddNote Add a note to a single commit of a repository
+func AddNote(ctx *context.APIContext, form api.CreateNoteOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/git/notes/{sha} repository repoAddNote
+	// ---
+	// summary: Add a note to a single commit of a repository, replacing its existing note
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: sha
+	//   in: path
+	//   description: a git ref or commit sha
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateNoteOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Note"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	commit := getNoteCommit(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := git.AddNote(ctx.Repo.GitRepo, ctx.User.NewGitSig(), commit.ID.String(), []byte(form.Message)); err != nil {
+		ctx.Error(http.StatusInternalServerError, "AddNote", err)
+		return
+	}
+	getNote(ctx, commit, http.StatusCreated)
+}
+
+// getNoteCommit returns the commit of the sha parameter
+func getNoteCommit(ctx *context.APIContext) *git.Commit {
+	sha := ctx.Params(":sha")
+	if (validation.GitRefNamePatternInvalid.MatchString(sha) || !validation.CheckGitRefAdditionalRulesValid(sha)) && !git.SHAPattern.MatchString(sha) {
+		ctx.Error(http.StatusUnprocessableEntity, "no valid ref or sha", fmt.Sprintf("no valid ref or sha: %s", sha))
+		return nil
+	}
+	commit, err := ctx.Repo.GitRepo.GetCommit(sha)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCommit", err)
+		}
+		return nil
+	}
+	return commit
+}
+
+func getNote(ctx *context.APIContext, commit *git.Commit, status int) {
+	note := &git.Note{}
+	if err := git.GetNote(ctx.Repo.GitRepo, commit.ID.String(), note); err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetNote", err)
+		}
+		return
+	}
+
+	cmt, err := toCommit(ctx, ctx.Repo.Repository, commit, nil)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "toCommit", err)
+		return
+	}
+	ctx.JSON(status, api.Note{Message: string(note.Message), Commit: cmt})
+}
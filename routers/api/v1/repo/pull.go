@@ -20,7 +20,9 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/services/automerge"
 	issue_service "code.gitea.io/gitea/services/issue"
+	"code.gitea.io/gitea/services/mergequeue"
 	pull_service "code.gitea.io/gitea/services/pull"
 )
 
@@ -114,7 +116,7 @@ func ListPullRequests(ctx *context.APIContext, form api.ListPullRequestsOptions)
 	}
 
 	ctx.SetLinkHeader(int(maxResults), listOptions.PageSize)
-	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", maxResults))
+	ctx.SetTotalCountHeader(maxResults)
 	ctx.JSON(http.StatusOK, &apiPrs)
 }
 
@@ -166,6 +168,7 @@ func GetPullRequest(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "LoadHeadRepo", err)
 		return
 	}
+	ctx.SetLastModified(pr.Issue.UpdatedUnix.AsTime())
 	ctx.JSON(http.StatusOK, convert.ToAPIPullRequest(pr))
 }
 
@@ -385,6 +388,7 @@ func CreatePullRequest(ctx *context.APIContext, form api.CreatePullRequestOption
 		BaseRepo:   repo,
 		MergeBase:  compareInfo.MergeBase,
 		Type:       models.PullRequestGitea,
+		IsDraft:    form.Draft,
 	}
 
 	// Get all assignee IDs
@@ -708,6 +712,8 @@ func MergePullRequest(ctx *context.APIContext, form auth.MergePullRequestForm) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/empty"
+	//   "202":
+	//     "$ref": "#/responses/empty"
 	//   "405":
 	//     "$ref": "#/responses/empty"
 	//   "409":
@@ -763,12 +769,27 @@ func MergePullRequest(ctx *context.APIContext, form auth.MergePullRequestForm) {
 		return
 	}
 
-	if err := pull_service.CheckPRReadyToMerge(pr); err != nil {
+	if err := pr.LoadProtectedBranch(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadProtectedBranch", err)
+		return
+	}
+	// The pull requests of branches with a merge queue are added to it instead of being merged,
+	// their status checks are checked by the queue
+	useMergeQueue := pr.ProtectedBranch != nil && pr.ProtectedBranch.EnableMergeQueue
+	checkPRReady := pull_service.CheckPRReadyToMerge
+	if useMergeQueue {
+		checkPRReady = pull_service.CheckPRReadyToEnterMergeQueue
+	}
+
+	scheduleMerge := false
+	if err := checkPRReady(pr); err != nil {
 		if !models.IsErrNotAllowedToMerge(err) {
 			ctx.Error(http.StatusInternalServerError, "CheckPRReadyToMerge", err)
 			return
 		}
-		if form.ForceMerge != nil && *form.ForceMerge {
+		if form.MergeWhenChecksSucceed && !useMergeQueue {
+			scheduleMerge = true
+		} else if form.ForceMerge != nil && *form.ForceMerge {
 			if isRepoAdmin, err := models.IsUserRepoAdmin(pr.BaseRepo, ctx.User); err != nil {
 				ctx.Error(http.StatusInternalServerError, "IsUserRepoAdmin", err)
 				return
@@ -795,20 +816,56 @@ func MergePullRequest(ctx *context.APIContext, form auth.MergePullRequestForm) {
 	}
 
 	message := strings.TrimSpace(form.MergeTitleField)
+	form.MergeMessageField = strings.TrimSpace(form.MergeMessageField)
 	if len(message) == 0 {
 		if models.MergeStyle(form.Do) == models.MergeStyleMerge {
 			message = pr.GetDefaultMergeMessage()
 		}
 		if models.MergeStyle(form.Do) == models.MergeStyleSquash {
-			message = pr.GetDefaultSquashMessage()
+			var body string
+			message, body = pull_service.GetSquashMergeMessage(pr)
+			if len(form.MergeMessageField) == 0 {
+				form.MergeMessageField = body
+			}
 		}
 	}
 
-	form.MergeMessageField = strings.TrimSpace(form.MergeMessageField)
 	if len(form.MergeMessageField) > 0 {
 		message += "\n\n" + form.MergeMessageField
 	}
 
+	if useMergeQueue {
+		if _, err := mergequeue.Add(ctx.User, pr, models.MergeStyle(form.Do), message); err != nil {
+			if models.IsErrInvalidMergeStyle(err) {
+				ctx.Status(http.StatusMethodNotAllowed)
+			} else if models.IsErrPullAlreadyInMergeQueue(err) {
+				ctx.Error(http.StatusConflict, "AddToMergeQueue", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "AddToMergeQueue", err)
+			}
+			return
+		}
+		log.Trace("Pull request added to the merge queue: %d", pr.ID)
+		ctx.Status(http.StatusAccepted)
+		return
+	}
+
+	if scheduleMerge {
+		if err := automerge.ScheduleAutoMerge(ctx.User, pr, models.MergeStyle(form.Do), message); err != nil {
+			if models.IsErrInvalidMergeStyle(err) {
+				ctx.Status(http.StatusMethodNotAllowed)
+			} else if models.IsErrPullAutoMergeAlreadyScheduled(err) {
+				ctx.Error(http.StatusConflict, "ScheduleAutoMerge", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "ScheduleAutoMerge", err)
+			}
+			return
+		}
+		log.Trace("Pull request scheduled to be merged: %d", pr.ID)
+		ctx.Status(http.StatusAccepted)
+		return
+	}
+
 	if err := pull_service.Merge(pr, ctx.User, ctx.Repo.GitRepo, models.MergeStyle(form.Do), message); err != nil {
 		if models.IsErrInvalidMergeStyle(err) {
 			ctx.Status(http.StatusMethodNotAllowed)
@@ -822,6 +879,9 @@ func MergePullRequest(ctx *context.APIContext, form auth.MergePullRequestForm) {
 		} else if models.IsErrMergeUnrelatedHistories(err) {
 			conflictError := err.(models.ErrMergeUnrelatedHistories)
 			ctx.JSON(http.StatusConflict, conflictError)
+		} else if models.IsErrMergeDivergingFastForwardOnly(err) {
+			conflictError := err.(models.ErrMergeDivergingFastForwardOnly)
+			ctx.JSON(http.StatusConflict, conflictError)
 		} else if git.IsErrPushOutOfDate(err) {
 			ctx.Error(http.StatusConflict, "Merge", "merge push out of date")
 			return
@@ -842,6 +902,336 @@ func MergePullRequest(ctx *context.APIContext, form auth.MergePullRequestForm) {
 	ctx.Status(http.StatusOK)
 }
 
+// CancelScheduledAutoMerge cancels the scheduled merge of a pull request
+func CancelScheduledAutoMerge(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/pulls/{index}/merge repository repoCancelScheduledAutoMerge
+	// ---
+	// summary: Cancel the scheduled merge of a pull request
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request whose scheduled merge to cancel
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPullRequestByIndex", err)
+		}
+		return
+	}
+
+	autoMerge, err := models.GetScheduledAutoMergeByPullID(pr.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetScheduledAutoMergeByPullID", err)
+		return
+	} else if autoMerge == nil {
+		ctx.NotFound()
+		return
+	}
+
+	// Besides the user who scheduled the merge, the users who can merge the pull request can cancel it
+	if autoMerge.DoerID != ctx.User.ID {
+		allowedMerge, err := pull_service.IsUserAllowedToMerge(pr, ctx.Repo.Permission, ctx.User)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "IsUserAllowedToMerge", err)
+			return
+		}
+		if !allowedMerge {
+			ctx.Error(http.StatusForbidden, "CancelScheduledAutoMerge", "User not allowed to cancel the scheduled merge")
+			return
+		}
+	}
+
+	if err := automerge.CancelScheduledAutoMerge(ctx.User, pr); err != nil {
+		if models.IsErrPullAutoMergeNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "CancelScheduledAutoMerge", err)
+		}
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// GetPullRequestMergeQueueEntry returns the entry of a pull request in the merge queue of its base branch
+func GetPullRequestMergeQueueEntry(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/merge_queue repository repoGetPullRequestMergeQueueEntry
+	// ---
+	// summary: Get the entry of a pull request in the merge queue of its base branch
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullRequestMergeQueueEntry"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPullRequestByIndex", err)
+		}
+		return
+	}
+
+	entry, err := models.GetMergeQueueEntryByPullID(pr.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetMergeQueueEntryByPullID", err)
+		return
+	} else if entry == nil {
+		ctx.NotFound()
+		return
+	}
+	if err := entry.LoadDoer(); err != nil {
+		if !models.IsErrUserNotExist(err) {
+			ctx.Error(http.StatusInternalServerError, "LoadDoer", err)
+			return
+		}
+		entry.Doer = models.NewGhostUser()
+	}
+	position, err := entry.Position()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "Position", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToPullRequestMergeQueueEntry(entry, position))
+}
+
+// ReadyForReviewPullRequest marks a draft pull request as ready for review
+func ReadyForReviewPullRequest(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/ready_for_review repository repoReadyForReviewPullRequest
+	// ---
+	// summary: Mark a draft pull request as ready for review
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullRequest"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+
+	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPullRequestByIndex", err)
+		}
+		return
+	}
+	if err := pr.LoadIssue(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadIssue", err)
+		return
+	}
+	if !pr.Issue.IsPoster(ctx.User.ID) && !ctx.Repo.CanWriteIssuesOrPulls(true) {
+		ctx.Status(http.StatusForbidden)
+		return
+	}
+
+	if err := pull_service.MarkReadyForReview(ctx.User, pr); err != nil {
+		if models.IsErrPullRequestNotDraft(err) {
+			ctx.Error(http.StatusConflict, "MarkReadyForReview", "pull request is not a draft")
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, "MarkReadyForReview", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToAPIPullRequest(pr))
+}
+
+// GetPullRequestCodeOwners returns the code owners who still have to approve a pull request
+func GetPullRequestCodeOwners(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/code_owners repository repoGetPullRequestCodeOwners
+	// ---
+	// summary: Get the owners of the files changed by a pull request who still have to approve it
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullRequestCodeOwners"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPullRequestByIndex", err)
+		}
+		return
+	}
+
+	owners, err := pull_service.GetUnapprovedCodeOwners(pr)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetUnapprovedCodeOwners", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, &api.PullRequestCodeOwners{
+		Required:   pr.ProtectedBranch != nil && pr.ProtectedBranch.RequireCodeOwnerReviews,
+		Unapproved: owners,
+	})
+}
+
+// RemovePullRequestFromMergeQueue removes a pull request from the merge queue of its base branch
+func RemovePullRequestFromMergeQueue(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/pulls/{index}/merge_queue repository repoRemovePullRequestFromMergeQueue
+	// ---
+	// summary: Remove a pull request from the merge queue of its base branch
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request to remove from the merge queue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPullRequestByIndex", err)
+		}
+		return
+	}
+
+	entry, err := models.GetMergeQueueEntryByPullID(pr.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetMergeQueueEntryByPullID", err)
+		return
+	} else if entry == nil {
+		ctx.NotFound()
+		return
+	}
+
+	// Besides the user who added the pull request to the merge queue, the users who can merge it can remove it
+	if entry.DoerID != ctx.User.ID {
+		allowedMerge, err := pull_service.IsUserAllowedToMerge(pr, ctx.Repo.Permission, ctx.User)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "IsUserAllowedToMerge", err)
+			return
+		}
+		if !allowedMerge {
+			ctx.Error(http.StatusForbidden, "RemoveFromMergeQueue", "User not allowed to remove the pull request from the merge queue")
+			return
+		}
+	}
+
+	if err := mergequeue.Remove(ctx.User, pr); err != nil {
+		if models.IsErrPullNotInMergeQueue(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "RemoveFromMergeQueue", err)
+		}
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
 func parseCompareInfo(ctx *context.APIContext, form api.CreatePullRequestOption) (*models.User, *models.Repository, *git.Repository, *git.CompareInfo, string, string) {
 	baseRepo := ctx.Repo.Repository
 
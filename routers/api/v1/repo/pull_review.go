@@ -539,3 +539,241 @@ func prepareSingleReview(ctx *context.APIContext) (*models.Review, *models.PullR
 
 	return review, pr, false
 }
+
+// ListPullReviewThreads lists the review threads of a pull request
+func ListPullReviewThreads(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/threads repository repoListPullReviewThreads
+	// ---
+	// summary: List the review threads of a pull request, including the outdated ones
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullReviewThreadList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.NotFound("GetPullRequestByIndex", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPullRequestByIndex", err)
+		}
+		return
+	}
+	if err = pr.LoadIssue(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadIssue", err)
+		return
+	}
+
+	conversations, err := models.FetchCodeConversations(pr.Issue, ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FetchCodeConversations", err)
+		return
+	}
+
+	apiThreads := make([]*api.PullReviewThread, 0, len(conversations))
+	for _, conv := range conversations {
+		apiThreads = append(apiThreads, convert.ToPullReviewThread(conv, ctx.User))
+	}
+	ctx.JSON(http.StatusOK, apiThreads)
+}
+
+// GetPullReviewThread gets a review thread of a pull request
+func GetPullReviewThread(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/threads/{id} repository repoGetPullReviewThread
+	// ---
+	// summary: Get a review thread of a pull request
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the thread, the id of its first comment
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullReviewThread"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	conv, statusSet := prepareSingleReviewThread(ctx)
+	if statusSet {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToPullReviewThread(conv, ctx.User))
+}
+
+// ResolvePullReviewThread marks a review thread of a pull request as resolved
+func ResolvePullReviewThread(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/threads/{id}/resolve repository repoResolvePullReviewThread
+	// ---
+	// summary: Mark a review thread of a pull request as resolved
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the thread, the id of its first comment
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullReviewThread"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	markPullReviewThread(ctx, true)
+}
+
+// UnresolvePullReviewThread marks a review thread of a pull request as unresolved
+func UnresolvePullReviewThread(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/threads/{id}/unresolve repository repoUnresolvePullReviewThread
+	// ---
+	// summary: Mark a review thread of a pull request as unresolved
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the thread, the id of its first comment
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullReviewThread"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	markPullReviewThread(ctx, false)
+}
+
+func markPullReviewThread(ctx *context.APIContext, isResolve bool) {
+	conv, statusSet := prepareSingleReviewThread(ctx)
+	if statusSet {
+		return
+	}
+
+	canMark, err := models.CanMarkConversation(conv[0].Issue, ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "CanMarkConversation", err)
+		return
+	}
+	if !canMark {
+		ctx.Error(http.StatusForbidden, "CanMarkConversation", "not allowed to resolve the thread")
+		return
+	}
+
+	if err = models.MarkConversation(conv[0], ctx.User, isResolve); err != nil {
+		ctx.Error(http.StatusInternalServerError, "MarkConversation", err)
+		return
+	}
+
+	if conv, err = models.GetCodeConversation(conv[0].Issue, ctx.User, conv[0].ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCodeConversation", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToPullReviewThread(conv, ctx.User))
+}
+
+// prepareSingleReviewThread return the review thread and false or nil and true if an error happen
+func prepareSingleReviewThread(ctx *context.APIContext) (models.CodeConversation, bool) {
+	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.NotFound("GetPullRequestByIndex", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPullRequestByIndex", err)
+		}
+		return nil, true
+	}
+	if err = pr.LoadIssue(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadIssue", err)
+		return nil, true
+	}
+
+	conv, err := models.GetCodeConversation(pr.Issue, ctx.User, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrCommentNotExist(err) {
+			ctx.NotFound("GetCodeConversation", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCodeConversation", err)
+		}
+		return nil, true
+	}
+	return conv, false
+}
@@ -5,15 +5,82 @@
 package repo
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/log"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/task"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/validation"
 	"code.gitea.io/gitea/routers/api/v1/utils"
 	releaseservice "code.gitea.io/gitea/services/release"
+
+	"github.com/gobwas/glob"
 )
 
+// toAPIRelease converts a release with loaded attributes to api.Release
+func toAPIRelease(ctx *context.APIContext, rel *models.Release) *api.Release {
+	apiRel := rel.APIFormat()
+	if rel.IsDraft || ctx.Repo.GitRepo == nil {
+		return apiRel
+	}
+	if ctx.Repo.CanRead(models.UnitTypeCode) {
+		for _, archive := range releaseservice.GetSourceArchives(rel) {
+			apiRel.SourceArchives = append(apiRel.SourceArchives, &api.ReleaseSourceArchive{
+				Name:        archive.Name,
+				Size:        archive.Size,
+				DownloadURL: archive.DownloadURL,
+			})
+		}
+	}
+	return apiRel
+}
+
+// toAPIReleaseWithTagVerification converts a release with loaded attributes to api.Release
+// including the verification of the signature of its tag. It reads the tag and looks up
+// the key of its signer, so lists of releases are converted without it.
+func toAPIReleaseWithTagVerification(ctx *context.APIContext, rel *models.Release) *api.Release {
+	apiRel := toAPIRelease(ctx, rel)
+	if rel.IsDraft || ctx.Repo.GitRepo == nil {
+		return apiRel
+	}
+	verification, err := convert.ToTagVerification(ctx.Repo.Repository, ctx.Repo.GitRepo, rel.TagName)
+	if err != nil {
+		log.Error("ToTagVerification[%s]: %v", rel.TagName, err)
+		return apiRel
+	}
+	apiRel.Verification.Tag = verification
+	return apiRel
+}
+
+// toReleaseExternalAssets converts the external assets options of a release,
+// it returns false if one of the download urls is invalid.
+func toReleaseExternalAssets(ctx *context.APIContext, opts []*api.ReleaseExternalAssetOption) ([]*models.ReleaseExternalAsset, bool) {
+	if opts == nil {
+		return nil, true
+	}
+	assets := make([]*models.ReleaseExternalAsset, 0, len(opts))
+	for _, opt := range opts {
+		if len(opt.Name) == 0 || !validation.IsValidURL(opt.DownloadURL) {
+			ctx.Error(http.StatusUnprocessableEntity, "InvalidExternalAsset", fmt.Errorf("invalid external asset: %q %q", opt.Name, opt.DownloadURL))
+			return nil, false
+		}
+		assets = append(assets, &models.ReleaseExternalAsset{
+			Name: opt.Name,
+			URL:  opt.DownloadURL,
+			Size: opt.Size,
+		})
+	}
+	return assets, true
+}
+
 // GetRelease get a single release of a repository
 func GetRelease(ctx *context.APIContext) {
 	// swagger:operation GET /repos/{owner}/{repo}/releases/{id} repository repoGetRelease
@@ -56,7 +123,141 @@ func GetRelease(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
 		return
 	}
-	ctx.JSON(http.StatusOK, release.APIFormat())
+	ctx.SetLastModified(release.UpdatedUnix.AsTime())
+	ctx.JSON(http.StatusOK, toAPIReleaseWithTagVerification(ctx, release))
+}
+
+// GetLatestRelease gets the most recent non-prerelease, non-draft release of a repository
+func GetLatestRelease(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/releases/latest repository repoGetLatestRelease
+	// ---
+	// summary: Gets the most recent non-prerelease, non-draft release of a repository, sorted by created_at
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Release"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	release, err := models.GetLatestReleaseByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		if models.IsErrReleaseNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetLatestReleaseByRepoID", err)
+		}
+		return
+	}
+	if err := release.LoadAttributes(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return
+	}
+	ctx.SetLastModified(release.UpdatedUnix.AsTime())
+	ctx.JSON(http.StatusOK, toAPIReleaseWithTagVerification(ctx, release))
+}
+
+// CompareReleases returns the changes between two releases
+func CompareReleases(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/releases/compare repository repoCompareReleases
+	// ---
+	// summary: Get the commits, merged pull requests and contributors between two releases
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: head
+	//   in: query
+	//   description: tag name of the release to get the changes of
+	//   type: string
+	//   required: true
+	// - name: base
+	//   in: query
+	//   description: tag name of the release to compare with, defaults to the release before the head
+	//   type: string
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ReleaseChangelog"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	headTag := ctx.Query("head")
+	if len(headTag) == 0 {
+		ctx.Error(http.StatusUnprocessableEntity, "", "head is required")
+		return
+	}
+
+	changelog, err := releaseservice.GetChangelog(ctx.Repo.GitRepo, ctx.Repo.Repository, ctx.Query("base"), headTag)
+	if err != nil {
+		if models.IsErrReleaseNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetChangelog", err)
+		}
+		return
+	}
+
+	apiChangelog := &api.ReleaseChangelog{
+		Commits:      make([]*api.Commit, 0, len(changelog.Commits)),
+		PullRequests: make([]*api.PullRequest, 0, len(changelog.PullRequests)),
+		Contributors: make([]*api.ReleaseContributor, 0, len(changelog.Contributors)),
+	}
+	for _, rel := range []*models.Release{changelog.Base, changelog.Head} {
+		if err := rel.LoadAttributes(); err != nil {
+			ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+			return
+		}
+	}
+	apiChangelog.Base = toAPIReleaseWithTagVerification(ctx, changelog.Base)
+	apiChangelog.Head = toAPIReleaseWithTagVerification(ctx, changelog.Head)
+
+	userCache := make(map[string]*models.User)
+	for _, commit := range changelog.Commits {
+		apiCommit, err := toCommit(ctx, ctx.Repo.Repository, commit, userCache)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "toCommit", err)
+			return
+		}
+		apiChangelog.Commits = append(apiChangelog.Commits, apiCommit)
+	}
+	for _, pr := range changelog.PullRequests {
+		apiChangelog.PullRequests = append(apiChangelog.PullRequests, convert.ToAPIPullRequest(pr))
+	}
+	for _, contributor := range changelog.Contributors {
+		apiContributor := &api.ReleaseContributor{
+			Name:    contributor.Name,
+			Email:   contributor.Email,
+			Commits: contributor.Commits,
+		}
+		if contributor.User != nil {
+			apiContributor.User = contributor.User.APIFormat()
+		}
+		apiChangelog.Contributors = append(apiChangelog.Contributors, apiContributor)
+	}
+
+	ctx.JSON(http.StatusOK, apiChangelog)
 }
 
 // ListReleases list a repository's releases
@@ -79,13 +280,25 @@ func ListReleases(ctx *context.APIContext) {
 	//   required: true
 	// - name: per_page
 	//   in: query
-	//   description: items count every page wants to load
+	//   description: page size of results, deprecated, use limit instead
 	//   type: integer
 	//   deprecated: true
 	// - name: page
 	//   in: query
 	//   description: page number of results to return (1-based)
 	//   type: integer
+	// - name: draft
+	//   in: query
+	//   description: filter (exclude / include) drafts, if you dont have repo write access none will show
+	//   type: boolean
+	// - name: pre-release
+	//   in: query
+	//   description: filter (exclude / include) pre-releases
+	//   type: boolean
+	// - name: q
+	//   in: query
+	//   description: filter by tag name, either a glob pattern like `v1.*` or a substring
+	//   type: string
 	// - name: limit
 	//   in: query
 	//   description: page size of results, maximum page size is 50
@@ -93,28 +306,45 @@ func ListReleases(ctx *context.APIContext) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/ReleaseList"
-	listOptions := utils.GetListOptions(ctx)
-	if ctx.QueryInt("per_page") != 0 {
-		listOptions.PageSize = ctx.QueryInt("per_page")
+	listOptions := utils.GetPaginatedListOptions(ctx)
+	if ctx.QueryInt("per_page") != 0 && ctx.QueryInt("limit") == 0 {
+		// per_page is the deprecated name of limit
+		listOptions.PageSize = convert.ToCorrectPageSize(ctx.QueryInt("per_page"))
 	}
 
-	releases, err := models.GetReleasesByRepoID(ctx.Repo.Repository.ID, models.FindReleasesOptions{
+	opts := models.FindReleasesOptions{
 		ListOptions:   listOptions,
 		IncludeDrafts: ctx.Repo.AccessMode >= models.AccessModeWrite,
 		IncludeTags:   false,
-	})
+		Keyword:       strings.TrimSpace(ctx.Query("q")),
+	}
+	if ctx.Query("draft") != "" {
+		opts.IsDraft = util.OptionalBoolOf(ctx.QueryBool("draft"))
+	}
+	if ctx.Query("pre-release") != "" {
+		opts.IsPreRelease = util.OptionalBoolOf(ctx.QueryBool("pre-release"))
+	}
+
+	releases, err := models.GetReleasesByRepoID(ctx.Repo.Repository.ID, opts)
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "GetReleasesByRepoID", err)
 		return
 	}
+	count, err := models.GetReleaseCountByRepoID(ctx.Repo.Repository.ID, opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetReleaseCountByRepoID", err)
+		return
+	}
 	rels := make([]*api.Release, len(releases))
 	for i, release := range releases {
 		if err := release.LoadAttributes(); err != nil {
 			ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
 			return
 		}
-		rels[i] = release.APIFormat()
+		rels[i] = toAPIRelease(ctx, release)
 	}
+
+	utils.SetPaginationHeaders(ctx, count, listOptions)
 	ctx.JSON(http.StatusOK, rels)
 }
 
@@ -147,6 +377,13 @@ func CreateRelease(ctx *context.APIContext, form api.CreateReleaseOption) {
 	//     "$ref": "#/responses/Release"
 	//   "409":
 	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	externalAssets, ok := toReleaseExternalAssets(ctx, form.ExternalAssets)
+	if !ok {
+		return
+	}
 
 	rel, err := models.GetRelease(ctx.Repo.Repository.ID, form.TagName)
 	if err != nil {
@@ -159,17 +396,18 @@ func CreateRelease(ctx *context.APIContext, form api.CreateReleaseOption) {
 			form.Target = ctx.Repo.Repository.DefaultBranch
 		}
 		rel = &models.Release{
-			RepoID:       ctx.Repo.Repository.ID,
-			PublisherID:  ctx.User.ID,
-			Publisher:    ctx.User,
-			TagName:      form.TagName,
-			Target:       form.Target,
-			Title:        form.Title,
-			Note:         form.Note,
-			IsDraft:      form.IsDraft,
-			IsPrerelease: form.IsPrerelease,
-			IsTag:        false,
-			Repo:         ctx.Repo.Repository,
+			RepoID:         ctx.Repo.Repository.ID,
+			PublisherID:    ctx.User.ID,
+			Publisher:      ctx.User,
+			TagName:        form.TagName,
+			Target:         form.Target,
+			Title:          form.Title,
+			Note:           form.Note,
+			IsDraft:        form.IsDraft,
+			IsPrerelease:   form.IsPrerelease,
+			IsTag:          false,
+			Repo:           ctx.Repo.Repository,
+			ExternalAssets: externalAssets,
 		}
 		if err := releaseservice.CreateRelease(ctx.Repo.GitRepo, rel, nil); err != nil {
 			if models.IsErrReleaseAlreadyExist(err) {
@@ -193,13 +431,14 @@ func CreateRelease(ctx *context.APIContext, form api.CreateReleaseOption) {
 		rel.IsTag = false
 		rel.Repo = ctx.Repo.Repository
 		rel.Publisher = ctx.User
+		rel.ExternalAssets = externalAssets
 
 		if err = releaseservice.UpdateRelease(ctx.User, ctx.Repo.GitRepo, rel, nil); err != nil {
 			ctx.ServerError("UpdateRelease", err)
 			return
 		}
 	}
-	ctx.JSON(http.StatusCreated, rel.APIFormat())
+	ctx.JSON(http.StatusCreated, toAPIReleaseWithTagVerification(ctx, rel))
 }
 
 // EditRelease edit a release
@@ -235,6 +474,10 @@ func EditRelease(ctx *context.APIContext, form api.EditReleaseOption) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/Release"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	id := ctx.ParamsInt64(":id")
 	rel, err := models.GetReleaseByID(id)
@@ -266,7 +509,16 @@ func EditRelease(ctx *context.APIContext, form api.EditReleaseOption) {
 	if form.IsPrerelease != nil {
 		rel.IsPrerelease = *form.IsPrerelease
 	}
+	externalAssets, ok := toReleaseExternalAssets(ctx, form.ExternalAssets)
+	if !ok {
+		return
+	}
+	rel.ExternalAssets = externalAssets
 	if err := releaseservice.UpdateRelease(ctx.User, ctx.Repo.GitRepo, rel, nil); err != nil {
+		if models.IsErrReleaseProtected(err) {
+			ctx.Error(http.StatusForbidden, "ReleaseProtected", err)
+			return
+		}
 		ctx.Error(http.StatusInternalServerError, "UpdateRelease", err)
 		return
 	}
@@ -280,7 +532,7 @@ func EditRelease(ctx *context.APIContext, form api.EditReleaseOption) {
 		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
 		return
 	}
-	ctx.JSON(http.StatusOK, rel.APIFormat())
+	ctx.JSON(http.StatusOK, toAPIReleaseWithTagVerification(ctx, rel))
 }
 
 // DeleteRelease delete a release from a repository
@@ -308,6 +560,8 @@ func DeleteRelease(ctx *context.APIContext) {
 	// responses:
 	//   "204":
 	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
 
 	id := ctx.ParamsInt64(":id")
 	rel, err := models.GetReleaseByID(id)
@@ -321,8 +575,248 @@ func DeleteRelease(ctx *context.APIContext) {
 		return
 	}
 	if err := releaseservice.DeleteReleaseByID(id, ctx.User, false); err != nil {
+		if models.IsErrReleaseProtected(err) {
+			ctx.Error(http.StatusForbidden, "ReleaseProtected", err)
+			return
+		}
 		ctx.Error(http.StatusInternalServerError, "DeleteReleaseByID", err)
 		return
 	}
 	ctx.Status(http.StatusNoContent)
 }
+
+// DeleteReleases delete the releases of a repository matching a filter in the background
+func DeleteReleases(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/releases repository repoDeleteReleases
+	// ---
+	// summary: Delete the releases matching a filter in the background, at least one filter is required
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: draft
+	//   in: query
+	//   description: only delete drafts
+	//   type: boolean
+	// - name: before
+	//   in: query
+	//   description: only delete the releases created before this time, format in RFC 3339
+	//   type: string
+	//   format: date-time
+	// - name: tag
+	//   in: query
+	//   description: only delete the releases whose tag name matches this glob pattern, e.g. `nightly-*`
+	//   type: string
+	// responses:
+	//   "202":
+	//     "$ref": "#/responses/ReleasesJob"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	opts := task.DeleteReleasesOptions{
+		DraftOnly:  ctx.QueryBool("draft"),
+		TagPattern: strings.TrimSpace(ctx.Query("tag")),
+	}
+	if before := ctx.Query("before"); before != "" {
+		t, err := time.Parse(time.RFC3339, before)
+		if err != nil {
+			ctx.Error(http.StatusUnprocessableEntity, "InvalidBefore", err)
+			return
+		}
+		opts.CreatedBefore = timeutil.TimeStamp(t.Unix())
+	}
+	if !opts.DraftOnly && opts.CreatedBefore == 0 && opts.TagPattern == "" {
+		ctx.Error(http.StatusUnprocessableEntity, "NoFilter", fmt.Errorf("at least one of draft, before or tag is required"))
+		return
+	}
+
+	if opts.TagPattern != "" {
+		if _, err := glob.Compile(opts.TagPattern); err != nil {
+			ctx.Error(http.StatusUnprocessableEntity, "InvalidTag", err)
+			return
+		}
+	}
+
+	t, err := task.DeleteReleases(ctx.User, ctx.Repo.Repository, opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteReleases", err)
+		return
+	}
+	ctx.JSON(http.StatusAccepted, toAPIReleasesJob(t))
+}
+
+// GetReleasesJob get the status of a background job on the releases of a repository
+func GetReleasesJob(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/releases/jobs/{id} repository repoGetReleasesJob
+	// ---
+	// summary: Get the status of a job deleting releases
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the job
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ReleasesJob"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	t, err := models.GetRepositoryTask(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"), api.TaskTypeDeleteReleases)
+	if err != nil {
+		if models.IsErrTaskDoesNotExist(err) {
+			ctx.NotFound()
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, "GetRepositoryTask", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, toAPIReleasesJob(t))
+}
+
+func toAPIReleasesJob(t *models.Task) *api.ReleasesJob {
+	return &api.ReleasesJob{
+		ID:      t.ID,
+		Status:  t.Status.Name(),
+		Errors:  t.Errors,
+		Created: t.Created.AsTime(),
+	}
+}
+
+// YankRelease yank a release
+func YankRelease(ctx *context.APIContext, form api.YankReleaseOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/releases/{id}/yank repository repoYankRelease
+	// ---
+	// summary: Yank a release, its tag and assets are kept
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the release to yank
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/YankReleaseOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Release"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	rel := getPublishedRelease(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := releaseservice.YankRelease(ctx.User, rel, form.Reason); err != nil {
+		if models.IsErrReleaseYankReasonRequired(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "ReleaseYankReasonRequired", err)
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, "YankRelease", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, toAPIReleaseWithTagVerification(ctx, rel))
+}
+
+// UnyankRelease restore a yanked release
+func UnyankRelease(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/releases/{id}/yank repository repoUnyankRelease
+	// ---
+	// summary: Restore a yanked release
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the release to restore
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Release"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	rel := getPublishedRelease(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := releaseservice.UnyankRelease(ctx.User, rel); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UnyankRelease", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, toAPIReleaseWithTagVerification(ctx, rel))
+}
+
+// getPublishedRelease returns the published release of the ":id" parameter with its attributes loaded
+func getPublishedRelease(ctx *context.APIContext) *models.Release {
+	rel, err := models.GetReleaseByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrReleaseNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetReleaseByID", err)
+		}
+		return nil
+	}
+	if rel.IsTag || rel.IsDraft || rel.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound()
+		return nil
+	}
+	if err := rel.LoadAttributes(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return nil
+	}
+	return rel
+}
@@ -5,7 +5,9 @@
 package repo
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"code.gitea.io/gitea/models"
@@ -13,7 +15,8 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
-	"code.gitea.io/gitea/modules/upload"
+	upload_module "code.gitea.io/gitea/modules/upload"
+	releaseservice "code.gitea.io/gitea/services/release"
 )
 
 // GetReleaseAttachment gets a single attachment of the release
@@ -142,6 +145,12 @@ func CreateReleaseAttachment(ctx *context.APIContext) {
 	//   description: name of the attachment
 	//   type: string
 	//   required: false
+	// - name: role
+	//   in: query
+	//   description: role of the attachment, empty for a regular asset
+	//   type: string
+	//   enum: [signature, certificate, provenance]
+	//   required: false
 	// - name: attachment
 	//   in: formData
 	//   description: attachment to upload
@@ -152,6 +161,8 @@ func CreateReleaseAttachment(ctx *context.APIContext) {
 	//     "$ref": "#/responses/Attachment"
 	//   "400":
 	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	// Check if attachments are enabled
 	if !setting.AttachmentEnabled {
@@ -167,6 +178,12 @@ func CreateReleaseAttachment(ctx *context.APIContext) {
 		return
 	}
 
+	role := models.AttachmentRole(ctx.Query("role"))
+	if !role.IsValid() {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("invalid attachment role: %s", role))
+		return
+	}
+
 	// Get uploaded file from request
 	file, header, err := ctx.GetFile("attachment")
 	if err != nil {
@@ -175,6 +192,15 @@ func CreateReleaseAttachment(ctx *context.APIContext) {
 	}
 	defer file.Close()
 
+	if err = models.CheckReleaseAssetQuota(release, header.Size); err != nil {
+		if models.IsErrReleaseAssetQuotaExceeded(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "ReleaseAssetQuotaExceeded", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "CheckReleaseAssetQuota", err)
+		}
+		return
+	}
+
 	buf := make([]byte, 1024)
 	n, _ := file.Read(buf)
 	if n > 0 {
@@ -182,7 +208,7 @@ func CreateReleaseAttachment(ctx *context.APIContext) {
 	}
 
 	// Check if the filetype is allowed by the settings
-	err = upload.VerifyAllowedContentType(buf, strings.Split(setting.AttachmentAllowedTypes, ","))
+	err = upload_module.VerifyAllowedContentType(buf, strings.Split(setting.AttachmentAllowedTypes, ","))
 	if err != nil {
 		ctx.Error(http.StatusBadRequest, "DetectContentType", err)
 		return
@@ -194,13 +220,13 @@ func CreateReleaseAttachment(ctx *context.APIContext) {
 	}
 
 	// Create a new attachment and save the file
-	attach, err := models.NewAttachment(&models.Attachment{
+	attach, err := releaseservice.CreateAttachment(ctx.User, release, &models.Attachment{
 		UploaderID: ctx.User.ID,
 		Name:       filename,
-		ReleaseID:  release.ID,
+		Role:       role,
 	}, buf, file)
 	if err != nil {
-		ctx.Error(http.StatusInternalServerError, "NewAttachment", err)
+		ctx.Error(http.StatusInternalServerError, "CreateAttachment", err)
 		return
 	}
 
@@ -246,6 +272,8 @@ func EditReleaseAttachment(ctx *context.APIContext, form api.EditAttachmentOptio
 	// responses:
 	//   "201":
 	//     "$ref": "#/responses/Attachment"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	// Check if release exists an load release
 	releaseID := ctx.ParamsInt64(":id")
@@ -261,13 +289,31 @@ func EditReleaseAttachment(ctx *context.APIContext, form api.EditAttachmentOptio
 		return
 	}
 	// FIXME Should prove the existence of the given repo, but results in unnecessary database requests
+	renamed := form.Name != "" && form.Name != attach.Name
 	if form.Name != "" {
 		attach.Name = form.Name
 	}
+	if form.Role != nil {
+		role := models.AttachmentRole(*form.Role)
+		if !role.IsValid() {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("invalid attachment role: %s", role))
+			return
+		}
+		attach.Role = role
+	}
 
 	if err := models.UpdateAttachment(attach); err != nil {
 		ctx.Error(http.StatusInternalServerError, "UpdateAttachment", attach)
 	}
+
+	// The copy in the release CDN is named after the attachment, so it is mirrored again
+	if renamed && attach.MirrorPath != "" {
+		if err := releaseservice.RemoveAttachmentMirror(attach); err != nil {
+			log.Error("RemoveAttachmentMirror: %v", err)
+		} else if release, err := models.GetReleaseByID(releaseID); err == nil {
+			releaseservice.AddToCDNQueue(release)
+		}
+	}
 	ctx.JSON(http.StatusCreated, attach.APIFormat())
 }
 
@@ -320,9 +366,289 @@ func DeleteReleaseAttachment(ctx *context.APIContext) {
 	}
 	// FIXME Should prove the existence of the given repo, but results in unnecessary database requests
 
-	if err := models.DeleteAttachment(attach, true); err != nil {
+	release, err := models.GetReleaseByID(releaseID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetReleaseByID", err)
+		return
+	}
+	if err := releaseservice.DeleteAttachment(ctx.User, release, attach); err != nil {
 		ctx.Error(http.StatusInternalServerError, "DeleteAttachment", err)
 		return
 	}
 	ctx.Status(http.StatusNoContent)
 }
+
+// getReleaseAttachmentUpload loads the upload given in the url and checks that it belongs to the release of the repository
+func getReleaseAttachmentUpload(ctx *context.APIContext) *models.AttachmentUpload {
+	releaseID := ctx.ParamsInt64(":id")
+	upload, err := models.GetAttachmentUploadByUUID(ctx.Params(":uuid"))
+	if err != nil {
+		if models.IsErrAttachmentUploadNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetAttachmentUploadByUUID", err)
+		}
+		return nil
+	}
+	if upload.ReleaseID != releaseID {
+		log.Info("User requested upload is not in release, release_id %v, upload: %v", releaseID, upload.UUID)
+		ctx.NotFound()
+		return nil
+	}
+	release, err := models.GetReleaseByID(releaseID)
+	if err != nil {
+		if models.IsErrReleaseNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetReleaseByID", err)
+		}
+		return nil
+	}
+	if release.RepoID != ctx.Repo.Repository.ID {
+		log.Info("User requested upload is not in repository, repo_id %v, upload: %v", ctx.Repo.Repository.ID, upload.UUID)
+		ctx.NotFound()
+		return nil
+	}
+	return upload
+}
+
+// CreateReleaseAttachmentUpload starts a resumable upload of a release attachment
+func CreateReleaseAttachmentUpload(ctx *context.APIContext, form api.CreateAttachmentUploadOptions) {
+	// swagger:operation POST /repos/{owner}/{repo}/releases/{id}/assets/uploads repository repoCreateReleaseAttachmentUpload
+	// ---
+	// summary: Start a resumable upload of a release attachment
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the release
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateAttachmentUploadOptions"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/AttachmentUpload"
+	//   "400":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	// Check if attachments are enabled
+	if !setting.AttachmentEnabled {
+		ctx.NotFound("Attachment is not enabled")
+		return
+	}
+
+	release, err := models.GetReleaseByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrReleaseNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetReleaseByID", err)
+		}
+		return
+	}
+	if release.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound()
+		return
+	}
+
+	upload, err := releaseservice.NewAttachmentUpload(ctx.User, release, form.Name, form.Size)
+	if err != nil {
+		if models.IsErrReleaseAssetQuotaExceeded(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "ReleaseAssetQuotaExceeded", err)
+			return
+		}
+		ctx.Error(http.StatusBadRequest, "NewAttachmentUpload", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, upload.APIFormat())
+}
+
+// GetReleaseAttachmentUpload gets the state of a resumable upload of a release attachment
+func GetReleaseAttachmentUpload(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/releases/{id}/assets/uploads/{uuid} repository repoGetReleaseAttachmentUpload
+	// ---
+	// summary: Get the state of a resumable upload of a release attachment
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the release
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: uuid
+	//   in: path
+	//   description: uuid of the upload
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AttachmentUpload"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	upload := getReleaseAttachmentUpload(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Header().Set("Upload-Offset", strconv.FormatInt(upload.Received, 10))
+	ctx.JSON(http.StatusOK, upload.APIFormat())
+}
+
+// UploadReleaseAttachmentChunk appends a chunk to a resumable upload of a release attachment
+func UploadReleaseAttachmentChunk(ctx *context.APIContext) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/releases/{id}/assets/uploads/{uuid} repository repoUploadReleaseAttachmentChunk
+	// ---
+	// summary: Upload the next chunk of a resumable upload of a release attachment
+	// description: The chunk is the raw request body. Once the last chunk is received the attachment is created and returned.
+	// consumes:
+	// - application/octet-stream
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the release
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: uuid
+	//   in: path
+	//   description: uuid of the upload
+	//   type: string
+	//   required: true
+	// - name: Upload-Offset
+	//   in: header
+	//   description: offset of the chunk, has to match the amount of bytes already received
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AttachmentUpload"
+	//   "201":
+	//     "$ref": "#/responses/Attachment"
+	//   "400":
+	//     "$ref": "#/responses/error"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+
+	upload := getReleaseAttachmentUpload(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	offset, err := strconv.ParseInt(ctx.Req.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		ctx.Error(http.StatusBadRequest, "Upload-Offset", err)
+		return
+	}
+
+	attach, err := releaseservice.AppendAttachmentUpload(ctx.User, upload, offset, ctx.Req.Request.Body)
+	if err != nil {
+		if models.IsErrAttachmentUploadOffsetMismatch(err) {
+			ctx.Header().Set("Upload-Offset", strconv.FormatInt(upload.Received, 10))
+			ctx.Error(http.StatusConflict, "AppendAttachmentUpload", err)
+		} else if models.IsErrAttachmentUploadNotExist(err) {
+			ctx.NotFound()
+		} else if upload_module.IsErrFileTypeForbidden(err) {
+			ctx.Error(http.StatusBadRequest, "AppendAttachmentUpload", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "AppendAttachmentUpload", err)
+		}
+		return
+	}
+
+	if attach != nil {
+		ctx.JSON(http.StatusCreated, attach.APIFormat())
+		return
+	}
+	ctx.Header().Set("Upload-Offset", strconv.FormatInt(upload.Received, 10))
+	ctx.JSON(http.StatusOK, upload.APIFormat())
+}
+
+// DeleteReleaseAttachmentUpload aborts a resumable upload of a release attachment
+func DeleteReleaseAttachmentUpload(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/releases/{id}/assets/uploads/{uuid} repository repoDeleteReleaseAttachmentUpload
+	// ---
+	// summary: Abort a resumable upload of a release attachment
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the release
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: uuid
+	//   in: path
+	//   description: uuid of the upload
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	upload := getReleaseAttachmentUpload(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := models.DeleteAttachmentUpload(upload); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteAttachmentUpload", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,262 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	releaseservice "code.gitea.io/gitea/services/release"
+)
+
+// ListReleaseComments list all the comments of a release
+func ListReleaseComments(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/releases/{id}/comments repository repoListReleaseComments
+	// ---
+	// summary: List all comments on a release
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the release
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results, maximum page size is 50
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ReleaseCommentList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	rel := getPublishedRelease(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	comments, err := models.FindReleaseComments(rel.ID, utils.GetListOptions(ctx))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindReleaseComments", err)
+		return
+	}
+
+	apiComments := make([]*api.ReleaseComment, len(comments))
+	for i, comment := range comments {
+		comment.Release = rel
+		apiComments[i] = comment.APIFormat()
+	}
+	ctx.JSON(http.StatusOK, &apiComments)
+}
+
+// CreateReleaseComment create a comment on a release
+func CreateReleaseComment(ctx *context.APIContext, form api.CreateReleaseCommentOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/releases/{id}/comments repository repoCreateReleaseComment
+	// ---
+	// summary: Add a comment to a release
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the release
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateReleaseCommentOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/ReleaseComment"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	rel := getPublishedRelease(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	comment, err := releaseservice.CreateReleaseComment(ctx.User, rel, form.Body)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "CreateReleaseComment", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, comment.APIFormat())
+}
+
+// EditReleaseComment modify a comment of a release
+func EditReleaseComment(ctx *context.APIContext, form api.EditReleaseCommentOption) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/releases/{id}/comments/{comment_id} repository repoEditReleaseComment
+	// ---
+	// summary: Edit a comment of a release
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the release
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: comment_id
+	//   in: path
+	//   description: id of the comment to edit
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditReleaseCommentOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ReleaseComment"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	comment := getReleaseComment(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	oldContent := comment.Content
+	comment.Content = form.Body
+	if err := releaseservice.UpdateReleaseComment(ctx.User, comment, oldContent); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdateReleaseComment", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, comment.APIFormat())
+}
+
+// DeleteReleaseComment delete a comment from a release
+func DeleteReleaseComment(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/releases/{id}/comments/{comment_id} repository repoDeleteReleaseComment
+	// ---
+	// summary: Delete a comment of a release
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the release
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: comment_id
+	//   in: path
+	//   description: id of the comment to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	comment := getReleaseComment(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := releaseservice.DeleteReleaseComment(ctx.User, comment); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteReleaseComment", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// getReleaseComment returns the comment of the ":comment_id" parameter if it belongs to the
+// published release of the ":id" parameter and the user is allowed to modify it.
+func getReleaseComment(ctx *context.APIContext) *models.ReleaseComment {
+	rel := getPublishedRelease(ctx)
+	if ctx.Written() {
+		return nil
+	}
+
+	comment, err := models.GetReleaseCommentByID(ctx.ParamsInt64(":comment"))
+	if err != nil {
+		if models.IsErrReleaseCommentNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetReleaseCommentByID", err)
+		}
+		return nil
+	}
+	if comment.ReleaseID != rel.ID {
+		ctx.NotFound()
+		return nil
+	}
+
+	if ctx.User.ID != comment.PosterID && !ctx.Repo.IsAdmin() {
+		ctx.Status(http.StatusForbidden)
+		return nil
+	}
+
+	comment.Release = rel
+	if err := comment.LoadPoster(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadPoster", err)
+		return nil
+	}
+	return comment
+}
@@ -14,6 +14,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/util"
@@ -221,7 +222,7 @@ func Search(ctx *context.APIContext) {
 	}
 
 	ctx.SetLinkHeader(int(count), opts.PageSize)
-	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", count))
+	ctx.SetTotalCountHeader(count)
 	ctx.JSON(http.StatusOK, api.SearchResults{
 		OK:   true,
 		Data: results,
@@ -234,21 +235,23 @@ func CreateUserRepo(ctx *context.APIContext, owner *models.User, opt api.CreateR
 		opt.Readme = "Default"
 	}
 	repo, err := repo_service.CreateRepository(ctx.User, owner, models.CreateRepoOptions{
-		Name:          opt.Name,
-		Description:   opt.Description,
-		IssueLabels:   opt.IssueLabels,
-		Gitignores:    opt.Gitignores,
-		License:       opt.License,
-		Readme:        opt.Readme,
-		IsPrivate:     opt.Private,
-		AutoInit:      opt.AutoInit,
-		DefaultBranch: opt.DefaultBranch,
+		Name:             opt.Name,
+		Description:      opt.Description,
+		IssueLabels:      opt.IssueLabels,
+		Gitignores:       opt.Gitignores,
+		License:          opt.License,
+		Readme:           opt.Readme,
+		IsPrivate:        opt.Private,
+		AutoInit:         opt.AutoInit,
+		DefaultBranch:    opt.DefaultBranch,
+		ObjectFormatName: opt.ObjectFormatName,
 	})
 	if err != nil {
 		if models.IsErrRepoAlreadyExist(err) {
 			ctx.Error(http.StatusConflict, "", "The repository with the same name already exists.")
 		} else if models.IsErrNameReserved(err) ||
-			models.IsErrNamePatternNotAllowed(err) {
+			models.IsErrNamePatternNotAllowed(err) ||
+			git.IsErrUnsupportedObjectFormat(err) {
 			ctx.Error(http.StatusUnprocessableEntity, "", err)
 		} else {
 			ctx.Error(http.StatusInternalServerError, "CreateRepository", err)
@@ -397,6 +400,7 @@ func Get(ctx *context.APIContext) {
 	//   "200":
 	//     "$ref": "#/responses/Repository"
 
+	ctx.SetLastModified(ctx.Repo.Repository.UpdatedUnix.AsTime())
 	ctx.JSON(http.StatusOK, ctx.Repo.Repository.APIFormat(ctx.Repo.AccessMode))
 }
 
@@ -436,6 +440,7 @@ func GetByID(ctx *context.APIContext) {
 		ctx.NotFound()
 		return
 	}
+	ctx.SetLastModified(repo.UpdatedUnix.AsTime())
 	ctx.JSON(http.StatusOK, repo.APIFormat(perm.AccessMode))
 }
 
@@ -498,6 +503,7 @@ func updateBasicProperties(ctx *context.APIContext, opts api.EditRepoOption) err
 	}
 	// Check if repository name has been changed and not just a case change
 	if repo.LowerName != strings.ToLower(newRepoName) {
+		oldRepoName := repo.Name
 		if err := repo_service.ChangeRepositoryName(ctx.User, repo, newRepoName); err != nil {
 			switch {
 			case models.IsErrRepoAlreadyExist(err):
@@ -512,7 +518,7 @@ func updateBasicProperties(ctx *context.APIContext, opts api.EditRepoOption) err
 			return err
 		}
 
-		log.Trace("Repository name changed: %s/%s -> %s", ctx.Repo.Owner.Name, repo.Name, newRepoName)
+		log.Trace("Repository name changed: %s/%s -> %s", ctx.Repo.Owner.Name, oldRepoName, newRepoName)
 	}
 	// Update the name in the repo object for the response
 	repo.Name = newRepoName
@@ -548,6 +554,19 @@ func updateBasicProperties(ctx *context.APIContext, opts api.EditRepoOption) err
 		repo.IsTemplate = *opts.Template
 	}
 
+	if opts.TrustModel != nil {
+		if !models.IsValidTrustModel(*opts.TrustModel) {
+			err := fmt.Errorf("unknown trust model: %s", *opts.TrustModel)
+			ctx.Error(http.StatusUnprocessableEntity, "TrustModel", err)
+			return err
+		}
+		repo.TrustModel = models.ToTrustModel(*opts.TrustModel)
+	}
+
+	if opts.TrustedKeys != nil {
+		repo.TrustedKeys = strings.TrimSpace(*opts.TrustedKeys)
+	}
+
 	// Default branch only updated if changed and exist
 	if opts.DefaultBranch != nil && repo.DefaultBranch != *opts.DefaultBranch && ctx.Repo.GitRepo.IsBranchExist(*opts.DefaultBranch) {
 		if err := ctx.Repo.GitRepo.SetDefaultBranch(*opts.DefaultBranch); err != nil {
@@ -707,6 +726,15 @@ func updateRepoUnits(ctx *context.APIContext, opts api.EditRepoOption) error {
 			if opts.AllowSquash != nil {
 				config.AllowSquash = *opts.AllowSquash
 			}
+			if opts.AllowRebaseFastForward != nil {
+				config.AllowRebaseFastForward = *opts.AllowRebaseFastForward
+			}
+			if opts.AllowFastForwardOnly != nil {
+				config.AllowFastForwardOnly = *opts.AllowFastForwardOnly
+			}
+			if opts.SquashMessageTemplate != nil {
+				config.SquashMessageTemplate = *opts.SquashMessageTemplate
+			}
 
 			units = append(units, models.RepoUnit{
 				RepoID: repo.ID,
@@ -737,12 +765,16 @@ func updateRepoArchivedState(ctx *context.APIContext, opts api.EditRepoOption) e
 			ctx.Error(http.StatusUnprocessableEntity, err.Error(), err)
 			return err
 		}
+		wasArchived := repo.IsArchived
 		if *opts.Archived {
 			if err := repo.SetArchiveRepoState(*opts.Archived); err != nil {
 				log.Error("Tried to archive a repo: %s", err)
 				ctx.Error(http.StatusInternalServerError, "ArchiveRepoState", err)
 				return err
 			}
+			if !wasArchived {
+				notification.NotifyArchiveRepository(ctx.User, repo)
+			}
 			log.Trace("Repository was archived: %s/%s", ctx.Repo.Owner.Name, repo.Name)
 		} else {
 			if err := repo.SetArchiveRepoState(*opts.Archived); err != nil {
@@ -750,6 +782,9 @@ func updateRepoArchivedState(ctx *context.APIContext, opts api.EditRepoOption) e
 				ctx.Error(http.StatusInternalServerError, "ArchiveRepoState", err)
 				return err
 			}
+			if wasArchived {
+				notification.NotifyUnarchiveRepository(ctx.User, repo)
+			}
 			log.Trace("Repository was un-archived: %s/%s", ctx.Repo.Owner.Name, repo.Name)
 		}
 	}
@@ -5,11 +5,14 @@
 package repo
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
@@ -31,6 +34,11 @@ func ListStargazers(ctx *context.APIContext) {
 	//   description: name of the repo
 	//   type: string
 	//   required: true
+	// - name: sort
+	//   in: query
+	//   description: order of the stargazers, by the time they starred the repo
+	//   type: string
+	//   enum: [oldest, newest]
 	// - name: page
 	//   in: query
 	//   description: page number of results to return (1-based)
@@ -41,16 +49,48 @@ func ListStargazers(ctx *context.APIContext) {
 	//   type: integer
 	// responses:
 	//   "200":
-	//     "$ref": "#/responses/UserList"
+	//     "$ref": "#/responses/StargazerList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	newestFirst, ok := parseDateSort(ctx)
+	if !ok {
+		return
+	}
 
-	stargazers, err := ctx.Repo.Repository.GetStargazers(utils.GetListOptions(ctx))
+	stargazers, err := ctx.Repo.Repository.GetStargazersByDate(utils.GetListOptions(ctx), newestFirst)
 	if err != nil {
-		ctx.Error(http.StatusInternalServerError, "GetStargazers", err)
+		ctx.Error(http.StatusInternalServerError, "GetStargazersByDate", err)
 		return
 	}
-	users := make([]*api.User, len(stargazers))
+	users := make([]*api.Stargazer, len(stargazers))
 	for i, stargazer := range stargazers {
-		users[i] = convert.ToUser(stargazer, ctx.IsSigned, ctx.User != nil && ctx.User.IsAdmin)
+		users[i] = &api.Stargazer{
+			User:      convert.ToUser(stargazer.User, ctx.IsSigned, ctx.User != nil && ctx.User.IsAdmin),
+			StarredAt: timeOrNil(stargazer.StarredUnix),
+		}
 	}
 	ctx.JSON(http.StatusOK, users)
 }
+
+// parseDateSort returns whether the newest items are listed first according to the sort
+// query parameter, it responds with an error if the parameter is invalid
+func parseDateSort(ctx *context.APIContext) (newestFirst, ok bool) {
+	switch ctx.Query("sort") {
+	case "", "oldest":
+		return false, true
+	case "newest":
+		return true, true
+	}
+	ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("invalid sort: %s", ctx.Query("sort")))
+	return false, false
+}
+
+// timeOrNil returns the time of the timestamp, nil if it is unknown
+func timeOrNil(t timeutil.TimeStamp) *time.Time {
+	if t == 0 {
+		return nil
+	}
+	at := t.AsTime()
+	return &at
+}
@@ -174,20 +174,29 @@ func GetCommitStatusesByRef(ctx *context.APIContext) {
 		return
 	}
 
+	filter, ok := resolveRefCommitSHA(ctx, filter)
+	if !ok {
+		return
+	}
+
+	getCommitStatuses(ctx, filter) //By default filter is maybe the raw SHA
+}
+
+// resolveRefCommitSHA returns the SHA of the commit of the branch or tag named ref, ref itself
+// if there is none as it may be the raw SHA. It writes the error response and returns false
+// if the refs can't be searched.
+func resolveRefCommitSHA(ctx *context.APIContext, ref string) (string, bool) {
 	for _, reftype := range []string{"heads", "tags"} { //Search branches and tags
-		refSHA, lastMethodName, err := searchRefCommitByType(ctx, reftype, filter)
+		refSHA, lastMethodName, err := searchRefCommitByType(ctx, reftype, ref)
 		if err != nil {
 			ctx.Error(http.StatusInternalServerError, lastMethodName, err)
-			return
+			return "", false
 		}
 		if refSHA != "" {
-			filter = refSHA
-			break
+			return refSHA, true
 		}
-
 	}
-
-	getCommitStatuses(ctx, filter) //By default filter is maybe the raw SHA
+	return ref, true
 }
 
 func searchRefCommitByType(ctx *context.APIContext, refType, filter string) (string, string, error) {
@@ -226,21 +235,12 @@ func getCommitStatuses(ctx *context.APIContext, sha string) {
 	ctx.JSON(http.StatusOK, apiStatuses)
 }
 
-type combinedCommitStatus struct {
-	State      api.CommitStatusState `json:"state"`
-	SHA        string                `json:"sha"`
-	TotalCount int                   `json:"total_count"`
-	Statuses   []*api.Status         `json:"statuses"`
-	Repo       *api.Repository       `json:"repository"`
-	CommitURL  string                `json:"commit_url"`
-	URL        string                `json:"url"`
-}
-
 // GetCombinedCommitStatusByRef returns the combined status for any given commit hash
 func GetCombinedCommitStatusByRef(ctx *context.APIContext) {
-	// swagger:operation GET /repos/{owner}/{repo}/commits/{ref}/statuses repository repoGetCombinedStatusByRef
+	// swagger:operation GET /repos/{owner}/{repo}/commits/{ref}/status repository repoGetCombinedStatusByRef
 	// ---
 	// summary: Get a commit's combined status, by branch/tag/commit reference
+	// description: The state is the worst one of the latest status of each context, as checked by the branch protections.
 	// produces:
 	// - application/json
 	// parameters:
@@ -261,49 +261,54 @@ func GetCombinedCommitStatusByRef(ctx *context.APIContext) {
 	//   required: true
 	// - name: page
 	//   in: query
-	//   description: page number of results
+	//   description: page number of the statuses to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of the statuses to return
 	//   type: integer
-	//   required: false
 	// responses:
 	//   "200":
-	//     "$ref": "#/responses/Status"
+	//     "$ref": "#/responses/CombinedStatus"
 	//   "400":
 	//     "$ref": "#/responses/error"
 
-	sha := ctx.Params("ref")
-	if len(sha) == 0 {
+	ref := ctx.Params("ref")
+	if len(ref) == 0 {
 		ctx.Error(http.StatusBadRequest, "ref/sha not given", nil)
 		return
 	}
-	repo := ctx.Repo.Repository
-
-	page := ctx.QueryInt("page")
-
-	statuses, err := models.GetLatestCommitStatus(repo, sha, page)
-	if err != nil {
-		ctx.Error(http.StatusInternalServerError, "GetLatestCommitStatus", fmt.Errorf("GetLatestCommitStatus[%s, %s, %d]: %v", repo.FullName(), sha, page, err))
+	sha, ok := resolveRefCommitSHA(ctx, ref)
+	if !ok {
 		return
 	}
+	repo := ctx.Repo.Repository
 
-	if len(statuses) == 0 {
-		ctx.Status(http.StatusOK)
+	// The state is calculated from the statuses of all the contexts, only the returned ones are paginated
+	statuses, err := models.GetLatestCommitStatus(repo, sha, models.ListOptions{})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetLatestCommitStatus", fmt.Errorf("GetLatestCommitStatus[%s, %s]: %v", repo.FullName(), sha, err))
 		return
 	}
 
-	retStatus := &combinedCommitStatus{
+	combinedStatus := &api.CombinedStatus{
 		SHA:        sha,
 		TotalCount: len(statuses),
-		Repo:       repo.APIFormat(ctx.Repo.AccessMode),
-		URL:        "",
+		Statuses:   make([]*api.Status, 0, len(statuses)),
+		Repository: repo.APIFormat(ctx.Repo.AccessMode),
+		CommitURL:  repo.APIURL() + "/git/commits/" + sha,
+		URL:        repo.APIURL() + "/commits/" + sha + "/status",
+	}
+	if len(statuses) > 0 {
+		combinedStatus.State = api.StatusState(models.CalcCommitStatus(statuses).State)
 	}
 
-	retStatus.Statuses = make([]*api.Status, 0, len(statuses))
+	listOptions := utils.GetListOptions(ctx)
+	start, end := listOptions.GetStartEnd(len(statuses))
+	statuses = statuses[start:end]
 	for _, status := range statuses {
-		retStatus.Statuses = append(retStatus.Statuses, status.APIFormat())
-		if status.State.NoBetterThan(retStatus.State) {
-			retStatus.State = status.State
-		}
+		combinedStatus.Statuses = append(combinedStatus.Statuses, status.APIFormat())
 	}
 
-	ctx.JSON(http.StatusOK, retStatus)
+	ctx.JSON(http.StatusOK, combinedStatus)
 }
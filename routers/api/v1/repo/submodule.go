@@ -0,0 +1,86 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+	submodule_service "code.gitea.io/gitea/services/submodule"
+)
+
+// ListSubmodules lists the submodules of a repository at a ref
+func ListSubmodules(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/submodules repository repoListSubmodules
+	// ---
+	// summary: List the submodules of a repository at a ref with the commits they are pinned at
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: query
+	//   description: "The name of the commit/branch/tag. Default the repository’s default branch (usually master)"
+	//   type: string
+	//   required: false
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/SubmoduleList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if ctx.Repo.Repository.IsEmpty {
+		ctx.JSON(http.StatusOK, []*api.Submodule{})
+		return
+	}
+
+	ref := ctx.QueryTrim("ref")
+	if ref == "" {
+		ref = ctx.Repo.Repository.DefaultBranch
+	}
+	commit, err := ctx.Repo.GitRepo.GetCommit(ref)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCommit", err)
+		}
+		return
+	}
+
+	submodules, err := submodule_service.Resolve(ctx.User, ctx.Repo.Repository, commit)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "Resolve", err)
+		return
+	}
+
+	apiSubmodules := make([]*api.Submodule, len(submodules))
+	for i, submodule := range submodules {
+		apiSubmodules[i] = &api.Submodule{
+			Name:      submodule.Name,
+			Path:      submodule.Path,
+			URL:       submodule.URL,
+			Branch:    submodule.Branch,
+			SHA:       submodule.CommitID,
+			HTMLURL:   submodule.RefURL,
+			CommitURL: submodule.CommitURL(),
+		}
+		if submodule.Repo != nil {
+			apiSubmodules[i].Repository = submodule.Repo.APIFormat(submodule.AccessMode)
+		}
+	}
+	ctx.JSON(http.StatusOK, apiSubmodules)
+}
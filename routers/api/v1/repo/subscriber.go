@@ -31,6 +31,11 @@ func ListSubscribers(ctx *context.APIContext) {
 	//   description: name of the repo
 	//   type: string
 	//   required: true
+	// - name: sort
+	//   in: query
+	//   description: order of the watchers, by the time they started watching the repo
+	//   type: string
+	//   enum: [oldest, newest]
 	// - name: page
 	//   in: query
 	//   description: page number of results to return (1-based)
@@ -41,16 +46,26 @@ func ListSubscribers(ctx *context.APIContext) {
 	//   type: integer
 	// responses:
 	//   "200":
-	//     "$ref": "#/responses/UserList"
+	//     "$ref": "#/responses/WatcherList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	newestFirst, ok := parseDateSort(ctx)
+	if !ok {
+		return
+	}
 
-	subscribers, err := ctx.Repo.Repository.GetWatchers(utils.GetListOptions(ctx))
+	subscribers, err := ctx.Repo.Repository.GetWatchersByDate(utils.GetListOptions(ctx), newestFirst)
 	if err != nil {
-		ctx.Error(http.StatusInternalServerError, "GetWatchers", err)
+		ctx.Error(http.StatusInternalServerError, "GetWatchersByDate", err)
 		return
 	}
-	users := make([]*api.User, len(subscribers))
+	users := make([]*api.Watcher, len(subscribers))
 	for i, subscriber := range subscribers {
-		users[i] = convert.ToUser(subscriber, ctx.IsSigned, ctx.User != nil && ctx.User.IsAdmin)
+		users[i] = &api.Watcher{
+			User:      convert.ToUser(subscriber.User, ctx.IsSigned, ctx.User != nil && ctx.User.IsAdmin),
+			WatchedAt: timeOrNil(subscriber.WatchedUnix),
+		}
 	}
 	ctx.JSON(http.StatusOK, users)
 }
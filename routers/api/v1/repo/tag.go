@@ -9,6 +9,7 @@ import (
 
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/git"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/routers/api/v1/utils"
 )
@@ -31,6 +32,15 @@ func ListTags(ctx *context.APIContext) {
 	//   description: name of the repo
 	//   type: string
 	//   required: true
+	// - name: prefix
+	//   in: query
+	//   description: only list the tags whose names start with the prefix
+	//   type: string
+	// - name: sort
+	//   in: query
+	//   description: sort order of the tags, the tags of a page are sorted by time otherwise
+	//   type: string
+	//   enum: [alphabetically, newest, oldest]
 	// - name: page
 	//   in: query
 	//   description: page number of results to return (1-based)
@@ -43,9 +53,14 @@ func ListTags(ctx *context.APIContext) {
 	//   "200":
 	//     "$ref": "#/responses/TagList"
 
-	listOpts := utils.GetListOptions(ctx)
+	listOpts := utils.GetPaginatedListOptions(ctx)
 
-	tags, err := ctx.Repo.GitRepo.GetTagInfos(listOpts.Page, listOpts.PageSize)
+	tags, total, err := ctx.Repo.GitRepo.GetTagInfos(git.ListRefsOptions{
+		Prefix:   ctx.Query("prefix"),
+		Sort:     git.RefSortType(ctx.Query("sort")),
+		Page:     listOpts.Page,
+		PageSize: listOpts.PageSize,
+	})
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "GetTags", err)
 		return
@@ -56,6 +71,7 @@ func ListTags(ctx *context.APIContext) {
 		apiTags[i] = convert.ToTag(ctx.Repo.Repository, tags[i])
 	}
 
+	utils.SetPaginationHeaders(ctx, int64(total), listOpts)
 	ctx.JSON(http.StatusOK, &apiTags)
 }
 
@@ -41,6 +41,8 @@ func Transfer(ctx *context.APIContext, opts api.TransferRepoOption) {
 	//   schema:
 	//     "$ref": "#/definitions/TransferRepoOption"
 	// responses:
+	//   "201":
+	//     "$ref": "#/responses/RepoTransfer"
 	//   "202":
 	//     "$ref": "#/responses/Repository"
 	//   "403":
@@ -84,11 +86,37 @@ func Transfer(ctx *context.APIContext, opts api.TransferRepoOption) {
 		}
 	}
 
-	if err = repo_service.TransferOwnership(ctx.User, newOwner, ctx.Repo.Repository, teams); err != nil {
+	if newOwner.ID == ctx.Repo.Repository.OwnerID {
+		ctx.Error(http.StatusUnprocessableEntity, "repoTransfer", "The repository is already owned by the new owner")
+		return
+	}
+
+	pending, err := repo_service.StartRepositoryTransfer(ctx.User, newOwner, ctx.Repo.Repository, teams)
+	if err != nil {
+		if models.IsErrRepoAlreadyExist(err) || models.IsErrRepoTransferInProgress(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "StartRepositoryTransfer", err)
+			return
+		}
 		ctx.InternalServerError(err)
 		return
 	}
 
+	if pending {
+		transfer, err := models.GetPendingRepositoryTransfer(ctx.Repo.Repository)
+		if err != nil {
+			ctx.InternalServerError(err)
+			return
+		}
+		if err = transfer.LoadAttributes(); err != nil {
+			ctx.InternalServerError(err)
+			return
+		}
+
+		log.Trace("Repository transfer requested: %s -> %s", ctx.Repo.Repository.FullName(), newOwner.Name)
+		ctx.JSON(http.StatusCreated, convert.ToRepoTransfer(transfer, ctx.User))
+		return
+	}
+
 	newRepo, err := models.GetRepositoryByName(newOwner.ID, ctx.Repo.Repository.Name)
 	if err != nil {
 		ctx.InternalServerError(err)
@@ -98,3 +126,196 @@ func Transfer(ctx *context.APIContext, opts api.TransferRepoOption) {
 	log.Trace("Repository transferred: %s -> %s", ctx.Repo.Repository.FullName(), newOwner.Name)
 	ctx.JSON(http.StatusAccepted, newRepo.APIFormat(models.AccessModeAdmin))
 }
+
+// getPendingTransfer returns the pending transfer of the repository of the context with its
+// attributes loaded, it writes the response and returns nil if there is none
+func getPendingTransfer(ctx *context.APIContext) *models.RepoTransfer {
+	transfer, err := models.GetPendingRepositoryTransfer(ctx.Repo.Repository)
+	if err != nil {
+		if models.IsErrNoPendingRepoTransfer(err) {
+			ctx.NotFound()
+		} else {
+			ctx.InternalServerError(err)
+		}
+		return nil
+	}
+	if err = transfer.LoadAttributes(); err != nil {
+		ctx.InternalServerError(err)
+		return nil
+	}
+	return transfer
+}
+
+// GetTransfer returns the pending transfer of a repository
+func GetTransfer(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/transfer repository repoGetTransfer
+	// ---
+	// summary: Get the pending transfer of a repo
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepoTransfer"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	transfer := getPendingTransfer(ctx)
+	if transfer == nil {
+		return
+	}
+	if !ctx.Repo.IsAdmin() && !transfer.CanUserAcceptTransfer(ctx.User) {
+		ctx.NotFound()
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToRepoTransfer(transfer, ctx.User))
+}
+
+// AcceptTransfer accepts the pending transfer of a repository
+func AcceptTransfer(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/transfer/accept repository repoAcceptTransfer
+	// ---
+	// summary: Accept the pending transfer of a repo
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo to transfer
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo to transfer
+	//   type: string
+	//   required: true
+	// responses:
+	//   "202":
+	//     "$ref": "#/responses/Repository"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	transfer := getPendingTransfer(ctx)
+	if transfer == nil {
+		return
+	}
+	if !transfer.CanUserAcceptTransfer(ctx.User) {
+		ctx.Error(http.StatusForbidden, "AcceptTransfer", "Only the new owner can accept the transfer")
+		return
+	}
+
+	oldName := ctx.Repo.Repository.FullName()
+	if err := repo_service.AcceptTransferOwnership(ctx.Repo.Repository); err != nil {
+		if models.IsErrRepoAlreadyExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "AcceptTransferOwnership", err)
+			return
+		}
+		ctx.InternalServerError(err)
+		return
+	}
+
+	newRepo, err := models.GetRepositoryByName(transfer.RecipientID, ctx.Repo.Repository.Name)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+
+	log.Trace("Repository transferred: %s -> %s", oldName, transfer.Recipient.Name)
+	ctx.JSON(http.StatusAccepted, newRepo.APIFormat(models.AccessModeAdmin))
+}
+
+// RejectTransfer rejects the pending transfer of a repository
+func RejectTransfer(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/transfer/reject repository repoRejectTransfer
+	// ---
+	// summary: Reject the pending transfer of a repo
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo to transfer
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo to transfer
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	transfer := getPendingTransfer(ctx)
+	if transfer == nil {
+		return
+	}
+	if !transfer.CanUserAcceptTransfer(ctx.User) {
+		ctx.Error(http.StatusForbidden, "RejectTransfer", "Only the new owner can reject the transfer")
+		return
+	}
+
+	if err := repo_service.RejectRepositoryTransfer(ctx.Repo.Repository, ctx.User); err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+
+	log.Trace("Repository transfer rejected: %s -> %s", ctx.Repo.Repository.FullName(), transfer.Recipient.Name)
+	ctx.Status(http.StatusNoContent)
+}
+
+// CancelTransfer cancels the pending transfer of a repository
+func CancelTransfer(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/transfer repository repoCancelTransfer
+	// ---
+	// summary: Cancel the pending transfer of a repo
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if getPendingTransfer(ctx) == nil {
+		return
+	}
+
+	if err := repo_service.RejectRepositoryTransfer(ctx.Repo.Repository, ctx.User); err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+
+	log.Trace("Repository transfer cancelled: %s", ctx.Repo.Repository.FullName())
+	ctx.Status(http.StatusNoContent)
+}
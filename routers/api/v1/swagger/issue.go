@@ -22,6 +22,13 @@ type swaggerResponseIssueList struct {
 	Body []api.Issue `json:"body"`
 }
 
+// CreateIssueResultList
+// swagger:response CreateIssueResultList
+type swaggerResponseCreateIssueResultList struct {
+	// in:body
+	Body []api.CreateIssueResult `json:"body"`
+}
+
 // Comment
 // swagger:response Comment
 type swaggerResponseComment struct {
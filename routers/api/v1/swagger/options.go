@@ -22,6 +22,8 @@ type swaggerParameterBodies struct {
 	CreateEmailOption api.CreateEmailOption
 	// in:body
 	DeleteEmailOption api.DeleteEmailOption
+	// in:body
+	UserEmailOption api.UserEmailOption
 
 	// in:body
 	CreateHookOption api.CreateHookOption
@@ -34,6 +36,8 @@ type swaggerParameterBodies struct {
 	// in:body
 	CreateIssueOption api.CreateIssueOption
 	// in:body
+	CreateIssuesOption api.CreateIssuesOption
+	// in:body
 	EditIssueOption api.EditIssueOption
 	// in:body
 	EditDeadlineOption api.EditDeadlineOption
@@ -78,6 +82,12 @@ type swaggerParameterBodies struct {
 	CreateReleaseOption api.CreateReleaseOption
 	// in:body
 	EditReleaseOption api.EditReleaseOption
+	// in:body
+	YankReleaseOption api.YankReleaseOption
+	// in:body
+	CreateReleaseCommentOption api.CreateReleaseCommentOption
+	// in:body
+	EditReleaseCommentOption api.EditReleaseCommentOption
 
 	// in:body
 	CreateRepoOption api.CreateRepoOption
@@ -91,6 +101,15 @@ type swaggerParameterBodies struct {
 	// in:body
 	CreateStatusOption api.CreateStatusOption
 
+	// in:body
+	CreateNoteOption api.CreateNoteOption
+
+	// in:body
+	CherryPickCommitOption api.CherryPickCommitOption
+
+	// in:body
+	CreateGitRefOption api.CreateGitRefOption
+
 	// in:body
 	CreateTeamOption api.CreateTeamOption
 	// in:body
@@ -111,6 +130,9 @@ type swaggerParameterBodies struct {
 	// in:body
 	EditAttachmentOptions api.EditAttachmentOptions
 
+	// in:body
+	CreateAttachmentUploadOptions api.CreateAttachmentUploadOptions
+
 	// in:body
 	CreateFileOptions api.CreateFileOptions
 
@@ -149,4 +171,7 @@ type swaggerParameterBodies struct {
 
 	// in:body
 	SubmitPullReviewOptions api.SubmitPullReviewOptions
+
+	// in:body
+	PruneHookDeliveriesOption api.PruneHookDeliveriesOption
 }
@@ -35,3 +35,10 @@ type swaggerResponseTeamList struct {
 	// in:body
 	Body []api.Team `json:"body"`
 }
+
+// ActivityFeedsList
+// swagger:response ActivityFeedsList
+type swaggerResponseActivityFeedsList struct {
+	// in:body
+	Body []api.Activity `json:"body"`
+}
@@ -29,6 +29,13 @@ type swaggerResponseBranch struct {
 	Body api.Branch `json:"body"`
 }
 
+// DeletedBranchList
+// swagger:response DeletedBranchList
+type swaggerResponseDeletedBranchList struct {
+	// in:body
+	Body []api.DeletedBranch `json:"body"`
+}
+
 // BranchList
 // swagger:response BranchList
 type swaggerResponseBranchList struct {
@@ -99,6 +106,34 @@ type swaggerResponseHookList struct {
 	Body []api.Hook `json:"body"`
 }
 
+// InheritedHookList
+// swagger:response InheritedHookList
+type swaggerResponseInheritedHookList struct {
+	// in:body
+	Body []api.InheritedHook `json:"body"`
+}
+
+// HookDelivery
+// swagger:response HookDelivery
+type swaggerResponseHookDelivery struct {
+	// in:body
+	Body api.HookDelivery `json:"body"`
+}
+
+// HookDeliveryList
+// swagger:response HookDeliveryList
+type swaggerResponseHookDeliveryList struct {
+	// in:body
+	Body []api.HookDelivery `json:"body"`
+}
+
+// PruneHookDeliveriesResult
+// swagger:response PruneHookDeliveriesResult
+type swaggerResponsePruneHookDeliveriesResult struct {
+	// in:body
+	Body api.PruneHookDeliveriesResult `json:"body"`
+}
+
 // GitHook
 // swagger:response GitHook
 type swaggerResponseGitHook struct {
@@ -127,6 +162,34 @@ type swaggerResponseReleaseList struct {
 	Body []api.Release `json:"body"`
 }
 
+// ReleaseChangelog
+// swagger:response ReleaseChangelog
+type swaggerResponseReleaseChangelog struct {
+	// in:body
+	Body api.ReleaseChangelog `json:"body"`
+}
+
+// ReleaseComment
+// swagger:response ReleaseComment
+type swaggerResponseReleaseComment struct {
+	// in:body
+	Body api.ReleaseComment `json:"body"`
+}
+
+// ReleaseCommentList
+// swagger:response ReleaseCommentList
+type swaggerResponseReleaseCommentList struct {
+	// in:body
+	Body []api.ReleaseComment `json:"body"`
+}
+
+// ReleasesJob
+// swagger:response ReleasesJob
+type swaggerResponseReleasesJob struct {
+	// in:body
+	Body api.ReleasesJob `json:"body"`
+}
+
 // PullRequest
 // swagger:response PullRequest
 type swaggerResponsePullRequest struct {
@@ -141,6 +204,20 @@ type swaggerResponsePullRequestList struct {
 	Body []api.PullRequest `json:"body"`
 }
 
+// PullRequestCodeOwners
+// swagger:response PullRequestCodeOwners
+type swaggerResponsePullRequestCodeOwners struct {
+	// in:body
+	Body api.PullRequestCodeOwners `json:"body"`
+}
+
+// PullRequestMergeQueueEntry
+// swagger:response PullRequestMergeQueueEntry
+type swaggerResponsePullRequestMergeQueueEntry struct {
+	// in:body
+	Body api.PullRequestMergeQueueEntry `json:"body"`
+}
+
 // PullReview
 // swagger:response PullReview
 type swaggerResponsePullReview struct {
@@ -169,6 +246,20 @@ type swaggerResponsePullReviewCommentList struct {
 	Body []api.PullReviewComment `json:"body"`
 }
 
+// PullReviewThread
+// swagger:response PullReviewThread
+type swaggerResponsePullReviewThread struct {
+	// in:body
+	Body api.PullReviewThread `json:"body"`
+}
+
+// PullReviewThreadList
+// swagger:response PullReviewThreadList
+type swaggerResponsePullReviewThreadList struct {
+	// in:body
+	Body []api.PullReviewThread `json:"body"`
+}
+
 // Status
 // swagger:response Status
 type swaggerResponseStatus struct {
@@ -183,6 +274,55 @@ type swaggerResponseStatusList struct {
 	Body []api.Status `json:"body"`
 }
 
+// CombinedStatus
+// swagger:response CombinedStatus
+type swaggerResponseCombinedStatus struct {
+	// in:body
+	Body api.CombinedStatus `json:"body"`
+}
+
+// Note
+// swagger:response Note
+type swaggerResponseNote struct {
+	// in:body
+	Body api.Note `json:"body"`
+}
+
+// CherryPickCommitResponse
+// swagger:response CherryPickCommitResponse
+type swaggerResponseCherryPickCommitResponse struct {
+	// in:body
+	Body api.CherryPickCommitResponse `json:"body"`
+}
+
+// BlameRangeList
+// swagger:response BlameRangeList
+type swaggerResponseBlameRangeList struct {
+	// in:body
+	Body []api.BlameRange `json:"body"`
+}
+
+// RepoGarbageCollection
+// swagger:response RepoGarbageCollection
+type swaggerResponseRepoGarbageCollection struct {
+	// in:body
+	Body api.RepoGarbageCollection `json:"body"`
+}
+
+// RepoArchive
+// swagger:response RepoArchive
+type swaggerResponseRepoArchive struct {
+	// in:body
+	Body api.RepoArchive `json:"body"`
+}
+
+// RepoGarbageCollectionList
+// swagger:response RepoGarbageCollectionList
+type swaggerResponseRepoGarbageCollectionList struct {
+	// in:body
+	Body []api.RepoGarbageCollection `json:"body"`
+}
+
 // WatchInfo
 // swagger:response WatchInfo
 type swaggerResponseWatchInfo struct {
@@ -211,6 +351,13 @@ type swaggerResponseAttachment struct {
 	Body api.Attachment `json:"body"`
 }
 
+// AttachmentUpload
+// swagger:response AttachmentUpload
+type swaggerResponseAttachmentUpload struct {
+	// in: body
+	Body api.AttachmentUpload `json:"body"`
+}
+
 // GitTreeResponse
 // swagger:response GitTreeResponse
 type swaggerGitTreeResponse struct {
@@ -309,3 +456,24 @@ type swaggerLanguageStatistics struct {
 	// in: body
 	Body map[string]int64 `json:"body"`
 }
+
+// LanguageShareList
+// swagger:response LanguageShareList
+type swaggerLanguageShareList struct {
+	// in: body
+	Body []api.LanguageShare `json:"body"`
+}
+
+// RepoTransfer
+// swagger:response RepoTransfer
+type swaggerResponseRepoTransfer struct {
+	// in: body
+	Body api.RepoTransfer `json:"body"`
+}
+
+// SubmoduleList
+// swagger:response SubmoduleList
+type swaggerSubmoduleList struct {
+	// in: body
+	Body []api.Submodule `json:"body"`
+}
@@ -23,6 +23,20 @@ type swaggerResponseUserList struct {
 	Body []api.User `json:"body"`
 }
 
+// StargazerList
+// swagger:response StargazerList
+type swaggerResponseStargazerList struct {
+	// in:body
+	Body []api.Stargazer `json:"body"`
+}
+
+// WatcherList
+// swagger:response WatcherList
+type swaggerResponseWatcherList struct {
+	// in:body
+	Body []api.Watcher `json:"body"`
+}
+
 // EmailList
 // swagger:response EmailList
 type swaggerResponseEmailList struct {
@@ -53,6 +53,7 @@ func ListAccessTokens(ctx *context.APIContext) {
 			ID:             tokens[i].ID,
 			Name:           tokens[i].Name,
 			TokenLastEight: tokens[i].TokenLastEight,
+			Scopes:         tokens[i].Scope.Names(),
 		}
 	}
 	ctx.JSON(http.StatusOK, &apiTokens)
@@ -82,13 +83,27 @@ func CreateAccessToken(ctx *context.APIContext, form api.CreateAccessTokenOption
 	//     properties:
 	//       name:
 	//         type: string
+	//       scopes:
+	//         type: array
+	//         items:
+	//           type: string
+	//           enum: [all, "repo:read", "repo:write", "issue:write", user, admin]
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/AccessToken"
+	//   "400":
+	//     "$ref": "#/responses/error"
+
+	scope, err := models.ParseAccessTokenScope(form.Scopes)
+	if err != nil {
+		ctx.Error(http.StatusBadRequest, "ParseAccessTokenScope", err)
+		return
+	}
 
 	t := &models.AccessToken{
-		UID:  ctx.User.ID,
-		Name: form.Name,
+		UID:   ctx.User.ID,
+		Name:  form.Name,
+		Scope: scope,
 	}
 
 	exist, err := models.AccessTokenByNameExists(t)
@@ -110,6 +125,7 @@ func CreateAccessToken(ctx *context.APIContext, form api.CreateAccessTokenOption
 		Token:          t.Token,
 		ID:             t.ID,
 		TokenLastEight: t.TokenLastEight,
+		Scopes:         t.Scope.Names(),
 	})
 }
 
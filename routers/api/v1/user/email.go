@@ -26,7 +26,12 @@ func ListEmails(ctx *context.APIContext) {
 	//   "200":
 	//     "$ref": "#/responses/EmailList"
 
-	emails, err := models.GetEmailAddresses(ctx.User.ID)
+	ListUserEmails(ctx, ctx.User.ID)
+}
+
+// ListUserEmails responds with the email addresses of the given user by ID.
+func ListUserEmails(ctx *context.APIContext, uid int64) {
+	emails, err := models.GetEmailAddresses(uid)
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "GetEmailAddresses", err)
 		return
@@ -61,6 +66,11 @@ func AddEmail(ctx *context.APIContext, form api.CreateEmailOption) {
 	//   "422":
 	//     "$ref": "#/responses/validationError"
 
+	AddUserEmails(ctx, form, ctx.User.ID)
+}
+
+// AddUserEmails adds the email addresses to given user by ID.
+func AddUserEmails(ctx *context.APIContext, form api.CreateEmailOption, uid int64) {
 	if len(form.Emails) == 0 {
 		ctx.Error(http.StatusUnprocessableEntity, "", "Email list empty")
 		return
@@ -69,7 +79,7 @@ func AddEmail(ctx *context.APIContext, form api.CreateEmailOption) {
 	emails := make([]*models.EmailAddress, len(form.Emails))
 	for i := range form.Emails {
 		emails[i] = &models.EmailAddress{
-			UID:         ctx.User.ID,
+			UID:         uid,
 			Email:       form.Emails[i],
 			IsActivated: !setting.Service.RegisterEmailConfirm,
 		}
@@ -107,6 +117,11 @@ func DeleteEmail(ctx *context.APIContext, form api.DeleteEmailOption) {
 	//   "204":
 	//     "$ref": "#/responses/empty"
 
+	DeleteUserEmails(ctx, form, ctx.User.ID)
+}
+
+// DeleteUserEmails deletes the email addresses of given user by ID.
+func DeleteUserEmails(ctx *context.APIContext, form api.DeleteEmailOption, uid int64) {
 	if len(form.Emails) == 0 {
 		ctx.Status(http.StatusNoContent)
 		return
@@ -116,7 +131,7 @@ func DeleteEmail(ctx *context.APIContext, form api.DeleteEmailOption) {
 	for i := range form.Emails {
 		emails[i] = &models.EmailAddress{
 			Email: form.Emails[i],
-			UID:   ctx.User.ID,
+			UID:   uid,
 		}
 	}
 
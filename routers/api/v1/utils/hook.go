@@ -13,6 +13,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/validation"
 	"code.gitea.io/gitea/modules/webhook"
 	"code.gitea.io/gitea/routers/utils"
 
@@ -49,6 +50,21 @@ func GetRepoHook(ctx *context.APIContext, repoID, hookID int64) (*models.Webhook
 	return w, nil
 }
 
+// GetSystemHook get an admin system webhook. If there is an error, write to
+// `ctx` accordingly and return the error
+func GetSystemHook(ctx *context.APIContext, hookID int64) (*models.Webhook, error) {
+	w, err := models.GetSystemWebhook(hookID)
+	if err != nil {
+		if models.IsErrWebhookNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetSystemWebhook", err)
+		}
+		return nil, err
+	}
+	return w, nil
+}
+
 // CheckCreateHookOption check if a CreateHookOption form is valid. If invalid,
 // write the appropriate error to `ctx`. Return whether the form is valid
 func CheckCreateHookOption(ctx *context.APIContext, form *api.CreateHookOption) bool {
@@ -56,7 +72,12 @@ func CheckCreateHookOption(ctx *context.APIContext, form *api.CreateHookOption)
 		ctx.Error(http.StatusUnprocessableEntity, "", "Invalid hook type")
 		return false
 	}
-	for _, name := range []string{"url", "content_type"} {
+	required := []string{"url", "content_type"}
+	if models.ToHookTaskType(form.Type) == models.MATRIX {
+		// The URL of a Matrix hook is built from its homeserver and room
+		required = []string{"homeserver_url", "room_id", "access_token", "content_type"}
+	}
+	for _, name := range required {
 		if _, ok := form.Config[name]; !ok {
 			ctx.Error(http.StatusUnprocessableEntity, "", "Missing config option: "+name)
 			return false
@@ -66,13 +87,21 @@ func CheckCreateHookOption(ctx *context.APIContext, form *api.CreateHookOption)
 		ctx.Error(http.StatusUnprocessableEntity, "", "Invalid content type")
 		return false
 	}
+	if alg := form.Config["signature_algorithm"]; len(alg) > 0 && !api.IsValidHookSignatureAlgorithm(alg) {
+		ctx.Error(http.StatusUnprocessableEntity, "", "Invalid signature algorithm")
+		return false
+	}
+	if proxy := form.Config["proxy_url"]; len(proxy) > 0 && !validation.IsValidProxyURL(proxy) {
+		ctx.Error(http.StatusUnprocessableEntity, "", "Invalid proxy URL")
+		return false
+	}
 	return true
 }
 
 // AddOrgHook add a hook to an organization. Writes to `ctx` accordingly
 func AddOrgHook(ctx *context.APIContext, form *api.CreateHookOption) {
 	org := ctx.Org.Organization
-	hook, ok := addHook(ctx, form, org.ID, 0)
+	hook, ok := addHook(ctx, form, org.ID, 0, false)
 	if ok {
 		ctx.JSON(http.StatusCreated, convert.ToHook(org.HomeLink(), hook))
 	}
@@ -81,12 +110,20 @@ func AddOrgHook(ctx *context.APIContext, form *api.CreateHookOption) {
 // AddRepoHook add a hook to a repo. Writes to `ctx` accordingly
 func AddRepoHook(ctx *context.APIContext, form *api.CreateHookOption) {
 	repo := ctx.Repo
-	hook, ok := addHook(ctx, form, 0, repo.Repository.ID)
+	hook, ok := addHook(ctx, form, 0, repo.Repository.ID, false)
 	if ok {
 		ctx.JSON(http.StatusCreated, convert.ToHook(repo.RepoLink, hook))
 	}
 }
 
+// AddSystemHook add an admin system webhook. Writes to `ctx` accordingly
+func AddSystemHook(ctx *context.APIContext, form *api.CreateHookOption) {
+	hook, ok := addHook(ctx, form, 0, 0, true)
+	if ok {
+		ctx.JSON(http.StatusCreated, convert.ToSystemHook(hook))
+	}
+}
+
 func issuesHook(events []string, event string) bool {
 	return com.IsSliceContainsStr(events, event) || com.IsSliceContainsStr(events, string(models.HookEventIssues))
 }
@@ -95,19 +132,21 @@ func pullHook(events []string, event string) bool {
 	return com.IsSliceContainsStr(events, event) || com.IsSliceContainsStr(events, string(models.HookEventPullRequest))
 }
 
-// addHook add the hook specified by `form`, `orgID` and `repoID`. If there is
-// an error, write to `ctx` accordingly. Return (webhook, ok)
-func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID int64) (*models.Webhook, bool) {
+// addHook add the hook specified by `form`, `orgID`, `repoID` and `isSystemWebhook`.
+// If there is an error, write to `ctx` accordingly. Return (webhook, ok)
+func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID int64, isSystemWebhook bool) (*models.Webhook, bool) {
 	if len(form.Events) == 0 {
 		form.Events = []string{"push"}
 	}
 	w := &models.Webhook{
-		OrgID:       orgID,
-		RepoID:      repoID,
-		URL:         form.Config["url"],
-		ContentType: models.ToHookContentType(form.Config["content_type"]),
-		Secret:      form.Config["secret"],
-		HTTPMethod:  "POST",
+		OrgID:              orgID,
+		RepoID:             repoID,
+		URL:                form.Config["url"],
+		ContentType:        models.ToHookContentType(form.Config["content_type"]),
+		Secret:             form.Config["secret"],
+		SignatureAlgorithm: form.Config["signature_algorithm"],
+		ProxyURL:           form.Config["proxy_url"],
+		HTTPMethod:         "POST",
 		HookEvent: &models.HookEvent{
 			ChooseEvents: true,
 			HookEvents: models.HookEvents{
@@ -129,11 +168,15 @@ func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID
 				PullRequestSync:      pullHook(form.Events, string(models.HookEventPullRequestSync)),
 				Repository:           com.IsSliceContainsStr(form.Events, string(models.HookEventRepository)),
 				Release:              com.IsSliceContainsStr(form.Events, string(models.HookEventRelease)),
+				User:                 com.IsSliceContainsStr(form.Events, string(models.HookEventUser)),
+				Organization:         com.IsSliceContainsStr(form.Events, string(models.HookEventOrganization)),
 			},
 			BranchFilter: form.BranchFilter,
+			TagFilter:    form.TagFilter,
 		},
-		IsActive:     form.Active,
-		HookTaskType: models.ToHookTaskType(form.Type),
+		IsActive:        form.Active,
+		HookTaskType:    models.ToHookTaskType(form.Type),
+		IsSystemWebhook: isSystemWebhook,
 	}
 	if w.HookTaskType == models.SLACK {
 		channel, ok := form.Config["channel"]
@@ -159,6 +202,42 @@ func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID
 		}
 		w.Meta = string(meta)
 	}
+	if w.HookTaskType == models.CUSTOM {
+		tmpl, ok := form.Config["template"]
+		if !ok {
+			ctx.Error(http.StatusUnprocessableEntity, "", "Missing config option: template")
+			return nil, false
+		}
+
+		meta, ok := customHookMeta(ctx, tmpl)
+		if !ok {
+			return nil, false
+		}
+		w.Meta = meta
+		w.ContentType = models.ContentTypeJSON
+	}
+	if w.HookTaskType == models.MSTEAMS {
+		meta, ok := msteamsHookMeta(ctx, form.Config["card_type"])
+		if !ok {
+			return nil, false
+		}
+		w.Meta = meta
+		w.ContentType = models.ContentTypeJSON
+	}
+	if w.HookTaskType == models.MATRIX {
+		matrix := &webhook.MatrixMeta{MessageType: webhook.ToMatrixMessageType("m.notice")}
+		meta, ok := matrixHookMeta(ctx, matrix, form.Config)
+		if !ok {
+			return nil, false
+		}
+		w.URL = webhook.GetMatrixRoomURL(matrix.HomeserverURL, matrix.Room)
+		w.Meta = meta
+		w.ContentType = models.ContentTypeJSON
+	}
+
+	if !setClientCertificate(ctx, w, form.Config) {
+		return nil, false
+	}
 
 	if err := w.UpdateEvent(); err != nil {
 		ctx.Error(http.StatusInternalServerError, "UpdateEvent", err)
@@ -170,6 +249,95 @@ func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID
 	return w, true
 }
 
+// setClientCertificate applies the client certificate config options to `w`, the current
+// key is kept if only the certificate is given. If the certificate is invalid, write to `ctx`
+// accordingly. Return whether successful
+func setClientCertificate(ctx *context.APIContext, w *models.Webhook, config map[string]string) bool {
+	cert, ok := config["client_certificate"]
+	if !ok {
+		if _, ok := config["client_key"]; !ok {
+			return true
+		}
+		cert = w.ClientCertificate
+	}
+	if err := w.SetClientCertificate(cert, config["client_key"]); err != nil {
+		if models.IsErrInvalidClientCertificate(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err.Error())
+		} else {
+			ctx.Error(http.StatusInternalServerError, "SetClientCertificate", err)
+		}
+		return false
+	}
+	return true
+}
+
+// customHookMeta checks the payload template of a custom webhook and returns its metadata.
+// If the template is invalid, write to `ctx` accordingly. Return whether successful
+func customHookMeta(ctx *context.APIContext, tmpl string) (string, bool) {
+	if err := webhook.ParseCustomTemplate(tmpl); err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", "Invalid template: "+err.Error())
+		return "", false
+	}
+
+	meta, err := json.Marshal(&webhook.CustomMeta{
+		Template: tmpl,
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "custom: JSON marshal failed", err)
+		return "", false
+	}
+	return string(meta), true
+}
+
+// msteamsHookMeta checks the card type of a Microsoft Teams webhook and returns its metadata.
+// If the card type is invalid, write to `ctx` accordingly. Return whether successful
+func msteamsHookMeta(ctx *context.APIContext, cardType string) (string, bool) {
+	if !webhook.IsValidMSTeamsCardType(cardType) {
+		ctx.Error(http.StatusUnprocessableEntity, "", "Invalid card type")
+		return "", false
+	}
+
+	meta, err := json.Marshal(&webhook.MSTeamsMeta{
+		CardType: cardType,
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "msteams: JSON marshal failed", err)
+		return "", false
+	}
+	return string(meta), true
+}
+
+// matrixHookMeta applies the Matrix config options to `meta` and returns the metadata
+// of the webhook. If the options are invalid, write to `ctx` accordingly. Return whether successful
+func matrixHookMeta(ctx *context.APIContext, meta *webhook.MatrixMeta, config map[string]string) (string, bool) {
+	if homeserverURL, ok := config["homeserver_url"]; ok {
+		meta.HomeserverURL = strings.TrimSuffix(homeserverURL, "/")
+	}
+	if roomID, ok := config["room_id"]; ok {
+		meta.Room = roomID
+	}
+	if accessToken, ok := config["access_token"]; ok {
+		meta.AccessToken = accessToken
+	}
+	if typ, ok := config["message_type"]; ok {
+		meta.MessageType = webhook.ToMatrixMessageType(typ)
+	}
+	if meta.MessageType == 0 {
+		ctx.Error(http.StatusUnprocessableEntity, "", "Invalid message type")
+		return "", false
+	}
+	if plain, ok := config["plain_text"]; ok {
+		meta.PlainText = plain == "true"
+	}
+
+	data, err := json.Marshal(meta)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "matrix: JSON marshal failed", err)
+		return "", false
+	}
+	return string(data), true
+}
+
 // EditOrgHook edit webhook `w` according to `form`. Writes to `ctx` accordingly
 func EditOrgHook(ctx *context.APIContext, form *api.EditHookOption, hookID int64) {
 	org := ctx.Org.Organization
@@ -204,6 +372,22 @@ func EditRepoHook(ctx *context.APIContext, form *api.EditHookOption, hookID int6
 	ctx.JSON(http.StatusOK, convert.ToHook(repo.RepoLink, updated))
 }
 
+// EditSystemHook edit admin system webhook `w` according to `form`. Writes to `ctx` accordingly
+func EditSystemHook(ctx *context.APIContext, form *api.EditHookOption, hookID int64) {
+	hook, err := GetSystemHook(ctx, hookID)
+	if err != nil {
+		return
+	}
+	if !editHook(ctx, form, hook) {
+		return
+	}
+	updated, err := GetSystemHook(ctx, hookID)
+	if err != nil {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToSystemHook(updated))
+}
+
 // editHook edit the webhook `w` according to `form`. If an error occurs, write
 // to `ctx` accordingly and return the error. Return whether successful
 func editHook(ctx *context.APIContext, form *api.EditHookOption, w *models.Webhook) bool {
@@ -218,6 +402,23 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *models.Webho
 			}
 			w.ContentType = models.ToHookContentType(ct)
 		}
+		if alg, ok := form.Config["signature_algorithm"]; ok {
+			if len(alg) > 0 && !api.IsValidHookSignatureAlgorithm(alg) {
+				ctx.Error(http.StatusUnprocessableEntity, "", "Invalid signature algorithm")
+				return false
+			}
+			w.SignatureAlgorithm = alg
+		}
+		if proxy, ok := form.Config["proxy_url"]; ok {
+			if len(proxy) > 0 && !validation.IsValidProxyURL(proxy) {
+				ctx.Error(http.StatusUnprocessableEntity, "", "Invalid proxy URL")
+				return false
+			}
+			w.ProxyURL = proxy
+		}
+		if !setClientCertificate(ctx, w, form.Config) {
+			return false
+		}
 
 		if w.HookTaskType == models.SLACK {
 			if channel, ok := form.Config["channel"]; ok {
@@ -234,6 +435,36 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *models.Webho
 				w.Meta = string(meta)
 			}
 		}
+
+		if w.HookTaskType == models.CUSTOM {
+			if tmpl, ok := form.Config["template"]; ok {
+				meta, ok := customHookMeta(ctx, tmpl)
+				if !ok {
+					return false
+				}
+				w.Meta = meta
+			}
+		}
+
+		if w.HookTaskType == models.MSTEAMS {
+			if cardType, ok := form.Config["card_type"]; ok {
+				meta, ok := msteamsHookMeta(ctx, cardType)
+				if !ok {
+					return false
+				}
+				w.Meta = meta
+			}
+		}
+
+		if w.HookTaskType == models.MATRIX {
+			matrix := webhook.GetMatrixHook(w)
+			meta, ok := matrixHookMeta(ctx, matrix, form.Config)
+			if !ok {
+				return false
+			}
+			w.URL = webhook.GetMatrixRoomURL(matrix.HomeserverURL, matrix.Room)
+			w.Meta = meta
+		}
 	}
 
 	// Update events
@@ -255,7 +486,10 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *models.Webho
 	w.PullRequest = com.IsSliceContainsStr(form.Events, string(models.HookEventPullRequest))
 	w.Repository = com.IsSliceContainsStr(form.Events, string(models.HookEventRepository))
 	w.Release = com.IsSliceContainsStr(form.Events, string(models.HookEventRelease))
+	w.User = com.IsSliceContainsStr(form.Events, string(models.HookEventUser))
+	w.Organization = com.IsSliceContainsStr(form.Events, string(models.HookEventOrganization))
 	w.BranchFilter = form.BranchFilter
+	w.TagFilter = form.TagFilter
 
 	if err := w.UpdateEvent(); err != nil {
 		ctx.Error(http.StatusInternalServerError, "UpdateEvent", err)
@@ -46,3 +46,20 @@ func GetListOptions(ctx *context.APIContext) models.ListOptions {
 		PageSize: convert.ToCorrectPageSize(ctx.QueryInt("limit")),
 	}
 }
+
+// GetPaginatedListOptions returns list options using the page and limit parameters like
+// GetListOptions, but the first page is returned instead of all the items if no page is given
+func GetPaginatedListOptions(ctx *context.APIContext) models.ListOptions {
+	listOptions := GetListOptions(ctx)
+	if listOptions.Page <= 0 {
+		listOptions.Page = 1
+	}
+	return listOptions
+}
+
+// SetPaginationHeaders sets the Link header to the other pages of a paginated list and its
+// X-Total-Count header to the total number of items
+func SetPaginationHeaders(ctx *context.APIContext, total int64, listOptions models.ListOptions) {
+	ctx.SetLinkHeader(int(total), listOptions.PageSize)
+	ctx.SetTotalCountHeader(total)
+}
@@ -26,13 +26,20 @@ import (
 	"code.gitea.io/gitea/modules/markup/external"
 	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/options"
+	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/ssh"
+	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/task"
 	"code.gitea.io/gitea/modules/webhook"
+	"code.gitea.io/gitea/services/archiver"
+	attachment_service "code.gitea.io/gitea/services/attachment"
+	"code.gitea.io/gitea/services/automerge"
 	"code.gitea.io/gitea/services/mailer"
+	"code.gitea.io/gitea/services/mergequeue"
 	mirror_service "code.gitea.io/gitea/services/mirror"
 	pull_service "code.gitea.io/gitea/services/pull"
+	release_service "code.gitea.io/gitea/services/release"
 
 	"gitea.com/macaron/i18n"
 	"gitea.com/macaron/macaron"
@@ -138,6 +145,10 @@ func GlobalInit(ctx context.Context) {
 			log.Fatal("ORM engine initialization failed: %v", err)
 		}
 
+		if err := storage.Init(); err != nil {
+			log.Fatal("Failed to initialize storage: %v", err)
+		}
+
 		if err := models.InitOAuth2(); err != nil {
 			log.Fatal("Failed to initialize OAuth2 support: %v", err)
 		}
@@ -152,14 +163,38 @@ func GlobalInit(ctx context.Context) {
 			log.Fatal("Failed to initialize repository stats indexer queue: %v", err)
 		}
 		mirror_service.InitSyncMirrors()
-		webhook.InitDeliverHooks()
+		if err := webhook.InitDeliverHooks(); err != nil {
+			log.Fatal("Failed to initialize webhook delivery queue: %v", err)
+		}
 		if err := pull_service.Init(); err != nil {
 			log.Fatal("Failed to initialize test pull requests queue: %v", err)
 		}
+		if err := automerge.Init(); err != nil {
+			log.Fatal("Failed to initialize pull request auto merge queue: %v", err)
+		}
+		if err := mergequeue.Init(); err != nil {
+			log.Fatal("Failed to initialize merge queues: %v", err)
+		}
 		if err := task.Init(); err != nil {
 			log.Fatal("Failed to initialize task scheduler: %v", err)
 		}
+		if err := archiver.InitArchiveQueue(); err != nil {
+			log.Fatal("Failed to initialize repository archive queue: %v", err)
+		}
+		if err := archiver.InitBundleQueue(); err != nil {
+			log.Fatal("Failed to initialize repository bundle queue: %v", err)
+		}
+		if err := release_service.InitArchiveQueue(); err != nil {
+			log.Fatal("Failed to initialize release archive queue: %v", err)
+		}
+		if err := release_service.InitCDNQueue(); err != nil {
+			log.Fatal("Failed to initialize release CDN queue: %v", err)
+		}
+		if err := repo_module.InitCommitGraphQueue(); err != nil {
+			log.Fatal("Failed to initialize repository commit-graph queue: %v", err)
+		}
 		eventsource.GetManager().Init()
+		attachment_service.InitDownloadCounter()
 	}
 	if setting.EnableSQLite3 {
 		log.Info("SQLite3 Supported")
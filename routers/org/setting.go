@@ -13,6 +13,7 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/setting"
 	userSetting "code.gitea.io/gitea/routers/user/setting"
 )
@@ -49,6 +50,7 @@ func SettingsPost(ctx *context.Context, form auth.UpdateOrgSettingForm) {
 	}
 
 	org := ctx.Org.Organization
+	oldName := org.Name
 
 	// Check if organization name has been changed.
 	if org.LowerName != strings.ToLower(form.Name) {
@@ -77,6 +79,10 @@ func SettingsPost(ctx *context.Context, form auth.UpdateOrgSettingForm) {
 	org.Name = form.Name
 	org.LowerName = strings.ToLower(form.Name)
 
+	if !strings.EqualFold(oldName, org.Name) {
+		notification.NotifyRenameUser(ctx.User, org, oldName)
+	}
+
 	if ctx.User.IsAdmin {
 		org.MaxRepoCreation = form.MaxRepoCreation
 	}
@@ -160,6 +166,7 @@ func SettingsDelete(ctx *context.Context) {
 			}
 		} else {
 			log.Trace("Organization deleted: %s", org.Name)
+			notification.NotifyDeleteUser(ctx.User, org)
 			ctx.Redirect(setting.AppSubURL + "/")
 		}
 		return
@@ -58,7 +58,9 @@ func verifyCommits(oldCommitID, newCommitID string, repo *git.Repository, env []
 	return err
 }
 
-func checkFileProtection(oldCommitID, newCommitID string, patterns []glob.Glob, repo *git.Repository, env []string) error {
+// walkChangedFiles calls fn with each file changed between the merge base of the commits and newCommitID,
+// until fn returns an error
+func walkChangedFiles(oldCommitID, newCommitID string, repo *git.Repository, env []string, fn func(path string) error) error {
 
 	stdoutReader, stdoutWriter, err := os.Pipe()
 	if err != nil {
@@ -70,7 +72,7 @@ func checkFileProtection(oldCommitID, newCommitID string, patterns []glob.Glob,
 		_ = stdoutWriter.Close()
 	}()
 
-	err = git.NewCommand("diff", "--name-only", oldCommitID+"..."+newCommitID).
+	return git.NewCommand("diff", "--name-only", oldCommitID+"..."+newCommitID).
 		RunInDirTimeoutEnvFullPipelineFunc(env, -1, repo.Path,
 			stdoutWriter, nil, nil,
 			func(ctx context.Context, cancel context.CancelFunc) error {
@@ -82,14 +84,9 @@ func checkFileProtection(oldCommitID, newCommitID string, patterns []glob.Glob,
 					if len(path) == 0 {
 						continue
 					}
-					lpath := strings.ToLower(path)
-					for _, pat := range patterns {
-						if pat.Match(lpath) {
-							cancel()
-							return models.ErrFilePathProtected{
-								Path: path,
-							}
-						}
+					if err := fn(path); err != nil {
+						cancel()
+						return err
 					}
 				}
 				if err := scanner.Err(); err != nil {
@@ -98,12 +95,59 @@ func checkFileProtection(oldCommitID, newCommitID string, patterns []glob.Glob,
 				_ = stdoutReader.Close()
 				return err
 			})
+}
+
+func checkFileProtection(oldCommitID, newCommitID string, patterns []glob.Glob, repo *git.Repository, env []string) error {
+	err := walkChangedFiles(oldCommitID, newCommitID, repo, env, func(path string) error {
+		lpath := strings.ToLower(path)
+		for _, pat := range patterns {
+			if pat.Match(lpath) {
+				return models.ErrFilePathProtected{
+					Path: path,
+				}
+			}
+		}
+		return nil
+	})
 	if err != nil && !models.IsErrFilePathProtected(err) {
 		log.Error("Unable to check file protection for commits from %s to %s in %s: %v", oldCommitID, newCommitID, repo.Path, err)
 	}
 	return err
 }
 
+// checkLFSLocks returns an ErrLFSFileLocked if a file changed between the commits is locked by another user than the pusher,
+// deploy keys owning no lock
+func checkLFSLocks(oldCommitID, newCommitID string, locks []*models.LFSLock, opts private.HookOptions, repo *git.Repository, env []string) error {
+	lockedPaths := make(map[string]*models.LFSLock, len(locks))
+	for _, lock := range locks {
+		if opts.IsDeployKey || lock.OwnerID != opts.UserID {
+			lockedPaths[strings.ToLower(lock.Path)] = lock
+		}
+	}
+	if len(lockedPaths) == 0 {
+		return nil
+	}
+
+	err := walkChangedFiles(oldCommitID, newCommitID, repo, env, func(path string) error {
+		if lock, ok := lockedPaths[strings.ToLower(path)]; ok {
+			userName := ""
+			if lock.Owner != nil {
+				userName = lock.Owner.Name
+			}
+			return models.ErrLFSFileLocked{
+				RepoID:   lock.RepoID,
+				Path:     path,
+				UserName: userName,
+			}
+		}
+		return nil
+	})
+	if err != nil && !models.IsErrLFSFileLocked(err) {
+		log.Error("Unable to check lfs locks for commits from %s to %s in %s: %v", oldCommitID, newCommitID, repo.Path, err)
+	}
+	return err
+}
+
 func readAndVerifyCommitsFromShaReader(input io.ReadCloser, repo *git.Repository, env []string) error {
 	scanner := bufio.NewScanner(input)
 	for scanner.Scan() {
@@ -200,13 +244,67 @@ func HookPreReceive(ctx *macaron.Context, opts private.HookOptions) {
 			private.GitQuarantinePath+"="+opts.GitQuarantinePath)
 	}
 
+	// Files locked through the lfs lock api can only be changed by the owners of their locks
+	var lfsLocks []*models.LFSLock
+	if setting.LFS.StartServer {
+		lfsLocks, err = models.GetLFSLockByRepoID(repo.ID, 0, 0)
+		if err != nil {
+			log.Error("Unable to get lfs locks of %-v Error: %v", repo, err)
+			ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"err": err.Error(),
+			})
+			return
+		}
+	}
+
 	for i := range opts.OldCommitIDs {
 		oldCommitID := opts.OldCommitIDs[i]
 		newCommitID := opts.NewCommitIDs[i]
 		refFullName := opts.RefFullNames[i]
 
+		// detect changes and deletions of tags of protected releases
+		if strings.HasPrefix(refFullName, git.TagPrefix) && !git.IsEmptyCommitID(oldCommitID) {
+			tagName := strings.TrimPrefix(refFullName, git.TagPrefix)
+			rel, err := models.GetRelease(repo.ID, tagName)
+			if err != nil && !models.IsErrReleaseNotExist(err) {
+				log.Error("Unable to get release: %s in %-v Error: %v", tagName, repo, err)
+				ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+					"err": err.Error(),
+				})
+				return
+			}
+			if rel != nil {
+				rel.Repo = repo
+				var pusher *models.User
+				if !opts.IsDeployKey {
+					if pusher, err = models.GetUserByID(opts.UserID); err != nil {
+						log.Error("Unable to get User id %d Error: %v", opts.UserID, err)
+						ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+							"err": fmt.Sprintf("Unable to get User id %d Error: %v", opts.UserID, err),
+						})
+						return
+					}
+				}
+				protected, err := rel.IsProtectedFrom(pusher)
+				if err != nil {
+					log.Error("Unable to check protection of release: %s in %-v Error: %v", tagName, repo, err)
+					ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+						"err": err.Error(),
+					})
+					return
+				}
+				if protected {
+					log.Warn("Forbidden: Tag: %s in %-v belongs to a protected release", tagName, repo)
+					ctx.JSON(http.StatusForbidden, map[string]interface{}{
+						"err": fmt.Sprintf("tag %s belongs to a protected release", tagName),
+					})
+					return
+				}
+			}
+		}
+
 		branchName := strings.TrimPrefix(refFullName, git.BranchPrefix)
-		if branchName == repo.DefaultBranch && newCommitID == git.EmptySHA {
+		if branchName == repo.DefaultBranch && git.IsEmptyCommitID(newCommitID) {
 			log.Warn("Forbidden: Branch: %s is the default branch in %-v and cannot be deleted", branchName, repo)
 			ctx.JSON(http.StatusForbidden, map[string]interface{}{
 				"err": fmt.Sprintf("branch %s is the default branch and cannot be deleted", branchName),
@@ -214,6 +312,40 @@ func HookPreReceive(ctx *macaron.Context, opts private.HookOptions) {
 			return
 		}
 
+		// detect changes of files locked by other users
+		if len(lfsLocks) > 0 && strings.HasPrefix(refFullName, git.BranchPrefix) && !git.IsEmptyCommitID(newCommitID) {
+			baseCommitID := oldCommitID
+			if git.IsEmptyCommitID(baseCommitID) && gitRepo.IsBranchExist(repo.DefaultBranch) {
+				// new branches are compared with the default branch
+				baseCommitID, err = gitRepo.GetBranchCommitID(repo.DefaultBranch)
+				if err != nil {
+					log.Error("Unable to get commit of the default branch %s in %-v Error: %v", repo.DefaultBranch, repo, err)
+					ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+						"err": err.Error(),
+					})
+					return
+				}
+			}
+			if !git.IsEmptyCommitID(baseCommitID) {
+				err := checkLFSLocks(baseCommitID, newCommitID, lfsLocks, opts, gitRepo, env)
+				if err != nil {
+					if !models.IsErrLFSFileLocked(err) {
+						log.Error("Unable to check lfs locks for commits from %s to %s in %-v: %v", baseCommitID, newCommitID, repo, err)
+						ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+							"err": fmt.Sprintf("Unable to check lfs locks for commits from %s to %s: %v", baseCommitID, newCommitID, err),
+						})
+						return
+					}
+					lockErr := err.(models.ErrLFSFileLocked)
+					log.Warn("Forbidden: File %s in %-v is locked by %s", lockErr.Path, repo, lockErr.UserName)
+					ctx.JSON(http.StatusForbidden, map[string]interface{}{
+						"err": fmt.Sprintf("file %s is locked by %s", lockErr.Path, lockErr.UserName),
+					})
+					return
+				}
+			}
+		}
+
 		protectBranch, err := models.GetProtectedBranchBy(repo.ID, branchName)
 		if err != nil {
 			log.Error("Unable to get protected branch: %s in %-v Error: %v", branchName, repo, err)
@@ -224,7 +356,7 @@ func HookPreReceive(ctx *macaron.Context, opts private.HookOptions) {
 		}
 		if protectBranch != nil && protectBranch.IsProtected() {
 			// detect and prevent deletion
-			if newCommitID == git.EmptySHA {
+			if git.IsEmptyCommitID(newCommitID) {
 				log.Warn("Forbidden: Branch: %s in %-v is protected from deletion", branchName, repo)
 				ctx.JSON(http.StatusForbidden, map[string]interface{}{
 					"err": fmt.Sprintf("branch %s is protected from deletion", branchName),
@@ -233,7 +365,7 @@ func HookPreReceive(ctx *macaron.Context, opts private.HookOptions) {
 			}
 
 			// detect force push
-			if git.EmptySHA != oldCommitID {
+			if !git.IsEmptyCommitID(oldCommitID) {
 				output, err := git.NewCommand("rev-list", "--max-count=1", oldCommitID, "^"+newCommitID).RunInDirWithEnv(repo.RepoPath(), env)
 				if err != nil {
 					log.Error("Unable to detect force push between: %s and %s in %-v Error: %v", oldCommitID, newCommitID, repo, err)
@@ -446,7 +578,7 @@ func HookPostReceive(ctx *macaron.Context, opts private.HookOptions) {
 
 		branch := git.RefEndName(opts.RefFullNames[i])
 
-		if newCommitID != git.EmptySHA && strings.HasPrefix(refFullName, git.BranchPrefix) {
+		if !git.IsEmptyCommitID(newCommitID) && strings.HasPrefix(refFullName, git.BranchPrefix) {
 			if repo == nil {
 				var err error
 				repo, err = models.GetRepositoryByOwnerAndName(ownerName, repoName)
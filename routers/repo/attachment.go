@@ -7,14 +7,16 @@ package repo
 import (
 	"fmt"
 	"net/http"
-	"os"
 	"strings"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/upload"
+	attachment_service "code.gitea.io/gitea/services/attachment"
+	releaseservice "code.gitea.io/gitea/services/release"
 )
 
 func renderAttachmentSettings(ctx *context.Context) {
@@ -77,7 +79,15 @@ func DeleteAttachment(ctx *context.Context) {
 		ctx.Error(403)
 		return
 	}
-	err = models.DeleteAttachment(attach, true)
+	if attach.ReleaseID > 0 {
+		// assets of a release also have to be removed from its checksums
+		var rel *models.Release
+		if rel, err = models.GetReleaseByID(attach.ReleaseID); err == nil {
+			err = releaseservice.DeleteAttachment(ctx.User, rel, attach)
+		}
+	} else {
+		err = models.DeleteAttachment(attach, true)
+	}
 	if err != nil {
 		ctx.Error(500, fmt.Sprintf("DeleteAttachment: %v", err))
 		return
@@ -122,15 +132,34 @@ func GetAttachment(ctx *context.Context) {
 		}
 	}
 
+	if attach.ReleaseID > 0 {
+		rel, err := models.GetReleaseByID(attach.ReleaseID)
+		if err != nil && !models.IsErrReleaseNotExist(err) {
+			ctx.ServerError("GetReleaseByID", err)
+			return
+		}
+		setYankedReleaseWarning(ctx, rel)
+	}
+
+	// Downloads of assets mirrored to the release CDN are offloaded to it
+	if mirrorURL := attach.MirrorURL(); mirrorURL != "" && repository != nil && !repository.IsPrivate {
+		if err := attachment_service.IncreaseDownloadCount(attach); err != nil {
+			ctx.ServerError("Update", err)
+			return
+		}
+		ctx.Redirect(mirrorURL)
+		return
+	}
+
 	//If we have matched and access to release or issue
-	fr, err := os.Open(attach.LocalPath())
+	fr, err := storage.Attachments.Open(attach.RelativePath())
 	if err != nil {
 		ctx.ServerError("Open", err)
 		return
 	}
 	defer fr.Close()
 
-	if err := attach.IncreaseDownloadCount(); err != nil {
+	if err := attachment_service.IncreaseDownloadCount(attach); err != nil {
 		ctx.ServerError("Update", err)
 		return
 	}
@@ -5,11 +5,14 @@
 package repo
 
 import (
+	"bufio"
 	"bytes"
-	"container/list"
+	"encoding/json"
 	"fmt"
 	"html"
 	gotemplate "html/template"
+	"io"
+	"net/http"
 	"net/url"
 	"strings"
 
@@ -36,8 +39,6 @@ func RefBlame(ctx *context.Context) {
 		return
 	}
 
-	userName := ctx.Repo.Owner.Name
-	repoName := ctx.Repo.Repository.Name
 	commitID := ctx.Repo.CommitID
 
 	commit, err := ctx.Repo.GitRepo.GetCommit(commitID)
@@ -49,8 +50,8 @@ func RefBlame(ctx *context.Context) {
 		}
 		return
 	}
-	if len(commitID) != 40 {
-		commitID = commit.ID.String()
+	if commit.IsReplaced() {
+		ctx.Data["ReplacedCommit"] = commit
 	}
 
 	branchLink := ctx.Repo.RepoLink + "/src/" + ctx.Repo.BranchNameSubURL()
@@ -89,7 +90,7 @@ func RefBlame(ctx *context.Context) {
 	ctx.Data["LatestCommitVerification"] = models.ParseCommitWithSignature(latestCommit)
 	ctx.Data["LatestCommitUser"] = models.ValidateCommitWithEmail(latestCommit)
 
-	statuses, err := models.GetLatestCommitStatus(ctx.Repo.Repository, ctx.Repo.Commit.ID.String(), 0)
+	statuses, err := models.GetLatestCommitStatus(ctx.Repo.Repository, ctx.Repo.Commit.ID.String(), models.ListOptions{})
 	if err != nil {
 		log.Error("GetLatestCommitStatus: %v", err)
 	}
@@ -141,129 +142,151 @@ func RefBlame(ctx *context.Context) {
 	ctx.Data["FileSize"] = blob.Size()
 	ctx.Data["FileName"] = blob.Name()
 
-	blameReader, err := git.CreateBlameReader(models.RepoPath(userName, repoName), commitID, fileName)
-	if err != nil {
-		ctx.NotFound("CreateBlameReader", err)
+	// Get Topics of this repo
+	renderRepoTopics(ctx)
+	if ctx.Written() {
 		return
 	}
-	defer blameReader.Close()
 
-	blameParts := make([]git.BlamePart, 0)
+	if err := renderBlameLines(ctx, blob); err != nil {
+		ctx.ServerError("renderBlameLines", err)
+		return
+	}
 
-	for {
-		blamePart, err := blameReader.NextPart()
-		if err != nil {
-			ctx.NotFound("NextPart", err)
-			return
-		}
-		if blamePart == nil {
-			break
-		}
-		blameParts = append(blameParts, *blamePart)
+	ctx.HTML(200, tplBlame)
+}
+
+// renderBlameLines renders the lines of the file with empty commit infos,
+// the blame is loaded progressively by the browser from RefBlameIncremental
+func renderBlameLines(ctx *context.Context, blob *git.Blob) error {
+	dataRc, err := blob.DataAsync()
+	if err != nil {
+		return err
 	}
+	defer dataRc.Close()
 
-	commitNames := make(map[string]models.UserCommit)
-	commits := list.New()
+	var commitInfo bytes.Buffer
+	var lineNumbers bytes.Buffer
+	var codeLines bytes.Buffer
 
-	for _, part := range blameParts {
-		sha := part.Sha
-		if _, ok := commitNames[sha]; ok {
-			continue
+	reader := bufio.NewReader(dataRc)
+	for i := 1; ; i++ {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
 		}
-
-		commit, err := ctx.Repo.GitRepo.GetCommit(sha)
-		if err != nil {
-			if git.IsErrNotExist(err) {
-				ctx.NotFound("Repo.GitRepo.GetCommit", err)
-			} else {
-				ctx.ServerError("Repo.GitRepo.GetCommit", err)
-			}
-			return
+		if len(line) == 0 {
+			break
 		}
 
-		commits.PushBack(commit)
+		commitInfo.WriteString(`<div class="blame-info">&#8203;</div>`)
 
-		commitNames[commit.ID.String()] = models.UserCommit{}
-	}
-
-	commits = models.ValidateCommitsWithEmails(commits)
+		//Line number
+		lineNumbers.WriteString(fmt.Sprintf(`<span id="L%d">%d</span>`, i, i))
 
-	for e := commits.Front(); e != nil; e = e.Next() {
-		c := e.Value.(models.UserCommit)
+		//Code line
+		codeLines.WriteString(fmt.Sprintf(`<li class="L%d" rel="L%d">%s</li>`, i, i, gotemplate.HTMLEscapeString(line)))
 
-		commitNames[c.ID.String()] = c
-	}
-
-	// Get Topics of this repo
-	renderRepoTopics(ctx)
-	if ctx.Written() {
-		return
+		if err == io.EOF {
+			break
+		}
 	}
 
-	renderBlame(ctx, blameParts, commitNames)
-
-	ctx.HTML(200, tplBlame)
+	ctx.Data["BlameContent"] = gotemplate.HTML(codeLines.String())
+	ctx.Data["BlameCommitInfo"] = gotemplate.HTML(commitInfo.String())
+	ctx.Data["BlameLineNums"] = gotemplate.HTML(lineNumbers.String())
+	return nil
 }
 
-func renderBlame(ctx *context.Context, blameParts []git.BlamePart, commitNames map[string]models.UserCommit) {
-	repoLink := ctx.Repo.RepoLink
+// blameEntry is a range of lines of the blame streamed by RefBlameIncremental
+type blameEntry struct {
+	Sha       string `json:"sha"`
+	StartLine int    `json:"start_line"`
+	NumLines  int    `json:"num_lines"`
+	// HTML is the rendered info of the commit, only sent with the first entry of each commit
+	HTML string `json:"html,omitempty"`
+}
 
-	var lines = make([]string, 0)
+// RefBlameIncremental streams the blame of a file as newline delimited JSON entries
+// in the order git finds them, which lets the blame page render it progressively
+func RefBlameIncremental(ctx *context.Context) {
+	if len(ctx.Repo.TreePath) == 0 {
+		ctx.NotFound("Blame FileName", nil)
+		return
+	}
+	entry, err := ctx.Repo.Commit.GetTreeEntryByPath(ctx.Repo.TreePath)
+	if err != nil {
+		ctx.NotFoundOrServerError("Repo.Commit.GetTreeEntryByPath", git.IsErrNotExist, err)
+		return
+	}
+	if entry.IsDir() || entry.IsSubModule() {
+		ctx.NotFound("Blame FileName", nil)
+		return
+	}
 
-	var commitInfo bytes.Buffer
-	var lineNumbers bytes.Buffer
-	var codeLines bytes.Buffer
+	blameReader, err := git.CreateBlameIncrementalReader(ctx.Req.Context(), ctx.Repo.Repository.RepoPath(), ctx.Repo.Commit.ID.String(), ctx.Repo.TreePath)
+	if err != nil {
+		ctx.ServerError("CreateBlameIncrementalReader", err)
+		return
+	}
+	defer blameReader.Close()
 
-	var i = 0
-	for pi, part := range blameParts {
-		for index, line := range part.Lines {
-			i++
-			lines = append(lines, line)
+	ctx.Resp.Header().Set("Content-Type", "application/x-ndjson; charset=UTF-8")
+	ctx.Resp.Header().Set("X-Accel-Buffering", "no")
+	ctx.Resp.WriteHeader(http.StatusOK)
 
-			var attr = ""
-			if len(part.Lines)-1 == index && len(blameParts)-1 != pi {
-				attr = " bottom-line"
-			}
-			commit := commitNames[part.Sha]
-			if index == 0 {
-				// User avatar image
-				avatar := ""
-				commitSince := timeutil.TimeSinceUnix(timeutil.TimeStamp(commit.Author.When.Unix()), ctx.Data["Lang"].(string))
-				if commit.User != nil {
-					authorName := commit.Author.Name
-					if len(commit.User.FullName) > 0 {
-						authorName = commit.User.FullName
-					}
-					avatar = fmt.Sprintf(`<a href="%s/%s"><img class="ui avatar image" src="%s" title="%s" alt=""/></a>`, setting.AppSubURL, url.PathEscape(commit.User.Name), commit.User.RelAvatarLink(), html.EscapeString(authorName))
-				} else {
-					avatar = fmt.Sprintf(`<img class="ui avatar image" src="%s" title="%s"/>`, html.EscapeString(models.AvatarLink(commit.Author.Email)), html.EscapeString(commit.Author.Name))
-				}
-				commitInfo.WriteString(fmt.Sprintf(`<div class="blame-info%s"><div class="blame-data"><div class="blame-avatar">%s</div><div class="blame-message"><a href="%s/commit/%s" title="%[5]s">%[5]s</a></div><div class="blame-time">%s</div></div></div>`, attr, avatar, repoLink, part.Sha, html.EscapeString(commit.CommitMessage), commitSince))
-			} else {
-				commitInfo.WriteString(fmt.Sprintf(`<div class="blame-info%s">&#8203;</div>`, attr))
-			}
+	encoder := json.NewEncoder(ctx.Resp)
+	sent := make(map[string]bool)
+	for {
+		part, err := blameReader.NextEntry()
+		if err != nil {
+			log.Error("NextEntry: %v", err)
+			return
+		}
+		if part == nil {
+			return
+		}
 
-			//Line number
-			if len(part.Lines)-1 == index && len(blameParts)-1 != pi {
-				lineNumbers.WriteString(fmt.Sprintf(`<span id="L%d" class="bottom-line">%d</span>`, i, i))
-			} else {
-				lineNumbers.WriteString(fmt.Sprintf(`<span id="L%d">%d</span>`, i, i))
+		entry := &blameEntry{
+			Sha:       part.Sha,
+			StartLine: part.StartLine,
+			NumLines:  part.NumLines,
+		}
+		if !sent[entry.Sha] {
+			commit, err := ctx.Repo.GitRepo.GetCommit(entry.Sha)
+			if err != nil {
+				log.Error("GetCommit: %v", err)
+				return
 			}
+			entry.HTML = renderBlameCommitInfo(ctx, models.UserCommit{User: models.ValidateCommitWithEmail(commit), Commit: commit})
+			sent[entry.Sha] = true
+		}
 
-			//Code line
-			line = gotemplate.HTMLEscapeString(line)
-			if i != len(lines)-1 {
-				line += "\n"
-			}
-			if len(part.Lines)-1 == index && len(blameParts)-1 != pi {
-				codeLines.WriteString(fmt.Sprintf(`<li class="L%d bottom-line" rel="L%d">%s</li>`, i, i, line))
-			} else {
-				codeLines.WriteString(fmt.Sprintf(`<li class="L%d" rel="L%d">%s</li>`, i, i, line))
-			}
+		if err := encoder.Encode(entry); err != nil {
+			log.Error("Encode: %v", err)
+			return
 		}
+		ctx.Resp.Flush()
 	}
+}
 
-	ctx.Data["BlameContent"] = gotemplate.HTML(codeLines.String())
-	ctx.Data["BlameCommitInfo"] = gotemplate.HTML(commitInfo.String())
-	ctx.Data["BlameLineNums"] = gotemplate.HTML(lineNumbers.String())
+// renderBlameCommitInfo renders the author, the message and the time of the commit of a blame entry
+func renderBlameCommitInfo(ctx *context.Context, commit models.UserCommit) string {
+	// User avatar image
+	avatar := ""
+	commitSince := timeutil.TimeSinceUnix(timeutil.TimeStamp(commit.Author.When.Unix()), ctx.Data["Lang"].(string))
+	if commit.User != nil {
+		authorName := commit.Author.Name
+		if len(commit.User.FullName) > 0 {
+			authorName = commit.User.FullName
+		}
+		avatar = fmt.Sprintf(`<a href="%s/%s"><img class="ui avatar image" src="%s" title="%s" alt=""/></a>`, setting.AppSubURL, url.PathEscape(commit.User.Name), commit.User.RelAvatarLink(), html.EscapeString(authorName))
+	} else {
+		avatar = fmt.Sprintf(`<img class="ui avatar image" src="%s" title="%s"/>`, html.EscapeString(models.AvatarLink(commit.Author.Email)), html.EscapeString(commit.Author.Name))
+	}
+	replaced := ""
+	if commit.IsReplaced() {
+		replaced = fmt.Sprintf(`<span class="poping up" data-content="%s" data-variation="tiny inverted"><svg class="svg octicon-sync" width="16" height="16" aria-hidden="true"><use xlink:href="#octicon-sync" /></svg></span> `, html.EscapeString(ctx.Tr("repo.commits.replaced_by", base.ShortSha(commit.ReplacedBy.String()))))
+	}
+	return fmt.Sprintf(`<div class="blame-data"><div class="blame-avatar">%s</div><div class="blame-message">%s<a href="%s/commit/%s" title="%[5]s">%[5]s</a></div><div class="blame-time">%s</div></div>`, avatar, replaced, ctx.Repo.RepoLink, commit.ID, html.EscapeString(commit.CommitMessage), commitSince)
 }
@@ -50,7 +50,32 @@ func Branches(ctx *context.Context) {
 	ctx.Data["PageIsViewCode"] = true
 	ctx.Data["PageIsBranches"] = true
 
-	ctx.Data["Branches"] = loadBranches(ctx)
+	page := ctx.QueryInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	keyword := strings.TrimSpace(ctx.Query("q"))
+	sortType := ctx.Query("sort")
+
+	defaultBranch, branches, total := loadBranches(ctx, git.ListRefsOptions{
+		Prefix:   keyword,
+		Sort:     git.RefSortType(sortType),
+		Page:     page,
+		PageSize: git.BranchesRangeSize,
+	})
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["DefaultBranchBranch"] = defaultBranch
+	ctx.Data["Branches"] = branches
+	ctx.Data["Keyword"] = keyword
+	ctx.Data["SortType"] = sortType
+
+	pager := context.NewPagination(total, git.BranchesRangeSize, page, 5)
+	pager.AddParam(ctx, "q", "Keyword")
+	pager.AddParam(ctx, "sort", "SortType")
+	ctx.Data["Page"] = pager
+
 	ctx.HTML(200, tplBranch)
 }
 
@@ -96,44 +121,22 @@ func RestoreBranchPost(ctx *context.Context) {
 	branchName := ctx.Query("name")
 
 	deletedBranch, err := ctx.Repo.Repository.GetDeletedBranchByID(branchID)
-	if err != nil {
+	if err != nil || deletedBranch == nil {
 		log.Error("GetDeletedBranchByID: %v", err)
 		ctx.Flash.Error(ctx.Tr("repo.branch.restore_failed", branchName))
 		return
 	}
 
-	if err := ctx.Repo.GitRepo.CreateBranch(deletedBranch.Name, deletedBranch.Commit); err != nil {
-		if strings.Contains(err.Error(), "already exists") {
+	if err := repofiles.RestoreDeletedBranch(ctx.User, ctx.Repo.Repository, deletedBranch); err != nil {
+		if models.IsErrBranchAlreadyExists(err) {
 			ctx.Flash.Error(ctx.Tr("repo.branch.already_exists", deletedBranch.Name))
 			return
 		}
-		log.Error("CreateBranch: %v", err)
-		ctx.Flash.Error(ctx.Tr("repo.branch.restore_failed", deletedBranch.Name))
-		return
-	}
-
-	if err := ctx.Repo.Repository.RemoveDeletedBranch(deletedBranch.ID); err != nil {
-		log.Error("RemoveDeletedBranch: %v", err)
+		log.Error("RestoreDeletedBranch: %v", err)
 		ctx.Flash.Error(ctx.Tr("repo.branch.restore_failed", deletedBranch.Name))
 		return
 	}
 
-	// Don't return error below this
-	if err := repofiles.PushUpdate(
-		ctx.Repo.Repository,
-		deletedBranch.Name,
-		repofiles.PushUpdateOptions{
-			RefFullName:  git.BranchPrefix + deletedBranch.Name,
-			OldCommitID:  git.EmptySHA,
-			NewCommitID:  deletedBranch.Commit,
-			PusherID:     ctx.User.ID,
-			PusherName:   ctx.User.Name,
-			RepoUserName: ctx.Repo.Owner.Name,
-			RepoName:     ctx.Repo.Repository.Name,
-		}); err != nil {
-		log.Error("Update: %v", err)
-	}
-
 	ctx.Flash.Success(ctx.Tr("repo.branch.restore_success", deletedBranch.Name))
 }
 
@@ -173,24 +176,20 @@ func deleteBranch(ctx *context.Context, branchName string) error {
 		log.Error("Update: %v", err)
 	}
 
-	if err := ctx.Repo.Repository.AddDeletedBranch(branchName, commit.ID.String(), ctx.User.ID); err != nil {
-		log.Warn("AddDeletedBranch: %v", err)
-	}
-
 	return nil
 }
 
-func loadBranches(ctx *context.Context) []*Branch {
-	rawBranches, err := repo_module.GetBranches(ctx.Repo.Repository)
+func loadBranches(ctx *context.Context, opts git.ListRefsOptions) (*Branch, []*Branch, int) {
+	rawBranches, total, err := ctx.Repo.GitRepo.GetBranchesPage(opts)
 	if err != nil {
-		ctx.ServerError("GetBranches", err)
-		return nil
+		ctx.ServerError("GetBranchesPage", err)
+		return nil, nil, 0
 	}
 
 	protectedBranches, err := ctx.Repo.Repository.GetProtectedBranches()
 	if err != nil {
 		ctx.ServerError("GetProtectedBranches", err)
-		return nil
+		return nil, nil, 0
 	}
 
 	repoIDToRepo := map[int64]*models.Repository{}
@@ -198,101 +197,137 @@ func loadBranches(ctx *context.Context) []*Branch {
 
 	repoIDToGitRepo := map[int64]*git.Repository{}
 	repoIDToGitRepo[ctx.Repo.Repository.ID] = ctx.Repo.GitRepo
+	defer func() {
+		for repoID, gitRepo := range repoIDToGitRepo {
+			if repoID != ctx.Repo.Repository.ID {
+				gitRepo.Close()
+			}
+		}
+	}()
 
-	branches := make([]*Branch, len(rawBranches))
-	for i := range rawBranches {
-		commit, err := rawBranches[i].GetCommit()
+	// The default branch is shown on every page
+	var defaultBranch *Branch
+	if ctx.Repo.GitRepo.IsBranchExist(ctx.Repo.Repository.DefaultBranch) {
+		rawBranch, err := ctx.Repo.GitRepo.GetBranch(ctx.Repo.Repository.DefaultBranch)
 		if err != nil {
-			ctx.ServerError("GetCommit", err)
-			return nil
+			ctx.ServerError("GetBranch", err)
+			return nil, nil, 0
 		}
-
-		var isProtected bool
-		branchName := rawBranches[i].Name
-		for _, b := range protectedBranches {
-			if b.BranchName == branchName {
-				isProtected = true
-				break
-			}
+		if defaultBranch = loadOneBranch(ctx, rawBranch, protectedBranches, repoIDToRepo, repoIDToGitRepo); defaultBranch == nil {
+			return nil, nil, 0
 		}
+	}
 
-		divergence, divergenceError := repofiles.CountDivergingCommits(ctx.Repo.Repository, branchName)
-		if divergenceError != nil {
-			ctx.ServerError("CountDivergingCommits", divergenceError)
-			return nil
+	branches := make([]*Branch, 0, len(rawBranches))
+	for i := range rawBranches {
+		if rawBranches[i].Name == ctx.Repo.Repository.DefaultBranch {
+			continue
+		}
+		branch := loadOneBranch(ctx, rawBranches[i], protectedBranches, repoIDToRepo, repoIDToGitRepo)
+		if branch == nil {
+			return nil, nil, 0
 		}
+		branches = append(branches, branch)
+	}
 
-		pr, err := models.GetLatestPullRequestByHeadInfo(ctx.Repo.Repository.ID, branchName)
+	// The deleted branches are few, they are listed after the branches of the first page
+	if ctx.Repo.CanWrite(models.UnitTypeCode) && opts.Page <= 1 {
+		deletedBranches, err := getDeletedBranches(ctx)
 		if err != nil {
-			ctx.ServerError("GetLatestPullRequestByHeadInfo", err)
-			return nil
+			ctx.ServerError("getDeletedBranches", err)
+			return nil, nil, 0
 		}
-		headCommit := commit.ID.String()
-
-		mergeMovedOn := false
-		if pr != nil {
-			pr.HeadRepo = ctx.Repo.Repository
-			if err := pr.LoadIssue(); err != nil {
-				ctx.ServerError("pr.LoadIssue", err)
-				return nil
-			}
-			if repo, ok := repoIDToRepo[pr.BaseRepoID]; ok {
-				pr.BaseRepo = repo
-			} else if err := pr.LoadBaseRepo(); err != nil {
-				ctx.ServerError("pr.LoadBaseRepo", err)
-				return nil
-			} else {
-				repoIDToRepo[pr.BaseRepoID] = pr.BaseRepo
-			}
-			pr.Issue.Repo = pr.BaseRepo
-
-			if pr.HasMerged {
-				baseGitRepo, ok := repoIDToGitRepo[pr.BaseRepoID]
-				if !ok {
-					baseGitRepo, err = git.OpenRepository(pr.BaseRepo.RepoPath())
-					if err != nil {
-						ctx.ServerError("OpenRepository", err)
-						return nil
-					}
-					defer baseGitRepo.Close()
-					repoIDToGitRepo[pr.BaseRepoID] = baseGitRepo
-				}
-				pullCommit, err := baseGitRepo.GetRefCommitID(pr.GetGitRefName())
-				if err != nil && !git.IsErrNotExist(err) {
-					ctx.ServerError("GetBranchCommitID", err)
-					return nil
-				}
-				if err == nil && headCommit != pullCommit {
-					// the head has moved on from the merge - we shouldn't delete
-					mergeMovedOn = true
-				}
+		for _, deletedBranch := range deletedBranches {
+			if strings.HasPrefix(deletedBranch.Name, opts.Prefix) {
+				branches = append(branches, deletedBranch)
 			}
 		}
+	}
+
+	return defaultBranch, branches, total
+}
 
-		isIncluded := divergence.Ahead == 0 && ctx.Repo.Repository.DefaultBranch != branchName
-
-		branches[i] = &Branch{
-			Name:              branchName,
-			Commit:            commit,
-			IsProtected:       isProtected,
-			IsIncluded:        isIncluded,
-			CommitsAhead:      divergence.Ahead,
-			CommitsBehind:     divergence.Behind,
-			LatestPullRequest: pr,
-			MergeMovedOn:      mergeMovedOn,
+// loadOneBranch loads the information of the branch shown in the list of branches
+func loadOneBranch(ctx *context.Context, rawBranch *git.Branch, protectedBranches []*models.ProtectedBranch, repoIDToRepo map[int64]*models.Repository, repoIDToGitRepo map[int64]*git.Repository) *Branch {
+	commit, err := rawBranch.GetCommit()
+	if err != nil {
+		ctx.ServerError("GetCommit", err)
+		return nil
+	}
+
+	var isProtected bool
+	branchName := rawBranch.Name
+	for _, b := range protectedBranches {
+		if b.BranchName == branchName {
+			isProtected = true
+			break
 		}
 	}
 
-	if ctx.Repo.CanWrite(models.UnitTypeCode) {
-		deletedBranches, err := getDeletedBranches(ctx)
-		if err != nil {
-			ctx.ServerError("getDeletedBranches", err)
+	divergence, divergenceError := repofiles.CountDivergingCommits(ctx.Repo.Repository, branchName)
+	if divergenceError != nil {
+		ctx.ServerError("CountDivergingCommits", divergenceError)
+		return nil
+	}
+
+	pr, err := models.GetLatestPullRequestByHeadInfo(ctx.Repo.Repository.ID, branchName)
+	if err != nil {
+		ctx.ServerError("GetLatestPullRequestByHeadInfo", err)
+		return nil
+	}
+	headCommit := commit.ID.String()
+
+	mergeMovedOn := false
+	if pr != nil {
+		pr.HeadRepo = ctx.Repo.Repository
+		if err := pr.LoadIssue(); err != nil {
+			ctx.ServerError("pr.LoadIssue", err)
 			return nil
 		}
-		branches = append(branches, deletedBranches...)
+		if repo, ok := repoIDToRepo[pr.BaseRepoID]; ok {
+			pr.BaseRepo = repo
+		} else if err := pr.LoadBaseRepo(); err != nil {
+			ctx.ServerError("pr.LoadBaseRepo", err)
+			return nil
+		} else {
+			repoIDToRepo[pr.BaseRepoID] = pr.BaseRepo
+		}
+		pr.Issue.Repo = pr.BaseRepo
+
+		if pr.HasMerged {
+			baseGitRepo, ok := repoIDToGitRepo[pr.BaseRepoID]
+			if !ok {
+				baseGitRepo, err = git.OpenRepository(pr.BaseRepo.RepoPath())
+				if err != nil {
+					ctx.ServerError("OpenRepository", err)
+					return nil
+				}
+				repoIDToGitRepo[pr.BaseRepoID] = baseGitRepo
+			}
+			pullCommit, err := baseGitRepo.GetRefCommitID(pr.GetGitRefName())
+			if err != nil && !git.IsErrNotExist(err) {
+				ctx.ServerError("GetBranchCommitID", err)
+				return nil
+			}
+			if err == nil && headCommit != pullCommit {
+				// the head has moved on from the merge - we shouldn't delete
+				mergeMovedOn = true
+			}
+		}
 	}
 
-	return branches
+	isIncluded := divergence.Ahead == 0 && ctx.Repo.Repository.DefaultBranch != branchName
+
+	return &Branch{
+		Name:              branchName,
+		Commit:            commit,
+		IsProtected:       isProtected,
+		IsIncluded:        isIncluded,
+		CommitsAhead:      divergence.Ahead,
+		CommitsBehind:     divergence.Behind,
+		LatestPullRequest: pr,
+		MergeMovedOn:      mergeMovedOn,
+	}
 }
 
 func getDeletedBranches(ctx *context.Context) ([]*Branch, error) {
@@ -17,6 +17,8 @@ import (
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/lfs"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 )
 
 // ServeData download file from io.Reader
@@ -71,6 +73,10 @@ func ServeBlob(ctx *context.Context, blob *git.Blob) error {
 
 // ServeBlobOrLFS download a git.Blob redirecting to LFS if necessary
 func ServeBlobOrLFS(ctx *context.Context, blob *git.Blob) error {
+	if blob.Size() >= lfs.MetaFileMaxSize {
+		return ServeBlob(ctx, blob)
+	}
+
 	dataRc, err := blob.DataAsync()
 	if err != nil {
 		return err
@@ -86,6 +92,17 @@ func ServeBlobOrLFS(ctx *context.Context, blob *git.Blob) error {
 		if meta == nil {
 			return ServeBlob(ctx, blob)
 		}
+		if setting.LFS.ServeDirect {
+			// Redirect to the storage if it can serve the content directly
+			u, err := lfs.NewContentStore().URL(meta, path.Base(ctx.Repo.TreePath))
+			if err == nil {
+				ctx.Redirect(u.String())
+				return nil
+			}
+			if err != storage.ErrURLNotSupported {
+				return err
+			}
+		}
 		lfsDataRc, err := lfs.ReadMetaObject(meta)
 		if err != nil {
 			return err
@@ -27,6 +27,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/metrics"
 	"code.gitea.io/gitea/modules/process"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
@@ -199,6 +200,15 @@ func HTTP(ctx *context.Context) {
 			// Assume password is a token.
 			token, err := models.GetAccessTokenBySHA(authToken)
 			if err == nil {
+				requiredScope := models.AccessTokenScopeRepoWrite
+				if isPull {
+					requiredScope = models.AccessTokenScopeRepoRead
+				}
+				if !token.Scope.Has(requiredScope) {
+					ctx.HandleText(http.StatusForbidden, fmt.Sprintf("the access token doesn't grant the %s scope", requiredScope))
+					return
+				}
+
 				authUser, err = models.GetUserByID(token.UID)
 				if err != nil {
 					ctx.ServerError("GetUserByID", err)
@@ -395,7 +405,7 @@ func dummyInfoRefs(ctx *context.Context) {
 			}
 		}()
 
-		if err := git.InitRepository(tmpDir, true); err != nil {
+		if err := git.InitRepository(tmpDir, true, git.Sha1ObjectFormat); err != nil {
 			log.Error("Failed to init bare repo for git-receive-pack cache: %v", err)
 			return
 		}
@@ -552,13 +562,28 @@ func serviceRPC(h serviceHandler, service string) {
 	// set this for allow pre-receive and post-receive execute
 	h.environ = append(h.environ, "SSH_ORIGINAL_COMMAND="+service)
 
-	ctx, cancel := gocontext.WithCancel(git.DefaultContext)
+	// Pick up the object filter of partial clones for the metrics
+	var uploadPackReq *git.UploadPackRequestReader
+	if service == "upload-pack" && setting.Metrics.Enabled && !setting.Git.DisablePartialClone {
+		uploadPackReq = git.NewUploadPackRequestReader(reqBody)
+		reqBody = ioutil.NopCloser(uploadPackReq)
+	}
+
+	// Fetches are killed once their clients have gone, while pushes are run to the end
+	// so the references they update are not left behind by the post-receive hooks
+	parent := git.DefaultContext
+	if service == "upload-pack" {
+		parent = h.r.Context()
+	}
+	ctx, cancel := gocontext.WithCancel(parent)
 	defer cancel()
 	var stderr bytes.Buffer
-	cmd := exec.CommandContext(ctx, git.GitExecutable, service, "--stateless-rpc", h.dir)
+	args := append(append([]string{}, git.GlobalCommandArgs...), service, "--stateless-rpc", h.dir)
+	cmd := exec.CommandContext(ctx, git.GitExecutable, args...)
 	cmd.Dir = h.dir
+	cmd.Env = append(os.Environ(), gitProtocolEnviron(h.r)...)
 	if service == "receive-pack" {
-		cmd.Env = append(os.Environ(), h.environ...)
+		cmd.Env = append(cmd.Env, h.environ...)
 	}
 	cmd.Stdout = h.w
 	cmd.Stdin = reqBody
@@ -571,6 +596,10 @@ func serviceRPC(h serviceHandler, service string) {
 		log.Error("Fail to serve RPC(%s): %v - %s", service, err, stderr.String())
 		return
 	}
+
+	if uploadPackReq != nil && uploadPackReq.Filter != "" {
+		metrics.GitFilteredFetches.WithLabelValues(git.ObjectFilterKind(uploadPackReq.Filter)).Inc()
+	}
 }
 
 func serviceUploadPack(h serviceHandler) {
@@ -605,19 +634,33 @@ func packetWrite(str string) []byte {
 	return []byte(s + str)
 }
 
+// gitProtocolEnviron passes the wire protocol version requested by the client to git,
+// which only advertises the capabilities instead of all the refs for version 2
+func gitProtocolEnviron(r *http.Request) []string {
+	protocol := r.Header.Get("Git-Protocol")
+	if !setting.Git.EnableAutoGitWireProtocol || !git.IsValidProtocolParameters(protocol) {
+		return nil
+	}
+	return []string{"GIT_PROTOCOL=" + protocol}
+}
+
 func getInfoRefs(h serviceHandler) {
 	h.setHeaderNoCache()
 	if hasAccess(getServiceType(h.r), h, false) {
 		service := getServiceType(h.r)
-		refs, err := git.NewCommand(service, "--stateless-rpc", "--advertise-refs", ".").RunInDirBytes(h.dir)
+		protocolEnv := gitProtocolEnviron(h.r)
+		refs, err := git.NewCommand(service, "--stateless-rpc", "--advertise-refs", ".").RunInDirTimeoutEnv(append(os.Environ(), protocolEnv...), -1, h.dir)
 		if err != nil {
 			log.Error(fmt.Sprintf("%v - %s", err, string(refs)))
 		}
 
 		h.w.Header().Set("Content-Type", fmt.Sprintf("application/x-git-%s-advertisement", service))
 		h.w.WriteHeader(http.StatusOK)
-		_, _ = h.w.Write(packetWrite("# service=git-" + service + "\n"))
-		_, _ = h.w.Write([]byte("0000"))
+		// The service announcement is left out for version 2, like git-http-backend does
+		if len(protocolEnv) == 0 || !strings.Contains(protocolEnv[0], "version=2") {
+			_, _ = h.w.Write(packetWrite("# service=git-" + service + "\n"))
+			_, _ = h.w.Write([]byte("0000"))
+		}
 		_, _ = h.w.Write(refs)
 	} else {
 		updateServerInfo(h.dir)
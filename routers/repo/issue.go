@@ -1073,10 +1073,28 @@ func ViewIssue(ctx *context.Context) {
 				ctx.Data["MergeStyle"] = models.MergeStyleRebaseMerge
 			} else if prConfig.AllowSquash {
 				ctx.Data["MergeStyle"] = models.MergeStyleSquash
+			} else if prConfig.AllowRebaseFastForward {
+				ctx.Data["MergeStyle"] = models.MergeStyleRebaseFastForward
+			} else if prConfig.AllowFastForwardOnly {
+				ctx.Data["MergeStyle"] = models.MergeStyleFastForwardOnly
 			} else {
 				ctx.Data["MergeStyle"] = ""
 			}
 		}
+		autoMerge, err := models.GetScheduledAutoMergeByPullID(pull.ID)
+		if err != nil {
+			ctx.ServerError("GetScheduledAutoMergeByPullID", err)
+			return
+		}
+		if autoMerge != nil {
+			if err := autoMerge.LoadDoer(); err != nil && !models.IsErrUserNotExist(err) {
+				ctx.ServerError("LoadDoer", err)
+				return
+			}
+			ctx.Data["AutoMerge"] = autoMerge
+			ctx.Data["CanCancelAutoMerge"] = ctx.User != nil && (autoMerge.DoerID == ctx.User.ID || ctx.Data["AllowMerge"] == true)
+		}
+
 		if err = pull.LoadProtectedBranch(); err != nil {
 			ctx.ServerError("LoadProtectedBranch", err)
 			return
@@ -1086,8 +1104,37 @@ func ViewIssue(ctx *context.Context) {
 			ctx.Data["IsBlockedByApprovals"] = !pull.ProtectedBranch.HasEnoughApprovals(pull)
 			ctx.Data["IsBlockedByRejection"] = pull.ProtectedBranch.MergeBlockedByRejectedReview(pull)
 			ctx.Data["IsBlockedByOutdatedBranch"] = pull.ProtectedBranch.MergeBlockedByOutdatedBranch(pull)
+			if pull.ProtectedBranch.RequireCodeOwnerReviews {
+				owners, err := pull_service.GetUnapprovedCodeOwners(pull)
+				if err != nil {
+					ctx.ServerError("GetUnapprovedCodeOwners", err)
+					return
+				}
+				ctx.Data["IsBlockedByCodeOwners"] = len(owners) > 0
+				ctx.Data["UnapprovedCodeOwners"] = strings.Join(owners, ", ")
+			}
 			ctx.Data["GrantedApprovals"] = cnt
 			ctx.Data["RequireSigned"] = pull.ProtectedBranch.RequireSignedCommits
+			if pull.ProtectedBranch.EnableMergeQueue {
+				// The status checks don't block the pull request from entering the merge queue, which checks them
+				ctx.Data["EnableMergeQueue"] = true
+				ctx.Data["EnableStatusCheck"] = false
+			}
+		}
+		mergeQueueEntry, err := models.GetMergeQueueEntryByPullID(pull.ID)
+		if err != nil {
+			ctx.ServerError("GetMergeQueueEntryByPullID", err)
+			return
+		}
+		if mergeQueueEntry != nil {
+			position, err := mergeQueueEntry.Position()
+			if err != nil {
+				ctx.ServerError("Position", err)
+				return
+			}
+			ctx.Data["MergeQueueEntry"] = mergeQueueEntry
+			ctx.Data["MergeQueuePosition"] = position
+			ctx.Data["CanRemoveFromMergeQueue"] = ctx.User != nil && (mergeQueueEntry.DoerID == ctx.User.ID || ctx.Data["AllowMerge"] == true)
 		}
 		ctx.Data["WillSign"] = false
 		if ctx.User != nil {
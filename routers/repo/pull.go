@@ -27,7 +27,9 @@ import (
 	"code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/routers/utils"
+	"code.gitea.io/gitea/services/automerge"
 	"code.gitea.io/gitea/services/gitdiff"
+	"code.gitea.io/gitea/services/mergequeue"
 	pull_service "code.gitea.io/gitea/services/pull"
 	repo_service "code.gitea.io/gitea/services/repository"
 
@@ -367,7 +369,7 @@ func PrepareViewPullInfo(ctx *context.Context, issue *models.Issue) *git.Compare
 			ctx.ServerError(fmt.Sprintf("GetRefCommitID(%s)", pull.GetGitRefName()), err)
 			return nil
 		}
-		commitStatuses, err := models.GetLatestCommitStatus(repo, sha, 0)
+		commitStatuses, err := models.GetLatestCommitStatus(repo, sha, models.ListOptions{})
 		if err != nil {
 			ctx.ServerError("GetLatestCommitStatus", err)
 			return nil
@@ -426,6 +428,16 @@ func PrepareViewPullInfo(ctx *context.Context, issue *models.Issue) *git.Compare
 			return nil
 		}
 		ctx.Data["GetCommitMessages"] = pull_service.GetCommitMessages(pull)
+
+		prUnit, err := repo.GetUnit(models.UnitTypePullRequests)
+		if err != nil {
+			ctx.ServerError("GetUnit", err)
+			return nil
+		}
+		if len(strings.TrimSpace(prUnit.PullRequestsConfig().SquashMessageTemplate)) > 0 {
+			ctx.Data["HasSquashMessageTemplate"] = true
+			ctx.Data["SquashMessageTitle"], ctx.Data["SquashMessageBody"] = pull_service.GetSquashMergeMessage(pull)
+		}
 	}
 
 	sha, err := baseGitRepo.GetRefCommitID(pull.GetGitRefName())
@@ -434,7 +446,7 @@ func PrepareViewPullInfo(ctx *context.Context, issue *models.Issue) *git.Compare
 		return nil
 	}
 
-	commitStatuses, err := models.GetLatestCommitStatus(repo, sha, 0)
+	commitStatuses, err := models.GetLatestCommitStatus(repo, sha, models.ListOptions{})
 	if err != nil {
 		ctx.ServerError("GetLatestCommitStatus", err)
 		return nil
@@ -490,6 +502,7 @@ func PrepareViewPullInfo(ctx *context.Context, issue *models.Issue) *git.Compare
 
 	if pull.IsWorkInProgress() {
 		ctx.Data["IsPullWorkInProgress"] = true
+		ctx.Data["IsPullDraft"] = pull.IsDraft
 		ctx.Data["WorkInProgressPrefix"] = pull.GetWorkInProgressPrefix()
 	}
 
@@ -759,12 +772,27 @@ func MergePullRequest(ctx *context.Context, form auth.MergePullRequestForm) {
 		return
 	}
 
-	if err := pull_service.CheckPRReadyToMerge(pr); err != nil {
+	if err := pr.LoadProtectedBranch(); err != nil {
+		ctx.ServerError("LoadProtectedBranch", err)
+		return
+	}
+	// The pull requests of branches with a merge queue are added to it instead of being merged,
+	// their status checks are checked by the queue
+	useMergeQueue := pr.ProtectedBranch != nil && pr.ProtectedBranch.EnableMergeQueue
+	checkPRReady := pull_service.CheckPRReadyToMerge
+	if useMergeQueue {
+		checkPRReady = pull_service.CheckPRReadyToEnterMergeQueue
+	}
+
+	scheduleMerge := false
+	if err := checkPRReady(pr); err != nil {
 		if !models.IsErrNotAllowedToMerge(err) {
 			ctx.ServerError("Merge PR status", err)
 			return
 		}
-		if isRepoAdmin, err := models.IsUserRepoAdmin(pr.BaseRepo, ctx.User); err != nil {
+		if form.MergeWhenChecksSucceed && !useMergeQueue {
+			scheduleMerge = true
+		} else if isRepoAdmin, err := models.IsUserRepoAdmin(pr.BaseRepo, ctx.User); err != nil {
 			ctx.ServerError("IsUserRepoAdmin", err)
 			return
 		} else if !isRepoAdmin {
@@ -781,6 +809,7 @@ func MergePullRequest(ctx *context.Context, form auth.MergePullRequestForm) {
 	}
 
 	message := strings.TrimSpace(form.MergeTitleField)
+	form.MergeMessageField = strings.TrimSpace(form.MergeMessageField)
 	if len(message) == 0 {
 		if models.MergeStyle(form.Do) == models.MergeStyleMerge {
 			message = pr.GetDefaultMergeMessage()
@@ -789,11 +818,14 @@ func MergePullRequest(ctx *context.Context, form auth.MergePullRequestForm) {
 			message = pr.GetDefaultMergeMessage()
 		}
 		if models.MergeStyle(form.Do) == models.MergeStyleSquash {
-			message = pr.GetDefaultSquashMessage()
+			var body string
+			message, body = pull_service.GetSquashMergeMessage(pr)
+			if len(form.MergeMessageField) == 0 {
+				form.MergeMessageField = body
+			}
 		}
 	}
 
-	form.MergeMessageField = strings.TrimSpace(form.MergeMessageField)
 	if len(form.MergeMessageField) > 0 {
 		message += "\n\n" + form.MergeMessageField
 	}
@@ -801,6 +833,48 @@ func MergePullRequest(ctx *context.Context, form auth.MergePullRequestForm) {
 	pr.Issue = issue
 	pr.Issue.Repo = ctx.Repo.Repository
 
+	if useMergeQueue {
+		entry, err := mergequeue.Add(ctx.User, pr, models.MergeStyle(form.Do), message)
+		if err != nil {
+			if models.IsErrInvalidMergeStyle(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
+			} else if models.IsErrPullAlreadyInMergeQueue(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.merge_queue.already_added"))
+			} else {
+				ctx.ServerError("AddToMergeQueue", err)
+				return
+			}
+			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+			return
+		}
+		position, err := entry.Position()
+		if err != nil {
+			ctx.ServerError("Position", err)
+			return
+		}
+		ctx.Flash.Success(ctx.Tr("repo.pulls.merge_queue.added", position, pr.BaseBranch))
+		ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+		return
+	}
+
+	if scheduleMerge {
+		if err := automerge.ScheduleAutoMerge(ctx.User, pr, models.MergeStyle(form.Do), message); err != nil {
+			if models.IsErrInvalidMergeStyle(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
+			} else if models.IsErrPullAutoMergeAlreadyScheduled(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.auto_merge_already_scheduled"))
+			} else {
+				ctx.ServerError("ScheduleAutoMerge", err)
+				return
+			}
+			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+			return
+		}
+		ctx.Flash.Success(ctx.Tr("repo.pulls.auto_merge_newly_scheduled"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+		return
+	}
+
 	noDeps, err := models.IssueNoDependenciesLeft(issue)
 	if err != nil {
 		return
@@ -832,6 +906,11 @@ func MergePullRequest(ctx *context.Context, form auth.MergePullRequestForm) {
 			ctx.Flash.Error(ctx.Tr("repo.pulls.unrelated_histories"))
 			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
 			return
+		} else if models.IsErrMergeDivergingFastForwardOnly(err) {
+			log.Debug("MergeDivergingFastForwardOnly error: %v", err)
+			ctx.Flash.Error(ctx.Tr("repo.pulls.fast_forward_only_diverging"))
+			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+			return
 		} else if git.IsErrPushOutOfDate(err) {
 			log.Debug("MergePushOutOfDate error: %v", err)
 			ctx.Flash.Error(ctx.Tr("repo.pulls.merge_out_of_date"))
@@ -862,6 +941,114 @@ func MergePullRequest(ctx *context.Context, form auth.MergePullRequestForm) {
 	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
 }
 
+// CancelAutoMergePullRequest cancels the scheduled merge of a pull request
+func CancelAutoMergePullRequest(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pr := issue.PullRequest
+
+	autoMerge, err := models.GetScheduledAutoMergeByPullID(pr.ID)
+	if err != nil {
+		ctx.ServerError("GetScheduledAutoMergeByPullID", err)
+		return
+	}
+	if autoMerge == nil {
+		ctx.NotFound("GetScheduledAutoMergeByPullID", nil)
+		return
+	}
+
+	// Besides the user who scheduled the merge, the users who can merge the pull request can cancel it
+	if autoMerge.DoerID != ctx.User.ID {
+		allowedMerge, err := pull_service.IsUserAllowedToMerge(pr, ctx.Repo.Permission, ctx.User)
+		if err != nil {
+			ctx.ServerError("IsUserAllowedToMerge", err)
+			return
+		}
+		if !allowedMerge {
+			ctx.Error(http.StatusForbidden)
+			return
+		}
+	}
+
+	if err := automerge.CancelScheduledAutoMerge(ctx.User, pr); err != nil {
+		if models.IsErrPullAutoMergeNotExist(err) {
+			ctx.NotFound("CancelScheduledAutoMerge", err)
+			return
+		}
+		ctx.ServerError("CancelScheduledAutoMerge", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.pulls.auto_merge_canceled_schedule"))
+	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+}
+
+// RemoveFromMergeQueuePullRequest removes a pull request from the merge queue of its base branch
+func RemoveFromMergeQueuePullRequest(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pr := issue.PullRequest
+
+	entry, err := models.GetMergeQueueEntryByPullID(pr.ID)
+	if err != nil {
+		ctx.ServerError("GetMergeQueueEntryByPullID", err)
+		return
+	}
+	if entry == nil {
+		ctx.NotFound("GetMergeQueueEntryByPullID", nil)
+		return
+	}
+
+	// Besides the user who added the pull request to the merge queue, the users who can merge it can remove it
+	if entry.DoerID != ctx.User.ID {
+		allowedMerge, err := pull_service.IsUserAllowedToMerge(pr, ctx.Repo.Permission, ctx.User)
+		if err != nil {
+			ctx.ServerError("IsUserAllowedToMerge", err)
+			return
+		}
+		if !allowedMerge {
+			ctx.Error(http.StatusForbidden)
+			return
+		}
+	}
+
+	if err := mergequeue.Remove(ctx.User, pr); err != nil {
+		if models.IsErrPullNotInMergeQueue(err) {
+			ctx.NotFound("RemoveFromMergeQueue", err)
+			return
+		}
+		ctx.ServerError("RemoveFromMergeQueue", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.pulls.merge_queue.removed"))
+	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+}
+
+// ReadyForReviewPullRequest marks a draft pull request as ready for review
+func ReadyForReviewPullRequest(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !issue.IsPoster(ctx.User.ID) && !ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull) {
+		ctx.Error(http.StatusForbidden)
+		return
+	}
+
+	if err := pull_service.MarkReadyForReview(ctx.User, issue.PullRequest); err != nil {
+		if !models.IsErrPullRequestNotDraft(err) {
+			ctx.ServerError("MarkReadyForReview", err)
+			return
+		}
+	}
+	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(issue.Index))
+}
+
 func stopTimerIfAvailable(user *models.User, issue *models.Issue) error {
 
 	if models.StopwatchExists(user.ID, issue.ID) {
@@ -944,6 +1131,7 @@ func CompareAndPullRequestPost(ctx *context.Context, form auth.CreateIssueForm)
 		BaseRepo:   repo,
 		MergeBase:  prInfo.MergeBase,
 		Type:       models.PullRequestGitea,
+		IsDraft:    form.Draft,
 	}
 	// FIXME: check error in the case two people send pull request at almost same time, give nice error prompt
 	// instead of 500.
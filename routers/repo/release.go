@@ -7,12 +7,16 @@ package repo
 
 import (
 	"fmt"
+	"net/http"
+	"strconv"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/auth"
 	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/feed"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup/markdown"
 	"code.gitea.io/gitea/modules/setting"
@@ -39,7 +43,7 @@ func calReleaseNumCommitsBehind(repoCtx *context.Repository, release *models.Rel
 			if err != nil {
 				return fmt.Errorf("GetBranchCommit: %v", err)
 			}
-			countCache[release.Target], err = commit.CommitsCount()
+			countCache[release.Target], err = cache.GetCommitsCount(commit)
 			if err != nil {
 				return fmt.Errorf("CommitsCount: %v", err)
 			}
@@ -52,6 +56,31 @@ func calReleaseNumCommitsBehind(repoCtx *context.Repository, release *models.Rel
 	return nil
 }
 
+// getPreviousReleases maps the IDs of the published releases to the release published before them.
+// releases must be sorted from newest to oldest.
+func getPreviousReleases(releases []*models.Release) (map[int64]*models.Release, error) {
+	previousReleases := make(map[int64]*models.Release, len(releases))
+	var previous *models.Release
+	for i := len(releases) - 1; i >= 0; i-- {
+		rel := releases[i]
+		if rel.IsDraft || rel.IsTag {
+			continue
+		}
+		if previous == nil {
+			// The previous release of the oldest one on the page is on another page
+			var err error
+			if previous, err = models.GetPreviousRelease(rel); err != nil && !models.IsErrReleaseNotExist(err) {
+				return nil, err
+			}
+		}
+		if previous != nil {
+			previousReleases[rel.ID] = previous
+		}
+		previous = rel
+	}
+	return previousReleases, nil
+}
+
 // Releases render releases list page
 func Releases(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.release.releases")
@@ -94,6 +123,11 @@ func Releases(ctx *context.Context) {
 		return
 	}
 
+	if err = models.GetReleaseExternalAssets(releases...); err != nil {
+		ctx.ServerError("GetReleaseExternalAssets", err)
+		return
+	}
+
 	// Temporary cache commits count of used branches to speed up.
 	countCache := make(map[string]int64)
 	cacheUsers := make(map[int64]*models.User)
@@ -122,7 +156,14 @@ func Releases(ctx *context.Context) {
 		r.Note = markdown.RenderString(r.Note, ctx.Repo.RepoLink, ctx.Repo.Repository.ComposeMetas())
 	}
 
+	previousReleases, err := getPreviousReleases(releases)
+	if err != nil {
+		ctx.ServerError("getPreviousReleases", err)
+		return
+	}
+
 	ctx.Data["Releases"] = releases
+	ctx.Data["PreviousReleases"] = previousReleases
 
 	pager := context.NewPagination(int(count), opts.PageSize, opts.Page, 5)
 	pager.SetDefaultParams(ctx)
@@ -151,6 +192,11 @@ func SingleRelease(ctx *context.Context) {
 		return
 	}
 
+	if err = models.GetReleaseExternalAssets(release); err != nil {
+		ctx.ServerError("GetReleaseExternalAssets", err)
+		return
+	}
+
 	release.Publisher, err = models.GetUserByID(release.PublisherID)
 	if err != nil {
 		if models.IsErrUserNotExist(err) {
@@ -166,10 +212,108 @@ func SingleRelease(ctx *context.Context) {
 	}
 	release.Note = markdown.RenderString(release.Note, ctx.Repo.RepoLink, ctx.Repo.Repository.ComposeMetas())
 
+	previousReleases, err := getPreviousReleases([]*models.Release{release})
+	if err != nil {
+		ctx.ServerError("getPreviousReleases", err)
+		return
+	}
+
+	if !release.IsDraft && !release.IsTag {
+		comments, err := models.FindReleaseComments(release.ID, models.ListOptions{})
+		if err != nil {
+			ctx.ServerError("FindReleaseComments", err)
+			return
+		}
+		for _, comment := range comments {
+			comment.RenderedContent = string(markdown.Render([]byte(comment.Content), ctx.Repo.RepoLink,
+				ctx.Repo.Repository.ComposeMetas()))
+		}
+		ctx.Data["Release"] = release
+		ctx.Data["ReleaseComments"] = comments
+		ctx.Data["CanCommentRelease"] = ctx.IsSigned && !ctx.Repo.Repository.IsArchived
+	}
+
 	ctx.Data["Releases"] = []*models.Release{release}
+	ctx.Data["PreviousReleases"] = previousReleases
 	ctx.HTML(200, tplReleases)
 }
 
+// NewReleaseCommentPost response for adding a comment to a release
+func NewReleaseCommentPost(ctx *context.Context, form auth.CreateReleaseCommentForm) {
+	release, err := models.GetRelease(ctx.Repo.Repository.ID, ctx.Params("tag"))
+	if err != nil {
+		if models.IsErrReleaseNotExist(err) {
+			ctx.NotFound("GetRelease", err)
+		} else {
+			ctx.ServerError("GetRelease", err)
+		}
+		return
+	}
+	release.Repo = ctx.Repo.Repository
+	link := release.HTMLURL()
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(link)
+		return
+	}
+
+	comment, err := releaseservice.CreateReleaseComment(ctx.User, release, form.Content)
+	if err != nil {
+		if models.IsErrReleaseNotPublished(err) {
+			ctx.NotFound("CreateReleaseComment", err)
+		} else {
+			ctx.ServerError("CreateReleaseComment", err)
+		}
+		return
+	}
+	ctx.Redirect(comment.HTMLURL())
+}
+
+// DeleteReleaseComment delete a comment of a release
+func DeleteReleaseComment(ctx *context.Context) {
+	comment, err := models.GetReleaseCommentByID(ctx.QueryInt64("id"))
+	if err != nil {
+		if models.IsErrReleaseCommentNotExist(err) {
+			ctx.NotFound("GetReleaseCommentByID", err)
+		} else {
+			ctx.ServerError("GetReleaseCommentByID", err)
+		}
+		return
+	}
+	if comment.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound("DeleteReleaseComment", nil)
+		return
+	}
+	if ctx.User.ID != comment.PosterID && !ctx.Repo.IsAdmin() {
+		ctx.Error(http.StatusForbidden)
+		return
+	}
+	if err := comment.LoadRelease(); err != nil {
+		ctx.ServerError("LoadRelease", err)
+		return
+	}
+
+	if err := releaseservice.DeleteReleaseComment(ctx.User, comment); err != nil {
+		ctx.ServerError("DeleteReleaseComment", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.release.comment_deletion_success"))
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": comment.Release.HTMLURL(),
+	})
+}
+
+// setYankedReleaseWarning adds a warning header to the response of a download belonging to a yanked release
+func setYankedReleaseWarning(ctx *context.Context, rel *models.Release) {
+	if rel == nil || !rel.IsYanked {
+		return
+	}
+	ctx.Resp.Header().Set("Warning", "299 - "+strconv.QuoteToASCII(
+		fmt.Sprintf("release %s has been yanked: %s", rel.TagName, rel.YankedReason)))
+}
+
 // LatestRelease redirects to the latest release
 func LatestRelease(ctx *context.Context) {
 	release, err := models.GetLatestReleaseByRepoID(ctx.Repo.Repository.ID)
@@ -190,11 +334,87 @@ func LatestRelease(ctx *context.Context) {
 	ctx.Redirect(release.HTMLURL())
 }
 
+// feedReleasesLimit is the maximum number of releases listed in a feed
+const feedReleasesLimit = 20
+
+// ReleasesRSS renders the published releases of a repository as RSS feed
+func ReleasesRSS(ctx *context.Context) {
+	renderReleasesFeed(ctx, feed.RSSContentType, (*feed.Feed).RSS)
+}
+
+// ReleasesAtom renders the published releases of a repository as Atom feed
+func ReleasesAtom(ctx *context.Context) {
+	renderReleasesFeed(ctx, feed.AtomContentType, (*feed.Feed).Atom)
+}
+
+func renderReleasesFeed(ctx *context.Context, contentType string, render func(*feed.Feed) ([]byte, error)) {
+	releases, err := models.GetReleasesByRepoID(ctx.Repo.Repository.ID, models.FindReleasesOptions{
+		ListOptions: models.ListOptions{
+			Page:     1,
+			PageSize: feedReleasesLimit,
+		},
+	})
+	if err != nil {
+		ctx.ServerError("GetReleasesByRepoID", err)
+		return
+	}
+
+	f := &feed.Feed{
+		Title:       ctx.Tr("repo.release.feed_title", ctx.Repo.Repository.FullName()),
+		Link:        ctx.Repo.Repository.HTMLURL() + "/releases",
+		Description: ctx.Repo.Repository.Description,
+		Items:       make([]*feed.Item, 0, len(releases)),
+	}
+
+	cacheUsers := make(map[int64]*models.User)
+	for _, r := range releases {
+		r.Repo = ctx.Repo.Repository
+		publisher, ok := cacheUsers[r.PublisherID]
+		if !ok {
+			publisher, err = models.GetUserByID(r.PublisherID)
+			if err != nil {
+				if !models.IsErrUserNotExist(err) {
+					ctx.ServerError("GetUserByID", err)
+					return
+				}
+				publisher = models.NewGhostUser()
+			}
+			cacheUsers[r.PublisherID] = publisher
+		}
+
+		title := r.Title
+		if title == "" {
+			title = r.TagName
+		}
+		f.Items = append(f.Items, &feed.Item{
+			ID:      r.HTMLURL(),
+			Title:   title,
+			Link:    r.HTMLURL(),
+			Author:  publisher.DisplayName(),
+			Content: markdown.RenderString(r.Note, ctx.Repo.RepoLink, ctx.Repo.Repository.ComposeMetas()),
+			Created: r.CreatedUnix.AsTime(),
+		})
+	}
+
+	data, err := render(f)
+	if err != nil {
+		ctx.ServerError("RenderFeed", err)
+		return
+	}
+
+	ctx.Resp.Header().Set("Content-Type", contentType)
+	ctx.Resp.WriteHeader(http.StatusOK)
+	if _, err = ctx.Resp.Write(data); err != nil {
+		log.Error("Write: %v", err)
+	}
+}
+
 // NewRelease render creating release page
 func NewRelease(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.release.new_release")
 	ctx.Data["PageIsReleaseList"] = true
 	ctx.Data["tag_target"] = ctx.Repo.Repository.DefaultBranch
+	ctx.Data["content"] = releaseservice.GetReleaseTemplate(ctx.Repo.GitRepo, ctx.Repo.Repository)
 	renderAttachmentSettings(ctx)
 	ctx.HTML(200, tplReleaseNew)
 }
@@ -245,6 +465,9 @@ func NewReleasePost(ctx *context.Context, form auth.NewReleaseForm) {
 				ctx.RenderWithErr(ctx.Tr("repo.release.tag_name_already_exist"), tplReleaseNew, &form)
 			case models.IsErrInvalidTagName(err):
 				ctx.RenderWithErr(ctx.Tr("repo.release.tag_name_invalid"), tplReleaseNew, &form)
+			case models.IsErrReleaseAssetQuotaExceeded(err):
+				ctx.Data["Err_TagName"] = false
+				ctx.RenderWithErr(ctx.Tr("repo.release.asset_quota_exceeded"), tplReleaseNew, &form)
 			default:
 				ctx.ServerError("CreateRelease", err)
 			}
@@ -266,6 +489,10 @@ func NewReleasePost(ctx *context.Context, form auth.NewReleaseForm) {
 		rel.IsTag = false
 
 		if err = releaseservice.UpdateRelease(ctx.User, ctx.Repo.GitRepo, rel, attachmentUUIDs); err != nil {
+			if models.IsErrReleaseAssetQuotaExceeded(err) {
+				ctx.RenderWithErr(ctx.Tr("repo.release.asset_quota_exceeded"), tplReleaseNew, &form)
+				return
+			}
 			ctx.Data["Err_TagName"] = true
 			ctx.ServerError("UpdateRelease", err)
 			return
@@ -345,6 +572,14 @@ func EditReleasePost(ctx *context.Context, form auth.EditReleaseForm) {
 	rel.IsDraft = len(form.Draft) > 0
 	rel.IsPrerelease = form.Prerelease
 	if err = releaseservice.UpdateRelease(ctx.User, ctx.Repo.GitRepo, rel, attachmentUUIDs); err != nil {
+		if models.IsErrReleaseProtected(err) {
+			ctx.RenderWithErr(ctx.Tr("repo.release.protected"), tplReleaseNew, &form)
+			return
+		}
+		if models.IsErrReleaseAssetQuotaExceeded(err) {
+			ctx.RenderWithErr(ctx.Tr("repo.release.asset_quota_exceeded"), tplReleaseNew, &form)
+			return
+		}
 		ctx.ServerError("UpdateRelease", err)
 		return
 	}
@@ -354,7 +589,11 @@ func EditReleasePost(ctx *context.Context, form auth.EditReleaseForm) {
 // DeleteRelease delete a release
 func DeleteRelease(ctx *context.Context) {
 	if err := releaseservice.DeleteReleaseByID(ctx.QueryInt64("id"), ctx.User, true); err != nil {
-		ctx.Flash.Error("DeleteReleaseByID: " + err.Error())
+		if models.IsErrReleaseProtected(err) {
+			ctx.Flash.Error(ctx.Tr("repo.release.protected"))
+		} else {
+			ctx.Flash.Error("DeleteReleaseByID: " + err.Error())
+		}
 	} else {
 		ctx.Flash.Success(ctx.Tr("repo.release.deletion_success"))
 	}
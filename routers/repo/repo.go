@@ -8,8 +8,6 @@ package repo
 import (
 	"fmt"
 	"net/url"
-	"os"
-	"path"
 	"strings"
 
 	"code.gitea.io/gitea/models"
@@ -23,9 +21,8 @@ import (
 	"code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/task"
 	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/services/archiver"
 	repo_service "code.gitea.io/gitea/services/repository"
-
-	"github.com/unknwon/com"
 )
 
 const (
@@ -131,6 +128,7 @@ func Create(ctx *context.Context) {
 	ctx.Data["LabelTemplates"] = models.LabelTemplates
 	ctx.Data["Licenses"] = models.Licenses
 	ctx.Data["Readmes"] = models.Readmes
+	ctx.Data["ObjectFormats"] = git.SupportedObjectFormats()
 	ctx.Data["readme"] = "Default"
 	ctx.Data["private"] = getRepoPrivate(ctx)
 	ctx.Data["IsForcedPrivate"] = setting.Repository.ForcePrivate
@@ -171,6 +169,8 @@ func handleCreateError(ctx *context.Context, owner *models.User, err error, name
 	case models.IsErrNamePatternNotAllowed(err):
 		ctx.Data["Err_RepoName"] = true
 		ctx.RenderWithErr(ctx.Tr("repo.form.name_pattern_not_allowed", err.(models.ErrNamePatternNotAllowed).Pattern), tpl, form)
+	case git.IsErrUnsupportedObjectFormat(err):
+		ctx.RenderWithErr(ctx.Tr("repo.form.object_format_not_supported", err.(git.ErrUnsupportedObjectFormat).Name), tpl, form)
 	default:
 		ctx.ServerError(name, err)
 	}
@@ -184,6 +184,7 @@ func CreatePost(ctx *context.Context, form auth.CreateRepoForm) {
 	ctx.Data["LabelTemplates"] = models.LabelTemplates
 	ctx.Data["Licenses"] = models.Licenses
 	ctx.Data["Readmes"] = models.Readmes
+	ctx.Data["ObjectFormats"] = git.SupportedObjectFormats()
 
 	ctxUser := checkContextUser(ctx, form.UID)
 	if ctx.Written() {
@@ -234,15 +235,16 @@ func CreatePost(ctx *context.Context, form auth.CreateRepoForm) {
 		}
 	} else {
 		repo, err = repo_service.CreateRepository(ctx.User, ctxUser, models.CreateRepoOptions{
-			Name:          form.RepoName,
-			Description:   form.Description,
-			Gitignores:    form.Gitignores,
-			IssueLabels:   form.IssueLabels,
-			License:       form.License,
-			Readme:        form.Readme,
-			IsPrivate:     form.Private || setting.Repository.ForcePrivate,
-			DefaultBranch: form.DefaultBranch,
-			AutoInit:      form.AutoInit,
+			Name:             form.RepoName,
+			Description:      form.Description,
+			Gitignores:       form.Gitignores,
+			IssueLabels:      form.IssueLabels,
+			License:          form.License,
+			Readme:           form.Readme,
+			IsPrivate:        form.Private || setting.Repository.ForcePrivate,
+			DefaultBranch:    form.DefaultBranch,
+			AutoInit:         form.AutoInit,
+			ObjectFormatName: form.ObjectFormatName,
 		})
 		if err == nil {
 			log.Trace("Repository created [%d]: %s/%s", repo.ID, ctxUser.Name, repo.Name)
@@ -295,6 +297,8 @@ func handleMigrateError(ctx *context.Context, owner *models.User, err error, nam
 	case models.IsErrNamePatternNotAllowed(err):
 		ctx.Data["Err_RepoName"] = true
 		ctx.RenderWithErr(ctx.Tr("repo.form.name_pattern_not_allowed", err.(models.ErrNamePatternNotAllowed).Pattern), tpl, form)
+	case git.IsErrUnsupportedObjectFormat(err):
+		ctx.RenderWithErr(ctx.Tr("repo.form.object_format_not_supported", err.(git.ErrUnsupportedObjectFormat).Name), tpl, form)
 	default:
 		remoteAddr, _ := form.ParseRemoteAddr(owner)
 		err = util.URLSanitizedError(err, remoteAddr)
@@ -459,24 +463,15 @@ func RedirectDownload(ctx *context.Context) {
 	ctx.Error(404)
 }
 
-// Download download an archive of a repository
-func Download(ctx *context.Context) {
-	var (
-		uri         = ctx.Params("*")
-		refName     string
-		ext         string
-		archivePath string
-		archiveType git.ArchiveType
-	)
-
+// archiveCommit returns the commit and the type of the archive requested by the URI
+func archiveCommit(ctx *context.Context) (commit *git.Commit, refName, ext string, archiveType git.ArchiveType) {
+	uri := ctx.Params("*")
 	switch {
 	case strings.HasSuffix(uri, ".zip"):
 		ext = ".zip"
-		archivePath = path.Join(ctx.Repo.GitRepo.Path, "archives/zip")
 		archiveType = git.ZIP
 	case strings.HasSuffix(uri, ".tar.gz"):
 		ext = ".tar.gz"
-		archivePath = path.Join(ctx.Repo.GitRepo.Path, "archives/targz")
 		archiveType = git.TARGZ
 	default:
 		log.Trace("Unknown format: %s", uri)
@@ -485,18 +480,8 @@ func Download(ctx *context.Context) {
 	}
 	refName = strings.TrimSuffix(uri, ext)
 
-	if !com.IsDir(archivePath) {
-		if err := os.MkdirAll(archivePath, os.ModePerm); err != nil {
-			ctx.ServerError("Download -> os.MkdirAll(archivePath)", err)
-			return
-		}
-	}
-
 	// Get corresponding commit.
-	var (
-		commit *git.Commit
-		err    error
-	)
+	var err error
 	gitRepo := ctx.Repo.GitRepo
 	if gitRepo.IsBranchExist(refName) {
 		commit, err = gitRepo.GetBranchCommit(refName)
@@ -510,6 +495,12 @@ func Download(ctx *context.Context) {
 			ctx.ServerError("GetTagCommit", err)
 			return
 		}
+		rel, err := models.GetRelease(ctx.Repo.Repository.ID, refName)
+		if err != nil && !models.IsErrReleaseNotExist(err) {
+			ctx.ServerError("GetRelease", err)
+			return
+		}
+		setYankedReleaseWarning(ctx, rel)
 	} else if len(refName) >= 4 && len(refName) <= 40 {
 		commit, err = gitRepo.GetCommit(refName)
 		if err != nil {
@@ -520,21 +511,59 @@ func Download(ctx *context.Context) {
 		ctx.NotFound("Download", nil)
 		return
 	}
+	return commit, refName, ext, archiveType
+}
+
+// Download download an archive of a repository, waiting for its generation if it isn't cached yet
+func Download(ctx *context.Context) {
+	commit, refName, ext, archiveType := archiveCommit(ctx)
+	if ctx.Written() {
+		return
+	}
 
-	archivePath = path.Join(archivePath, base.ShortSha(commit.ID.String())+ext)
-	if !com.IsFile(archivePath) {
-		if err := commit.CreateArchive(archivePath, git.CreateArchiveOpts{
-			Format: archiveType,
-			Prefix: setting.Repository.PrefixArchiveFiles,
-		}); err != nil {
-			ctx.ServerError("Download -> CreateArchive "+archivePath, err)
+	var (
+		archivePath string
+		err         error
+	)
+	if ctx.QueryBool("submodules") {
+		if archiveType != git.TARGZ {
+			ctx.Error(400, "Submodules can only be included in tar.gz archives")
+			return
+		}
+		archivePath, err = archiver.CreateArchiveWithSubmodules(ctx.User, ctx.Repo.Repository, commit)
+	} else {
+		archivePath, err = archiver.WaitForArchive(ctx.Req.Context(), ctx.Repo.Repository, commit, archiveType)
+	}
+	if err != nil {
+		if ctx.Req.Context().Err() != nil {
+			// The client is gone, the archive is still generated in the background
 			return
 		}
+		ctx.ServerError("Download -> CreateArchive", err)
+		return
 	}
 
 	ctx.ServeFile(archivePath, ctx.Repo.Repository.Name+"-"+refName+ext)
 }
 
+// InitiateDownload queues the generation of an archive of a repository and returns whether it is complete,
+// so the archive can be downloaded without waiting once it is
+func InitiateDownload(ctx *context.Context) {
+	commit, _, _, archiveType := archiveCommit(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	_, complete, err := archiver.RequestArchive(ctx.Repo.Repository, commit.ID.String(), archiveType)
+	if err != nil {
+		ctx.ServerError("RequestArchive", err)
+		return
+	}
+	ctx.JSON(200, map[string]interface{}{
+		"complete": complete,
+	})
+}
+
 // Status returns repository's status
 func Status(ctx *context.Context) {
 	task, err := models.GetMigratingTask(ctx.Repo.Repository.ID)
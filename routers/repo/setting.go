@@ -20,6 +20,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
@@ -28,6 +29,7 @@ import (
 	"code.gitea.io/gitea/routers/utils"
 	"code.gitea.io/gitea/services/mailer"
 	mirror_service "code.gitea.io/gitea/services/mirror"
+	pull_service "code.gitea.io/gitea/services/pull"
 	repo_service "code.gitea.io/gitea/services/repository"
 
 	"github.com/unknwon/com"
@@ -51,13 +53,31 @@ func Settings(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.settings")
 	ctx.Data["PageIsSettingsOptions"] = true
 	ctx.Data["ForcePrivate"] = setting.Repository.ForcePrivate
+	ctx.Data["TrustModels"] = models.TrustModels
+	ctx.Data["DefaultTrustModel"] = models.DefaultTrustModelOfInstance().String()
+	ctx.Data["SquashMessagePlaceholders"] = squashMessagePlaceholders()
+	if len(setting.Repository.GitConfigAllowlist) > 0 {
+		values, err := ctx.Repo.Repository.GitConfigValues()
+		if err != nil {
+			ctx.ServerError("GitConfigValues", err)
+			return
+		}
+		ctx.Data["GitConfigAllowlist"] = setting.Repository.GitConfigAllowlist
+		ctx.Data["GitConfigValues"] = values
+	}
 	ctx.HTML(200, tplSettingsOptions)
 }
 
+// squashMessagePlaceholders returns the list of the placeholders of the squash message templates
+func squashMessagePlaceholders() string {
+	return "${" + strings.Join(pull_service.SquashMessagePlaceholders, "}, ${") + "}"
+}
+
 // SettingsPost response for changes of a repository
 func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 	ctx.Data["Title"] = ctx.Tr("repo.settings")
 	ctx.Data["PageIsSettingsOptions"] = true
+	ctx.Data["SquashMessagePlaceholders"] = squashMessagePlaceholders()
 
 	repo := ctx.Repo.Repository
 
@@ -71,6 +91,7 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 		newRepoName := form.RepoName
 		// Check if repository name has been changed.
 		if repo.LowerName != strings.ToLower(newRepoName) {
+			oldRepoName := repo.Name
 			// Close the GitRepo if open
 			if ctx.Repo.GitRepo != nil {
 				ctx.Repo.GitRepo.Close()
@@ -91,7 +112,7 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 				return
 			}
 
-			log.Trace("Repository name changed: %s/%s -> %s", ctx.Repo.Owner.Name, repo.Name, newRepoName)
+			log.Trace("Repository name changed: %s/%s -> %s", ctx.Repo.Owner.Name, oldRepoName, newRepoName)
 		}
 		// In case it's just a case change.
 		repo.Name = newRepoName
@@ -294,12 +315,27 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 					AllowRebase:               form.PullsAllowRebase,
 					AllowRebaseMerge:          form.PullsAllowRebaseMerge,
 					AllowSquash:               form.PullsAllowSquash,
+					AllowRebaseFastForward:    form.PullsAllowRebaseFastForward,
+					AllowFastForwardOnly:      form.PullsAllowFastForwardOnly,
+					SquashMessageTemplate:     form.PullsSquashMessageTemplate,
 				},
 			})
 		} else if !models.UnitTypePullRequests.UnitGlobalDisabled() {
 			deleteUnitTypes = append(deleteUnitTypes, models.UnitTypePullRequests)
 		}
 
+		if repo.UnitEnabled(models.UnitTypeReleases) {
+			units = append(units, models.RepoUnit{
+				RepoID: repo.ID,
+				Type:   models.UnitTypeReleases,
+				Config: &models.ReleasesConfig{
+					ProtectPublished:       form.ReleasesProtectPublished,
+					ProtectedTagPatterns:   strings.TrimSpace(form.ReleasesProtectedTagPatterns),
+					AutoReleaseTagPatterns: strings.TrimSpace(form.ReleasesAutoReleaseTagPatterns),
+				},
+			})
+		}
+
 		if err := models.UpdateRepositoryUnits(repo, units, deleteUnitTypes); err != nil {
 			ctx.ServerError("UpdateRepositoryUnits", err)
 			return
@@ -309,6 +345,44 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
 		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
 
+	case "signing":
+		if !models.IsValidTrustModel(form.TrustModel) {
+			ctx.Flash.Error(ctx.Tr("repo.settings.trust_model_not_supported"))
+			ctx.Redirect(repo.Link() + "/settings")
+			return
+		}
+		repo.TrustModel = models.ToTrustModel(form.TrustModel)
+		repo.TrustedKeys = strings.TrimSpace(form.TrustedKeys)
+		if err := models.UpdateRepositoryCols(repo, "trust_model", "trusted_keys"); err != nil {
+			ctx.ServerError("UpdateRepositoryCols", err)
+			return
+		}
+		log.Trace("Repository signing settings updated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
+		ctx.Redirect(repo.Link() + "/settings")
+
+	case "git_config":
+		values := make(map[string]string)
+		for _, name := range setting.Repository.GitConfigAllowlist {
+			if value := ctx.QueryTrim("git_config." + name); len(value) > 0 {
+				values[name] = value
+			}
+		}
+		if err := models.UpdateRepoGitConfigs(repo, values); err != nil {
+			if models.IsErrInvalidGitConfig(err) {
+				ctx.Flash.Error(ctx.Tr("repo.settings.git_config_invalid", err.(models.ErrInvalidGitConfig).Name))
+				ctx.Redirect(repo.Link() + "/settings")
+				return
+			}
+			ctx.ServerError("UpdateRepoGitConfigs", err)
+			return
+		}
+		log.Trace("Repository git config updated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
+		ctx.Redirect(repo.Link() + "/settings")
+
 	case "admin":
 		if !ctx.User.IsAdmin {
 			ctx.Error(403)
@@ -323,6 +397,10 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 			repo.CloseIssuesViaCommitInAnyBranch = form.EnableCloseIssuesViaCommitInAnyBranch
 		}
 
+		repo.ReleaseMaxAssetSize = form.ReleaseMaxAssetSize
+		repo.ReleaseMaxAssets = form.ReleaseMaxAssets
+		repo.ReleaseMaxTotalSize = form.ReleaseMaxTotalSize
+
 		if err := models.UpdateRepository(repo, false); err != nil {
 			ctx.ServerError("UpdateRepository", err)
 			return
@@ -393,15 +471,25 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 			ctx.Repo.GitRepo.Close()
 			ctx.Repo.GitRepo = nil
 		}
-		if err = repo_service.TransferOwnership(ctx.User, newOwner, repo, nil); err != nil {
+		pending, err := repo_service.StartRepositoryTransfer(ctx.User, newOwner, repo, nil)
+		if err != nil {
 			if models.IsErrRepoAlreadyExist(err) {
 				ctx.RenderWithErr(ctx.Tr("repo.settings.new_owner_has_same_repo"), tplSettingsOptions, nil)
+			} else if models.IsErrRepoTransferInProgress(err) {
+				ctx.RenderWithErr(ctx.Tr("repo.settings.transfer_in_progress"), tplSettingsOptions, nil)
 			} else {
-				ctx.ServerError("TransferOwnership", err)
+				ctx.ServerError("StartRepositoryTransfer", err)
 			}
 			return
 		}
 
+		if pending {
+			log.Trace("Repository transfer requested: %s/%s -> %s", ctx.Repo.Owner.Name, repo.Name, newOwner)
+			ctx.Flash.Success(ctx.Tr("repo.settings.transfer_started", newOwner.DisplayName()))
+			ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+			return
+		}
+
 		log.Trace("Repository transferred: %s/%s -> %s", ctx.Repo.Owner.Name, repo.Name, newOwner)
 		ctx.Flash.Success(ctx.Tr("repo.settings.transfer_succeed"))
 		ctx.Redirect(setting.AppSubURL + "/" + newOwner.Name + "/" + repo.Name)
@@ -463,6 +551,8 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 			return
 		}
 
+		notification.NotifyArchiveRepository(ctx.User, repo)
+
 		ctx.Flash.Success(ctx.Tr("repo.settings.archive.success"))
 
 		log.Trace("Repository was archived: %s/%s", ctx.Repo.Owner.Name, repo.Name)
@@ -480,6 +570,8 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 			return
 		}
 
+		notification.NotifyUnarchiveRepository(ctx.User, repo)
+
 		ctx.Flash.Success(ctx.Tr("repo.settings.unarchive.success"))
 
 		log.Trace("Repository was un-archived: %s/%s", ctx.Repo.Owner.Name, repo.Name)
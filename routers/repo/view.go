@@ -7,9 +7,9 @@ package repo
 
 import (
 	"bytes"
-	"encoding/base64"
 	"fmt"
 	gotemplate "html/template"
+	"io"
 	"io/ioutil"
 	"net/url"
 	"path"
@@ -261,7 +261,7 @@ func renderDirectory(ctx *context.Context, treeLink string) {
 		ctx.Data["IsLFSFile"] = false
 
 		// FIXME: what happens when README file is an image?
-		if isTextFile && setting.LFS.StartServer {
+		if isTextFile && setting.LFS.StartServer && readmeFile.blob.Size() < lfs.MetaFileMaxSize {
 			meta := lfs.IsPointerFile(&buf)
 			if meta != nil {
 				meta, err = ctx.Repo.Repository.GetLFSMetaObjectByOid(meta.Oid)
@@ -286,7 +286,7 @@ func renderDirectory(ctx *context.Context, treeLink string) {
 
 				buf = make([]byte, 1024)
 				n, err = dataRc.Read(buf)
-				if err != nil {
+				if err != nil && err != io.EOF {
 					ctx.ServerError("Data", err)
 					return
 				}
@@ -297,8 +297,7 @@ func renderDirectory(ctx *context.Context, treeLink string) {
 
 				fileSize = meta.Size
 				ctx.Data["FileSize"] = meta.Size
-				filenameBase64 := base64.RawURLEncoding.EncodeToString([]byte(readmeFile.name))
-				ctx.Data["RawFileLink"] = fmt.Sprintf("%s%s.git/info/lfs/objects/%s/%s", setting.AppURL, ctx.Repo.Repository.FullName(), meta.Oid, filenameBase64)
+				ctx.Data["RawFileLink"] = ctx.Repo.RepoLink + "/media/" + ctx.Repo.BranchNameSubURL() + "/" + path.Join(ctx.Repo.TreePath, readmeFile.name)
 			}
 		}
 
@@ -343,7 +342,7 @@ func renderDirectory(ctx *context.Context, treeLink string) {
 
 	ctx.Data["LatestCommitUser"] = models.ValidateCommitWithEmail(latestCommit)
 
-	statuses, err := models.GetLatestCommitStatus(ctx.Repo.Repository, ctx.Repo.Commit.ID.String(), 0)
+	statuses, err := models.GetLatestCommitStatus(ctx.Repo.Repository, ctx.Repo.Commit.ID.String(), models.ListOptions{})
 	if err != nil {
 		log.Error("GetLatestCommitStatus: %v", err)
 	}
@@ -386,7 +385,7 @@ func renderFile(ctx *context.Context, entry *git.TreeEntry, treeLink, rawLink st
 	ctx.Data["IsTextFile"] = isTextFile
 
 	//Check for LFS meta file
-	if isTextFile && setting.LFS.StartServer {
+	if isTextFile && setting.LFS.StartServer && fileSize < lfs.MetaFileMaxSize {
 		meta := lfs.IsPointerFile(&buf)
 		if meta != nil {
 			meta, err = ctx.Repo.Repository.GetLFSMetaObjectByOid(meta.Oid)
@@ -410,7 +409,7 @@ func renderFile(ctx *context.Context, entry *git.TreeEntry, treeLink, rawLink st
 
 			buf = make([]byte, 1024)
 			n, err = dataRc.Read(buf)
-			if err != nil {
+			if err != nil && err != io.EOF {
 				ctx.ServerError("Data", err)
 				return
 			}
@@ -421,8 +420,8 @@ func renderFile(ctx *context.Context, entry *git.TreeEntry, treeLink, rawLink st
 
 			fileSize = meta.Size
 			ctx.Data["FileSize"] = meta.Size
-			filenameBase64 := base64.RawURLEncoding.EncodeToString([]byte(blob.Name()))
-			ctx.Data["RawFileLink"] = fmt.Sprintf("%s%s.git/info/lfs/objects/%s/%s", setting.AppURL, ctx.Repo.Repository.FullName(), meta.Oid, filenameBase64)
+			// The content of the object is served with the media link, directly from the storage if it can
+			ctx.Data["RawFileLink"] = ctx.Repo.RepoLink + "/media/" + ctx.Repo.BranchNameSubURL() + "/" + ctx.Repo.TreePath
 		}
 	}
 	// Check LFS Lock
@@ -667,6 +666,9 @@ func renderCode(ctx *context.Context) {
 		ctx.NotFoundOrServerError("Repo.Commit.GetTreeEntryByPath", git.IsErrNotExist, err)
 		return
 	}
+	if ctx.Repo.Commit.IsReplaced() {
+		ctx.Data["ReplacedCommit"] = ctx.Repo.Commit
+	}
 
 	renderLanguageStats(ctx)
 	if ctx.Written() {
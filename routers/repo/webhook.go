@@ -16,9 +16,7 @@ import (
 	"code.gitea.io/gitea/modules/auth"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
-	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/setting"
-	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/webhook"
 
 	"github.com/unknwon/com"
@@ -45,9 +43,64 @@ func Webhooks(ctx *context.Context) {
 	}
 	ctx.Data["Webhooks"] = ws
 
+	if ctx.Repo.Owner.IsOrganization() {
+		orgHooks, err := models.GetWebhooksByOrgID(ctx.Repo.Owner.ID, models.ListOptions{})
+		if err != nil {
+			ctx.ServerError("GetWebhooksByOrgID", err)
+			return
+		}
+		ids, err := models.GetOptedOutWebhookIDs(ctx.Repo.Repository.ID)
+		if err != nil {
+			ctx.ServerError("GetOptedOutWebhookIDs", err)
+			return
+		}
+		optedOut := make(map[int64]bool, len(ids))
+		for _, id := range ids {
+			optedOut[id] = true
+		}
+		ctx.Data["OrgWebhooks"] = orgHooks
+		ctx.Data["OptedOutWebhooks"] = optedOut
+	}
+
 	ctx.HTML(200, tplHooks)
 }
 
+// DisableOrgWebhook stops sending the events of the repository to a webhook of its organization
+func DisableOrgWebhook(ctx *context.Context) {
+	setOrgWebhookOptOut(ctx, true)
+}
+
+// EnableOrgWebhook sends the events of the repository to a webhook of its organization again
+func EnableOrgWebhook(ctx *context.Context) {
+	setOrgWebhookOptOut(ctx, false)
+}
+
+func setOrgWebhookOptOut(ctx *context.Context, optOut bool) {
+	w, err := models.GetWebhookByOrgID(ctx.Repo.Owner.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrWebhookNotExist(err) {
+			ctx.NotFound("GetWebhookByOrgID", nil)
+		} else {
+			ctx.ServerError("GetWebhookByOrgID", err)
+		}
+		return
+	}
+
+	if err := models.SetWebhookOptOut(ctx.Repo.Repository.ID, w.ID, optOut); err != nil {
+		ctx.ServerError("SetWebhookOptOut", err)
+		return
+	}
+	if optOut {
+		ctx.Flash.Success(ctx.Tr("repo.settings.org_hook_disable_success"))
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.settings.org_hook_enable_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": ctx.Repo.RepoLink + "/settings/hooks",
+	})
+}
+
 type orgRepoCtx struct {
 	OrgID           int64
 	RepoID          int64
@@ -169,8 +222,11 @@ func ParseHookEvent(form auth.WebhookForm) *models.HookEvent {
 			PullRequestReview:    form.PullRequestReview,
 			PullRequestSync:      form.PullRequestSync,
 			Repository:           form.Repository,
+			User:                 form.User,
+			Organization:         form.Organization,
 		},
 		BranchFilter: form.BranchFilter,
+		TagFilter:    form.TagFilter,
 	}
 }
 
@@ -195,21 +251,27 @@ func GiteaHooksNewPost(ctx *context.Context, form auth.NewWebhookForm) {
 	}
 
 	contentType := models.ContentTypeJSON
-	if models.HookContentType(form.ContentType) == models.ContentTypeForm {
-		contentType = models.ContentTypeForm
+	switch models.HookContentType(form.ContentType) {
+	case models.ContentTypeForm, models.ContentTypeCloudEvents:
+		contentType = models.HookContentType(form.ContentType)
 	}
 
 	w := &models.Webhook{
-		RepoID:          orCtx.RepoID,
-		URL:             form.PayloadURL,
-		HTTPMethod:      form.HTTPMethod,
-		ContentType:     contentType,
-		Secret:          form.Secret,
-		HookEvent:       ParseHookEvent(form.WebhookForm),
-		IsActive:        form.Active,
-		HookTaskType:    models.GITEA,
-		OrgID:           orCtx.OrgID,
-		IsSystemWebhook: orCtx.IsSystemWebhook,
+		RepoID:             orCtx.RepoID,
+		URL:                form.PayloadURL,
+		HTTPMethod:         form.HTTPMethod,
+		ContentType:        contentType,
+		Secret:             form.Secret,
+		SignatureAlgorithm: form.SignatureAlgorithm,
+		HookEvent:          ParseHookEvent(form.WebhookForm),
+		ProxyURL:           form.ProxyURL,
+		IsActive:           form.Active,
+		HookTaskType:       models.GITEA,
+		OrgID:              orCtx.OrgID,
+		IsSystemWebhook:    orCtx.IsSystemWebhook,
+	}
+	if !setClientCertificate(ctx, w, form.ClientCertificate, form.ClientKey, orCtx.NewTemplate, &form) {
+		return
 	}
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
@@ -223,6 +285,22 @@ func GiteaHooksNewPost(ctx *context.Context, form auth.NewWebhookForm) {
 	ctx.Redirect(orCtx.Link)
 }
 
+// setClientCertificate sets the client certificate of the webhook from the form, the current
+// key is kept if the key is left empty. If the certificate is invalid, render the form with
+// the error. Return whether successful
+func setClientCertificate(ctx *context.Context, w *models.Webhook, cert, key string, tpl base.TplName, form interface{}) bool {
+	if err := w.SetClientCertificate(strings.TrimSpace(cert), strings.TrimSpace(key)); err != nil {
+		if models.IsErrInvalidClientCertificate(err) {
+			ctx.Data["Err_ClientCertificate"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.client_certificate_invalid", err.Error()), tpl, form)
+		} else {
+			ctx.ServerError("SetClientCertificate", err)
+		}
+		return false
+	}
+	return true
+}
+
 // GogsHooksNewPost response for creating webhook
 func GogsHooksNewPost(ctx *context.Context, form auth.NewGogshookForm) {
 	newGogsWebhookPost(ctx, form, models.GOGS)
@@ -259,11 +337,15 @@ func newGogsWebhookPost(ctx *context.Context, form auth.NewGogshookForm, kind mo
 		ContentType:     contentType,
 		Secret:          form.Secret,
 		HookEvent:       ParseHookEvent(form.WebhookForm),
+		ProxyURL:        form.ProxyURL,
 		IsActive:        form.Active,
 		HookTaskType:    kind,
 		OrgID:           orCtx.OrgID,
 		IsSystemWebhook: orCtx.IsSystemWebhook,
 	}
+	if !setClientCertificate(ctx, w, form.ClientCertificate, form.ClientKey, orCtx.NewTemplate, &form) {
+		return
+	}
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -309,6 +391,7 @@ func DiscordHooksNewPost(ctx *context.Context, form auth.NewDiscordHookForm) {
 		URL:             form.PayloadURL,
 		ContentType:     models.ContentTypeJSON,
 		HookEvent:       ParseHookEvent(form.WebhookForm),
+		ProxyURL:        form.ProxyURL,
 		IsActive:        form.Active,
 		HookTaskType:    models.DISCORD,
 		Meta:            string(meta),
@@ -351,6 +434,7 @@ func DingtalkHooksNewPost(ctx *context.Context, form auth.NewDingtalkHookForm) {
 		URL:             form.PayloadURL,
 		ContentType:     models.ContentTypeJSON,
 		HookEvent:       ParseHookEvent(form.WebhookForm),
+		ProxyURL:        form.ProxyURL,
 		IsActive:        form.Active,
 		HookTaskType:    models.DINGTALK,
 		Meta:            "",
@@ -402,6 +486,7 @@ func TelegramHooksNewPost(ctx *context.Context, form auth.NewTelegramHookForm) {
 		URL:             fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage?chat_id=%s", form.BotToken, form.ChatID),
 		ContentType:     models.ContentTypeJSON,
 		HookEvent:       ParseHookEvent(form.WebhookForm),
+		ProxyURL:        form.ProxyURL,
 		IsActive:        form.Active,
 		HookTaskType:    models.TELEGRAM,
 		Meta:            string(meta),
@@ -444,6 +529,7 @@ func MatrixHooksNewPost(ctx *context.Context, form auth.NewMatrixHookForm) {
 		Room:          form.RoomID,
 		AccessToken:   form.AccessToken,
 		MessageType:   form.MessageType,
+		PlainText:     form.PlainText,
 	})
 	if err != nil {
 		ctx.ServerError("Marshal", err)
@@ -452,9 +538,10 @@ func MatrixHooksNewPost(ctx *context.Context, form auth.NewMatrixHookForm) {
 
 	w := &models.Webhook{
 		RepoID:          orCtx.RepoID,
-		URL:             fmt.Sprintf("%s/_matrix/client/r0/rooms/%s/send/m.room.message", form.HomeserverURL, form.RoomID),
+		URL:             webhook.GetMatrixRoomURL(form.HomeserverURL, form.RoomID),
 		ContentType:     models.ContentTypeJSON,
 		HookEvent:       ParseHookEvent(form.WebhookForm),
+		ProxyURL:        form.ProxyURL,
 		IsActive:        form.Active,
 		HookTaskType:    models.MATRIX,
 		Meta:            string(meta),
@@ -473,6 +560,70 @@ func MatrixHooksNewPost(ctx *context.Context, form auth.NewMatrixHookForm) {
 	ctx.Redirect(orCtx.Link)
 }
 
+// CustomHooksNewPost response for creating a custom hook
+func CustomHooksNewPost(ctx *context.Context, form auth.NewCustomHookForm) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksNew"] = true
+	ctx.Data["Webhook"] = models.Webhook{HookEvent: &models.HookEvent{}}
+	ctx.Data["HookType"] = models.CUSTOM.Name()
+	ctx.Data["CustomHook"] = &webhook.CustomMeta{Template: form.Template}
+
+	orCtx, err := getOrgRepoCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getOrgRepoCtx", err)
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(200, orCtx.NewTemplate)
+		return
+	}
+
+	if err := webhook.ParseCustomTemplate(form.Template); err != nil {
+		ctx.Data["Err_Template"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.custom.template_invalid", err.Error()), orCtx.NewTemplate, &form)
+		return
+	}
+
+	meta, err := json.Marshal(&webhook.CustomMeta{
+		Template: form.Template,
+	})
+	if err != nil {
+		ctx.ServerError("Marshal", err)
+		return
+	}
+
+	w := &models.Webhook{
+		RepoID:             orCtx.RepoID,
+		URL:                form.PayloadURL,
+		HTTPMethod:         "POST",
+		ContentType:        models.ContentTypeJSON,
+		Secret:             form.Secret,
+		SignatureAlgorithm: form.SignatureAlgorithm,
+		HookEvent:          ParseHookEvent(form.WebhookForm),
+		ProxyURL:           form.ProxyURL,
+		IsActive:           form.Active,
+		HookTaskType:       models.CUSTOM,
+		Meta:               string(meta),
+		OrgID:              orCtx.OrgID,
+		IsSystemWebhook:    orCtx.IsSystemWebhook,
+	}
+	if !setClientCertificate(ctx, w, form.ClientCertificate, form.ClientKey, orCtx.NewTemplate, &form) {
+		return
+	}
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.CreateWebhook(w); err != nil {
+		ctx.ServerError("CreateWebhook", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
+	ctx.Redirect(orCtx.Link)
+}
+
 // MSTeamsHooksNewPost response for creating MS Teams hook
 func MSTeamsHooksNewPost(ctx *context.Context, form auth.NewMSTeamsHookForm) {
 	ctx.Data["Title"] = ctx.Tr("repo.settings")
@@ -480,6 +631,7 @@ func MSTeamsHooksNewPost(ctx *context.Context, form auth.NewMSTeamsHookForm) {
 	ctx.Data["PageIsSettingsHooksNew"] = true
 	ctx.Data["Webhook"] = models.Webhook{HookEvent: &models.HookEvent{}}
 	ctx.Data["HookType"] = models.MSTEAMS.Name()
+	ctx.Data["MSTeamsHook"] = &webhook.MSTeamsMeta{CardType: form.CardType}
 
 	orCtx, err := getOrgRepoCtx(ctx)
 	if err != nil {
@@ -492,14 +644,21 @@ func MSTeamsHooksNewPost(ctx *context.Context, form auth.NewMSTeamsHookForm) {
 		return
 	}
 
+	meta, err := json.Marshal(&webhook.MSTeamsMeta{CardType: form.CardType})
+	if err != nil {
+		ctx.ServerError("Marshal", err)
+		return
+	}
+
 	w := &models.Webhook{
 		RepoID:          orCtx.RepoID,
 		URL:             form.PayloadURL,
 		ContentType:     models.ContentTypeJSON,
 		HookEvent:       ParseHookEvent(form.WebhookForm),
+		ProxyURL:        form.ProxyURL,
 		IsActive:        form.Active,
 		HookTaskType:    models.MSTEAMS,
-		Meta:            "",
+		Meta:            string(meta),
 		OrgID:           orCtx.OrgID,
 		IsSystemWebhook: orCtx.IsSystemWebhook,
 	}
@@ -556,6 +715,7 @@ func SlackHooksNewPost(ctx *context.Context, form auth.NewSlackHookForm) {
 		URL:             form.PayloadURL,
 		ContentType:     models.ContentTypeJSON,
 		HookEvent:       ParseHookEvent(form.WebhookForm),
+		ProxyURL:        form.ProxyURL,
 		IsActive:        form.Active,
 		HookTaskType:    models.SLACK,
 		Meta:            string(meta),
@@ -598,6 +758,7 @@ func FeishuHooksNewPost(ctx *context.Context, form auth.NewFeishuHookForm) {
 		URL:             form.PayloadURL,
 		ContentType:     models.ContentTypeJSON,
 		HookEvent:       ParseHookEvent(form.WebhookForm),
+		ProxyURL:        form.ProxyURL,
 		IsActive:        form.Active,
 		HookTaskType:    models.FEISHU,
 		Meta:            "",
@@ -655,8 +816,13 @@ func checkWebhook(ctx *context.Context) (*orgRepoCtx, *models.Webhook) {
 		ctx.Data["TelegramHook"] = webhook.GetTelegramHook(w)
 	case models.MATRIX:
 		ctx.Data["MatrixHook"] = webhook.GetMatrixHook(w)
+	case models.MSTEAMS:
+		ctx.Data["MSTeamsHook"] = webhook.GetMSTeamsHook(w)
+	case models.CUSTOM:
+		ctx.Data["CustomHook"] = webhook.GetCustomHook(w)
 	}
 
+	ctx.Data["TestEvents"] = testEvents(w)
 	ctx.Data["History"], err = w.History(1)
 	if err != nil {
 		ctx.ServerError("History", err)
@@ -664,6 +830,20 @@ func checkWebhook(ctx *context.Context) (*orgRepoCtx, *models.Webhook) {
 	return orCtx, w
 }
 
+// testEvents returns the events a test delivery of the webhook can be sent for,
+// push comes first as it is the default one.
+func testEvents(w *models.Webhook) []string {
+	events := make([]string, 0, 10)
+	for _, event := range w.EventsArray() {
+		if event == string(models.HookEventPush) {
+			events = append([]string{event}, events...)
+		} else {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
 // WebHooksEdit render editing web hook page
 func WebHooksEdit(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.settings.update_webhook")
@@ -697,16 +877,22 @@ func WebHooksEditPost(ctx *context.Context, form auth.NewWebhookForm) {
 	}
 
 	contentType := models.ContentTypeJSON
-	if models.HookContentType(form.ContentType) == models.ContentTypeForm {
-		contentType = models.ContentTypeForm
+	switch models.HookContentType(form.ContentType) {
+	case models.ContentTypeForm, models.ContentTypeCloudEvents:
+		contentType = models.HookContentType(form.ContentType)
 	}
 
 	w.URL = form.PayloadURL
 	w.ContentType = contentType
 	w.Secret = form.Secret
+	w.SignatureAlgorithm = form.SignatureAlgorithm
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
+	w.ProxyURL = form.ProxyURL
 	w.IsActive = form.Active
 	w.HTTPMethod = form.HTTPMethod
+	if !setClientCertificate(ctx, w, form.ClientCertificate, form.ClientKey, orCtx.NewTemplate, &form) {
+		return
+	}
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -745,7 +931,11 @@ func GogsHooksEditPost(ctx *context.Context, form auth.NewGogshookForm) {
 	w.ContentType = contentType
 	w.Secret = form.Secret
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
+	w.ProxyURL = form.ProxyURL
 	w.IsActive = form.Active
+	if !setClientCertificate(ctx, w, form.ClientCertificate, form.ClientKey, orCtx.NewTemplate, &form) {
+		return
+	}
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -795,6 +985,7 @@ func SlackHooksEditPost(ctx *context.Context, form auth.NewSlackHookForm) {
 	w.URL = form.PayloadURL
 	w.Meta = string(meta)
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
+	w.ProxyURL = form.ProxyURL
 	w.IsActive = form.Active
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
@@ -837,6 +1028,7 @@ func DiscordHooksEditPost(ctx *context.Context, form auth.NewDiscordHookForm) {
 	w.URL = form.PayloadURL
 	w.Meta = string(meta)
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
+	w.ProxyURL = form.ProxyURL
 	w.IsActive = form.Active
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
@@ -869,6 +1061,7 @@ func DingtalkHooksEditPost(ctx *context.Context, form auth.NewDingtalkHookForm)
 
 	w.URL = form.PayloadURL
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
+	w.ProxyURL = form.ProxyURL
 	w.IsActive = form.Active
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
@@ -909,6 +1102,7 @@ func TelegramHooksEditPost(ctx *context.Context, form auth.NewTelegramHookForm)
 	w.Meta = string(meta)
 	w.URL = fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage?chat_id=%s", form.BotToken, form.ChatID)
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
+	w.ProxyURL = form.ProxyURL
 	w.IsActive = form.Active
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
@@ -943,15 +1137,17 @@ func MatrixHooksEditPost(ctx *context.Context, form auth.NewMatrixHookForm) {
 		Room:          form.RoomID,
 		AccessToken:   form.AccessToken,
 		MessageType:   form.MessageType,
+		PlainText:     form.PlainText,
 	})
 	if err != nil {
 		ctx.ServerError("Marshal", err)
 		return
 	}
 	w.Meta = string(meta)
-	w.URL = fmt.Sprintf("%s/_matrix/client/r0/rooms/%s/send/m.room.message", form.HomeserverURL, form.RoomID)
+	w.URL = webhook.GetMatrixRoomURL(form.HomeserverURL, form.RoomID)
 
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
+	w.ProxyURL = form.ProxyURL
 	w.IsActive = form.Active
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
@@ -965,6 +1161,60 @@ func MatrixHooksEditPost(ctx *context.Context, form auth.NewMatrixHookForm) {
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
 
+// CustomHooksEditPost response for editing a custom hook
+func CustomHooksEditPost(ctx *context.Context, form auth.NewCustomHookForm) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksEdit"] = true
+
+	orCtx, w := checkWebhook(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Webhook"] = w
+	ctx.Data["CustomHook"] = &webhook.CustomMeta{Template: form.Template}
+
+	if ctx.HasError() {
+		ctx.HTML(200, orCtx.NewTemplate)
+		return
+	}
+
+	if err := webhook.ParseCustomTemplate(form.Template); err != nil {
+		ctx.Data["Err_Template"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.custom.template_invalid", err.Error()), orCtx.NewTemplate, &form)
+		return
+	}
+
+	meta, err := json.Marshal(&webhook.CustomMeta{
+		Template: form.Template,
+	})
+	if err != nil {
+		ctx.ServerError("Marshal", err)
+		return
+	}
+	w.Meta = string(meta)
+	w.URL = form.PayloadURL
+	w.Secret = form.Secret
+	w.SignatureAlgorithm = form.SignatureAlgorithm
+
+	w.HookEvent = ParseHookEvent(form.WebhookForm)
+	w.ProxyURL = form.ProxyURL
+	w.IsActive = form.Active
+	if !setClientCertificate(ctx, w, form.ClientCertificate, form.ClientKey, orCtx.NewTemplate, &form) {
+		return
+	}
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.UpdateWebhook(w); err != nil {
+		ctx.ServerError("UpdateWebhook", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
+	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
+}
+
 // MSTeamsHooksEditPost response for editing MS Teams hook
 func MSTeamsHooksEditPost(ctx *context.Context, form auth.NewMSTeamsHookForm) {
 	ctx.Data["Title"] = ctx.Tr("repo.settings")
@@ -982,8 +1232,16 @@ func MSTeamsHooksEditPost(ctx *context.Context, form auth.NewMSTeamsHookForm) {
 		return
 	}
 
+	meta, err := json.Marshal(&webhook.MSTeamsMeta{CardType: form.CardType})
+	if err != nil {
+		ctx.ServerError("Marshal", err)
+		return
+	}
+
 	w.URL = form.PayloadURL
+	w.Meta = string(meta)
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
+	w.ProxyURL = form.ProxyURL
 	w.IsActive = form.Active
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
@@ -1016,6 +1274,7 @@ func FeishuHooksEditPost(ctx *context.Context, form auth.NewFeishuHookForm) {
 
 	w.URL = form.PayloadURL
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
+	w.ProxyURL = form.ProxyURL
 	w.IsActive = form.Active
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
@@ -1039,43 +1298,23 @@ func TestWebhook(ctx *context.Context) {
 		return
 	}
 
-	// Grab latest commit or fake one if it's empty repository.
-	commit := ctx.Repo.Commit
-	if commit == nil {
-		ghost := models.NewGhostUser()
-		commit = &git.Commit{
-			ID:            git.MustIDFromString(git.EmptySHA),
-			Author:        ghost.NewGitSig(),
-			Committer:     ghost.NewGitSig(),
-			CommitMessage: "This is a fake commit",
-		}
+	event := models.HookEventPush
+	if len(ctx.Query("event")) > 0 {
+		event = models.HookEventType(ctx.Query("event"))
+	}
+	if !com.IsSliceContainsStr(w.EventsArray(), string(event)) {
+		ctx.Flash.Error(ctx.Tr("repo.settings.webhook.test_delivery_event_invalid", event))
+		ctx.Status(422)
+		return
 	}
 
-	apiUser := ctx.User.APIFormat()
-	p := &api.PushPayload{
-		Ref:    git.BranchPrefix + ctx.Repo.Repository.DefaultBranch,
-		Before: commit.ID.String(),
-		After:  commit.ID.String(),
-		Commits: []*api.PayloadCommit{
-			{
-				ID:      commit.ID.String(),
-				Message: commit.Message(),
-				URL:     ctx.Repo.Repository.HTMLURL() + "/commit/" + commit.ID.String(),
-				Author: &api.PayloadUser{
-					Name:  commit.Author.Name,
-					Email: commit.Author.Email,
-				},
-				Committer: &api.PayloadUser{
-					Name:  commit.Committer.Name,
-					Email: commit.Committer.Email,
-				},
-			},
-		},
-		Repo:   ctx.Repo.Repository.APIFormat(models.AccessModeNone),
-		Pusher: apiUser,
-		Sender: apiUser,
+	p, err := webhook.GetSamplePayload(event, ctx.Repo.Repository, ctx.User, ctx.Repo.Commit)
+	if err != nil {
+		ctx.Flash.Error("GetSamplePayload: " + err.Error())
+		ctx.Status(500)
+		return
 	}
-	if err := webhook.PrepareWebhook(w, ctx.Repo.Repository, models.HookEventPush, p); err != nil {
+	if err := webhook.PrepareWebhook(w, ctx.Repo.Repository, event, p); err != nil {
 		ctx.Flash.Error("PrepareWebhook: " + err.Error())
 		ctx.Status(500)
 	} else {
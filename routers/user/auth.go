@@ -18,6 +18,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/eventsource"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/password"
 	"code.gitea.io/gitea/modules/recaptcha"
 	"code.gitea.io/gitea/modules/setting"
@@ -972,6 +973,7 @@ func LinkAccountPostRegister(ctx *context.Context, cpt *captcha.Captcha, form au
 		return
 	}
 	log.Trace("Account created: %s", u.Name)
+	notification.NotifyCreateUser(nil, u)
 
 	// Auto-set admin for the only user.
 	if models.CountUsers() == 1 {
@@ -1137,6 +1139,7 @@ func SignUpPost(ctx *context.Context, cpt *captcha.Captcha, form auth.RegisterFo
 		return
 	}
 	log.Trace("Account created: %s", u.Name)
+	notification.NotifyCreateUser(nil, u)
 
 	// Auto-set admin for the only user.
 	if models.CountUsers() == 1 {
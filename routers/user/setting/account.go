@@ -13,6 +13,7 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/password"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
@@ -235,6 +236,7 @@ func DeleteAccount(ctx *context.Context) {
 		}
 	} else {
 		log.Trace("Account deleted: %s", ctx.User.Name)
+		notification.NotifyDeleteUser(ctx.User, ctx.User)
 		ctx.Redirect(setting.AppSubURL + "/")
 	}
 }
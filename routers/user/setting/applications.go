@@ -39,9 +39,17 @@ func ApplicationsPost(ctx *context.Context, form auth.NewAccessTokenForm) {
 		return
 	}
 
+	scope, err := models.ParseAccessTokenScope(form.Scope)
+	if err != nil {
+		ctx.Flash.Error(err.Error())
+		ctx.Redirect(setting.AppSubURL + "/user/settings/applications")
+		return
+	}
+
 	t := &models.AccessToken{
-		UID:  ctx.User.ID,
-		Name: form.Name,
+		UID:   ctx.User.ID,
+		Name:  form.Name,
+		Scope: scope,
 	}
 
 	exist, err := models.AccessTokenByNameExists(t)
@@ -16,6 +16,7 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/setting"
 
 	"github.com/unknwon/com"
@@ -43,6 +44,7 @@ func handleUsernameChange(ctx *context.Context, newName string) {
 	}
 
 	// Check if user name has been changed
+	oldName := ctx.User.Name
 	if ctx.User.LowerName != strings.ToLower(newName) {
 		if err := models.ChangeUserName(ctx.User, newName); err != nil {
 			switch {
@@ -72,6 +74,10 @@ func handleUsernameChange(ctx *context.Context, newName string) {
 	// In case it's just a case change
 	ctx.User.Name = newName
 	ctx.User.LowerName = strings.ToLower(newName)
+
+	if !strings.EqualFold(oldName, newName) {
+		notification.NotifyRenameUser(ctx.User, ctx.User, oldName)
+	}
 }
 
 // ProfilePost response for change user's profile
@@ -0,0 +1,85 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package archiver
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/sync"
+
+	"github.com/unknwon/com"
+)
+
+// archivePool prevents the same archive from being generated concurrently
+var archivePool = sync.NewExclusivePool()
+
+// ArchiveTypes are the supported archive types
+var ArchiveTypes = []git.ArchiveType{git.ZIP, git.TARGZ}
+
+// Extension returns the file extension of an archive type
+func Extension(archiveType git.ArchiveType) string {
+	return "." + archiveType.String()
+}
+
+// ArchivePath returns the path the archive of the commit is cached at
+func ArchivePath(repo *models.Repository, commitID string, archiveType git.ArchiveType) string {
+	dir := "zip"
+	if archiveType == git.TARGZ {
+		dir = "targz"
+	}
+	return filepath.Join(repo.RepoPath(), "archives", dir, base.ShortSha(commitID)+Extension(archiveType))
+}
+
+// CreateArchive generates the archive of the commit if it isn't cached yet and returns its path
+func CreateArchive(repo *models.Repository, commit *git.Commit, archiveType git.ArchiveType) (string, error) {
+	return generateArchive(ArchivePath(repo, commit.ID.String(), archiveType), func(target string) error {
+		return commit.CreateArchive(target, git.CreateArchiveOpts{
+			Format: archiveType,
+			Prefix: setting.Repository.PrefixArchiveFiles,
+		})
+	})
+}
+
+// generateArchive runs generate for the archive path if the archive isn't cached yet
+func generateArchive(archivePath string, generate func(target string) error) (string, error) {
+	if com.IsFile(archivePath) {
+		return archivePath, nil
+	}
+
+	archivePool.CheckIn(archivePath)
+	defer archivePool.CheckOut(archivePath)
+
+	// The archive might have been generated while waiting
+	if com.IsFile(archivePath) {
+		return archivePath, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(archivePath), os.ModePerm); err != nil {
+		return "", fmt.Errorf("MkdirAll: %v", err)
+	}
+
+	// Generate the archive under a temporary name, so an unfinished archive is never served
+	tmp, err := ioutil.TempFile(filepath.Dir(archivePath), filepath.Base(archivePath)+".tmp")
+	if err != nil {
+		return "", fmt.Errorf("TempFile: %v", err)
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	if err := generate(tmp.Name()); err != nil {
+		return "", fmt.Errorf("CreateArchive: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), archivePath); err != nil {
+		return "", fmt.Errorf("Rename: %v", err)
+	}
+	return archivePath, nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package archiver
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	models.MainTest(m, filepath.Join("..", ".."))
+}
+
+func TestCreateArchive(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+
+	commit, err := gitRepo.GetTagCommit("v1.1")
+	assert.NoError(t, err)
+
+	for _, archiveType := range ArchiveTypes {
+		expectedPath := ArchivePath(repo, commit.ID.String(), archiveType)
+		_ = os.Remove(expectedPath)
+
+		// concurrent requests of the same archive share one generation
+		var wg sync.WaitGroup
+		paths := make([]string, 3)
+		for i := range paths {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				var err error
+				paths[i], err = CreateArchive(repo, commit, archiveType)
+				assert.NoError(t, err)
+			}(i)
+		}
+		wg.Wait()
+
+		for _, p := range paths {
+			assert.Equal(t, expectedPath, p)
+		}
+		fi, err := os.Stat(expectedPath)
+		assert.NoError(t, err)
+		assert.NotZero(t, fi.Size())
+
+		// no temporary files are left behind
+		matches, err := filepath.Glob(expectedPath + ".tmp*")
+		assert.NoError(t, err)
+		assert.Empty(t, matches)
+	}
+}
+
+// commitWithSubmodule creates a commit in the repository which only contains a submodule at sub
+func commitWithSubmodule(t *testing.T, repoPath, url, refID string) string {
+	run := func(stdin string, args ...string) string {
+		stdout := new(strings.Builder)
+		stderr := new(strings.Builder)
+		err := git.NewCommand(args...).RunInDirTimeoutEnvFullPipeline([]string{
+			"GIT_AUTHOR_NAME=user2", "GIT_AUTHOR_EMAIL=user2@example.com",
+			"GIT_COMMITTER_NAME=user2", "GIT_COMMITTER_EMAIL=user2@example.com",
+		}, -1, repoPath, stdout, stderr, strings.NewReader(stdin))
+		assert.NoError(t, err, stderr.String())
+		return strings.TrimSpace(stdout.String())
+	}
+
+	blobID := run("[submodule \"sub\"]\n\tpath = sub\n\turl = "+url+"\n", "hash-object", "-w", "--stdin")
+	treeID := run("100644 blob "+blobID+"\t.gitmodules\n160000 commit "+refID+"\tsub\n", "mktree")
+	return run("", "commit-tree", treeID, "-m", "add submodule")
+}
+
+func readTarGzNames(t *testing.T, archivePath string) []string {
+	f, err := os.Open(archivePath)
+	assert.NoError(t, err)
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	assert.NoError(t, err)
+	tr := tar.NewReader(gr)
+
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return names
+		}
+		if !assert.NoError(t, err) {
+			return names
+		}
+		names = append(names, hdr.Name)
+	}
+}
+
+func TestCreateArchiveWithSubmodules(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	defer func(prefix bool) {
+		setting.Repository.PrefixArchiveFiles = prefix
+	}(setting.Repository.PrefixArchiveFiles)
+	setting.Repository.PrefixArchiveFiles = true
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+
+	// the private repo16 of user2 is pinned as submodule
+	commitID := commitWithSubmodule(t, repo.RepoPath(), "../repo16.git", "69554a64c1e6030f051e5c3f94bfbd773cd6a324")
+	commit, err := gitRepo.GetCommit(commitID)
+	assert.NoError(t, err)
+
+	user2 := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	archivePath, err := CreateArchiveWithSubmodules(user2, repo, commit)
+	assert.NoError(t, err)
+	assert.NotEqual(t, ArchivePath(repo, commitID, git.TARGZ), archivePath)
+	names := readTarGzNames(t, archivePath)
+	assert.Contains(t, names, "repo1/.gitmodules")
+	assert.Contains(t, names, "repo1/sub/readme.md")
+
+	// users who can't read the submodule get the plain archive
+	archivePath, err = CreateArchiveWithSubmodules(nil, repo, commit)
+	assert.NoError(t, err)
+	assert.Equal(t, ArchivePath(repo, commitID, git.TARGZ), archivePath)
+	names = readTarGzNames(t, archivePath)
+	assert.Contains(t, names, "repo1/.gitmodules")
+	assert.NotContains(t, names, "repo1/sub/readme.md")
+}
@@ -0,0 +1,138 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package archiver
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+)
+
+// BundleHead is a ref included in a bundle and the object it points to
+type BundleHead struct {
+	ObjectID string
+	RefName  string
+}
+
+// ErrBundleRefNotExist represents a ref requested in a bundle which isn't a branch or tag of the repository
+type ErrBundleRefNotExist struct {
+	RefName string
+}
+
+// IsErrBundleRefNotExist checks if an error is an ErrBundleRefNotExist
+func IsErrBundleRefNotExist(err error) bool {
+	_, ok := err.(ErrBundleRefNotExist)
+	return ok
+}
+
+func (err ErrBundleRefNotExist) Error() string {
+	return fmt.Sprintf("bundle ref does not exist [ref: %s]", err.RefName)
+}
+
+// ResolveBundleHeads returns the heads of the bundle of the branches and tags named by refNames,
+// which are either full ref names or branch and tag names. All the branches and tags are included
+// if refNames is empty.
+func ResolveBundleHeads(repoPath string, refNames []string) ([]BundleHead, error) {
+	stdout, err := git.NewCommand("for-each-ref", "--format=%(objectname) %(refname)", git.BranchPrefix, git.TagPrefix).RunInDir(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("for-each-ref: %v", err)
+	}
+	allHeads := parseBundleHeads(stdout)
+	if len(refNames) == 0 {
+		return allHeads, nil
+	}
+	objectIDs := make(map[string]string, len(allHeads))
+	for _, head := range allHeads {
+		objectIDs[head.RefName] = head.ObjectID
+	}
+
+	var heads []BundleHead
+	included := make(map[string]bool, len(refNames))
+	for _, name := range refNames {
+		var refName string
+		for _, candidate := range []string{name, git.BranchPrefix + name, git.TagPrefix + name} {
+			if _, ok := objectIDs[candidate]; ok {
+				refName = candidate
+				break
+			}
+		}
+		if refName == "" {
+			return nil, ErrBundleRefNotExist{RefName: name}
+		}
+		if !included[refName] {
+			included[refName] = true
+			heads = append(heads, BundleHead{ObjectID: objectIDs[refName], RefName: refName})
+		}
+	}
+	sort.Slice(heads, func(i, j int) bool {
+		return heads[i].RefName < heads[j].RefName
+	})
+	return heads, nil
+}
+
+// formatBundleHeads formats the heads as git bundle list-heads does
+func formatBundleHeads(heads []BundleHead) string {
+	var sb strings.Builder
+	for _, head := range heads {
+		sb.WriteString(head.ObjectID + " " + head.RefName + "\n")
+	}
+	return sb.String()
+}
+
+// parseBundleHeads parses heads formatted as git bundle list-heads does
+func parseBundleHeads(s string) []BundleHead {
+	var heads []BundleHead
+	for _, line := range strings.Split(s, "\n") {
+		if fields := strings.SplitN(line, " ", 2); len(fields) == 2 {
+			heads = append(heads, BundleHead{ObjectID: fields[0], RefName: fields[1]})
+		}
+	}
+	return heads
+}
+
+// BundlePath returns the path the bundle of the heads is cached at,
+// so it is generated again once any of the refs is updated
+func BundlePath(repo *models.Repository, heads []BundleHead) string {
+	sum := sha1.Sum([]byte(formatBundleHeads(heads)))
+	return filepath.Join(repo.RepoPath(), "archives", "bundle", hex.EncodeToString(sum[:])+".bundle")
+}
+
+// WriteBundle writes the bundle of the heads of the repository at repoPath to target.
+// It fails if any of the refs has been updated since the heads were resolved.
+func WriteBundle(repoPath string, heads []BundleHead, target string) error {
+	args := []string{"bundle", "create", target}
+	for _, head := range heads {
+		args = append(args, head.RefName)
+	}
+	if _, err := git.NewCommand(args...).RunInDirTimeout(-1, repoPath); err != nil {
+		return fmt.Errorf("bundle create: %v", err)
+	}
+
+	stdout, err := git.NewCommand("bundle", "list-heads", target).RunInDir(repoPath)
+	if err != nil {
+		return fmt.Errorf("bundle list-heads: %v", err)
+	}
+	bundled := parseBundleHeads(stdout)
+	sort.Slice(bundled, func(i, j int) bool {
+		return bundled[i].RefName < bundled[j].RefName
+	})
+	if formatBundleHeads(bundled) != formatBundleHeads(heads) {
+		return fmt.Errorf("the refs of the bundle have been updated while it was generated")
+	}
+	return nil
+}
+
+// CreateBundle generates the bundle of the heads if it isn't cached yet and returns its path
+func CreateBundle(repo *models.Repository, heads []BundleHead) (string, error) {
+	return generateArchive(BundlePath(repo, heads), func(target string) error {
+		return WriteBundle(repo.RepoPath(), heads, target)
+	})
+}
@@ -0,0 +1,112 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package archiver
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+)
+
+// BundleRequest represents a request for the bundle of refs of a repository, which is generated in the background
+type BundleRequest struct {
+	RepoID int64
+	// the heads of the bundle as formatted by formatBundleHeads, so the request can be compared
+	Heads string
+}
+
+// bundleQueue represents a queue of bundles to generate
+var bundleQueue queue.UniqueQueue
+
+func handleBundle(data ...queue.Data) {
+	for _, datum := range data {
+		req := datum.(BundleRequest)
+		bundlePath, err := generateRequestedBundle(req)
+		if err != nil {
+			log.Error("Unable to generate the bundle of repository %d: %v", req.RepoID, err)
+		}
+		if bundlePath != "" {
+			finishGeneration(bundlePath, err)
+		}
+	}
+}
+
+// generateRequestedBundle generates the requested bundle and returns its path
+func generateRequestedBundle(req BundleRequest) (string, error) {
+	repo, err := models.GetRepositoryByID(req.RepoID)
+	if err != nil {
+		return "", fmt.Errorf("GetRepositoryByID: %v", err)
+	}
+	heads := parseBundleHeads(req.Heads)
+	bundlePath := BundlePath(repo, heads)
+	_, err = CreateBundle(repo, heads)
+	return bundlePath, err
+}
+
+// InitBundleQueue runs the queue generating the bundles of repositories
+func InitBundleQueue() error {
+	bundleQueue = queue.CreateUniqueQueue("repo_bundle", handleBundle, BundleRequest{}).(queue.UniqueQueue)
+	if bundleQueue == nil {
+		return fmt.Errorf("Unable to create repo_bundle Queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(bundleQueue.Run)
+	return nil
+}
+
+// requestBundle returns the generation of the bundle, which is queued unless it is pending already
+func requestBundle(repo *models.Repository, heads []BundleHead) (*archiveGeneration, error) {
+	return requestGeneration(BundlePath(repo, heads), func() error {
+		return bundleQueue.Push(BundleRequest{
+			RepoID: repo.ID,
+			Heads:  formatBundleHeads(heads),
+		})
+	})
+}
+
+// RequestBundle returns the path of the bundle of the heads if it is generated already.
+// Otherwise its generation is queued and false is returned while it is pending.
+func RequestBundle(repo *models.Repository, heads []BundleHead) (string, bool, error) {
+	if bundlePath, ok := cachedPath(BundlePath(repo, heads)); ok {
+		return bundlePath, true, nil
+	}
+	if bundleQueue == nil {
+		return "", false, fmt.Errorf("the bundle queue isn't running")
+	}
+	if _, err := requestBundle(repo, heads); err != nil {
+		return "", false, err
+	}
+	return "", false, nil
+}
+
+// WaitForBundle returns the path of the bundle of the heads and waits for its generation
+// if it isn't generated yet, or until the context is done.
+func WaitForBundle(ctx context.Context, repo *models.Repository, heads []BundleHead) (string, error) {
+	bundlePath := BundlePath(repo, heads)
+	if _, ok := cachedPath(bundlePath); ok {
+		return bundlePath, nil
+	}
+	if bundleQueue == nil {
+		return CreateBundle(repo, heads)
+	}
+
+	generation, err := requestBundle(repo, heads)
+	if err != nil {
+		return "", err
+	}
+	select {
+	case <-generation.done:
+		if generation.err != nil {
+			return "", generation.err
+		}
+		return bundlePath, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package archiver
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveBundleHeads(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+
+	// All the branches and tags, but not the refs of pull requests
+	heads, err := ResolveBundleHeads(repo.RepoPath(), nil)
+	assert.NoError(t, err)
+	var refNames []string
+	for _, head := range heads {
+		refNames = append(refNames, head.RefName)
+	}
+	assert.Contains(t, refNames, "refs/heads/master")
+	assert.Contains(t, refNames, "refs/tags/v1.1")
+	for _, refName := range refNames {
+		assert.False(t, strings.HasPrefix(refName, "refs/pull/"), refName)
+	}
+
+	// Branches and tags are found by their names, only once
+	heads, err = ResolveBundleHeads(repo.RepoPath(), []string{"v1.1", "master", "refs/heads/master"})
+	assert.NoError(t, err)
+	assert.Equal(t, []BundleHead{
+		{ObjectID: "65f1bf27bc3bf70f64657658635e66094edbcb4d", RefName: "refs/heads/master"},
+		{ObjectID: "65f1bf27bc3bf70f64657658635e66094edbcb4d", RefName: "refs/tags/v1.1"},
+	}, heads)
+
+	_, err = ResolveBundleHeads(repo.RepoPath(), []string{"master", "not-a-branch"})
+	assert.EqualError(t, err, ErrBundleRefNotExist{RefName: "not-a-branch"}.Error())
+	assert.True(t, IsErrBundleRefNotExist(err))
+	_, err = ResolveBundleHeads(repo.RepoPath(), []string{"refs/pull/2/head"})
+	assert.True(t, IsErrBundleRefNotExist(err))
+}
+
+func TestCreateBundle(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+
+	heads, err := ResolveBundleHeads(repo.RepoPath(), []string{"master", "branch2"})
+	assert.NoError(t, err)
+	bundlePath, err := CreateBundle(repo, heads)
+	assert.NoError(t, err)
+	defer os.Remove(bundlePath)
+	assert.Equal(t, BundlePath(repo, heads), bundlePath)
+
+	// The bundle can be verified against an empty repository, it holds all the history of its refs
+	tmpDir, err := ioutil.TempDir("", "bundle")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, git.InitRepository(tmpDir, true, git.Sha1ObjectFormat))
+	_, err = git.NewCommand("bundle", "verify", bundlePath).RunInDir(tmpDir)
+	assert.NoError(t, err)
+	stdout, err := git.NewCommand("bundle", "list-heads", bundlePath).RunInDir(tmpDir)
+	assert.NoError(t, err)
+	assert.Equal(t, "985f0301dba5e7b34be866819cd15ad3d8f508ee refs/heads/branch2\n"+
+		"65f1bf27bc3bf70f64657658635e66094edbcb4d refs/heads/master\n", stdout)
+
+	// Another selection of refs is another bundle
+	heads, err = ResolveBundleHeads(repo.RepoPath(), []string{"master"})
+	assert.NoError(t, err)
+	assert.NotEqual(t, bundlePath, BundlePath(repo, heads))
+
+	// Bundles of refs which have been updated since they were resolved are refused
+	heads[0].ObjectID = "985f0301dba5e7b34be866819cd15ad3d8f508ee"
+	_, err = CreateBundle(repo, heads)
+	assert.Error(t, err)
+}
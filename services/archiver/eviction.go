@@ -0,0 +1,100 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package archiver
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+
+	"xorm.io/builder"
+)
+
+// cachedArchive is an archive file in the cache of a repository
+type cachedArchive struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// EvictRepositoryArchives deletes the least recently used archives of all repositories
+// until the archives take at most maxSize bytes
+func EvictRepositoryArchives(ctx context.Context, maxSize int64) error {
+	log.Trace("Doing: EvictRepositoryArchives")
+
+	var archives []*cachedArchive
+	var totalSize int64
+	if err := models.Iterate(
+		models.DefaultDBContext(),
+		new(models.Repository),
+		builder.Gt{"id": 0},
+		func(idx int, bean interface{}) error {
+			repo := bean.(*models.Repository)
+			select {
+			case <-ctx.Done():
+				return models.ErrCancelledf("before listing the archives of %s", repo.FullName())
+			default:
+			}
+
+			for _, dir := range []string{"zip", "targz", "bundle"} {
+				path := filepath.Join(repo.RepoPath(), "archives", dir)
+				files, err := ioutil.ReadDir(path)
+				if err != nil {
+					if os.IsNotExist(err) {
+						continue
+					}
+					log.Warn("Unable to read directory %s: %v", path, err)
+					return err
+				}
+				for _, info := range files {
+					// Archives being generated are skipped
+					if info.IsDir() || strings.Contains(info.Name(), ".tmp") {
+						continue
+					}
+					archives = append(archives, &cachedArchive{
+						path:    filepath.Join(path, info.Name()),
+						size:    info.Size(),
+						modTime: info.ModTime(),
+					})
+					totalSize += info.Size()
+				}
+			}
+			return nil
+		},
+	); err != nil {
+		log.Trace("Error: EvictRepositoryArchives: %v", err)
+		return err
+	}
+
+	// The modification time of an archive is updated whenever it is downloaded
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].modTime.Before(archives[j].modTime)
+	})
+	for _, archive := range archives {
+		if totalSize <= maxSize {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return models.ErrCancelledf("before evicting the archive %s", archive.path)
+		default:
+		}
+		if err := os.Remove(archive.path); err != nil && !os.IsNotExist(err) {
+			log.Warn("Unable to evict the archive %s: %v", archive.path, err)
+			continue
+		}
+		totalSize -= archive.size
+	}
+
+	log.Trace("Finished: EvictRepositoryArchives")
+	return nil
+}
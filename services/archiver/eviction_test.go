@@ -0,0 +1,54 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package archiver
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/unknwon/com"
+)
+
+func TestEvictRepositoryArchives(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	repo1 := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	repo2 := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 2}).(*models.Repository)
+	assert.NoError(t, models.DeleteRepositoryArchives(context.Background()))
+
+	writeArchive := func(repo *models.Repository, commitID string, archiveType git.ArchiveType, age time.Duration) string {
+		archivePath := ArchivePath(repo, commitID, archiveType)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(archivePath), os.ModePerm))
+		assert.NoError(t, ioutil.WriteFile(archivePath, make([]byte, 100), 0644))
+		modTime := time.Now().Add(-age)
+		assert.NoError(t, os.Chtimes(archivePath, modTime, modTime))
+		return archivePath
+	}
+	oldest := writeArchive(repo1, "1111111111111111111111111111111111111111", git.ZIP, 3*time.Hour)
+	older := writeArchive(repo2, "2222222222222222222222222222222222222222", git.TARGZ, 2*time.Hour)
+	recent := writeArchive(repo1, "3333333333333333333333333333333333333333", git.TARGZ, time.Hour)
+	// archives being generated are kept
+	pending := filepath.Join(filepath.Dir(oldest), "4444444444.zip.tmp123")
+	assert.NoError(t, ioutil.WriteFile(pending, make([]byte, 100), 0644))
+
+	assert.NoError(t, EvictRepositoryArchives(context.Background(), 300))
+	for _, p := range []string{oldest, older, recent, pending} {
+		assert.FileExists(t, p)
+	}
+
+	assert.NoError(t, EvictRepositoryArchives(context.Background(), 150))
+	assert.False(t, com.IsFile(oldest))
+	assert.False(t, com.IsFile(older))
+	assert.FileExists(t, recent)
+	assert.FileExists(t, pending)
+}
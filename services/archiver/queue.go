@@ -0,0 +1,190 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package archiver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+
+	"github.com/unknwon/com"
+)
+
+// ArchiveRequest represents a request for the archive of a commit, which is generated in the background
+type ArchiveRequest struct {
+	RepoID   int64
+	CommitID string
+	Type     git.ArchiveType
+}
+
+// archiveQueue represents a queue of archives to generate
+var archiveQueue queue.UniqueQueue
+
+// archiveGeneration is the pending generation of an archive, which can be waited for
+type archiveGeneration struct {
+	done chan struct{}
+	err  error
+}
+
+// pendingArchives are the archives being generated by their paths
+var pendingArchives = struct {
+	sync.Mutex
+	generations map[string]*archiveGeneration
+}{
+	generations: make(map[string]*archiveGeneration),
+}
+
+func handleArchive(data ...queue.Data) {
+	for _, datum := range data {
+		req := datum.(ArchiveRequest)
+		archivePath, err := generateRequestedArchive(req)
+		if err != nil {
+			log.Error("Unable to generate the archive of %s in repository %d: %v", req.CommitID, req.RepoID, err)
+		}
+
+		finishGeneration(archivePath, err)
+	}
+}
+
+// finishGeneration marks the pending generation of the archive at archivePath as done
+func finishGeneration(archivePath string, err error) {
+	pendingArchives.Lock()
+	if generation, ok := pendingArchives.generations[archivePath]; ok {
+		generation.err = err
+		close(generation.done)
+		delete(pendingArchives.generations, archivePath)
+	}
+	pendingArchives.Unlock()
+}
+
+// generateRequestedArchive generates the requested archive and returns its path
+func generateRequestedArchive(req ArchiveRequest) (string, error) {
+	repo, err := models.GetRepositoryByID(req.RepoID)
+	if err != nil {
+		return "", fmt.Errorf("GetRepositoryByID: %v", err)
+	}
+	archivePath := ArchivePath(repo, req.CommitID, req.Type)
+
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return archivePath, fmt.Errorf("OpenRepository: %v", err)
+	}
+	defer gitRepo.Close()
+
+	commit, err := gitRepo.GetCommit(req.CommitID)
+	if err != nil {
+		return archivePath, fmt.Errorf("GetCommit: %v", err)
+	}
+	_, err = CreateArchive(repo, commit, req.Type)
+	return archivePath, err
+}
+
+// InitArchiveQueue runs the queue generating the archives of repositories
+func InitArchiveQueue() error {
+	archiveQueue = queue.CreateUniqueQueue("repo_archiver", handleArchive, ArchiveRequest{}).(queue.UniqueQueue)
+	if archiveQueue == nil {
+		return fmt.Errorf("Unable to create repo_archiver Queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(archiveQueue.Run)
+	return nil
+}
+
+// requestArchive returns the generation of the archive, which is queued unless it is pending already
+func requestArchive(repo *models.Repository, commitID string, archiveType git.ArchiveType) (*archiveGeneration, error) {
+	return requestGeneration(ArchivePath(repo, commitID, archiveType), func() error {
+		return archiveQueue.Push(ArchiveRequest{
+			RepoID:   repo.ID,
+			CommitID: commitID,
+			Type:     archiveType,
+		})
+	})
+}
+
+// requestGeneration returns the generation of the archive at archivePath, which is queued by push
+// unless it is pending already
+func requestGeneration(archivePath string, push func() error) (*archiveGeneration, error) {
+	pendingArchives.Lock()
+	if generation, ok := pendingArchives.generations[archivePath]; ok {
+		pendingArchives.Unlock()
+		return generation, nil
+	}
+	// The generation is registered before it is queued, so it can't be handled before it is known
+	generation := &archiveGeneration{done: make(chan struct{})}
+	pendingArchives.generations[archivePath] = generation
+	pendingArchives.Unlock()
+
+	if err := push(); err != nil && err != queue.ErrAlreadyInQueue {
+		finishGeneration(archivePath, err)
+		return nil, err
+	}
+	return generation, nil
+}
+
+// cachedArchivePath returns the path of the archive if it is cached and marks it as used,
+// so the recently downloaded archives are the last ones evicted from the cache
+func cachedArchivePath(repo *models.Repository, commitID string, archiveType git.ArchiveType) (string, bool) {
+	return cachedPath(ArchivePath(repo, commitID, archiveType))
+}
+
+// cachedPath returns archivePath if the archive is cached and marks it as used
+func cachedPath(archivePath string) (string, bool) {
+	if !com.IsFile(archivePath) {
+		return "", false
+	}
+	now := time.Now()
+	if err := os.Chtimes(archivePath, now, now); err != nil {
+		log.Warn("Unable to mark the archive %s as used: %v", archivePath, err)
+	}
+	return archivePath, true
+}
+
+// RequestArchive returns the path of the archive of the commit if it is generated already.
+// Otherwise its generation is queued and false is returned while it is pending.
+func RequestArchive(repo *models.Repository, commitID string, archiveType git.ArchiveType) (string, bool, error) {
+	if archivePath, ok := cachedArchivePath(repo, commitID, archiveType); ok {
+		return archivePath, true, nil
+	}
+	if archiveQueue == nil {
+		return "", false, fmt.Errorf("the archive queue isn't running")
+	}
+	if _, err := requestArchive(repo, commitID, archiveType); err != nil {
+		return "", false, err
+	}
+	return "", false, nil
+}
+
+// WaitForArchive returns the path of the archive of the commit and waits for its generation
+// if it isn't generated yet, or until the context is done.
+func WaitForArchive(ctx context.Context, repo *models.Repository, commit *git.Commit, archiveType git.ArchiveType) (string, error) {
+	if archivePath, ok := cachedArchivePath(repo, commit.ID.String(), archiveType); ok {
+		return archivePath, nil
+	}
+	if archiveQueue == nil {
+		return CreateArchive(repo, commit, archiveType)
+	}
+
+	generation, err := requestArchive(repo, commit.ID.String(), archiveType)
+	if err != nil {
+		return "", err
+	}
+	select {
+	case <-generation.done:
+		if generation.err != nil {
+			return "", generation.err
+		}
+		return ArchivePath(repo, commit.ID.String(), archiveType), nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
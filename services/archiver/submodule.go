@@ -0,0 +1,167 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package archiver
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	submodule_service "code.gitea.io/gitea/services/submodule"
+)
+
+// maxSubmoduleDepth limits how deeply nested submodules are included in archives
+const maxSubmoduleDepth = 5
+
+// archiveSubmodule is a submodule whose content is included in an archive
+type archiveSubmodule struct {
+	prefix  string
+	repo    *models.Repository
+	gitRepo *git.Repository
+	commit  *git.Commit
+}
+
+// CreateArchiveWithSubmodules generates the tar.gz archive of the commit including the content
+// of its submodules at their pinned commits if it isn't cached yet and returns its path.
+// Only submodules hosted on this instance that the doer can read are included, the others
+// stay empty directories like in plain archives.
+func CreateArchiveWithSubmodules(doer *models.User, repo *models.Repository, commit *git.Commit) (string, error) {
+	var prefix string
+	if setting.Repository.PrefixArchiveFiles {
+		prefix = repo.LowerName + "/"
+	}
+
+	submodules, err := findArchiveSubmodules(doer, repo, commit, prefix, 1)
+	defer func() {
+		for _, submodule := range submodules {
+			submodule.gitRepo.Close()
+		}
+	}()
+	if err != nil {
+		return "", err
+	}
+	if len(submodules) == 0 {
+		return CreateArchive(repo, commit, git.TARGZ)
+	}
+
+	return generateArchive(submodulesArchivePath(repo, commit.ID.String(), submodules), func(target string) error {
+		return writeTarGzArchive(target, commit, prefix, submodules)
+	})
+}
+
+// submodulesArchivePath returns the path the archive of the commit with the given submodules is cached at,
+// which depends on the submodules included since they differ between users
+func submodulesArchivePath(repo *models.Repository, commitID string, submodules []*archiveSubmodule) string {
+	h := sha1.New()
+	for _, submodule := range submodules {
+		fmt.Fprintf(h, "%s %d %s\n", submodule.prefix, submodule.repo.ID, submodule.commit.ID)
+	}
+	name := base.ShortSha(commitID) + "-" + base.ShortSha(hex.EncodeToString(h.Sum(nil))) + Extension(git.TARGZ)
+	return filepath.Join(repo.RepoPath(), "archives", "targz", name)
+}
+
+// findArchiveSubmodules returns the submodules of the commit to include in its archive, including nested ones.
+// The git repositories of the returned submodules must be closed by the caller, even if an error is returned.
+func findArchiveSubmodules(doer *models.User, repo *models.Repository, commit *git.Commit, prefix string, depth int) ([]*archiveSubmodule, error) {
+	if depth > maxSubmoduleDepth {
+		return nil, nil
+	}
+
+	modules, err := commit.GetSubModules()
+	if err != nil {
+		return nil, fmt.Errorf("GetSubModules: %v", err)
+	} else if modules == nil {
+		return nil, nil
+	}
+	paths := modules.Keys()
+	sort.Strings(paths)
+
+	var submodules []*archiveSubmodule
+	for _, path := range paths {
+		entry, err := commit.GetTreeEntryByPath(path)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				continue
+			}
+			return submodules, fmt.Errorf("GetTreeEntryByPath: %v", err)
+		}
+		if !entry.IsSubModule() {
+			continue
+		}
+
+		module, _ := modules.Get(path)
+		refID := entry.ID.String()
+		subRepo, _, err := submodule_service.GetLocalRepository(doer, repo, module.(*git.SubModule).URL)
+		if err != nil {
+			return submodules, err
+		} else if subRepo == nil {
+			continue
+		}
+
+		gitRepo, err := git.OpenRepository(subRepo.RepoPath())
+		if err != nil {
+			return submodules, fmt.Errorf("OpenRepository: %v", err)
+		}
+		subCommit, err := gitRepo.GetCommit(refID)
+		if err != nil {
+			gitRepo.Close()
+			if git.IsErrNotExist(err) {
+				continue
+			}
+			return submodules, fmt.Errorf("GetCommit: %v", err)
+		}
+
+		submodule := &archiveSubmodule{
+			prefix:  prefix + path + "/",
+			repo:    subRepo,
+			gitRepo: gitRepo,
+			commit:  subCommit,
+		}
+		submodules = append(submodules, submodule)
+
+		nested, err := findArchiveSubmodules(doer, subRepo, subCommit, submodule.prefix, depth+1)
+		submodules = append(submodules, nested...)
+		if err != nil {
+			return submodules, err
+		}
+	}
+	return submodules, nil
+}
+
+// writeTarGzArchive writes the files of the commit and its submodules as tar.gz archive to target
+func writeTarGzArchive(target string, commit *git.Commit, prefix string, submodules []*archiveSubmodule) error {
+	f, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	if err := commit.WriteTarArchive(tw, prefix); err != nil {
+		return err
+	}
+	for _, submodule := range submodules {
+		if err := submodule.commit.WriteTarArchive(tw, submodule.prefix); err != nil {
+			return fmt.Errorf("WriteTarArchive[%s]: %v", submodule.repo.FullName(), err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
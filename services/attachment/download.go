@@ -0,0 +1,98 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package attachment
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// downloadCounter collects the downloads of attachments in memory
+// so they can be written to the database in batches.
+type downloadCounter struct {
+	lock   sync.Mutex
+	counts map[int64]int64
+	// write writes the counts to the database, all of them or none
+	write func(counts map[int64]int64) error
+}
+
+var counter = &downloadCounter{
+	counts: make(map[int64]int64),
+	write:  models.IncreaseAttachmentDownloadCounts,
+}
+
+func (c *downloadCounter) add(id int64) {
+	c.lock.Lock()
+	c.counts[id]++
+	c.lock.Unlock()
+}
+
+func (c *downloadCounter) flush() error {
+	c.lock.Lock()
+	counts := c.counts
+	c.counts = make(map[int64]int64, len(counts))
+	c.lock.Unlock()
+
+	if len(counts) == 0 {
+		return nil
+	}
+	if err := c.write(counts); err != nil {
+		// Keep the counts for the next flush, along the downloads recorded meanwhile
+		c.lock.Lock()
+		for id, count := range counts {
+			c.counts[id] += count
+		}
+		c.lock.Unlock()
+		return err
+	}
+	return nil
+}
+
+// InitDownloadCounter starts writing the collected download counts periodically
+func InitDownloadCounter() {
+	if setting.AttachmentDownloadCountFlushInterval <= 0 {
+		return
+	}
+	go graceful.GetManager().RunWithShutdownContext(runDownloadCounter)
+}
+
+func runDownloadCounter(ctx context.Context) {
+	ticker := time.NewTicker(setting.AttachmentDownloadCountFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := FlushDownloadCounts(); err != nil {
+				log.Error("FlushDownloadCounts: %v", err)
+			}
+			return
+		case <-ticker.C:
+			if err := FlushDownloadCounts(); err != nil {
+				log.Error("FlushDownloadCounts: %v", err)
+			}
+		}
+	}
+}
+
+// IncreaseDownloadCount records a download of the attachment, the count is written
+// to the database with the next flush unless buffering is disabled.
+func IncreaseDownloadCount(attach *models.Attachment) error {
+	if setting.AttachmentDownloadCountFlushInterval <= 0 {
+		return attach.IncreaseDownloadCount()
+	}
+	counter.add(attach.ID)
+	return nil
+}
+
+// FlushDownloadCounts writes all collected download counts to the database
+func FlushDownloadCounts() error {
+	return counter.flush()
+}
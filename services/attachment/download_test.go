@@ -0,0 +1,78 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package attachment
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	models.MainTest(m, filepath.Join("..", ".."))
+}
+
+func TestIncreaseDownloadCount(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	defer func(interval time.Duration) {
+		setting.AttachmentDownloadCountFlushInterval = interval
+	}(setting.AttachmentDownloadCountFlushInterval)
+	setting.AttachmentDownloadCountFlushInterval = time.Minute
+
+	attach := models.AssertExistsAndLoadBean(t, &models.Attachment{ID: 1}).(*models.Attachment)
+	assert.NoError(t, IncreaseDownloadCount(attach))
+	assert.NoError(t, IncreaseDownloadCount(attach))
+
+	// nothing is written before the flush
+	attach = models.AssertExistsAndLoadBean(t, &models.Attachment{ID: 1}).(*models.Attachment)
+	assert.EqualValues(t, 0, attach.DownloadCount)
+
+	assert.NoError(t, FlushDownloadCounts())
+	attach = models.AssertExistsAndLoadBean(t, &models.Attachment{ID: 1}).(*models.Attachment)
+	assert.EqualValues(t, 2, attach.DownloadCount)
+
+	// buffering disabled
+	setting.AttachmentDownloadCountFlushInterval = 0
+	assert.NoError(t, IncreaseDownloadCount(attach))
+	attach = models.AssertExistsAndLoadBean(t, &models.Attachment{ID: 1}).(*models.Attachment)
+	assert.EqualValues(t, 3, attach.DownloadCount)
+}
+
+func TestFlushDownloadCountsFailure(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	defer func(interval time.Duration) {
+		setting.AttachmentDownloadCountFlushInterval = interval
+	}(setting.AttachmentDownloadCountFlushInterval)
+	setting.AttachmentDownloadCountFlushInterval = time.Minute
+	defer func(write func(map[int64]int64) error) {
+		counter.write = write
+	}(counter.write)
+
+	attach := models.AssertExistsAndLoadBean(t, &models.Attachment{ID: 1}).(*models.Attachment)
+	assert.NoError(t, IncreaseDownloadCount(attach))
+
+	// the counts of a failed flush are kept along the downloads recorded meanwhile
+	write := counter.write
+	counter.write = func(counts map[int64]int64) error {
+		assert.NoError(t, IncreaseDownloadCount(attach))
+		return errors.New("database is unavailable")
+	}
+	assert.Error(t, FlushDownloadCounts())
+	attach = models.AssertExistsAndLoadBean(t, &models.Attachment{ID: 1}).(*models.Attachment)
+	assert.EqualValues(t, 0, attach.DownloadCount)
+
+	counter.write = write
+	assert.NoError(t, FlushDownloadCounts())
+	attach = models.AssertExistsAndLoadBean(t, &models.Attachment{ID: 1}).(*models.Attachment)
+	assert.EqualValues(t, 2, attach.DownloadCount)
+}
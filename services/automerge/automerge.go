@@ -0,0 +1,220 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package automerge
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/services/mergequeue"
+	pull_service "code.gitea.io/gitea/services/pull"
+)
+
+// autoMergeQueue represents a queue of pull requests scheduled to be merged which may be ready
+var autoMergeQueue queue.UniqueQueue
+
+func handle(data ...queue.Data) {
+	for _, datum := range data {
+		id := datum.(int64)
+		if err := handlePull(id); err != nil {
+			log.Error("handlePull[%d]: %v", id, err)
+		}
+	}
+}
+
+// Init runs the queue merging the pull requests scheduled to be merged once they are ready
+func Init() error {
+	autoMergeQueue = queue.CreateUniqueQueue("pr_auto_merge", handle, int64(0)).(queue.UniqueQueue)
+	if autoMergeQueue == nil {
+		return fmt.Errorf("Unable to create pr_auto_merge Queue")
+	}
+
+	notification.RegisterNotifier(NewNotifier())
+	go graceful.GetManager().RunWithShutdownFns(autoMergeQueue.Run)
+	return nil
+}
+
+// AddToQueue checks in the background whether the pull request is ready to be merged if it is scheduled
+func AddToQueue(pullID int64) {
+	if autoMergeQueue == nil {
+		return
+	}
+	if err := autoMergeQueue.Push(pullID); err != nil && err != queue.ErrAlreadyInQueue {
+		log.Error("Unable to add pull request %d to the auto merge queue: %v", pullID, err)
+	}
+}
+
+// ScheduleAutoMerge schedules the merge of the pull request with the style and the message on behalf
+// of the doer once its branch protection is satisfied. The caller must check the doer can merge it.
+func ScheduleAutoMerge(doer *models.User, pr *models.PullRequest, style models.MergeStyle, message string) error {
+	if err := pr.LoadBaseRepo(); err != nil {
+		return err
+	}
+	prUnit, err := pr.BaseRepo.GetUnit(models.UnitTypePullRequests)
+	if err != nil {
+		return err
+	}
+	if !prUnit.PullRequestsConfig().IsMergeStyleAllowed(style) {
+		return models.ErrInvalidMergeStyle{ID: pr.BaseRepo.ID, Style: style}
+	}
+
+	headCommitID, err := pull_service.GetHeadCommitID(pr)
+	if err != nil {
+		return err
+	} else if len(headCommitID) == 0 {
+		return models.ErrBranchDoesNotExist{BranchName: pr.HeadBranch}
+	}
+
+	if err := models.ScheduleAutoMerge(doer, pr, headCommitID, style, message); err != nil {
+		return err
+	}
+	notification.NotifyPullRequestAutoMergeScheduled(doer, pr)
+
+	// The pull request may have become ready in the meantime
+	AddToQueue(pr.ID)
+	return nil
+}
+
+// CancelScheduledAutoMerge cancels the scheduled merge of the pull request
+func CancelScheduledAutoMerge(doer *models.User, pr *models.PullRequest) error {
+	if err := models.CancelScheduledAutoMerge(doer, pr, ""); err != nil {
+		return err
+	}
+	notification.NotifyPullRequestAutoMergeCancelled(doer, pr)
+	return nil
+}
+
+// handlePull merges the pull request, or adds it to the merge queue of its base branch, if it is scheduled
+// to be merged and ready, it is kept scheduled otherwise
+func handlePull(pullID int64) error {
+	autoMerge, err := models.GetScheduledAutoMergeByPullID(pullID)
+	if err != nil {
+		return fmt.Errorf("GetScheduledAutoMergeByPullID: %v", err)
+	} else if autoMerge == nil {
+		return nil
+	}
+
+	pr, err := models.GetPullRequestByID(pullID)
+	if err != nil {
+		return fmt.Errorf("GetPullRequestByID: %v", err)
+	}
+	if err := pr.LoadIssue(); err != nil {
+		return fmt.Errorf("LoadIssue: %v", err)
+	}
+	if pr.HasMerged || pr.Issue.IsClosed {
+		return models.DeleteScheduledAutoMerge(pr.ID)
+	}
+	if cancelled, err := cancelIfHeadChanged(autoMerge, pr); err != nil || cancelled {
+		return err
+	}
+	if pr.IsWorkInProgress() || !pr.CanAutoMerge() {
+		return nil
+	}
+
+	if err := pr.LoadProtectedBranch(); err != nil {
+		return fmt.Errorf("LoadProtectedBranch: %v", err)
+	}
+	// The pull requests of branches with a merge queue are added to it instead of being merged,
+	// their status checks are checked by the queue
+	useMergeQueue := pr.ProtectedBranch != nil && pr.ProtectedBranch.EnableMergeQueue
+	checkPRReady := pull_service.CheckPRReadyToMerge
+	if useMergeQueue {
+		checkPRReady = pull_service.CheckPRReadyToEnterMergeQueue
+	}
+	if err := checkPRReady(pr); err != nil {
+		if models.IsErrNotAllowedToMerge(err) {
+			log.Trace("Pull request %d isn't ready to be merged yet: %v", pr.ID, err)
+			return nil
+		}
+		return fmt.Errorf("CheckPRReadyToMerge: %v", err)
+	}
+	if noDeps, err := models.IssueNoDependenciesLeft(pr.Issue); err != nil {
+		return fmt.Errorf("IssueNoDependenciesLeft: %v", err)
+	} else if !noDeps {
+		return nil
+	}
+
+	if err := autoMerge.LoadDoer(); err != nil {
+		return fmt.Errorf("LoadDoer: %v", err)
+	}
+	perm, err := models.GetUserRepoPermission(pr.BaseRepo, autoMerge.Doer)
+	if err != nil {
+		return fmt.Errorf("GetUserRepoPermission: %v", err)
+	}
+	if allowed, err := pull_service.IsUserAllowedToMerge(pr, perm, autoMerge.Doer); err != nil {
+		return fmt.Errorf("IsUserAllowedToMerge: %v", err)
+	} else if !allowed {
+		log.Warn("%s isn't allowed to merge the pull request %d anymore, it isn't merged", autoMerge.Doer.Name, pr.ID)
+		return nil
+	}
+	if _, err := pull_service.IsSignedIfRequired(pr, autoMerge.Doer); err != nil {
+		if models.IsErrWontSign(err) {
+			log.Warn("The merge of the pull request %d wouldn't be signed as required, it isn't merged", pr.ID)
+			return nil
+		}
+		return fmt.Errorf("IsSignedIfRequired: %v", err)
+	}
+
+	// The head branch may have changed while the pull request has been checked
+	if cancelled, err := cancelIfHeadChanged(autoMerge, pr); err != nil || cancelled {
+		return err
+	}
+
+	if useMergeQueue {
+		if _, err := mergequeue.Add(autoMerge.Doer, pr, autoMerge.MergeStyle, autoMerge.Message); err != nil && !models.IsErrPullAlreadyInMergeQueue(err) {
+			if models.IsErrInvalidMergeStyle(err) {
+				log.Warn("The merge style %s of the pull request %d isn't allowed anymore, it isn't added to the merge queue", autoMerge.MergeStyle, pr.ID)
+				return nil
+			}
+			return fmt.Errorf("mergequeue.Add: %v", err)
+		}
+		log.Trace("Pull request %d added to the merge queue on behalf of %s", pr.ID, autoMerge.Doer.Name)
+		return models.DeleteScheduledAutoMerge(pr.ID)
+	}
+
+	baseGitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+	defer baseGitRepo.Close()
+
+	if err := pull_service.Merge(pr, autoMerge.Doer, baseGitRepo, autoMerge.MergeStyle, autoMerge.Message); err != nil {
+		return fmt.Errorf("Merge: %v", err)
+	}
+	log.Trace("Pull request %d merged on behalf of %s", pr.ID, autoMerge.Doer.Name)
+	return nil
+}
+
+// cancelIfHeadChanged cancels the scheduled merge of the pull request if its head branch has changed
+// since the merge has been scheduled, it returns whether it has been cancelled
+func cancelIfHeadChanged(autoMerge *models.PullAutoMerge, pr *models.PullRequest) (bool, error) {
+	headCommitID, err := pull_service.GetHeadCommitID(pr)
+	if err != nil {
+		return false, fmt.Errorf("GetHeadCommitID: %v", err)
+	} else if headCommitID == autoMerge.HeadCommitID {
+		return false, nil
+	}
+
+	if err := autoMerge.LoadDoer(); err != nil {
+		if !models.IsErrUserNotExist(err) {
+			return false, fmt.Errorf("LoadDoer: %v", err)
+		}
+		autoMerge.Doer = models.NewGhostUser()
+	}
+	log.Trace("Scheduled merge of pull request %d cancelled: its head branch has changed", pr.ID)
+	if err := models.CancelScheduledAutoMerge(autoMerge.Doer, pr, models.AutoMergeCancelledHeadChanged); err != nil {
+		if models.IsErrPullAutoMergeNotExist(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	notification.NotifyPullRequestAutoMergeCancelled(autoMerge.Doer, pr)
+	return true, nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package automerge
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification/base"
+)
+
+type autoMergeNotifier struct {
+	base.NullNotifier
+}
+
+var (
+	_ base.Notifier = &autoMergeNotifier{}
+)
+
+// NewNotifier create a new autoMergeNotifier notifier checking the scheduled merges
+// of pull requests when they may have become ready or their head branch has changed
+func NewNotifier() base.Notifier {
+	return &autoMergeNotifier{}
+}
+
+func (n *autoMergeNotifier) NotifyPullRequestReview(pr *models.PullRequest, review *models.Review, comment *models.Comment) {
+	if review.Type == models.ReviewTypeApprove {
+		AddToQueue(pr.ID)
+	}
+}
+
+func (n *autoMergeNotifier) NotifyCreateCommitStatus(creator *models.User, repo *models.Repository, sha string, status *models.CommitStatus) {
+	pullIDs, err := models.GetScheduledAutoMergePullIDs(repo.ID)
+	if err != nil {
+		log.Error("GetScheduledAutoMergePullIDs[%d]: %v", repo.ID, err)
+		return
+	}
+	for _, pullID := range pullIDs {
+		AddToQueue(pullID)
+	}
+}
+
+func (n *autoMergeNotifier) NotifyPullRequestSynchronized(doer *models.User, pr *models.PullRequest) {
+	AddToQueue(pr.ID)
+}
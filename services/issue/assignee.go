@@ -65,7 +65,14 @@ func ReviewRequest(issue *models.Issue, doer *models.User, reviewer *models.User
 		return
 	}
 
-	if comment != nil {
+	if comment == nil {
+		return nil
+	}
+	// The reviewers of draft pull requests are notified once they are ready for review
+	if err = issue.LoadPullRequest(); err != nil {
+		return
+	}
+	if !issue.PullRequest.IsDraft {
 		notification.NotifyPullReviewRequest(doer, issue, reviewer, isAdd, comment)
 	}
 
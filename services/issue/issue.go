@@ -28,6 +28,37 @@ func NewIssue(repo *models.Repository, issue *models.Issue, labelIDs []int64, uu
 	return nil
 }
 
+// NewIssues creates the issues with their labels and assignees for repository in a single
+// transaction, the notifications are sent once all of them are created.
+func NewIssues(repo *models.Repository, opts []models.NewIssueOptions) error {
+	if err := models.NewIssues(repo, opts); err != nil {
+		return err
+	}
+
+	for _, opt := range opts {
+		notification.NotifyNewIssue(opt.Issue)
+
+		if len(opt.AssigneeIDs) == 0 {
+			continue
+		}
+		comments, err := models.FindComments(models.FindCommentsOptions{
+			IssueID: opt.Issue.ID,
+			Type:    models.CommentTypeAssignees,
+		})
+		if err != nil {
+			return err
+		}
+		for _, comment := range comments {
+			if err = comment.LoadAssigneeUser(); err != nil {
+				return err
+			}
+			notification.NotifyIssueChangeAssignee(opt.Issue.Poster, opt.Issue, comment.Assignee, false, comment)
+		}
+	}
+
+	return nil
+}
+
 // ChangeTitle changes the title of this issue, as the given user.
 func ChangeTitle(issue *models.Issue, doer *models.User, title string) (err error) {
 	oldTitle := issue.Title
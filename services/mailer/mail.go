@@ -33,6 +33,7 @@ const (
 	mailAuthRegisterNotify base.TplName = "auth/register_notify"
 
 	mailNotifyCollaborator base.TplName = "notify/collaborator"
+	mailNotifyRepoTransfer base.TplName = "notify/repo_transfer"
 
 	// There's no actual limit for subject in RFC 5322
 	mailMaxSubjectRunes = 256
@@ -164,6 +165,57 @@ func SendCollaboratorMail(u, doer *models.User, repo *models.Repository) {
 	SendAsync(msg)
 }
 
+// SendRepoTransferNotifyMail sends mail notification to the new owner of a repository whose
+// transfer is requested, to the owners of the new organization.
+func SendRepoTransferNotifyMail(doer, newOwner *models.User, repo *models.Repository) {
+	recipients := []*models.User{newOwner}
+	if newOwner.IsOrganization() {
+		team, err := newOwner.GetOwnerTeam()
+		if err != nil {
+			log.Error("GetOwnerTeam: %v", err)
+			return
+		}
+		if err = team.GetMembers(&models.SearchMembersOptions{}); err != nil {
+			log.Error("GetMembers: %v", err)
+			return
+		}
+		recipients = team.Members
+	}
+
+	tos := make([]string, 0, len(recipients))
+	for _, u := range recipients {
+		if u.IsMailable() {
+			tos = append(tos, u.Email)
+		}
+	}
+	if len(tos) == 0 {
+		return
+	}
+
+	repoName := repo.FullName()
+	subject := fmt.Sprintf("%s would like to transfer %s to %s", doer.DisplayName(), repoName, newOwner.Name)
+
+	data := map[string]interface{}{
+		"Subject":     subject,
+		"RepoName":    repoName,
+		"Doer":        doer.DisplayName(),
+		"Destination": newOwner.Name,
+		"Link":        repo.HTMLURL(),
+	}
+
+	var content bytes.Buffer
+
+	if err := bodyTemplates.ExecuteTemplate(&content, string(mailNotifyRepoTransfer), data); err != nil {
+		log.Error("Template: %v", err)
+		return
+	}
+
+	msg := NewMessage(tos, subject, content.String())
+	msg.Info = fmt.Sprintf("UID: %d, repository pending transfer notification", newOwner.ID)
+
+	SendAsync(msg)
+}
+
 func composeIssueCommentMessages(ctx *mailCommentContext, tos []string, fromMention bool, info string) []*Message {
 
 	var (
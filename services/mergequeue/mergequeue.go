@@ -0,0 +1,292 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mergequeue
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/sync"
+	pull_service "code.gitea.io/gitea/services/pull"
+)
+
+// branchQueue represents a queue of the branches whose merge queue has to be processed
+var branchQueue queue.UniqueQueue
+
+// mergeQueueWorkingPool makes sure the merge queue of a branch is processed once at a time
+var mergeQueueWorkingPool = sync.NewExclusivePool()
+
+func handle(data ...queue.Data) {
+	for _, datum := range data {
+		key := datum.(string)
+		fields := strings.SplitN(key, ":", 2)
+		repoID, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil || len(fields) != 2 {
+			log.Error("Invalid merge queue key %q", key)
+			continue
+		}
+		if err := handleBranch(repoID, fields[1]); err != nil {
+			log.Error("handleBranch[%s]: %v", key, err)
+		}
+	}
+}
+
+// Init runs the queue processing the merge queues of the branches
+func Init() error {
+	branchQueue = queue.CreateUniqueQueue("pr_merge_queue", handle, "").(queue.UniqueQueue)
+	if branchQueue == nil {
+		return fmt.Errorf("Unable to create pr_merge_queue Queue")
+	}
+
+	notification.RegisterNotifier(NewNotifier())
+	go graceful.GetManager().RunWithShutdownFns(branchQueue.Run)
+	return nil
+}
+
+// AddToQueue processes in the background the merge queue of the branch of the repository
+func AddToQueue(repoID int64, branch string) {
+	if branchQueue == nil {
+		return
+	}
+	// Branch names can't contain colons
+	key := fmt.Sprintf("%d:%s", repoID, branch)
+	if err := branchQueue.Push(key); err != nil && err != queue.ErrAlreadyInQueue {
+		log.Error("Unable to add the merge queue %s to the queue: %v", key, err)
+	}
+}
+
+// Add adds the pull request to the merge queue of its base branch to be merged with the style and the message
+// on behalf of the doer. The caller must check the doer can merge it and it can enter the merge queue.
+func Add(doer *models.User, pr *models.PullRequest, style models.MergeStyle, message string) (*models.MergeQueueEntry, error) {
+	if err := pr.LoadBaseRepo(); err != nil {
+		return nil, err
+	}
+	prUnit, err := pr.BaseRepo.GetUnit(models.UnitTypePullRequests)
+	if err != nil {
+		return nil, err
+	}
+	if !prUnit.PullRequestsConfig().IsMergeStyleAllowed(style) {
+		return nil, models.ErrInvalidMergeStyle{ID: pr.BaseRepo.ID, Style: style}
+	}
+
+	headCommitID, err := pull_service.GetHeadCommitID(pr)
+	if err != nil {
+		return nil, err
+	} else if len(headCommitID) == 0 {
+		return nil, models.ErrBranchDoesNotExist{BranchName: pr.HeadBranch}
+	}
+
+	entry, err := models.AddToMergeQueue(doer, pr, headCommitID, style, message)
+	if err != nil {
+		return nil, err
+	}
+	AddToQueue(pr.BaseRepoID, pr.BaseBranch)
+	return entry, nil
+}
+
+// Remove removes the pull request from the merge queue of its base branch on behalf of the doer
+func Remove(doer *models.User, pr *models.PullRequest) error {
+	entry, err := models.GetMergeQueueEntryByPullID(pr.ID)
+	if err != nil {
+		return err
+	} else if entry == nil {
+		return models.ErrPullNotInMergeQueue{PullID: pr.ID}
+	}
+
+	if err := models.RemoveFromMergeQueue(doer, pr, ""); err != nil {
+		return err
+	}
+	if err := pull_service.DeleteMergeQueueTestBranch(entry); err != nil {
+		log.Error("DeleteMergeQueueTestBranch[%d]: %v", pr.ID, err)
+	}
+
+	// The next pull request may have to be tested
+	AddToQueue(entry.BaseRepoID, entry.BaseBranch)
+	return nil
+}
+
+// handleBranch processes the merge queue of the branch until its first pull request has to wait for its status checks
+func handleBranch(repoID int64, branch string) error {
+	key := fmt.Sprintf("%d:%s", repoID, branch)
+	mergeQueueWorkingPool.CheckIn(key)
+	defer mergeQueueWorkingPool.CheckOut(key)
+
+	// The pull requests waiting behind the first one leave the queue as soon as their head branch changes too
+	entries, err := models.GetMergeQueue(repoID, branch)
+	if err != nil {
+		return fmt.Errorf("GetMergeQueue: %v", err)
+	}
+	for _, entry := range entries {
+		if _, err := removeIfHeadChanged(entry); err != nil {
+			return err
+		}
+	}
+
+	for {
+		entries, err := models.GetMergeQueue(repoID, branch)
+		if err != nil {
+			return fmt.Errorf("GetMergeQueue: %v", err)
+		} else if len(entries) == 0 {
+			return nil
+		}
+
+		if next, err := handleEntry(entries[0]); err != nil {
+			return err
+		} else if !next {
+			return nil
+		}
+	}
+}
+
+// handleEntry tests and merges the first pull request of a merge queue, it returns whether
+// the queue has to be processed again, like when the pull request has left the queue
+func handleEntry(entry *models.MergeQueueEntry) (bool, error) {
+	if err := entry.LoadPullRequest(); err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			return true, models.DeleteMergeQueueEntry(entry)
+		}
+		return false, fmt.Errorf("LoadPullRequest: %v", err)
+	}
+	pr := entry.Pull
+	if err := pr.LoadIssue(); err != nil {
+		return false, fmt.Errorf("LoadIssue: %v", err)
+	} else if err := pr.LoadBaseRepo(); err != nil {
+		return false, fmt.Errorf("LoadBaseRepo: %v", err)
+	}
+	if pr.HasMerged || pr.Issue.IsClosed {
+		if err := pull_service.DeleteMergeQueueTestBranch(entry); err != nil {
+			log.Error("DeleteMergeQueueTestBranch[%d]: %v", pr.ID, err)
+		}
+		return true, models.DeleteMergeQueueEntry(entry)
+	}
+
+	if err := entry.LoadDoer(); err != nil {
+		if !models.IsErrUserNotExist(err) {
+			return false, fmt.Errorf("LoadDoer: %v", err)
+		}
+		entry.Doer = models.NewGhostUser()
+		return true, removeEntry(entry, models.MergeQueueRemovalNotAllowed)
+	}
+	if pr.BaseBranch != entry.BaseBranch {
+		return true, removeEntry(entry, models.MergeQueueRemovalRetargeted)
+	}
+	if err := pr.LoadProtectedBranch(); err != nil {
+		return false, fmt.Errorf("LoadProtectedBranch: %v", err)
+	}
+	if pr.ProtectedBranch == nil || !pr.ProtectedBranch.EnableMergeQueue {
+		return true, removeEntry(entry, models.MergeQueueRemovalDisabled)
+	}
+	perm, err := models.GetUserRepoPermission(pr.BaseRepo, entry.Doer)
+	if err != nil {
+		return false, fmt.Errorf("GetUserRepoPermission: %v", err)
+	}
+	if allowed, err := pull_service.IsUserAllowedToMerge(pr, perm, entry.Doer); err != nil {
+		return false, fmt.Errorf("IsUserAllowedToMerge: %v", err)
+	} else if !allowed {
+		return true, removeEntry(entry, models.MergeQueueRemovalNotAllowed)
+	}
+	if removed, err := removeIfHeadChanged(entry); err != nil {
+		return false, err
+	} else if removed {
+		return true, nil
+	}
+
+	baseCommitID, err := git.GetFullCommitID(pr.BaseRepo.RepoPath(), git.BranchPrefix+pr.BaseBranch)
+	if err != nil {
+		return false, fmt.Errorf("GetFullCommitID(%s): %v", pr.BaseBranch, err)
+	}
+	if !entry.IsTesting() || entry.TestBaseCommitID != baseCommitID {
+		if entry.TestBranch, entry.TestBaseCommitID, entry.TestCommitID, err = pull_service.TestMergeInQueue(entry); err != nil {
+			if models.IsErrMergeConflicts(err) || models.IsErrRebaseConflicts(err) || models.IsErrMergeUnrelatedHistories(err) ||
+				models.IsErrMergeDivergingFastForwardOnly(err) {
+				return true, removeEntry(entry, models.MergeQueueRemovalConflicts)
+			}
+			return false, fmt.Errorf("TestMergeInQueue: %v", err)
+		}
+		if err := models.UpdateMergeQueueEntryTest(entry); err != nil {
+			return false, fmt.Errorf("UpdateMergeQueueEntryTest: %v", err)
+		}
+		log.Trace("Merge of pull request %d is tested with %s on %s", pr.ID, entry.TestCommitID, entry.TestBranch)
+	}
+
+	state, err := pull_service.GetMergeQueueCommitStatusState(pr)
+	if err != nil {
+		return false, fmt.Errorf("GetMergeQueueCommitStatusState: %v", err)
+	}
+	if state.IsPending() {
+		return false, nil
+	} else if !state.IsSuccess() {
+		return true, removeEntry(entry, models.MergeQueueRemovalChecksFailed)
+	}
+
+	if err := pull_service.LandMergeQueueTest(entry); err != nil {
+		if git.IsErrPushOutOfDate(err) {
+			// The base branch has moved on, the merge has to be tested again
+			entry.TestCommitID = ""
+			return true, models.UpdateMergeQueueEntryTest(entry)
+		} else if models.IsErrMergeQueueHeadChanged(err) {
+			return true, removeEntry(entry, models.MergeQueueRemovalHeadChanged)
+		} else if git.IsErrPushRejected(err) {
+			log.Warn("Merge of pull request %d tested by the merge queue has been rejected: %v", pr.ID, err)
+			return true, removeEntry(entry, models.MergeQueueRemovalNotAllowed)
+		}
+		return false, fmt.Errorf("LandMergeQueueTest: %v", err)
+	}
+	log.Trace("Pull request %d merged by the merge queue with %s", pr.ID, entry.TestCommitID)
+
+	if err := pull_service.DeleteMergeQueueTestBranch(entry); err != nil {
+		log.Error("DeleteMergeQueueTestBranch[%d]: %v", pr.ID, err)
+	}
+	return true, models.DeleteMergeQueueEntry(entry)
+}
+
+// removeIfHeadChanged removes the pull request of the entry from its merge queue if its head branch has changed
+// since it has been added to it, it returns whether it has been removed
+func removeIfHeadChanged(entry *models.MergeQueueEntry) (bool, error) {
+	if err := entry.LoadPullRequest(); err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("LoadPullRequest: %v", err)
+	}
+	pr := entry.Pull
+	if pr.HasMerged {
+		return false, nil
+	}
+
+	headCommitID, err := pull_service.GetHeadCommitID(pr)
+	if err != nil {
+		return false, fmt.Errorf("GetHeadCommitID: %v", err)
+	} else if headCommitID == entry.HeadCommitID {
+		return false, nil
+	}
+
+	if err := entry.LoadDoer(); err != nil {
+		if !models.IsErrUserNotExist(err) {
+			return false, fmt.Errorf("LoadDoer: %v", err)
+		}
+		entry.Doer = models.NewGhostUser()
+	}
+	return true, removeEntry(entry, models.MergeQueueRemovalHeadChanged)
+}
+
+// removeEntry removes the pull request of the entry from its merge queue on behalf of the doer who added it
+func removeEntry(entry *models.MergeQueueEntry, reason string) error {
+	log.Trace("Pull request %d removed from the merge queue of %s: %s", entry.PullID, entry.BaseBranch, reason)
+	if err := models.RemoveFromMergeQueue(entry.Doer, entry.Pull, reason); err != nil && !models.IsErrPullNotInMergeQueue(err) {
+		return err
+	}
+	if err := pull_service.DeleteMergeQueueTestBranch(entry); err != nil {
+		log.Error("DeleteMergeQueueTestBranch[%d]: %v", entry.PullID, err)
+	}
+	return nil
+}
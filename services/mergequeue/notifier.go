@@ -0,0 +1,75 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mergequeue
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification/base"
+	"code.gitea.io/gitea/modules/repository"
+)
+
+type mergeQueueNotifier struct {
+	base.NullNotifier
+}
+
+var (
+	_ base.Notifier = &mergeQueueNotifier{}
+)
+
+// NewNotifier create a new mergeQueueNotifier notifier processing the merge queues when the status checks
+// of the merges they test, their base branches or the head branches of their pull requests change
+func NewNotifier() base.Notifier {
+	return &mergeQueueNotifier{}
+}
+
+func (n *mergeQueueNotifier) NotifyCreateCommitStatus(creator *models.User, repo *models.Repository, sha string, status *models.CommitStatus) {
+	entries, err := models.GetMergeQueueEntriesByTestCommitID(repo.ID, sha)
+	if err != nil {
+		log.Error("GetMergeQueueEntriesByTestCommitID[%d, %s]: %v", repo.ID, sha, err)
+		return
+	}
+	for _, entry := range entries {
+		AddToQueue(entry.BaseRepoID, entry.BaseBranch)
+	}
+}
+
+func (n *mergeQueueNotifier) NotifyPushCommits(pusher *models.User, repo *models.Repository, refName, oldCommitID, newCommitID string, commits *repository.PushCommits) {
+	// The pushes to the head branches of the pull requests are handled by NotifyPullRequestSynchronized
+	if strings.HasPrefix(refName, git.BranchPrefix) {
+		AddToQueue(repo.ID, git.RefEndName(refName))
+	}
+}
+
+func (n *mergeQueueNotifier) NotifyPullRequestSynchronized(doer *models.User, pr *models.PullRequest) {
+	entry, err := models.GetMergeQueueEntryByPullID(pr.ID)
+	if err != nil {
+		log.Error("GetMergeQueueEntryByPullID[%d]: %v", pr.ID, err)
+		return
+	} else if entry != nil {
+		AddToQueue(entry.BaseRepoID, entry.BaseBranch)
+	}
+}
+
+func (n *mergeQueueNotifier) NotifyMergePullRequest(pr *models.PullRequest, doer *models.User) {
+	AddToQueue(pr.BaseRepoID, pr.BaseBranch)
+}
+
+func (n *mergeQueueNotifier) NotifyIssueChangeStatus(doer *models.User, issue *models.Issue, actionComment *models.Comment, isClosed bool) {
+	if isClosed && issue.IsPull {
+		if err := issue.LoadPullRequest(); err != nil {
+			log.Error("LoadPullRequest[%d]: %v", issue.ID, err)
+			return
+		}
+		AddToQueue(issue.PullRequest.BaseRepoID, issue.PullRequest.BaseBranch)
+	}
+}
+
+func (n *mergeQueueNotifier) NotifyPullRequestChangeTargetBranch(doer *models.User, pr *models.PullRequest, oldBranch string) {
+	AddToQueue(pr.BaseRepoID, oldBranch)
+}
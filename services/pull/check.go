@@ -202,25 +202,32 @@ func handle(data ...queue.Data) {
 		id := com.StrTo(prID).MustInt64()
 
 		log.Trace("Testing PR ID %d from the pull requests patch checking queue", id)
+		testPR(id)
+	}
+}
 
-		pr, err := models.GetPullRequestByID(id)
-		if err != nil {
-			log.Error("GetPullRequestByID[%s]: %v", prID, err)
-			continue
-		} else if pr.HasMerged {
-			continue
-		} else if manuallyMerged(pr) {
-			continue
-		} else if err = TestPatch(pr); err != nil {
-			log.Error("testPatch[%d]: %v", pr.ID, err)
-			pr.Status = models.PullRequestStatusError
-			if err := pr.UpdateCols("status"); err != nil {
-				log.Error("update pr [%d] status to PullRequestStatusError failed: %v", pr.ID, err)
-			}
-			continue
+func testPR(id int64) {
+	// A pull request being merged is checked once the merge is over
+	pullWorkingPool.CheckIn(fmt.Sprint(id))
+	defer pullWorkingPool.CheckOut(fmt.Sprint(id))
+
+	pr, err := models.GetPullRequestByID(id)
+	if err != nil {
+		log.Error("GetPullRequestByID[%d]: %v", id, err)
+		return
+	} else if pr.HasMerged {
+		return
+	} else if manuallyMerged(pr) {
+		return
+	} else if err = TestPatch(pr); err != nil {
+		log.Error("testPatch[%d]: %v", pr.ID, err)
+		pr.Status = models.PullRequestStatusError
+		if err := pr.UpdateCols("status"); err != nil {
+			log.Error("update pr [%d] status to PullRequestStatusError failed: %v", pr.ID, err)
 		}
-		checkAndUpdateStatus(pr)
+		return
 	}
+	checkAndUpdateStatus(pr)
 }
 
 // Init runs the task queue to test all the checking status pull requests
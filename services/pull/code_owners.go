@@ -0,0 +1,289 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/codeowners"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	issue_service "code.gitea.io/gitea/services/issue"
+)
+
+// GetCodeOwners returns the CODEOWNERS file of the branch of the repository, nil if it hasn't any
+func GetCodeOwners(gitRepo *git.Repository, branch string) (*codeowners.File, error) {
+	commit, err := gitRepo.GetBranchCommit(branch)
+	if err != nil {
+		return nil, fmt.Errorf("GetBranchCommit(%s): %v", branch, err)
+	}
+	for _, path := range codeowners.Paths {
+		blob, err := commit.GetBlobByPath(path)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("GetBlobByPath(%s): %v", path, err)
+		}
+		dataRc, err := blob.DataAsync()
+		if err != nil {
+			return nil, fmt.Errorf("DataAsync(%s): %v", path, err)
+		}
+		defer dataRc.Close()
+		content, err := ioutil.ReadAll(dataRc)
+		if err != nil {
+			return nil, fmt.Errorf("ReadAll(%s): %v", path, err)
+		}
+		return codeowners.Parse(string(content)), nil
+	}
+	return nil, nil
+}
+
+// getChangedFilesCodeOwners returns the owners of each of the owned files changed by the pull request,
+// as written in the CODEOWNERS file of its base branch, none if it hasn't any
+func getChangedFilesCodeOwners(pr *models.PullRequest) ([][]string, error) {
+	if err := pr.LoadBaseRepo(); err != nil {
+		return nil, fmt.Errorf("LoadBaseRepo: %v", err)
+	}
+
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return nil, fmt.Errorf("OpenRepository: %v", err)
+	}
+	defer gitRepo.Close()
+
+	file, err := GetCodeOwners(gitRepo, pr.BaseBranch)
+	if err != nil || file == nil {
+		return nil, err
+	}
+	changedFiles, err := gitRepo.GetFilesChangedBetween(git.BranchPrefix+pr.BaseBranch, pr.GetGitRefName())
+	if err != nil {
+		return nil, fmt.Errorf("GetFilesChangedBetween: %v", err)
+	}
+
+	owners := make([][]string, 0, len(changedFiles))
+	for _, path := range changedFiles {
+		if fileOwners := file.Owners(path); len(fileOwners) > 0 {
+			owners = append(owners, fileOwners)
+		}
+	}
+	return owners, nil
+}
+
+// resolveCodeOwner returns the user or the team of an owner of a CODEOWNERS file, none if it doesn't exist
+func resolveCodeOwner(owner string) (*models.User, *models.Team, error) {
+	if !strings.HasPrefix(owner, "@") {
+		user, err := models.GetUserByEmail(owner)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				return nil, nil, nil
+			}
+			return nil, nil, fmt.Errorf("GetUserByEmail: %v", err)
+		}
+		return user, nil, nil
+	}
+
+	fields := strings.SplitN(owner[1:], "/", 2)
+	user, err := models.GetUserByName(fields[0])
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("GetUserByName: %v", err)
+	}
+	if len(fields) == 1 {
+		if user.IsOrganization() {
+			return nil, nil, nil
+		}
+		return user, nil, nil
+	}
+
+	if !user.IsOrganization() {
+		return nil, nil, nil
+	}
+	team, err := models.GetTeam(user.ID, fields[1])
+	if err != nil {
+		if models.IsErrTeamNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("GetTeam: %v", err)
+	}
+	return nil, team, nil
+}
+
+// GetUnapprovedCodeOwners returns the owners of the files changed by the pull request, as written in the CODEOWNERS
+// file of its base branch, who still have to approve it: at least one owner of each changed file has to approve it.
+// The owners which aren't existing users or teams are ignored.
+func GetUnapprovedCodeOwners(pr *models.PullRequest) ([]string, error) {
+	if err := pr.LoadProtectedBranch(); err != nil {
+		return nil, fmt.Errorf("LoadProtectedBranch: %v", err)
+	}
+	filesOwners, err := getChangedFilesCodeOwners(pr)
+	if err != nil || len(filesOwners) == 0 {
+		return nil, err
+	}
+
+	reviews, err := models.GetReviewersByIssueID(pr.IssueID)
+	if err != nil {
+		return nil, fmt.Errorf("GetReviewersByIssueID: %v", err)
+	}
+	approvers := make([]int64, 0, len(reviews))
+	for _, review := range reviews {
+		if review.Type != models.ReviewTypeApprove {
+			continue
+		}
+		if review.Stale && pr.ProtectedBranch != nil && pr.ProtectedBranch.DismissStaleApprovals {
+			continue
+		}
+		approvers = append(approvers, review.ReviewerID)
+	}
+
+	checker := &codeOwnerChecker{
+		approvers: approvers,
+		approved:  make(map[string]bool),
+	}
+	unapproved := make([]string, 0, 5)
+	seen := make(map[string]bool)
+	for _, fileOwners := range filesOwners {
+		owners, err := checker.validOwners(fileOwners)
+		if err != nil {
+			return nil, err
+		}
+		if len(owners) == 0 {
+			continue
+		}
+		approved := false
+		for _, owner := range owners {
+			if checker.approved[owner] {
+				approved = true
+				break
+			}
+		}
+		if approved {
+			continue
+		}
+		for _, owner := range owners {
+			if !seen[owner] {
+				seen[owner] = true
+				unapproved = append(unapproved, owner)
+			}
+		}
+	}
+	return unapproved, nil
+}
+
+// codeOwnerChecker resolves the owners of a CODEOWNERS file and checks whether they approved a pull request
+type codeOwnerChecker struct {
+	approvers []int64
+	// approved is whether the resolved owners approved the pull request
+	approved map[string]bool
+}
+
+// validOwners returns the owners which are existing users or teams
+func (c *codeOwnerChecker) validOwners(owners []string) ([]string, error) {
+	valid := make([]string, 0, len(owners))
+	for _, owner := range owners {
+		if _, ok := c.approved[owner]; !ok {
+			user, team, err := resolveCodeOwner(owner)
+			if err != nil {
+				return nil, err
+			}
+			if user == nil && team == nil {
+				log.Trace("Unknown code owner %s ignored", owner)
+				continue
+			}
+			if c.approved[owner], err = c.hasApproved(user, team); err != nil {
+				return nil, err
+			}
+		}
+		valid = append(valid, owner)
+	}
+	return valid, nil
+}
+
+// hasApproved returns whether the user or a member of the team approved the pull request
+func (c *codeOwnerChecker) hasApproved(user *models.User, team *models.Team) (bool, error) {
+	if user != nil {
+		return base.Int64sContains(c.approvers, user.ID), nil
+	}
+	for _, approver := range c.approvers {
+		if isMember, err := models.IsTeamMember(team.OrgID, team.ID, approver); err != nil {
+			return false, fmt.Errorf("IsTeamMember: %v", err)
+		} else if isMember {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RequestCodeOwnerReviews requests on behalf of the doer the reviews of the owners of the files changed
+// by the pull request, the members of the teams are requested instead of the teams. The owners who have
+// already been requested or reviewed it, the doer, the poster and the users who can't read it are skipped.
+func RequestCodeOwnerReviews(pr *models.PullRequest, doer *models.User) error {
+	filesOwners, err := getChangedFilesCodeOwners(pr)
+	if err != nil || len(filesOwners) == 0 {
+		return err
+	}
+	if err := pr.LoadIssue(); err != nil {
+		return fmt.Errorf("LoadIssue: %v", err)
+	} else if err := pr.Issue.LoadRepo(); err != nil {
+		return fmt.Errorf("LoadRepo: %v", err)
+	}
+
+	reviewers := make([]*models.User, 0, 5)
+	seen := make(map[string]bool)
+	for _, fileOwners := range filesOwners {
+		for _, owner := range fileOwners {
+			if seen[owner] {
+				continue
+			}
+			seen[owner] = true
+
+			user, team, err := resolveCodeOwner(owner)
+			if err != nil {
+				return err
+			}
+			if user != nil {
+				reviewers = append(reviewers, user)
+			} else if team != nil {
+				members, err := models.GetTeamMembers(team.ID)
+				if err != nil {
+					return fmt.Errorf("GetTeamMembers: %v", err)
+				}
+				reviewers = append(reviewers, members...)
+			}
+		}
+	}
+
+	requested := make(map[int64]bool)
+	for _, reviewer := range reviewers {
+		if requested[reviewer.ID] || reviewer.ID == doer.ID || reviewer.ID == pr.Issue.PosterID {
+			continue
+		}
+		requested[reviewer.ID] = true
+
+		review, err := models.GetReviewerByIssueIDAndUserID(pr.IssueID, reviewer.ID)
+		if err != nil {
+			return fmt.Errorf("GetReviewerByIssueIDAndUserID: %v", err)
+		} else if review.ID > 0 {
+			continue
+		}
+		perm, err := models.GetUserRepoPermission(pr.BaseRepo, reviewer)
+		if err != nil {
+			return fmt.Errorf("GetUserRepoPermission: %v", err)
+		} else if !perm.CanAccessAny(models.AccessModeRead, models.UnitTypePullRequests) {
+			continue
+		}
+
+		if err := issue_service.ReviewRequest(pr.Issue, doer, reviewer, true); err != nil {
+			return fmt.Errorf("ReviewRequest: %v", err)
+		}
+	}
+	return nil
+}
@@ -86,6 +86,13 @@ func IsPullCommitStatusPass(pr *models.PullRequest) (bool, error) {
 	if pr.ProtectedBranch == nil || !pr.ProtectedBranch.EnableStatusCheck {
 		return true, nil
 	}
+	if pr.ProtectedBranch.EnableMergeQueue {
+		state, err := GetMergeQueueCommitStatusState(pr)
+		if err != nil {
+			return false, err
+		}
+		return state.IsSuccess(), nil
+	}
 
 	state, err := GetPullRequestCommitStatusState(pr)
 	if err != nil {
@@ -121,10 +128,42 @@ func GetPullRequestCommitStatusState(pr *models.PullRequest) (structs.CommitStat
 		return "", errors.Wrap(err, "LoadBaseRepo")
 	}
 
-	commitStatuses, err := models.GetLatestCommitStatus(pr.BaseRepo, sha, 0)
+	commitStatuses, err := models.GetLatestCommitStatus(pr.BaseRepo, sha, models.ListOptions{})
 	if err != nil {
 		return "", errors.Wrap(err, "GetLatestCommitStatus")
 	}
 
 	return MergeRequiredContextsCommitStatus(commitStatuses, pr.ProtectedBranch.StatusCheckContexts), nil
 }
+
+// GetMergeQueueCommitStatusState returns the commit status state of the merge of the pull request
+// tested by the merge queue of its base branch, which is pending until it is tested
+func GetMergeQueueCommitStatusState(pr *models.PullRequest) (structs.CommitStatusState, error) {
+	entry, err := models.GetMergeQueueEntryByPullID(pr.ID)
+	if err != nil {
+		return "", errors.Wrap(err, "GetMergeQueueEntryByPullID")
+	}
+	if entry == nil || !entry.IsTesting() {
+		return structs.CommitStatusPending, nil
+	}
+
+	if err := pr.LoadBaseRepo(); err != nil {
+		return "", errors.Wrap(err, "LoadBaseRepo")
+	}
+	if err := pr.LoadProtectedBranch(); err != nil {
+		return "", errors.Wrap(err, "LoadProtectedBranch")
+	}
+	var requiredContexts []string
+	if pr.ProtectedBranch != nil {
+		if !pr.ProtectedBranch.EnableStatusCheck {
+			return structs.CommitStatusSuccess, nil
+		}
+		requiredContexts = pr.ProtectedBranch.StatusCheckContexts
+	}
+
+	commitStatuses, err := models.GetLatestCommitStatus(pr.BaseRepo, entry.TestCommitID, models.ListOptions{})
+	if err != nil {
+		return "", errors.Wrap(err, "GetLatestCommitStatus")
+	}
+	return MergeRequiredContextsCommitStatus(commitStatuses, requiredContexts), nil
+}
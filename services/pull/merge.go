@@ -8,6 +8,7 @@ package pull
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -19,6 +20,7 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/references"
@@ -31,8 +33,10 @@ import (
 
 // Merge merges pull request to base repository.
 // Caller should check PR is ready to be merged (review and status checks)
-// FIXME: add repoWorkingPull make sure two merges does not happen at same time.
 func Merge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repository, mergeStyle models.MergeStyle, message string) (err error) {
+	pullWorkingPool.CheckIn(fmt.Sprint(pr.ID))
+	defer pullWorkingPool.CheckOut(fmt.Sprint(pr.ID))
+
 	if err = pr.LoadHeadRepo(); err != nil {
 		log.Error("LoadHeadRepo: %v", err)
 		return fmt.Errorf("LoadHeadRepo: %v", err)
@@ -57,11 +61,16 @@ func Merge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repositor
 		go AddTestPullRequestTask(doer, pr.BaseRepo.ID, pr.BaseBranch, false, "", "")
 	}()
 
-	pr.MergedCommitID, err = rawMerge(pr, doer, mergeStyle, message)
+	mergeCommitID, err := rawMerge(pr, doer, mergeStyle, message, pr.BaseBranch)
 	if err != nil {
 		return err
 	}
+	return setMerged(pr, doer, mergeCommitID)
+}
 
+// setMerged marks the pull request as merged by the doer with the merge commit which has been pushed into its base branch
+func setMerged(pr *models.PullRequest, doer *models.User, mergeCommitID string) (err error) {
+	pr.MergedCommitID = mergeCommitID
 	pr.MergedUnix = timeutil.TimeStampNow()
 	pr.Merger = doer
 	pr.MergerID = doer.ID
@@ -111,14 +120,21 @@ func Merge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repositor
 	return nil
 }
 
-// rawMerge perform the merge operation without changing any pull information in database
-func rawMerge(pr *models.PullRequest, doer *models.User, mergeStyle models.MergeStyle, message string) (string, error) {
+// rawMerge perform the merge operation without changing any pull information in database,
+// the merge is pushed into the target branch of the base repository
+func rawMerge(pr *models.PullRequest, doer *models.User, mergeStyle models.MergeStyle, message, targetBranch string) (string, error) {
 	binVersion, err := git.BinVersion()
 	if err != nil {
 		log.Error("git.BinVersion: %v", err)
 		return "", fmt.Errorf("Unable to get git version: %v", err)
 	}
 
+	if mergeCommitID, merged, err := mergeWithoutCheckout(pr, doer, mergeStyle, message, targetBranch); err != nil {
+		return "", err
+	} else if merged {
+		return mergeCommitID, nil
+	}
+
 	// Clone base repo.
 	tmpBasePath, err := createTemporaryRepo(pr)
 	if err != nil {
@@ -131,6 +147,11 @@ func rawMerge(pr *models.PullRequest, doer *models.User, mergeStyle models.Merge
 		}
 	}()
 
+	// The git commands of the merge share its timeout. It isn't canceled with the request,
+	// so the base branch can't be pushed without the pull request being marked as merged.
+	ctx, cancel := git.NewOperationContext(graceful.GetManager().HammerContext(), git.OperationMerge)
+	defer cancel()
+
 	baseBranch := "base"
 	trackingBranch := "tracking"
 	stagingBranch := "staging"
@@ -159,11 +180,11 @@ func rawMerge(pr *models.PullRequest, doer *models.User, mergeStyle models.Merge
 	var gitConfigCommand func() *git.Command
 	if version.Compare(binVersion, "1.8.0", ">=") {
 		gitConfigCommand = func() *git.Command {
-			return git.NewCommand("config", "--local")
+			return git.NewCommandContext(ctx, "config", "--local")
 		}
 	} else {
 		gitConfigCommand = func() *git.Command {
-			return git.NewCommand("config")
+			return git.NewCommandContext(ctx, "config")
 		}
 	}
 
@@ -204,7 +225,7 @@ func rawMerge(pr *models.PullRequest, doer *models.User, mergeStyle models.Merge
 	errbuf.Reset()
 
 	// Read base branch index
-	if err := git.NewCommand("read-tree", "HEAD").RunInDirPipeline(tmpBasePath, &outbuf, &errbuf); err != nil {
+	if err := git.NewCommandContext(ctx, "read-tree", "HEAD").RunInDirPipeline(tmpBasePath, &outbuf, &errbuf); err != nil {
 		log.Error("git read-tree HEAD: %v\n%s\n%s", err, outbuf.String(), errbuf.String())
 		return "", fmt.Errorf("Unable to read base branch in to the index: %v\n%s\n%s", err, outbuf.String(), errbuf.String())
 	}
@@ -238,21 +259,47 @@ func rawMerge(pr *models.PullRequest, doer *models.User, mergeStyle models.Merge
 	// Merge commits.
 	switch mergeStyle {
 	case models.MergeStyleMerge:
-		cmd := git.NewCommand("merge", "--no-ff", "--no-commit", trackingBranch)
+		cmd := git.NewCommandContext(ctx, "merge", "--no-ff", "--no-commit", trackingBranch)
 		if err := runMergeCommand(pr, mergeStyle, cmd, tmpBasePath); err != nil {
 			log.Error("Unable to merge tracking into base: %v", err)
 			return "", err
 		}
 
-		if err := commitAndSignNoAuthor(pr, message, signArg, tmpBasePath, env); err != nil {
+		if err := commitAndSignNoAuthor(ctx, pr, message, signArg, tmpBasePath, env); err != nil {
 			log.Error("Unable to make final commit: %v", err)
 			return "", err
 		}
+	case models.MergeStyleFastForwardOnly:
+		cmd := git.NewCommandContext(ctx, "merge", "--ff-only", trackingBranch)
+		if err := runMergeCommand(pr, mergeStyle, cmd, tmpBasePath); err != nil {
+			log.Error("Unable to fast-forward base to tracking: %v", err)
+			return "", err
+		}
+	case models.MergeStyleRebaseFastForward:
+		// Fast-forward when the head branch contains the base branch, so its commits are kept as they are,
+		// merge commits included, instead of being linearized by the rebase
+		if err := git.NewCommandContext(ctx, "merge-base", "--is-ancestor", baseBranch, trackingBranch).RunInDirPipeline(tmpBasePath, &outbuf, &errbuf); err == nil {
+			outbuf.Reset()
+			errbuf.Reset()
+			cmd := git.NewCommandContext(ctx, "merge", "--ff-only", trackingBranch)
+			if err := runMergeCommand(pr, mergeStyle, cmd, tmpBasePath); err != nil {
+				log.Error("Unable to fast-forward base to tracking: %v", err)
+				return "", err
+			}
+			break
+		} else if !strings.Contains(err.Error(), "exit status 1") {
+			// Errors are signaled by a non-zero status that is not 1
+			log.Error("git merge-base --is-ancestor [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
+			return "", fmt.Errorf("git merge-base --is-ancestor [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
+		}
+		outbuf.Reset()
+		errbuf.Reset()
+		fallthrough
 	case models.MergeStyleRebase:
 		fallthrough
 	case models.MergeStyleRebaseMerge:
 		// Checkout head branch
-		if err := git.NewCommand("checkout", "-b", stagingBranch, trackingBranch).RunInDirPipeline(tmpBasePath, &outbuf, &errbuf); err != nil {
+		if err := git.NewCommandContext(ctx, "checkout", "-b", stagingBranch, trackingBranch).RunInDirPipeline(tmpBasePath, &outbuf, &errbuf); err != nil {
 			log.Error("git checkout base prior to merge post staging rebase [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
 			return "", fmt.Errorf("git checkout base prior to merge post staging rebase  [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
 		}
@@ -260,7 +307,7 @@ func rawMerge(pr *models.PullRequest, doer *models.User, mergeStyle models.Merge
 		errbuf.Reset()
 
 		// Rebase before merging
-		if err := git.NewCommand("rebase", baseBranch).RunInDirPipeline(tmpBasePath, &outbuf, &errbuf); err != nil {
+		if err := git.NewCommandContext(ctx, "rebase", baseBranch).RunInDirPipeline(tmpBasePath, &outbuf, &errbuf); err != nil {
 			// Rebase will leave a REBASE_HEAD file in .git if there is a conflict
 			if _, statErr := os.Stat(filepath.Join(tmpBasePath, ".git", "REBASE_HEAD")); statErr == nil {
 				var commitSha string
@@ -303,18 +350,18 @@ func rawMerge(pr *models.PullRequest, doer *models.User, mergeStyle models.Merge
 		errbuf.Reset()
 
 		// Checkout base branch again
-		if err := git.NewCommand("checkout", baseBranch).RunInDirPipeline(tmpBasePath, &outbuf, &errbuf); err != nil {
+		if err := git.NewCommandContext(ctx, "checkout", baseBranch).RunInDirPipeline(tmpBasePath, &outbuf, &errbuf); err != nil {
 			log.Error("git checkout base prior to merge post staging rebase [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
 			return "", fmt.Errorf("git checkout base prior to merge post staging rebase  [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
 		}
 		outbuf.Reset()
 		errbuf.Reset()
 
-		cmd := git.NewCommand("merge")
-		if mergeStyle == models.MergeStyleRebase {
-			cmd.AddArguments("--ff-only")
-		} else {
+		cmd := git.NewCommandContext(ctx, "merge")
+		if mergeStyle == models.MergeStyleRebaseMerge {
 			cmd.AddArguments("--no-ff", "--no-commit")
+		} else {
+			cmd.AddArguments("--ff-only")
 		}
 		cmd.AddArguments(stagingBranch)
 
@@ -324,14 +371,14 @@ func rawMerge(pr *models.PullRequest, doer *models.User, mergeStyle models.Merge
 			return "", err
 		}
 		if mergeStyle == models.MergeStyleRebaseMerge {
-			if err := commitAndSignNoAuthor(pr, message, signArg, tmpBasePath, env); err != nil {
+			if err := commitAndSignNoAuthor(ctx, pr, message, signArg, tmpBasePath, env); err != nil {
 				log.Error("Unable to make final commit: %v", err)
 				return "", err
 			}
 		}
 	case models.MergeStyleSquash:
 		// Merge with squash
-		cmd := git.NewCommand("merge", "--squash", trackingBranch)
+		cmd := git.NewCommandContext(ctx, "merge", "--squash", trackingBranch)
 		if err := runMergeCommand(pr, mergeStyle, cmd, tmpBasePath); err != nil {
 			log.Error("Unable to merge --squash tracking into base: %v", err)
 			return "", err
@@ -343,12 +390,12 @@ func rawMerge(pr *models.PullRequest, doer *models.User, mergeStyle models.Merge
 		}
 		sig := pr.Issue.Poster.NewGitSig()
 		if signArg == "" {
-			if err := git.NewCommand("commit", fmt.Sprintf("--author='%s <%s>'", sig.Name, sig.Email), "-m", message).RunInDirTimeoutEnvPipeline(env, -1, tmpBasePath, &outbuf, &errbuf); err != nil {
+			if err := git.NewCommandContext(ctx, "commit", fmt.Sprintf("--author='%s <%s>'", sig.Name, sig.Email), "-m", message).RunInDirTimeoutEnvPipeline(env, -1, tmpBasePath, &outbuf, &errbuf); err != nil {
 				log.Error("git commit [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
 				return "", fmt.Errorf("git commit [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
 			}
 		} else {
-			if err := git.NewCommand("commit", signArg, fmt.Sprintf("--author='%s <%s>'", sig.Name, sig.Email), "-m", message).RunInDirTimeoutEnvPipeline(env, -1, tmpBasePath, &outbuf, &errbuf); err != nil {
+			if err := git.NewCommandContext(ctx, "commit", signArg, fmt.Sprintf("--author='%s <%s>'", sig.Name, sig.Email), "-m", message).RunInDirTimeoutEnvPipeline(env, -1, tmpBasePath, &outbuf, &errbuf); err != nil {
 				log.Error("git commit [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
 				return "", fmt.Errorf("git commit [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
 			}
@@ -382,29 +429,13 @@ func rawMerge(pr *models.PullRequest, doer *models.User, mergeStyle models.Merge
 		}
 	}
 
-	var headUser *models.User
-	err = pr.HeadRepo.GetOwner()
+	env, err = mergePushingEnvironment(pr, doer)
 	if err != nil {
-		if !models.IsErrUserNotExist(err) {
-			log.Error("Can't find user: %d for head repository - %v", pr.HeadRepo.OwnerID, err)
-			return "", err
-		}
-		log.Error("Can't find user: %d for head repository - defaulting to doer: %s - %v", pr.HeadRepo.OwnerID, doer.Name, err)
-		headUser = doer
-	} else {
-		headUser = pr.HeadRepo.Owner
+		return "", err
 	}
 
-	env = models.FullPushingEnvironment(
-		headUser,
-		doer,
-		pr.BaseRepo,
-		pr.BaseRepo.Name,
-		pr.ID,
-	)
-
 	// Push back to upstream.
-	if err := git.NewCommand("push", "origin", baseBranch+":"+pr.BaseBranch).RunInDirTimeoutEnvPipeline(env, -1, tmpBasePath, &outbuf, &errbuf); err != nil {
+	if err := git.NewCommandContext(ctx, "push", "origin", baseBranch+":"+targetBranch).RunInDirTimeoutEnvPipeline(env, -1, tmpBasePath, &outbuf, &errbuf); err != nil {
 		if strings.Contains(errbuf.String(), "non-fast-forward") {
 			return "", &git.ErrPushOutOfDate{
 				StdOut: outbuf.String(),
@@ -428,15 +459,39 @@ func rawMerge(pr *models.PullRequest, doer *models.User, mergeStyle models.Merge
 	return mergeCommitID, nil
 }
 
-func commitAndSignNoAuthor(pr *models.PullRequest, message, signArg, tmpBasePath string, env []string) error {
+// mergePushingEnvironment returns the environment to push the merge of the pull request into its base branch with
+func mergePushingEnvironment(pr *models.PullRequest, doer *models.User) ([]string, error) {
+	var headUser *models.User
+	err := pr.HeadRepo.GetOwner()
+	if err != nil {
+		if !models.IsErrUserNotExist(err) {
+			log.Error("Can't find user: %d for head repository - %v", pr.HeadRepo.OwnerID, err)
+			return nil, err
+		}
+		log.Error("Can't find user: %d for head repository - defaulting to doer: %s - %v", pr.HeadRepo.OwnerID, doer.Name, err)
+		headUser = doer
+	} else {
+		headUser = pr.HeadRepo.Owner
+	}
+
+	return models.FullPushingEnvironment(
+		headUser,
+		doer,
+		pr.BaseRepo,
+		pr.BaseRepo.Name,
+		pr.ID,
+	), nil
+}
+
+func commitAndSignNoAuthor(ctx context.Context, pr *models.PullRequest, message, signArg, tmpBasePath string, env []string) error {
 	var outbuf, errbuf strings.Builder
 	if signArg == "" {
-		if err := git.NewCommand("commit", "-m", message).RunInDirTimeoutEnvPipeline(env, -1, tmpBasePath, &outbuf, &errbuf); err != nil {
+		if err := git.NewCommandContext(ctx, "commit", "-m", message).RunInDirTimeoutEnvPipeline(env, -1, tmpBasePath, &outbuf, &errbuf); err != nil {
 			log.Error("git commit [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
 			return fmt.Errorf("git commit [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
 		}
 	} else {
-		if err := git.NewCommand("commit", signArg, "-m", message).RunInDirTimeoutEnvPipeline(env, -1, tmpBasePath, &outbuf, &errbuf); err != nil {
+		if err := git.NewCommandContext(ctx, "commit", signArg, "-m", message).RunInDirTimeoutEnvPipeline(env, -1, tmpBasePath, &outbuf, &errbuf); err != nil {
 			log.Error("git commit [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
 			return fmt.Errorf("git commit [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
 		}
@@ -465,6 +520,13 @@ func runMergeCommand(pr *models.PullRequest, mergeStyle models.MergeStyle, cmd *
 				StdErr: errbuf.String(),
 				Err:    err,
 			}
+		} else if mergeStyle == models.MergeStyleFastForwardOnly && strings.Contains(errbuf.String(), "Not possible to fast-forward") {
+			log.Debug("MergeDivergingFastForwardOnly [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
+			return models.ErrMergeDivergingFastForwardOnly{
+				StdOut: outbuf.String(),
+				StdErr: errbuf.String(),
+				Err:    err,
+			}
 		}
 		log.Error("git merge [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
 		return fmt.Errorf("git merge [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
@@ -551,8 +613,19 @@ func IsUserAllowedToMerge(pr *models.PullRequest, p models.Permission, user *mod
 	return false, nil
 }
 
-// CheckPRReadyToMerge checks whether the PR is ready to be merged (reviews and status checks)
+// CheckPRReadyToMerge checks whether the PR is ready to be merged (reviews and status checks).
+// The status checks of the pull requests of branches with a merge queue are the ones of their merge tested by the queue.
 func CheckPRReadyToMerge(pr *models.PullRequest) (err error) {
+	return checkPRReadyToMerge(pr, true)
+}
+
+// CheckPRReadyToEnterMergeQueue checks whether the PR can be added to the merge queue of its base branch (reviews),
+// its status checks are checked by the queue
+func CheckPRReadyToEnterMergeQueue(pr *models.PullRequest) error {
+	return checkPRReadyToMerge(pr, false)
+}
+
+func checkPRReadyToMerge(pr *models.PullRequest, checkStatus bool) (err error) {
 	if err = pr.LoadBaseRepo(); err != nil {
 		return fmt.Errorf("LoadBaseRepo: %v", err)
 	}
@@ -564,13 +637,15 @@ func CheckPRReadyToMerge(pr *models.PullRequest) (err error) {
 		return nil
 	}
 
-	isPass, err := IsPullCommitStatusPass(pr)
-	if err != nil {
-		return err
-	}
-	if !isPass {
-		return models.ErrNotAllowedToMerge{
-			Reason: "Not all required status checks successful",
+	if checkStatus {
+		isPass, err := IsPullCommitStatusPass(pr)
+		if err != nil {
+			return err
+		}
+		if !isPass {
+			return models.ErrNotAllowedToMerge{
+				Reason: "Not all required status checks successful",
+			}
 		}
 	}
 
@@ -579,6 +654,17 @@ func CheckPRReadyToMerge(pr *models.PullRequest) (err error) {
 			Reason: "Does not have enough approvals",
 		}
 	}
+	if pr.ProtectedBranch.RequireCodeOwnerReviews {
+		owners, err := GetUnapprovedCodeOwners(pr)
+		if err != nil {
+			return fmt.Errorf("GetUnapprovedCodeOwners: %v", err)
+		}
+		if len(owners) > 0 {
+			return models.ErrNotAllowedToMerge{
+				Reason: "Does not have the approvals of the code owners " + strings.Join(owners, ", "),
+			}
+		}
+	}
 	if pr.ProtectedBranch.MergeBlockedByRejectedReview(pr) {
 		return models.ErrNotAllowedToMerge{
 			Reason: "There are requested changes",
@@ -0,0 +1,93 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// SquashMessagePlaceholders are the placeholders which can be used in the squash message templates of repositories
+var SquashMessagePlaceholders = []string{
+	"PullRequestTitle",
+	"PullRequestIndex",
+	"PullRequestReference",
+	"PullRequestDescription",
+	"PullRequestURL",
+	"BaseBranch",
+	"HeadBranch",
+	"CommitMessages",
+	"CoAuthors",
+	"Approvers",
+}
+
+// GetSquashMergeMessage returns the title and the body of the message to squash the pull request with,
+// expanded from the squash message template of its base repository if it has one
+func GetSquashMergeMessage(pr *models.PullRequest) (title, body string) {
+	if err := pr.LoadBaseRepo(); err != nil {
+		log.Error("LoadBaseRepo: %v", err)
+		return "", ""
+	}
+	prUnit, err := pr.BaseRepo.GetUnit(models.UnitTypePullRequests)
+	if err != nil {
+		log.Error("GetUnit: %v", err)
+		return "", ""
+	}
+	template := prUnit.PullRequestsConfig().SquashMessageTemplate
+	if len(strings.TrimSpace(template)) == 0 {
+		return pr.GetDefaultSquashMessage(), ""
+	}
+
+	if err := pr.LoadIssue(); err != nil {
+		log.Error("LoadIssue: %v", err)
+		return "", ""
+	}
+	reference := fmt.Sprintf("#%d", pr.Issue.Index)
+	if pr.BaseRepo.UnitEnabled(models.UnitTypeExternalTracker) {
+		reference = fmt.Sprintf("!%d", pr.Issue.Index)
+	}
+	values := map[string]string{
+		"PullRequestTitle":       pr.Issue.Title,
+		"PullRequestIndex":       fmt.Sprint(pr.Issue.Index),
+		"PullRequestReference":   reference,
+		"PullRequestDescription": pr.Issue.Content,
+		"PullRequestURL":         pr.Issue.HTMLURL(),
+		"BaseBranch":             pr.BaseBranch,
+		"HeadBranch":             pr.HeadBranch,
+	}
+	// Listing the commits of the pull request is only worth it if the template uses them
+	if strings.Contains(template, "${CommitMessages}") || strings.Contains(template, "${CoAuthors}") {
+		messages, authors := getCommitMessagesAndAuthors(pr)
+		coAuthors := make([]string, 0, len(authors))
+		for _, author := range authors {
+			coAuthors = append(coAuthors, "Co-authored-by: "+author)
+		}
+		values["CommitMessages"] = strings.TrimSpace(messages)
+		values["CoAuthors"] = strings.Join(coAuthors, "\n")
+	}
+	if strings.Contains(template, "${Approvers}") {
+		values["Approvers"] = strings.TrimSpace(pr.GetApprovers())
+	}
+
+	return expandSquashMessageTemplate(template, values)
+}
+
+// expandSquashMessageTemplate replaces the placeholders of the template with their values,
+// the first line of the message is its title and the remaining lines its body
+func expandSquashMessageTemplate(template string, values map[string]string) (title, body string) {
+	oldnew := make([]string, 0, 2*len(SquashMessagePlaceholders))
+	for _, placeholder := range SquashMessagePlaceholders {
+		oldnew = append(oldnew, "${"+placeholder+"}", values[placeholder])
+	}
+	message := strings.NewReplacer(oldnew...).Replace(template)
+	message = strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n"))
+	if i := strings.IndexByte(message, '\n'); i >= 0 {
+		return strings.TrimSpace(message[:i]), strings.TrimSpace(message[i+1:])
+	}
+	return message, ""
+}
@@ -0,0 +1,40 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandSquashMessageTemplate(t *testing.T) {
+	values := map[string]string{
+		"PullRequestTitle":       "Add a feature",
+		"PullRequestReference":   "#3",
+		"PullRequestDescription": "The feature is\r\ndescribed here",
+		"CoAuthors":              "Co-authored-by: user1 <user1@example.com>\nCo-authored-by: user4 <user4@example.com>",
+	}
+
+	for _, kase := range []struct {
+		template string
+		title    string
+		body     string
+	}{
+		{"${PullRequestTitle} (${PullRequestReference})", "Add a feature (#3)", ""},
+		{
+			"${PullRequestTitle} (${PullRequestReference})\r\n\r\n${PullRequestDescription}\r\n\r\n${CoAuthors}\r\n",
+			"Add a feature (#3)",
+			"The feature is\ndescribed here\n\nCo-authored-by: user1 <user1@example.com>\nCo-authored-by: user4 <user4@example.com>",
+		},
+		// Unknown placeholders are left as they are and the placeholders without a value are removed
+		{"${Unknown} ${HeadBranch}fix", "${Unknown} fix", ""},
+		{"\n  ${PullRequestTitle}  \nbody $PullRequestTitle", "Add a feature", "body $PullRequestTitle"},
+	} {
+		title, body := expandSquashMessageTemplate(kase.template, values)
+		assert.Equal(t, kase.title, title, kase.template)
+		assert.Equal(t, kase.body, body, kase.template)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// MergeQueueTestBranch returns the branch of the base repository the merge of the pull request is tested on
+// by the merge queue of its base branch
+func MergeQueueTestBranch(pr *models.PullRequest) string {
+	return fmt.Sprintf("merge-queue/pr-%d", pr.Index)
+}
+
+// GetHeadCommitID returns the latest commit of the head branch of the pull request,
+// empty if its head repository or branch doesn't exist anymore
+func GetHeadCommitID(pr *models.PullRequest) (string, error) {
+	if err := pr.LoadHeadRepo(); err != nil {
+		return "", fmt.Errorf("LoadHeadRepo: %v", err)
+	} else if pr.HeadRepo == nil {
+		return "", nil
+	}
+
+	headRepoPath := pr.HeadRepo.RepoPath()
+	if !git.IsBranchExist(headRepoPath, pr.HeadBranch) {
+		return "", nil
+	}
+	headCommitID, err := git.GetFullCommitID(headRepoPath, git.BranchPrefix+pr.HeadBranch)
+	if err != nil {
+		return "", fmt.Errorf("GetFullCommitID(%s): %v", pr.HeadBranch, err)
+	}
+	return headCommitID, nil
+}
+
+// TestMergeInQueue pushes the merge of the pull request into the latest commit of its base branch to its test branch,
+// so its status checks run on it. It returns the test branch, the commit of the base branch and the merge.
+func TestMergeInQueue(entry *models.MergeQueueEntry) (testBranch, baseCommitID, testCommitID string, err error) {
+	pr := entry.Pull
+	if err = pr.LoadHeadRepo(); err != nil {
+		return "", "", "", fmt.Errorf("LoadHeadRepo: %v", err)
+	} else if err = pr.LoadBaseRepo(); err != nil {
+		return "", "", "", fmt.Errorf("LoadBaseRepo: %v", err)
+	} else if err = pr.LoadIssue(); err != nil {
+		return "", "", "", fmt.Errorf("LoadIssue: %v", err)
+	}
+
+	baseGitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return "", "", "", fmt.Errorf("OpenRepository: %v", err)
+	}
+	defer baseGitRepo.Close()
+
+	baseCommitID, err = baseGitRepo.GetBranchCommitID(pr.BaseBranch)
+	if err != nil {
+		return "", "", "", fmt.Errorf("GetBranchCommitID(%s): %v", pr.BaseBranch, err)
+	}
+
+	// The merge of the previous test may not be based on the latest commit of the base branch anymore
+	testBranch = MergeQueueTestBranch(pr)
+	if baseGitRepo.IsBranchExist(testBranch) {
+		if err = baseGitRepo.DeleteBranch(testBranch, git.DeleteBranchOptions{Force: true}); err != nil {
+			return "", "", "", fmt.Errorf("DeleteBranch(%s): %v", testBranch, err)
+		}
+	}
+
+	testCommitID, err = rawMerge(pr, entry.Doer, entry.MergeStyle, entry.Message, testBranch)
+	if err != nil {
+		return "", "", "", err
+	}
+	return testBranch, baseCommitID, testCommitID, nil
+}
+
+// LandMergeQueueTest pushes the merge of the pull request tested by the merge queue into its base branch
+// and marks the pull request as merged. It fails with git.ErrPushOutOfDate if the base branch has moved on
+// since the merge has been made and with models.ErrMergeQueueHeadChanged if the head branch has.
+func LandMergeQueueTest(entry *models.MergeQueueEntry) error {
+	pr := entry.Pull
+	pullWorkingPool.CheckIn(fmt.Sprint(pr.ID))
+	defer pullWorkingPool.CheckOut(fmt.Sprint(pr.ID))
+
+	if err := pr.LoadHeadRepo(); err != nil {
+		return fmt.Errorf("LoadHeadRepo: %v", err)
+	} else if err := pr.LoadBaseRepo(); err != nil {
+		return fmt.Errorf("LoadBaseRepo: %v", err)
+	}
+
+	// The tested merge mustn't land the commits pushed to the head branch after the pull request has been added
+	headCommitID, err := GetHeadCommitID(pr)
+	if err != nil {
+		return err
+	} else if headCommitID != entry.HeadCommitID {
+		return models.ErrMergeQueueHeadChanged{PullID: pr.ID, HeadCommitID: headCommitID}
+	}
+
+	env, err := mergePushingEnvironment(pr, entry.Doer)
+	if err != nil {
+		return err
+	}
+	repoPath := pr.BaseRepo.RepoPath()
+	if err := git.Push(repoPath, git.PushOptions{
+		Remote: repoPath,
+		Branch: entry.TestCommitID + ":" + git.BranchPrefix + pr.BaseBranch,
+		Env:    env,
+	}); err != nil {
+		return err
+	}
+
+	defer func() {
+		go AddTestPullRequestTask(entry.Doer, pr.BaseRepo.ID, pr.BaseBranch, false, "", "")
+	}()
+
+	return setMerged(pr, entry.Doer, entry.TestCommitID)
+}
+
+// DeleteMergeQueueTestBranch deletes the branch the merge of the pull request has been tested on if it exists
+func DeleteMergeQueueTestBranch(entry *models.MergeQueueEntry) error {
+	if len(entry.TestBranch) == 0 {
+		return nil
+	}
+	baseRepo, err := models.GetRepositoryByID(entry.BaseRepoID)
+	if err != nil {
+		return fmt.Errorf("GetRepositoryByID: %v", err)
+	}
+	baseGitRepo, err := git.OpenRepository(baseRepo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+	defer baseGitRepo.Close()
+
+	if !baseGitRepo.IsBranchExist(entry.TestBranch) {
+		return nil
+	}
+	log.Trace("Deleting the merge queue test branch %s of %s", entry.TestBranch, baseRepo.FullName())
+	return baseGitRepo.DeleteBranch(entry.TestBranch, git.DeleteBranchOptions{Force: true})
+}
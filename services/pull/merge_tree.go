@@ -0,0 +1,126 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/mcuadros/go-version"
+)
+
+// mergeTreeMinVersion is the first git version which merges trees without a work tree
+const mergeTreeMinVersion = "2.38"
+
+// mergeWithoutCheckout merges or squashes the pull request in the base repository itself with git merge-tree,
+// instead of checking out the base branch in a temporary clone of it, which is slow and huge for big repositories.
+// It returns false without an error when the pull request has to be merged in a temporary clone after all,
+// like for conflicts, rebases or head commits which aren't in the base repository yet.
+// The merge is pushed into the target branch of the base repository.
+func mergeWithoutCheckout(pr *models.PullRequest, doer *models.User, mergeStyle models.MergeStyle, message, targetBranch string) (string, bool, error) {
+	if mergeStyle != models.MergeStyleMerge && mergeStyle != models.MergeStyleSquash {
+		return "", false, nil
+	}
+	binVersion, err := git.BinVersion()
+	if err != nil {
+		return "", false, fmt.Errorf("Unable to get git version: %v", err)
+	}
+	if version.Compare(binVersion, mergeTreeMinVersion, "<") {
+		return "", false, nil
+	}
+
+	repoPath := pr.BaseRepo.RepoPath()
+	baseCommitID, err := git.GetFullCommitID(repoPath, git.BranchPrefix+pr.BaseBranch)
+	if err != nil {
+		return "", false, fmt.Errorf("GetFullCommitID(%s): %v", pr.BaseBranch, err)
+	}
+	headCommitID, err := git.GetFullCommitID(pr.HeadRepo.RepoPath(), git.BranchPrefix+pr.HeadBranch)
+	if err != nil {
+		return "", false, fmt.Errorf("GetFullCommitID(%s): %v", pr.HeadBranch, err)
+	}
+	// The head commits of a pull request from a fork may not have been pushed into the base repository yet
+	catFileBatch, err := git.GetCatFileBatchCheck(repoPath)
+	if err != nil {
+		return "", false, err
+	}
+	_, err = catFileBatch.Info(headCommitID + "^{commit}")
+	catFileBatch.Release()
+	if err != nil {
+		log.Debug("Head commit %s of %s is not in %s yet, merging in a temporary repository: %v", headCommitID, pr.HeadRepo.FullName(), pr.BaseRepo.FullName(), err)
+		return "", false, nil
+	}
+
+	// The conflicts and unrelated histories are reported by merging in a temporary repository
+	treeID, err := git.NewCommand("merge-tree", "--write-tree", baseCommitID, headCommitID).RunInDir(repoPath)
+	if err != nil {
+		log.Debug("git merge-tree [%s:%s -> %s:%s]: %v", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err)
+		return "", false, nil
+	}
+	treeID = strings.TrimSpace(strings.SplitN(treeID, "\n", 2)[0])
+
+	args := []string{"commit-tree", treeID, "-p", baseCommitID}
+	sig := doer.NewGitSig()
+	author := sig
+	if mergeStyle == models.MergeStyleMerge {
+		args = append(args, "-p", headCommitID)
+	} else {
+		if err := pr.Issue.LoadPoster(); err != nil {
+			log.Error("LoadPoster: %v", err)
+			return "", false, fmt.Errorf("LoadPoster: %v", err)
+		}
+		author = pr.Issue.Poster.NewGitSig()
+	}
+
+	if sign, keyID, _ := pr.SignMerge(doer, repoPath, baseCommitID, headCommitID); sign {
+		args = append(args, "-S"+keyID)
+	} else {
+		args = append(args, "--no-gpg-sign")
+	}
+	args = append(args, "-m", strings.TrimSpace(message))
+
+	commitTimeStr := time.Now().Format(time.RFC3339)
+	env := append(os.Environ(),
+		"GIT_AUTHOR_NAME="+author.Name,
+		"GIT_AUTHOR_EMAIL="+author.Email,
+		"GIT_AUTHOR_DATE="+commitTimeStr,
+		"GIT_COMMITTER_NAME="+sig.Name,
+		"GIT_COMMITTER_EMAIL="+sig.Email,
+		"GIT_COMMITTER_DATE="+commitTimeStr,
+	)
+	mergeCommitID, err := git.NewCommand(args...).RunInDirWithEnv(repoPath, env)
+	if err != nil {
+		log.Error("git commit-tree [%s:%s -> %s:%s]: %v", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err)
+		return "", false, fmt.Errorf("git commit-tree [%s:%s -> %s:%s]: %v", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err)
+	}
+	mergeCommitID = strings.TrimSpace(mergeCommitID)
+
+	if setting.LFS.StartServer {
+		if err := LFSPush(repoPath, mergeCommitID, baseCommitID, pr); err != nil {
+			return "", false, err
+		}
+	}
+
+	env, err = mergePushingEnvironment(pr, doer)
+	if err != nil {
+		return "", false, err
+	}
+	// Push the merge from the base repository into itself, so the hooks run like for a merge in a temporary repository
+	if err := git.Push(repoPath, git.PushOptions{
+		Remote: repoPath,
+		Branch: mergeCommitID + ":" + git.BranchPrefix + targetBranch,
+		Env:    env,
+	}); err != nil {
+		return "", false, err
+	}
+
+	return mergeCommitID, true, nil
+}
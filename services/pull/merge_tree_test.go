@@ -0,0 +1,153 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/mcuadros/go-version"
+	"github.com/stretchr/testify/assert"
+)
+
+// prepareMergeTreeTest loads the pull request and disables the hooks of its base repository,
+// they call a Gitea binary which the unit tests don't run
+func prepareMergeTreeTest(t *testing.T, prID int64) *models.PullRequest {
+	binVersion, err := git.BinVersion()
+	assert.NoError(t, err)
+	if version.Compare(binVersion, mergeTreeMinVersion, "<") {
+		t.Skipf("git %s doesn't merge trees without a work tree", binVersion)
+	}
+
+	models.PrepareTestEnv(t)
+
+	pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: prID}).(*models.PullRequest)
+	assert.NoError(t, pr.LoadBaseRepo())
+	assert.NoError(t, pr.LoadHeadRepo())
+	assert.NoError(t, pr.LoadIssue())
+	assert.NoError(t, pr.Issue.LoadPoster())
+
+	hooksPath := filepath.Join(setting.RepoRootPath, "no-hooks")
+	assert.NoError(t, os.MkdirAll(hooksPath, os.ModePerm))
+	_, err = git.NewCommand("config", "core.hooksPath", hooksPath).RunInDir(pr.BaseRepo.RepoPath())
+	assert.NoError(t, err)
+
+	return pr
+}
+
+func getBranchCommit(t *testing.T, repoPath, branch string) *git.Commit {
+	gitRepo, err := git.OpenRepository(repoPath)
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+
+	commit, err := gitRepo.GetBranchCommit(branch)
+	assert.NoError(t, err)
+	return commit
+}
+
+func TestMergeWithoutCheckout_Merge(t *testing.T) {
+	pr := prepareMergeTreeTest(t, 2)
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	repoPath := pr.BaseRepo.RepoPath()
+	baseCommit := getBranchCommit(t, repoPath, pr.BaseBranch)
+	headCommit := getBranchCommit(t, repoPath, pr.HeadBranch)
+
+	mergeCommitID, merged, err := mergeWithoutCheckout(pr, doer, models.MergeStyleMerge, "Merge branch2\n", pr.BaseBranch)
+	assert.NoError(t, err)
+	assert.True(t, merged)
+
+	commit := getBranchCommit(t, repoPath, pr.BaseBranch)
+	assert.Equal(t, mergeCommitID, commit.ID.String())
+	assert.Equal(t, "Merge branch2\n", commit.CommitMessage)
+	if assert.Equal(t, 2, commit.ParentCount()) {
+		parentID, _ := commit.ParentID(0)
+		assert.Equal(t, baseCommit.ID, parentID)
+		parentID, _ = commit.ParentID(1)
+		assert.Equal(t, headCommit.ID, parentID)
+	}
+	assert.Equal(t, headCommit.Tree.ID, commit.Tree.ID)
+	assert.Equal(t, doer.GetEmail(), commit.Author.Email)
+	assert.Equal(t, doer.GetEmail(), commit.Committer.Email)
+}
+
+func TestMergeWithoutCheckout_Squash(t *testing.T) {
+	pr := prepareMergeTreeTest(t, 2)
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	repoPath := pr.BaseRepo.RepoPath()
+	baseCommit := getBranchCommit(t, repoPath, pr.BaseBranch)
+	headCommit := getBranchCommit(t, repoPath, pr.HeadBranch)
+
+	_, merged, err := mergeWithoutCheckout(pr, doer, models.MergeStyleSquash, "Squash branch2", pr.BaseBranch)
+	assert.NoError(t, err)
+	assert.True(t, merged)
+
+	// The squashed commit is authored by the poster of the pull request and committed by the merger
+	commit := getBranchCommit(t, repoPath, pr.BaseBranch)
+	if assert.Equal(t, 1, commit.ParentCount()) {
+		parentID, _ := commit.ParentID(0)
+		assert.Equal(t, baseCommit.ID, parentID)
+	}
+	assert.Equal(t, headCommit.Tree.ID, commit.Tree.ID)
+	assert.Equal(t, pr.Issue.Poster.GitName(), commit.Author.Name)
+	assert.Equal(t, pr.Issue.Poster.GetEmail(), commit.Author.Email)
+	assert.Equal(t, doer.GitName(), commit.Committer.Name)
+	assert.Equal(t, doer.GetEmail(), commit.Committer.Email)
+}
+
+func TestMergeWithoutCheckout_Conflict(t *testing.T) {
+	pr := prepareMergeTreeTest(t, 2)
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	repoPath := pr.BaseRepo.RepoPath()
+
+	// Change the line of the head branch in the base branch too
+	tmpPath, err := ioutil.TempDir("", "merge-tree")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpPath)
+	assert.NoError(t, git.Clone(repoPath, tmpPath, git.CloneRepoOptions{Branch: pr.BaseBranch}))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpPath, "README.md"), []byte("# repo1\n\nDescription for repo1\n\nAnd a conflicting change\n"), 0644))
+	assert.NoError(t, git.AddChanges(tmpPath, true))
+	assert.NoError(t, git.CommitChanges(tmpPath, git.CommitChangesOptions{
+		Committer: doer.NewGitSig(),
+		Message:   "conflict",
+	}))
+	assert.NoError(t, git.Push(tmpPath, git.PushOptions{Remote: "origin", Branch: pr.BaseBranch}))
+	baseCommit := getBranchCommit(t, repoPath, pr.BaseBranch)
+
+	// The conflicts are left to the merge in a temporary repository
+	mergeCommitID, merged, err := mergeWithoutCheckout(pr, doer, models.MergeStyleMerge, "Merge branch2", pr.BaseBranch)
+	assert.NoError(t, err)
+	assert.False(t, merged)
+	assert.Empty(t, mergeCommitID)
+	assert.Equal(t, baseCommit.ID, getBranchCommit(t, repoPath, pr.BaseBranch).ID)
+}
+
+func TestMergeWithoutCheckout_ForkHead(t *testing.T) {
+	pr := prepareMergeTreeTest(t, 3)
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	repoPath := pr.BaseRepo.RepoPath()
+	baseCommit := getBranchCommit(t, repoPath, pr.BaseBranch)
+
+	// The head commit hasn't been pushed into the base repository yet
+	mergeCommitID, merged, err := mergeWithoutCheckout(pr, doer, models.MergeStyleMerge, "Merge branch2", pr.BaseBranch)
+	assert.NoError(t, err)
+	assert.False(t, merged)
+	assert.Empty(t, mergeCommitID)
+	assert.Equal(t, baseCommit.ID, getBranchCommit(t, repoPath, pr.BaseBranch).ID)
+}
+
+func TestMergeWithoutCheckout_Rebase(t *testing.T) {
+	pr := prepareMergeTreeTest(t, 2)
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+
+	_, merged, err := mergeWithoutCheckout(pr, doer, models.MergeStyleRebase, "", pr.BaseBranch)
+	assert.NoError(t, err)
+	assert.False(t, merged)
+}
@@ -21,11 +21,16 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/sync"
 	issue_service "code.gitea.io/gitea/services/issue"
 
 	"github.com/unknwon/com"
 )
 
+// pullWorkingPool makes sure a pull request isn't merged and checked at the same time,
+// so a merge which fast-forwards its base branch isn't mistaken for a manual merge
+var pullWorkingPool = sync.NewExclusivePool()
+
 // NewPullRequest creates new pull request with labels for repository.
 func NewPullRequest(repo *models.Repository, pull *models.Issue, labelIDs []int64, uuids []string, pr *models.PullRequest, assigneeIDs []int64) error {
 	if err := TestPatch(pr); err != nil {
@@ -95,6 +100,49 @@ func NewPullRequest(repo *models.Repository, pull *models.Issue, labelIDs []int6
 		_, _ = models.CreateComment(ops)
 	}
 
+	if err := RequestCodeOwnerReviews(pr, pull.Poster); err != nil {
+		log.Error("RequestCodeOwnerReviews[%d]: %v", pr.ID, err)
+	}
+
+	return nil
+}
+
+// MarkReadyForReview marks the draft pull request as ready for review on behalf of the doer and sends the
+// notifications of its pending review requests, which have been skipped while it was a draft
+func MarkReadyForReview(doer *models.User, pr *models.PullRequest) error {
+	if err := pr.MarkReadyForReview(doer); err != nil {
+		return err
+	}
+
+	reviews, err := models.GetReviewersByIssueID(pr.IssueID)
+	if err != nil {
+		return fmt.Errorf("GetReviewersByIssueID: %v", err)
+	}
+	comments, err := models.FindComments(models.FindCommentsOptions{
+		IssueID: pr.IssueID,
+		Type:    models.CommentTypeReviewRequest,
+	})
+	if err != nil {
+		return fmt.Errorf("FindComments: %v", err)
+	}
+	for _, review := range reviews {
+		if review.Type != models.ReviewTypeRequest {
+			continue
+		}
+		var request *models.Comment
+		for _, comment := range comments {
+			if comment.AssigneeID == review.ReviewerID {
+				request = comment
+			}
+		}
+		if request == nil || request.RemovedAssignee {
+			continue
+		}
+		if err := request.LoadPoster(); err != nil {
+			return fmt.Errorf("LoadPoster: %v", err)
+		}
+		notification.NotifyPullReviewRequest(request.Poster, pr.Issue, review.Reviewer, true, request)
+	}
 	return nil
 }
 
@@ -243,7 +291,7 @@ func AddTestPullRequestTask(doer *models.User, repoID int64, branch string, isSy
 			}
 			if err == nil {
 				for _, pr := range prs {
-					if newCommitID != "" && newCommitID != git.EmptySHA {
+					if !git.IsEmptyCommitID(newCommitID) {
 						changed, err := checkIfPRContentChanged(pr, oldCommitID, newCommitID)
 						if err != nil {
 							log.Error("checkIfPRContentChanged: %v", err)
@@ -275,6 +323,14 @@ func AddTestPullRequestTask(doer *models.User, repoID int64, branch string, isSy
 		}
 
 		addHeadRepoTasks(prs)
+		if isSync {
+			// The pull requests may change files owned by other owners
+			for _, pr := range prs {
+				if err := RequestCodeOwnerReviews(pr, doer); err != nil {
+					log.Error("RequestCodeOwnerReviews[%d]: %v", pr.ID, err)
+				}
+			}
+		}
 		for _, pr := range prs {
 			comment, err := models.CreatePushPullComment(doer, pr, oldCommitID, newCommitID)
 			if err == nil && comment != nil {
@@ -527,14 +583,46 @@ func CloseRepoBranchesPulls(doer *models.User, repo *models.Repository) error {
 
 // GetCommitMessages returns the commit messages between head and merge base (if there is one)
 func GetCommitMessages(pr *models.PullRequest) string {
+	messages, authors := getCommitMessagesAndAuthors(pr)
+	stringBuilder := strings.Builder{}
+	stringBuilder.WriteString(messages)
+
+	if len(authors) > 0 {
+		if _, err := stringBuilder.WriteRune('\n'); err != nil {
+			log.Error("Unable to write to string builder Error: %v", err)
+			return ""
+		}
+	}
+
+	for _, author := range authors {
+		if _, err := stringBuilder.Write([]byte("Co-authored-by: ")); err != nil {
+			log.Error("Unable to write to string builder Error: %v", err)
+			return ""
+		}
+		if _, err := stringBuilder.Write([]byte(author)); err != nil {
+			log.Error("Unable to write to string builder Error: %v", err)
+			return ""
+		}
+		if _, err := stringBuilder.WriteRune('\n'); err != nil {
+			log.Error("Unable to write to string builder Error: %v", err)
+			return ""
+		}
+	}
+
+	return stringBuilder.String()
+}
+
+// getCommitMessagesAndAuthors returns the commit messages between head and merge base (if there is one)
+// and the authors of the commits besides the poster of the pull request
+func getCommitMessagesAndAuthors(pr *models.PullRequest) (string, []string) {
 	if err := pr.LoadIssue(); err != nil {
 		log.Error("Cannot load issue %d for PR id %d: Error: %v", pr.IssueID, pr.ID, err)
-		return ""
+		return "", nil
 	}
 
 	if err := pr.Issue.LoadPoster(); err != nil {
 		log.Error("Cannot load poster %d for pr id %d, index %d Error: %v", pr.Issue.PosterID, pr.ID, pr.Index, err)
-		return ""
+		return "", nil
 	}
 
 	if pr.HeadRepo == nil {
@@ -542,27 +630,27 @@ func GetCommitMessages(pr *models.PullRequest) string {
 		pr.HeadRepo, err = models.GetRepositoryByID(pr.HeadRepoID)
 		if err != nil {
 			log.Error("GetRepositoryById[%d]: %v", pr.HeadRepoID, err)
-			return ""
+			return "", nil
 		}
 	}
 
 	gitRepo, err := git.OpenRepository(pr.HeadRepo.RepoPath())
 	if err != nil {
 		log.Error("Unable to open head repository: Error: %v", err)
-		return ""
+		return "", nil
 	}
 	defer gitRepo.Close()
 
 	headCommit, err := gitRepo.GetBranchCommit(pr.HeadBranch)
 	if err != nil {
 		log.Error("Unable to get head commit: %s Error: %v", pr.HeadBranch, err)
-		return ""
+		return "", nil
 	}
 
 	mergeBase, err := gitRepo.GetCommit(pr.MergeBase)
 	if err != nil {
 		log.Error("Unable to get merge base commit: %s Error: %v", pr.MergeBase, err)
-		return ""
+		return "", nil
 	}
 
 	limit := setting.Repository.PullRequest.DefaultMergeMessageCommitsLimit
@@ -570,7 +658,7 @@ func GetCommitMessages(pr *models.PullRequest) string {
 	list, err := gitRepo.CommitsBetweenLimit(headCommit, mergeBase, limit, 0)
 	if err != nil {
 		log.Error("Unable to get commits between: %s %s Error: %v", pr.HeadBranch, pr.MergeBase, err)
-		return ""
+		return "", nil
 	}
 
 	maxSize := setting.Repository.PullRequest.DefaultMergeMessageSize
@@ -591,12 +679,12 @@ func GetCommitMessages(pr *models.PullRequest) string {
 			}
 			if _, err := stringBuilder.Write(toWrite); err != nil {
 				log.Error("Unable to write commit message Error: %v", err)
-				return ""
+				return "", nil
 			}
 
 			if _, err := stringBuilder.WriteRune('\n'); err != nil {
 				log.Error("Unable to write commit message Error: %v", err)
-				return ""
+				return "", nil
 			}
 		}
 
@@ -616,7 +704,7 @@ func GetCommitMessages(pr *models.PullRequest) string {
 			list, err := gitRepo.CommitsBetweenLimit(headCommit, mergeBase, limit, skip)
 			if err != nil {
 				log.Error("Unable to get commits between: %s %s Error: %v", pr.HeadBranch, pr.MergeBase, err)
-				return ""
+				return "", nil
 
 			}
 			if list.Len() == 0 {
@@ -637,29 +725,7 @@ func GetCommitMessages(pr *models.PullRequest) string {
 		}
 	}
 
-	if len(authors) > 0 {
-		if _, err := stringBuilder.WriteRune('\n'); err != nil {
-			log.Error("Unable to write to string builder Error: %v", err)
-			return ""
-		}
-	}
-
-	for _, author := range authors {
-		if _, err := stringBuilder.Write([]byte("Co-authored-by: ")); err != nil {
-			log.Error("Unable to write to string builder Error: %v", err)
-			return ""
-		}
-		if _, err := stringBuilder.Write([]byte(author)); err != nil {
-			log.Error("Unable to write to string builder Error: %v", err)
-			return ""
-		}
-		if _, err := stringBuilder.WriteRune('\n'); err != nil {
-			log.Error("Unable to write to string builder Error: %v", err)
-			return ""
-		}
-	}
-
-	return stringBuilder.String()
+	return stringBuilder.String(), authors
 }
 
 // GetLastCommitStatus returns the last commit status for this pull request.
@@ -688,7 +754,7 @@ func GetLastCommitStatus(pr *models.PullRequest) (status *models.CommitStatus, e
 		return nil, err
 	}
 
-	statusList, err := models.GetLatestCommitStatus(pr.BaseRepo, lastCommitID, 0)
+	statusList, err := models.GetLatestCommitStatus(pr.BaseRepo, lastCommitID, models.ListOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -53,7 +53,7 @@ func createTemporaryRepo(pr *models.PullRequest) (string, error) {
 	baseRepoPath := pr.BaseRepo.RepoPath()
 	headRepoPath := pr.HeadRepo.RepoPath()
 
-	if err := git.InitRepository(tmpBasePath, false); err != nil {
+	if err := git.InitRepository(tmpBasePath, false, pr.BaseRepo.ObjectFormat()); err != nil {
 		log.Error("git init tmpBasePath: %v", err)
 		if err := models.RemoveTemporaryPath(tmpBasePath); err != nil {
 			log.Error("CreateTempRepo: RemoveTemporaryPath: %s", err)
@@ -0,0 +1,116 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package release
+
+import (
+	"fmt"
+	"os"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/services/archiver"
+)
+
+// archiveQueue represents a queue of releases to generate the source archives of
+var archiveQueue queue.UniqueQueue
+
+func handleArchive(data ...queue.Data) {
+	for _, datum := range data {
+		id := datum.(int64)
+		if err := GenerateReleaseArchives(id); err != nil {
+			log.Error("GenerateReleaseArchives[%d]: %v", id, err)
+		}
+	}
+}
+
+// InitArchiveQueue runs the queue generating the source archives of published releases
+func InitArchiveQueue() error {
+	archiveQueue = queue.CreateUniqueQueue("release_archiver", handleArchive, int64(0)).(queue.UniqueQueue)
+	if archiveQueue == nil {
+		return fmt.Errorf("Unable to create release_archiver Queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(archiveQueue.Run)
+	return nil
+}
+
+// addToArchiveQueue schedules the generation of the source archives of a published release
+func addToArchiveQueue(rel *models.Release) {
+	if archiveQueue == nil {
+		return
+	}
+	if err := archiveQueue.Push(rel.ID); err != nil && err != queue.ErrAlreadyInQueue {
+		log.Error("Unable to add release %d to the archive queue: %v", rel.ID, err)
+	}
+}
+
+// GenerateReleaseArchives generates the source archives of a published release in all archive types,
+// so downloads of the release tag are served from the cache.
+func GenerateReleaseArchives(releaseID int64) error {
+	rel, err := models.GetReleaseByID(releaseID)
+	if err != nil {
+		if models.IsErrReleaseNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("GetReleaseByID: %v", err)
+	}
+	if rel.IsDraft {
+		return nil
+	}
+
+	repo, err := models.GetRepositoryByID(rel.RepoID)
+	if err != nil {
+		return fmt.Errorf("GetRepositoryByID: %v", err)
+	}
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+	defer gitRepo.Close()
+
+	commit, err := gitRepo.GetTagCommit(rel.TagName)
+	if err != nil {
+		return fmt.Errorf("GetTagCommit: %v", err)
+	}
+	for _, archiveType := range archiver.ArchiveTypes {
+		if _, err := archiver.CreateArchive(repo, commit, archiveType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SourceArchive represents a source code archive of a release, served like an asset
+type SourceArchive struct {
+	Name        string
+	DownloadURL string
+	// Size is 0 if the archive isn't generated yet
+	Size int64
+}
+
+// GetSourceArchives returns the source archives of a published release. release must have attributes loaded
+func GetSourceArchives(rel *models.Release) []*SourceArchive {
+	if rel.IsDraft {
+		return nil
+	}
+	archives := make([]*SourceArchive, 0, len(archiver.ArchiveTypes))
+	for _, archiveType := range archiver.ArchiveTypes {
+		ext := archiver.Extension(archiveType)
+		archive := &SourceArchive{
+			Name:        rel.Repo.Name + "-" + rel.TagName + ext,
+			DownloadURL: rel.Repo.HTMLURL() + "/archive/" + rel.TagName + ext,
+		}
+		if len(rel.Sha1) > 0 {
+			if fi, err := os.Stat(archiver.ArchivePath(rel.Repo, rel.Sha1, archiveType)); err == nil {
+				archive.Size = fi.Size()
+			}
+		}
+		archives = append(archives, archive)
+	}
+	return archives
+}
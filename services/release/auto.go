@@ -0,0 +1,83 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package release
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+)
+
+// autoReleaseNoteMaxCommits is the maximum number of commits listed in a generated release note
+const autoReleaseNoteMaxCommits = 100
+
+// CreateReleaseForPushedTag creates a published release with a generated note for a pushed tag
+// if it matches the automatic release tag patterns of the repository and has no release yet.
+func CreateReleaseForPushedTag(gitRepo *git.Repository, repo *models.Repository, pusher *models.User, tagName string) error {
+	if !repo.UnitEnabled(models.UnitTypeReleases) ||
+		!repo.MustGetUnit(models.UnitTypeReleases).ReleasesConfig().IsAutoReleaseTag(tagName) {
+		return nil
+	}
+
+	isExist, err := models.IsReleaseExist(repo.ID, tagName)
+	if err != nil {
+		return err
+	} else if isExist {
+		return nil
+	}
+
+	note, err := GenerateReleaseNote(gitRepo, repo, tagName)
+	if err != nil {
+		return fmt.Errorf("GenerateReleaseNote: %v", err)
+	}
+
+	return CreateRelease(gitRepo, &models.Release{
+		RepoID:      repo.ID,
+		Repo:        repo,
+		PublisherID: pusher.ID,
+		Publisher:   pusher,
+		TagName:     tagName,
+		Target:      repo.DefaultBranch,
+		Title:       tagName,
+		Note:        note,
+	}, nil)
+}
+
+// GenerateReleaseNote lists the commits of the tag since the latest published release.
+// The note is empty if there is no previous release, so the release template is used instead.
+func GenerateReleaseNote(gitRepo *git.Repository, repo *models.Repository, tagName string) (string, error) {
+	previous, err := models.GetLatestReleaseByRepoID(repo.ID)
+	if err != nil {
+		if models.IsErrReleaseNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	if previous.TagName == tagName {
+		return "", nil
+	}
+
+	compareInfo, err := gitRepo.GetCompareInfo(repo.RepoPath(), previous.TagName, tagName)
+	if err != nil {
+		return "", fmt.Errorf("GetCompareInfo: %v", err)
+	}
+
+	var note strings.Builder
+	fmt.Fprintf(&note, "## Changes since %s\n\n", previous.TagName)
+	count := 0
+	for e := compareInfo.Commits.Front(); e != nil; e = e.Next() {
+		if count == autoReleaseNoteMaxCommits {
+			fmt.Fprintf(&note, "- and %d more commits\n", compareInfo.Commits.Len()-count)
+			break
+		}
+		commit := e.Value.(*git.Commit)
+		fmt.Fprintf(&note, "- %s %s\n", commit.ID.String()[:10], commit.Summary())
+		count++
+	}
+	fmt.Fprintf(&note, "\n%s/compare/%s...%s\n", repo.HTMLURL(), previous.TagName, tagName)
+	return note.String(), nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package release
+
+import (
+	"fmt"
+	"path"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/storage"
+)
+
+// cdnQueue represents a queue of releases to mirror the assets of to the release CDN
+var cdnQueue queue.UniqueQueue
+
+func handleCDN(data ...queue.Data) {
+	for _, datum := range data {
+		id := datum.(int64)
+		if err := MirrorReleaseAssets(id); err != nil {
+			log.Error("MirrorReleaseAssets[%d]: %v", id, err)
+		}
+	}
+}
+
+// InitCDNQueue runs the queue mirroring the assets of published releases to the release CDN if it is enabled
+func InitCDNQueue() error {
+	if storage.ReleaseCDN == nil {
+		return nil
+	}
+
+	cdnQueue = queue.CreateUniqueQueue("release_cdn", handleCDN, int64(0)).(queue.UniqueQueue)
+	if cdnQueue == nil {
+		return fmt.Errorf("Unable to create release_cdn Queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(cdnQueue.Run)
+	return nil
+}
+
+// AddToCDNQueue schedules mirroring the assets of a published release to the release CDN
+func AddToCDNQueue(rel *models.Release) {
+	if cdnQueue == nil || rel.IsDraft || rel.IsTag {
+		return
+	}
+	if err := cdnQueue.Push(rel.ID); err != nil && err != queue.ErrAlreadyInQueue {
+		log.Error("Unable to add release %d to the CDN queue: %v", rel.ID, err)
+	}
+}
+
+// MirrorReleaseAssets copies the assets of a published release of a public repository which are not mirrored yet
+// to the release CDN. The attachments storage stays the source of truth, the copies are only used for downloads.
+func MirrorReleaseAssets(releaseID int64) error {
+	if storage.ReleaseCDN == nil {
+		return nil
+	}
+
+	rel, err := models.GetReleaseByID(releaseID)
+	if err != nil {
+		if models.IsErrReleaseNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("GetReleaseByID: %v", err)
+	}
+	if rel.IsDraft || rel.IsTag {
+		return nil
+	}
+
+	repo, err := models.GetRepositoryByID(rel.RepoID)
+	if err != nil {
+		return fmt.Errorf("GetRepositoryByID: %v", err)
+	}
+	// The CDN is public, assets of private repositories must not be mirrored
+	if repo.IsPrivate {
+		return nil
+	}
+
+	if err = models.GetReleaseAttachments(rel); err != nil {
+		return fmt.Errorf("GetReleaseAttachments: %v", err)
+	}
+	for _, attach := range rel.Attachments {
+		if attach.MirrorPath != "" {
+			continue
+		}
+
+		mirrorPath := path.Join(attach.UUID, attach.Name)
+		if _, err = storage.Copy(storage.ReleaseCDN, mirrorPath, storage.Attachments, attach.RelativePath()); err != nil {
+			return fmt.Errorf("Copy %s: %v", attach.UUID, err)
+		}
+		attach.MirrorPath = mirrorPath
+		if err = models.UpdateAttachmentMirrorPath(attach); err != nil {
+			return fmt.Errorf("UpdateAttachmentMirrorPath: %v", err)
+		}
+	}
+	return nil
+}
+
+// RemoveAttachmentMirror deletes the copy of an attachment from the release CDN,
+// the attachment is served by Gitea until it is mirrored again.
+func RemoveAttachmentMirror(attach *models.Attachment) error {
+	if attach.MirrorPath == "" {
+		return nil
+	}
+	if storage.ReleaseCDN != nil {
+		if err := storage.ReleaseCDN.Delete(attach.MirrorPath); err != nil {
+			return err
+		}
+	}
+	attach.MirrorPath = ""
+	return models.UpdateAttachmentMirrorPath(attach)
+}
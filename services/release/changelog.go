@@ -0,0 +1,113 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package release
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+)
+
+// Changelog represents the changes between two releases of a repository
+type Changelog struct {
+	Base         *models.Release
+	Head         *models.Release
+	Commits      []*git.Commit
+	PullRequests models.PullRequestList
+	Contributors []*Contributor
+}
+
+// Contributor represents an author of the commits of a changelog
+type Contributor struct {
+	Name  string
+	Email string
+	// User is nil if the email doesn't belong to a user
+	User    *models.User
+	Commits int
+}
+
+// getPublishedRelease returns the release of the tag, drafts don't count as they have no tag yet
+func getPublishedRelease(repo *models.Repository, tagName string) (*models.Release, error) {
+	rel, err := models.GetRelease(repo.ID, tagName)
+	if err != nil {
+		return nil, err
+	}
+	if rel.IsDraft {
+		return nil, models.ErrReleaseNotExist{ID: rel.ID, TagName: tagName}
+	}
+	rel.Repo = repo
+	return rel, nil
+}
+
+// GetChangelog returns the commits, merged pull requests and contributors between the releases of
+// the base and the head tag. If the base tag is empty the previous release of the head is used.
+func GetChangelog(gitRepo *git.Repository, repo *models.Repository, baseTag, headTag string) (*Changelog, error) {
+	head, err := getPublishedRelease(repo, headTag)
+	if err != nil {
+		return nil, err
+	}
+
+	var base *models.Release
+	if len(baseTag) == 0 {
+		if base, err = models.GetPreviousRelease(head); err != nil {
+			return nil, err
+		}
+		base.Repo = repo
+	} else if base, err = getPublishedRelease(repo, baseTag); err != nil {
+		return nil, err
+	}
+
+	compareInfo, err := gitRepo.GetCompareInfo(repo.RepoPath(), base.TagName, head.TagName)
+	if err != nil {
+		return nil, fmt.Errorf("GetCompareInfo: %v", err)
+	}
+
+	changelog := &Changelog{
+		Base:    base,
+		Head:    head,
+		Commits: make([]*git.Commit, 0, compareInfo.Commits.Len()),
+	}
+	commitIDs := make([]string, 0, compareInfo.Commits.Len())
+	contributors := make(map[string]*Contributor)
+	for e := compareInfo.Commits.Front(); e != nil; e = e.Next() {
+		commit := e.Value.(*git.Commit)
+		changelog.Commits = append(changelog.Commits, commit)
+		commitIDs = append(commitIDs, commit.ID.String())
+
+		email := strings.ToLower(commit.Author.Email)
+		if contributor, ok := contributors[email]; ok {
+			contributor.Commits++
+			continue
+		}
+		user, err := models.GetUserByEmail(email)
+		if err != nil && !models.IsErrUserNotExist(err) {
+			return nil, fmt.Errorf("GetUserByEmail: %v", err)
+		}
+		contributor := &Contributor{
+			Name:    commit.Author.Name,
+			Email:   commit.Author.Email,
+			User:    user,
+			Commits: 1,
+		}
+		contributors[email] = contributor
+		changelog.Contributors = append(changelog.Contributors, contributor)
+	}
+
+	sort.SliceStable(changelog.Contributors, func(i, j int) bool {
+		return changelog.Contributors[i].Commits > changelog.Contributors[j].Commits
+	})
+
+	if changelog.PullRequests, err = models.GetMergedPullRequestsByCommitIDs(repo.ID, commitIDs); err != nil {
+		return nil, fmt.Errorf("GetMergedPullRequestsByCommitIDs: %v", err)
+	}
+	if err = changelog.PullRequests.LoadAttributes(); err != nil {
+		return nil, fmt.Errorf("LoadAttributes: %v", err)
+	}
+
+	return changelog, nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package release
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetChangelog(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+
+	gitRepo, err := git.OpenRepository(models.RepoPath(user.Name, repo.Name))
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+
+	pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: 1}).(*models.PullRequest)
+	pr.MergedCommitID = "985f0301dba5e7b34be866819cd15ad3d8f508ee"
+	assert.NoError(t, pr.UpdateCols("merged_commit_id"))
+
+	assert.NoError(t, CreateRelease(gitRepo, &models.Release{
+		RepoID:      repo.ID,
+		PublisherID: user.ID,
+		TagName:     "v1.2",
+		Target:      "branch2",
+		Title:       "v1.2 is released",
+		Note:        "v1.2 is released",
+	}, nil))
+
+	// the base defaults to the previous release
+	changelog, err := GetChangelog(gitRepo, repo, "", "v1.2")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.1", changelog.Base.TagName)
+	assert.Equal(t, "v1.2", changelog.Head.TagName)
+	if assert.Len(t, changelog.Commits, 2) {
+		assert.Equal(t, "985f0301dba5e7b34be866819cd15ad3d8f508ee", changelog.Commits[0].ID.String())
+		assert.Equal(t, "5c050d3b6d2db231ab1f64e324f1b6b9a0b181c2", changelog.Commits[1].ID.String())
+	}
+	if assert.Len(t, changelog.PullRequests, 1) {
+		assert.EqualValues(t, 1, changelog.PullRequests[0].ID)
+	}
+	if assert.Len(t, changelog.Contributors, 1) {
+		assert.Equal(t, "6543@obermui.de", changelog.Contributors[0].Email)
+		assert.Equal(t, 2, changelog.Contributors[0].Commits)
+	}
+
+	changelog, err = GetChangelog(gitRepo, repo, "v1.2", "v1.2")
+	assert.NoError(t, err)
+	assert.Empty(t, changelog.Commits)
+	assert.Empty(t, changelog.PullRequests)
+
+	_, err = GetChangelog(gitRepo, repo, "v1.1", "v9.9")
+	assert.True(t, models.IsErrReleaseNotExist(err))
+	_, err = GetChangelog(gitRepo, repo, "", "v1.1")
+	assert.True(t, models.IsErrReleaseNotExist(err))
+}
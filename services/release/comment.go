@@ -0,0 +1,45 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package release
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/notification"
+)
+
+// CreateReleaseComment adds a comment to a published release and notifies it
+func CreateReleaseComment(doer *models.User, rel *models.Release, content string) (*models.ReleaseComment, error) {
+	if rel.IsDraft || rel.IsTag {
+		return nil, models.ErrReleaseNotPublished{TagName: rel.TagName}
+	}
+
+	comment, err := models.CreateReleaseComment(doer, rel, content)
+	if err != nil {
+		return nil, err
+	}
+
+	notification.NotifyCreateReleaseComment(doer, rel, comment)
+	return comment, nil
+}
+
+// UpdateReleaseComment updates the content of a release comment and notifies it
+func UpdateReleaseComment(doer *models.User, comment *models.ReleaseComment, oldContent string) error {
+	if err := models.UpdateReleaseComment(comment); err != nil {
+		return err
+	}
+
+	notification.NotifyUpdateReleaseComment(doer, comment, oldContent)
+	return nil
+}
+
+// DeleteReleaseComment deletes a release comment and notifies it
+func DeleteReleaseComment(doer *models.User, comment *models.ReleaseComment) error {
+	if err := models.DeleteReleaseComment(comment); err != nil {
+		return err
+	}
+
+	notification.NotifyDeleteReleaseComment(doer, comment)
+	return nil
+}
@@ -5,18 +5,46 @@
 package release
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/timeutil"
 )
 
+// ChecksumsFileName is the name of the generated checksums asset of a release
+const ChecksumsFileName = "checksums.txt"
+
+// createGitTag creates the git tag of a release, it will be a signed annotated tag
+// if the signing rules allow it and a lightweight tag otherwise.
+func createGitTag(gitRepo *git.Repository, rel *models.Release, commitID string) error {
+	sign, keyID, err := rel.Repo.SignRelease(rel.Publisher)
+	if err != nil && !models.IsErrWontSign(err) {
+		return err
+	}
+	if !sign {
+		return gitRepo.CreateTag(rel.TagName, commitID)
+	}
+
+	sig := rel.Publisher.NewGitSig()
+	env := append(os.Environ(),
+		"GIT_COMMITTER_NAME="+sig.Name,
+		"GIT_COMMITTER_EMAIL="+sig.Email,
+	)
+	return gitRepo.CreateSignedTag(rel.TagName, rel.TagMessage(), commitID, keyID, env)
+}
+
 func createTag(gitRepo *git.Repository, rel *models.Release) error {
 	// Only actual create when publish.
 	if !rel.IsDraft {
@@ -26,9 +54,14 @@ func createTag(gitRepo *git.Repository, rel *models.Release) error {
 				return fmt.Errorf("GetCommit: %v", err)
 			}
 
+			if err := rel.LoadAttributes(); err != nil {
+				log.Error("LoadAttributes: %v", err)
+				return err
+			}
+
 			// Trim '--' prefix to prevent command line argument vulnerability.
 			rel.TagName = strings.TrimPrefix(rel.TagName, "--")
-			if err = gitRepo.CreateTag(rel.TagName, commit.ID.String()); err != nil {
+			if err = createGitTag(gitRepo, rel, commit.ID.String()); err != nil {
 				if strings.Contains(err.Error(), "is not a valid tag name") {
 					return models.ErrInvalidTagName{
 						TagName: rel.TagName,
@@ -38,10 +71,6 @@ func createTag(gitRepo *git.Repository, rel *models.Release) error {
 			}
 			rel.LowerTagName = strings.ToLower(rel.TagName)
 			// Prepare Notify
-			if err := rel.LoadAttributes(); err != nil {
-				log.Error("LoadAttributes: %v", err)
-				return err
-			}
 			notification.NotifyPushCommits(
 				rel.Publisher, rel.Repo, git.TagPrefix+rel.TagName,
 				git.EmptySHA, commit.ID.String(), repository.NewPushCommits())
@@ -54,7 +83,7 @@ func createTag(gitRepo *git.Repository, rel *models.Release) error {
 
 		rel.Sha1 = commit.ID.String()
 		rel.CreatedUnix = timeutil.TimeStampNow()
-		rel.NumCommits, err = commit.CommitsCount()
+		rel.NumCommits, err = cache.GetCommitsCount(commit)
 		if err != nil {
 			return fmt.Errorf("CommitsCount: %v", err)
 		}
@@ -64,8 +93,102 @@ func createTag(gitRepo *git.Repository, rel *models.Release) error {
 	return nil
 }
 
+// UpdateChecksumsAttachment regenerates the checksums asset of a release
+// from the SHA256 digests of all its other attachments. The generated asset has no uploader,
+// none is generated if a checksums asset has been uploaded along the other ones.
+func UpdateChecksumsAttachment(rel *models.Release) error {
+	if !setting.AttachmentReleaseChecksums || rel.IsTag {
+		return nil
+	}
+
+	if err := models.GetReleaseAttachments(rel); err != nil {
+		return fmt.Errorf("GetReleaseAttachments: %v", err)
+	}
+
+	attachments := make([]*models.Attachment, 0, len(rel.Attachments))
+	hasUploadedChecksums := false
+	for _, attach := range rel.Attachments {
+		if attach.Name == ChecksumsFileName && attach.UploaderID == 0 {
+			if err := models.DeleteAttachment(attach, true); err != nil {
+				return fmt.Errorf("DeleteAttachment: %v", err)
+			}
+			continue
+		}
+		hasUploadedChecksums = hasUploadedChecksums || attach.Name == ChecksumsFileName
+		attachments = append(attachments, attach)
+	}
+	rel.Attachments = attachments
+	if len(attachments) == 0 || hasUploadedChecksums {
+		return nil
+	}
+
+	sort.Slice(attachments, func(i, j int) bool {
+		return attachments[i].Name < attachments[j].Name
+	})
+	var buf bytes.Buffer
+	for _, attach := range attachments {
+		fmt.Fprintf(&buf, "%s  %s\n", attach.Sha256, attach.Name)
+	}
+
+	checksums, err := models.NewAttachment(&models.Attachment{
+		Name:      ChecksumsFileName,
+		ReleaseID: rel.ID,
+	}, buf.Bytes(), &bytes.Buffer{})
+	if err != nil {
+		return fmt.Errorf("NewAttachment: %v", err)
+	}
+	rel.Attachments = append(rel.Attachments, checksums)
+	return nil
+}
+
+// CreateAttachment adds an asset to a release. The upload of the asset is notified if
+// the release is published, assets of drafts are announced when they are published.
+func CreateAttachment(doer *models.User, rel *models.Release, attach *models.Attachment, buf []byte, file io.Reader) (*models.Attachment, error) {
+	attach.ReleaseID = rel.ID
+	attach, err := models.NewAttachment(attach, buf, file)
+	if err != nil {
+		return nil, fmt.Errorf("NewAttachment: %v", err)
+	}
+
+	if err = UpdateChecksumsAttachment(rel); err != nil {
+		return nil, fmt.Errorf("UpdateChecksumsAttachment: %v", err)
+	}
+	AddToCDNQueue(rel)
+
+	if !rel.IsDraft && !rel.IsTag {
+		notification.NotifyReleaseAssetUploaded(doer, rel, attach)
+	}
+	return attach, nil
+}
+
+// DeleteAttachment removes an asset from a release and notifies it if the release is published.
+func DeleteAttachment(doer *models.User, rel *models.Release, attach *models.Attachment) error {
+	if err := models.DeleteAttachment(attach, true); err != nil {
+		return fmt.Errorf("DeleteAttachment: %v", err)
+	}
+
+	if err := UpdateChecksumsAttachment(rel); err != nil {
+		return fmt.Errorf("UpdateChecksumsAttachment: %v", err)
+	}
+
+	if !rel.IsDraft && !rel.IsTag {
+		notification.NotifyReleaseAssetDeleted(doer, rel, attach)
+	}
+	return nil
+}
+
 // CreateRelease creates a new release of repository.
 func CreateRelease(gitRepo *git.Repository, rel *models.Release, attachmentUUIDs []string) error {
+	return createRelease(gitRepo, rel, attachmentUUIDs, false)
+}
+
+// CreateMigratedRelease creates a release migrated from another service. Unlike CreateRelease
+// it keeps the note and the creation time of the release as they are and sends no notifications.
+func CreateMigratedRelease(gitRepo *git.Repository, rel *models.Release, attachmentUUIDs []string) error {
+	return createRelease(gitRepo, rel, attachmentUUIDs, true)
+}
+
+func createRelease(gitRepo *git.Repository, rel *models.Release, attachmentUUIDs []string, isMigration bool) error {
 	isExist, err := models.IsReleaseExist(rel.RepoID, rel.TagName)
 	if err != nil {
 		return err
@@ -75,9 +198,24 @@ func CreateRelease(gitRepo *git.Repository, rel *models.Release, attachmentUUIDs
 		}
 	}
 
+	// Check the quotas before anything is created
+	if err = models.CheckReleaseAttachmentsQuota(rel, attachmentUUIDs); err != nil {
+		return err
+	}
+
+	if !isMigration {
+		if err = applyReleaseTemplate(gitRepo, rel); err != nil {
+			return fmt.Errorf("applyReleaseTemplate: %v", err)
+		}
+	}
+
+	createdUnix := rel.CreatedUnix
 	if err = createTag(gitRepo, rel); err != nil {
 		return err
 	}
+	if isMigration && createdUnix > 0 {
+		rel.CreatedUnix = createdUnix
+	}
 
 	rel.LowerTagName = strings.ToLower(rel.TagName)
 	if err = models.InsertRelease(rel); err != nil {
@@ -88,15 +226,56 @@ func CreateRelease(gitRepo *git.Repository, rel *models.Release, attachmentUUIDs
 		return err
 	}
 
-	if !rel.IsDraft {
+	if err = UpdateChecksumsAttachment(rel); err != nil {
+		return err
+	}
+
+	if rel.ExternalAssets != nil {
+		if err = models.UpdateReleaseExternalAssets(rel.ID, rel.ExternalAssets); err != nil {
+			return err
+		}
+	}
+
+	if !rel.IsDraft && !isMigration {
 		notification.NotifyNewRelease(rel)
+		addToArchiveQueue(rel)
+		AddToCDNQueue(rel)
 	}
 
 	return nil
 }
 
+// checkReleaseProtection returns ErrReleaseProtected if the user is not allowed to change the release
+func checkReleaseProtection(rel *models.Release, doer *models.User) error {
+	protected, err := rel.IsProtectedFrom(doer)
+	if err != nil {
+		return fmt.Errorf("IsProtectedFrom: %v", err)
+	}
+	if protected {
+		return models.ErrReleaseProtected{TagName: rel.TagName}
+	}
+	return nil
+}
+
 // UpdateRelease updates information of a release.
 func UpdateRelease(doer *models.User, gitRepo *git.Repository, rel *models.Release, attachmentUUIDs []string) (err error) {
+	// The protection is based on the stored state, a published release can't be turned into a draft to bypass it.
+	oldRel, err := models.GetReleaseByID(rel.ID)
+	if err != nil {
+		return fmt.Errorf("GetReleaseByID: %v", err)
+	}
+	if oldRel.Repo = rel.Repo; oldRel.Repo == nil {
+		if oldRel.Repo, err = models.GetRepositoryByID(oldRel.RepoID); err != nil {
+			return fmt.Errorf("GetRepositoryByID: %v", err)
+		}
+	}
+	if err = checkReleaseProtection(oldRel, doer); err != nil {
+		return err
+	}
+	if err = models.CheckReleaseAttachmentsQuota(rel, attachmentUUIDs); err != nil {
+		return err
+	}
+
 	if err = createTag(gitRepo, rel); err != nil {
 		return err
 	}
@@ -108,13 +287,48 @@ func UpdateRelease(doer *models.User, gitRepo *git.Repository, rel *models.Relea
 
 	if err = models.AddReleaseAttachments(rel.ID, attachmentUUIDs); err != nil {
 		log.Error("AddReleaseAttachments: %v", err)
+	} else if err = UpdateChecksumsAttachment(rel); err != nil {
+		log.Error("UpdateChecksumsAttachment: %v", err)
 	}
 
-	notification.NotifyUpdateRelease(doer, rel)
+	if rel.ExternalAssets != nil {
+		if err = models.UpdateReleaseExternalAssets(rel.ID, rel.ExternalAssets); err != nil {
+			log.Error("UpdateReleaseExternalAssets: %v", err)
+		}
+	}
+
+	// Drafts are not announced, publishing one is the same as creating a published release
+	if !rel.IsDraft {
+		if oldRel.IsDraft {
+			notification.NotifyNewRelease(rel)
+		} else {
+			notification.NotifyUpdateRelease(doer, rel)
+			notifyReleaseAssetsUploaded(doer, rel, attachmentUUIDs)
+		}
+		addToArchiveQueue(rel)
+		AddToCDNQueue(rel)
+	}
 
 	return err
 }
 
+// notifyReleaseAssetsUploaded notifies the upload of the assets added to a published release
+func notifyReleaseAssetsUploaded(doer *models.User, rel *models.Release, attachmentUUIDs []string) {
+	if len(attachmentUUIDs) == 0 {
+		return
+	}
+	attachments, err := models.GetAttachmentsByUUIDs(attachmentUUIDs)
+	if err != nil {
+		log.Error("GetAttachmentsByUUIDs: %v", err)
+		return
+	}
+	for _, attach := range attachments {
+		if attach.ReleaseID == rel.ID {
+			notification.NotifyReleaseAssetUploaded(doer, rel, attach)
+		}
+	}
+}
+
 // DeleteReleaseByID deletes a release and corresponding Git tag by given ID.
 func DeleteReleaseByID(id int64, doer *models.User, delTag bool) error {
 	rel, err := models.GetReleaseByID(id)
@@ -127,6 +341,12 @@ func DeleteReleaseByID(id int64, doer *models.User, delTag bool) error {
 		return fmt.Errorf("GetRepositoryByID: %v", err)
 	}
 
+	rel.Repo = repo
+	if err = checkReleaseProtection(rel, doer); err != nil {
+		return err
+	}
+	isPublished := !rel.IsDraft && !rel.IsTag
+
 	if delTag {
 		if stdout, err := git.NewCommand("tag", "-d", rel.TagName).
 			SetDescription(fmt.Sprintf("DeleteReleaseByID (git tag -d): %d", rel.ID)).
@@ -159,14 +379,79 @@ func DeleteReleaseByID(id int64, doer *models.User, delTag bool) error {
 		return fmt.Errorf("DeleteAttachments: %v", err)
 	}
 
+	if err := models.DeleteReleaseExternalAssetsByRelease(rel.ID); err != nil {
+		return fmt.Errorf("DeleteReleaseExternalAssets: %v", err)
+	}
+
+	if err := models.DeleteAttachmentUploadsByRelease(rel.ID); err != nil {
+		return fmt.Errorf("DeleteAttachmentUploadsByRelease: %v", err)
+	}
+
+	if err := models.DeleteReleaseCommentsByRelease(rel.ID); err != nil {
+		return fmt.Errorf("DeleteReleaseCommentsByRelease: %v", err)
+	}
+
 	for i := range rel.Attachments {
 		attachment := rel.Attachments[i]
-		if err := os.RemoveAll(attachment.LocalPath()); err != nil {
+		if err := storage.Attachments.Delete(attachment.RelativePath()); err != nil {
 			log.Error("Delete attachment %s of release %s failed: %v", attachment.UUID, rel.ID, err)
 		}
+		if attachment.MirrorPath != "" && storage.ReleaseCDN != nil {
+			if err := storage.ReleaseCDN.Delete(attachment.MirrorPath); err != nil {
+				log.Error("Delete mirror of attachment %s of release %s failed: %v", attachment.UUID, rel.ID, err)
+			}
+		}
+	}
+
+	if isPublished {
+		notification.NotifyDeleteRelease(doer, rel)
+	}
+
+	return nil
+}
+
+// YankRelease withdraws a published release for the given reason. The tag and the assets
+// of the release are kept, but it isn't considered as the latest release anymore.
+func YankRelease(doer *models.User, rel *models.Release, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if len(reason) == 0 {
+		return models.ErrReleaseYankReasonRequired{TagName: rel.TagName}
+	}
+	if rel.IsDraft || rel.IsTag {
+		return models.ErrReleaseNotPublished{TagName: rel.TagName}
+	}
+
+	wasYanked := rel.IsYanked
+	rel.IsYanked = true
+	rel.YankedReason = reason
+	if !wasYanked {
+		rel.YankedUnix = timeutil.TimeStampNow()
+	}
+	if err := models.UpdateRelease(models.DefaultDBContext(), rel); err != nil {
+		return err
+	}
+
+	if wasYanked {
+		notification.NotifyUpdateRelease(doer, rel)
+	} else {
+		notification.NotifyYankRelease(doer, rel)
+	}
+	return nil
+}
+
+// UnyankRelease restores a yanked release
+func UnyankRelease(doer *models.User, rel *models.Release) error {
+	if !rel.IsYanked {
+		return nil
 	}
 
-	notification.NotifyDeleteRelease(doer, rel)
+	rel.IsYanked = false
+	rel.YankedReason = ""
+	rel.YankedUnix = 0
+	if err := models.UpdateRelease(models.DefaultDBContext(), rel); err != nil {
+		return err
+	}
 
+	notification.NotifyUpdateRelease(doer, rel)
 	return nil
 }
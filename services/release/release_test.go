@@ -5,11 +5,20 @@
 package release
 
 import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -101,3 +110,408 @@ func TestRelease_Create(t *testing.T) {
 		IsTag:        true,
 	}, nil))
 }
+
+func TestRelease_CreateWithTemplatePlaceholders(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+
+	rel := &models.Release{
+		RepoID:      repo.ID,
+		PublisherID: user.ID,
+		TagName:     "v1.2",
+		Target:      "master",
+		Title:       "v1.2",
+		Note:        "Release ${TAG_NAME} follows ${PREVIOUS_TAG}, see ${COMPARE_URL} and $HOME",
+		IsDraft:     true,
+	}
+	assert.NoError(t, CreateRelease(gitRepo, rel, nil))
+	assert.Equal(t, "Release v1.2 follows v1.1, see https://try.gitea.io/user2/repo1/compare/v1.1...v1.2 and $HOME", rel.Note)
+
+	// no template in the default branch
+	assert.Empty(t, GetReleaseTemplate(gitRepo, repo))
+}
+
+func TestRelease_Protected(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	owner := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	other := models.AssertExistsAndLoadBean(t, &models.User{ID: 4}).(*models.User)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+
+	gitRepo, err := git.OpenRepository(models.RepoPath(owner.Name, repo.Name))
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+
+	assert.NoError(t, models.UpdateRepositoryUnits(repo, []models.RepoUnit{{
+		RepoID: repo.ID,
+		Type:   models.UnitTypeReleases,
+		Config: &models.ReleasesConfig{ProtectPublished: true, ProtectedTagPatterns: "v0.*"},
+	}}, nil))
+
+	rel := &models.Release{
+		RepoID:      repo.ID,
+		PublisherID: owner.ID,
+		TagName:     "v0.2",
+		Target:      "master",
+		Title:       "v0.2 is released",
+	}
+	assert.NoError(t, CreateRelease(gitRepo, rel, nil))
+
+	rel.Title = "v0.2 is changed"
+	err = UpdateRelease(other, gitRepo, rel, nil)
+	assert.True(t, models.IsErrReleaseProtected(err))
+	err = DeleteReleaseByID(rel.ID, other, true)
+	assert.True(t, models.IsErrReleaseProtected(err))
+	models.AssertExistsAndLoadBean(t, &models.Release{ID: rel.ID, Title: "v0.2 is released"})
+
+	// Releases of tags not matching the patterns are not protected
+	unprotected := models.AssertExistsAndLoadBean(t, &models.Release{ID: 1}).(*models.Release)
+	unprotected.Title = "v1.1 is changed"
+	assert.NoError(t, UpdateRelease(other, gitRepo, unprotected, nil))
+
+	// Repository admins are not restricted
+	assert.NoError(t, UpdateRelease(owner, gitRepo, rel, nil))
+	assert.NoError(t, DeleteReleaseByID(rel.ID, owner, true))
+	models.AssertNotExistsBean(t, &models.Release{ID: rel.ID})
+}
+
+func TestRelease_Yank(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	rel := models.AssertExistsAndLoadBean(t, &models.Release{ID: 1}).(*models.Release)
+
+	err := YankRelease(doer, rel, "  ")
+	assert.True(t, models.IsErrReleaseYankReasonRequired(err))
+
+	assert.NoError(t, YankRelease(doer, rel, "broken build"))
+	yanked := models.AssertExistsAndLoadBean(t, &models.Release{ID: 1}).(*models.Release)
+	assert.True(t, yanked.IsYanked)
+	assert.Equal(t, "broken build", yanked.YankedReason)
+	assert.NotZero(t, yanked.YankedUnix)
+	assert.Equal(t, "v1.1", yanked.TagName)
+
+	// A yanked release is never the latest one
+	_, err = models.GetLatestReleaseByRepoID(rel.RepoID)
+	assert.True(t, models.IsErrReleaseNotExist(err))
+
+	assert.NoError(t, UnyankRelease(doer, rel))
+	restored := models.AssertExistsAndLoadBean(t, &models.Release{ID: 1}).(*models.Release)
+	assert.False(t, restored.IsYanked)
+	assert.Empty(t, restored.YankedReason)
+	latest, err := models.GetLatestReleaseByRepoID(rel.RepoID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, latest.ID)
+
+	// Only published releases can be yanked
+	draft := &models.Release{ID: rel.ID, TagName: rel.TagName, IsDraft: true}
+	err = YankRelease(doer, draft, "broken build")
+	assert.True(t, models.IsErrReleaseNotPublished(err))
+}
+
+func TestRelease_CreateForPushedTag(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	pusher := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+
+	gitRepo, err := git.OpenRepository(models.RepoPath(pusher.Name, repo.Name))
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+
+	assert.NoError(t, models.UpdateRepositoryUnits(repo, []models.RepoUnit{{
+		RepoID: repo.ID,
+		Type:   models.UnitTypeReleases,
+		Config: &models.ReleasesConfig{AutoReleaseTagPatterns: "v5.*"},
+	}}, nil))
+
+	for _, tagName := range []string{"v5.0", "nightly-5"} {
+		assert.NoError(t, gitRepo.CreateTag(tagName, "65f1bf27bc3bf70f64657658635e66094edbcb4d"))
+		assert.NoError(t, CreateReleaseForPushedTag(gitRepo, repo, pusher, tagName))
+	}
+
+	rel := models.AssertExistsAndLoadBean(t, &models.Release{RepoID: repo.ID, TagName: "v5.0"}).(*models.Release)
+	assert.False(t, rel.IsTag)
+	assert.False(t, rel.IsDraft)
+	assert.EqualValues(t, pusher.ID, rel.PublisherID)
+	assert.Equal(t, "v5.0", rel.Title)
+	assert.True(t, strings.HasPrefix(rel.Note, "## Changes since v1.1\n"))
+	assert.Contains(t, rel.Note, "/user2/repo1/compare/v1.1...v5.0")
+	models.AssertNotExistsBean(t, &models.Release{RepoID: repo.ID, TagName: "nightly-5"})
+
+	// Existing releases are kept
+	assert.NoError(t, CreateReleaseForPushedTag(gitRepo, repo, pusher, "v5.0"))
+	models.AssertExistsAndLoadBean(t, &models.Release{ID: rel.ID, Note: rel.Note})
+}
+
+func TestRelease_CreateMigrated(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+
+	gitRepo, err := git.OpenRepository(models.RepoPath(user.Name, repo.Name))
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+
+	attach, err := models.NewAttachment(&models.Attachment{
+		UploaderID:    user.ID,
+		Name:          "asset.txt",
+		DownloadCount: 42,
+	}, nil, strings.NewReader("migrated asset"))
+	assert.NoError(t, err)
+
+	rel := &models.Release{
+		RepoID:      repo.ID,
+		PublisherID: user.ID,
+		TagName:     "v0.3",
+		Target:      "master",
+		Title:       "v0.3 is released",
+		Note:        "Changes since ${PREVIOUS_TAG}",
+		CreatedUnix: 1262304000,
+	}
+	assert.NoError(t, CreateMigratedRelease(gitRepo, rel, []string{attach.UUID}))
+
+	// the note is not expanded like a release template and the creation time is kept
+	models.AssertExistsAndLoadBean(t, &models.Release{
+		ID:          rel.ID,
+		Note:        "Changes since ${PREVIOUS_TAG}",
+		CreatedUnix: 1262304000,
+	})
+	models.AssertExistsAndLoadBean(t, &models.Attachment{ID: attach.ID, ReleaseID: rel.ID, DownloadCount: 42})
+	assert.True(t, gitRepo.IsTagExist("v0.3"))
+
+	// drafts of migrated repositories don't need an existing tag
+	draft := &models.Release{
+		RepoID:      repo.ID,
+		PublisherID: user.ID,
+		TagName:     "v0.4",
+		Target:      "master",
+		Title:       "v0.4 is coming",
+		IsDraft:     true,
+		CreatedUnix: 1262304000,
+	}
+	assert.NoError(t, CreateMigratedRelease(gitRepo, draft, nil))
+	assert.False(t, gitRepo.IsTagExist("v0.4"))
+}
+
+func TestRelease_GenerateArchives(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	rel := models.AssertExistsAndLoadBean(t, &models.Release{ID: 1}).(*models.Release)
+	assert.NoError(t, rel.LoadAttributes())
+
+	assert.NoError(t, GenerateReleaseArchives(rel.ID))
+
+	archives := GetSourceArchives(rel)
+	if assert.Len(t, archives, 2) {
+		assert.Equal(t, "repo1-v1.1.zip", archives[0].Name)
+		assert.Equal(t, rel.Repo.HTMLURL()+"/archive/v1.1.zip", archives[0].DownloadURL)
+		assert.Equal(t, "repo1-v1.1.tar.gz", archives[1].Name)
+		for _, archive := range archives {
+			assert.NotZero(t, archive.Size)
+		}
+	}
+
+	// drafts have no tag to archive
+	rel.IsDraft = true
+	assert.Empty(t, GetSourceArchives(rel))
+}
+
+func TestRelease_MirrorAssets(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	cdnPath, err := ioutil.TempDir("", "release-cdn")
+	assert.NoError(t, err)
+	defer os.RemoveAll(cdnPath)
+
+	oldCDN, oldEnabled, oldBaseURL := storage.ReleaseCDN, setting.AttachmentCDNEnabled, setting.AttachmentCDNBaseURL
+	defer func() {
+		storage.ReleaseCDN, setting.AttachmentCDNEnabled, setting.AttachmentCDNBaseURL = oldCDN, oldEnabled, oldBaseURL
+	}()
+	storage.ReleaseCDN, err = storage.NewLocalStorage(cdnPath)
+	assert.NoError(t, err)
+	setting.AttachmentCDNEnabled = true
+	setting.AttachmentCDNBaseURL = "https://cdn.example.com/"
+
+	attach := models.AssertExistsAndLoadBean(t, &models.Attachment{ID: 9}).(*models.Attachment)
+	_, err = storage.Attachments.Save(attach.RelativePath(), strings.NewReader("asset content"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, MirrorReleaseAssets(attach.ReleaseID))
+
+	attach = models.AssertExistsAndLoadBean(t, &models.Attachment{ID: 9}).(*models.Attachment)
+	assert.Equal(t, attach.UUID+"/attach1", attach.MirrorPath)
+	assert.Equal(t, "https://cdn.example.com/"+attach.UUID+"/attach1", attach.DownloadURL())
+	content, err := ioutil.ReadFile(filepath.Join(cdnPath, attach.MirrorPath))
+	assert.NoError(t, err)
+	assert.Equal(t, "asset content", string(content))
+
+	assert.NoError(t, RemoveAttachmentMirror(attach))
+	assert.Empty(t, attach.MirrorPath)
+	_, err = os.Stat(filepath.Join(cdnPath, attach.UUID, "attach1"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Assets of private repositories are never mirrored
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	repo.IsPrivate = true
+	assert.NoError(t, models.UpdateRepository(repo, true))
+	assert.NoError(t, MirrorReleaseAssets(attach.ReleaseID))
+	models.AssertExistsAndLoadBean(t, &models.Attachment{ID: 9}, models.Cond("mirror_path = ?", ""))
+}
+
+func TestRelease_ChecksumsAttachment(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	defer func(enabled bool) {
+		setting.AttachmentReleaseChecksums = enabled
+	}(setting.AttachmentReleaseChecksums)
+	setting.AttachmentReleaseChecksums = true
+
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	rel := models.AssertExistsAndLoadBean(t, &models.Release{ID: 1}).(*models.Release)
+	checksumsUploaders := func() []int64 {
+		assert.NoError(t, models.GetReleaseAttachments(rel))
+		var uploaders []int64
+		for _, attach := range rel.Attachments {
+			if attach.Name == ChecksumsFileName {
+				uploaders = append(uploaders, attach.UploaderID)
+			}
+		}
+		return uploaders
+	}
+
+	_, err := CreateAttachment(user, rel, &models.Attachment{Name: "asset.bin", UploaderID: user.ID}, []byte("asset"), &bytes.Buffer{})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{0}, checksumsUploaders())
+
+	// The generated checksums are replaced, not added again
+	_, err = CreateAttachment(user, rel, &models.Attachment{Name: "other.bin", UploaderID: user.ID}, []byte("other"), &bytes.Buffer{})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{0}, checksumsUploaders())
+
+	// An uploaded checksums asset is kept and nothing is generated along it
+	uploaded, err := CreateAttachment(user, rel, &models.Attachment{Name: ChecksumsFileName, UploaderID: user.ID}, []byte("uploaded"), &bytes.Buffer{})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{user.ID}, checksumsUploaders())
+	_, err = CreateAttachment(user, rel, &models.Attachment{Name: "third.bin", UploaderID: user.ID}, []byte("third"), &bytes.Buffer{})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{user.ID}, checksumsUploaders())
+	models.AssertExistsAndLoadBean(t, &models.Attachment{ID: uploaded.ID})
+}
+
+func TestRelease_AppendAttachmentUploadConcurrently(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	rel := models.AssertExistsAndLoadBean(t, &models.Release{ID: 1}).(*models.Release)
+	upload, err := NewAttachmentUpload(user, rel, "asset.txt", 20)
+	assert.NoError(t, err)
+
+	// Both requests have loaded the upload before any chunk is appended
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		u := *upload
+		chunk := strings.Repeat(fmt.Sprint(i), 10)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = AppendAttachmentUpload(user, &u, 0, strings.NewReader(chunk))
+		}(i)
+	}
+	wg.Wait()
+
+	if errs[0] != nil {
+		errs[0], errs[1] = errs[1], errs[0]
+	}
+	assert.NoError(t, errs[0])
+	assert.True(t, models.IsErrAttachmentUploadOffsetMismatch(errs[1]))
+
+	upload, err = models.GetAttachmentUploadByUUID(upload.UUID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 10, upload.Received)
+	content, err := ioutil.ReadFile(upload.LocalPath())
+	assert.NoError(t, err)
+	assert.Len(t, content, 10)
+}
+
+func TestRelease_CreateSigned(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	repoPath := models.RepoPath(user.Name, repo.Name)
+
+	gitRepo, err := git.OpenRepository(repoPath)
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+
+	// Generate a signing key without passphrase in a new GPG home
+	tmpDir, err := ioutil.TempDir("", "release-gpg")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, os.Chmod(tmpDir, 0700))
+
+	oldGNUPGHome := os.Getenv("GNUPGHOME")
+	assert.NoError(t, os.Setenv("GNUPGHOME", tmpDir))
+	defer os.Setenv("GNUPGHOME", oldGNUPGHome)
+
+	_, _, err = process.GetManager().Exec("gpg --quick-gen-key", "gpg", "--batch", "--passphrase", "", "--quick-gen-key", "gitea <gitea@fake.local>", "rsa2048", "sign", "never")
+	assert.NoError(t, err)
+
+	oldSigningKey, oldReleases := setting.Repository.Signing.SigningKey, setting.Repository.Signing.Releases
+	defer func() {
+		setting.Repository.Signing.SigningKey = oldSigningKey
+		setting.Repository.Signing.Releases = oldReleases
+	}()
+	setting.Repository.Signing.SigningKey = "gitea@fake.local"
+
+	createRelease := func(tagName string) {
+		assert.NoError(t, CreateRelease(gitRepo, &models.Release{
+			RepoID:      repo.ID,
+			PublisherID: user.ID,
+			TagName:     tagName,
+			Target:      "master",
+			Title:       tagName + " is released",
+			Note:        "signed or not",
+		}, nil))
+	}
+	getTagType := func(tagName string) string {
+		tagType, err := git.NewCommand("cat-file", "-t", git.TagPrefix+tagName).RunInDir(repoPath)
+		assert.NoError(t, err)
+		return strings.TrimSpace(tagType)
+	}
+
+	// Tags are signed with the signing key of the instance and tagged by the publisher
+	setting.Repository.Signing.Releases = []string{"always"}
+	createRelease("v-signed")
+	assert.Equal(t, "tag", getTagType("v-signed"))
+	_, err = git.NewCommand("verify-tag", "v-signed").RunInDir(repoPath)
+	assert.NoError(t, err)
+	content, err := git.NewCommand("cat-file", "-p", git.TagPrefix+"v-signed").RunInDir(repoPath)
+	assert.NoError(t, err)
+	assert.Contains(t, content, fmt.Sprintf("tagger %s <%s>", user.GitName(), user.GetEmail()))
+	assert.Contains(t, content, "v-signed is released\n\nsigned or not\n")
+
+	// The publisher has no GPG key
+	setting.Repository.Signing.Releases = []string{"pubkey"}
+	createRelease("v-pubkey")
+	assert.Equal(t, "commit", getTagType("v-pubkey"))
+
+	setting.Repository.Signing.Releases = []string{"never"}
+	createRelease("v-never")
+	assert.Equal(t, "commit", getTagType("v-never"))
+
+	// Without a signing key lightweight tags are created whatever the rules
+	setting.Repository.Signing.SigningKey = "none"
+	setting.Repository.Signing.Releases = []string{"always"}
+	createRelease("v-nokey")
+	assert.Equal(t, "commit", getTagType("v-nokey"))
+}
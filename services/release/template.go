@@ -0,0 +1,92 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package release
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// ReleaseTemplateCandidates are the files in the default branch a release template is read from
+var ReleaseTemplateCandidates = []string{
+	".gitea/release_template.md",
+	".gitea/RELEASE_TEMPLATE.md",
+}
+
+// GetReleaseTemplate returns the release template of the repository,
+// or an empty string if the default branch does not contain one.
+func GetReleaseTemplate(gitRepo *git.Repository, repo *models.Repository) string {
+	if repo.IsEmpty {
+		return ""
+	}
+
+	commit, err := gitRepo.GetBranchCommit(repo.DefaultBranch)
+	if err != nil {
+		return ""
+	}
+
+	for _, filename := range ReleaseTemplateCandidates {
+		entry, err := commit.GetTreeEntryByPath(filename)
+		if err != nil || entry.Blob().Size() >= setting.UI.MaxDisplayFileSize {
+			continue
+		}
+		r, err := entry.Blob().DataAsync()
+		if err != nil {
+			continue
+		}
+		data, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			continue
+		}
+		return string(data)
+	}
+	return ""
+}
+
+// ExpandReleaseTemplate replaces the placeholders ${TAG_NAME}, ${PREVIOUS_TAG} and ${COMPARE_URL}
+// of a release note with the values for a release of the given tag. The previous tag is the one
+// of the latest published release, the placeholders are empty if there is none.
+func ExpandReleaseTemplate(repo *models.Repository, note, tagName string) (string, error) {
+	if !strings.Contains(note, "${") {
+		return note, nil
+	}
+
+	var previousTag, compareURL string
+	latest, err := models.GetLatestReleaseByRepoID(repo.ID)
+	if err != nil && !models.IsErrReleaseNotExist(err) {
+		return "", err
+	}
+	if latest != nil && latest.TagName != tagName {
+		previousTag = latest.TagName
+		compareURL = repo.HTMLURL() + "/compare/" + previousTag + "..." + tagName
+	}
+
+	return strings.NewReplacer(
+		"${TAG_NAME}", tagName,
+		"${PREVIOUS_TAG}", previousTag,
+		"${COMPARE_URL}", compareURL,
+	).Replace(note), nil
+}
+
+// applyReleaseTemplate fills the note of a new release from the release template of the repository
+// if none was given, and expands the placeholders of the note.
+func applyReleaseTemplate(gitRepo *git.Repository, rel *models.Release) (err error) {
+	if rel.Repo == nil {
+		if rel.Repo, err = models.GetRepositoryByID(rel.RepoID); err != nil {
+			return err
+		}
+	}
+
+	if len(strings.TrimSpace(rel.Note)) == 0 {
+		rel.Note = GetReleaseTemplate(gitRepo, rel.Repo)
+	}
+	rel.Note, err = ExpandReleaseTemplate(rel.Repo, rel.Note, rel.TagName)
+	return err
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package release
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/sync"
+	"code.gitea.io/gitea/modules/upload"
+)
+
+// uploadWorkingPool serializes the chunks appended to the same upload
+var uploadWorkingPool = sync.NewExclusivePool()
+
+// NewAttachmentUpload starts a resumable upload of an attachment for the given release
+func NewAttachmentUpload(doer *models.User, rel *models.Release, name string, size int64) (*models.AttachmentUpload, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid size of attachment: %d", size)
+	}
+	if maxSize := setting.Repository.Release.MaxUploadSize; maxSize > -1 && size > maxSize*1024*1024 {
+		return nil, models.ErrReleaseAssetQuotaExceeded{Quota: "upload size", Limit: maxSize}
+	}
+	if err := models.CheckReleaseAssetQuota(rel, size); err != nil {
+		return nil, err
+	}
+	return models.NewAttachmentUpload(&models.AttachmentUpload{
+		ReleaseID:  rel.ID,
+		UploaderID: doer.ID,
+		Name:       name,
+		Size:       size,
+	})
+}
+
+// AppendAttachmentUpload appends a chunk to a resumable upload. Once all the content
+// has been received the attachment is created and returned, otherwise the returned
+// attachment is nil and the upload can be continued at the new offset.
+// The upload is reloaded first, it may have been changed by a concurrent request.
+func AppendAttachmentUpload(doer *models.User, u *models.AttachmentUpload, offset int64, chunk io.Reader) (*models.Attachment, error) {
+	uploadWorkingPool.CheckIn(u.UUID)
+	defer uploadWorkingPool.CheckOut(u.UUID)
+
+	current, err := models.GetAttachmentUploadByUUID(u.UUID)
+	if err != nil {
+		return nil, err
+	}
+	*u = *current
+
+	if err := u.AppendChunk(offset, chunk); err != nil {
+		return nil, err
+	}
+	if !u.IsComplete() {
+		return nil, nil
+	}
+
+	file, err := os.Open(u.LocalPath())
+	if err != nil {
+		return nil, fmt.Errorf("Open: %v", err)
+	}
+	defer file.Close()
+
+	buf := make([]byte, 1024)
+	n, _ := file.Read(buf)
+	buf = buf[:n]
+
+	// Check if the filetype is allowed by the settings
+	if err = upload.VerifyAllowedContentType(buf, strings.Split(setting.AttachmentAllowedTypes, ",")); err != nil {
+		if delErr := models.DeleteAttachmentUpload(u); delErr != nil {
+			return nil, fmt.Errorf("DeleteAttachmentUpload: %v", delErr)
+		}
+		return nil, err
+	}
+
+	rel, err := models.GetReleaseByID(u.ReleaseID)
+	if err != nil {
+		return nil, fmt.Errorf("GetReleaseByID: %v", err)
+	}
+
+	attach, err := CreateAttachment(doer, rel, &models.Attachment{
+		UploaderID: u.UploaderID,
+		Name:       u.Name,
+	}, buf, file)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = models.DeleteAttachmentUpload(u); err != nil {
+		return nil, fmt.Errorf("DeleteAttachmentUpload: %v", err)
+	}
+	return attach, nil
+}
@@ -0,0 +1,275 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	repo_module "code.gitea.io/gitea/modules/repository"
+	pull_service "code.gitea.io/gitea/services/pull"
+
+	"github.com/mcuadros/go-version"
+)
+
+// CherryPickOptions are the options to cherry-pick or revert a commit onto a branch
+type CherryPickOptions struct {
+	// Revert reverts the commit instead of cherry-picking it
+	Revert bool
+	// Branch is the branch to apply the commit onto, the default branch if empty
+	Branch string
+	// NewBranch is created from Branch with the new commit instead of updating Branch
+	NewBranch string
+	// Message is the message of the new commit, generated from the commit if empty
+	Message string
+	// PullOnConflict commits conflicts with their markers to a new branch and opens
+	// a pull request into Branch to resolve them, instead of failing
+	PullOnConflict bool
+}
+
+// CherryPickResult is the result of cherry-picking or reverting a commit
+type CherryPickResult struct {
+	CommitID  string
+	Branch    string
+	Conflicts []string
+	Pull      *models.PullRequest
+}
+
+// CherryPick applies a commit, or its revert, onto a branch of the repository as a new commit of the doer.
+// The author of a cherry-picked commit is kept.
+func CherryPick(doer *models.User, repo *models.Repository, commit *git.Commit, opts CherryPickOptions) (*CherryPickResult, error) {
+	if commit.ParentCount() > 1 {
+		return nil, fmt.Errorf("merge commit %s cannot be applied", commit.ID)
+	}
+	if opts.Branch == "" {
+		opts.Branch = repo.DefaultBranch
+	}
+	if opts.NewBranch == opts.Branch {
+		opts.NewBranch = ""
+	}
+
+	// The branch to apply the commit onto must exist
+	if _, err := repo_module.GetBranch(repo, opts.Branch); err != nil {
+		return nil, err
+	}
+	if opts.NewBranch != "" {
+		if err := checkBranchNotExist(repo, opts.NewBranch); err != nil {
+			return nil, err
+		}
+	} else if err := checkUserCanPush(doer, repo, opts.Branch); err != nil {
+		return nil, err
+	}
+
+	tmpBasePath, err := models.CreateTemporaryPath("cherry-pick")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := models.RemoveTemporaryPath(tmpBasePath); err != nil {
+			log.Error("CherryPick: RemoveTemporaryPath: %s", err)
+		}
+	}()
+
+	committer := doer.NewGitSig()
+	author := committer
+	if !opts.Revert {
+		author = commit.Author
+	}
+	commitTimeStr := time.Now().Format(time.RFC3339)
+	// Because this may call hooks we should pass in the environment
+	env := append(os.Environ(),
+		"GIT_AUTHOR_NAME="+author.Name,
+		"GIT_AUTHOR_EMAIL="+author.Email,
+		"GIT_AUTHOR_DATE="+author.When.Format(time.RFC3339),
+		"GIT_COMMITTER_NAME="+committer.Name,
+		"GIT_COMMITTER_EMAIL="+committer.Email,
+		"GIT_COMMITTER_DATE="+commitTimeStr,
+	)
+	run := func(stdin string, args ...string) (string, error) {
+		stdout := new(bytes.Buffer)
+		stderr := new(bytes.Buffer)
+		if err := git.NewCommand(args...).RunInDirTimeoutEnvFullPipeline(env, -1, tmpBasePath, stdout, stderr, strings.NewReader(stdin)); err != nil {
+			return stdout.String(), fmt.Errorf("git %s: %v\n%s\n%s", args[0], err, stdout, stderr)
+		}
+		return stdout.String(), nil
+	}
+
+	// The clone shares the objects of the repository, so the commit is available even if it isn't on the branch
+	if _, err := git.NewCommand("clone", "-s", "--no-checkout", "-b", opts.Branch, repo.RepoPath(), tmpBasePath).Run(); err != nil {
+		return nil, fmt.Errorf("git clone: %v", err)
+	}
+	// Leave LFS pointers alone
+	for _, key := range []string{"filter.lfs.process", "filter.lfs.required", "filter.lfs.clean", "filter.lfs.smudge"} {
+		if _, err := run("", "config", "--local", key, ""); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := run("", "reset", "--hard", "-q", "HEAD"); err != nil {
+		return nil, err
+	}
+
+	action := "cherry-pick"
+	if opts.Revert {
+		action = "revert"
+	}
+	conflicts := []string{}
+	if _, err := run("", action, "--no-commit", commit.ID.String()); err != nil {
+		unmerged, diffErr := run("", "diff", "--name-only", "--diff-filter=U")
+		if diffErr != nil {
+			return nil, diffErr
+		}
+		conflicts = strings.Fields(unmerged)
+		if len(conflicts) == 0 {
+			return nil, err
+		}
+		if !opts.PullOnConflict {
+			return nil, models.ErrCherryPickConflicts{
+				CommitSHA: commit.ID.String(),
+				Revert:    opts.Revert,
+				Files:     conflicts,
+			}
+		}
+		if _, err := run("", "add", "-A"); err != nil {
+			return nil, err
+		}
+
+		// The conflicts are resolved in a pull request from a new branch
+		if opts.NewBranch == "" {
+			opts.NewBranch = fmt.Sprintf("%s-%s", action, base.ShortSha(commit.ID.String()))
+			if err := checkBranchNotExist(repo, opts.NewBranch); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	treeHash, err := run("", "write-tree")
+	if err != nil {
+		return nil, err
+	}
+
+	message := strings.TrimSpace(opts.Message)
+	if message == "" {
+		if opts.Revert {
+			message = fmt.Sprintf("Revert \"%s\"\n\nThis reverts commit %s.", commit.Summary(), commit.ID)
+		} else {
+			message = fmt.Sprintf("%s\n\n(cherry picked from commit %s)", strings.TrimSpace(commit.Message()), commit.ID)
+		}
+	}
+	if len(conflicts) > 0 {
+		message += "\n\nConflicts:\n\t" + strings.Join(conflicts, "\n\t")
+	}
+
+	args := []string{"commit-tree", strings.TrimSpace(treeHash), "-p", "HEAD"}
+	binVersion, err := git.BinVersion()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get git version: %v", err)
+	}
+	if version.Compare(binVersion, "1.7.9", ">=") {
+		sign, keyID, _ := repo.SignCRUDAction(doer, tmpBasePath, "HEAD")
+		if sign {
+			args = append(args, "-S"+keyID)
+		} else if version.Compare(binVersion, "2.0.0", ">=") {
+			args = append(args, "--no-gpg-sign")
+		}
+	}
+	commitID, err := run(message+"\n", args...)
+	if err != nil {
+		return nil, err
+	}
+	commitID = strings.TrimSpace(commitID)
+
+	targetBranch := opts.Branch
+	if opts.NewBranch != "" {
+		targetBranch = opts.NewBranch
+	}
+	if err := git.Push(tmpBasePath, git.PushOptions{
+		Remote: repo.RepoPath(),
+		Branch: commitID + ":refs/heads/" + targetBranch,
+		Env:    models.PushingEnvironment(doer, repo),
+	}); err != nil {
+		return nil, err
+	}
+
+	result := &CherryPickResult{
+		CommitID:  commitID,
+		Branch:    targetBranch,
+		Conflicts: conflicts,
+	}
+	if len(conflicts) == 0 {
+		return result, nil
+	}
+
+	mergeBase, err := run("", "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	issue := &models.Issue{
+		RepoID:   repo.ID,
+		Title:    strings.SplitN(message, "\n", 2)[0],
+		PosterID: doer.ID,
+		Poster:   doer,
+		IsPull:   true,
+		Content: fmt.Sprintf("Applying %s onto `%s` conflicts in:\n\n* `%s`\n\nThe conflict markers were committed to resolve them here.",
+			commit.ID, opts.Branch, strings.Join(conflicts, "`\n* `")),
+	}
+	result.Pull = &models.PullRequest{
+		HeadRepoID: repo.ID,
+		BaseRepoID: repo.ID,
+		HeadBranch: targetBranch,
+		BaseBranch: opts.Branch,
+		HeadRepo:   repo,
+		BaseRepo:   repo,
+		MergeBase:  strings.TrimSpace(mergeBase),
+		Type:       models.PullRequestGitea,
+	}
+	if err := pull_service.NewPullRequest(repo, issue, nil, nil, result.Pull, nil); err != nil {
+		return nil, fmt.Errorf("NewPullRequest: %v", err)
+	}
+	return result, nil
+}
+
+func checkBranchNotExist(repo *models.Repository, branch string) error {
+	if _, err := repo_module.GetBranch(repo, branch); err == nil {
+		return models.ErrBranchAlreadyExists{
+			BranchName: branch,
+		}
+	} else if !git.IsErrBranchNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func checkUserCanPush(doer *models.User, repo *models.Repository, branch string) error {
+	protectedBranch, err := repo.GetBranchProtection(branch)
+	if err != nil {
+		return err
+	}
+	if protectedBranch == nil {
+		return nil
+	}
+	if !protectedBranch.CanUserPush(doer.ID) {
+		return models.ErrUserCannotCommit{
+			UserName: doer.LowerName,
+		}
+	}
+	if protectedBranch.RequireSignedCommits {
+		if _, _, err := repo.SignCRUDAction(doer, repo.RepoPath(), branch); err != nil {
+			if !models.IsErrWontSign(err) {
+				return err
+			}
+			return models.ErrUserCannotCommit{
+				UserName: doer.LowerName,
+			}
+		}
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ package repository
 
 import (
 	"fmt"
+	"strings"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/notification"
@@ -53,6 +54,64 @@ func TransferOwnership(doer, newOwner *models.User, repo *models.Repository, tea
 	return nil
 }
 
+// StartRepositoryTransfer transfers the repository to the new owner if the doer is a site
+// admin or can create repositories for the new owner. Otherwise it creates a transfer
+// request, the repository is transferred once the new owner accepts it. It returns
+// whether the transfer is pending.
+func StartRepositoryTransfer(doer, newOwner *models.User, repo *models.Repository, teams []*models.Team) (bool, error) {
+	canCreate := doer.IsAdmin || doer.ID == newOwner.ID
+	if !canCreate && newOwner.IsOrganization() {
+		var err error
+		if canCreate, err = newOwner.CanCreateOrgRepo(doer.ID); err != nil {
+			return false, err
+		}
+	}
+	if canCreate {
+		return false, TransferOwnership(doer, newOwner, repo, teams)
+	}
+
+	if err := models.CreatePendingRepositoryTransfer(doer, newOwner, repo, teams); err != nil {
+		return false, err
+	}
+
+	notification.NotifyRepoPendingTransfer(doer, newOwner, repo)
+
+	return true, nil
+}
+
+// AcceptTransferOwnership transfers the repository as requested by its pending transfer, the
+// caller checks that the user accepting it is allowed to.
+func AcceptTransferOwnership(repo *models.Repository) error {
+	transfer, err := models.GetPendingRepositoryTransfer(repo)
+	if err != nil {
+		return err
+	}
+	if err = transfer.LoadAttributes(); err != nil {
+		return err
+	}
+
+	return TransferOwnership(transfer.Doer, transfer.Recipient, repo, transfer.Teams)
+}
+
+// RejectRepositoryTransfer removes the pending transfer of the repository, it is rejected
+// by the new owner or cancelled by the owner.
+func RejectRepositoryTransfer(repo *models.Repository, doer *models.User) error {
+	transfer, err := models.GetPendingRepositoryTransfer(repo)
+	if err != nil {
+		return err
+	}
+	if err = transfer.LoadAttributes(); err != nil {
+		return err
+	}
+	if err = models.CancelRepositoryTransfer(repo); err != nil {
+		return err
+	}
+
+	notification.NotifyRejectRepoTransfer(doer, transfer.Recipient, repo)
+
+	return nil
+}
+
 // ChangeRepositoryName changes all corresponding setting from old repository name to new one.
 func ChangeRepositoryName(doer *models.User, repo *models.Repository, newRepoName string) error {
 	oldRepoName := repo.Name
@@ -68,6 +127,9 @@ func ChangeRepositoryName(doer *models.User, repo *models.Repository, newRepoNam
 	}
 	repoWorkingPool.CheckOut(com.ToStr(repo.ID))
 
+	repo.Name = newRepoName
+	repo.LowerName = strings.ToLower(newRepoName)
+
 	notification.NotifyRenameRepository(doer, repo, oldRepoName)
 
 	return nil
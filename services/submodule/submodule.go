@@ -0,0 +1,170 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package submodule
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+var scpSyntax = regexp.MustCompile(`^([a-zA-Z0-9_]+@)?([a-zA-Z0-9._-]+):(.*)$`)
+
+// Submodule is a submodule of a commit resolved to the repository it refers to
+type Submodule struct {
+	Name   string
+	Path   string
+	URL    string
+	Branch string
+	// CommitID is the commit of the submodule repository the submodule is pinned at
+	CommitID string
+	// RefURL is the web link of the submodule repository, empty if it can't be guessed from its URL
+	RefURL string
+	// Repo is the repository of this instance the submodule refers to if the doer can read it, nil otherwise
+	Repo *models.Repository
+	// AccessMode is the access mode of the doer to Repo
+	AccessMode models.AccessMode
+}
+
+// CommitURL returns the web link of the commit the submodule is pinned at, empty if it can't be guessed
+func (s *Submodule) CommitURL() string {
+	if s.RefURL == "" {
+		return ""
+	}
+	return s.RefURL + "/commit/" + s.CommitID
+}
+
+// isLocalHost returns whether the host of an URL is the one of this instance
+func isLocalHost(host string, ssh bool) bool {
+	appURL, err := url.Parse(setting.AppURL)
+	if err == nil && strings.EqualFold(host, appURL.Hostname()) {
+		return true
+	}
+	return ssh && strings.EqualFold(host, setting.SSH.Domain)
+}
+
+// LocalRepoPath returns the owner and the name of the repository of this instance the URL of a submodule
+// of repo refers to. Relative URLs are resolved against the URL of repo, ssh URLs refer to this instance
+// if their host is its SSH domain or its domain, and http URLs if they start with its URL.
+func LocalRepoPath(repo *models.Repository, submoduleURL string) (owner, name string, ok bool) {
+	submoduleURL = strings.TrimSpace(submoduleURL)
+
+	var repoPath string
+	if strings.HasPrefix(submoduleURL, "./") || strings.HasPrefix(submoduleURL, "../") {
+		repoPath = path.Join("/", repo.OwnerName, repo.Name, submoduleURL)
+	} else if match := scpSyntax.FindStringSubmatch(submoduleURL); match != nil && !strings.Contains(submoduleURL, "://") {
+		// scp like syntax, ex: git@try.gitea.io:go-gitea/gitea.git
+		if !isLocalHost(match[2], true) {
+			return "", "", false
+		}
+		repoPath = match[3]
+	} else {
+		u, err := url.Parse(submoduleURL)
+		if err != nil {
+			return "", "", false
+		}
+		switch u.Scheme {
+		case "ssh", "git+ssh":
+			if !isLocalHost(u.Hostname(), true) {
+				return "", "", false
+			}
+			repoPath = u.Path
+		case "http", "https":
+			appURL, err := url.Parse(setting.AppURL)
+			if err != nil || !strings.EqualFold(u.Host, appURL.Host) || !strings.HasPrefix(u.Path, appURL.Path) {
+				return "", "", false
+			}
+			repoPath = strings.TrimPrefix(u.Path, appURL.Path)
+		default:
+			return "", "", false
+		}
+	}
+
+	fields := strings.Split(strings.Trim(repoPath, "/"), "/")
+	if len(fields) != 2 || fields[0] == "" || fields[1] == "" {
+		return "", "", false
+	}
+	return fields[0], strings.TrimSuffix(fields[1], ".git"), true
+}
+
+// GetLocalRepository returns the repository of this instance the URL of a submodule of repo refers to
+// with the access mode of the doer to it, if the doer can read its code
+func GetLocalRepository(doer *models.User, repo *models.Repository, submoduleURL string) (*models.Repository, models.AccessMode, error) {
+	owner, name, ok := LocalRepoPath(repo, submoduleURL)
+	if !ok {
+		return nil, models.AccessModeNone, nil
+	}
+
+	subRepo, err := models.GetRepositoryByOwnerAndName(owner, name)
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			return nil, models.AccessModeNone, nil
+		}
+		return nil, models.AccessModeNone, fmt.Errorf("GetRepositoryByOwnerAndName: %v", err)
+	}
+	perm, err := models.GetUserRepoPermission(subRepo, doer)
+	if err != nil {
+		return nil, models.AccessModeNone, fmt.Errorf("GetUserRepoPermission: %v", err)
+	}
+	if !perm.CanRead(models.UnitTypeCode) {
+		return nil, models.AccessModeNone, nil
+	}
+	return subRepo, perm.AccessMode, nil
+}
+
+// Resolve returns the submodules of the commit of repo sorted by path, with the commits they are pinned at.
+// The submodules referring to repositories of this instance are resolved to them if the doer can read them.
+func Resolve(doer *models.User, repo *models.Repository, commit *git.Commit) ([]*Submodule, error) {
+	modules, err := commit.GetSubModules()
+	if err != nil {
+		return nil, fmt.Errorf("GetSubModules: %v", err)
+	} else if modules == nil {
+		return []*Submodule{}, nil
+	}
+	paths := modules.Keys()
+	sort.Strings(paths)
+
+	submodules := make([]*Submodule, 0, len(paths))
+	for _, modulePath := range paths {
+		entry, err := commit.GetTreeEntryByPath(modulePath)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("GetTreeEntryByPath: %v", err)
+		}
+		if !entry.IsSubModule() {
+			continue
+		}
+
+		module, _ := modules.Get(modulePath)
+		gitModule := module.(*git.SubModule)
+		submodule := &Submodule{
+			Name:     gitModule.Name,
+			Path:     gitModule.Path,
+			URL:      gitModule.URL,
+			Branch:   gitModule.Branch,
+			CommitID: entry.ID.String(),
+		}
+		submodule.Repo, submodule.AccessMode, err = GetLocalRepository(doer, repo, gitModule.URL)
+		if err != nil {
+			return nil, err
+		}
+		if submodule.Repo != nil {
+			submodule.RefURL = submodule.Repo.HTMLURL()
+		} else {
+			submodule.RefURL = git.NewSubModuleFile(commit, gitModule.URL, submodule.CommitID).RefURL(setting.AppURL, repo.FullName())
+		}
+		submodules = append(submodules, submodule)
+	}
+	return submodules, nil
+}
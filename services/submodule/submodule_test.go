@@ -0,0 +1,121 @@
+// Copyright 2020 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package submodule
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	models.MainTest(m, filepath.Join("..", ".."))
+}
+
+func TestLocalRepoPath(t *testing.T) {
+	defer func(appURL, sshDomain string) {
+		setting.AppURL = appURL
+		setting.SSH.Domain = sshDomain
+	}(setting.AppURL, setting.SSH.Domain)
+	setting.AppURL = "https://try.gitea.io/gitea/"
+	setting.SSH.Domain = "ssh.gitea.io"
+
+	repo := &models.Repository{OwnerName: "user2", Name: "repo1"}
+	for submoduleURL, expected := range map[string]string{
+		"../repo2.git":                                 "user2/repo2",
+		"./sub":                                        "",
+		"../../user3/repo3":                            "user3/repo3",
+		"git@try.gitea.io:user3/repo3.git":             "user3/repo3",
+		"git@ssh.gitea.io:user3/repo3.git":             "user3/repo3",
+		"ssh://git@ssh.gitea.io:2222/user3/repo3.git":  "user3/repo3",
+		"git+ssh://git@try.gitea.io/user3/repo3.git":   "user3/repo3",
+		"https://try.gitea.io/gitea/user3/repo3.git":   "user3/repo3",
+		"https://try.gitea.io/gitea/user3/repo3/":      "user3/repo3",
+		"https://try.gitea.io/user3/repo3.git":         "",
+		"http://try.gitea.io:3000/gitea/user3/repo3":   "",
+		"https://github.com/user3/repo3.git":           "",
+		"git@github.com:user3/repo3.git":               "",
+		"git://try.gitea.io/gitea/user3/repo3.git":     "",
+		"https://try.gitea.io/gitea/user3/repo3/wiki/": "",
+	} {
+		owner, name, ok := LocalRepoPath(repo, submoduleURL)
+		assert.Equal(t, expected != "", ok, submoduleURL)
+		if ok {
+			assert.Equal(t, expected, owner+"/"+name, submoduleURL)
+		}
+	}
+}
+
+// commitWithSubmodules creates a commit in the repository which only contains submodules pinned at the given commits
+func commitWithSubmodules(t *testing.T, repoPath string, submodules map[string][2]string) string {
+	run := func(stdin string, args ...string) string {
+		stdout := new(strings.Builder)
+		stderr := new(strings.Builder)
+		err := git.NewCommand(args...).RunInDirTimeoutEnvFullPipeline([]string{
+			"GIT_AUTHOR_NAME=user2", "GIT_AUTHOR_EMAIL=user2@example.com",
+			"GIT_COMMITTER_NAME=user2", "GIT_COMMITTER_EMAIL=user2@example.com",
+		}, -1, repoPath, stdout, stderr, strings.NewReader(stdin))
+		assert.NoError(t, err, stderr.String())
+		return strings.TrimSpace(stdout.String())
+	}
+
+	var gitmodules, tree strings.Builder
+	for path, submodule := range submodules {
+		gitmodules.WriteString("[submodule \"" + path + "\"]\n\tpath = " + path + "\n\turl = " + submodule[0] + "\n")
+		tree.WriteString("160000 commit " + submodule[1] + "\t" + path + "\n")
+	}
+	blobID := run(gitmodules.String(), "hash-object", "-w", "--stdin")
+	treeID := run(tree.String()+"100644 blob "+blobID+"\t.gitmodules\n", "mktree")
+	return run("", "commit-tree", treeID, "-m", "add submodules")
+}
+
+func TestResolve(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+
+	// the private repo16 of user2 and an external repository are pinned as submodules
+	commitID := commitWithSubmodules(t, repo.RepoPath(), map[string][2]string{
+		"local":    {"../repo16.git", "69554a64c1e6030f051e5c3f94bfbd773cd6a324"},
+		"external": {"git@github.com:go-gitea/test_repo.git", "2839944139e0de9737a044f78b0e4b40d989a9e3"},
+	})
+	commit, err := gitRepo.GetCommit(commitID)
+	assert.NoError(t, err)
+
+	user2 := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	submodules, err := Resolve(user2, repo, commit)
+	assert.NoError(t, err)
+	if assert.Len(t, submodules, 2) {
+		assert.Equal(t, "external", submodules[0].Path)
+		assert.Equal(t, "2839944139e0de9737a044f78b0e4b40d989a9e3", submodules[0].CommitID)
+		assert.Equal(t, "http://github.com/go-gitea/test_repo", submodules[0].RefURL)
+		assert.Equal(t, "http://github.com/go-gitea/test_repo/commit/2839944139e0de9737a044f78b0e4b40d989a9e3", submodules[0].CommitURL())
+		assert.Nil(t, submodules[0].Repo)
+
+		assert.Equal(t, "local", submodules[1].Path)
+		if assert.NotNil(t, submodules[1].Repo) {
+			assert.EqualValues(t, 16, submodules[1].Repo.ID)
+		}
+		assert.Equal(t, models.AccessModeOwner, submodules[1].AccessMode)
+		assert.Equal(t, setting.AppURL+"user2/repo16", submodules[1].RefURL)
+	}
+
+	// users who can't read the local repository only get its URL
+	submodules, err = Resolve(nil, repo, commit)
+	assert.NoError(t, err)
+	if assert.Len(t, submodules, 2) {
+		assert.Nil(t, submodules[1].Repo)
+		assert.Equal(t, setting.AppURL+"user2/repo16", submodules[1].RefURL)
+	}
+}
@@ -0,0 +1,36 @@
+const {csrf} = window.config;
+
+async function waitForArchive(url) {
+  const data = await $.ajax({
+    type: 'POST',
+    url,
+    data: {
+      _csrf: csrf,
+    },
+  });
+  if (!data.complete) {
+    await new Promise((resolve) => setTimeout(resolve, 2000));
+    await waitForArchive(url);
+  }
+}
+
+// Archives are generated in the background, they are downloaded once they are complete
+export default function initArchiveLinks() {
+  $('.archive-link').on('click', async function (event) {
+    event.preventDefault();
+    const $link = $(this);
+    if ($link.hasClass('disabled')) {
+      return;
+    }
+    const url = $link.attr('href');
+
+    $link.addClass('disabled');
+    try {
+      await waitForArchive(url);
+    } catch (error) {
+      console.error(error);
+    }
+    $link.removeClass('disabled');
+    window.location.href = url;
+  });
+}
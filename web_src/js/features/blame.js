@@ -0,0 +1,65 @@
+// The blame of a file is streamed as newline delimited JSON entries in the order git finds them,
+// they are rendered as soon as they are received
+function renderBlameLine(shas, infos, commits, line) {
+  const sha = shas[line];
+  const info = infos[line - 1];
+  if (!sha || !info) return;
+
+  if (shas[line - 1] !== sha) {
+    info.innerHTML = commits[sha];
+  } else {
+    info.innerHTML = '&#8203;';
+  }
+
+  const isBottom = shas[line + 1] !== undefined && shas[line + 1] !== sha;
+  info.classList.toggle('bottom-line', isBottom);
+  $(`#L${line}`).toggleClass('bottom-line', isBottom);
+  $(`.lines-code li.L${line}`).toggleClass('bottom-line', isBottom);
+}
+
+function renderBlameEntry(shas, infos, commits, entry) {
+  if (entry.html) {
+    commits[entry.sha] = entry.html;
+  }
+  for (let line = entry.start_line; line < entry.start_line + entry.num_lines; line++) {
+    shas[line] = entry.sha;
+  }
+  // The lines around the entry start or end a block depending on it
+  for (let line = entry.start_line - 1; line <= entry.start_line + entry.num_lines; line++) {
+    renderBlameLine(shas, infos, commits, line);
+  }
+}
+
+export default async function initBlame() {
+  const container = document.querySelector('.lines-commit[data-blame-url]');
+  if (!container) return;
+
+  const infos = container.querySelectorAll('.blame-info');
+  const shas = [];
+  const commits = {};
+
+  const response = await fetch(container.getAttribute('data-blame-url'));
+  if (!response.ok) {
+    console.error(`Unable to load the blame: ${response.status}`);
+    return;
+  }
+
+  const reader = response.body.getReader();
+  const decoder = new TextDecoder();
+  let buffer = '';
+  for (;;) {
+    const {done, value} = await reader.read();
+    if (value) {
+      buffer += decoder.decode(value, {stream: !done});
+    }
+    const lines = buffer.split('\n');
+    buffer = done ? '' : lines.pop();
+    for (const line of lines) {
+      if (line) {
+        renderBlameEntry(shas, infos, commits, JSON.parse(line));
+      }
+    }
+    if (done) break;
+  }
+  $(container).find('.poping.up').popup();
+}
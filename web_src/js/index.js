@@ -21,6 +21,8 @@ import highlight from './features/highlight.js';
 import ActivityTopAuthors from './components/ActivityTopAuthors.vue';
 import {initNotificationsTable, initNotificationCount} from './features/notification.js';
 import {createCodeEditor} from './features/codeeditor.js';
+import initArchiveLinks from './features/archive.js';
+import initBlame from './features/blame.js';
 
 const {AppSubUrl, StaticUrlPrefix, csrf} = window.config;
 
@@ -1704,7 +1706,8 @@ function initWebhook() {
     const $this = $(this);
     $this.addClass('loading disabled');
     $.post($this.data('link'), {
-      _csrf: csrf
+      _csrf: csrf,
+      event: $('#test-delivery-event input').val()
     }).done(
       setTimeout(() => {
         window.location.href = $this.data('redirect');
@@ -2450,6 +2453,8 @@ $(document).ready(async () => {
   initContextPopups();
   initNotificationsTable();
   initNotificationCount();
+  initArchiveLinks();
+  initBlame();
 
   // Repo clone url.
   if ($('#repo-clone-url').length > 0) {